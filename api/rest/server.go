@@ -0,0 +1,263 @@
+// Package rest is the HTTP API of `ipsw serve`: device and firmware lookups and a shared download queue
+package rest
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+
+	"github.com/blacktop/ipsw/api/types"
+	"github.com/blacktop/ipsw/internal/download"
+	"github.com/gin-gonic/gin"
+)
+
+// Config is the HTTP server config
+type Config struct {
+	Output   string            // folder the downloads are saved to (defaults to the current folder)
+	Proxy    string            // HTTP/HTTPS proxy of the downloads
+	Insecure bool              // do not verify ssl certs
+	Manager  *download.Manager // the download queue (defaults to download.DefaultManager())
+}
+
+type server struct {
+	conf    Config
+	manager *download.Manager
+}
+
+// NewHandler returns the HTTP handler of the API:
+//
+//	GET    /devices                 all devices (without their firmwares)
+//	GET    /device/{id}             a device and its IPSWs
+//	GET    /device/{id}/firmwares   the IPSWs of a device (?type=ota for its OTAs, ?signed=true for the signed ones)
+//	POST   /downloads               queue a download of a URL or of a device's build (returns its job)
+//	GET    /jobs                    all download jobs in the order they were submitted
+//	GET    /jobs/{id}               a download job
+//	DELETE /jobs/{id}               cancel a queued or running download job (or remove a finished one)
+func NewHandler(conf Config) http.Handler {
+	s := &server{conf: conf, manager: conf.Manager}
+	if s.manager == nil {
+		s.manager = download.DefaultManager()
+	}
+	if len(s.conf.Output) == 0 {
+		s.conf.Output = "."
+	}
+
+	router := gin.New()
+	router.Use(gin.Logger(), gin.Recovery())
+	router.GET("/devices", s.listDevices)
+	router.GET("/device/:id", s.getDevice)
+	router.GET("/device/:id/firmwares", s.listFirmwares)
+	router.POST("/downloads", s.submitDownload)
+	router.GET("/jobs", s.listJobs)
+	router.GET("/jobs/:id", s.getJob)
+	router.DELETE("/jobs/:id", s.cancelJob)
+	return router
+}
+
+// httpStatus returns the HTTP status code matching the download.ErrorCode of err
+func httpStatus(err error) int {
+	switch download.ErrorCodeOf(err) {
+	case download.ErrCodeInvalidArgument:
+		return http.StatusBadRequest
+	case download.ErrCodeNotFound:
+		return http.StatusNotFound
+	case download.ErrCodeNetwork, download.ErrCodeParse:
+		return http.StatusBadGateway
+	case download.ErrCodeAuth:
+		return http.StatusForbidden
+	case download.ErrCodeTimeout:
+		return http.StatusGatewayTimeout
+	case download.ErrCodeUnsupported:
+		return http.StatusNotImplemented
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func abort(c *gin.Context, code int, err error) {
+	c.AbortWithStatusJSON(code, types.GenericError{Error: err.Error()})
+}
+
+type devicesResponse struct {
+	Devices []download.Device `json:"devices"`
+}
+
+func (s *server) listDevices(c *gin.Context) {
+	devices, err := download.GetAllDevicesWithContext(c.Request.Context())
+	if err != nil {
+		abort(c, httpStatus(err), err)
+		return
+	}
+	for i := range devices {
+		devices[i].Firmwares = nil
+	}
+	c.IndentedJSON(http.StatusOK, devicesResponse{Devices: devices})
+}
+
+func (s *server) getDevice(c *gin.Context) {
+	device, err := download.GetDeviceWithContext(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		abort(c, httpStatus(err), err)
+		return
+	}
+	c.IndentedJSON(http.StatusOK, device)
+}
+
+type firmwaresResponse struct {
+	Firmwares []download.IPSW `json:"firmwares"`
+}
+
+func (s *server) listFirmwares(c *gin.Context) {
+	var signedOnly bool
+	if signed := c.Query("signed"); len(signed) > 0 {
+		var err error
+		if signedOnly, err = strconv.ParseBool(signed); err != nil {
+			abort(c, http.StatusBadRequest, fmt.Errorf("invalid signed query parameter '%s'", signed))
+			return
+		}
+	}
+	var (
+		firmwares []download.IPSW
+		err       error
+	)
+	switch typ := c.DefaultQuery("type", "ipsw"); typ {
+	case "ipsw":
+		firmwares, err = download.GetDeviceIPSWsWithContext(c.Request.Context(), c.Param("id"))
+	case "ota":
+		firmwares, err = download.GetDeviceOTAsWithContext(c.Request.Context(), c.Param("id"))
+	default:
+		abort(c, http.StatusBadRequest, fmt.Errorf("invalid firmware type '%s' (must be ipsw or ota)", typ))
+		return
+	}
+	if err != nil {
+		abort(c, httpStatus(err), err)
+		return
+	}
+	resp := firmwaresResponse{Firmwares: make([]download.IPSW, 0, len(firmwares))}
+	for _, fw := range firmwares {
+		if signedOnly && !fw.Signed {
+			continue
+		}
+		resp.Firmwares = append(resp.Firmwares, fw)
+	}
+	c.IndentedJSON(http.StatusOK, resp)
+}
+
+type downloadRequest struct {
+	URL        string `json:"url"`        // URL to download
+	Identifier string `json:"identifier"` // or the device whose build IPSW to download (i.e. iPhone15,2)
+	Build      string `json:"build"`      // i.e. 21A329
+	Dest       string `json:"dest"`       // path under the server's output folder (defaults to the URL's filename)
+	Sha1       string `json:"sha1"`       // expected sha1 of the download (looked up for a device's build)
+}
+
+// destPath returns where to save the download of u to under the output folder
+func (s *server) destPath(u *url.URL, dest string) (string, error) {
+	if len(dest) == 0 {
+		dest = path.Base(u.Path)
+	}
+	dest = filepath.Clean(filepath.FromSlash(dest))
+	if !filepath.IsLocal(dest) {
+		return "", fmt.Errorf("dest '%s' must be a relative path inside the server's output folder", dest)
+	}
+	return filepath.Join(s.conf.Output, dest), nil
+}
+
+func (s *server) submitDownload(c *gin.Context) {
+	var req downloadRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		abort(c, http.StatusBadRequest, err)
+		return
+	}
+	switch {
+	case len(req.URL) > 0:
+	case len(req.Identifier) > 0 && len(req.Build) > 0:
+		i, err := download.GetIPSWWithContext(c.Request.Context(), req.Identifier, req.Build)
+		if err != nil {
+			abort(c, httpStatus(err), err)
+			return
+		}
+		req.URL = i.URL
+		if len(req.Sha1) == 0 {
+			req.Sha1 = i.SHA1
+		}
+	default:
+		abort(c, http.StatusBadRequest, fmt.Errorf("url (or identifier and build) is required"))
+		return
+	}
+
+	u, err := url.Parse(req.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		abort(c, http.StatusBadRequest, fmt.Errorf("invalid download URL '%s'", req.URL))
+		return
+	}
+	dest, err := s.destPath(u, req.Dest)
+	if err != nil {
+		abort(c, http.StatusBadRequest, err)
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(dest), 0o750); err != nil {
+		abort(c, http.StatusInternalServerError, fmt.Errorf("failed to create download folder: %v", err))
+		return
+	}
+
+	// NOTE: resume partial downloads instead of prompting
+	d := download.NewDownload(s.conf.Proxy, s.conf.Insecure, false, true, false, false, false)
+	d.URL = req.URL
+	d.Sha1 = req.Sha1
+	d.DestName = dest
+
+	id := s.manager.Add(d)
+	status, err := s.manager.Status(id)
+	if err != nil {
+		abort(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.Header("Location", "/jobs/"+id)
+	c.IndentedJSON(http.StatusAccepted, status)
+}
+
+type jobsResponse struct {
+	Jobs []download.DownloadStatus `json:"jobs"`
+}
+
+func (s *server) listJobs(c *gin.Context) {
+	c.IndentedJSON(http.StatusOK, jobsResponse{Jobs: s.manager.List()})
+}
+
+func (s *server) getJob(c *gin.Context) {
+	status, err := s.manager.Status(c.Param("id"))
+	if err != nil {
+		abort(c, http.StatusNotFound, err)
+		return
+	}
+	c.IndentedJSON(http.StatusOK, status)
+}
+
+func (s *server) cancelJob(c *gin.Context) {
+	id := c.Param("id")
+	status, err := s.manager.Status(id)
+	if err != nil {
+		abort(c, http.StatusNotFound, err)
+		return
+	}
+	switch status.State {
+	case download.StateDone, download.StateFailed, download.StateCanceled:
+		if err := s.manager.Remove(id); err != nil {
+			abort(c, http.StatusConflict, err)
+			return
+		}
+		c.IndentedJSON(http.StatusOK, status)
+		return
+	}
+	if err := s.manager.Cancel(id); err != nil {
+		abort(c, http.StatusConflict, err)
+		return
+	}
+	status, _ = s.manager.Status(id)
+	c.IndentedJSON(http.StatusOK, status)
+}
@@ -0,0 +1,155 @@
+package rest_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/blacktop/ipsw/api/rest"
+	"github.com/blacktop/ipsw/internal/download"
+	"github.com/blacktop/ipsw/pkg/testsupport"
+	"github.com/gin-gonic/gin"
+)
+
+// do sends a request to h and decodes its JSON response into v (if not nil)
+func do(t *testing.T, h http.Handler, method, target, body string, v any) *httptest.ResponseRecorder {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(method, target, strings.NewReader(body)))
+	if v != nil {
+		if err := json.Unmarshal(rec.Body.Bytes(), v); err != nil {
+			t.Fatalf("%s %s: failed to decode response %q: %v", method, target, rec.Body.String(), err)
+		}
+	}
+	return rec
+}
+
+func TestHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	testsupport.NewFirmwareServer(t)
+	data := testsupport.FirmwareData()
+
+	output := t.TempDir()
+	manager := download.NewManager(1)
+	t.Cleanup(manager.Close)
+	h := rest.NewHandler(rest.Config{Output: output, Manager: manager})
+
+	var devices struct {
+		Devices []download.Device `json:"devices"`
+	}
+	if rec := do(t, h, http.MethodGet, "/devices", "", &devices); rec.Code != http.StatusOK {
+		t.Fatalf("GET /devices = %d: %s", rec.Code, rec.Body)
+	}
+	if len(devices.Devices) != 1 || devices.Devices[0].Identifier != "iPhone15,2" || len(devices.Devices[0].Firmwares) != 0 {
+		t.Fatalf("GET /devices = %v", devices.Devices)
+	}
+	if rec := do(t, h, http.MethodGet, "/device/iPhone0,0", "", nil); rec.Code != http.StatusNotFound {
+		t.Errorf("GET /device of an unknown device = %d, want 404", rec.Code)
+	}
+
+	var firmwares struct {
+		Firmwares []download.IPSW `json:"firmwares"`
+	}
+	if rec := do(t, h, http.MethodGet, "/device/iPhone15,2/firmwares?signed=true", "", &firmwares); rec.Code != http.StatusOK {
+		t.Fatalf("GET /device/iPhone15,2/firmwares = %d: %s", rec.Code, rec.Body)
+	}
+	if len(firmwares.Firmwares) != 1 || firmwares.Firmwares[0].BuildID != "21A329" {
+		t.Fatalf("GET /device/iPhone15,2/firmwares?signed=true = %v, want 21A329", firmwares.Firmwares)
+	}
+	if rec := do(t, h, http.MethodGet, "/device/iPhone15,2/firmwares?type=dmg", "", nil); rec.Code != http.StatusBadRequest {
+		t.Errorf("GET /device/iPhone15,2/firmwares?type=dmg = %d, want 400", rec.Code)
+	}
+
+	if rec := do(t, h, http.MethodPost, "/downloads", `{"url": "`+testsupport.FirmwareURL+`", "dest": "../escape.ipsw"}`, nil); rec.Code != http.StatusBadRequest {
+		t.Errorf("POST /downloads outside of the output folder = %d, want 400", rec.Code)
+	}
+	if rec := do(t, h, http.MethodPost, "/downloads", `{"identifier": "iPhone15,2"}`, nil); rec.Code != http.StatusBadRequest {
+		t.Errorf("POST /downloads without a build = %d, want 400", rec.Code)
+	}
+	var job download.DownloadStatus
+	rec := do(t, h, http.MethodPost, "/downloads", `{"identifier": "iPhone15,2", "build": "21A329", "dest": "ipsws/17.0.ipsw"}`, &job)
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("POST /downloads = %d: %s", rec.Code, rec.Body)
+	}
+	if len(job.ID) == 0 || rec.Header().Get("Location") != "/jobs/"+job.ID {
+		t.Fatalf("POST /downloads returned job %q at %q", job.ID, rec.Header().Get("Location"))
+	}
+
+	deadline := time.Now().Add(10 * time.Second)
+	for job.State != download.StateDone {
+		if job.State == download.StateFailed || time.Now().After(deadline) {
+			t.Fatalf("GET /jobs/%s = %+v, want a done download", job.ID, job)
+		}
+		time.Sleep(10 * time.Millisecond)
+		do(t, h, http.MethodGet, "/jobs/"+job.ID, "", &job)
+	}
+	if job.Downloaded != int64(len(data)) {
+		t.Errorf("GET /jobs/%s downloaded = %d, want %d", job.ID, job.Downloaded, len(data))
+	}
+	if got, _ := os.ReadFile(filepath.Join(output, "ipsws", "17.0.ipsw")); !bytes.Equal(got, data) {
+		t.Fatal("downloaded IPSW does not match")
+	}
+
+	var jobs struct {
+		Jobs []download.DownloadStatus `json:"jobs"`
+	}
+	do(t, h, http.MethodGet, "/jobs", "", &jobs)
+	if len(jobs.Jobs) != 1 || jobs.Jobs[0].ID != job.ID {
+		t.Fatalf("GET /jobs = %v, want job %s", jobs.Jobs, job.ID)
+	}
+	if rec := do(t, h, http.MethodDelete, "/jobs/"+job.ID, "", nil); rec.Code != http.StatusOK {
+		t.Errorf("DELETE /jobs/%s of a done download = %d, want 200", job.ID, rec.Code)
+	}
+	if rec := do(t, h, http.MethodGet, "/jobs/"+job.ID, "", nil); rec.Code != http.StatusNotFound {
+		t.Errorf("GET /jobs/%s of a removed download = %d, want 404", job.ID, rec.Code)
+	}
+	if rec := do(t, h, http.MethodGet, "/jobs/0", "", nil); rec.Code != http.StatusNotFound {
+		t.Errorf("GET /jobs/0 = %d, want 404", rec.Code)
+	}
+}
+
+func TestJobRetention(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	testsupport.NewFirmwareServer(t)
+
+	manager := download.NewManager(1)
+	manager.SetRetention(1)
+	t.Cleanup(manager.Close)
+	h := rest.NewHandler(rest.Config{Output: t.TempDir(), Manager: manager})
+
+	var ids []string
+	for _, dest := range []string{"first.ipsw", "second.ipsw"} {
+		var job download.DownloadStatus
+		if rec := do(t, h, http.MethodPost, "/downloads", `{"url": "`+testsupport.FirmwareURL+`", "dest": "`+dest+`"}`, &job); rec.Code != http.StatusAccepted {
+			t.Fatalf("POST /downloads = %d: %s", rec.Code, rec.Body)
+		}
+		deadline := time.Now().Add(10 * time.Second)
+		for job.State != download.StateDone {
+			if job.State == download.StateFailed || time.Now().After(deadline) {
+				t.Fatalf("GET /jobs/%s = %+v, want a done download", job.ID, job)
+			}
+			time.Sleep(10 * time.Millisecond)
+			do(t, h, http.MethodGet, "/jobs/"+job.ID, "", &job)
+		}
+		ids = append(ids, job.ID)
+	}
+
+	var jobs struct {
+		Jobs []download.DownloadStatus `json:"jobs"`
+	}
+	do(t, h, http.MethodGet, "/jobs", "", &jobs)
+	if len(jobs.Jobs) != 1 || jobs.Jobs[0].ID != ids[1] {
+		t.Fatalf("GET /jobs = %v, want only job %s", jobs.Jobs, ids[1])
+	}
+	if rec := do(t, h, http.MethodGet, "/jobs/"+ids[0], "", nil); rec.Code != http.StatusNotFound {
+		t.Errorf("GET /jobs/%s of a pruned download = %d, want 404", ids[0], rec.Code)
+	}
+}
@@ -0,0 +1,416 @@
+// The gRPC API of `ipsw serve --grpc`
+//
+// Regenerate the Go code with `go generate ./api/rpc/...` (needs protoc, protoc-gen-go and protoc-gen-go-grpc).
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             v4.24.3
+// source: ipsw.proto
+
+package ipswpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	IPSW_ListDevices_FullMethodName    = "/ipsw.v1.IPSW/ListDevices"
+	IPSW_GetDevice_FullMethodName      = "/ipsw.v1.IPSW/GetDevice"
+	IPSW_ListFirmwares_FullMethodName  = "/ipsw.v1.IPSW/ListFirmwares"
+	IPSW_SubmitDownload_FullMethodName = "/ipsw.v1.IPSW/SubmitDownload"
+	IPSW_GetDownload_FullMethodName    = "/ipsw.v1.IPSW/GetDownload"
+	IPSW_ListDownloads_FullMethodName  = "/ipsw.v1.IPSW/ListDownloads"
+	IPSW_CancelDownload_FullMethodName = "/ipsw.v1.IPSW/CancelDownload"
+	IPSW_WatchDownload_FullMethodName  = "/ipsw.v1.IPSW/WatchDownload"
+)
+
+// IPSWClient is the client API for IPSW service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type IPSWClient interface {
+	// ListDevices returns all devices (without their firmwares)
+	ListDevices(ctx context.Context, in *ListDevicesRequest, opts ...grpc.CallOption) (*ListDevicesResponse, error)
+	// GetDevice returns a device and its IPSWs
+	GetDevice(ctx context.Context, in *GetDeviceRequest, opts ...grpc.CallOption) (*Device, error)
+	// ListFirmwares returns the IPSWs or OTAs of a device
+	ListFirmwares(ctx context.Context, in *ListFirmwaresRequest, opts ...grpc.CallOption) (*ListFirmwaresResponse, error)
+	// SubmitDownload queues a download on the server and returns its status (with its job ID)
+	SubmitDownload(ctx context.Context, in *SubmitDownloadRequest, opts ...grpc.CallOption) (*DownloadStatus, error)
+	// GetDownload returns the status of a download
+	GetDownload(ctx context.Context, in *GetDownloadRequest, opts ...grpc.CallOption) (*DownloadStatus, error)
+	// ListDownloads returns the status of all downloads in the order they were submitted
+	ListDownloads(ctx context.Context, in *ListDownloadsRequest, opts ...grpc.CallOption) (*ListDownloadsResponse, error)
+	// CancelDownload cancels a queued or running download
+	CancelDownload(ctx context.Context, in *CancelDownloadRequest, opts ...grpc.CallOption) (*DownloadStatus, error)
+	// WatchDownload streams the progress of a download until it is done, failed or canceled
+	WatchDownload(ctx context.Context, in *WatchDownloadRequest, opts ...grpc.CallOption) (IPSW_WatchDownloadClient, error)
+}
+
+type iPSWClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewIPSWClient(cc grpc.ClientConnInterface) IPSWClient {
+	return &iPSWClient{cc}
+}
+
+func (c *iPSWClient) ListDevices(ctx context.Context, in *ListDevicesRequest, opts ...grpc.CallOption) (*ListDevicesResponse, error) {
+	out := new(ListDevicesResponse)
+	err := c.cc.Invoke(ctx, IPSW_ListDevices_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *iPSWClient) GetDevice(ctx context.Context, in *GetDeviceRequest, opts ...grpc.CallOption) (*Device, error) {
+	out := new(Device)
+	err := c.cc.Invoke(ctx, IPSW_GetDevice_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *iPSWClient) ListFirmwares(ctx context.Context, in *ListFirmwaresRequest, opts ...grpc.CallOption) (*ListFirmwaresResponse, error) {
+	out := new(ListFirmwaresResponse)
+	err := c.cc.Invoke(ctx, IPSW_ListFirmwares_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *iPSWClient) SubmitDownload(ctx context.Context, in *SubmitDownloadRequest, opts ...grpc.CallOption) (*DownloadStatus, error) {
+	out := new(DownloadStatus)
+	err := c.cc.Invoke(ctx, IPSW_SubmitDownload_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *iPSWClient) GetDownload(ctx context.Context, in *GetDownloadRequest, opts ...grpc.CallOption) (*DownloadStatus, error) {
+	out := new(DownloadStatus)
+	err := c.cc.Invoke(ctx, IPSW_GetDownload_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *iPSWClient) ListDownloads(ctx context.Context, in *ListDownloadsRequest, opts ...grpc.CallOption) (*ListDownloadsResponse, error) {
+	out := new(ListDownloadsResponse)
+	err := c.cc.Invoke(ctx, IPSW_ListDownloads_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *iPSWClient) CancelDownload(ctx context.Context, in *CancelDownloadRequest, opts ...grpc.CallOption) (*DownloadStatus, error) {
+	out := new(DownloadStatus)
+	err := c.cc.Invoke(ctx, IPSW_CancelDownload_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *iPSWClient) WatchDownload(ctx context.Context, in *WatchDownloadRequest, opts ...grpc.CallOption) (IPSW_WatchDownloadClient, error) {
+	stream, err := c.cc.NewStream(ctx, &IPSW_ServiceDesc.Streams[0], IPSW_WatchDownload_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &iPSWWatchDownloadClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type IPSW_WatchDownloadClient interface {
+	Recv() (*DownloadProgress, error)
+	grpc.ClientStream
+}
+
+type iPSWWatchDownloadClient struct {
+	grpc.ClientStream
+}
+
+func (x *iPSWWatchDownloadClient) Recv() (*DownloadProgress, error) {
+	m := new(DownloadProgress)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// IPSWServer is the server API for IPSW service.
+// All implementations must embed UnimplementedIPSWServer
+// for forward compatibility
+type IPSWServer interface {
+	// ListDevices returns all devices (without their firmwares)
+	ListDevices(context.Context, *ListDevicesRequest) (*ListDevicesResponse, error)
+	// GetDevice returns a device and its IPSWs
+	GetDevice(context.Context, *GetDeviceRequest) (*Device, error)
+	// ListFirmwares returns the IPSWs or OTAs of a device
+	ListFirmwares(context.Context, *ListFirmwaresRequest) (*ListFirmwaresResponse, error)
+	// SubmitDownload queues a download on the server and returns its status (with its job ID)
+	SubmitDownload(context.Context, *SubmitDownloadRequest) (*DownloadStatus, error)
+	// GetDownload returns the status of a download
+	GetDownload(context.Context, *GetDownloadRequest) (*DownloadStatus, error)
+	// ListDownloads returns the status of all downloads in the order they were submitted
+	ListDownloads(context.Context, *ListDownloadsRequest) (*ListDownloadsResponse, error)
+	// CancelDownload cancels a queued or running download
+	CancelDownload(context.Context, *CancelDownloadRequest) (*DownloadStatus, error)
+	// WatchDownload streams the progress of a download until it is done, failed or canceled
+	WatchDownload(*WatchDownloadRequest, IPSW_WatchDownloadServer) error
+	mustEmbedUnimplementedIPSWServer()
+}
+
+// UnimplementedIPSWServer must be embedded to have forward compatible implementations.
+type UnimplementedIPSWServer struct {
+}
+
+func (UnimplementedIPSWServer) ListDevices(context.Context, *ListDevicesRequest) (*ListDevicesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListDevices not implemented")
+}
+func (UnimplementedIPSWServer) GetDevice(context.Context, *GetDeviceRequest) (*Device, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetDevice not implemented")
+}
+func (UnimplementedIPSWServer) ListFirmwares(context.Context, *ListFirmwaresRequest) (*ListFirmwaresResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListFirmwares not implemented")
+}
+func (UnimplementedIPSWServer) SubmitDownload(context.Context, *SubmitDownloadRequest) (*DownloadStatus, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SubmitDownload not implemented")
+}
+func (UnimplementedIPSWServer) GetDownload(context.Context, *GetDownloadRequest) (*DownloadStatus, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetDownload not implemented")
+}
+func (UnimplementedIPSWServer) ListDownloads(context.Context, *ListDownloadsRequest) (*ListDownloadsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListDownloads not implemented")
+}
+func (UnimplementedIPSWServer) CancelDownload(context.Context, *CancelDownloadRequest) (*DownloadStatus, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CancelDownload not implemented")
+}
+func (UnimplementedIPSWServer) WatchDownload(*WatchDownloadRequest, IPSW_WatchDownloadServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchDownload not implemented")
+}
+func (UnimplementedIPSWServer) mustEmbedUnimplementedIPSWServer() {}
+
+// UnsafeIPSWServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to IPSWServer will
+// result in compilation errors.
+type UnsafeIPSWServer interface {
+	mustEmbedUnimplementedIPSWServer()
+}
+
+func RegisterIPSWServer(s grpc.ServiceRegistrar, srv IPSWServer) {
+	s.RegisterService(&IPSW_ServiceDesc, srv)
+}
+
+func _IPSW_ListDevices_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListDevicesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IPSWServer).ListDevices(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: IPSW_ListDevices_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IPSWServer).ListDevices(ctx, req.(*ListDevicesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _IPSW_GetDevice_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetDeviceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IPSWServer).GetDevice(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: IPSW_GetDevice_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IPSWServer).GetDevice(ctx, req.(*GetDeviceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _IPSW_ListFirmwares_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListFirmwaresRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IPSWServer).ListFirmwares(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: IPSW_ListFirmwares_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IPSWServer).ListFirmwares(ctx, req.(*ListFirmwaresRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _IPSW_SubmitDownload_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SubmitDownloadRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IPSWServer).SubmitDownload(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: IPSW_SubmitDownload_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IPSWServer).SubmitDownload(ctx, req.(*SubmitDownloadRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _IPSW_GetDownload_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetDownloadRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IPSWServer).GetDownload(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: IPSW_GetDownload_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IPSWServer).GetDownload(ctx, req.(*GetDownloadRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _IPSW_ListDownloads_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListDownloadsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IPSWServer).ListDownloads(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: IPSW_ListDownloads_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IPSWServer).ListDownloads(ctx, req.(*ListDownloadsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _IPSW_CancelDownload_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CancelDownloadRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IPSWServer).CancelDownload(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: IPSW_CancelDownload_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IPSWServer).CancelDownload(ctx, req.(*CancelDownloadRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _IPSW_WatchDownload_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchDownloadRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(IPSWServer).WatchDownload(m, &iPSWWatchDownloadServer{stream})
+}
+
+type IPSW_WatchDownloadServer interface {
+	Send(*DownloadProgress) error
+	grpc.ServerStream
+}
+
+type iPSWWatchDownloadServer struct {
+	grpc.ServerStream
+}
+
+func (x *iPSWWatchDownloadServer) Send(m *DownloadProgress) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// IPSW_ServiceDesc is the grpc.ServiceDesc for IPSW service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var IPSW_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "ipsw.v1.IPSW",
+	HandlerType: (*IPSWServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListDevices",
+			Handler:    _IPSW_ListDevices_Handler,
+		},
+		{
+			MethodName: "GetDevice",
+			Handler:    _IPSW_GetDevice_Handler,
+		},
+		{
+			MethodName: "ListFirmwares",
+			Handler:    _IPSW_ListFirmwares_Handler,
+		},
+		{
+			MethodName: "SubmitDownload",
+			Handler:    _IPSW_SubmitDownload_Handler,
+		},
+		{
+			MethodName: "GetDownload",
+			Handler:    _IPSW_GetDownload_Handler,
+		},
+		{
+			MethodName: "ListDownloads",
+			Handler:    _IPSW_ListDownloads_Handler,
+		},
+		{
+			MethodName: "CancelDownload",
+			Handler:    _IPSW_CancelDownload_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchDownload",
+			Handler:       _IPSW_WatchDownload_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "ipsw.proto",
+}
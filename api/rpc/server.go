@@ -0,0 +1,329 @@
+// Package rpc is the gRPC API of `ipsw serve --grpc` (see ipswpb/ipsw.proto)
+package rpc
+
+//go:generate protoc -I ipswpb --go_out=ipswpb --go_opt=paths=source_relative --go-grpc_out=ipswpb --go-grpc_opt=paths=source_relative ipswpb/ipsw.proto
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+
+	"github.com/blacktop/ipsw/api/rpc/ipswpb"
+	"github.com/blacktop/ipsw/internal/download"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	defaultWatchInterval = time.Second
+	minWatchInterval     = 100 * time.Millisecond
+)
+
+// Config is the gRPC server config
+type Config struct {
+	Output   string            // folder the downloads are saved to (defaults to the current folder)
+	Proxy    string            // HTTP/HTTPS proxy of the downloads
+	Insecure bool              // do not verify ssl certs
+	Manager  *download.Manager // the download queue (defaults to download.DefaultManager())
+}
+
+// Server implements the ipsw.v1.IPSW gRPC service
+type Server struct {
+	ipswpb.UnimplementedIPSWServer
+
+	conf    Config
+	manager *download.Manager
+}
+
+// NewServer creates a new gRPC service
+func NewServer(conf Config) *Server {
+	s := &Server{conf: conf, manager: conf.Manager}
+	if s.manager == nil {
+		s.manager = download.DefaultManager()
+	}
+	if len(s.conf.Output) == 0 {
+		s.conf.Output = "."
+	}
+	return s
+}
+
+// NewGRPCServer returns a gRPC server serving the service (and server reflection, i.e. for grpcurl)
+func NewGRPCServer(conf Config, opts ...grpc.ServerOption) *grpc.Server {
+	srv := grpc.NewServer(opts...)
+	ipswpb.RegisterIPSWServer(srv, NewServer(conf))
+	reflection.Register(srv)
+	return srv
+}
+
+// statusError converts err to a gRPC status error with the code matching its download.ErrorCode
+func statusError(err error) error {
+	code := codes.Unknown
+	switch download.ErrorCodeOf(err) {
+	case download.ErrCodeInvalidArgument:
+		code = codes.InvalidArgument
+	case download.ErrCodeNotFound:
+		code = codes.NotFound
+	case download.ErrCodeNetwork:
+		code = codes.Unavailable
+	case download.ErrCodeParse:
+		code = codes.Internal
+	case download.ErrCodeAuth:
+		code = codes.PermissionDenied
+	case download.ErrCodeCanceled:
+		code = codes.Canceled
+	case download.ErrCodeTimeout:
+		code = codes.DeadlineExceeded
+	case download.ErrCodeChecksum:
+		code = codes.DataLoss
+	case download.ErrCodeUnsupported:
+		code = codes.Unimplemented
+	}
+	return status.Error(code, err.Error())
+}
+
+func toDevice(d download.Device) *ipswpb.Device {
+	dev := &ipswpb.Device{
+		Name:        d.Name,
+		Identifier:  d.Identifier,
+		BoardConfig: d.BoardConfig,
+		Platform:    d.Platform,
+		Cpid:        int64(d.CpID),
+		Bdid:        int64(d.BdID),
+	}
+	for _, fw := range d.Firmwares {
+		dev.Firmwares = append(dev.Firmwares, toFirmware(fw))
+	}
+	return dev
+}
+
+func toFirmware(i download.IPSW) *ipswpb.Firmware {
+	fw := &ipswpb.Firmware{
+		Identifier: i.Identifier,
+		Version:    i.Version,
+		BuildId:    i.BuildID,
+		Sha1:       i.SHA1,
+		Md5:        i.MD5,
+		Size:       int64(i.FileSize),
+		Url:        i.URL,
+		Signed:     i.Signed,
+	}
+	if !i.ReleaseDate.IsZero() {
+		fw.ReleaseDate = timestamppb.New(i.ReleaseDate)
+	}
+	if !i.UploadDate.IsZero() {
+		fw.UploadDate = timestamppb.New(i.UploadDate)
+	}
+	return fw
+}
+
+var downloadStates = map[download.DownloadState]ipswpb.DownloadState{
+	download.StateQueued:   ipswpb.DownloadState_DOWNLOAD_STATE_QUEUED,
+	download.StateRunning:  ipswpb.DownloadState_DOWNLOAD_STATE_RUNNING,
+	download.StatePaused:   ipswpb.DownloadState_DOWNLOAD_STATE_PAUSED,
+	download.StateCanceled: ipswpb.DownloadState_DOWNLOAD_STATE_CANCELED,
+	download.StateDone:     ipswpb.DownloadState_DOWNLOAD_STATE_DONE,
+	download.StateFailed:   ipswpb.DownloadState_DOWNLOAD_STATE_FAILED,
+}
+
+func toDownloadStatus(s download.DownloadStatus) *ipswpb.DownloadStatus {
+	ds := &ipswpb.DownloadStatus{
+		Id:         s.ID,
+		Url:        s.URL,
+		Dest:       s.DestName,
+		State:      downloadStates[s.State],
+		Size:       s.Size,
+		Downloaded: s.Downloaded,
+		Error:      s.Error,
+	}
+	if s.Stats != nil {
+		ds.AvgSpeed = s.Stats.AvgSpeed
+		ds.Retries = int32(s.Stats.Retries)
+	}
+	return ds
+}
+
+func finished(state download.DownloadState) bool {
+	return state == download.StateDone || state == download.StateFailed || state == download.StateCanceled
+}
+
+// ListDevices returns all devices (without their firmwares)
+func (s *Server) ListDevices(ctx context.Context, _ *ipswpb.ListDevicesRequest) (*ipswpb.ListDevicesResponse, error) {
+	devices, err := download.GetAllDevicesWithContext(ctx)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	resp := &ipswpb.ListDevicesResponse{Devices: make([]*ipswpb.Device, 0, len(devices))}
+	for _, d := range devices {
+		d.Firmwares = nil
+		resp.Devices = append(resp.Devices, toDevice(d))
+	}
+	return resp, nil
+}
+
+// GetDevice returns a device and its IPSWs
+func (s *Server) GetDevice(ctx context.Context, req *ipswpb.GetDeviceRequest) (*ipswpb.Device, error) {
+	if len(req.GetIdentifier()) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "identifier is required")
+	}
+	d, err := download.GetDeviceWithContext(ctx, req.GetIdentifier())
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return toDevice(d), nil
+}
+
+// ListFirmwares returns the IPSWs or OTAs of a device
+func (s *Server) ListFirmwares(ctx context.Context, req *ipswpb.ListFirmwaresRequest) (*ipswpb.ListFirmwaresResponse, error) {
+	if len(req.GetIdentifier()) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "identifier is required")
+	}
+	var (
+		firmwares []download.IPSW
+		err       error
+	)
+	switch req.GetType() {
+	case ipswpb.FirmwareType_FIRMWARE_TYPE_OTA:
+		firmwares, err = download.GetDeviceOTAsWithContext(ctx, req.GetIdentifier())
+	default:
+		firmwares, err = download.GetDeviceIPSWsWithContext(ctx, req.GetIdentifier())
+	}
+	if err != nil {
+		return nil, statusError(err)
+	}
+	resp := &ipswpb.ListFirmwaresResponse{Firmwares: make([]*ipswpb.Firmware, 0, len(firmwares))}
+	for _, fw := range firmwares {
+		if req.GetSignedOnly() && !fw.Signed {
+			continue
+		}
+		resp.Firmwares = append(resp.Firmwares, toFirmware(fw))
+	}
+	return resp, nil
+}
+
+// destPath returns where to save the download of u to under the output folder
+func (s *Server) destPath(u *url.URL, dest string) (string, error) {
+	if len(dest) == 0 {
+		dest = path.Base(u.Path)
+	}
+	dest = filepath.Clean(filepath.FromSlash(dest))
+	if !filepath.IsLocal(dest) {
+		return "", fmt.Errorf("dest '%s' must be a relative path inside the server's output folder", dest)
+	}
+	return filepath.Join(s.conf.Output, dest), nil
+}
+
+// SubmitDownload queues a download and returns its status (with its job ID)
+func (s *Server) SubmitDownload(ctx context.Context, req *ipswpb.SubmitDownloadRequest) (*ipswpb.DownloadStatus, error) {
+	if len(req.GetUrl()) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "url is required")
+	}
+	u, err := url.Parse(req.GetUrl())
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid download URL '%s'", req.GetUrl())
+	}
+	dest, err := s.destPath(u, req.GetDest())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err := os.MkdirAll(filepath.Dir(dest), 0o750); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to create download folder: %v", err)
+	}
+
+	// NOTE: resume partial downloads instead of prompting
+	d := download.NewDownload(s.conf.Proxy, s.conf.Insecure, false, true, false, false, false)
+	d.URL = req.GetUrl()
+	d.Sha1 = req.GetSha1()
+	d.DestName = dest
+
+	return s.downloadStatus(s.manager.Add(d))
+}
+
+func (s *Server) downloadStatus(id string) (*ipswpb.DownloadStatus, error) {
+	st, err := s.manager.Status(id)
+	if err != nil {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	return toDownloadStatus(*st), nil
+}
+
+// GetDownload returns the status of a download
+func (s *Server) GetDownload(ctx context.Context, req *ipswpb.GetDownloadRequest) (*ipswpb.DownloadStatus, error) {
+	return s.downloadStatus(req.GetId())
+}
+
+// ListDownloads returns the status of all downloads in the order they were submitted
+func (s *Server) ListDownloads(ctx context.Context, _ *ipswpb.ListDownloadsRequest) (*ipswpb.ListDownloadsResponse, error) {
+	statuses := s.manager.List()
+	resp := &ipswpb.ListDownloadsResponse{Downloads: make([]*ipswpb.DownloadStatus, 0, len(statuses))}
+	for _, st := range statuses {
+		resp.Downloads = append(resp.Downloads, toDownloadStatus(st))
+	}
+	return resp, nil
+}
+
+// CancelDownload cancels a queued or running download
+func (s *Server) CancelDownload(ctx context.Context, req *ipswpb.CancelDownloadRequest) (*ipswpb.DownloadStatus, error) {
+	if _, err := s.manager.Get(req.GetId()); err != nil {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	if err := s.manager.Cancel(req.GetId()); err != nil {
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+	return s.downloadStatus(req.GetId())
+}
+
+// WatchDownload streams the progress of a download until it is done, failed or canceled
+func (s *Server) WatchDownload(req *ipswpb.WatchDownloadRequest, stream ipswpb.IPSW_WatchDownloadServer) error {
+	interval := defaultWatchInterval
+	if req.GetInterval() != nil {
+		interval = max(req.GetInterval().AsDuration(), minWatchInterval)
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var (
+		lastDone int64
+		lastTime time.Time
+	)
+	for {
+		st, err := s.manager.Status(req.GetId())
+		if err != nil {
+			return status.Error(codes.NotFound, err.Error())
+		}
+		progress := &ipswpb.DownloadProgress{
+			Id:    st.ID,
+			State: downloadStates[st.State],
+			Done:  st.Downloaded,
+			Total: st.Size,
+			Error: st.Error,
+		}
+		now := time.Now()
+		if !lastTime.IsZero() && st.Downloaded >= lastDone {
+			progress.Speed = float64(st.Downloaded-lastDone) / now.Sub(lastTime).Seconds()
+		}
+		if progress.Speed > 0 && st.Size > st.Downloaded {
+			eta := time.Duration(float64(st.Size-st.Downloaded) / progress.Speed * float64(time.Second))
+			progress.Eta = durationpb.New(eta.Round(time.Second))
+		}
+		lastDone, lastTime = st.Downloaded, now
+		if err := stream.Send(progress); err != nil {
+			return err
+		}
+		if finished(st.State) {
+			return nil
+		}
+		select {
+		case <-stream.Context().Done():
+			return status.FromContextError(stream.Context().Err()).Err()
+		case <-ticker.C:
+		}
+	}
+}
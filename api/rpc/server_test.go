@@ -0,0 +1,122 @@
+package rpc_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/blacktop/ipsw/api/rpc"
+	"github.com/blacktop/ipsw/api/rpc/ipswpb"
+	"github.com/blacktop/ipsw/internal/download"
+	"github.com/blacktop/ipsw/pkg/testsupport"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// newClient serves the IPSW service in-process and returns a client connected to it
+func newClient(t *testing.T, conf rpc.Config) ipswpb.IPSWClient {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	srv := rpc.NewGRPCServer(conf)
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return ipswpb.NewIPSWClient(conn)
+}
+
+func TestServer(t *testing.T) {
+	testsupport.NewFirmwareServer(t)
+	data := testsupport.FirmwareData()
+
+	output := t.TempDir()
+	manager := download.NewManager(1)
+	t.Cleanup(manager.Close)
+	client := newClient(t, rpc.Config{Output: output, Manager: manager})
+	ctx := context.Background()
+
+	devices, err := client.ListDevices(ctx, &ipswpb.ListDevicesRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(devices.GetDevices()) != 1 || devices.GetDevices()[0].GetIdentifier() != "iPhone15,2" {
+		t.Fatalf("ListDevices() = %v", devices.GetDevices())
+	}
+	if _, err := client.GetDevice(ctx, &ipswpb.GetDeviceRequest{Identifier: "iPhone0,0"}); status.Code(err) != codes.NotFound {
+		t.Errorf("GetDevice() of an unknown device = %v, want NotFound", err)
+	}
+	if _, err := client.GetDevice(ctx, &ipswpb.GetDeviceRequest{}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("GetDevice() without an identifier = %v, want InvalidArgument", err)
+	}
+
+	firmwares, err := client.ListFirmwares(ctx, &ipswpb.ListFirmwaresRequest{Identifier: "iPhone15,2", SignedOnly: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(firmwares.GetFirmwares()) != 1 || firmwares.GetFirmwares()[0].GetBuildId() != "21A329" {
+		t.Fatalf("ListFirmwares(signed_only) = %v, want 21A329", firmwares.GetFirmwares())
+	}
+	fw := firmwares.GetFirmwares()[0]
+
+	if _, err := client.SubmitDownload(ctx, &ipswpb.SubmitDownloadRequest{Url: fw.GetUrl(), Dest: "../escape.ipsw"}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("SubmitDownload() outside of the output folder = %v, want InvalidArgument", err)
+	}
+	job, err := client.SubmitDownload(ctx, &ipswpb.SubmitDownloadRequest{Url: fw.GetUrl(), Dest: "ipsws/17.0.ipsw", Sha1: fw.GetSha1()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(job.GetId()) == 0 {
+		t.Fatal("SubmitDownload() returned no job ID")
+	}
+
+	stream, err := client.WatchDownload(ctx, &ipswpb.WatchDownloadRequest{Id: job.GetId(), Interval: durationpb.New(0)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var last *ipswpb.DownloadProgress
+	for {
+		p, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		last = p
+	}
+	if last.GetState() != ipswpb.DownloadState_DOWNLOAD_STATE_DONE || last.GetDone() != int64(len(data)) {
+		t.Fatalf("last WatchDownload() progress = %v, want a done download of %d bytes", last, len(data))
+	}
+	if got, _ := os.ReadFile(filepath.Join(output, "ipsws", "17.0.ipsw")); !bytes.Equal(got, data) {
+		t.Fatal("downloaded IPSW does not match")
+	}
+
+	downloads, err := client.ListDownloads(ctx, &ipswpb.ListDownloadsRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(downloads.GetDownloads()) != 1 || downloads.GetDownloads()[0].GetId() != job.GetId() {
+		t.Fatalf("ListDownloads() = %v, want job %s", downloads.GetDownloads(), job.GetId())
+	}
+	if _, err := client.CancelDownload(ctx, &ipswpb.CancelDownloadRequest{Id: job.GetId()}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("CancelDownload() of a done download = %v, want FailedPrecondition", err)
+	}
+	if _, err := client.GetDownload(ctx, &ipswpb.GetDownloadRequest{Id: "0"}); status.Code(err) != codes.NotFound {
+		t.Errorf("GetDownload() of an unknown job = %v, want NotFound", err)
+	}
+}
@@ -0,0 +1,55 @@
+package download
+
+import (
+	"net/http"
+
+	"github.com/blacktop/ipsw/api/types"
+	"github.com/blacktop/ipsw/internal/download"
+	"github.com/gin-gonic/gin"
+)
+
+// swagger:parameters getDownloadOtaDelta
+type otaDeltaParams struct {
+	// device identifier (i.e. iPhone15,2)
+	// in:query
+	// required: true
+	Device string `form:"device" json:"device" binding:"required"`
+	// build the device is currently running
+	// in:query
+	// required: true
+	From string `form:"from" json:"from" binding:"required"`
+	// build to update to
+	// in:query
+	// required: true
+	To string `form:"to" json:"to" binding:"required"`
+	// HTTP/HTTPS proxy
+	// in:query
+	Proxy string `form:"proxy" json:"proxy"`
+	// do not verify ssl certs
+	// in:query
+	Insecure bool `form:"insecure" json:"insecure"`
+}
+
+// swagger:response
+type otaDeltaResponse struct {
+	// The delta and full OTA assets for the update
+	// in:body
+	download.OtaUpdate
+	// The smallest asset that performs the update
+	// in:body
+	Best *download.OtaArtifact `json:"best"`
+}
+
+func otaDelta(c *gin.Context) {
+	var params otaDeltaParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, types.GenericError{Error: err.Error()})
+		return
+	}
+	update, err := download.GetOtaUpdate(params.Device, params.From, params.To, params.Proxy, params.Insecure)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, types.GenericError{Error: err.Error()})
+		return
+	}
+	c.IndentedJSON(http.StatusOK, otaDeltaResponse{OtaUpdate: *update, Best: update.Best()})
+}
@@ -0,0 +1,121 @@
+package download
+
+import (
+	"net/http"
+	"net/url"
+	"path"
+	"path/filepath"
+
+	"github.com/blacktop/ipsw/api/types"
+	"github.com/blacktop/ipsw/internal/download"
+	"github.com/gin-gonic/gin"
+)
+
+// swagger:parameters postDownloadQueue
+type downloadQueueParams struct {
+	// URL to download
+	// in:body
+	// required: true
+	URL string `json:"url" binding:"required"`
+	// path to save the download to (defaults to the URL's filename)
+	// in:body
+	Dest string `json:"dest"`
+	// expected sha1 of the download
+	// in:body
+	Sha1 string `json:"sha1"`
+	// HTTP/HTTPS proxy
+	// in:body
+	Proxy string `json:"proxy"`
+	// do not verify ssl certs
+	// in:body
+	Insecure bool `json:"insecure"`
+}
+
+// swagger:response
+type downloadQueueResponse struct {
+	// The download's status
+	// in:body
+	download.DownloadStatus
+}
+
+// swagger:response
+type downloadQueueListResponse struct {
+	// The status of all queued downloads
+	// in:body
+	Downloads []download.DownloadStatus `json:"downloads"`
+}
+
+func queueDownload(c *gin.Context) {
+	var params downloadQueueParams
+	if err := c.ShouldBindJSON(&params); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, types.GenericError{Error: err.Error()})
+		return
+	}
+
+	u, err := url.Parse(params.URL)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, types.GenericError{Error: err.Error()})
+		return
+	}
+	if len(params.Dest) == 0 {
+		params.Dest = path.Base(u.Path)
+	}
+
+	// NOTE: resume partial downloads instead of prompting
+	d := download.NewDownload(params.Proxy, params.Insecure, false, true, false, false, false)
+	d.URL = params.URL
+	d.Sha1 = params.Sha1
+	d.DestName = filepath.Clean(params.Dest)
+
+	id := download.DefaultManager().Add(d)
+
+	status, err := download.DefaultManager().Status(id)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, types.GenericError{Error: err.Error()})
+		return
+	}
+	c.IndentedJSON(http.StatusOK, downloadQueueResponse{*status})
+}
+
+func listDownloads(c *gin.Context) {
+	c.IndentedJSON(http.StatusOK, downloadQueueListResponse{Downloads: download.DefaultManager().List()})
+}
+
+func getDownload(c *gin.Context) {
+	status, err := download.DefaultManager().Status(c.Param("id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, types.GenericError{Error: err.Error()})
+		return
+	}
+	c.IndentedJSON(http.StatusOK, downloadQueueResponse{*status})
+}
+
+func removeDownload(c *gin.Context) {
+	id := c.Param("id")
+	status, err := download.DefaultManager().Status(id)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, types.GenericError{Error: err.Error()})
+		return
+	}
+	if err := download.DefaultManager().Remove(id); err != nil {
+		c.AbortWithStatusJSON(http.StatusConflict, types.GenericError{Error: err.Error()})
+		return
+	}
+	c.IndentedJSON(http.StatusOK, downloadQueueResponse{*status})
+}
+
+func controlDownload(action func(*download.Manager, string) error) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.Param("id")
+		if _, err := download.DefaultManager().Get(id); err != nil {
+			c.AbortWithStatusJSON(http.StatusNotFound, types.GenericError{Error: err.Error()})
+			return
+		}
+		if err := action(download.DefaultManager(), id); err != nil {
+			c.AbortWithStatusJSON(http.StatusConflict, types.GenericError{Error: err.Error()})
+			return
+		}
+		status, _ := download.DefaultManager().Status(id)
+		c.IndentedJSON(http.StatusOK, downloadQueueResponse{*status})
+	}
+}
@@ -2,6 +2,7 @@
 package download
 
 import (
+	"github.com/blacktop/ipsw/internal/download"
 	"github.com/gin-gonic/gin"
 )
 
@@ -35,6 +36,98 @@ func AddRoutes(rg *gin.RouterGroup) {
 	//       200: latestIpswIosBuildResponse
 	//       500: genericError
 	dl.GET("/ipsw/ios/latest/build", latestBuild)
+	// swagger:route GET /download/ota/delta Download getDownloadOtaDelta
+	//
+	// OTA Delta
+	//
+	// Check if a delta OTA exists to update a device from one build to another and resolve it alongside the full OTA.
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       200: otaDeltaResponse
+	//       400: genericError
+	//       500: genericError
+	dl.GET("/ota/delta", otaDelta)
+
+	// swagger:route POST /download/queue Download postDownloadQueue
+	//
+	// Queue Download
+	//
+	// Add a URL to the download queue.
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       200: downloadQueueResponse
+	//       400: genericError
+	//       500: genericError
+	dl.POST("/queue", queueDownload)
+	// swagger:route GET /download/queue Download getDownloadQueue
+	//
+	// List Downloads
+	//
+	// Get the status of all queued downloads.
+	//
+	//     Responses:
+	//       200: downloadQueueListResponse
+	dl.GET("/queue", listDownloads)
+	// swagger:route GET /download/queue/{id} Download getDownloadQueueID
+	//
+	// Download Status
+	//
+	// Get the status of a queued download.
+	//
+	//     Responses:
+	//       200: downloadQueueResponse
+	//       404: genericError
+	dl.GET("/queue/:id", getDownload)
+	// swagger:route POST /download/queue/{id}/pause Download postDownloadQueuePause
+	//
+	// Pause Download
+	//
+	// Pause a queued or running download.
+	//
+	//     Responses:
+	//       200: downloadQueueResponse
+	//       404: genericError
+	//       409: genericError
+	dl.POST("/queue/:id/pause", controlDownload((*download.Manager).Pause))
+	// swagger:route POST /download/queue/{id}/resume Download postDownloadQueueResume
+	//
+	// Resume Download
+	//
+	// Resume a paused download.
+	//
+	//     Responses:
+	//       200: downloadQueueResponse
+	//       404: genericError
+	//       409: genericError
+	dl.POST("/queue/:id/resume", controlDownload((*download.Manager).Resume))
+	// swagger:route POST /download/queue/{id}/cancel Download postDownloadQueueCancel
+	//
+	// Cancel Download
+	//
+	// Cancel a download and remove the partial download.
+	//
+	//     Responses:
+	//       200: downloadQueueResponse
+	//       404: genericError
+	//       409: genericError
+	dl.POST("/queue/:id/cancel", controlDownload((*download.Manager).Cancel))
+	// swagger:route DELETE /download/queue/{id} Download deleteDownloadQueue
+	//
+	// Remove Download
+	//
+	// Remove a finished, failed or canceled download from the queue.
+	//
+	//     Responses:
+	//       200: downloadQueueResponse
+	//       404: genericError
+	//       409: genericError
+	dl.DELETE("/queue/:id", removeDownload)
 
 	// dl.GET("/macos", handler) // TODO:
 	// dl.GET("/ota", handler)   // TODO:
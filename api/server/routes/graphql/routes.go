@@ -0,0 +1,100 @@
+// Package graphql contains the /graphql routes for the API
+package graphql
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/blacktop/ipsw/api/types"
+	"github.com/blacktop/ipsw/internal/graphql"
+	"github.com/gin-gonic/gin"
+)
+
+// swagger:parameters postGraphQL
+type graphqlParams struct {
+	// in:body
+	Body graphql.Request
+}
+
+// swagger:response
+type graphqlResponse graphql.Response
+
+// AddRoutes adds the graphql routes to the router
+func AddRoutes(rg *gin.RouterGroup) {
+	// swagger:route POST /graphql GraphQL postGraphQL
+	//
+	// Query Metadata.
+	//
+	// This will execute a GraphQL query over the devices, firmwares, components and signing status.
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       200: graphqlResponse
+	//       400: genericError
+	rg.POST("/graphql", func(c *gin.Context) {
+		var req graphql.Request
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, types.GenericError{Error: err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, Schema.Do(c.Request.Context(), req))
+	})
+	// swagger:route GET /graphql GraphQL getGraphQL
+	//
+	// Query Metadata.
+	//
+	// This will execute the GraphQL query in the 'query' parameter (with optional JSON 'variables').
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Parameters:
+	//       + name: query
+	//         in: query
+	//         description: GraphQL query
+	//         required: true
+	//         type: string
+	//       + name: operationName
+	//         in: query
+	//         description: operation to execute
+	//         required: false
+	//         type: string
+	//       + name: variables
+	//         in: query
+	//         description: JSON object of the query variables
+	//         required: false
+	//         type: string
+	//
+	//     Responses:
+	//       200: graphqlResponse
+	//       400: genericError
+	rg.GET("/graphql", func(c *gin.Context) {
+		req := graphql.Request{
+			Query:         c.Query("query"),
+			OperationName: c.Query("operationName"),
+		}
+		if vars := c.Query("variables"); len(vars) > 0 {
+			if err := json.Unmarshal([]byte(vars), &req.Variables); err != nil {
+				c.AbortWithStatusJSON(http.StatusBadRequest, types.GenericError{Error: "invalid variables: " + err.Error()})
+				return
+			}
+		}
+		c.JSON(http.StatusOK, Schema.Do(c.Request.Context(), req))
+	})
+	// swagger:route GET /graphql/schema GraphQL getGraphQLSchema
+	//
+	// GraphQL Schema.
+	//
+	// This will return the GraphQL schema (SDL) of the /graphql endpoint.
+	//
+	//     Produces:
+	//     - text/plain
+	//
+	//     Responses:
+	//       200: description:GraphQL schema definition
+	rg.GET("/graphql/schema", func(c *gin.Context) {
+		c.String(http.StatusOK, Schema.SDL())
+	})
+}
@@ -0,0 +1,315 @@
+package graphql
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/blacktop/ipsw/internal/db"
+	"github.com/blacktop/ipsw/internal/download"
+	"github.com/blacktop/ipsw/internal/graphql"
+	"github.com/blacktop/ipsw/internal/models"
+	"github.com/blacktop/ipsw/pkg/info"
+)
+
+type device struct {
+	Identifier string `json:"identifier"`
+	info.Device
+}
+
+type component struct {
+	Model string `json:"model"`
+	info.Board
+}
+
+type firmware struct {
+	download.IPSW
+}
+
+var (
+	devicesOnce sync.Once
+	devices     *info.Devices
+	devicesErr  error
+)
+
+func getDevices() (*info.Devices, error) {
+	devicesOnce.Do(func() {
+		devices, devicesErr = info.GetIpswDB()
+	})
+	return devices, devicesErr
+}
+
+func lookupDevice(identifier string) (*device, error) {
+	ds, err := getDevices()
+	if err != nil {
+		return nil, err
+	}
+	d, ok := (*ds)[identifier]
+	if !ok {
+		return nil, nil
+	}
+	return &device{Identifier: identifier, Device: d}, nil
+}
+
+// signingWindows returns the signing windows recorded by `ipsw signed` (none if it was never run)
+func signingWindows(device, build string) ([]models.SigningWindow, error) {
+	path, err := db.DefaultSigningPath()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return []models.SigningWindow{}, nil
+	}
+	sdb, err := db.NewSigningDB(path)
+	if err != nil {
+		return nil, err
+	}
+	defer sdb.Close()
+	return sdb.History(device, build)
+}
+
+func formatTime(t time.Time) any {
+	if t.IsZero() {
+		return nil
+	}
+	return t.Format(time.RFC3339)
+}
+
+var signingWindowType = &graphql.Object{
+	Name:        "SigningWindow",
+	Description: "A period during which Apple signed a firmware build for a device",
+	Fields: []*graphql.Field{
+		{Name: "device", Type: "String!"},
+		{Name: "version", Type: "String!"},
+		{Name: "build", Type: "String!"},
+		{Name: "start", Type: "String!", Description: "First TSS check that found the build signed (RFC3339)",
+			Resolve: func(p graphql.Params) (any, error) {
+				return formatTime(p.Source.(models.SigningWindow).Start), nil
+			}},
+		{Name: "stop", Type: "String", Description: "First TSS check that found the build unsigned (RFC3339)",
+			Resolve: func(p graphql.Params) (any, error) {
+				if stop := p.Source.(models.SigningWindow).Stop; stop != nil {
+					return formatTime(*stop), nil
+				}
+				return nil, nil
+			}},
+		{Name: "lastChecked", Type: "String", Description: "Last TSS check (RFC3339)",
+			Resolve: func(p graphql.Params) (any, error) {
+				return formatTime(p.Source.(models.SigningWindow).LastChecked), nil
+			}},
+		{Name: "open", Type: "Boolean!", Description: "Whether the build is still being signed",
+			Resolve: func(p graphql.Params) (any, error) {
+				return p.Source.(models.SigningWindow).Open(), nil
+			}},
+	},
+}
+
+var componentType = &graphql.Object{
+	Name:        "Component",
+	Description: "A board (hardware model) of a device",
+	Fields: []*graphql.Field{
+		{Name: "model", Type: "String!", Description: "Board config (i.e. d73ap)"},
+		{Name: "cpu", Type: "String"},
+		{Name: "platform", Type: "String"},
+		{Name: "platformName", Type: "String", Resolve: func(p graphql.Params) (any, error) {
+			return p.Source.(component).PlatformName, nil
+		}},
+		{Name: "chipId", Type: "String", Resolve: func(p graphql.Params) (any, error) {
+			return p.Source.(component).ChipID, nil
+		}},
+		{Name: "boardId", Type: "String", Resolve: func(p graphql.Params) (any, error) {
+			return p.Source.(component).BoardID, nil
+		}},
+		{Name: "arch", Type: "String"},
+		{Name: "cpuIsa", Type: "String", Resolve: func(p graphql.Params) (any, error) {
+			return p.Source.(component).CpuISA, nil
+		}},
+		{Name: "basebandChipId", Type: "String", Resolve: func(p graphql.Params) (any, error) {
+			return p.Source.(component).BasebandChipID, nil
+		}},
+		{Name: "kernelCacheType", Type: "String", Resolve: func(p graphql.Params) (any, error) {
+			return p.Source.(component).KernelCacheType, nil
+		}},
+		{Name: "researchSupported", Type: "Boolean!", Resolve: func(p graphql.Params) (any, error) {
+			return p.Source.(component).ResearchSupported, nil
+		}},
+	},
+}
+
+var firmwareType = &graphql.Object{
+	Name:        "Firmware",
+	Description: "An IPSW firmware release (from ipsw.me)",
+	Fields: []*graphql.Field{
+		{Name: "identifier", Type: "String!", Description: "Device identifier (i.e. iPhone15,2)"},
+		{Name: "version", Type: "String!"},
+		{Name: "build", Type: "String!", Resolve: func(p graphql.Params) (any, error) {
+			return p.Source.(firmware).BuildID, nil
+		}},
+		{Name: "url", Type: "String"},
+		{Name: "sha1", Type: "String", Resolve: func(p graphql.Params) (any, error) {
+			return p.Source.(firmware).SHA1, nil
+		}},
+		{Name: "size", Type: "Float", Description: "Size in bytes", Resolve: func(p graphql.Params) (any, error) {
+			return float64(p.Source.(firmware).FileSize), nil
+		}},
+		{Name: "releaseDate", Type: "String", Description: "Release date (RFC3339)", Resolve: func(p graphql.Params) (any, error) {
+			return formatTime(p.Source.(firmware).ReleaseDate), nil
+		}},
+		{Name: "signed", Type: "Boolean!", Description: "Whether ipsw.me reports the build as signed"},
+		{Name: "signing", Type: "[SigningWindow!]!", Description: "Recorded signing windows of the build", Resolve: func(p graphql.Params) (any, error) {
+			fw := p.Source.(firmware)
+			return signingWindows(fw.Identifier, fw.BuildID)
+		}},
+	},
+}
+
+var deviceType = &graphql.Object{
+	Name:        "Device",
+	Description: "An Apple device",
+	Fields: []*graphql.Field{
+		{Name: "identifier", Type: "String!", Description: "Product type (i.e. iPhone15,2)"},
+		{Name: "name", Type: "String"},
+		{Name: "description", Type: "String", Resolve: func(p graphql.Params) (any, error) {
+			return p.Source.(*device).Description, nil
+		}},
+		{Name: "type", Type: "String"},
+		{Name: "sdk", Type: "String"},
+		{Name: "memClass", Type: "Int", Resolve: func(p graphql.Params) (any, error) {
+			return int(p.Source.(*device).MemClass), nil
+		}},
+		{Name: "aNumbers", Type: "[String!]!", Resolve: func(p graphql.Params) (any, error) {
+			return append([]string{}, p.Source.(*device).ANumbers...), nil
+		}},
+		{Name: "modelNumbers", Type: "[String!]!", Resolve: func(p graphql.Params) (any, error) {
+			return append([]string{}, p.Source.(*device).ModelNumbers...), nil
+		}},
+		{Name: "components", Type: "[Component!]!", Resolve: func(p graphql.Params) (any, error) {
+			d := p.Source.(*device)
+			var boards []string
+			for model := range d.Boards {
+				boards = append(boards, model)
+			}
+			sort.Strings(boards)
+			comps := []component{}
+			for _, model := range boards {
+				comps = append(comps, component{Model: model, Board: d.Boards[model]})
+			}
+			return comps, nil
+		}},
+		{Name: "firmwares", Type: "[Firmware!]!",
+			Args: []*graphql.Arg{
+				{Name: "version", Type: "String"},
+				{Name: "build", Type: "String"},
+			},
+			Resolve: func(p graphql.Params) (any, error) {
+				return getFirmwares(p.Source.(*device).Identifier, p.String("version"), p.String("build"))
+			}},
+		{Name: "signing", Type: "[SigningWindow!]!",
+			Args: []*graphql.Arg{
+				{Name: "build", Type: "String"},
+				{Name: "open", Type: "Boolean", Description: "Only return the windows that are still open (or closed)"},
+			},
+			Resolve: func(p graphql.Params) (any, error) {
+				return getSigning(p.Source.(*device).Identifier, p)
+			}},
+	},
+}
+
+var queryType = &graphql.Object{
+	Name: "Query",
+	Fields: []*graphql.Field{
+		{Name: "device", Type: "Device", Description: "Look up a device by its identifier",
+			Args: []*graphql.Arg{{Name: "identifier", Type: "String!"}},
+			Resolve: func(p graphql.Params) (any, error) {
+				return lookupDevice(p.String("identifier"))
+			}},
+		{Name: "devices", Type: "[Device!]!", Description: "List the devices (optionally filtered by type or a name substring)",
+			Args: []*graphql.Arg{
+				{Name: "type", Type: "String", Description: "Device type (i.e. iPhone)"},
+				{Name: "name", Type: "String", Description: "Case-insensitive substring of the device name"},
+			},
+			Resolve: func(p graphql.Params) (any, error) {
+				ds, err := getDevices()
+				if err != nil {
+					return nil, err
+				}
+				var ids []string
+				for id := range *ds {
+					ids = append(ids, id)
+				}
+				sort.Strings(ids)
+				devs := []*device{}
+				for _, id := range ids {
+					d := (*ds)[id]
+					if p.Has("type") && !strings.EqualFold(d.Type, p.String("type")) {
+						continue
+					}
+					if p.Has("name") && !strings.Contains(strings.ToLower(d.Name), strings.ToLower(p.String("name"))) {
+						continue
+					}
+					devs = append(devs, &device{Identifier: id, Device: d})
+				}
+				return devs, nil
+			}},
+		{Name: "firmwares", Type: "[Firmware!]!", Description: "List a device's IPSW firmwares",
+			Args: []*graphql.Arg{
+				{Name: "device", Type: "String!"},
+				{Name: "version", Type: "String"},
+				{Name: "build", Type: "String"},
+			},
+			Resolve: func(p graphql.Params) (any, error) {
+				return getFirmwares(p.String("device"), p.String("version"), p.String("build"))
+			}},
+		{Name: "signing", Type: "[SigningWindow!]!", Description: "List the recorded signing windows",
+			Args: []*graphql.Arg{
+				{Name: "device", Type: "String"},
+				{Name: "build", Type: "String"},
+				{Name: "open", Type: "Boolean", Description: "Only return the windows that are still open (or closed)"},
+			},
+			Resolve: func(p graphql.Params) (any, error) {
+				return getSigning(p.String("device"), p)
+			}},
+	},
+}
+
+func getFirmwares(identifier, version, build string) ([]firmware, error) {
+	ipsws, err := download.GetDeviceIPSWs(identifier)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get firmwares for %s: %v", identifier, err)
+	}
+	fws := []firmware{}
+	for _, i := range ipsws {
+		if (len(version) > 0 && i.Version != version) || (len(build) > 0 && i.BuildID != build) {
+			continue
+		}
+		fws = append(fws, firmware{IPSW: i})
+	}
+	return fws, nil
+}
+
+func getSigning(device string, p graphql.Params) ([]models.SigningWindow, error) {
+	windows, err := signingWindows(device, p.String("build"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get signing windows: %v", err)
+	}
+	out := []models.SigningWindow{}
+	for _, w := range windows {
+		if p.Has("open") && w.Open() != p.Bool("open") {
+			continue
+		}
+		out = append(out, w)
+	}
+	return out, nil
+}
+
+// Schema is the metadata GraphQL schema
+var Schema = func() *graphql.Schema {
+	s, err := graphql.NewSchema(queryType, deviceType, componentType, firmwareType, signingWindowType)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}()
@@ -164,3 +164,21 @@ func getFsLaunchdConfig(c *gin.Context) {
 
 	c.IndentedJSON(http.StatusOK, getFsLaunchdConfigResponse{Path: ipswPath, LaunchdConfig: ldconf})
 }
+
+// swagger:response
+type getWebKitResponse struct {
+	Path string `json:"path"`
+	*extract.WebKitVersions
+}
+
+func getWebKit(c *gin.Context) {
+	ipswPath := c.Query("path")
+
+	wk, err := extract.WebKit(ipswPath)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, types.GenericError{Error: err.Error()})
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, getWebKitResponse{Path: ipswPath, WebKitVersions: wk})
+}
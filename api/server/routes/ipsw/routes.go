@@ -67,4 +67,24 @@ func AddRoutes(rg *gin.RouterGroup) {
 	//       200: getFsLaunchdConfigResponse
 	//       500: genericError
 	dl.GET("/fs/launchd", getFsLaunchdConfig)
+	// swagger:route GET /ipsw/webkit IPSW getIpswWebKit
+	//
+	// WebKit
+	//
+	// Get the WebKit and Safari versions shipped in the IPSW.
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Parameters:
+	//       + name: path
+	//         in: query
+	//         description: path to IPSW
+	//         required: true
+	//         type: string
+	//
+	//     Responses:
+	//       200: getWebKitResponse
+	//       500: genericError
+	dl.GET("/webkit", getWebKit)
 }
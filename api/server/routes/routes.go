@@ -8,6 +8,7 @@ import (
 	"github.com/blacktop/ipsw/api/server/routes/download"
 	"github.com/blacktop/ipsw/api/server/routes/dsc"
 	"github.com/blacktop/ipsw/api/server/routes/extract"
+	"github.com/blacktop/ipsw/api/server/routes/graphql"
 	"github.com/blacktop/ipsw/api/server/routes/idev"
 	"github.com/blacktop/ipsw/api/server/routes/info"
 	"github.com/blacktop/ipsw/api/server/routes/ipsw"
@@ -26,6 +27,7 @@ func Add(rg *gin.RouterGroup) {
 	// dtree.AddRoutes(rg) // TODO: add dtree routes
 	dsc.AddRoutes(rg)
 	extract.AddRoutes(rg)
+	graphql.AddRoutes(rg)
 	idev.AddRoutes(rg)
 	// img4.AddRoutes(rg) // TODO: add img4 routes
 	info.AddRoutes(rg)
@@ -0,0 +1,116 @@
+/*
+Copyright © 2023 blacktop
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/apex/log"
+	"github.com/blacktop/ipsw/pkg/aea"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func init() {
+	rootCmd.AddCommand(aeaCmd)
+
+	aeaCmd.Flags().BoolP("info", "i", false, "Print AEA header and auth data")
+	aeaCmd.Flags().StringP("key", "k", "", "Base64 encoded symmetric key (i.e. OTA ArchiveDecryptionKey)")
+	aeaCmd.Flags().String("pem", "", "PEM encoded fcs-key private key (default: fetched from the archive's fcs-key-url)")
+	aeaCmd.Flags().StringP("output", "o", "", "Folder to write decrypted file to")
+	aeaCmd.Flags().String("proxy", "", "HTTP/HTTPS proxy")
+	aeaCmd.Flags().Bool("insecure", false, "do not verify ssl certs")
+	viper.BindPFlag("aea.info", aeaCmd.Flags().Lookup("info"))
+	viper.BindPFlag("aea.key", aeaCmd.Flags().Lookup("key"))
+	viper.BindPFlag("aea.pem", aeaCmd.Flags().Lookup("pem"))
+	viper.BindPFlag("aea.output", aeaCmd.Flags().Lookup("output"))
+	viper.BindPFlag("aea.proxy", aeaCmd.Flags().Lookup("proxy"))
+	viper.BindPFlag("aea.insecure", aeaCmd.Flags().Lookup("insecure"))
+
+	aeaCmd.MarkFlagFilename("pem", "pem")
+	aeaCmd.MarkFlagDirname("output")
+}
+
+// aeaCmd represents the aea command
+var aeaCmd = &cobra.Command{
+	Use:   "aea <AEA>",
+	Short: "Decrypt Apple Encrypted Archives (AEA)",
+	Example: `  # Decrypt an IPSW DMG (the fcs-key is fetched from Apple)
+  ❯ ipsw aea 090-34187-052.dmg.aea
+  # Decrypt an OTA with its ArchiveDecryptionKey
+  ❯ ipsw aea --key "<BASE64_KEY>" iPhone16,1_OTA.aea`,
+	Args:          cobra.ExactArgs(1),
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+
+		if Verbose {
+			log.SetLevel(log.DebugLevel)
+		}
+
+		if viper.GetBool("aea.info") {
+			a, err := aea.Open(args[0])
+			if err != nil {
+				return err
+			}
+			defer a.Close()
+			fmt.Printf("%s %s\n", color.New(color.Bold).Sprint("Profile:"), fmt.Sprint(a.Profile()))
+			fmt.Println(color.New(color.Bold).Sprint("Auth Data:"))
+			var keys []string
+			for k := range a.Metadata {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				fmt.Printf("  %s: %s\n", color.New(color.FgHiBlue).Sprint(k), string(a.Metadata[k]))
+			}
+			return nil
+		}
+
+		conf := &aea.DecryptConfig{
+			Input:     args[0],
+			Output:    viper.GetString("aea.output"),
+			B64SymKey: viper.GetString("aea.key"),
+			Proxy:     viper.GetString("aea.proxy"),
+			Insecure:  viper.GetBool("aea.insecure"),
+		}
+		if len(viper.GetString("aea.pem")) > 0 {
+			var err error
+			conf.PemKey, err = os.ReadFile(viper.GetString("aea.pem"))
+			if err != nil {
+				return fmt.Errorf("failed to read PEM key: %v", err)
+			}
+		}
+
+		log.Infof("Decrypting %s", args[0])
+		fname, err := aea.Decrypt(conf)
+		if err != nil {
+			return err
+		}
+		log.Infof("Created %s", fname)
+
+		return nil
+	},
+}
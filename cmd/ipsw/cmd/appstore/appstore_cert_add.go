@@ -27,6 +27,7 @@ import (
 	"path/filepath"
 
 	"github.com/apex/log"
+	"github.com/blacktop/ipsw/internal/utils"
 	"github.com/blacktop/ipsw/pkg/appstore"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -90,7 +91,7 @@ var ASCertAddCmd = &cobra.Command{
 		}
 
 		log.Info("Certificate:")
-		log.Infof("%s: %s (%s), Expires: %s", cert.ID, cert.Attributes.Name, cert.Attributes.CertificateType, cert.Attributes.ExpirationDate.Format("02Jan2006 15:04:05"))
+		log.Infof("%s: %s (%s), Expires: %s", cert.ID, cert.Attributes.Name, cert.Attributes.CertificateType, utils.FormatTime(cert.Attributes.ExpirationDate.Time()))
 		fname := fmt.Sprintf("%s_%s.cer", cert.Attributes.Name, cert.Attributes.ExpirationDate.Format("2006-01-02"))
 		if output != "" {
 			if err := os.MkdirAll(output, os.ModePerm); err != nil {
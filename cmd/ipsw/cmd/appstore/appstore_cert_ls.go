@@ -72,7 +72,7 @@ var ASCertListCmd = &cobra.Command{
 
 		log.Info("Certificates:")
 		for _, cert := range certs {
-			utils.Indent(log.Info, 2)(fmt.Sprintf("%s: %s (%s), Expires: %s", cert.ID, cert.Attributes.Name, cert.Attributes.CertificateType, cert.Attributes.ExpirationDate.Format("02Jan2006 15:04:05")))
+			utils.Indent(log.Info, 2)(fmt.Sprintf("%s: %s (%s), Expires: %s", cert.ID, cert.Attributes.Name, cert.Attributes.CertificateType, utils.FormatTime(cert.Attributes.ExpirationDate.Time())))
 		}
 
 		return nil
@@ -28,6 +28,7 @@ import (
 	"github.com/AlecAivazis/survey/v2"
 	"github.com/AlecAivazis/survey/v2/terminal"
 	"github.com/apex/log"
+	"github.com/blacktop/ipsw/internal/utils"
 	"github.com/blacktop/ipsw/pkg/appstore"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -89,9 +90,12 @@ var ASCertRevokeCmd = &cobra.Command{
 				Options:  choices,
 				PageSize: 10,
 			}
-			if err := survey.AskOne(prompt, &choice); err == terminal.InterruptErr {
-				log.Warn("Exiting...")
-				return nil
+			if err := utils.AskOne(prompt, &choice); err != nil {
+				if err == terminal.InterruptErr {
+					log.Warn("Exiting...")
+					return nil
+				}
+				return err
 			}
 
 			for _, c := range cs {
@@ -103,9 +103,12 @@ var ASDeviceModifyCmd = &cobra.Command{
 				Options:  choices,
 				PageSize: 10,
 			}
-			if err := survey.AskOne(prompt, &choice); err == terminal.InterruptErr {
-				log.Warn("Exiting...")
-				return nil
+			if err := utils.AskOne(prompt, &choice); err != nil {
+				if err == terminal.InterruptErr {
+					log.Warn("Exiting...")
+					return nil
+				}
+				return err
 			}
 
 			for _, d := range devs {
@@ -126,7 +129,7 @@ var ASDeviceModifyCmd = &cobra.Command{
 		if dev.Attributes.Model != "" {
 			model = dev.Attributes.Model
 		}
-		utils.Indent(log.Info, 2)(fmt.Sprintf("%s: [%s] Added: %s - %s (%s)", dev.ID, dev.Attributes.Status, dev.Attributes.AddedDate.Format("02Jan2006 15:04:05"), dev.Attributes.Name, model))
+		utils.Indent(log.Info, 2)(fmt.Sprintf("%s: [%s] Added: %s - %s (%s)", dev.ID, dev.Attributes.Status, utils.FormatTime(dev.Attributes.AddedDate.Time()), dev.Attributes.Name, model))
 
 		return nil
 	},
@@ -89,7 +89,7 @@ var ASDeviceRegisterCmd = &cobra.Command{
 		if dev.Attributes.Model != "" {
 			model = dev.Attributes.Model
 		}
-		utils.Indent(log.Info, 2)(fmt.Sprintf("%s: [%s] Added: %s - %s (%s)", dev.ID, dev.Attributes.Status, dev.Attributes.AddedDate.Format("02Jan2006 15:04:05"), dev.Attributes.Name, model))
+		utils.Indent(log.Info, 2)(fmt.Sprintf("%s: [%s] Added: %s - %s (%s)", dev.ID, dev.Attributes.Status, utils.FormatTime(dev.Attributes.AddedDate.Time()), dev.Attributes.Name, model))
 
 		return nil
 	},
@@ -107,9 +107,12 @@ var ASProfileCreateCmd = &cobra.Command{
 				Options:  choices,
 				PageSize: 10,
 			}
-			if err := survey.AskOne(prompt, &choice); err == terminal.InterruptErr {
-				log.Warn("Exiting...")
-				return nil
+			if err := utils.AskOne(prompt, &choice); err != nil {
+				if err == terminal.InterruptErr {
+					log.Warn("Exiting...")
+					return nil
+				}
+				return err
 			}
 
 			for _, b := range bids {
@@ -137,9 +140,12 @@ var ASProfileCreateCmd = &cobra.Command{
 				Options:  choices,
 				PageSize: 10,
 			}
-			if err := survey.AskOne(prompt, &choose); err == terminal.InterruptErr {
-				log.Warn("Exiting...")
-				return nil
+			if err := utils.AskOne(prompt, &choose); err != nil {
+				if err == terminal.InterruptErr {
+					log.Warn("Exiting...")
+					return nil
+				}
+				return err
 			}
 
 			for _, idx := range choose {
@@ -164,9 +170,12 @@ var ASProfileCreateCmd = &cobra.Command{
 				Options:  choices,
 				PageSize: 10,
 			}
-			if err := survey.AskOne(prompt, &choose); err == terminal.InterruptErr {
-				log.Warn("Exiting...")
-				return nil
+			if err := utils.AskOne(prompt, &choose); err != nil {
+				if err == terminal.InterruptErr {
+					log.Warn("Exiting...")
+					return nil
+				}
+				return err
 			}
 
 			for _, idx := range choose {
@@ -187,7 +196,7 @@ var ASProfileCreateCmd = &cobra.Command{
 
 		log.Info("Created Profile:")
 		prof := resp.Data
-		utils.Indent(log.Info, 2)(fmt.Sprintf("%s: %s (%s), Expires: %s", prof.ID, prof.Attributes.Name, prof.Attributes.ProfileState, prof.Attributes.ExpirationDate.Format("02Jan2006 15:04:05")))
+		utils.Indent(log.Info, 2)(fmt.Sprintf("%s: %s (%s), Expires: %s", prof.ID, prof.Attributes.Name, prof.Attributes.ProfileState, utils.FormatTime(prof.Attributes.ExpirationDate.Time())))
 		cs, err := as.GetProfileCerts(prof.ID)
 		if err != nil {
 			return err
@@ -196,7 +205,7 @@ var ASProfileCreateCmd = &cobra.Command{
 			utils.Indent(log.Info, 3)("Certificates:")
 		}
 		for _, cert := range cs {
-			utils.Indent(log.Info, 4)(fmt.Sprintf("%s: %s (%s), Expires: %s", cert.ID, cert.Attributes.Name, cert.Attributes.CertificateType, cert.Attributes.ExpirationDate.Format("02Jan2006 15:04:05")))
+			utils.Indent(log.Info, 4)(fmt.Sprintf("%s: %s (%s), Expires: %s", cert.ID, cert.Attributes.Name, cert.Attributes.CertificateType, utils.FormatTime(cert.Attributes.ExpirationDate.Time())))
 		}
 		devs, err := as.GetProfileDevices(prof.ID)
 		if err != nil {
@@ -73,9 +73,9 @@ var ASProfileListCmd = &cobra.Command{
 		log.Info("Provisioning Profiles:")
 		for _, prof := range profs {
 			if prof.IsExpired() || prof.IsInvalid() {
-				utils.Indent(log.Error, 2)(fmt.Sprintf("%s: %s (%s), Expires: %s", prof.ID, prof.Attributes.Name, prof.Attributes.ProfileState, prof.Attributes.ExpirationDate.Format("02Jan2006 15:04:05")))
+				utils.Indent(log.Error, 2)(fmt.Sprintf("%s: %s (%s), Expires: %s", prof.ID, prof.Attributes.Name, prof.Attributes.ProfileState, utils.FormatTime(prof.Attributes.ExpirationDate.Time())))
 			} else {
-				utils.Indent(log.Info, 2)(fmt.Sprintf("%s: %s (%s), Expires: %s", prof.ID, prof.Attributes.Name, prof.Attributes.ProfileState, prof.Attributes.ExpirationDate.Format("02Jan2006 15:04:05")))
+				utils.Indent(log.Info, 2)(fmt.Sprintf("%s: %s (%s), Expires: %s", prof.ID, prof.Attributes.Name, prof.Attributes.ProfileState, utils.FormatTime(prof.Attributes.ExpirationDate.Time())))
 			}
 			certs, err := as.GetProfileCerts(prof.ID)
 			if err != nil {
@@ -85,7 +85,7 @@ var ASProfileListCmd = &cobra.Command{
 				utils.Indent(log.Info, 3)("Certificates:")
 			}
 			for _, cert := range certs {
-				utils.Indent(log.Info, 4)(fmt.Sprintf("%s: %s (%s), Expires: %s", cert.ID, cert.Attributes.Name, cert.Attributes.CertificateType, cert.Attributes.ExpirationDate.Format("02Jan2006 15:04:05")))
+				utils.Indent(log.Info, 4)(fmt.Sprintf("%s: %s (%s), Expires: %s", cert.ID, cert.Attributes.Name, cert.Attributes.CertificateType, utils.FormatTime(cert.Attributes.ExpirationDate.Time())))
 			}
 			devs, err := as.GetProfileDevices(prof.ID)
 			if err != nil {
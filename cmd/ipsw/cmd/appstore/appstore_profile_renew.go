@@ -116,7 +116,7 @@ var ASProfileRenewCmd = &cobra.Command{
 
 			var choices []string
 			for _, prof := range profs {
-				choices = append(choices, fmt.Sprintf("%s: %s (%s), Expires: %s", prof.ID, prof.Attributes.Name, prof.Attributes.ProfileState, prof.Attributes.ExpirationDate.Format("02Jan2006 15:04:05")))
+				choices = append(choices, fmt.Sprintf("%s: %s (%s), Expires: %s", prof.ID, prof.Attributes.Name, prof.Attributes.ProfileState, utils.FormatTime(prof.Attributes.ExpirationDate.Time())))
 			}
 
 			var choice string
@@ -125,9 +125,12 @@ var ASProfileRenewCmd = &cobra.Command{
 				Options:  choices,
 				PageSize: 10,
 			}
-			if err := survey.AskOne(prompt, &choice); err == terminal.InterruptErr {
-				log.Warn("Exiting...")
-				return nil
+			if err := utils.AskOne(prompt, &choice); err != nil {
+				if err == terminal.InterruptErr {
+					log.Warn("Exiting...")
+					return nil
+				}
+				return err
 			}
 
 			for _, prof := range profs {
@@ -178,7 +181,7 @@ var ASProfileRenewCmd = &cobra.Command{
 
 		log.Info("Renewed Profile:")
 		prof := resp.Data
-		utils.Indent(log.Info, 2)(fmt.Sprintf("%s: %s (%s), Expires: %s", prof.ID, prof.Attributes.Name, prof.Attributes.ProfileState, prof.Attributes.ExpirationDate.Format("02Jan2006 15:04:05")))
+		utils.Indent(log.Info, 2)(fmt.Sprintf("%s: %s (%s), Expires: %s", prof.ID, prof.Attributes.Name, prof.Attributes.ProfileState, utils.FormatTime(prof.Attributes.ExpirationDate.Time())))
 		cs, err := as.GetProfileCerts(prof.ID)
 		if err != nil {
 			return err
@@ -187,7 +190,7 @@ var ASProfileRenewCmd = &cobra.Command{
 			utils.Indent(log.Info, 3)("Certificates:")
 		}
 		for _, cert := range cs {
-			utils.Indent(log.Info, 4)(fmt.Sprintf("%s: %s (%s), Expires: %s", cert.ID, cert.Attributes.Name, cert.Attributes.CertificateType, cert.Attributes.ExpirationDate.Format("02Jan2006 15:04:05")))
+			utils.Indent(log.Info, 4)(fmt.Sprintf("%s: %s (%s), Expires: %s", cert.ID, cert.Attributes.Name, cert.Attributes.CertificateType, utils.FormatTime(cert.Attributes.ExpirationDate.Time())))
 		}
 		devs, err := as.GetProfileDevices(prof.ID)
 		if err != nil {
@@ -0,0 +1,204 @@
+/*
+Copyright © 2024 blacktop
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/blacktop/ipsw/internal/commands/extract"
+	"github.com/blacktop/ipsw/internal/diff"
+	"github.com/blacktop/ipsw/internal/download"
+	"github.com/blacktop/ipsw/internal/exitcode"
+	"github.com/blacktop/ipsw/internal/utils"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func init() {
+	rootCmd.AddCommand(betaDiffCmd)
+
+	betaDiffCmd.Flags().StringP("device", "d", "", "iOS Device (i.e. iPhone16,1)")
+	betaDiffCmd.Flags().String("os", "iOS", "Operating system of the betas (i.e. iOS, iPadOS, macOS)")
+	betaDiffCmd.Flags().StringP("output", "o", "", "Folder to save the diff report and kernelcaches to")
+	betaDiffCmd.Flags().BoolP("api", "a", false, "Use Github API")
+	betaDiffCmd.Flags().String("api-token", "", "Github API Token")
+	betaDiffCmd.Flags().String("proxy", "", "HTTP/HTTPS proxy")
+	betaDiffCmd.Flags().Bool("insecure", false, "do not verify ssl certs")
+	betaDiffCmd.MarkFlagRequired("device")
+	betaDiffCmd.MarkFlagDirname("output")
+	betaDiffCmd.RegisterFlagCompletionFunc("os", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{"audioOS", "bridgeOS", "iOS", "iPadOS", "macOS", "tvOS", "watchOS"}, cobra.ShellCompDirectiveDefault
+	})
+	viper.BindPFlag("beta-diff.device", betaDiffCmd.Flags().Lookup("device"))
+	viper.BindPFlag("beta-diff.os", betaDiffCmd.Flags().Lookup("os"))
+	viper.BindPFlag("beta-diff.output", betaDiffCmd.Flags().Lookup("output"))
+	viper.BindPFlag("beta-diff.api", betaDiffCmd.Flags().Lookup("api"))
+	viper.BindPFlag("beta-diff.api-token", betaDiffCmd.Flags().Lookup("api-token"))
+	viper.BindPFlag("beta-diff.proxy", betaDiffCmd.Flags().Lookup("proxy"))
+	viper.BindPFlag("beta-diff.insecure", betaDiffCmd.Flags().Lookup("insecure"))
+}
+
+// betaDiffCmd represents the beta-diff command
+var betaDiffCmd = &cobra.Command{
+	Use:   "beta-diff",
+	Short: "Diff the kernelcaches of the two latest betas for a device",
+	Long: `Looks up the two latest betas for a device in AppleDB, extracts and decompresses their kernelcaches
+from the remote IPSWs (without downloading the IPSWs) and reports the kext and symbol differences.`,
+	Example: `  # Diff the kexts and symbols of the two latest iOS beta kernelcaches for the iPhone 15 Pro
+  ❯ ipsw beta-diff --device iPhone16,1
+
+  # Save the markdown report and the kernelcaches to a folder
+  ❯ ipsw beta-diff --device iPhone16,1 --output /tmp/beta-diff`,
+	Args:          cobra.NoArgs,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) (err error) {
+
+		if Verbose {
+			log.SetLevel(log.DebugLevel)
+		}
+
+		device := download.ResolveDevice(viper.GetString("beta-diff.device"))
+		proxy := viper.GetString("beta-diff.proxy")
+		insecure := viper.GetBool("beta-diff.insecure")
+		output := viper.GetString("beta-diff.output")
+
+		apiToken := viper.GetString("beta-diff.api-token")
+		if len(apiToken) == 0 {
+			if val, ok := os.LookupEnv("GITHUB_TOKEN"); ok {
+				apiToken = val
+			} else if val, ok := os.LookupEnv("GITHUB_API_TOKEN"); ok {
+				apiToken = val
+			}
+		}
+
+		query := &download.ADBQuery{
+			OSes:     []string{viper.GetString("beta-diff.os")},
+			Device:   device,
+			IsBeta:   true,
+			Proxy:    proxy,
+			Insecure: insecure,
+			APIToken: apiToken,
+		}
+
+		log.Info("Querying AppleDB...")
+		var osfiles download.OsFiles
+		if viper.GetBool("beta-diff.api") {
+			osfiles, err = download.AppleDBOsFiles(query)
+		} else {
+			if len(viper.ConfigFileUsed()) == 0 {
+				home, err := os.UserHomeDir()
+				if err != nil {
+					return err
+				}
+				query.ConfigDir = filepath.Join(home, ".config", "ipsw")
+				if err := os.MkdirAll(query.ConfigDir, 0770); err != nil {
+					return fmt.Errorf("failed to create config folder: %v", err)
+				}
+			} else {
+				query.ConfigDir = filepath.Dir(viper.ConfigFileUsed())
+			}
+			osfiles, err = download.LocalAppleDBOsFiles(query)
+		}
+		if err != nil {
+			return err
+		}
+
+		betas := osfiles.LatestBetas(device, 2)
+		if len(betas) < 2 {
+			return exitcode.Errorf(exitcode.NotFound, "found %d %s beta IPSW(s) for %s in AppleDB (need 2 to diff)", len(betas), query.OSes[0], device)
+		}
+		newBeta, oldBeta := betas[0], betas[1]
+
+		folder := output
+		if len(folder) == 0 {
+			folder, err = os.MkdirTemp("", "ipsw_beta_diff")
+			if err != nil {
+				return fmt.Errorf("failed to create temporary directory: %v", err)
+			}
+			defer os.RemoveAll(folder)
+		}
+
+		extractKernel := func(beta download.AppleDbOsFile) (string, error) {
+			url := beta.IPSWURL(device)
+			log.WithFields(log.Fields{"device": device, "build": beta.Build, "version": beta.Version}).Info("Extracting remote kernelcache")
+			out := filepath.Join(folder, beta.Build)
+			kernels, err := extract.Kernelcache(&extract.Config{
+				URL:      url,
+				Proxy:    proxy,
+				Insecure: insecure,
+				Output:   out,
+			})
+			if err != nil {
+				return "", fmt.Errorf("failed to extract kernelcache from %s: %v", url, err)
+			}
+			for kpath, devices := range kernels {
+				if slices.Contains(devices, device) {
+					utils.Indent(log.Info, 2)(fmt.Sprintf("Created %s", kpath))
+					return kpath, nil
+				}
+			}
+			// already extracted by a previous run
+			matches, _ := filepath.Glob(filepath.Join(out, "*", "kernelcache*"))
+			for _, match := range matches {
+				if strings.Contains(filepath.Base(match), device) {
+					return match, nil
+				}
+			}
+			return "", exitcode.Errorf(exitcode.NotFound, "no kernelcache for %s found in %s", device, url)
+		}
+
+		oldKernel, err := extractKernel(oldBeta)
+		if err != nil {
+			return err
+		}
+		newKernel, err := extractKernel(newBeta)
+		if err != nil {
+			return err
+		}
+
+		log.Info("Diffing KERNELCACHES")
+		kd, err := diff.DiffKernelcaches(
+			fmt.Sprintf("%s %s (%s) .vs %s (%s)", device, oldBeta.Version, oldBeta.Build, newBeta.Version, newBeta.Build),
+			oldKernel,
+			newKernel,
+		)
+		if err != nil {
+			return err
+		}
+
+		if len(output) > 0 {
+			fname := filepath.Join(output, fmt.Sprintf("%s_%s_%s.md", device, oldBeta.Build, newBeta.Build))
+			log.Infof("Creating diff file: %s", fname)
+			return os.WriteFile(fname, []byte(kd.String()), 0660)
+		}
+
+		fmt.Println(kd)
+
+		return nil
+	},
+}
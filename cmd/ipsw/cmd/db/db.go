@@ -0,0 +1,67 @@
+/*
+Copyright © 2023 blacktop
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package db
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/blacktop/ipsw/internal/db"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// DbCmd represents the db command
+var DbCmd = &cobra.Command{
+	Use:   "db",
+	Short: "Manage the local metadata database (for offline lookups)",
+	Args:  cobra.NoArgs,
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		viper.BindPFlag("color", cmd.Flags().Lookup("color"))
+		viper.BindPFlag("verbose", cmd.Flags().Lookup("verbose"))
+		viper.BindPFlag("diff-tool", cmd.Flags().Lookup("diff-tool"))
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+	},
+}
+
+// MetadataPath returns the metadata database path (the `metadata-db` config key or ~/.ipsw/metadata.db)
+func MetadataPath() (string, error) {
+	if path := viper.GetString("metadata-db"); len(path) > 0 {
+		return path, nil
+	}
+	return db.DefaultMetadataPath()
+}
+
+// OpenMetadataDB opens (or creates) the metadata database
+func OpenMetadataDB() (*db.MetadataDB, error) {
+	path, err := MetadataPath()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return nil, fmt.Errorf("failed to create directory %s: %v", filepath.Dir(path), err)
+	}
+	return db.NewMetadataDB(path)
+}
@@ -0,0 +1,90 @@
+/*
+Copyright © 2023 blacktop
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package db
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/apex/log"
+	"github.com/blacktop/ipsw/internal/utils"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func init() {
+	DbCmd.AddCommand(dbInfoCmd)
+
+	dbInfoCmd.Flags().Bool("json", false, "Output as JSON")
+	viper.BindPFlag("db.info.json", dbInfoCmd.Flags().Lookup("json"))
+}
+
+// dbInfoCmd represents the db info command
+var dbInfoCmd = &cobra.Command{
+	Use:           "info",
+	Short:         "Show what the local metadata database contains",
+	Args:          cobra.NoArgs,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+
+		if viper.GetBool("verbose") {
+			log.SetLevel(log.DebugLevel)
+		}
+
+		path, err := MetadataPath()
+		if err != nil {
+			return err
+		}
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			return fmt.Errorf("metadata DB %s does not exist (run `ipsw db sync` first)", path)
+		}
+
+		mdb, err := OpenMetadataDB()
+		if err != nil {
+			return fmt.Errorf("failed to open metadata DB: %v", err)
+		}
+		defer mdb.Close()
+
+		stats, err := mdb.Stats()
+		if err != nil {
+			return fmt.Errorf("failed to get metadata DB stats: %v", err)
+		}
+
+		if viper.GetBool("db.info.json") {
+			return json.NewEncoder(os.Stdout).Encode(stats)
+		}
+
+		log.Infof("Metadata DB %s", mdb.Path)
+		if stats.SyncedAt.IsZero() {
+			utils.Indent(log.Warn, 2)("Never synced")
+		} else {
+			utils.Indent(log.Info, 2)(fmt.Sprintf("Synced:    %s", utils.FormatTime(stats.SyncedAt)))
+		}
+		utils.Indent(log.Info, 2)(fmt.Sprintf("Devices:   %d", stats.Devices))
+		utils.Indent(log.Info, 2)(fmt.Sprintf("Firmwares: %d (%d signed)", stats.Firmwares, stats.Signed))
+		utils.Indent(log.Info, 2)(fmt.Sprintf("OTAs:      %d", stats.OTAs))
+
+		return nil
+	},
+}
@@ -0,0 +1,129 @@
+/*
+Copyright © 2023 blacktop
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package db
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/apex/log"
+	"github.com/blacktop/ipsw/internal/db"
+	"github.com/blacktop/ipsw/internal/utils"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func init() {
+	DbCmd.AddCommand(dbLsCmd)
+
+	dbLsCmd.Flags().StringP("device", "d", "", "iOS Device (i.e. iPhone12,1)")
+	dbLsCmd.Flags().Bool("signed", false, "Only list signed builds")
+	dbLsCmd.Flags().Bool("latest", false, "Only list the latest build of each device")
+	dbLsCmd.Flags().Bool("ota", false, "List OTAs instead of IPSWs")
+	dbLsCmd.Flags().String("as-of", "", "List the builds as of a past date (i.e. 2023-06-01)")
+	dbLsCmd.Flags().Bool("json", false, "Output as JSON")
+	viper.BindPFlag("db.ls.device", dbLsCmd.Flags().Lookup("device"))
+	viper.BindPFlag("db.ls.signed", dbLsCmd.Flags().Lookup("signed"))
+	viper.BindPFlag("db.ls.latest", dbLsCmd.Flags().Lookup("latest"))
+	viper.BindPFlag("db.ls.ota", dbLsCmd.Flags().Lookup("ota"))
+	viper.BindPFlag("db.ls.as-of", dbLsCmd.Flags().Lookup("as-of"))
+	viper.BindPFlag("db.ls.json", dbLsCmd.Flags().Lookup("json"))
+}
+
+// dbLsCmd represents the db ls command
+var dbLsCmd = &cobra.Command{
+	Use:     "ls",
+	Aliases: []string{"list"},
+	Short:   "List the builds in the local metadata database",
+	Example: `  # What was the latest signed build for the iPhone12,1 on June 1st 2023?
+  ❯ ipsw db ls --device iPhone12,1 --signed --latest --as-of 2023-06-01`,
+	Args:          cobra.NoArgs,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+
+		if viper.GetBool("verbose") {
+			log.SetLevel(log.DebugLevel)
+		}
+
+		q := &db.FirmwareQuery{
+			Device: viper.GetString("db.ls.device"),
+			Signed: viper.GetBool("db.ls.signed"),
+			Latest: viper.GetBool("db.ls.latest"),
+			OTA:    viper.GetBool("db.ls.ota"),
+		}
+		if asOf := viper.GetString("db.ls.as-of"); len(asOf) > 0 {
+			var err error
+			if q.AsOf, err = db.ParseAsOf(asOf); err != nil {
+				return err
+			}
+			if q.OTA && q.Signed {
+				return fmt.Errorf("the signing status of OTAs is not recorded (cannot use --ota --signed with --as-of)")
+			}
+		}
+
+		path, err := MetadataPath()
+		if err != nil {
+			return err
+		}
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			return fmt.Errorf("metadata DB %s does not exist (run `ipsw db sync` first)", path)
+		}
+
+		mdb, err := OpenMetadataDB()
+		if err != nil {
+			return fmt.Errorf("failed to open metadata DB: %v", err)
+		}
+		defer mdb.Close()
+
+		if !q.AsOf.IsZero() && q.Signed {
+			if first, err := mdb.FirstSync(); err == nil && q.AsOf.Before(first) {
+				log.Warnf("The metadata DB was first synced on %s: builds that were no longer signed by then are listed as unsigned", utils.FormatDate(first))
+			}
+		}
+
+		ipsws, err := mdb.Firmwares(q)
+		if err != nil {
+			return fmt.Errorf("failed to query metadata DB: %v", err)
+		}
+
+		if viper.GetBool("db.ls.json") {
+			return json.NewEncoder(os.Stdout).Encode(ipsws)
+		}
+
+		if len(ipsws) == 0 {
+			log.Warn("No builds found")
+			return nil
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "DEVICE\tVERSION\tBUILD\tRELEASED\tSIGNED")
+		for _, i := range ipsws {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%t\n", i.Identifier, i.Version, i.BuildID, utils.FormatDate(i.ReleaseDate), i.Signed)
+		}
+		w.Flush()
+
+		return nil
+	},
+}
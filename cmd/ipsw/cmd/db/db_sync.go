@@ -0,0 +1,104 @@
+/*
+Copyright © 2023 blacktop
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package db
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/apex/log"
+	"github.com/blacktop/ipsw/internal/download"
+	"github.com/blacktop/ipsw/internal/utils"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func init() {
+	DbCmd.AddCommand(dbSyncCmd)
+
+	dbSyncCmd.Flags().Bool("ota", false, "Also sync OTAs")
+	dbSyncCmd.Flags().Bool("json", false, "Output stats as JSON")
+	viper.BindPFlag("db.sync.ota", dbSyncCmd.Flags().Lookup("ota"))
+	viper.BindPFlag("db.sync.json", dbSyncCmd.Flags().Lookup("json"))
+}
+
+// dbSyncCmd represents the db sync command
+var dbSyncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Mirror devices, firmwares, OTAs and signing status into the local metadata database",
+	Long: `Mirror devices, firmwares, OTAs and signing status from the ipsw.me API into a local
+SQLite database (~/.ipsw/metadata.db or the 'metadata-db' config key).
+
+Once synced, device and build lookups resolve from the database first, and with --offline
+they never touch the network (i.e. on airgapped analysis machines).`,
+	Example: `  # Sync the IPSWs and OTAs of every device
+  ❯ ipsw db sync --ota
+
+  # Then copy ~/.ipsw/metadata.db to the airgapped machine and check what it knows
+  ❯ ipsw --offline db info`,
+	Args:          cobra.NoArgs,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+
+		if viper.GetBool("verbose") {
+			log.SetLevel(log.DebugLevel)
+		}
+
+		if viper.GetBool("offline") {
+			return fmt.Errorf("cannot sync the metadata database with --offline")
+		}
+
+		mdb, err := OpenMetadataDB()
+		if err != nil {
+			return fmt.Errorf("failed to open metadata DB: %v", err)
+		}
+		defer mdb.Close()
+
+		// sync from the API (not from the database being synced)
+		download.SetMetadataSource(nil, false)
+
+		log.Infof("Syncing metadata DB %s", mdb.Path)
+		start := time.Now()
+		if err := mdb.Sync(viper.GetBool("db.sync.ota")); err != nil {
+			return err
+		}
+
+		stats, err := mdb.Stats()
+		if err != nil {
+			return fmt.Errorf("failed to get metadata DB stats: %v", err)
+		}
+
+		if viper.GetBool("db.sync.json") {
+			return json.NewEncoder(os.Stdout).Encode(stats)
+		}
+
+		log.Infof("Synced in %s", time.Since(start).Round(time.Second))
+		utils.Indent(log.Info, 2)(fmt.Sprintf("Devices:   %d", stats.Devices))
+		utils.Indent(log.Info, 2)(fmt.Sprintf("Firmwares: %d (%d signed)", stats.Firmwares, stats.Signed))
+		utils.Indent(log.Info, 2)(fmt.Sprintf("OTAs:      %d", stats.OTAs))
+
+		return nil
+	},
+}
@@ -0,0 +1,220 @@
+/*
+Copyright © 2023 blacktop
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package dev
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/AlecAivazis/survey/v2/terminal"
+	"github.com/apex/log"
+	"github.com/blacktop/ipsw/internal/download"
+	"github.com/blacktop/ipsw/internal/utils"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func init() {
+	DevCmd.AddCommand(devAuthCmd)
+	devAuthCmd.AddCommand(devAuthListCmd)
+	devAuthCmd.AddCommand(devAuthAddCmd)
+	devAuthCmd.AddCommand(devAuthRemoveCmd)
+
+	devAuthCmd.PersistentFlags().StringP("vault-password", "k", "", "Password to unlock credential vault (only for file vaults)")
+	devAuthCmd.PersistentFlags().String("keyring-backend", "auto", "Credential keyring backend (auto, system, keychain, secret-service, kwallet, keyctl, wincred, pass or file)")
+	viper.BindPFlag("dev.auth.vault-password", devAuthCmd.PersistentFlags().Lookup("vault-password"))
+	viper.BindPFlag("dev.auth.keyring-backend", devAuthCmd.PersistentFlags().Lookup("keyring-backend"))
+
+	devAuthListCmd.Flags().Bool("json", false, "Output as JSON")
+	viper.BindPFlag("dev.auth.list.json", devAuthListCmd.Flags().Lookup("json"))
+
+	devAuthAddCmd.Flags().StringP("username", "u", "", "Apple ID username")
+	devAuthAddCmd.Flags().StringP("password", "p", "", "Apple ID password")
+	viper.BindPFlag("dev.auth.add.username", devAuthAddCmd.Flags().Lookup("username"))
+	viper.BindPFlag("dev.auth.add.password", devAuthAddCmd.Flags().Lookup("password"))
+}
+
+// openVault opens the credential vault
+func openVault() (*download.DevPortal, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user home directory: %v", err)
+	}
+	if viper.GetString("dev.auth.keyring-backend") == download.KeyringBackendEnv {
+		return nil, fmt.Errorf("the '%s' keyring backend does not persist credentials", download.KeyringBackendEnv)
+	}
+	app := download.NewDevPortal(&download.DevConfig{
+		ConfigDir:      filepath.Join(home, ".ipsw"),
+		VaultPassword:  viper.GetString("dev.auth.vault-password"),
+		KeyringBackend: viper.GetString("dev.auth.keyring-backend"),
+		Verbose:        viper.GetBool("verbose"),
+	})
+	if err := app.Init(); err != nil {
+		return nil, fmt.Errorf("failed to initialize app: %v", err)
+	}
+	return app, nil
+}
+
+func ask(p survey.Prompt, answer *string) error {
+	if err := utils.AskOne(p, answer); err != nil {
+		if err == terminal.InterruptErr {
+			log.Warn("Exiting...")
+			os.Exit(0)
+		}
+		return err
+	}
+	return nil
+}
+
+// devAuthCmd represents the dev auth command
+var devAuthCmd = &cobra.Command{
+	Use:     "auth",
+	Aliases: []string{"a", "account"},
+	Short:   "Manage the Apple Developer account credentials in the vault",
+	Args:    cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+	},
+}
+
+// devAuthListCmd represents the dev auth list command
+var devAuthListCmd = &cobra.Command{
+	Use:           "list",
+	Aliases:       []string{"ls"},
+	Short:         "List the accounts in the vault",
+	Args:          cobra.NoArgs,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+
+		if viper.GetBool("verbose") {
+			log.SetLevel(log.DebugLevel)
+		}
+
+		app, err := openVault()
+		if err != nil {
+			return err
+		}
+
+		accounts, err := app.ListAccounts()
+		if err != nil {
+			return err
+		}
+
+		if viper.GetBool("dev.auth.list.json") {
+			return json.NewEncoder(os.Stdout).Encode(accounts)
+		}
+
+		if len(accounts) == 0 {
+			log.Warn("No accounts in vault (add one with `ipsw dev auth add`)")
+			return nil
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ACCOUNT\tUSERNAME\tSESSION")
+		for _, a := range accounts {
+			fmt.Fprintf(w, "%s\t%s\t%t\n", a.Name, a.Username, a.Session)
+		}
+		return w.Flush()
+	},
+}
+
+// devAuthAddCmd represents the dev auth add command
+var devAuthAddCmd = &cobra.Command{
+	Use:   "add [ACCOUNT]",
+	Short: "Add (or replace) an account in the vault",
+	Example: `  # Add a work Apple ID
+  ❯ ipsw dev auth add work --username me@work.com
+  # Use it to download from the developer portal
+  ❯ ipsw download dev --account work`,
+	Args:          cobra.MaximumNArgs(1),
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+
+		if viper.GetBool("verbose") {
+			log.SetLevel(log.DebugLevel)
+		}
+
+		name := download.DefaultAccount
+		if len(args) > 0 {
+			name = args[0]
+		}
+		username := viper.GetString("dev.auth.add.username")
+		password := viper.GetString("dev.auth.add.password")
+
+		if len(username) == 0 {
+			if err := ask(&survey.Input{Message: "Please type your username:"}, &username); err != nil {
+				return err
+			}
+		}
+		if len(password) == 0 {
+			if err := ask(&survey.Password{Message: "Please type your password:"}, &password); err != nil {
+				return err
+			}
+		}
+
+		app, err := openVault()
+		if err != nil {
+			return err
+		}
+
+		if err := app.AddAccount(name, username, password); err != nil {
+			return err
+		}
+
+		log.WithField("username", username).Infof("Added account %s", name)
+		return nil
+	},
+}
+
+// devAuthRemoveCmd represents the dev auth remove command
+var devAuthRemoveCmd = &cobra.Command{
+	Use:           "remove ACCOUNT",
+	Aliases:       []string{"rm"},
+	Short:         "Remove an account (and its session) from the vault",
+	Args:          cobra.ExactArgs(1),
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+
+		if viper.GetBool("verbose") {
+			log.SetLevel(log.DebugLevel)
+		}
+
+		app, err := openVault()
+		if err != nil {
+			return err
+		}
+
+		if err := app.RemoveAccount(args[0]); err != nil {
+			return err
+		}
+
+		log.Infof("Removed account %s", args[0])
+		return nil
+	},
+}
@@ -0,0 +1,145 @@
+/*
+Copyright © 2023 blacktop
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/apex/log"
+	"github.com/blacktop/ipsw/internal/download"
+	"github.com/blacktop/ipsw/pkg/info"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func init() {
+	rootCmd.AddCommand(deviceLookupCmd)
+
+	deviceLookupCmd.Flags().Bool("remote", false, "Fetch the latest model numbers from theapplewiki.com")
+	deviceLookupCmd.Flags().String("proxy", "", "HTTP/HTTPS proxy")
+	deviceLookupCmd.Flags().Bool("insecure", false, "do not verify ssl certs")
+	deviceLookupCmd.Flags().Bool("json", false, "Output as JSON")
+	viper.BindPFlag("device-lookup.remote", deviceLookupCmd.Flags().Lookup("remote"))
+	viper.BindPFlag("device-lookup.proxy", deviceLookupCmd.Flags().Lookup("proxy"))
+	viper.BindPFlag("device-lookup.insecure", deviceLookupCmd.Flags().Lookup("insecure"))
+	viper.BindPFlag("device-lookup.json", deviceLookupCmd.Flags().Lookup("json"))
+}
+
+// ipswDB is the embedded device DB (loaded on first use)
+var ipswDB = sync.OnceValues(info.GetIpswDB)
+
+// resolveDeviceName resolves a board config, marketing name or A-number to its device identifier
+// (the fallback of the download package's device alias table)
+func resolveDeviceName(name string) (string, bool) {
+	db, err := ipswDB()
+	if err != nil {
+		log.Debugf("failed to get ipsw device DB: %v", err)
+		return "", false
+	}
+	prod, err := db.ResolveIdentifier(name)
+	if err != nil {
+		return "", false
+	}
+	return prod, true
+}
+
+// deviceLookupCmd represents the device-lookup command
+var deviceLookupCmd = &cobra.Command{
+	Use:     "device-lookup <A-NUMBER|MODEL|BOARD|IDENTIFIER>",
+	Aliases: []string{"model"},
+	Short:   "Convert between A-numbers, model numbers, internal names and identifiers",
+	Example: `  # Lookup an A-number
+  ❯ ipsw device-lookup A2848
+  # Lookup a model number and its region
+  ❯ ipsw device-lookup MTQU3LL/A
+  # Lookup an internal name
+  ❯ ipsw device-lookup D83AP`,
+	Args:          cobra.ExactArgs(1),
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+
+		if Verbose {
+			log.SetLevel(log.DebugLevel)
+		}
+
+		db, err := info.GetIpswDB()
+		if err != nil {
+			return fmt.Errorf("failed to get ipsw device DB: %v", err)
+		}
+
+		infos, err := db.LookupModel(args[0])
+		if err != nil || viper.GetBool("device-lookup.remote") {
+			log.Info("Fetching model numbers from theapplewiki.com")
+			models, err := download.GetWikiModels(viper.GetString("device-lookup.proxy"), viper.GetBool("device-lookup.insecure"))
+			if err != nil {
+				return fmt.Errorf("failed to get models: %v", err)
+			}
+			db.AddModels(models)
+			infos, err = db.LookupModel(args[0])
+			if err != nil {
+				return err
+			}
+		}
+
+		if viper.GetBool("device-lookup.json") {
+			return json.NewEncoder(os.Stdout).Encode(infos)
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', 0)
+		for _, i := range infos {
+			name := i.Device.Name
+			if len(i.Device.Description) > 0 {
+				name = i.Device.Description
+			}
+			fmt.Fprintf(w, "Identifier:\t%s\n", i.Product)
+			fmt.Fprintf(w, "Name:\t%s\n", name)
+			var boards []string
+			for b := range i.Device.Boards {
+				boards = append(boards, b)
+			}
+			sort.Strings(boards)
+			if len(boards) > 0 {
+				fmt.Fprintf(w, "Internal Names:\t%s\n", strings.Join(boards, ", "))
+			}
+			if len(i.Device.ANumbers) > 0 {
+				fmt.Fprintf(w, "A-Numbers:\t%s\n", strings.Join(i.Device.ANumbers, ", "))
+			}
+			if len(i.ModelNumber) > 0 {
+				fmt.Fprintf(w, "Model Number:\t%s%s\n", i.ModelNumber, i.RegionCode)
+			}
+			if len(i.Region) > 0 {
+				fmt.Fprintf(w, "Region:\t%s\n", i.Region)
+			} else if len(i.RegionCode) > 0 {
+				fmt.Fprintf(w, "Region:\t%s (unknown)\n", i.RegionCode)
+			}
+			fmt.Fprintln(w)
+		}
+		return w.Flush()
+	},
+}
@@ -26,6 +26,7 @@ import (
 	"path"
 	"strings"
 
+	"github.com/apex/log"
 	"github.com/blacktop/ipsw/internal/download"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -39,6 +40,12 @@ type downloadFlags struct {
 	ResumeAll    bool
 	RestartAll   bool
 	RemoveCommas bool
+	LimitRate    string
+	VerifyRetry  int
+	IfExists     string
+	ArchiveRoots []string
+	CAS          string
+	Aria2        string
 
 	WhiteList []string
 	BlackList []string
@@ -66,9 +73,21 @@ func init() {
 	viper.BindPFlag("download.resume-all", DownloadCmd.Flags().Lookup("resume-all"))
 	viper.BindPFlag("download.restart-all", DownloadCmd.Flags().Lookup("restart-all"))
 	viper.BindPFlag("download.remove-commas", DownloadCmd.Flags().Lookup("remove-commas"))
+	DownloadCmd.PersistentFlags().StringVar(&dFlg.LimitRate, "limit-rate", "", "Limit the combined download bandwidth (i.e. 500K, 10M or 1G bytes per second)")
+	viper.BindPFlag("download.limit-rate", DownloadCmd.PersistentFlags().Lookup("limit-rate"))
+	DownloadCmd.PersistentFlags().IntVar(&dFlg.VerifyRetry, "verify-retries", download.DefaultVerifyRetries, "Number of times to re-download a file with an incorrect checksum")
+	viper.BindPFlag("download.verify-retries", DownloadCmd.PersistentFlags().Lookup("verify-retries"))
+	DownloadCmd.PersistentFlags().StringVar(&dFlg.IfExists, "if-exists", string(download.IfExistsSkip), "What to do when a file was already downloaded (skip, verify or overwrite)")
+	viper.BindPFlag("download.if-exists", DownloadCmd.PersistentFlags().Lookup("if-exists"))
+	DownloadCmd.PersistentFlags().StringArrayVar(&dFlg.ArchiveRoots, "archive-root", []string{}, "Folder to look for verified copies of missing files in before downloading them (i.e. a previous mirror)")
+	viper.BindPFlag("download.archive-root", DownloadCmd.PersistentFlags().Lookup("archive-root"))
+	DownloadCmd.PersistentFlags().StringVar(&dFlg.CAS, "cas", "", "Store the downloads once by hash in this folder and link them into place (content-addressable storage)")
+	viper.BindPFlag("download.cas", DownloadCmd.PersistentFlags().Lookup("cas"))
+	DownloadCmd.PersistentFlags().StringVar(&dFlg.Aria2, "aria2", "", "Hand the downloads to a running aria2c JSON-RPC endpoint (i.e. "+download.DefaultAria2RPC+")")
+	viper.BindPFlag("download.aria2", DownloadCmd.PersistentFlags().Lookup("aria2"))
 	// Filters
-	DownloadCmd.PersistentFlags().StringArrayVar(&dFlg.WhiteList, "white-list", []string{}, "iOS device white list")
-	DownloadCmd.PersistentFlags().StringArrayVar(&dFlg.BlackList, "black-list", []string{}, "iOS device black list")
+	DownloadCmd.PersistentFlags().StringArrayVar(&dFlg.WhiteList, "white-list", []string{}, "Device white list (product type prefix or class, i.e. iPhone14, audioOS, bridgeOS)")
+	DownloadCmd.PersistentFlags().StringArrayVar(&dFlg.BlackList, "black-list", []string{}, "Device black list (product type prefix or class, i.e. iPhone14, audioOS, bridgeOS)")
 	DownloadCmd.PersistentFlags().StringVarP(&dFlg.Device, "device", "d", "", "iOS Device (i.e. iPhone11,2)")
 	DownloadCmd.PersistentFlags().StringVarP(&dFlg.Model, "model", "m", "", "iOS Model (i.e. D321AP)")
 	DownloadCmd.PersistentFlags().StringVarP(&dFlg.Version, "version", "v", "", "iOS Version (i.e. 12.3.1)")
@@ -81,7 +100,7 @@ func init() {
 	viper.BindPFlag("download.build", DownloadCmd.Flags().Lookup("build"))
 }
 
-func filterIPSWs(cmd *cobra.Command, macos bool) ([]download.IPSW, error) {
+func filterIPSWs(cmd *cobra.Command, itunes *download.ITunesVersionMaster, macos bool) ([]download.IPSW, error) {
 
 	var err error
 	var ipsws []download.IPSW
@@ -100,7 +119,7 @@ func filterIPSWs(cmd *cobra.Command, macos bool) ([]download.IPSW, error) {
 	viper.BindPFlag("download.build", cmd.Flags().Lookup("build"))
 
 	// filters
-	device := viper.GetString("download.device")
+	device := download.ResolveDevice(viper.GetString("download.device"))
 	// model := viper.GetString("download.model")
 	version := viper.GetString("download.version")
 	build := viper.GetString("download.build")
@@ -115,31 +134,16 @@ func filterIPSWs(cmd *cobra.Command, macos bool) ([]download.IPSW, error) {
 		return nil, fmt.Errorf("you cannot supply --version AND --build (they are mutually exclusive)")
 	}
 
-	if len(version) > 0 {
-		ipsws, err = download.GetAllIPSW(version)
-		if err != nil {
-			return nil, fmt.Errorf("failed to query ipsw.me api for ALL ipsws for version %s: %v", version, err)
-		}
-	} else if len(build) > 0 {
-		version, err = download.GetVersion(build)
-		if err != nil {
-			return nil, fmt.Errorf("failed to query ipsw.me api for buildID %s => version: %v", build, err)
-		}
-		ipsws, err = download.GetAllIPSW(version)
+	if viper.GetBool("download.ipsw.itunes") && itunes != nil {
+		ipsws = itunes.GetIPSWs(device, version, build)
+	} else {
+		ipsws, err = queryIPSWs(device, version, build)
 		if err != nil {
-			return nil, fmt.Errorf("failed to query ipsw.me api for ALL ipsws for version %s: %v", version, err)
-		}
-		var buildFiltered []download.IPSW
-		for _, i := range ipsws {
-			if strings.EqualFold(build, i.BuildID) {
-				buildFiltered = append(buildFiltered, i)
+			if itunes == nil {
+				return nil, err
 			}
-		}
-		ipsws = buildFiltered
-	} else if len(device) > 0 {
-		ipsws, err = download.GetDeviceIPSWs(device)
-		if err != nil {
-			return nil, fmt.Errorf("failed to query ipsw.me api for device %s: %v", device, err)
+			log.Warnf("%v (falling back to the iTunes version catalog)", err)
+			ipsws = itunes.GetIPSWs(device, version, build)
 		}
 	}
 
@@ -151,13 +155,13 @@ func filterIPSWs(cmd *cobra.Command, macos bool) ([]download.IPSW, error) {
 		} else {
 			if len(doDownload) > 0 {
 				for _, doDown := range doDownload {
-					if strings.HasPrefix(strings.ToLower(i.Identifier), strings.ToLower(doDown)) {
+					if download.MatchDeviceFilter(i.Identifier, doDown) {
 						filteredIPSWs = append(filteredIPSWs, i)
 					}
 				}
 			} else if len(doNotDownload) > 0 {
 				for _, dontDown := range doNotDownload {
-					if !strings.HasPrefix(strings.ToLower(i.Identifier), strings.ToLower(dontDown)) {
+					if !download.MatchDeviceFilter(i.Identifier, dontDown) {
 						filteredIPSWs = append(filteredIPSWs, i)
 					}
 				}
@@ -195,6 +199,42 @@ func filterIPSWs(cmd *cobra.Command, macos bool) ([]download.IPSW, error) {
 	return uniqueIPSWs, nil
 }
 
+// queryIPSWs queries the ipsw.me API for the IPSWs that match the given device, version or build
+func queryIPSWs(device, version, build string) ([]download.IPSW, error) {
+	var err error
+	var ipsws []download.IPSW
+
+	if len(version) > 0 {
+		ipsws, err = download.GetAllIPSW(version)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query ipsw.me api for ALL ipsws for version %s: %v", version, err)
+		}
+	} else if len(build) > 0 {
+		version, err = download.GetVersion(build)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query ipsw.me api for buildID %s => version: %v", build, err)
+		}
+		ipsws, err = download.GetAllIPSW(version)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query ipsw.me api for ALL ipsws for version %s: %v", version, err)
+		}
+		var buildFiltered []download.IPSW
+		for _, i := range ipsws {
+			if strings.EqualFold(build, i.BuildID) {
+				buildFiltered = append(buildFiltered, i)
+			}
+		}
+		ipsws = buildFiltered
+	} else if len(device) > 0 {
+		ipsws, err = download.GetDeviceIPSWs(device)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query ipsw.me api for device %s: %v", device, err)
+		}
+	}
+
+	return ipsws, nil
+}
+
 func getDestName(url string, removeCommas bool) string {
 	if removeCommas {
 		return strings.Replace(path.Base(url), ",", "_", -1)
@@ -208,10 +248,46 @@ var DownloadCmd = &cobra.Command{
 	Aliases: []string{"dl"},
 	Short:   "Download Apple Firmware files (and more)",
 	Args:    cobra.NoArgs,
-	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
 		viper.BindPFlag("color", cmd.Flags().Lookup("color"))
 		viper.BindPFlag("verbose", cmd.Flags().Lookup("verbose"))
 		viper.BindPFlag("diff-tool", cmd.Flags().Lookup("diff-tool"))
+		if limit := viper.GetString("download.limit-rate"); len(limit) > 0 {
+			bytesPerSec, err := download.ParseRate(limit)
+			if err != nil {
+				return fmt.Errorf("--limit-rate: %v", err)
+			}
+			download.SetRateLimit(bytesPerSec)
+		}
+		download.SetVerifyRetries(viper.GetInt("download.verify-retries"))
+		ifExists, err := download.ParseIfExists(viper.GetString("download.if-exists"))
+		if err != nil {
+			return err
+		}
+		download.SetIfExists(ifExists)
+		download.SetArchiveRoots(viper.GetStringSlice("download.archive-root")...)
+		if root := viper.GetString("download.cas"); len(root) > 0 {
+			cas, err := download.NewCAS(root)
+			if err != nil {
+				return fmt.Errorf("--cas: %v", err)
+			}
+			download.SetCAS(cas)
+		}
+		// aria2c RPC secret i.e. `IPSW_DOWNLOAD_ARIA2_SECRET=secret ipsw download ipsw --aria2 http://localhost:6800/jsonrpc`
+		if rpc := viper.GetString("download.aria2"); len(rpc) > 0 {
+			a := download.NewAria2(&download.Aria2Config{
+				RPC:    rpc,
+				Secret: viper.GetString("download.aria2-secret"),
+			})
+			version, err := a.Version(cmd.Context())
+			if err != nil {
+				return fmt.Errorf("--aria2: %v", err)
+			}
+			log.Debugf("Downloading with aria2 %s (%s)", version, rpc)
+			download.SetAria2(a)
+		}
+		download.EnableKeybindings()
+		return nil
 	},
 	Run: func(cmd *cobra.Command, args []string) {
 		cmd.Help()
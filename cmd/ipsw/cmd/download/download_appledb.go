@@ -116,7 +116,7 @@ var downloadAppledbCmd = &cobra.Command{
 		restartAll := viper.GetBool("download.restart-all")
 		removeCommas := viper.GetBool("download.remove-commas")
 		// filters
-		device := viper.GetString("download.device")
+		device := download.ResolveDevice(viper.GetString("download.device"))
 		version := viper.GetString("download.version")
 		build := viper.GetString("download.build")
 		// flags
@@ -237,7 +237,9 @@ var downloadAppledbCmd = &cobra.Command{
 				prompt := &survey.Confirm{
 					Message: fmt.Sprintf("You are about to download %d IPSW files. Continue?", len(results)),
 				}
-				survey.AskOne(prompt, &cont)
+				if err := utils.AskOne(prompt, &cont); err != nil {
+					return err
+				}
 			}
 		}
 
@@ -288,20 +290,23 @@ var downloadAppledbCmd = &cobra.Command{
 						}
 					}
 					fname := filepath.Join(destPath, getDestName(url, removeCommas))
-					if _, err := os.Stat(fname); os.IsNotExist(err) {
+					downloader.URL = url
+					downloader.DestName = fname
+					downloader.Sha1 = result.Hashes.Sha1
+					downloader.SHA256 = result.Hashes.Sha2256
+					if skip, err := downloader.SkipExisting(); err != nil {
+						return err
+					} else if !skip {
 						d, v, b := download.ParseIpswURLString(url)
 						log.WithFields(log.Fields{"devices": d, "build": b, "version": v}).Infof("Getting (%d/%d) IPSW", idx+1, len(results))
 						// download file
-						downloader.URL = url
-						downloader.DestName = fname
-						downloader.Sha1 = result.Hashes.Sha1
-
 						err = downloader.Do()
 						if err != nil {
 							return fmt.Errorf("failed to download IPSW: %v", err)
 						}
-					} else {
-						log.Warnf("IPSW already exists: %s", fname)
+						if err := download.StoreCAS(fname, d, b); err != nil {
+							log.Errorf("failed to store %s in CAS: %v", fname, err)
+						}
 					}
 				}
 			}
@@ -0,0 +1,182 @@
+/*
+Copyright © 2023 blacktop
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package download
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/AlecAivazis/survey/v2/terminal"
+	"github.com/apex/log"
+	"github.com/blacktop/ipsw/internal/download"
+	"github.com/blacktop/ipsw/internal/exitcode"
+	"github.com/blacktop/ipsw/internal/utils"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func init() {
+	DownloadCmd.AddCommand(bridgeosCmd)
+
+	bridgeosCmd.Flags().BoolP("list", "l", false, "Show available bridgeOS updates")
+	bridgeosCmd.Flags().Bool("latest", false, "Download latest bridgeOS update")
+	bridgeosCmd.Flags().Bool("ignore", false, "Do NOT verify pkg digests")
+	bridgeosCmd.Flags().StringP("output", "o", "", "Folder to download files to")
+	bridgeosCmd.MarkFlagDirname("output")
+	bridgeosCmd.SetHelpFunc(func(c *cobra.Command, s []string) {
+		DownloadCmd.PersistentFlags().MarkHidden("white-list")
+		DownloadCmd.PersistentFlags().MarkHidden("black-list")
+		DownloadCmd.PersistentFlags().MarkHidden("device")
+		DownloadCmd.PersistentFlags().MarkHidden("remove-commas")
+		c.Parent().HelpFunc()(c, s)
+	})
+	viper.BindPFlag("download.bridgeos.list", bridgeosCmd.Flags().Lookup("list"))
+	viper.BindPFlag("download.bridgeos.latest", bridgeosCmd.Flags().Lookup("latest"))
+	viper.BindPFlag("download.bridgeos.ignore", bridgeosCmd.Flags().Lookup("ignore"))
+	viper.BindPFlag("download.bridgeos.output", bridgeosCmd.Flags().Lookup("output"))
+}
+
+// bridgeosCmd represents the bridgeos command
+var bridgeosCmd = &cobra.Command{
+	Use:           "bridgeos",
+	Aliases:       []string{"bridge"},
+	Short:         "Download bridgeOS firmware updates (for T2 Macs)",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+
+		if viper.GetBool("verbose") {
+			log.SetLevel(log.DebugLevel)
+		}
+
+		viper.BindPFlag("download.proxy", cmd.Flags().Lookup("proxy"))
+		viper.BindPFlag("download.insecure", cmd.Flags().Lookup("insecure"))
+		viper.BindPFlag("download.confirm", cmd.Flags().Lookup("confirm"))
+		viper.BindPFlag("download.skip-all", cmd.Flags().Lookup("skip-all"))
+		viper.BindPFlag("download.resume-all", cmd.Flags().Lookup("resume-all"))
+		viper.BindPFlag("download.restart-all", cmd.Flags().Lookup("restart-all"))
+		viper.BindPFlag("download.model", cmd.Flags().Lookup("model"))
+		viper.BindPFlag("download.version", cmd.Flags().Lookup("version"))
+		viper.BindPFlag("download.build", cmd.Flags().Lookup("build"))
+
+		// settings
+		proxy := viper.GetString("download.proxy")
+		insecure := viper.GetBool("download.insecure")
+		confirm := viper.GetBool("download.confirm")
+		skipAll := viper.GetBool("download.skip-all")
+		resumeAll := viper.GetBool("download.resume-all")
+		restartAll := viper.GetBool("download.restart-all")
+		// filters
+		model := viper.GetString("download.model")
+		version := viper.GetString("download.version")
+		build := viper.GetString("download.build")
+		// flags
+		latest := viper.GetBool("download.bridgeos.latest")
+		ignoreSha1 := viper.GetBool("download.bridgeos.ignore")
+		output := viper.GetString("download.bridgeos.output")
+
+		// verify args
+		if len(version) > 0 && len(build) > 0 {
+			return fmt.Errorf("you cannot supply a --version AND a --build (they are mutually exclusive)")
+		} else if (len(version) > 0 || len(build) > 0) && latest {
+			return fmt.Errorf("you cannot supply a --latest AND (--version OR --build) (they are mutually exclusive)")
+		}
+
+		bos, err := download.GetBridgeOSInfo()
+		if err != nil {
+			return err
+		}
+
+		// filter updates
+		if len(model) > 0 {
+			bos = bos.FilterByModel(model)
+		}
+		if len(version) > 0 {
+			bos = bos.FilterByVersion(version)
+		} else if len(build) > 0 {
+			bos = bos.FilterByBuild(build)
+		} else if latest {
+			bos = bos.GetLatest()
+		}
+
+		if len(bos) == 0 {
+			return exitcode.Errorf(exitcode.NotFound, "no bridgeOS updates found for given options")
+		}
+
+		if viper.GetBool("download.bridgeos.list") {
+			for _, b := range bos {
+				fmt.Println(b)
+			}
+			return nil
+		}
+
+		if len(bos) > 1 && len(build) == 0 && !latest {
+			var choices []string
+			for _, b := range bos {
+				choices = append(choices, fmt.Sprintf("%-35s%-8s %-8s %s", b.Title, b.Version, b.Build, utils.FormatTime(b.PostDate)))
+			}
+			selected := []int{}
+			prompt := &survey.MultiSelect{
+				Message:  "Choose bridgeOS update(s):",
+				Options:  choices,
+				PageSize: 25,
+			}
+			if err := utils.AskOne(prompt, &selected); err != nil {
+				if err == terminal.InterruptErr {
+					log.Warn("Exiting...")
+					os.Exit(0)
+				}
+				return err
+			}
+			var chosen download.BridgeOSInfos
+			for _, idx := range selected {
+				chosen = append(chosen, bos[idx])
+			}
+			bos = chosen
+		}
+
+		cont := true
+		if !confirm {
+			prompt := &survey.Confirm{
+				Message: fmt.Sprintf("You are about to download %d bridgeOS update(s). Continue?", len(bos)),
+			}
+			if err := utils.AskOne(prompt, &cont); err != nil {
+				if err == terminal.InterruptErr {
+					log.Warn("Exiting...")
+					os.Exit(0)
+				}
+				return err
+			}
+		}
+
+		if cont {
+			for _, b := range bos {
+				if err := b.Download(output, proxy, insecure, skipAll, resumeAll, restartAll, ignoreSha1); err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+	},
+}
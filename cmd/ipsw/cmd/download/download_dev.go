@@ -35,6 +35,8 @@ import (
 	"github.com/AlecAivazis/survey/v2"
 	"github.com/AlecAivazis/survey/v2/terminal"
 	"github.com/apex/log"
+	"github.com/blacktop/ipsw/internal/exitcode"
+	"github.com/blacktop/ipsw/internal/utils"
 	"github.com/caarlos0/ctrlc"
 
 	"github.com/blacktop/ipsw/internal/download"
@@ -50,10 +52,24 @@ func init() {
 	devCmd.Flags().Bool("sms", false, "Prefer SMS Two-factor authentication")
 	devCmd.Flags().Bool("json", false, "Output downloadable items as JSON")
 	devCmd.Flags().Bool("pretty", false, "Pretty print JSON")
-	devCmd.Flags().Bool("kdk", false, "Download KDK")
+	devCmd.Flags().Bool("kdk", false, "Download KDK for --build (or current host OS)")
 	devCmd.Flags().DurationP("timeout", "t", 5*time.Minute, "Timeout for watch attempts in minutes")
 	devCmd.Flags().StringP("output", "o", "", "Folder to download files to")
 	devCmd.Flags().StringP("vault-password", "k", "", "Password to unlock credential vault (only for file vaults)")
+	devCmd.Flags().String("keyring-backend", "auto", "Credential keyring backend (auto, system, env, keychain, secret-service, kwallet, keyctl, wincred, pass or file)")
+	devCmd.Flags().String("pattern", "", "Download all files whose name matches regex (without prompting)")
+	devCmd.Flags().String("filter", "", "Download all files whose name matches glob (without prompting)")
+	devCmd.Flags().String("account", "", "Vault account profile to use (see `ipsw dev auth list`)")
+	devCmd.Flags().StringP("username", "u", "", "Apple ID username (or IPSW_DOWNLOAD_DEV_USERNAME)")
+	devCmd.Flags().String("password", "", "Apple ID password (or IPSW_DOWNLOAD_DEV_PASSWORD)")
+	devCmd.Flags().Bool("headless", false, "Never prompt (for CI; requires credentials, --vault-password and --trust-token)")
+	devCmd.Flags().String("trust-token", "", "Pre-seeded 2FA trust token (or IPSW_DOWNLOAD_DEV_TRUST_TOKEN)")
+	devCmd.Flags().Bool("show-trust-token", false, "Print the 2FA trust token after logging in (to seed --trust-token)")
+	devCmd.Flags().Bool("refresh", false, "Re-fetch the downloads list (ignore the local cache)")
+	devCmd.Flags().Duration("cache-ttl", download.DefaultDevCacheTTL, "How long to cache the downloads list (0 to disable)")
+	devCmd.Flags().String("webhook", "", "Webhook URL to POST NEW watched items to (as JSON)")
+	devCmd.Flags().String("slack-webhook", "", "Slack incoming webhook URL to notify of NEW watched items")
+	devCmd.Flags().String("discord-webhook", "", "Discord webhook URL to notify of NEW watched items")
 	viper.BindPFlag("download.dev.watch", devCmd.Flags().Lookup("watch"))
 	viper.BindPFlag("download.dev.os", devCmd.Flags().Lookup("os"))
 	viper.BindPFlag("download.dev.more", devCmd.Flags().Lookup("more"))
@@ -65,7 +81,20 @@ func init() {
 	viper.BindPFlag("download.dev.timeout", devCmd.Flags().Lookup("timeout"))
 	viper.BindPFlag("download.dev.output", devCmd.Flags().Lookup("output"))
 	viper.BindPFlag("download.dev.vault-password", devCmd.Flags().Lookup("vault-password"))
-	devCmd.Flags().MarkHidden("kdk")
+	viper.BindPFlag("download.dev.keyring-backend", devCmd.Flags().Lookup("keyring-backend"))
+	viper.BindPFlag("download.dev.pattern", devCmd.Flags().Lookup("pattern"))
+	viper.BindPFlag("download.dev.filter", devCmd.Flags().Lookup("filter"))
+	viper.BindPFlag("download.dev.account", devCmd.Flags().Lookup("account"))
+	viper.BindPFlag("download.dev.username", devCmd.Flags().Lookup("username"))
+	viper.BindPFlag("download.dev.password", devCmd.Flags().Lookup("password"))
+	viper.BindPFlag("download.dev.headless", devCmd.Flags().Lookup("headless"))
+	viper.BindPFlag("download.dev.trust-token", devCmd.Flags().Lookup("trust-token"))
+	viper.BindPFlag("download.dev.show-trust-token", devCmd.Flags().Lookup("show-trust-token"))
+	viper.BindPFlag("download.dev.refresh", devCmd.Flags().Lookup("refresh"))
+	viper.BindPFlag("download.dev.cache-ttl", devCmd.Flags().Lookup("cache-ttl"))
+	viper.BindPFlag("download.dev.webhook", devCmd.Flags().Lookup("webhook"))
+	viper.BindPFlag("download.dev.slack-webhook", devCmd.Flags().Lookup("slack-webhook"))
+	viper.BindPFlag("download.dev.discord-webhook", devCmd.Flags().Lookup("discord-webhook"))
 	devCmd.MarkFlagDirname("output")
 	devCmd.SetHelpFunc(func(c *cobra.Command, s []string) {
 		DownloadCmd.PersistentFlags().MarkHidden("white-list")
@@ -81,9 +110,20 @@ func init() {
 
 // devCmd represents the dev command
 var devCmd = &cobra.Command{
-	Use:           "dev",
-	Aliases:       []string{"d", "developer"},
-	Short:         "Download IPSWs (and more) from https://developer.apple.com/download",
+	Use:     "dev",
+	Aliases: []string{"d", "developer"},
+	Short:   "Download IPSWs (and more) from https://developer.apple.com/download",
+	Example: `  # Download all the Xcode betas without prompting
+  ❯ ipsw download dev --more --pattern '^Xcode.*beta'
+  # Download the iPhone15,2 IPSWs of any OS version
+  ❯ ipsw download dev --os --filter '*iPhone15,2*'
+  # Get a 2FA trust token (once, interactively) to seed CI with
+  ❯ ipsw download dev --show-trust-token
+  # Watch for NEW Xcode betas and notify a Slack channel
+  ❯ ipsw download dev --more --watch '^Xcode.*beta' --slack-webhook https://hooks.slack.com/services/...
+  # Download new Xcode releases in CI
+  ❯ IPSW_DOWNLOAD_DEV_USERNAME=user@example.com IPSW_DOWNLOAD_DEV_PASSWORD=... IPSW_DOWNLOAD_DEV_VAULT_PASSWORD=... \
+    IPSW_DOWNLOAD_DEV_TRUST_TOKEN=... ipsw download dev --headless --more --pattern '^Xcode'`,
 	SilenceUsage:  true,
 	SilenceErrors: true,
 	RunE: func(cmd *cobra.Command, args []string) error {
@@ -117,6 +157,22 @@ var devCmd = &cobra.Command{
 		asJSON := viper.GetBool("download.dev.json")
 		prettyJSON := viper.GetBool("download.dev.pretty")
 		output := viper.GetString("download.dev.output")
+		pattern := viper.GetString("download.dev.pattern")
+		filter := viper.GetString("download.dev.filter")
+		headless := viper.GetBool("download.dev.headless")
+		nonInteractive := len(pattern) > 0 || len(filter) > 0
+
+		if headless && !nonInteractive && !viper.GetBool("download.dev.kdk") && !asJSON && !viper.GetBool("download.dev.show-trust-token") {
+			return fmt.Errorf("--headless requires --pattern, --filter, --kdk or --json")
+		}
+		if headless && len(watchList) > 0 {
+			return fmt.Errorf("--headless cannot be used with --watch")
+		}
+
+		// never prompt to resume partial downloads when run non-interactively
+		if nonInteractive && !skipAll && !restartAll {
+			resumeAll = true
+		}
 
 		username := viper.GetString("download.dev.username")
 		password := viper.GetString("download.dev.password")
@@ -127,18 +183,31 @@ var devCmd = &cobra.Command{
 		}
 
 		app := download.NewDevPortal(&download.DevConfig{
-			Proxy:         proxy,
-			Insecure:      insecure,
-			SkipAll:       skipAll,
-			ResumeAll:     resumeAll,
-			RestartAll:    restartAll,
-			RemoveCommas:  removeCommas,
-			PreferSMS:     sms,
-			PageSize:      pageSize,
-			WatchList:     watchList,
-			ConfigDir:     filepath.Join(home, ".ipsw"),
-			VaultPassword: viper.GetString("download.dev.vault-password"),
-			Verbose:       viper.GetBool("verbose"),
+			Proxy:        proxy,
+			Insecure:     insecure,
+			SkipAll:      skipAll,
+			ResumeAll:    resumeAll,
+			RestartAll:   restartAll,
+			RemoveCommas: removeCommas,
+			PreferSMS:    sms,
+			PageSize:     pageSize,
+			WatchList:    watchList,
+			Notifiers: download.NewNotifiers(&download.NotifyConfig{
+				WebhookURL:        viper.GetString("download.dev.webhook"),
+				SlackWebhookURL:   viper.GetString("download.dev.slack-webhook"),
+				DiscordWebhookURL: viper.GetString("download.dev.discord-webhook"),
+				Proxy:             proxy,
+				Insecure:          insecure,
+			}),
+			ConfigDir:      filepath.Join(home, ".ipsw"),
+			VaultPassword:  viper.GetString("download.dev.vault-password"),
+			KeyringBackend: viper.GetString("download.dev.keyring-backend"),
+			Account:        viper.GetString("download.dev.account"),
+			CacheTTL:       viper.GetDuration("download.dev.cache-ttl"),
+			RefreshCache:   viper.GetBool("download.dev.refresh"),
+			Verbose:        viper.GetBool("verbose"),
+			Headless:       headless,
+			TrustToken:     viper.GetString("download.dev.trust-token"),
 		})
 
 		if err := app.Init(); err != nil {
@@ -146,7 +215,15 @@ var devCmd = &cobra.Command{
 		}
 
 		if err := app.Login(username, password); err != nil {
-			return fmt.Errorf("failed to login: %v", err)
+			return exitcode.Errorf(exitcode.Auth, "failed to login: %v", err)
+		}
+
+		if viper.GetBool("download.dev.show-trust-token") {
+			if len(app.GetTrustToken()) == 0 {
+				return fmt.Errorf("no 2FA trust token in session (log in again to get one)")
+			}
+			fmt.Println(app.GetTrustToken())
+			return nil
 		}
 
 		if viper.GetBool("download.dev.kdk") {
@@ -158,12 +235,16 @@ var devCmd = &cobra.Command{
 			dlType = "os"
 		} else if viper.GetBool("download.dev.more") {
 			dlType = "more"
+		} else if nonInteractive {
+			dlType = "all"
+		} else if headless {
+			dlType = "more"
 		} else {
 			prompt := &survey.Select{
 				Message: "Choose a download type:",
 				Options: []string{"OSes (iOS, macOS, tvOS...)", "More (XCode, KDKs...)"},
 			}
-			if err := survey.AskOne(prompt, &dlType); err != nil {
+			if err := utils.AskOne(prompt, &dlType); err != nil {
 				if err == terminal.InterruptErr {
 					log.Warn("Exiting...")
 					os.Exit(0)
@@ -196,6 +277,26 @@ var devCmd = &cobra.Command{
 			}
 		}
 
+		if nonInteractive {
+			dlTypes := []string{dlType}
+			if dlType == "all" {
+				dlTypes = []string{"os", "more"}
+			}
+			var matched int
+			for _, typ := range dlTypes {
+				n, err := app.DownloadMatching(typ, pattern, filter, output)
+				if err != nil {
+					return err
+				}
+				matched += n
+			}
+			if matched == 0 {
+				return exitcode.Errorf(exitcode.NotFound, "no downloads matched --pattern %#v / --filter %#v", pattern, filter)
+			}
+			log.Infof("Downloaded %d file(s)", matched)
+			return nil
+		}
+
 		if asJSON {
 			if dat, err := app.GetDownloadsAsJSON(dlType, prettyJSON); err != nil {
 				return fmt.Errorf("failed to get downloads as JSON: %v", err)
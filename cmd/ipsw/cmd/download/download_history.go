@@ -0,0 +1,132 @@
+/*
+Copyright © 2023 blacktop
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package download
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/apex/log"
+	"github.com/blacktop/ipsw/internal/download"
+	"github.com/blacktop/ipsw/internal/utils"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func init() {
+	DownloadCmd.AddCommand(historyCmd)
+
+	historyCmd.Flags().String("host", "", "Only show downloads from host (i.e. updates.cdn-apple.com)")
+	historyCmd.Flags().IntP("limit", "n", 20, "Number of most recent downloads to show (0 for all)")
+	historyCmd.Flags().Bool("json", false, "Output as JSON")
+	historyCmd.SetHelpFunc(func(c *cobra.Command, s []string) {
+		DownloadCmd.PersistentFlags().MarkHidden("white-list")
+		DownloadCmd.PersistentFlags().MarkHidden("black-list")
+		DownloadCmd.PersistentFlags().MarkHidden("device")
+		DownloadCmd.PersistentFlags().MarkHidden("model")
+		DownloadCmd.PersistentFlags().MarkHidden("version")
+		DownloadCmd.PersistentFlags().MarkHidden("build")
+		DownloadCmd.PersistentFlags().MarkHidden("confirm")
+		DownloadCmd.PersistentFlags().MarkHidden("skip-all")
+		DownloadCmd.PersistentFlags().MarkHidden("resume-all")
+		DownloadCmd.PersistentFlags().MarkHidden("restart-all")
+		DownloadCmd.PersistentFlags().MarkHidden("remove-commas")
+		DownloadCmd.PersistentFlags().MarkHidden("proxy")
+		DownloadCmd.PersistentFlags().MarkHidden("insecure")
+		c.Parent().HelpFunc()(c, s)
+	})
+	viper.BindPFlag("download.history.host", historyCmd.Flags().Lookup("host"))
+	viper.BindPFlag("download.history.limit", historyCmd.Flags().Lookup("limit"))
+	viper.BindPFlag("download.history.json", historyCmd.Flags().Lookup("json"))
+}
+
+// historyCmd represents the history command
+var historyCmd = &cobra.Command{
+	Use:           "history",
+	Short:         "Show bandwidth stats of previous downloads",
+	Args:          cobra.NoArgs,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+
+		if viper.GetBool("verbose") {
+			log.SetLevel(log.DebugLevel)
+		}
+
+		stats, err := download.GetHistory()
+		if err != nil {
+			return err
+		}
+
+		if host := viper.GetString("download.history.host"); len(host) > 0 {
+			var filtered []download.Stats
+			for _, s := range stats {
+				if strings.EqualFold(s.Host, host) {
+					filtered = append(filtered, s)
+				}
+			}
+			stats = filtered
+		}
+		if limit := viper.GetInt("download.history.limit"); limit > 0 && len(stats) > limit {
+			stats = stats[len(stats)-limit:]
+		}
+
+		if viper.GetBool("download.history.json") {
+			dat, err := json.Marshal(stats)
+			if err != nil {
+				return fmt.Errorf("failed to marshal download history: %v", err)
+			}
+			fmt.Println(string(dat))
+			return nil
+		}
+
+		if len(stats) == 0 {
+			log.Warn("No downloads found in history")
+			return nil
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "DATE\tHOST\tFILE\tSIZE\tDURATION\tAVG\tPEAK\tRETRIES\tRESUMED\tSTATE")
+		for _, s := range stats {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s/s\t%s/s\t%d\t%s\t%s\n",
+				utils.FormatTime(s.Started),
+				s.Host,
+				filepath.Base(s.DestName),
+				utils.FormatBytes(uint64(s.Bytes)),
+				s.Duration.Round(time.Second),
+				utils.FormatBytes(uint64(s.AvgSpeed)),
+				utils.FormatBytes(uint64(s.PeakSpeed)),
+				s.Retries,
+				utils.FormatBytes(uint64(s.ResumedBytes)),
+				s.State,
+			)
+		}
+		w.Flush()
+
+		return nil
+	},
+}
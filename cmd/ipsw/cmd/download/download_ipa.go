@@ -32,6 +32,8 @@ import (
 	"github.com/AlecAivazis/survey/v2/terminal"
 	"github.com/apex/log"
 	"github.com/blacktop/ipsw/internal/download"
+	"github.com/blacktop/ipsw/internal/exitcode"
+	"github.com/blacktop/ipsw/internal/utils"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -43,12 +45,14 @@ func init() {
 	ipaCmd.Flags().StringP("output", "o", "", "Folder to download files to")
 	ipaCmd.Flags().StringP("store-front", "s", "US", "The country code for the App Store to download from")
 	ipaCmd.Flags().StringP("vault-password", "k", "", "Password to unlock credential vault (only for file vaults)")
+	ipaCmd.Flags().String("keyring-backend", "auto", "Credential keyring backend (auto, system, env, keychain, secret-service, kwallet, keyctl, wincred, pass or file)")
 	ipaCmd.MarkFlagDirname("output")
 	viper.BindPFlag("download.ipa.sms", ipaCmd.Flags().Lookup("sms"))
 	viper.BindPFlag("download.ipa.search", ipaCmd.Flags().Lookup("search"))
 	viper.BindPFlag("download.ipa.output", ipaCmd.Flags().Lookup("output"))
 	viper.BindPFlag("download.ipa.store-front", ipaCmd.Flags().Lookup("store-front"))
 	viper.BindPFlag("download.ipa.vault-password", ipaCmd.Flags().Lookup("vault-password"))
+	viper.BindPFlag("download.ipa.keyring-backend", ipaCmd.Flags().Lookup("keyring-backend"))
 	ipaCmd.SetHelpFunc(func(c *cobra.Command, s []string) {
 		DownloadCmd.PersistentFlags().MarkHidden("white-list")
 		DownloadCmd.PersistentFlags().MarkHidden("black-list")
@@ -99,13 +103,14 @@ var ipaCmd = &cobra.Command{
 		}
 
 		as := download.NewAppStore(&download.AppStoreConfig{
-			Proxy:         proxy,
-			Insecure:      insecure,
-			PreferSMS:     sms,
-			ConfigDir:     filepath.Join(home, ".ipsw"),
-			VaultPassword: viper.GetString("download.dev.vault-password"),
-			StoreFront:    viper.GetString("download.ipa.store-front"),
-			Verbose:       viper.GetBool("verbose"),
+			Proxy:          proxy,
+			Insecure:       insecure,
+			PreferSMS:      sms,
+			ConfigDir:      filepath.Join(home, ".ipsw"),
+			VaultPassword:  viper.GetString("download.dev.vault-password"),
+			KeyringBackend: viper.GetString("download.ipa.keyring-backend"),
+			StoreFront:     viper.GetString("download.ipa.store-front"),
+			Verbose:        viper.GetBool("verbose"),
 		})
 
 		if err := as.Init(); err != nil {
@@ -113,7 +118,7 @@ var ipaCmd = &cobra.Command{
 		}
 
 		if err := as.Login(username, password); err != nil {
-			return fmt.Errorf("failed to login to App Store: %v", err)
+			return exitcode.Errorf(exitcode.Auth, "failed to login to App Store: %v", err)
 		}
 
 		if viper.GetBool("download.ipa.search") {
@@ -133,7 +138,7 @@ var ipaCmd = &cobra.Command{
 				Options:  choices,
 				PageSize: 20,
 			}
-			if err := survey.AskOne(prompt, &dfiles); err != nil {
+			if err := utils.AskOne(prompt, &dfiles); err != nil {
 				if err == terminal.InterruptErr {
 					log.Warn("Exiting...")
 					os.Exit(0)
@@ -22,6 +22,8 @@ THE SOFTWARE.
 package download
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -31,8 +33,10 @@ import (
 	"github.com/apex/log"
 	"github.com/blacktop/ipsw/internal/commands/extract"
 	"github.com/blacktop/ipsw/internal/download"
+	"github.com/blacktop/ipsw/internal/exitcode"
 	"github.com/blacktop/ipsw/internal/utils"
 	"github.com/blacktop/ipsw/pkg/info"
+	"github.com/blacktop/ipsw/pkg/tss"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -52,10 +56,21 @@ func init() {
 	// ipswCmd.Flags().BoolP("kernel-spec", "", false, "Download kernels into spec folders")
 	ipswCmd.Flags().String("pattern", "", "Download remote files that match regex")
 	ipswCmd.Flags().Bool("beta", false, "Download Beta IPSWs")
-	ipswCmd.Flags().StringP("output", "o", "", "Folder to download files to")
+	ipswCmd.Flags().Bool("itunes", false, "Use the iTunes version XML catalog instead of the ipsw.me API")
+	ipswCmd.Flags().StringP("output", "o", "", "Folder to download files to (- streams a single IPSW to stdout)")
 	ipswCmd.Flags().BoolP("flat", "f", false, "Do NOT perserve directory structure when downloading with --pattern")
 	ipswCmd.Flags().BoolP("urls", "u", false, "Dump URLs only")
 	ipswCmd.Flags().Bool("usb", false, "Download IPSWs for USB attached iDevices")
+	ipswCmd.Flags().Bool("shsh", false, "Save SHSH blobs alongside downloaded signed IPSWs")
+	ipswCmd.Flags().String("ecid", "", "Device ECID to save SHSH blobs for (hex with 0x prefix or decimal)")
+	ipswCmd.Flags().String("generator", "", "Boot-nonce generator to save SHSH blobs for (default: "+tss.DefaultGenerator+")")
+	ipswCmd.Flags().IntP("threads", "t", 1, "Number of concurrent connections per IPSW download")
+	ipswCmd.Flags().IntP("parallel", "p", 1, "Number of IPSWs to download at once")
+	ipswCmd.Flags().Int("per-host", 0, "Max number of IPSWs to download at once from the same host (0 is unlimited)")
+	ipswCmd.Flags().Bool("json", false, "Output the downloaded IPSWs (and their checksum verification) as JSON")
+	ipswCmd.Flags().String("manifest", "", "Write a checksum manifest of the downloaded IPSWs to file (.csv or .json)")
+	ipswCmd.Flags().StringSlice("sources", download.DefaultSources, "Sources to fall back to (in order) when an IPSW URL is dead")
+	ipswCmd.Flags().Bool("no-failover", false, "Do NOT fall back to other sources when an IPSW URL is dead")
 	ipswCmd.MarkFlagDirname("output")
 
 	viper.BindPFlag("download.ipsw.latest", ipswCmd.Flags().Lookup("latest"))
@@ -69,10 +84,37 @@ func init() {
 	// viper.BindPFlag("download.ipsw.kernel-spec", ipswCmd.Flags().Lookup("kernel-spec"))
 	viper.BindPFlag("download.ipsw.pattern", ipswCmd.Flags().Lookup("pattern"))
 	viper.BindPFlag("download.ipsw.beta", ipswCmd.Flags().Lookup("beta"))
+	viper.BindPFlag("download.ipsw.itunes", ipswCmd.Flags().Lookup("itunes"))
 	viper.BindPFlag("download.ipsw.output", ipswCmd.Flags().Lookup("output"))
 	viper.BindPFlag("download.ipsw.flat", ipswCmd.Flags().Lookup("flat"))
 	viper.BindPFlag("download.ipsw.urls", ipswCmd.Flags().Lookup("urls"))
 	viper.BindPFlag("download.ipsw.usb", ipswCmd.Flags().Lookup("usb"))
+	viper.BindPFlag("download.ipsw.shsh", ipswCmd.Flags().Lookup("shsh"))
+	viper.BindPFlag("download.ipsw.ecid", ipswCmd.Flags().Lookup("ecid"))
+	viper.BindPFlag("download.ipsw.generator", ipswCmd.Flags().Lookup("generator"))
+	viper.BindPFlag("download.ipsw.threads", ipswCmd.Flags().Lookup("threads"))
+	viper.BindPFlag("download.ipsw.parallel", ipswCmd.Flags().Lookup("parallel"))
+	viper.BindPFlag("download.ipsw.per-host", ipswCmd.Flags().Lookup("per-host"))
+	viper.BindPFlag("download.ipsw.json", ipswCmd.Flags().Lookup("json"))
+	viper.BindPFlag("download.ipsw.manifest", ipswCmd.Flags().Lookup("manifest"))
+	viper.BindPFlag("download.ipsw.sources", ipswCmd.Flags().Lookup("sources"))
+	viper.BindPFlag("download.ipsw.no-failover", ipswCmd.Flags().Lookup("no-failover"))
+}
+
+// ipswResult is a downloaded IPSW in the --json output
+type ipswResult struct {
+	download.IPSW
+	Path         string                 `json:"path"`
+	Verification *download.Verification `json:"verification,omitempty"`
+	Error        string                 `json:"error,omitempty"`
+}
+
+// verification returns the checksum verification of a finished download (nil if it was not verified)
+func verification(dl *download.Download) *download.Verification {
+	if status := dl.Status(); status.Stats != nil {
+		return status.Stats.Verification
+	}
+	return nil
 }
 
 // ipswCmd represents the ipsw command
@@ -117,7 +159,7 @@ var ipswCmd = &cobra.Command{
 		restartAll := viper.GetBool("download.restart-all")
 		removeCommas := viper.GetBool("download.remove-commas")
 		// filters
-		device := viper.GetString("download.device")
+		device := download.ResolveDevice(viper.GetString("download.device"))
 		// model := viper.GetString("download.model")
 		// version := viper.GetString("download.version")
 		// build := viper.GetString("download.build")
@@ -136,8 +178,34 @@ var ipswCmd = &cobra.Command{
 		remotePattern := viper.GetString("download.ipsw.pattern")
 		output := viper.GetString("download.ipsw.output")
 		flat := viper.GetBool("download.ipsw.flat")
+		saveBlobs := viper.GetBool("download.ipsw.shsh")
+		parallel := viper.GetInt("download.ipsw.parallel")
 		// beta := viper.GetBool("download.ipsw.beta")
 
+		var ecid uint64
+
+		// failover chain of dead IPSW URLs i.e. `download: {ipsw: {sources: [itunes, ipsw.me, cdn]}}`
+		var failover *download.FailoverConfig
+		if !viper.GetBool("download.ipsw.no-failover") {
+			sources, err := download.ParseSources(viper.GetStringSlice("download.ipsw.sources"))
+			if err != nil {
+				return err
+			}
+			failover = &download.FailoverConfig{
+				Sources:  sources,
+				Proxy:    proxy,
+				Insecure: insecure,
+				APIToken: os.Getenv("GITHUB_TOKEN"),
+			}
+		}
+		// resolve replaces a dead IPSW URL with the first live one of the failover chain
+		resolve := func(i download.IPSW) (download.IPSW, error) {
+			if failover == nil {
+				return i, nil
+			}
+			return download.Failover(i, failover)
+		}
+
 		// verify args
 		if len(dyldArches) > 0 && !remoteDSC {
 			return errors.New("--dyld-arch can only be used with --dyld")
@@ -152,6 +220,9 @@ var ipswCmd = &cobra.Command{
 		if showLatestBuild && len(device) == 0 {
 			return errors.New("--show-latest-build requires --device to be set")
 		}
+		if output == "-" && (remoteKernel || remoteDSC || len(remotePattern) > 0 || saveBlobs || parallel > 1 || viper.GetBool("download.ipsw.json") || len(viper.GetString("download.ipsw.manifest")) > 0) {
+			return errors.New("--output - cannot be used with --kernel, --dyld, --pattern, --shsh, --parallel, --json or --manifest")
+		}
 
 		if viper.GetBool("download.ipsw.usb") {
 			dev, err := utils.PickDevice()
@@ -160,6 +231,18 @@ var ipswCmd = &cobra.Command{
 			}
 			dFlg.Device = dev.ProductType
 			dFlg.Build = dev.BuildVersion
+			ecid = uint64(dev.UniqueChipID)
+		}
+
+		if saveBlobs && ecid == 0 {
+			if len(viper.GetString("download.ipsw.ecid")) == 0 {
+				return errors.New("--shsh requires --ecid OR --usb to be set")
+			}
+			var err error
+			ecid, err = tss.ParseECID(viper.GetString("download.ipsw.ecid"))
+			if err != nil {
+				return err
+			}
 		}
 
 		if len(device) > 0 {
@@ -170,6 +253,8 @@ var ipswCmd = &cobra.Command{
 			if dev, err := db.LookupDevice(device); err == nil {
 				if dev.SDKPlatform == "macosx" {
 					macos = true
+				} else if dev.Type == "bridgeos" {
+					ibridge = true // bridgeOS IPSWs are in the iBridge catalog
 				}
 			}
 		}
@@ -267,11 +352,11 @@ var ipswCmd = &cobra.Command{
 
 			for _, v := range builds {
 				if len(doDownload) > 0 {
-					if utils.StrSliceHas(doDownload, v.Identifier) {
+					if utils.StrSliceHas(doDownload, v.Identifier) || download.InDeviceClasses(v.Identifier, doDownload) {
 						filteredBuilds = append(filteredBuilds, v)
 					}
 				} else if len(doNotDownload) > 0 {
-					if !utils.StrSliceHas(doNotDownload, v.Identifier) {
+					if !utils.StrSliceHas(doNotDownload, v.Identifier) && !download.InDeviceClasses(v.Identifier, doNotDownload) {
 						filteredBuilds = append(filteredBuilds, v)
 					}
 				} else {
@@ -295,7 +380,7 @@ var ipswCmd = &cobra.Command{
 				})
 			}
 		} else {
-			ipsws, err = filterIPSWs(cmd, macos)
+			ipsws, err = filterIPSWs(cmd, itunes, macos)
 			if err != nil {
 				log.Fatal(err.Error())
 			}
@@ -312,6 +397,45 @@ var ipswCmd = &cobra.Command{
 			utils.Indent(log.Debug, 2)(i.URL)
 		}
 
+		warned := make(map[int]bool)
+		for _, i := range ipsws {
+			if download.DeviceClassForIdentifier(i.Identifier) != "watchos" {
+				continue
+			}
+			if compat, err := download.GetWatchCompatibility(i.Version); err == nil && !warned[compat.WatchOS] {
+				log.Warn(compat.String())
+				warned[compat.WatchOS] = true
+			}
+		}
+		if len(warned) > 0 {
+			utils.Indent(log.Warn, 2)(download.WatchRestoreNote)
+		}
+
+		// STREAM MODE (i.e. `ipsw download ipsw --device iPhone15,2 --latest -o - | aws s3 cp - s3://bucket/key`)
+		if output == "-" {
+			if len(ipsws) != 1 {
+				return fmt.Errorf("--output - can only stream a single IPSW (found %d; filter with --device and --version/--build/--latest)", len(ipsws))
+			}
+			i, err := resolve(ipsws[0])
+			if err != nil {
+				return err
+			}
+			log.WithFields(log.Fields{
+				"device":  i.Identifier,
+				"build":   i.BuildID,
+				"version": i.Version,
+				"signed":  i.Signed,
+			}).Info("Streaming IPSW to stdout")
+			downloader := download.NewDownload(proxy, insecure, false, false, false, false, viper.GetBool("verbose"))
+			downloader.URL = i.URL
+			downloader.Sha1 = i.SHA1
+			downloader.MD5 = i.MD5
+			if err := downloader.Stream(context.Background(), os.Stdout); err != nil {
+				return fmt.Errorf("failed to stream file: %w", err)
+			}
+			return nil
+		}
+
 		cont := true
 		if !confirm {
 			// if filtered to a single device skip the prompt
@@ -320,7 +444,9 @@ var ipswCmd = &cobra.Command{
 				prompt := &survey.Confirm{
 					Message: fmt.Sprintf("You are about to download %d IPSW files. Continue?", len(ipsws)),
 				}
-				survey.AskOne(prompt, &cont)
+				if err := utils.AskOne(prompt, &cont); err != nil {
+					return err
+				}
 			}
 		}
 
@@ -371,7 +497,105 @@ var ipswCmd = &cobra.Command{
 					}
 				}
 			} else { // NORMAL MODE
-				for _, i := range ipsws {
+				var manifest []*download.ManifestEntry
+				// addManifest adds a downloaded IPSW to the --manifest output (v skips re-hashing an already verified sha1)
+				addManifest := func(i download.IPSW, destName string, v *download.Verification) {
+					e, err := download.NewManifestEntry(destName, i.Identifier, i.BuildID, i.URL, v)
+					if err != nil {
+						log.Errorf("failed to add %s to manifest: %v", destName, err)
+						return
+					}
+					manifest = append(manifest, e)
+				}
+				if manifestPath := viper.GetString("download.ipsw.manifest"); len(manifestPath) > 0 {
+					defer func() {
+						if len(manifest) == 0 {
+							return
+						}
+						if err := download.WriteManifest(manifestPath, manifest); err != nil {
+							log.Error(err.Error())
+						} else {
+							log.Info("Created: " + manifestPath)
+						}
+					}()
+				}
+
+				// created runs after an IPSW is downloaded
+				created := func(i download.IPSW, destName string, v *download.Verification) error {
+					log.Info("Created: " + destName)
+
+					if err := download.StoreCAS(destName, i.Identifier, i.BuildID); err != nil {
+						log.Errorf("failed to store %s in CAS: %v", destName, err)
+					}
+
+					if saveBlobs {
+						if !i.Signed {
+							log.Warnf("Skipping SHSH blobs for %s (%s): no longer signed", i.Version, i.BuildID)
+						} else if inf, err := info.Parse(destName); err != nil {
+							log.Errorf("failed to parse %s: %v", destName, err)
+						} else if fname, err := tss.SaveSHSHBlob(inf.Plists.BuildManifest, &tss.BlobConfig{
+							Device:    i.Identifier,
+							ECID:      ecid,
+							Generator: viper.GetString("download.ipsw.generator"),
+							Proxy:     proxy,
+							Insecure:  insecure,
+						}, filepath.Dir(destName)); err != nil {
+							log.Errorf("failed to save SHSH blobs for %s (%s): %v", i.Version, i.BuildID, err)
+						} else {
+							log.Info("Created: " + fname)
+						}
+					}
+
+					if len(viper.GetString("download.ipsw.manifest")) > 0 {
+						addManifest(i, destName, v)
+					}
+
+					// append sha1 and filename to checksums file
+					f, err := os.OpenFile("checksums.txt.sha1", os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+					if err != nil {
+						return fmt.Errorf("failed to open checksums.txt.sha1: %v", err)
+					}
+					defer f.Close()
+
+					if _, err = f.WriteString(i.SHA1 + "  " + destName + "\n"); err != nil {
+						return fmt.Errorf("failed to write to checksums.txt.sha1: %v", err)
+					}
+					return nil
+				}
+
+				var results []ipswResult
+				// report adds a finished download to the --json output
+				report := func(i download.IPSW, destName string, dl *download.Download) {
+					results = append(results, ipswResult{IPSW: i, Path: destName, Verification: verification(dl), Error: dl.Status().Error})
+				}
+				if viper.GetBool("download.ipsw.json") {
+					defer func() {
+						if len(results) == 0 {
+							return
+						}
+						if dat, err := json.MarshalIndent(results, "", "  "); err == nil {
+							fmt.Println(string(dat))
+						}
+					}()
+				}
+
+				var queue *download.Manager
+				if parallel > 1 {
+					queue = download.NewManager(parallel)
+					queue.SetHostLimit(viper.GetInt("download.ipsw.per-host"))
+					queue.ShowProgress()
+					// concurrent downloads cannot prompt to resume partial downloads
+					resumeAll = resumeAll || (!skipAll && !restartAll)
+				}
+
+				type queued struct {
+					ipsw     download.IPSW
+					destName string
+					dl       *download.Download
+				}
+				var jobs []queued
+
+				for idx, i := range ipsws {
 					destName := getDestName(i.URL, removeCommas)
 					if len(output) > 0 {
 						destName = filepath.Join(filepath.Clean(output), destName)
@@ -379,37 +603,70 @@ var ipswCmd = &cobra.Command{
 					if err := os.MkdirAll(filepath.Dir(destName), 0755); err != nil {
 						return fmt.Errorf("failed to create directory: %v", err)
 					}
-					if _, err := os.Stat(destName); os.IsNotExist(err) {
-						log.WithFields(log.Fields{
-							"device":  i.Identifier,
-							"build":   i.BuildID,
-							"version": i.Version,
-							"signed":  i.Signed,
-						}).Info("Getting IPSW")
-
-						downloader := download.NewDownload(proxy, insecure, skipAll, resumeAll, restartAll, false, viper.GetBool("verbose"))
-						downloader.URL = i.URL
-						downloader.Sha1 = i.SHA1
-						downloader.DestName = destName
-
-						if err := downloader.Do(); err != nil {
-							return fmt.Errorf("failed to download file: %v", err)
+					downloader := download.NewDownload(proxy, insecure, skipAll, resumeAll, restartAll, false, viper.GetBool("verbose"))
+					downloader.Threads = viper.GetInt("download.ipsw.threads")
+					downloader.URL = i.URL
+					downloader.Sha1 = i.SHA1
+					downloader.MD5 = i.MD5
+					downloader.Size = int64(i.FileSize)
+					downloader.DestName = destName
+
+					if skip, err := downloader.SkipExisting(); err != nil {
+						return exitcode.WrapPartial(idx, err)
+					} else if skip {
+						if len(viper.GetString("download.ipsw.manifest")) > 0 {
+							addManifest(i, destName, nil)
 						}
+						continue
+					}
 
-						log.Info("Created: " + destName)
+					log.WithFields(log.Fields{
+						"device":  i.Identifier,
+						"build":   i.BuildID,
+						"version": i.Version,
+						"signed":  i.Signed,
+					}).Info("Getting IPSW")
+
+					if i, err = resolve(i); err != nil {
+						return exitcode.WrapPartial(idx, err)
+					}
+					downloader.URL = i.URL
 
-						// append sha1 and filename to checksums file
-						f, err := os.OpenFile("checksums.txt.sha1", os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
-						if err != nil {
-							return fmt.Errorf("failed to open checksums.txt.sha1: %v", err)
-						}
-						defer f.Close()
+					if queue != nil {
+						queue.Add(downloader)
+						jobs = append(jobs, queued{ipsw: i, destName: destName, dl: downloader})
+						continue
+					}
+
+					err := downloader.Do()
+					report(i, destName, downloader)
+					if err != nil {
+						return exitcode.WrapPartial(idx, fmt.Errorf("failed to download file: %w", err))
+					}
+					if err := created(i, destName, verification(downloader)); err != nil {
+						return err
+					}
+				}
 
-						if _, err = f.WriteString(i.SHA1 + "  " + destName + "\n"); err != nil {
-							return fmt.Errorf("failed to write to checksums.txt.sha1: %v", err)
+				if queue != nil {
+					queue.Wait()
+					queue.Close()
+					// report in queue order
+					var done int
+					var failed []string
+					for _, job := range jobs {
+						report(job.ipsw, job.destName, job.dl)
+						if status := job.dl.Status(); status.State != download.StateDone {
+							failed = append(failed, fmt.Sprintf("%s: %s", filepath.Base(job.destName), status.Error))
+							continue
 						}
-					} else {
-						log.Warnf("IPSW already exists: %s", destName)
+						done++
+						if err := created(job.ipsw, job.destName, verification(job.dl)); err != nil {
+							return err
+						}
+					}
+					if len(failed) > 0 {
+						return exitcode.WrapPartial(done, fmt.Errorf("failed to download %d of %d files:\n\t%s", len(failed), len(jobs), strings.Join(failed, "\n\t")))
 					}
 				}
 			}
@@ -40,6 +40,7 @@ func init() {
 	DownloadCmd.AddCommand(downloadKdkCmd)
 	downloadKdkCmd.Flags().Bool("host", false, "Download KDK for current host OS")
 	downloadKdkCmd.Flags().BoolP("install", "i", false, "Install KDK after download")
+	downloadKdkCmd.Flags().BoolP("list", "l", false, "List available KDKs")
 	downloadKdkCmd.Flags().StringP("output", "o", "", "Folder to download files to")
 	downloadKdkCmd.MarkFlagDirname("output")
 	downloadKdkCmd.SetHelpFunc(func(c *cobra.Command, s []string) {
@@ -48,23 +49,58 @@ func init() {
 		DownloadCmd.PersistentFlags().MarkHidden("device")
 		DownloadCmd.PersistentFlags().MarkHidden("model")
 		DownloadCmd.PersistentFlags().MarkHidden("version")
-		DownloadCmd.PersistentFlags().MarkHidden("build")
 		DownloadCmd.PersistentFlags().MarkHidden("confirm")
 		DownloadCmd.PersistentFlags().MarkHidden("remove-commas")
 		c.Parent().HelpFunc()(c, s)
 	})
 	viper.BindPFlag("download.kdk.host", downloadKdkCmd.Flags().Lookup("host"))
 	viper.BindPFlag("download.kdk.install", downloadKdkCmd.Flags().Lookup("install"))
+	viper.BindPFlag("download.kdk.list", downloadKdkCmd.Flags().Lookup("list"))
 	viper.BindPFlag("download.kdk.output", downloadKdkCmd.Flags().Lookup("output"))
 }
 
+// devPortalKDKs logs in to the dev portal without prompting and lists its KDKs (nil if no Apple ID
+// credentials are set with IPSW_DOWNLOAD_DEV_USERNAME and IPSW_DOWNLOAD_DEV_PASSWORD, see `ipsw download dev`)
+func devPortalKDKs(config *download.DevConfig) (*download.DevPortal, download.KDKs, error) {
+	username := viper.GetString("download.dev.username")
+	password := viper.GetString("download.dev.password")
+	if len(username) == 0 || len(password) == 0 {
+		return nil, nil, nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get user home directory: %v", err)
+	}
+	config.ConfigDir = filepath.Join(home, ".ipsw")
+	config.VaultPassword = viper.GetString("download.dev.vault-password")
+	config.KeyringBackend = viper.GetString("download.dev.keyring-backend")
+	config.TrustToken = viper.GetString("download.dev.trust-token")
+	config.Headless = true
+
+	app := download.NewDevPortal(config)
+	if err := app.Init(); err != nil {
+		return nil, nil, fmt.Errorf("failed to initialize dev portal: %v", err)
+	}
+	if err := app.Login(username, password); err != nil {
+		return nil, nil, fmt.Errorf("failed to login to dev portal: %v", err)
+	}
+	kdks, err := app.ListKDKs()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list dev portal KDKs: %v", err)
+	}
+	return app, kdks, nil
+}
+
 // downloadKdkCmd represents the kdk command
 var downloadKdkCmd = &cobra.Command{
-	Use:           "kdk",
-	Short:         "Download KDKs",
+	Use:   "kdk",
+	Short: "Download KDKs",
+	Long: `Download KDKs from the Apple developer portal when Apple ID credentials are set
+(IPSW_DOWNLOAD_DEV_USERNAME and IPSW_DOWNLOAD_DEV_PASSWORD, plus IPSW_DOWNLOAD_DEV_TRUST_TOKEN
+or a saved 2FA session), otherwise from the KdkSupportPkg manifest.`,
 	SilenceUsage:  true,
 	SilenceErrors: true,
-	Hidden:        true,
 	RunE: func(cmd *cobra.Command, args []string) error {
 
 		if viper.GetBool("verbose") {
@@ -76,6 +112,7 @@ var downloadKdkCmd = &cobra.Command{
 		viper.BindPFlag("download.skip-all", cmd.Flags().Lookup("skip-all"))
 		viper.BindPFlag("download.resume-all", cmd.Flags().Lookup("resume-all"))
 		viper.BindPFlag("download.restart-all", cmd.Flags().Lookup("restart-all"))
+		viper.BindPFlag("download.build", cmd.Flags().Lookup("build"))
 
 		// settings
 		proxy := viper.GetString("download.proxy")
@@ -83,14 +120,40 @@ var downloadKdkCmd = &cobra.Command{
 		skipAll := viper.GetBool("download.skip-all")
 		resumeAll := viper.GetBool("download.resume-all")
 		restartAll := viper.GetBool("download.restart-all")
+		// filters
+		build := viper.GetString("download.build")
 		// flags
 		forHost := viper.GetBool("download.kdk.host")
 		install := viper.GetBool("download.kdk.install")
 		output := viper.GetString("download.kdk.output")
 
-		kdks, err := download.ListKDKs()
+		if forHost && len(build) > 0 {
+			return fmt.Errorf("you cannot supply a --host AND a --build (they are mutually exclusive)")
+		}
+
+		app, kdks, err := devPortalKDKs(&download.DevConfig{
+			Proxy:      proxy,
+			Insecure:   insecure,
+			SkipAll:    skipAll,
+			ResumeAll:  resumeAll,
+			RestartAll: restartAll,
+			Verbose:    viper.GetBool("verbose"),
+		})
 		if err != nil {
-			return err
+			log.WithError(err).Warn("Falling back to the KDK manifest")
+		}
+		if app == nil {
+			kdks, err = download.ListKDKs()
+			if err != nil {
+				return err
+			}
+		}
+
+		if viper.GetBool("download.kdk.list") {
+			for _, kdk := range kdks {
+				fmt.Printf("%-8s %-10s %s (%s)\n", kdk.Version, kdk.Build, kdk.Name, utils.FormatDate(kdk.Date.Time()))
+			}
+			return nil
 		}
 
 		var aKDK download.KDK
@@ -100,17 +163,17 @@ var downloadKdkCmd = &cobra.Command{
 			if err != nil {
 				return fmt.Errorf("failed to get build info: %v", err)
 			}
-			found := false
-			for _, kdk := range kdks {
-				if kdk.Version == binfo.ProductVersion && kdk.Build == binfo.BuildVersion {
-					aKDK = kdk
-					found = true
-					break
-				}
-			}
-			if !found {
+			kdk, err := kdks.GetByBuild(binfo.BuildVersion)
+			if err != nil {
 				return fmt.Errorf("failed to find KDK for %s (%s)", binfo.ProductVersion, binfo.BuildVersion)
 			}
+			aKDK = *kdk
+		} else if len(build) > 0 {
+			kdk, err := kdks.GetByBuild(build)
+			if err != nil {
+				return err
+			}
+			aKDK = *kdk
 		} else {
 			var choices []string
 			for _, kdk := range kdks {
@@ -123,9 +186,12 @@ var downloadKdkCmd = &cobra.Command{
 				Options:  choices,
 				PageSize: 10,
 			}
-			if err := survey.AskOne(prompt, &choice); err == terminal.InterruptErr {
-				log.Warn("Exiting...")
-				return nil
+			if err := utils.AskOne(prompt, &choice); err != nil {
+				if err == terminal.InterruptErr {
+					log.Warn("Exiting...")
+					return nil
+				}
+				return err
 			}
 
 			for _, kdk := range kdks {
@@ -144,16 +210,31 @@ var downloadKdkCmd = &cobra.Command{
 			return fmt.Errorf("failed to create directory: %v", err)
 		}
 
-		if _, err := os.Stat(destName); os.IsNotExist(err) {
+		if app != nil { // the dev portal downloads need its session
 			log.Infof("Downloading to %s...", destName)
+			if err := app.Download(aKDK.URL, filepath.Dir(destName)); err != nil {
+				return err
+			}
+			if err := download.StoreCAS(destName, "", aKDK.Build); err != nil {
+				log.Errorf("failed to store %s in CAS: %v", destName, err)
+			}
+		} else {
 			downloader := download.NewDownload(proxy, insecure, skipAll, resumeAll, restartAll, false, viper.GetBool("verbose"))
 			downloader.URL = aKDK.URL
+			downloader.MD5 = aKDK.Md5Sum
+			downloader.SHA256 = aKDK.Sha256Sum
 			downloader.DestName = destName
-			if err := downloader.Do(); err != nil {
+			if skip, err := downloader.SkipExisting(); err != nil {
 				return err
+			} else if !skip {
+				log.Infof("Downloading to %s...", destName)
+				if err := downloader.Do(); err != nil {
+					return err
+				}
+				if err := download.StoreCAS(destName, "", aKDK.Build); err != nil {
+					log.Errorf("failed to store %s in CAS: %v", destName, err)
+				}
 			}
-		} else {
-			log.Warnf("File already exists: %s", destName)
 		}
 
 		if install {
@@ -29,6 +29,8 @@ import (
 	"github.com/AlecAivazis/survey/v2/terminal"
 	"github.com/apex/log"
 	"github.com/blacktop/ipsw/internal/download"
+	"github.com/blacktop/ipsw/internal/exitcode"
+	"github.com/blacktop/ipsw/internal/utils"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -127,11 +129,11 @@ var macosCmd = &cobra.Command{
 
 		var prodList []string
 		for _, p := range prods {
-			prodList = append(prodList, fmt.Sprintf("%-35s%-8s %-8s %s", p.Title, p.Version, p.Build, p.PostDate.Format("02Jan2006 15:04:05")))
+			prodList = append(prodList, fmt.Sprintf("%-35s%-8s %-8s %s", p.Title, p.Version, p.Build, utils.FormatTime(p.PostDate)))
 		}
 
 		if len(prodList) == 0 {
-			return fmt.Errorf("no installers found for given options")
+			return exitcode.Errorf(exitcode.NotFound, "no installers found for given options")
 		}
 
 		if len(prodList) > 1 && len(build) == 0 && !latest {
@@ -159,7 +161,7 @@ var macosCmd = &cobra.Command{
 				Options:  prodList,
 				PageSize: 25,
 			}
-			if err := survey.AskOne(prompt, &choices); err != nil {
+			if err := utils.AskOne(prompt, &choices); err != nil {
 				if err == terminal.InterruptErr {
 					log.Warn("Exiting...")
 					os.Exit(0)
@@ -182,7 +184,7 @@ var macosCmd = &cobra.Command{
 			prompt := &survey.Confirm{
 				Message: msg,
 			}
-			if err := survey.AskOne(prompt, &cont); err != nil {
+			if err := utils.AskOne(prompt, &cont); err != nil {
 				if err == terminal.InterruptErr {
 					log.Warn("Exiting...")
 					os.Exit(0)
@@ -0,0 +1,219 @@
+/*
+Copyright © 2023 blacktop
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package download
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/AlecAivazis/survey/v2/terminal"
+	"github.com/apex/log"
+	"github.com/blacktop/ipsw/internal/download"
+	"github.com/blacktop/ipsw/internal/exitcode"
+	"github.com/blacktop/ipsw/internal/utils"
+	"github.com/blacktop/ipsw/pkg/info"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func init() {
+	DownloadCmd.AddCommand(ossCmd)
+
+	ossCmd.Flags().StringP("release", "r", "", "Release to download sources for (i.e. 'macOS 14.0' or '17.0')")
+	ossCmd.Flags().String("ipsw", "", "Download sources for the release of a local IPSW")
+	ossCmd.Flags().StringSliceP("product", "p", []string{}, "Project(s) to download (supports globs, i.e. 'xnu', 'dyld*')")
+	ossCmd.Flags().BoolP("list", "l", false, "List releases (or projects in --release)")
+	ossCmd.Flags().Bool("json", false, "Output as JSON")
+	ossCmd.Flags().StringP("output", "o", "", "Folder to download files to")
+	ossCmd.MarkFlagDirname("output")
+	ossCmd.SetHelpFunc(func(c *cobra.Command, s []string) {
+		DownloadCmd.PersistentFlags().MarkHidden("white-list")
+		DownloadCmd.PersistentFlags().MarkHidden("black-list")
+		DownloadCmd.PersistentFlags().MarkHidden("device")
+		DownloadCmd.PersistentFlags().MarkHidden("model")
+		DownloadCmd.PersistentFlags().MarkHidden("version")
+		DownloadCmd.PersistentFlags().MarkHidden("skip-all")
+		DownloadCmd.PersistentFlags().MarkHidden("resume-all")
+		DownloadCmd.PersistentFlags().MarkHidden("restart-all")
+		DownloadCmd.PersistentFlags().MarkHidden("remove-commas")
+		c.Parent().HelpFunc()(c, s)
+	})
+	viper.BindPFlag("download.oss.release", ossCmd.Flags().Lookup("release"))
+	viper.BindPFlag("download.oss.ipsw", ossCmd.Flags().Lookup("ipsw"))
+	viper.BindPFlag("download.oss.product", ossCmd.Flags().Lookup("product"))
+	viper.BindPFlag("download.oss.list", ossCmd.Flags().Lookup("list"))
+	viper.BindPFlag("download.oss.json", ossCmd.Flags().Lookup("json"))
+	viper.BindPFlag("download.oss.output", ossCmd.Flags().Lookup("output"))
+}
+
+// ossCmd represents the oss command
+var ossCmd = &cobra.Command{
+	Use:     "oss",
+	Aliases: []string{"opensource"},
+	Short:   "Download opensource.apple.com release tarballs",
+	Example: `  # List the projects in the release of a build
+  ❯ ipsw download oss --build 23A344 --list
+
+  # Download the xnu and Security sources of a local IPSW's release
+  ❯ ipsw download oss --ipsw iPhone15,2_17.0_21A329_Restore.ipsw -p xnu -p Security`,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+
+		if viper.GetBool("verbose") {
+			log.SetLevel(log.DebugLevel)
+		}
+
+		viper.BindPFlag("download.proxy", cmd.Flags().Lookup("proxy"))
+		viper.BindPFlag("download.insecure", cmd.Flags().Lookup("insecure"))
+		viper.BindPFlag("download.confirm", cmd.Flags().Lookup("confirm"))
+		viper.BindPFlag("download.build", cmd.Flags().Lookup("build"))
+
+		// settings
+		proxy := viper.GetString("download.proxy")
+		insecure := viper.GetBool("download.insecure")
+		confirm := viper.GetBool("download.confirm")
+		// flags
+		release := viper.GetString("download.oss.release")
+		build := viper.GetString("download.build")
+		ipswPath := viper.GetString("download.oss.ipsw")
+		products := viper.GetStringSlice("download.oss.product")
+		asJSON := viper.GetBool("download.oss.json")
+		output := viper.GetString("download.oss.output")
+
+		var given int
+		for _, flag := range []string{release, build, ipswPath} {
+			if len(flag) > 0 {
+				given++
+			}
+		}
+		if given > 1 {
+			return fmt.Errorf("--release, --build and --ipsw are mutually exclusive")
+		}
+
+		releases, err := download.GetOSSReleases(proxy, insecure)
+		if err != nil {
+			return fmt.Errorf("failed to get opensource.apple.com releases: %v", err)
+		}
+
+		var rel *download.OSSRelease
+		switch {
+		case len(ipswPath) > 0:
+			inf, err := info.Parse(ipswPath)
+			if err != nil {
+				return fmt.Errorf("failed to parse IPSW: %v", err)
+			}
+			var class string
+			if devices := inf.Plists.BuildManifest.SupportedProductTypes; len(devices) > 0 {
+				class = download.DeviceClassForIdentifier(devices[0])
+			}
+			rel, err = releases.ForVersion(class, inf.Plists.BuildManifest.ProductVersion)
+			if err != nil {
+				return err
+			}
+			log.WithField("build", inf.Plists.BuildManifest.ProductBuildVersion).Infof("Found release %s", rel.Name)
+		case len(build) > 0:
+			rel, err = releases.ForBuild(build)
+			if err != nil {
+				return err
+			}
+			log.WithField("build", build).Infof("Found release %s", rel.Name)
+		case len(release) == 0:
+			if viper.GetBool("download.oss.list") {
+				if asJSON {
+					return json.NewEncoder(os.Stdout).Encode(releases)
+				}
+				for _, r := range releases {
+					fmt.Printf("%s (%d projects)\n", r.Name, len(r.Projects))
+				}
+				return nil
+			}
+			var choices []string
+			for _, r := range releases {
+				choices = append(choices, r.Name)
+			}
+			prompt := &survey.Select{
+				Message:  "Select a release:",
+				Options:  choices,
+				PageSize: 15,
+			}
+			if err := utils.AskOne(prompt, &release); err != nil {
+				if err == terminal.InterruptErr {
+					log.Warn("Exiting...")
+					return nil
+				}
+				return err
+			}
+			fallthrough
+		default:
+			rel, err = releases.Get(release)
+			if err != nil {
+				return err
+			}
+		}
+
+		projects, err := rel.Filter(products)
+		if err != nil {
+			return err
+		}
+		if len(projects) == 0 {
+			return exitcode.Errorf(exitcode.NotFound, "no projects in %s match %v", rel.Name, products)
+		}
+
+		if viper.GetBool("download.oss.list") {
+			if asJSON {
+				return json.NewEncoder(os.Stdout).Encode(projects)
+			}
+			for _, p := range projects {
+				fmt.Printf("%-30s %s\n", p.Name, p.Tag)
+			}
+			return nil
+		}
+
+		cont := true
+		if !confirm && len(products) == 0 {
+			cont = false
+			prompt := &survey.Confirm{
+				Message: fmt.Sprintf("You are about to download %d %s project tarballs. Continue?", len(projects), rel.Name),
+			}
+			if err := utils.AskOne(prompt, &cont); err != nil {
+				if err == terminal.InterruptErr {
+					log.Warn("Exiting...")
+					return nil
+				}
+				return err
+			}
+		}
+
+		if cont {
+			for _, p := range projects {
+				if err := p.Download(output, proxy, insecure); err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+	},
+}
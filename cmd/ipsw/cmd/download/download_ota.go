@@ -34,12 +34,14 @@ import (
 	"github.com/AlecAivazis/survey/v2"
 	"github.com/apex/log"
 	"github.com/blacktop/ipsw/internal/download"
+	"github.com/blacktop/ipsw/internal/exitcode"
 	"github.com/blacktop/ipsw/internal/utils"
+	"github.com/blacktop/ipsw/pkg/aea"
 	"github.com/blacktop/ipsw/pkg/dyld"
 	"github.com/blacktop/ipsw/pkg/info"
 	"github.com/blacktop/ipsw/pkg/kernelcache"
 	"github.com/blacktop/ipsw/pkg/ota"
-	"github.com/dustin/go-humanize"
+	"github.com/blacktop/ipsw/pkg/ota/types"
 	semver "github.com/hashicorp/go-version"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
@@ -75,6 +77,11 @@ func init() {
 	otaDLCmd.Flags().StringP("output", "o", "", "Folder to download files to")
 	otaDLCmd.Flags().Bool("show-latest-version", false, "Show latest iOS version")
 	otaDLCmd.Flags().Bool("show-latest-build", false, "Show latest iOS build")
+	otaDLCmd.Flags().String("prereq-version", "", "Download the delta OTA that updates from this version")
+	otaDLCmd.Flags().String("prereq-build", "", "Download the delta OTA that updates from this build")
+	otaDLCmd.Flags().String("seed", "", "Beta seed program (developer, public, appleseed), enrollment profile (.mobileconfig) or asset audience")
+	otaDLCmd.Flags().String("paired-ios", "", "Only download watchOS OTAs installable from an iPhone running this iOS version")
+	otaDLCmd.Flags().IntP("threads", "t", 1, "Number of concurrent connections per (large) OTA download")
 	viper.BindPFlag("download.ota.platform", otaDLCmd.Flags().Lookup("platform"))
 	viper.BindPFlag("download.ota.beta", otaDLCmd.Flags().Lookup("beta"))
 	viper.BindPFlag("download.ota.rsr", otaDLCmd.Flags().Lookup("rsr"))
@@ -90,12 +97,20 @@ func init() {
 	viper.BindPFlag("download.ota.output", otaDLCmd.Flags().Lookup("output"))
 	viper.BindPFlag("download.ota.show-latest-version", otaDLCmd.Flags().Lookup("show-latest-version"))
 	viper.BindPFlag("download.ota.show-latest-build", otaDLCmd.Flags().Lookup("show-latest-build"))
+	viper.BindPFlag("download.ota.prereq-version", otaDLCmd.Flags().Lookup("prereq-version"))
+	viper.BindPFlag("download.ota.prereq-build", otaDLCmd.Flags().Lookup("prereq-build"))
+	viper.BindPFlag("download.ota.seed", otaDLCmd.Flags().Lookup("seed"))
+	viper.BindPFlag("download.ota.paired-ios", otaDLCmd.Flags().Lookup("paired-ios"))
+	viper.BindPFlag("download.ota.threads", otaDLCmd.Flags().Lookup("threads"))
 
 	otaDLCmd.MarkFlagDirname("output")
 	otaDLCmd.MarkFlagsMutuallyExclusive("info", "beta")
 	otaDLCmd.RegisterFlagCompletionFunc("platform", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 		return otaDlCmdPlatforms, cobra.ShellCompDirectiveDefault
 	})
+	otaDLCmd.RegisterFlagCompletionFunc("seed", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return download.SeedPrograms, cobra.ShellCompDirectiveDefault
+	})
 }
 
 // otaDLCmd represents the ota download command
@@ -109,7 +124,11 @@ var otaDLCmd = &cobra.Command{
 	  • Getting OTA               build=18H107 device=iPhone10,1 version=iOS1481Short
 	  280.0 MiB / 3.7 GiB [===>------------------------------------------------------| 51m18s
   # Get all the latest BETA iOS OTAs URLs as JSON
-  ❯ ipsw download ota --platform ios --beta --urls --json`,
+  ❯ ipsw download ota --platform ios --beta --urls --json
+  # Get the latest iOS public beta OTAs URLs
+  ❯ ipsw download ota --platform ios --seed public --urls
+  # Download the delta OTA from iOS 17.4 to 17.5 for the iPhone15,2
+  ❯ ipsw download ota --platform ios --version 17.5 --prereq-version 17.4 --device iPhone15,2`,
 	SilenceUsage:  true,
 	SilenceErrors: true,
 	RunE: func(cmd *cobra.Command, args []string) error {
@@ -144,7 +163,7 @@ var otaDLCmd = &cobra.Command{
 		restartAll := viper.GetBool("download.restart-all")
 		removeCommas := viper.GetBool("download.remove-commas")
 		// filters
-		device := viper.GetString("download.device")
+		device := download.ResolveDevice(viper.GetString("download.device"))
 		model := viper.GetString("download.model")
 		version := viper.GetString("download.version")
 		build := viper.GetString("download.build")
@@ -164,6 +183,10 @@ var otaDLCmd = &cobra.Command{
 		output := viper.GetString("download.ota.output")
 		showLatestVersion := viper.GetBool("download.ota.show-latest-version")
 		showLatestBuild := viper.GetBool("download.ota.show-latest-build")
+		prereqVersion := viper.GetString("download.ota.prereq-version")
+		prereqBuild := viper.GetString("download.ota.prereq-build")
+		seed := viper.GetString("download.ota.seed")
+		pairedIOS := viper.GetString("download.ota.paired-ios")
 		// verify args
 		if len(dyldArches) > 0 && !remoteDyld {
 			return errors.New("--dyld-arch || -a can only be used with --dyld || -d")
@@ -177,11 +200,16 @@ var otaDLCmd = &cobra.Command{
 		}
 		if len(platform) == 0 {
 			return fmt.Errorf("you must supply a valid --platform flag. Choices are: ios, watchos, tvos, audioos, visionos || accessory, macos, recovery")
+		} else if platform == "bridgeos" {
+			return fmt.Errorf("bridgeOS is not served as OTAs (it ships inside macOS updates); use `ipsw download bridgeos` or `ipsw download ipsw --ibridge` instead")
 		} else {
 			if !utils.StrSliceHas([]string{"ios", "macos", "recovery", "watchos", "tvos", "audioos", "accessory", "visionos"}, platform) {
 				return fmt.Errorf("valid --platform flag choices are: ios, watchos, tvos, audioos, visionos || accessory, macos, recovery")
 			}
 		}
+		if len(pairedIOS) > 0 && platform != "watchos" {
+			return fmt.Errorf("--paired-ios can only be used with --platform watchos")
+		}
 		if (showLatestVersion || showLatestBuild) && len(device) == 0 {
 			return fmt.Errorf("you must supply a --device when using --show-latest-version or --show-latest-build")
 		}
@@ -194,6 +222,21 @@ var otaDLCmd = &cobra.Command{
 		if len(build) == 0 {
 			build = "0"
 		}
+		var audience string
+		if len(seed) > 0 {
+			audience, err = download.ResolveAssetAudience(platform, version, seed)
+			if err != nil {
+				return fmt.Errorf("failed to resolve asset audience: %v", err)
+			}
+			log.WithField("audience", audience).Debug("Using seed asset audience")
+			getBeta = true
+		}
+		if len(prereqVersion) > 0 && len(prereqBuild) == 0 && len(device) > 0 {
+			prereqBuild, err = download.GetBuildID(prereqVersion, device)
+			if err != nil {
+				log.Warnf("failed to get build for prerequisite version %s: %v", prereqVersion, err)
+			}
+		}
 
 		// Query for asset sets
 		as, err := download.GetAssetSets(proxy, insecure)
@@ -254,18 +297,21 @@ var otaDLCmd = &cobra.Command{
 		}
 
 		otaXML, err := download.NewOTA(as, download.OtaConf{
-			Platform:        strings.ToLower(platform),
-			Beta:            getBeta,
-			RSR:             getRSR,
-			Device:          device,
-			Model:           model,
-			Version:         ver,
-			Build:           build,
-			DeviceWhiteList: doDownload,
-			DeviceBlackList: doNotDownload,
-			Proxy:           proxy,
-			Insecure:        insecure,
-			Timeout:         90,
+			Platform:            strings.ToLower(platform),
+			Beta:                getBeta,
+			RSR:                 getRSR,
+			Device:              device,
+			Model:               model,
+			Version:             ver,
+			Build:               build,
+			PrerequisiteVersion: prereqVersion,
+			PrerequisiteBuild:   prereqBuild,
+			AssetAudience:       audience,
+			DeviceWhiteList:     doDownload,
+			DeviceBlackList:     doNotDownload,
+			Proxy:               proxy,
+			Insecure:            insecure,
+			Timeout:             90,
 		})
 		if err != nil {
 			return fmt.Errorf("failed to parse remote OTA XML: %v", err)
@@ -276,18 +322,25 @@ var otaDLCmd = &cobra.Command{
 			return err
 		}
 
+		if platform == "watchos" {
+			otas, err = checkWatchCompatibility(otas, pairedIOS)
+			if err != nil {
+				return err
+			}
+		}
+
 		if showLatestVersion {
 			if len(otas) > 0 {
 				fmt.Println(strings.TrimPrefix(otas[0].OSVersion, "9.9."))
 				return nil
 			}
-			return fmt.Errorf("no OTA found")
+			return exitcode.Errorf(exitcode.NotFound, "no OTA found")
 		} else if showLatestBuild {
 			if len(otas) > 0 {
 				fmt.Println(otas[0].Build)
 				return nil
 			}
-			return fmt.Errorf("no OTA found")
+			return exitcode.Errorf(exitcode.NotFound, "no OTA found")
 		}
 
 		if viper.GetBool("download.ota.urls") || viper.GetBool("download.ota.json") {
@@ -308,14 +361,21 @@ var otaDLCmd = &cobra.Command{
 		if viper.GetBool("verbose") {
 			log.Info("OTA(s):")
 			for _, o := range otas {
-				utils.Indent(log.WithFields(log.Fields{
+				fields := log.Fields{
 					"name":         o.DocumentationID,
 					"version":      o.OSVersion,
 					"build":        o.Build,
+					"prereq":       o.PrerequisiteBuild,
 					"device_count": len(o.SupportedDevices),
 					"model_count":  len(o.SupportedDeviceModels),
-					"size":         humanize.Bytes(uint64(o.UnarchivedSize)),
-				}).Info, 2)(filepath.Base(o.RelativePath))
+					"size":         utils.FormatBytes(uint64(o.UnarchivedSize)),
+				}
+				if platform == "watchos" {
+					if compat, err := download.GetWatchCompatibility(o.OSVersion); err == nil {
+						fields["requires"] = fmt.Sprintf("%s+ on iOS %s+", compat.MinIPhone, compat.MinIOS)
+					}
+				}
+				utils.Indent(log.WithFields(fields).Info, 2)(filepath.Base(o.RelativePath))
 			}
 		}
 
@@ -332,7 +392,9 @@ var otaDLCmd = &cobra.Command{
 				prompt := &survey.Confirm{
 					Message: fmt.Sprintf("You are about to download %d OTA files. Continue?", len(otas)),
 				}
-				survey.AskOne(prompt, &cont)
+				if err := utils.AskOne(prompt, &cont); err != nil {
+					return err
+				}
 			}
 		}
 
@@ -459,7 +521,8 @@ var otaDLCmd = &cobra.Command{
 				}
 			} else {
 				downloader := download.NewDownload(proxy, insecure, skipAll, resumeAll, restartAll, false, viper.GetBool("verbose"))
-				for _, o := range otas {
+				downloader.Threads = viper.GetInt("download.ota.threads")
+				for idx, o := range otas {
 					folder := filepath.Join(destPath, fmt.Sprintf("%s%s_OTAs", o.ProductSystemName, strings.TrimPrefix(o.OSVersion, "9.9.")))
 					os.MkdirAll(folder, 0750)
 					var devices string
@@ -484,7 +547,11 @@ var otaDLCmd = &cobra.Command{
 						isRSR = fmt.Sprintf("%s_%s_%s_RSR_", o.OSVersion, o.ProductVersionExtra, o.Build)
 					}
 					destName := filepath.Join(folder, fmt.Sprintf("%s%s_%s", isRSR, devices, getDestName(url, removeCommas)))
-					if _, err := os.Stat(destName); os.IsNotExist(err) {
+					downloader.URL = url
+					downloader.DestName = destName
+					if skip, err := downloader.SkipExisting(); err != nil {
+						return exitcode.WrapPartial(idx, err)
+					} else if !skip {
 						log.WithFields(log.Fields{
 							"device": strings.Join(o.SupportedDevices, " "),
 							"model":  strings.Join(o.SupportedDeviceModels, " "),
@@ -492,15 +559,23 @@ var otaDLCmd = &cobra.Command{
 							"type":   o.DocumentationID,
 						}).Info(fmt.Sprintf("Getting %s %s OTA", o.ProductSystemName, strings.TrimPrefix(o.OSVersion, "9.9.")))
 						// download file
-						downloader.URL = url
-						downloader.DestName = destName
 						if err := downloader.Do(); err != nil {
-							return fmt.Errorf("failed to download file: %v", err)
+							return exitcode.WrapPartial(idx, fmt.Errorf("failed to download file: %w", err))
+						}
+						if err := download.StoreCAS(destName, devices, o.Build); err != nil {
+							log.Errorf("failed to store %s in CAS: %v", destName, err)
+						}
+						if len(o.ArchiveDecryptionKey) > 0 && strings.HasSuffix(destName, ".aea") {
+							log.Info("Decrypting AEA OTA")
+							fname, err := aea.Decrypt(&aea.DecryptConfig{
+								Input:     destName,
+								B64SymKey: o.ArchiveDecryptionKey,
+							})
+							if err != nil {
+								return fmt.Errorf("failed to decrypt OTA: %v", err)
+							}
+							log.Infof("Created %s", fname)
 						}
-					} else if err != nil {
-						return fmt.Errorf("failed to stat file %s: %v", destName, err)
-					} else {
-						log.Warnf("OTA already exists: %s", destName)
 					}
 				}
 			}
@@ -509,3 +584,36 @@ var otaDLCmd = &cobra.Command{
 		return nil
 	},
 }
+
+// checkWatchCompatibility warns about the pairing constraints of watchOS OTAs
+// and drops the ones that can't be installed from an iPhone running pairedIOS
+func checkWatchCompatibility(otas []types.Asset, pairedIOS string) ([]types.Asset, error) {
+	var filtered []types.Asset
+	seen := make(map[int]bool)
+	for _, o := range otas {
+		compat, err := download.GetWatchCompatibility(o.OSVersion)
+		if err != nil {
+			log.Debugf("failed to get watchOS pairing constraints: %v", err)
+			filtered = append(filtered, o)
+			continue
+		}
+		if len(pairedIOS) > 0 {
+			if err := compat.CheckPairedIOS(pairedIOS); err != nil {
+				log.WithField("build", o.Build).Warnf("Skipping %s: %v", filepath.Base(o.RelativePath), err)
+				continue
+			}
+		}
+		if !seen[compat.WatchOS] {
+			log.Warn(compat.String())
+			seen[compat.WatchOS] = true
+		}
+		filtered = append(filtered, o)
+	}
+	if len(seen) > 0 {
+		utils.Indent(log.Warn, 2)(download.WatchRestoreNote)
+	}
+	if len(filtered) == 0 && len(otas) > 0 {
+		return nil, fmt.Errorf("no watchOS OTAs can be installed from an iPhone running iOS %s", pairedIOS)
+	}
+	return filtered, nil
+}
@@ -24,9 +24,14 @@ THE SOFTWARE.
 package download
 
 import (
+	"encoding/hex"
 	"fmt"
+	"path/filepath"
+	"strings"
 
 	"github.com/apex/log"
+	"github.com/blacktop/ipsw/internal/download"
+	"github.com/blacktop/ipsw/internal/exitcode"
 	"github.com/blacktop/ipsw/internal/utils"
 	"github.com/blacktop/ipsw/pkg/tss"
 	"github.com/spf13/cobra"
@@ -38,9 +43,15 @@ func init() {
 
 	tssCmd.Flags().BoolP("signed", "s", false, "Check if iOS version is still being signed")
 	tssCmd.Flags().BoolP("usb", "u", false, "Download blobs for USB connected device")
+	tssCmd.Flags().StringP("ecid", "e", "", "Device ECID (hex with 0x prefix or decimal)")
+	tssCmd.Flags().StringP("generator", "g", "", "Boot-nonce generator to save blobs for (default: "+tss.DefaultGenerator+")")
+	tssCmd.Flags().String("apnonce", "", "ApNonce to save blobs for (hex)")
 	tssCmd.Flags().StringP("output", "o", "", "Output directory to save blobs to")
 	viper.BindPFlag("download.tss.signed", tssCmd.Flags().Lookup("signed"))
 	viper.BindPFlag("download.tss.usb", tssCmd.Flags().Lookup("usb"))
+	viper.BindPFlag("download.tss.ecid", tssCmd.Flags().Lookup("ecid"))
+	viper.BindPFlag("download.tss.generator", tssCmd.Flags().Lookup("generator"))
+	viper.BindPFlag("download.tss.apnonce", tssCmd.Flags().Lookup("apnonce"))
 	viper.BindPFlag("download.tss.output", tssCmd.Flags().Lookup("output"))
 
 	tssCmd.SetHelpFunc(func(c *cobra.Command, s []string) {
@@ -60,9 +71,13 @@ func init() {
 
 // tssCmd represents the tss command
 var tssCmd = &cobra.Command{
-	Use:           "tss",
-	Aliases:       []string{"t", "tsschecker"},
-	Short:         "🚧 Download SHSH Blobs",
+	Use:     "tss",
+	Aliases: []string{"t", "tsschecker"},
+	Short:   "🚧 Download SHSH Blobs",
+	Example: `  # Save blobs for all signed builds of a device
+  ❯ ipsw download tss --device iPhone14,2 --ecid 0x1234567890ABC --generator 0x1111111111111111
+  # Save blobs for the USB connected device's current build
+  ❯ ipsw download tss --usb --output blobs/`,
 	SilenceUsage:  false,
 	SilenceErrors: true,
 	RunE: func(cmd *cobra.Command, args []string) error {
@@ -78,15 +93,23 @@ var tssCmd = &cobra.Command{
 		viper.BindPFlag("download.proxy", cmd.Flags().Lookup("proxy"))
 		viper.BindPFlag("download.insecure", cmd.Flags().Lookup("insecure"))
 		// settings
-		device := viper.GetString("download.device")
+		device := download.ResolveDevice(viper.GetString("download.device"))
 		build := viper.GetString("download.build")
 		version := viper.GetString("download.version")
 		proxy := viper.GetString("download.proxy")
 		insecure := viper.GetBool("download.insecure")
 		// flags
 		isSigned := viper.GetBool("download.tss.signed")
+		output := viper.GetString("download.tss.output")
 
-		if device == "" {
+		if len(viper.GetString("download.tss.generator")) > 0 && len(viper.GetString("download.tss.apnonce")) > 0 {
+			return fmt.Errorf("cannot use --generator AND --apnonce at the same time")
+		}
+
+		if device == "" && !viper.GetBool("download.tss.usb") {
+			if !isSigned {
+				return fmt.Errorf("you must supply a --device OR use --usb")
+			}
 			device = "iPhone10,3"
 		}
 
@@ -113,14 +136,90 @@ var tssCmd = &cobra.Command{
 		}
 
 		if isSigned {
-			if _, err := tss.GetTSSResponse(conf); err != nil {
-				log.Errorf("🔥 %s is NO LONGER being signed: %v", conf.Version, err)
+			if conf.Build == "" {
+				var err error
+				conf.Build, err = download.GetBuildID(conf.Version, conf.Device)
+				if err != nil {
+					return err
+				}
+			}
+			status, err := tss.GetSigningStatus(conf.Device, conf.Build, conf.Proxy, conf.Insecure)
+			if err != nil {
+				return err
+			}
+			if status.Signed {
+				log.Infof("✅ %s (%s) is still being signed", status.Version, status.Build)
 			} else {
-				log.Infof("✅ %s is still being signed", conf.Version)
+				log.Errorf("🔥 %s (%s) is NO LONGER being signed: %s", status.Version, status.Build, status.Message)
 			}
 			return nil
 		}
 
-		return fmt.Errorf("downloading SHSH blobs has not been implimented yet")
+		bconf := &tss.BlobConfig{
+			Device:    conf.Device,
+			ECID:      conf.ECID,
+			Generator: viper.GetString("download.tss.generator"),
+			ApNonce:   conf.ApNonce,
+			Proxy:     conf.Proxy,
+			Insecure:  conf.Insecure,
+		}
+		if bconf.ECID == 0 {
+			if len(viper.GetString("download.tss.ecid")) == 0 {
+				return fmt.Errorf("you must supply an --ecid OR use --usb")
+			}
+			var err error
+			bconf.ECID, err = tss.ParseECID(viper.GetString("download.tss.ecid"))
+			if err != nil {
+				return err
+			}
+		}
+		if apnonce := viper.GetString("download.tss.apnonce"); len(apnonce) > 0 {
+			var err error
+			bconf.ApNonce, err = hex.DecodeString(strings.TrimPrefix(apnonce, "0x"))
+			if err != nil {
+				return fmt.Errorf("invalid --apnonce: %v", err)
+			}
+		} else if len(bconf.Generator) > 0 {
+			bconf.ApNonce = nil // derive the ApNonce from the generator
+		}
+
+		var builds []string
+		if len(conf.Build) > 0 {
+			builds = append(builds, conf.Build)
+		} else if len(conf.Version) > 0 {
+			build, err := download.GetBuildID(conf.Version, conf.Device)
+			if err != nil {
+				return err
+			}
+			builds = append(builds, build)
+		} else {
+			d, err := download.GetDevice(conf.Device)
+			if err != nil {
+				return fmt.Errorf("failed to get firmwares for %s: %v", conf.Device, err)
+			}
+			for _, fw := range d.Firmwares {
+				if fw.Signed {
+					builds = append(builds, fw.BuildID)
+				}
+			}
+			if len(builds) == 0 {
+				return exitcode.Errorf(exitcode.NotFound, "no signed builds found for %s", conf.Device)
+			}
+		}
+
+		for _, build := range builds {
+			bm, err := tss.GetBuildManifest(conf.Device, build, conf.Proxy, conf.Insecure)
+			if err != nil {
+				return err
+			}
+			fname, err := tss.SaveSHSHBlob(bm, bconf, filepath.Clean(output))
+			if err != nil {
+				log.Errorf("failed to save SHSH blob for %s (%s): %v", bm.ProductVersion, build, err)
+				continue
+			}
+			log.Infof("Created %s", fname)
+		}
+
+		return nil
 	},
 }
@@ -48,6 +48,7 @@ func init() {
 	DownloadCmd.AddCommand(wikiCmd)
 	wikiCmd.Flags().Bool("ipsw", false, "Download IPSWs")
 	wikiCmd.Flags().Bool("ota", false, "Download OTAs")
+	wikiCmd.Flags().Bool("keys", false, "Download firmware keys for --device and --build")
 	wikiCmd.Flags().Bool("kernel", false, "Extract kernelcache from remote IPSW")
 	wikiCmd.Flags().String("pattern", "", "Download remote files that match regex")
 	wikiCmd.Flags().Bool("beta", false, "Download beta IPSWs/OTAs")
@@ -65,6 +66,7 @@ func init() {
 	})
 	viper.BindPFlag("download.wiki.ipsw", wikiCmd.Flags().Lookup("ipsw"))
 	viper.BindPFlag("download.wiki.ota", wikiCmd.Flags().Lookup("ota"))
+	viper.BindPFlag("download.wiki.keys", wikiCmd.Flags().Lookup("keys"))
 	viper.BindPFlag("download.wiki.kernel", wikiCmd.Flags().Lookup("kernel"))
 	viper.BindPFlag("download.wiki.pattern", wikiCmd.Flags().Lookup("pattern"))
 	viper.BindPFlag("download.wiki.beta", wikiCmd.Flags().Lookup("beta"))
@@ -75,7 +77,7 @@ func init() {
 	viper.BindPFlag("download.wiki.db", wikiCmd.Flags().Lookup("db"))
 	viper.BindPFlag("download.wiki.flat", wikiCmd.Flags().Lookup("flat"))
 
-	wikiCmd.MarkFlagsMutuallyExclusive("ipsw", "ota")
+	wikiCmd.MarkFlagsMutuallyExclusive("ipsw", "ota", "keys")
 	wikiCmd.MarkFlagDirname("output")
 }
 
@@ -113,7 +115,7 @@ var wikiCmd = &cobra.Command{
 		restartAll := viper.GetBool("download.restart-all")
 		removeCommas := viper.GetBool("download.remove-commas")
 		// filters
-		device := viper.GetString("download.device")
+		device := download.ResolveDevice(viper.GetString("download.device"))
 		version := viper.GetString("download.version")
 		build := viper.GetString("download.build")
 		// flags
@@ -124,9 +126,42 @@ var wikiCmd = &cobra.Command{
 		output := viper.GetString("download.wiki.output")
 		flat := viper.GetBool("download.wiki.flat")
 
+		if viper.GetBool("download.wiki.keys") {
+			if len(device) == 0 || len(build) == 0 {
+				return fmt.Errorf("must specify --device and --build with --keys")
+			}
+			keys, err := download.GetWikiFirmwareKeysForBuild(device, build, proxy, insecure)
+			if err != nil {
+				return fmt.Errorf("failed to get firmware keys: %v", err)
+			}
+			if viper.GetBool("download.wiki.json") {
+				return json.NewEncoder(os.Stdout).Encode(keys)
+			}
+			log.WithFields(log.Fields{
+				"version":  keys.Version,
+				"build":    keys.Build,
+				"device":   keys.Device,
+				"codename": keys.Codename,
+			}).Info("Firmware Keys")
+			for _, comp := range keys.Components() {
+				k := keys.Keys[comp]
+				fmt.Printf("%s (%s)\n", comp, k.Filename)
+				if len(k.IV) > 0 {
+					fmt.Printf("    IV:   %s\n", k.IV)
+				}
+				if len(k.Key) > 0 {
+					fmt.Printf("    Key:  %s\n", k.Key)
+				}
+				if len(k.KBAG) > 0 {
+					fmt.Printf("    KBAG: %s\n", k.KBAG)
+				}
+			}
+			return nil
+		}
+
 		// validate flags
 		if !dlIPSWs && !dlOTAs {
-			return fmt.Errorf("must specify one of --ipsw or --ota")
+			return fmt.Errorf("must specify one of --ipsw, --ota or --keys")
 		}
 		if len(device) == 0 && len(version) == 0 && len(build) == 0 {
 			return fmt.Errorf("must specify at least one of --device, --version, or --build")
@@ -262,7 +297,9 @@ var wikiCmd = &cobra.Command{
 						prompt := &survey.Confirm{
 							Message: fmt.Sprintf("You are about to download %d IPSW files. Continue?", len(filteredIPSW)),
 						}
-						survey.AskOne(prompt, &cont)
+						if err := utils.AskOne(prompt, &cont); err != nil {
+							return err
+						}
 					}
 				}
 
@@ -480,7 +517,9 @@ var wikiCmd = &cobra.Command{
 						prompt := &survey.Confirm{
 							Message: fmt.Sprintf("You are about to download %d OTA files. Continue?", len(filteredOTAs)),
 						}
-						survey.AskOne(prompt, &cont)
+						if err := utils.AskOne(prompt, &cont); err != nil {
+							return err
+						}
 					}
 				}
 
@@ -24,12 +24,16 @@ THE SOFTWARE.
 package download
 
 import (
+	"fmt"
+	"os"
 	"path"
+	"path/filepath"
 
 	"github.com/AlecAivazis/survey/v2"
 	"github.com/AlecAivazis/survey/v2/terminal"
 	"github.com/apex/log"
 	"github.com/blacktop/ipsw/internal/download"
+	"github.com/blacktop/ipsw/internal/exitcode"
 	"github.com/blacktop/ipsw/internal/utils"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -39,13 +43,21 @@ func init() {
 	DownloadCmd.AddCommand(xcodeCmd)
 	xcodeCmd.Flags().BoolP("latest", "l", false, "Download newest XCode")
 	xcodeCmd.Flags().BoolP("sim", "s", false, "Download Simulator Runtimes")
+	xcodeCmd.Flags().StringP("platform", "p", "", "Simulator runtime platform to download (ios, watchos, tvos, visionos)")
+	xcodeCmd.Flags().Bool("list", false, "List available Simulator Runtimes")
+	xcodeCmd.Flags().BoolP("install", "i", false, "Install Simulator Runtime after download")
+	xcodeCmd.Flags().StringP("output", "o", "", "Folder to download files to")
+	xcodeCmd.MarkFlagDirname("output")
+	viper.BindPFlag("download.xcode.platform", xcodeCmd.Flags().Lookup("platform"))
+	viper.BindPFlag("download.xcode.list", xcodeCmd.Flags().Lookup("list"))
+	viper.BindPFlag("download.xcode.install", xcodeCmd.Flags().Lookup("install"))
+	viper.BindPFlag("download.xcode.output", xcodeCmd.Flags().Lookup("output"))
 
 	xcodeCmd.SetHelpFunc(func(c *cobra.Command, s []string) {
 		DownloadCmd.PersistentFlags().MarkHidden("white-list")
 		DownloadCmd.PersistentFlags().MarkHidden("black-list")
 		DownloadCmd.PersistentFlags().MarkHidden("device")
 		DownloadCmd.PersistentFlags().MarkHidden("model")
-		DownloadCmd.PersistentFlags().MarkHidden("version")
 		DownloadCmd.PersistentFlags().MarkHidden("build")
 		DownloadCmd.PersistentFlags().MarkHidden("confirm")
 		DownloadCmd.PersistentFlags().MarkHidden("remove-commas")
@@ -71,6 +83,7 @@ var xcodeCmd = &cobra.Command{
 		viper.BindPFlag("download.skip-all", cmd.Flags().Lookup("skip-all"))
 		viper.BindPFlag("download.resume-all", cmd.Flags().Lookup("resume-all"))
 		viper.BindPFlag("download.restart-all", cmd.Flags().Lookup("restart-all"))
+		viper.BindPFlag("download.version", cmd.Flags().Lookup("version"))
 
 		// settings
 		proxy := viper.GetString("download.proxy")
@@ -81,41 +94,79 @@ var xcodeCmd = &cobra.Command{
 		// flags
 		latest, _ := cmd.Flags().GetBool("latest")
 		dlSim, _ := cmd.Flags().GetBool("sim")
+		platform := viper.GetString("download.xcode.platform")
+		version := viper.GetString("download.version")
+		install := viper.GetBool("download.xcode.install")
+		output := viper.GetString("download.xcode.output")
 
-		if dlSim {
+		if dlSim || len(platform) > 0 || viper.GetBool("download.xcode.list") {
 			dvt, err := download.GetDVTDownloadableIndex()
 			if err != nil {
 				return err
 			}
 
-			var choices []string
-			for _, dl := range dvt.Downloadables {
-				choices = append(choices, dl.Name)
+			runtimes, err := dvt.GetSimulatorRuntimes(platform, version)
+			if err != nil {
+				return err
 			}
-
-			var choice string
-			prompt := &survey.Select{
-				Message:  "Select what to download:",
-				Options:  choices,
-				PageSize: 10,
+			if len(runtimes) == 0 {
+				return exitcode.Errorf(exitcode.NotFound, "no simulator runtimes found for given options")
 			}
-			if err := survey.AskOne(prompt, &choice); err == terminal.InterruptErr {
-				log.Warn("Exiting...")
+
+			if viper.GetBool("download.xcode.list") {
+				for _, rt := range runtimes {
+					auth := ""
+					if rt.RequiresAuth() {
+						auth = " (requires dev portal login)"
+					}
+					fmt.Printf("%-30s %-12s %s%s\n", rt.Name, rt.SimulatorVersion.BuildUpdate, rt.Source, auth)
+				}
 				return nil
 			}
 
 			var dl download.Downloadable
-			for _, d := range dvt.Downloadables {
-				if d.Name == choice {
-					dl = d
+			if len(platform) > 0 && len(version) > 0 {
+				dl = runtimes[0] // non-interactive (newest matching runtime)
+			} else {
+				var choices []string
+				for _, rt := range runtimes {
+					choices = append(choices, rt.Name)
+				}
+
+				var choice string
+				prompt := &survey.Select{
+					Message:  "Select what to download:",
+					Options:  choices,
+					PageSize: 10,
+				}
+				if err := utils.AskOne(prompt, &choice); err != nil {
+					if err == terminal.InterruptErr {
+						log.Warn("Exiting...")
+						return nil
+					}
+					return err
+				}
+
+				for _, d := range runtimes {
+					if d.Name == choice {
+						dl = d
+					}
 				}
 			}
 
-			if dl.Authentication == "" {
+			destName := path.Base(dl.Source)
+			if len(output) > 0 {
+				if err := os.MkdirAll(output, 0750); err != nil {
+					return fmt.Errorf("failed to create directory %s: %v", output, err)
+				}
+				destName = filepath.Join(filepath.Clean(output), destName)
+			}
+
+			if !dl.RequiresAuth() {
 				log.Infof("Downloading %s...", dl.Name)
 				downloader := download.NewDownload(proxy, insecure, skipAll, resumeAll, restartAll, false, viper.GetBool("verbose"))
 				downloader.URL = dl.Source
-				downloader.DestName = path.Base(dl.Source)
+				downloader.DestName = destName
 				if err := downloader.Do(); err != nil {
 					return err
 				}
@@ -133,17 +184,21 @@ var xcodeCmd = &cobra.Command{
 				}
 			}
 
-			install := false
-			iprompt := &survey.Confirm{
-				Message: "Install Simulator Runtime?",
-			}
-			if err := survey.AskOne(iprompt, &install); err == terminal.InterruptErr {
-				log.Warn("Exiting...")
-				return nil
+			if !install && !(len(platform) > 0 && len(version) > 0) {
+				iprompt := &survey.Confirm{
+					Message: "Install Simulator Runtime?",
+				}
+				if err := utils.AskOne(iprompt, &install); err != nil {
+					if err == terminal.InterruptErr {
+						log.Warn("Exiting...")
+						return nil
+					}
+					return err
+				}
 			}
 
 			if install {
-				return utils.InstallXCodeSimRuntime(path.Base(dl.Source))
+				return utils.InstallXCodeSimRuntime(destName)
 			}
 
 			return nil
@@ -168,9 +223,12 @@ var xcodeCmd = &cobra.Command{
 				Options:  choices,
 				PageSize: 10,
 			}
-			if err := survey.AskOne(prompt, &choice); err == terminal.InterruptErr {
-				log.Warn("Exiting...")
-				return nil
+			if err := utils.AskOne(prompt, &choice); err != nil {
+				if err == terminal.InterruptErr {
+					log.Warn("Exiting...")
+					return nil
+				}
+				return err
 			}
 		}
 
@@ -32,6 +32,7 @@ import (
 	"github.com/apex/log"
 	"github.com/blacktop/go-macho"
 	"github.com/blacktop/go-macho/pkg/fixupchains"
+	"github.com/blacktop/ipsw/internal/utils"
 	"github.com/blacktop/ipsw/pkg/dyld"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
@@ -122,7 +123,7 @@ var dyldExtractCmd = &cobra.Command{
 	RunE: func(cmd *cobra.Command, args []string) error {
 
 		var bar *mpb.Bar
-		var p *mpb.Progress
+		var p *utils.Progress
 		var images []*dyld.CacheImage
 
 		if viper.GetBool("verbose") {
@@ -189,7 +190,7 @@ var dyldExtractCmd = &cobra.Command{
 			// set images to all images in shared cache
 			images = f.Images
 			// initialize progress bar
-			p = mpb.New(mpb.WithWidth(80))
+			p = utils.NewProgress("Extracting dylibs", mpb.WithWidth(80)).Counts()
 			// adding a single bar, which will inherit container's width
 			name := "      "
 			bar = p.New(int64(len(images)),
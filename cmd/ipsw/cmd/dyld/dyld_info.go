@@ -220,8 +220,8 @@ var dyldInfoCmd = &cobra.Command{
 							fmt.Fprintf(w, "        OU: %s\tCN: %s\t(%s thru %s)\n",
 								ou,
 								cert.Subject.CommonName,
-								cert.NotBefore.Format("02Jan2006 15:04:05"),
-								cert.NotAfter.Format("02Jan2006 15:04:05"))
+								utils.FormatTime(cert.NotBefore),
+								utils.FormatTime(cert.NotAfter))
 						}
 						w.Flush()
 					}
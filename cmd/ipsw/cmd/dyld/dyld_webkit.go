@@ -201,7 +201,7 @@ var WebkitCmd = &cobra.Command{
 						log.Infof("WebKit Version: %s", webkit1)
 						utils.Indent(log.Info, 2)(fmt.Sprintf("Tag:  %s", tag.Name))
 						utils.Indent(log.Info, 2)(fmt.Sprintf("URL:  %s", tag.TarURL))
-						utils.Indent(log.Info, 2)(fmt.Sprintf("Date: %s", tag.Commit.Date.Format("02Jan2006 15:04:05")))
+						utils.Indent(log.Info, 2)(fmt.Sprintf("Date: %s", utils.FormatTime(tag.Commit.Date)))
 					}
 					return nil
 				}
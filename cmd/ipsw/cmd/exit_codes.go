@@ -0,0 +1,50 @@
+/*
+Copyright © 2023 blacktop
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/blacktop/ipsw/internal/exitcode"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(exitCodesCmd)
+}
+
+// exitCodesCmd is a help topic (`ipsw help exit-codes`) documenting the exit codes
+var exitCodesCmd = &cobra.Command{
+	Use:   "exit-codes",
+	Short: "Exit codes returned by ipsw (for scripting)",
+	Long:  exitCodesHelp(),
+}
+
+func exitCodesHelp() string {
+	var sb strings.Builder
+	sb.WriteString("ipsw exits with one of the following codes so that scripts can branch on the type of failure:\n\n")
+	for _, c := range exitcode.Codes {
+		sb.WriteString(fmt.Sprintf("  %3d  %-13s %s\n", c.Code, c.Name, c.Description))
+	}
+	return sb.String()
+}
@@ -55,6 +55,8 @@ func init() {
 	extractCmd.Flags().BoolP("json", "j", false, "Output extracted paths as JSON")
 	extractCmd.Flags().StringArrayP("dyld-arch", "a", []string{}, "dyld_shared_cache architecture to extract")
 	extractCmd.Flags().Bool("driverkit", false, "Extract DriverKit dyld_shared_cache")
+	extractCmd.Flags().String("pack", "", "Stream the files matching --pattern into an archive instead of extracting them (.tar, .tar.gz, .tar.xz, .tar.zst or .zip)")
+	extractCmd.Flags().Bool("reproducible", false, "Normalize timestamps, permissions and order of the extracted files (uses $SOURCE_DATE_EPOCH)")
 	extractCmd.RegisterFlagCompletionFunc("dmg", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 		return []string{
 			"app\tAppOS",
@@ -82,13 +84,26 @@ func init() {
 	viper.BindPFlag("extract.json", extractCmd.Flags().Lookup("json"))
 	viper.BindPFlag("extract.dyld-arch", extractCmd.Flags().Lookup("dyld-arch"))
 	viper.BindPFlag("extract.driverkit", extractCmd.Flags().Lookup("driverkit"))
+	viper.BindPFlag("extract.pack", extractCmd.Flags().Lookup("pack"))
+	viper.BindPFlag("extract.reproducible", extractCmd.Flags().Lookup("reproducible"))
 }
 
 // extractCmd represents the extract command
 var extractCmd = &cobra.Command{
-	Use:           "extract <IPSW/OTA | URL>",
-	Aliases:       []string{"e", "ex"},
-	Short:         "Extract kernelcache, dyld_shared_cache or DeviceTree from IPSW/OTA",
+	Use:     "extract <IPSW/OTA | URL>",
+	Aliases: []string{"e", "ex"},
+	Short:   "Extract kernelcache, dyld_shared_cache or DeviceTree from IPSW/OTA",
+	Example: `  # Extract the kernelcache from a remote IPSW (only the kernelcache is downloaded)
+  ❯ ipsw extract --remote --kernel https://updates.cdn-apple.com/.../iPhone15,2_17.0_21A329_Restore.ipsw
+
+  # Extract the BuildManifest.plist from a remote IPSW
+  ❯ ipsw extract --remote --pattern 'BuildManifest.plist$' https://updates.cdn-apple.com/.../iPhone15,2_17.0_21A329_Restore.ipsw
+
+  # Extract the SystemOS cryptex DMG from a remote IPSW
+  ❯ ipsw extract --remote --dmg sys https://updates.cdn-apple.com/.../iPhone15,2_17.0_21A329_Restore.ipsw
+
+  # Pack the im4p firmwares of an IPSW into an archive (without extracting them to disk)
+  ❯ ipsw extract --pattern '.*im4p$' --pack firmware.tar.zst iPhone15,2_17.0_21A329_Restore.ipsw`,
 	Args:          cobra.MinimumNArgs(1),
 	SilenceUsage:  true,
 	SilenceErrors: true,
@@ -116,6 +131,10 @@ var extractCmd = &cobra.Command{
 			}
 		} else if viper.GetBool("extract.files") && len(viper.GetString("extract.pattern")) == 0 {
 			return fmt.Errorf("--pattern or -p must be used with --files or -f")
+		} else if len(viper.GetString("extract.pack")) > 0 && len(viper.GetString("extract.pattern")) == 0 {
+			return fmt.Errorf("--pack requires --pattern")
+		} else if len(viper.GetString("extract.pack")) > 0 && viper.GetBool("extract.files") {
+			return fmt.Errorf("--pack can only pack IPSW entries (not --files from the DMGs)")
 		} else if viper.GetBool("extract.driverkit") && !viper.GetBool("extract.dyld") {
 			return fmt.Errorf("--driverkit can only be used with --dyld or -d")
 		}
@@ -133,6 +152,8 @@ var extractCmd = &cobra.Command{
 			Flatten:   viper.GetBool("extract.flat"),
 			Progress:  true,
 			Output:    viper.GetString("extract.output"),
+
+			Reproducible: viper.GetBool("extract.reproducible"),
 		}
 
 		if viper.GetBool("extract.remote") {
@@ -141,6 +162,32 @@ var extractCmd = &cobra.Command{
 			config.IPSW = args[0]
 		}
 
+		if pack := viper.GetString("extract.pack"); len(pack) > 0 {
+			log.Infof("Packing files matching pattern %#v into %s", config.Pattern, pack)
+			out, err := extract.Pack(config, pack)
+			if err != nil {
+				return err
+			}
+			if viper.GetBool("extract.json") {
+				dat, err := json.Marshal(out)
+				if err != nil {
+					return fmt.Errorf("failed to marshal packed paths as JSON: %s", err)
+				}
+				fmt.Println(string(dat))
+			} else {
+				utils.Indent(log.Info, 2)(fmt.Sprintf("Created %s (%d files)", pack, len(out)))
+			}
+			return nil
+		}
+
+		// normalize the extracted files so that their hashes are comparable across machines
+		finish := func(out []string) ([]string, error) {
+			if !viper.GetBool("extract.reproducible") {
+				return out, nil
+			}
+			return extract.Reproducible(config, out)
+		}
+
 		if typ, err := extract.FirmwareType(config); err == nil {
 			if typ == "OTA" {
 				log.Warn("Extracting from OTA may not work (you should try the `ipsw ota extract` command)")
@@ -153,6 +200,13 @@ var extractCmd = &cobra.Command{
 			if err != nil {
 				return err
 			}
+			var kernels []string
+			for fn := range out {
+				kernels = append(kernels, fn)
+			}
+			if kernels, err = finish(kernels); err != nil {
+				return err
+			}
 			if viper.GetBool("extract.json") {
 				dat, err := json.Marshal(out)
 				if err != nil {
@@ -160,7 +214,7 @@ var extractCmd = &cobra.Command{
 				}
 				fmt.Println(string(dat))
 			} else {
-				for fn := range out {
+				for _, fn := range kernels {
 					utils.Indent(log.Info, 2)("Created " + fn)
 				}
 			}
@@ -172,6 +226,9 @@ var extractCmd = &cobra.Command{
 			if err != nil {
 				return err
 			}
+			if out, err = finish(out); err != nil {
+				return err
+			}
 			if viper.GetBool("extract.json") {
 				dat, err := json.Marshal(out)
 				if err != nil {
@@ -187,24 +244,23 @@ var extractCmd = &cobra.Command{
 
 		if viper.GetString("extract.dmg") != "" {
 			config.DMGs = true
-			if viper.GetBool("extract.remote") {
-				log.Error("unable to extract File System DMG remotely (let the author know if this is something you want)")
-			} else {
-				log.Info("Extracting DMG")
-				out, err := extract.DMG(config)
+			log.Info("Extracting DMG")
+			out, err := extract.DMG(config)
+			if err != nil {
+				return err
+			}
+			if out, err = finish(out); err != nil {
+				return err
+			}
+			if viper.GetBool("extract.json") {
+				dat, err := json.Marshal(out)
 				if err != nil {
-					return err
+					return fmt.Errorf("failed to marshal output paths as JSON: %s", err)
 				}
-				if viper.GetBool("extract.json") {
-					dat, err := json.Marshal(out)
-					if err != nil {
-						return fmt.Errorf("failed to marshal output paths as JSON: %s", err)
-					}
-					fmt.Println(string(dat))
-				} else {
-					for _, f := range out {
-						utils.Indent(log.Info, 2)("Created " + f)
-					}
+				fmt.Println(string(dat))
+			} else {
+				for _, f := range out {
+					utils.Indent(log.Info, 2)("Created " + f)
 				}
 			}
 		}
@@ -216,6 +272,9 @@ var extractCmd = &cobra.Command{
 			if err != nil {
 				return err
 			}
+			if out, err = finish(out); err != nil {
+				return err
+			}
 			if viper.GetBool("extract.json") {
 				dat, err := json.Marshal(out)
 				if err != nil {
@@ -236,6 +295,9 @@ var extractCmd = &cobra.Command{
 			if err != nil {
 				return err
 			}
+			if out, err = finish(out); err != nil {
+				return err
+			}
 			if viper.GetBool("extract.json") {
 				dat, err := json.Marshal(out)
 				if err != nil {
@@ -256,6 +318,9 @@ var extractCmd = &cobra.Command{
 			if err != nil {
 				return err
 			}
+			if out, err = finish(out); err != nil {
+				return err
+			}
 			if viper.GetBool("extract.json") {
 				dat, err := json.Marshal(out)
 				if err != nil {
@@ -275,6 +340,9 @@ var extractCmd = &cobra.Command{
 			if err != nil {
 				return err
 			}
+			if out, err = finish(out); err != nil {
+				return err
+			}
 			if viper.GetBool("extract.json") {
 				dat, err := json.Marshal(out)
 				if err != nil {
@@ -294,6 +362,9 @@ var extractCmd = &cobra.Command{
 			if err != nil {
 				return err
 			}
+			if _, err := finish([]string{out}); err != nil {
+				return err
+			}
 			utils.Indent(log.Info, 2)("Created " + out)
 		}
 
@@ -319,6 +390,9 @@ var extractCmd = &cobra.Command{
 			if err != nil {
 				return err
 			}
+			if out, err = finish(out); err != nil {
+				return err
+			}
 			if viper.GetBool("extract.json") {
 				dat, err := json.Marshal(out)
 				if err != nil {
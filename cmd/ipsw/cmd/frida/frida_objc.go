@@ -129,9 +129,12 @@ var fridaObjcCmd = &cobra.Command{
 					Message: "Select what device to connect to:",
 					Options: choices,
 				}
-				if err := survey.AskOne(prompt, &selected); err == terminal.InterruptErr {
-					log.Warn("Exiting...")
-					os.Exit(0)
+				if err := utils.AskOne(prompt, &selected); err != nil {
+					if err == terminal.InterruptErr {
+						log.Warn("Exiting...")
+						os.Exit(0)
+					}
+					return err
 				}
 				dev = devices[selected]
 			}
@@ -36,6 +36,7 @@ func init() {
 	AfcCmd.AddCommand(idevAfcRmCmd)
 
 	idevAfcRmCmd.Flags().BoolP("recursive", "r", false, "recursive delete")
+	idevAfcRmCmd.Flags().BoolP("force", "f", false, "Do not prompt for confirmation")
 }
 
 // idevAfcRmCmd represents the rm command
@@ -53,6 +54,7 @@ var idevAfcRmCmd = &cobra.Command{
 
 		udid, _ := cmd.Flags().GetString("udid")
 		recursive, _ := cmd.Flags().GetBool("recursive")
+		force, _ := cmd.Flags().GetBool("force")
 
 		if len(udid) == 0 {
 			dev, err := utils.PickDevice()
@@ -68,11 +70,15 @@ var idevAfcRmCmd = &cobra.Command{
 		}
 		defer cli.Close()
 
-		yes := false
-		prompt := &survey.Confirm{
-			Message: fmt.Sprintf("Are you sure you want to delete '%s'?", args[0]),
+		yes := force
+		if !force {
+			prompt := &survey.Confirm{
+				Message: fmt.Sprintf("Are you sure you want to delete '%s'?", args[0]),
+			}
+			if err := utils.AskOne(prompt, &yes); err != nil {
+				return err
+			}
 		}
-		survey.AskOne(prompt, &yes)
 
 		if yes {
 			if recursive {
@@ -29,7 +29,6 @@ import (
 	"github.com/apex/log"
 	"github.com/blacktop/ipsw/internal/utils"
 	"github.com/blacktop/ipsw/pkg/usb/afc"
-	"github.com/dustin/go-humanize"
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -87,7 +86,7 @@ var idevAfcTreeCmd = &cobra.Command{
 					fmt.Println(dirColor(path))
 				} else {
 					if viper.GetBool("verbose") {
-						fmt.Printf("%s (%s)\n", path, sizeColor(humanize.Bytes(uint64(info.Size()))))
+						fmt.Printf("%s (%s)\n", path, sizeColor(utils.FormatBytes(uint64(info.Size()))))
 					} else {
 						fmt.Println(path)
 					}
@@ -122,7 +121,7 @@ var idevAfcTreeCmd = &cobra.Command{
 				} else {
 					text := filepath.Base(path)
 					if viper.GetBool("verbose") {
-						text = fmt.Sprintf("%s (%s)", text, sizeColor(humanize.Bytes(uint64(info.Size()))))
+						text = fmt.Sprintf("%s (%s)", text, sizeColor(utils.FormatBytes(uint64(info.Size()))))
 					}
 					if curDir.Path() == filepath.Dir(path) {
 						curDir.Add(path, text)
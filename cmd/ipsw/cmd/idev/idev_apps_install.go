@@ -69,7 +69,7 @@ var idevAppsInstallCmd = &cobra.Command{
 		defer cli.Close()
 
 		// initialize progress bar
-		p := mpb.New(mpb.WithWidth(80))
+		p := utils.NewProgress("Installing "+filepath.Base(ipaPath), mpb.WithWidth(80)).Counts()
 		// adding a single bar, which will inherit container's width
 		name := "Installing"
 		bar := p.New(100,
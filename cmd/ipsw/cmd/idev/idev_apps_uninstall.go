@@ -68,7 +68,7 @@ var idevAppsUninstallCmd = &cobra.Command{
 		defer cli.Close()
 
 		// initialize progress bar
-		p := mpb.New(mpb.WithWidth(80))
+		p := utils.NewProgress("Uninstalling "+args[0], mpb.WithWidth(80)).Counts()
 		// adding a single bar, which will inherit container's width
 		name := "Uninstalling"
 		bar := p.New(100,
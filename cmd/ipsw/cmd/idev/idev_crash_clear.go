@@ -36,6 +36,7 @@ import (
 func init() {
 	CrashCmd.AddCommand(iDevCrashClearCmd)
 
+	iDevCrashClearCmd.Flags().BoolP("force", "f", false, "Do not prompt for confirmation")
 }
 
 // iDevCrashClearCmd represents the clear command
@@ -51,6 +52,7 @@ var iDevCrashClearCmd = &cobra.Command{
 		}
 
 		udid, _ := cmd.Flags().GetString("udid")
+		force, _ := cmd.Flags().GetBool("force")
 
 		if len(udid) == 0 {
 			dev, err := utils.PickDevice()
@@ -66,11 +68,15 @@ var iDevCrashClearCmd = &cobra.Command{
 		}
 		defer cli.Close()
 
-		yes := false
-		prompt := &survey.Confirm{
-			Message: "Are you sure you want to delete ALL the crashlogs?",
+		yes := force
+		if !force {
+			prompt := &survey.Confirm{
+				Message: "Are you sure you want to delete ALL the crashlogs?",
+			}
+			if err := utils.AskOne(prompt, &yes); err != nil {
+				return err
+			}
 		}
-		survey.AskOne(prompt, &yes)
 
 		if yes {
 			if err := cli.RemoveAll("/"); err != nil {
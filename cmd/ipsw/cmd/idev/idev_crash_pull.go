@@ -118,7 +118,7 @@ var iDevCrashPullCmd = &cobra.Command{
 				Options:  logs,
 				PageSize: 50,
 			}
-			if err := survey.AskOne(prompt, &choices, survey.WithKeepFilter(true)); err != nil {
+			if err := utils.AskOne(prompt, &choices, survey.WithKeepFilter(true)); err != nil {
 				if err == terminal.InterruptErr {
 					log.Warn("Exiting...")
 					os.Exit(0)
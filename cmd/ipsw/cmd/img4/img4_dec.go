@@ -28,6 +28,7 @@ import (
 
 	"github.com/apex/log"
 	icmd "github.com/blacktop/ipsw/internal/commands/img4"
+	"github.com/blacktop/ipsw/internal/download"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -39,6 +40,12 @@ func init() {
 	decImg4Cmd.PersistentFlags().StringP("iv", "i", "", "AES iv")
 	decImg4Cmd.PersistentFlags().StringP("key", "k", "", "AES key")
 	decImg4Cmd.PersistentFlags().StringP("output", "o", "", "Output file")
+	decImg4Cmd.Flags().StringP("device", "d", "", "Lookup keys on theapplewiki.com for device (i.e. iPhone12,1)")
+	decImg4Cmd.Flags().StringP("build", "b", "", "Lookup keys on theapplewiki.com for build (i.e. 17A577)")
+	decImg4Cmd.Flags().StringP("component", "c", "", "Component to lookup keys for (defaults to im4p filename)")
+	decImg4Cmd.Flags().String("proxy", "", "HTTP/HTTPS proxy")
+	decImg4Cmd.Flags().Bool("insecure", false, "do not verify ssl certs")
+	decImg4Cmd.MarkFlagsRequiredTogether("device", "build")
 }
 
 // decCmd represents the dec command
@@ -58,11 +65,40 @@ var decImg4Cmd = &cobra.Command{
 		ivkeyStr, _ := cmd.Flags().GetString("iv-key")
 		ivStr, _ := cmd.Flags().GetString("iv")
 		keyStr, _ := cmd.Flags().GetString("key")
+		device, _ := cmd.Flags().GetString("device")
+		build, _ := cmd.Flags().GetString("build")
 		// validate flags
-		if len(ivkeyStr) != 0 && (len(ivStr) != 0 || len(keyStr) != 0) {
+		if len(device) > 0 && len(build) > 0 {
+			if len(ivkeyStr) != 0 || len(ivStr) != 0 || len(keyStr) != 0 {
+				return fmt.Errorf("cannot specify --device/--build AND --iv-key or --iv/--key")
+			}
+			proxy, _ := cmd.Flags().GetString("proxy")
+			insecure, _ := cmd.Flags().GetBool("insecure")
+			component, _ := cmd.Flags().GetString("component")
+			if len(component) == 0 {
+				component = args[0]
+			}
+			keys, err := download.GetWikiFirmwareKeysForBuild(device, build, proxy, insecure)
+			if err != nil {
+				return fmt.Errorf("failed to get firmware keys: %v", err)
+			}
+			fwkey, err := keys.Get(component)
+			if err != nil {
+				return err
+			}
+			iv, key, err := fwkey.IVKey()
+			if err != nil {
+				return err
+			}
+			log.WithFields(log.Fields{
+				"iv":  fwkey.IV,
+				"key": fwkey.Key,
+			}).Debugf("Found keys for %s", fwkey.Filename)
+			return icmd.DecryptPayload(args[0], outputFile, iv, key)
+		} else if len(ivkeyStr) != 0 && (len(ivStr) != 0 || len(keyStr) != 0) {
 			return fmt.Errorf("cannot specify both --iv-key AND --iv/--key")
 		} else if len(ivkeyStr) == 0 && (len(ivStr) == 0 || len(keyStr) == 0) {
-			return fmt.Errorf("must specify either --iv-key OR --iv/--key")
+			return fmt.Errorf("must specify either --iv-key OR --iv/--key OR --device/--build")
 		}
 
 		var iv []byte
@@ -31,9 +31,10 @@ import (
 	"text/tabwriter"
 
 	"github.com/apex/log"
+	"github.com/blacktop/ipsw/internal/commands/extract"
 	"github.com/blacktop/ipsw/internal/download"
+	"github.com/blacktop/ipsw/internal/utils"
 	"github.com/blacktop/ipsw/pkg/info"
-	"github.com/dustin/go-humanize"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -45,12 +46,14 @@ func init() {
 	infoCmd.Flags().BoolP("remote", "r", false, "Extract from URL")
 	infoCmd.Flags().BoolP("list", "l", false, "List files in IPSW/OTA")
 	infoCmd.Flags().BoolP("json", "j", false, "Output as JSON")
+	infoCmd.Flags().BoolP("webkit", "w", false, "Show the WebKit and Safari versions in the IPSW's filesystem")
 
 	viper.BindPFlag("info.proxy", infoCmd.Flags().Lookup("proxy"))
 	viper.BindPFlag("info.insecure", infoCmd.Flags().Lookup("insecure"))
 	viper.BindPFlag("info.remote", infoCmd.Flags().Lookup("remote"))
 	viper.BindPFlag("info.list", infoCmd.Flags().Lookup("list"))
 	viper.BindPFlag("info.json", infoCmd.Flags().Lookup("json"))
+	viper.BindPFlag("info.webkit", infoCmd.Flags().Lookup("webkit"))
 
 	infoCmd.MarkZshCompPositionalArgumentFile(1, "*.ipsw", "*.zip")
 	infoCmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
@@ -74,6 +77,26 @@ var infoCmd = &cobra.Command{
 			log.SetLevel(log.DebugLevel)
 		}
 
+		if viper.GetBool("info.webkit") {
+			if viper.GetBool("info.remote") || viper.GetBool("info.list") {
+				return fmt.Errorf("--webkit cannot be used with --remote or --list")
+			}
+			wk, err := extract.WebKit(args[0])
+			if err != nil {
+				return fmt.Errorf("failed to get WebKit version: %v", err)
+			}
+			if viper.GetBool("info.json") {
+				dat, err := json.Marshal(wk)
+				if err != nil {
+					return fmt.Errorf("failed to JSON marshal WebKit versions: %v", err)
+				}
+				fmt.Println(string(dat))
+			} else {
+				fmt.Println(wk)
+			}
+			return nil
+		}
+
 		if viper.GetBool("info.remote") {
 			zr, err := download.NewRemoteZipReader(args[0], &download.RemoteConfig{
 				Proxy:    viper.GetString("info.proxy"),
@@ -87,7 +110,7 @@ var infoCmd = &cobra.Command{
 				fmt.Fprintf(w, "PATH\tSIZE\n")
 				fmt.Fprintf(w, "----\t----\n")
 				for _, f := range zr.File {
-					fmt.Fprintf(w, "%s\t%s\n", f.Name, humanize.Bytes(f.UncompressedSize64))
+					fmt.Fprintf(w, "%s\t%s\n", f.Name, utils.FormatBytes(f.UncompressedSize64))
 				}
 				w.Flush()
 			} else {
@@ -112,7 +135,7 @@ var infoCmd = &cobra.Command{
 				fmt.Fprintf(w, "PATH\tSIZE\n")
 				fmt.Fprintf(w, "----\t----\n")
 				for _, f := range zr.File {
-					fmt.Fprintf(w, "%s\t%s\n", f.Name, humanize.Bytes(f.UncompressedSize64))
+					fmt.Fprintf(w, "%s\t%s\n", f.Name, utils.FormatBytes(f.UncompressedSize64))
 				}
 				w.Flush()
 			} else {
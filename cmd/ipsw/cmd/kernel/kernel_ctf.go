@@ -120,7 +120,9 @@ var ctfdumpCmd = &cobra.Command{
 					Message: "Detected a universal MachO file, please select an architecture to analyze:",
 					Options: options,
 				}
-				survey.AskOne(prompt, &choice)
+				if err := utils.AskOne(prompt, &choice); err != nil {
+					return err
+				}
 				m = fat.Arches[choice].File
 			}
 		}
@@ -189,7 +191,9 @@ var ctfdumpCmd = &cobra.Command{
 						Message: "Detected a universal MachO file, please select an architecture to analyze:",
 						Options: options,
 					}
-					survey.AskOne(prompt, &choice)
+					if err := utils.AskOne(prompt, &choice); err != nil {
+						return err
+					}
 					m2 = fat.Arches[choice].File
 				}
 			}
@@ -56,7 +56,7 @@ func selectKDKs() ([]string, error) {
 		Options:  kdks,
 		PageSize: 15,
 	}
-	if err := survey.AskOne(prompt, &selKDKs, survey.WithValidator(survey.MinItems(2)), survey.WithValidator(survey.MaxItems(2))); err != nil {
+	if err := utils.AskOne(prompt, &selKDKs, survey.WithValidator(survey.MinItems(2)), survey.WithValidator(survey.MaxItems(2))); err != nil {
 		if err == terminal.InterruptErr {
 			log.Warn("Exiting...")
 			os.Exit(0)
@@ -88,7 +88,7 @@ func selectKDKs() ([]string, error) {
 		Options:  kerns,
 		PageSize: 15,
 	}
-	if err := survey.AskOne(prompt2, &kern); err != nil {
+	if err := utils.AskOne(prompt2, &kern); err != nil {
 		if err == terminal.InterruptErr {
 			log.Warn("Exiting...")
 			os.Exit(0)
@@ -120,7 +120,7 @@ func selectKDK() (string, error) {
 		Options:  kdks,
 		PageSize: 15,
 	}
-	if err := survey.AskOne(prompt, &selKDK); err != nil {
+	if err := utils.AskOne(prompt, &selKDK); err != nil {
 		if err == terminal.InterruptErr {
 			log.Warn("Exiting...")
 			os.Exit(0)
@@ -151,7 +151,7 @@ func selectKDK() (string, error) {
 		Options:  kerns,
 		PageSize: 15,
 	}
-	if err := survey.AskOne(prompt, &kern); err != nil {
+	if err := utils.AskOne(prompt, &kern); err != nil {
 		if err == terminal.InterruptErr {
 			log.Warn("Exiting...")
 			os.Exit(0)
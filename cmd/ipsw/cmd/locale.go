@@ -0,0 +1,100 @@
+/*
+Copyright © 2023 blacktop
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/apex/log"
+	"github.com/blacktop/ipsw/internal/commands/locale"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func init() {
+	rootCmd.AddCommand(localeCmd)
+
+	localeCmd.Flags().BoolP("json", "j", false, "Output as JSON")
+	localeCmd.Flags().StringP("output", "o", "", "File to write the output to")
+	viper.BindPFlag("locale.json", localeCmd.Flags().Lookup("json"))
+	viper.BindPFlag("locale.output", localeCmd.Flags().Lookup("output"))
+}
+
+// localeCmd represents the locale command
+var localeCmd = &cobra.Command{
+	Use:     "locale <IPSW|FOLDER> [IPSW|FOLDER]",
+	Aliases: []string{"locales"},
+	Short:   "List (or diff) the localizations and preinstalled apps in a build",
+	Example: `  # List the locales and apps in an IPSW's filesystem
+  ❯ ipsw locale iPhone15,2_17.0_21A329_Restore.ipsw
+
+  # Diff the locales and apps of two IPSWs as JSON
+  ❯ ipsw locale --json iPhone15,2_16.6_20G75_Restore.ipsw iPhone15,2_17.0_21A329_Restore.ipsw`,
+	Args:          cobra.RangeArgs(1, 2),
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+
+		if Verbose {
+			log.SetLevel(log.DebugLevel)
+		}
+
+		var invs []*locale.Inventory
+		for _, arg := range args {
+			log.Infof("Scanning %s", filepath.Base(arg))
+			inv, err := locale.Scan(filepath.Clean(arg))
+			if err != nil {
+				return fmt.Errorf("failed to scan %s: %v", arg, err)
+			}
+			invs = append(invs, inv)
+		}
+
+		var out fmt.Stringer = invs[0]
+		if len(invs) == 2 {
+			out = locale.Compare(invs[0], invs[1])
+		}
+
+		var dat []byte
+		if viper.GetBool("locale.json") {
+			var err error
+			dat, err = json.Marshal(out)
+			if err != nil {
+				return fmt.Errorf("failed to JSON marshal locales: %v", err)
+			}
+		} else {
+			dat = []byte(out.String())
+		}
+
+		if output := viper.GetString("locale.output"); len(output) > 0 {
+			if err := os.MkdirAll(filepath.Dir(output), 0750); err != nil {
+				return fmt.Errorf("failed to create directory %s: %v", filepath.Dir(output), err)
+			}
+			log.Infof("Writing to %s", output)
+			return os.WriteFile(output, dat, 0660)
+		}
+		fmt.Println(string(dat))
+		return nil
+	},
+}
@@ -34,6 +34,7 @@ import (
 	"github.com/blacktop/go-macho"
 	"github.com/blacktop/go-macho/types"
 	"github.com/blacktop/ipsw/internal/magic"
+	"github.com/blacktop/ipsw/internal/utils"
 	"github.com/blacktop/ipsw/pkg/disass"
 	"github.com/caarlos0/ctrlc"
 	"github.com/fatih/color"
@@ -174,7 +175,9 @@ var machoDisassCmd = &cobra.Command{
 						Message: "Detected a universal MachO file, please select an architecture to analyze:",
 						Options: options,
 					}
-					survey.AskOne(prompt, &choice)
+					if err := utils.AskOne(prompt, &choice); err != nil {
+						return err
+					}
 					m = fat.Arches[choice].File
 				}
 			}
@@ -237,7 +240,9 @@ var machoDisassCmd = &cobra.Command{
 									Message: fmt.Sprintf("Recreate %s. Continue?", cacheFile),
 									Default: true,
 								}
-								survey.AskOne(prompt, &yes)
+								if err := utils.AskOne(prompt, &yes); err != nil {
+									return err
+								}
 								if yes {
 									f.Close()
 									if err := os.Remove(cacheFile); err != nil {
@@ -136,7 +136,9 @@ var machoDumpCmd = &cobra.Command{
 					Message: "Detected a universal MachO file, please select an architecture to analyze:",
 					Options: options,
 				}
-				survey.AskOne(prompt, &choice)
+				if err := utils.AskOne(prompt, &choice); err != nil {
+					return err
+				}
 				m = fat.Arches[choice].File
 			}
 		}
@@ -224,7 +224,9 @@ var machoInfoCmd = &cobra.Command{
 					Message: "Detected a universal MachO file, please select an architecture to analyze:",
 					Options: options,
 				}
-				survey.AskOne(prompt, &choice)
+				if err := utils.AskOne(prompt, &choice); err != nil {
+					return err
+				}
 				m = fat.Arches[choice].File
 			}
 		}
@@ -592,8 +594,8 @@ var machoInfoCmd = &cobra.Command{
 							fmt.Fprintf(w, "        OU: %s\tCN: %s\t(%s thru %s)\n",
 								ou,
 								cert.Subject.CommonName,
-								cert.NotBefore.Format("02Jan2006 15:04:05"),
-								cert.NotAfter.Format("02Jan2006 15:04:05"))
+								utils.FormatTime(cert.NotBefore),
+								utils.FormatTime(cert.NotAfter))
 						}
 
 					}
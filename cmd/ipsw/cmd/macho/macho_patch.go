@@ -63,17 +63,19 @@ var supportedLCs = []string{
 	"LC_DYLD_ENVIRONMENT",
 }
 
-func confirm(path string, overwrite bool) bool {
+func confirm(path string, overwrite bool) (bool, error) {
 	if overwrite {
-		return true
+		return true, nil
 	}
 	yes := false
 	prompt := &survey.Confirm{
 		Message: fmt.Sprintf("You are about to overwrite %s. Continue?", path),
 		Default: true,
 	}
-	survey.AskOne(prompt, &yes)
-	return yes
+	if err := utils.AskOne(prompt, &yes); err != nil {
+		return false, err
+	}
+	return yes, nil
 }
 
 func pointerAlign(sz uint32) uint32 {
@@ -547,7 +549,9 @@ var machoPatchCmd = &cobra.Command{
 
 		if len(output) == 0 { // modify in place
 			output = machoPath
-			if !confirm(output, overwrite) { // confirm overwrite
+			if ok, err := confirm(output, overwrite); err != nil {
+				return err
+			} else if !ok { // confirm overwrite
 				return nil
 			}
 		}
@@ -152,7 +152,9 @@ var machoSignCmd = &cobra.Command{
 
 		if len(output) == 0 { // sign in place
 			output = machoPath
-			if !confirm(output, overwrite) { // confirm overwrite
+			if ok, err := confirm(output, overwrite); err != nil {
+				return err
+			} else if !ok { // confirm overwrite
 				return nil
 			}
 		}
@@ -32,7 +32,6 @@ import (
 	"github.com/apex/log"
 	"github.com/blacktop/ipsw/internal/utils"
 	"github.com/blacktop/ipsw/pkg/ota"
-	"github.com/dustin/go-humanize"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -66,7 +65,7 @@ var lsCmd = &cobra.Command{
 		fmt.Fprintf(w, "[ OTA zip files ] %s\n", strings.Repeat("-", 50))
 		for _, f := range files {
 			if !f.IsDir() {
-				fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", f.Mode(), f.ModTime().Format(time.RFC3339), humanize.Bytes(uint64(f.Size())), f.Name())
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", f.Mode(), f.ModTime().Format(time.RFC3339), utils.FormatBytes(uint64(f.Size())), f.Name())
 			}
 		}
 		w.Flush()
@@ -80,7 +79,7 @@ var lsCmd = &cobra.Command{
 		}
 		for _, f := range files {
 			if !f.IsDir() {
-				fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", f.Mode(), f.ModTime().Format(time.RFC3339), humanize.Bytes(uint64(f.Size())), f.Name())
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", f.Mode(), f.ModTime().Format(time.RFC3339), utils.FormatBytes(uint64(f.Size())), f.Name())
 			}
 		}
 		w.Flush()
@@ -0,0 +1,92 @@
+/*
+Copyright © 2023 blacktop
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/blacktop/ipsw/pkg/plist"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func init() {
+	plistCmd.AddCommand(plistConvertCmd)
+
+	plistConvertCmd.Flags().StringP("format", "f", plist.FormatXML, "Output format (xml, binary, json)")
+	plistConvertCmd.Flags().StringP("output", "o", "", "Output file (default: stdout for xml/json)")
+	plistConvertCmd.RegisterFlagCompletionFunc("format", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{plist.FormatXML, plist.FormatBinary, plist.FormatJSON}, cobra.ShellCompDirectiveNoFileComp
+	})
+	viper.BindPFlag("plist.convert.format", plistConvertCmd.Flags().Lookup("format"))
+	viper.BindPFlag("plist.convert.output", plistConvertCmd.Flags().Lookup("output"))
+}
+
+// plistConvertCmd represents the plist convert command
+var plistConvertCmd = &cobra.Command{
+	Use:           "convert <file>",
+	Aliases:       []string{"c"},
+	Short:         "Convert plist between binary, XML and JSON",
+	Args:          cobra.ExactArgs(1),
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+
+		if Verbose {
+			log.SetLevel(log.DebugLevel)
+		}
+
+		format := strings.ToLower(viper.GetString("plist.convert.format"))
+		output := viper.GetString("plist.convert.output")
+
+		data, err := os.ReadFile(filepath.Clean(args[0]))
+		if err != nil {
+			return fmt.Errorf("failed to read file %s: %v", args[0], err)
+		}
+
+		if inFormat, err := plist.GetFormat(data); err == nil {
+			log.Debugf("Converting %s plist to %s", inFormat, format)
+		}
+
+		out, err := plist.Convert(data, format)
+		if err != nil {
+			return err
+		}
+
+		if len(output) > 0 {
+			log.Infof("Creating %s", output)
+			return os.WriteFile(output, out, 0644)
+		}
+
+		if format == plist.FormatBinary {
+			return fmt.Errorf("refusing to write binary plist to stdout (use the --output flag)")
+		}
+
+		fmt.Println(string(out))
+
+		return nil
+	},
+}
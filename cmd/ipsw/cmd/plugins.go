@@ -0,0 +1,127 @@
+/*
+Copyright © 2023 blacktop
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// pluginPrefix is the prefix of the executables on PATH that are added as subcommands (git-style)
+const pluginPrefix = "ipsw-"
+
+// findPlugins returns the ipsw-<name> executables on PATH by name (the first one on PATH wins)
+func findPlugins() map[string]string {
+	plugins := make(map[string]string)
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		if len(dir) == 0 {
+			continue
+		}
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			fname := entry.Name()
+			if entry.IsDir() || !strings.HasPrefix(fname, pluginPrefix) {
+				continue
+			}
+			name := strings.TrimPrefix(fname, pluginPrefix)
+			if runtime.GOOS == "windows" {
+				if !strings.EqualFold(filepath.Ext(name), ".exe") {
+					continue
+				}
+				name = strings.TrimSuffix(name, filepath.Ext(name))
+			} else if info, err := entry.Info(); err != nil || info.Mode()&0111 == 0 {
+				continue
+			}
+			if _, ok := plugins[name]; !ok && len(name) > 0 {
+				plugins[name] = filepath.Join(dir, fname)
+			}
+		}
+	}
+	return plugins
+}
+
+// addPluginCommands adds the ipsw-<name> executables on PATH as `ipsw <name>` subcommands
+// (built-in commands take precedence)
+func addPluginCommands() {
+	builtin := make(map[string]bool)
+	for _, c := range rootCmd.Commands() {
+		builtin[c.Name()] = true
+		for _, alias := range c.Aliases {
+			builtin[alias] = true
+		}
+	}
+	for name, path := range findPlugins() {
+		if builtin[name] || name == "help" || name == "completion" {
+			continue
+		}
+		rootCmd.AddCommand(newPluginCmd(name, path))
+	}
+}
+
+func newPluginCmd(name, path string) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:                name,
+		Short:              "Plugin (" + path + ")",
+		DisableFlagParsing: true,
+		SilenceUsage:       true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runPlugin(path, args)
+		},
+	}
+	// `ipsw help <name>` shows the plugin's own help
+	cmd.SetHelpFunc(func(cmd *cobra.Command, args []string) {
+		runPlugin(path, []string{"--help"})
+	})
+	return cmd
+}
+
+// runPlugin runs a plugin with the config file and global settings in its environment
+func runPlugin(path string, args []string) error {
+	c := exec.Command(path, args...)
+	c.Stdin = os.Stdin
+	c.Stdout = os.Stdout
+	c.Stderr = os.Stderr
+	c.Env = append(os.Environ(),
+		"IPSW_BIN="+os.Args[0],
+		"IPSW_CONFIG_FILE="+viper.ConfigFileUsed(),
+		"IPSW_VERSION="+AppVersion,
+	)
+	if err := c.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			// the plugin already reported its error
+			os.Exit(exitErr.ExitCode())
+		}
+		return err
+	}
+	return nil
+}
@@ -22,6 +22,7 @@ THE SOFTWARE.
 package cmd
 
 import (
+	"crypto/x509"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -30,6 +31,8 @@ import (
 	"github.com/apex/log"
 	clihander "github.com/apex/log/handlers/cli"
 	"github.com/blacktop/ipsw/cmd/ipsw/cmd/appstore"
+	"github.com/blacktop/ipsw/cmd/ipsw/cmd/db"
+	"github.com/blacktop/ipsw/cmd/ipsw/cmd/dev"
 	"github.com/blacktop/ipsw/cmd/ipsw/cmd/download"
 	"github.com/blacktop/ipsw/cmd/ipsw/cmd/dyld"
 	"github.com/blacktop/ipsw/cmd/ipsw/cmd/frida"
@@ -39,6 +42,13 @@ import (
 	"github.com/blacktop/ipsw/cmd/ipsw/cmd/macho"
 	"github.com/blacktop/ipsw/cmd/ipsw/cmd/ota"
 	"github.com/blacktop/ipsw/cmd/ipsw/cmd/ssh"
+	"github.com/blacktop/ipsw/cmd/ipsw/cmd/store"
+	"github.com/blacktop/ipsw/cmd/ipsw/cmd/tss"
+	idl "github.com/blacktop/ipsw/internal/download"
+	"github.com/blacktop/ipsw/internal/exitcode"
+	"github.com/blacktop/ipsw/internal/utils"
+	"github.com/fatih/color"
+	"github.com/spf13/cast"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -64,9 +74,12 @@ var rootCmd = &cobra.Command{
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
+	addPluginCommands()
+	err := rootCmd.Execute()
+	idl.SaveBudgets() // the request budgets are only persisted periodically while running
+	if err != nil {
 		log.Error(err.Error())
-		os.Exit(1)
+		os.Exit(int(exitcode.Of(err)))
 	}
 }
 
@@ -83,14 +96,36 @@ func init() {
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.config/ipsw/config.yaml)")
 	rootCmd.PersistentFlags().BoolVarP(&Verbose, "verbose", "V", false, "verbose output")
 	rootCmd.PersistentFlags().BoolVar(&Color, "color", false, "colorize output")
+	rootCmd.PersistentFlags().Bool("non-interactive", false, "never prompt (error if input would be required)")
+	rootCmd.PersistentFlags().Bool("plain", false, "plain output (no colors, progress bars or prompt widgets; progress is printed as log lines)")
+	rootCmd.PersistentFlags().String("locale", "", "locale of human-readable sizes and dates (i.e. de-DE, ja, iso)")
+	rootCmd.PersistentFlags().Bool("raw", false, "raw sizes (byte counts) and dates (RFC3339 UTC) for log parsers")
+	rootCmd.PersistentFlags().Duration("heartbeat", 0, "print a status line at this interval during long operations (for CI)")
+	rootCmd.PersistentFlags().Int("retries", idl.DefaultRetryPolicy.MaxAttempts, "max attempts of HTTP requests and downloads that fail with 5xx or network errors")
+	rootCmd.PersistentFlags().Duration("retry-delay", idl.DefaultRetryPolicy.BaseDelay, "delay before the first retry (doubles with each retry)")
+	rootCmd.PersistentFlags().Float64("retry-jitter", idl.DefaultRetryPolicy.Jitter, "fraction of each retry delay that is randomized (0-1)")
+	rootCmd.PersistentFlags().Bool("no-http-cache", false, "do not cache API responses (they are re-validated with their ETag)")
+	rootCmd.PersistentFlags().Bool("offline", false, "resolve device/build lookups from the metadata DB only (see `ipsw db sync`)")
 	rootCmd.PersistentFlags().String("diff-tool", "", "git diff tool (for --diff commands)")
 	rootCmd.PersistentFlags().MarkHidden("diff-tool")
 	viper.BindPFlag("verbose", rootCmd.Flags().Lookup("verbose"))
 	viper.BindPFlag("color", rootCmd.Flags().Lookup("color"))
+	viper.BindPFlag("non-interactive", rootCmd.PersistentFlags().Lookup("non-interactive"))
+	viper.BindPFlag("plain", rootCmd.PersistentFlags().Lookup("plain"))
+	viper.BindPFlag("locale", rootCmd.PersistentFlags().Lookup("locale"))
+	viper.BindPFlag("raw", rootCmd.PersistentFlags().Lookup("raw"))
+	viper.BindPFlag("heartbeat", rootCmd.PersistentFlags().Lookup("heartbeat"))
+	viper.BindPFlag("retry.attempts", rootCmd.PersistentFlags().Lookup("retries"))
+	viper.BindPFlag("retry.delay", rootCmd.PersistentFlags().Lookup("retry-delay"))
+	viper.BindPFlag("retry.jitter", rootCmd.PersistentFlags().Lookup("retry-jitter"))
+	viper.BindPFlag("no-http-cache", rootCmd.PersistentFlags().Lookup("no-http-cache"))
+	viper.BindPFlag("offline", rootCmd.PersistentFlags().Lookup("offline"))
 	viper.BindPFlag("diff-tool", rootCmd.Flags().Lookup("diff-tool"))
 	viper.BindEnv("color", "CLICOLOR")
 	// Add subcommand groups
 	rootCmd.AddCommand(appstore.AppstoreCmd)
+	rootCmd.AddCommand(db.DbCmd)
+	rootCmd.AddCommand(dev.DevCmd)
 	rootCmd.AddCommand(download.DownloadCmd)
 	rootCmd.AddCommand(dyld.DyldCmd)
 	rootCmd.AddCommand(frida.FridaCmd)
@@ -100,8 +135,13 @@ func init() {
 	rootCmd.AddCommand(macho.MachoCmd)
 	rootCmd.AddCommand(ota.OtaCmd)
 	rootCmd.AddCommand(ssh.SSHCmd)
+	rootCmd.AddCommand(store.StoreCmd)
+	rootCmd.AddCommand(tss.TssCmd)
 	// Settings
 	rootCmd.CompletionOptions.HiddenDefaultCmd = true
+	rootCmd.SetFlagErrorFunc(func(_ *cobra.Command, err error) error {
+		return exitcode.Wrap(exitcode.Usage, err)
+	})
 }
 
 // initConfig reads in config file and ENV variables if set.
@@ -128,4 +168,95 @@ func initConfig() {
 	if err := viper.ReadInConfig(); err == nil {
 		fmt.Fprintln(os.Stderr, "Using config file:", viper.ConfigFileUsed())
 	}
+
+	utils.NonInteractive = viper.GetBool("non-interactive")
+	if viper.GetBool("plain") {
+		utils.Plain = true
+		Color = false
+		viper.Set("color", false)
+		color.NoColor = true
+	}
+	utils.Heartbeat = viper.GetDuration("heartbeat")
+	utils.RawOutput = viper.GetBool("raw")
+	utils.Locale = viper.GetString("locale")
+	if !utils.KnownLocale(utils.Locale) {
+		log.Warnf("unknown locale %s (using the default size/date formats)", utils.Locale)
+	}
+
+	// retry policy of all HTTP requests i.e. `retry: {attempts: 5, delay: 2s, max-delay: 1m, jitter: 0.5}`
+	policy := idl.DefaultRetryPolicy
+	policy.MaxAttempts = viper.GetInt("retry.attempts")
+	policy.BaseDelay = viper.GetDuration("retry.delay")
+	policy.Jitter = viper.GetFloat64("retry.jitter")
+	if viper.IsSet("retry.max-delay") {
+		policy.MaxDelay = viper.GetDuration("retry.max-delay")
+	}
+	idl.SetRetryPolicy(policy)
+
+	// API responses are cached in ~/.ipsw/http-cache unless disabled or moved i.e. `http-cache: /tmp/ipsw-cache`
+	if viper.GetBool("no-http-cache") {
+		idl.SetHTTPCacheDir("")
+	} else if dir := viper.GetString("http-cache"); len(dir) > 0 {
+		idl.SetHTTPCacheDir(dir)
+	}
+
+	// device/build lookups resolve from the metadata DB first once synced with `ipsw db sync` (and only from it with --offline)
+	if path, err := db.MetadataPath(); err == nil {
+		if _, err := os.Stat(path); err == nil {
+			if mdb, err := db.OpenMetadataDB(); err == nil {
+				idl.SetMetadataSource(mdb, viper.GetBool("offline"))
+			} else {
+				log.WithError(err).Warn("failed to open metadata DB")
+			}
+		} else if viper.GetBool("offline") {
+			idl.SetMetadataSource(nil, true)
+		}
+	}
+
+	// proxy credentials i.e. `IPSW_PROXY_USER=user IPSW_PROXY_PASSWORD=pass ipsw download ipsw --proxy socks5://host:1080`
+	idl.SetProxyAuth(viper.GetString("proxy-user"), viper.GetString("proxy-password"))
+
+	// connection settings of all HTTP requests i.e. `http: {dial-timeout: 10s, response-header-timeout: 2m, disable-http2: true, ca-cert: corp.pem}`
+	if viper.IsSet("http") {
+		conf := idl.DefaultClientConfig
+		if viper.IsSet("http.dial-timeout") {
+			conf.DialTimeout = viper.GetDuration("http.dial-timeout")
+		}
+		if viper.IsSet("http.tls-handshake-timeout") {
+			conf.TLSHandshakeTimeout = viper.GetDuration("http.tls-handshake-timeout")
+		}
+		if viper.IsSet("http.response-header-timeout") {
+			conf.ResponseHeaderTimeout = viper.GetDuration("http.response-header-timeout")
+		}
+		if viper.IsSet("http.idle-conn-timeout") {
+			conf.IdleConnTimeout = viper.GetDuration("http.idle-conn-timeout")
+		}
+		if viper.IsSet("http.max-idle-conns-per-host") {
+			conf.MaxIdleConnsPerHost = viper.GetInt("http.max-idle-conns-per-host")
+		}
+		conf.DisableHTTP2 = viper.GetBool("http.disable-http2")
+		if caCert := viper.GetString("http.ca-cert"); len(caCert) > 0 {
+			pool, err := x509.SystemCertPool()
+			if err != nil {
+				pool = x509.NewCertPool()
+			}
+			if pem, err := os.ReadFile(caCert); err != nil {
+				log.WithError(err).Warnf("failed to read CA certificate %s", caCert)
+			} else if !pool.AppendCertsFromPEM(pem) {
+				log.Warnf("failed to parse CA certificate %s", caCert)
+			} else {
+				conf.RootCAs = pool
+			}
+		}
+		idl.SetClientConfig(conf)
+	}
+
+	// stale device identifiers resolve with a deprecation warning i.e. `device-aliases: {OldDevice1,1: NewDevice1,1}`
+	idl.SetDeviceAliases(viper.GetStringMapString("device-aliases"))
+	idl.SetDeviceLookup(resolveDeviceName)
+
+	// per-provider request budgets (requests per hour) i.e. `budgets: {api.ipsw.me: 500}`
+	for provider, limit := range viper.GetStringMap("budgets") {
+		idl.SetBudget(provider, cast.ToInt(limit))
+	}
 }
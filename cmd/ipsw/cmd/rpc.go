@@ -0,0 +1,107 @@
+/*
+Copyright © 2024 blacktop
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/apex/log"
+	"github.com/blacktop/ipsw/internal/commands/rpc"
+	"github.com/blacktop/ipsw/internal/download"
+	"github.com/blacktop/ipsw/internal/utils"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func init() {
+	rootCmd.AddCommand(rpcCmd)
+
+	rpcCmd.Flags().StringP("output", "o", "", "Folder to save the submitted downloads to (if their dest is relative)")
+	rpcCmd.Flags().Int("workers", 2, "Number of downloads to run at once")
+	rpcCmd.Flags().String("proxy", "", "HTTP/HTTPS proxy")
+	rpcCmd.Flags().Bool("insecure", false, "do not verify ssl certs")
+	rpcCmd.MarkFlagDirname("output")
+	viper.BindPFlag("rpc.output", rpcCmd.Flags().Lookup("output"))
+	viper.BindPFlag("rpc.workers", rpcCmd.Flags().Lookup("workers"))
+	viper.BindPFlag("rpc.proxy", rpcCmd.Flags().Lookup("proxy"))
+	viper.BindPFlag("rpc.insecure", rpcCmd.Flags().Lookup("insecure"))
+}
+
+// rpcCmd represents the rpc command
+var rpcCmd = &cobra.Command{
+	Use:   "rpc",
+	Short: "Speak line-delimited JSON-RPC 2.0 over stdin/stdout (to embed ipsw as a subprocess)",
+	Long: `Speak line-delimited JSON-RPC 2.0 over stdin/stdout: read one request per line on stdin and write
+one response per line on stdout until stdin is closed (the logs go to stderr).
+
+The methods are the library's (i.e. ipsw_me.device, xcode.devices; call rpc.methods to list them) and the
+download queue's: download.submit, download.status, download.list, download.cancel, download.remove and
+download.wait (finished downloads are kept until removed, up to the last 100).
+Requests run concurrently so the responses can be out of order (match them by id); cancel an in-flight
+request with rpc.cancel. A download submitted with "progress": true sends download.progress notifications.
+
+A failed request's error has the JSON-RPC code and its libipsw error code and name in its data.`,
+	Example: `  # Look up a device
+  ❯ echo '{"jsonrpc": "2.0", "id": 1, "method": "ipsw_me.device", "params": {"identifier": "iPhone15,2"}}' | ipsw rpc
+  # Download a device's build and wait for it
+  ❯ ipsw rpc --output /tmp/ipsws
+  {"jsonrpc": "2.0", "id": 1, "method": "download.submit", "params": {"identifier": "iPhone15,2", "build": "21A329", "progress": true}}
+  {"jsonrpc": "2.0", "id": 2, "method": "download.wait", "params": {"id": "1"}}`,
+	Args:          cobra.NoArgs,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if Verbose {
+			log.SetLevel(log.DebugLevel)
+		}
+
+		// stdout is the protocol's: anything else printed to it goes to stderr instead
+		out := os.Stdout
+		os.Stdout = os.Stderr
+		utils.Plain = true
+
+		output := viper.GetString("rpc.output")
+		if len(output) > 0 {
+			if err := os.MkdirAll(output, 0750); err != nil {
+				return fmt.Errorf("failed to create output folder %s: %v", output, err)
+			}
+		}
+
+		manager := download.NewManager(viper.GetInt("rpc.workers"))
+		srv := rpc.NewServer(rpc.Config{
+			Output:   output,
+			Proxy:    viper.GetString("rpc.proxy"),
+			Insecure: viper.GetBool("rpc.insecure"),
+			Manager:  manager,
+		})
+
+		if err := srv.Serve(context.Background(), os.Stdin, out); err != nil {
+			return err
+		}
+		// NOTE: Serve waits for the in-flight requests (i.e. a download.wait) so only the downloads
+		// nobody waits for are canceled when stdin is closed
+		manager.Close()
+		return nil
+	},
+}
@@ -0,0 +1,143 @@
+/*
+Copyright © 2023 blacktop
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/blacktop/ipsw/internal/commands/sbom"
+	"github.com/blacktop/ipsw/internal/utils"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func init() {
+	rootCmd.AddCommand(sbomCmd)
+
+	sbomCmd.Flags().StringP("format", "f", "cyclonedx", fmt.Sprintf("SBOM format (%s)", strings.Join(sbom.Formats, ", ")))
+	sbomCmd.RegisterFlagCompletionFunc("format", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return sbom.Formats, cobra.ShellCompDirectiveDefault
+	})
+	sbomCmd.Flags().Bool("dsc", false, "Include the dyld_shared_cache images")
+	sbomCmd.Flags().Bool("oss", false, "Match binaries against the build's opensource.apple.com release")
+	sbomCmd.Flags().String("oss-release", "", "opensource.apple.com release to match against (i.e. 'macOS 14.0')")
+	sbomCmd.Flags().String("name", "", "OS name of FOLDER (i.e. iOS)")
+	sbomCmd.Flags().String("version", "", "OS version of FOLDER (i.e. 17.0)")
+	sbomCmd.Flags().String("build", "", "OS build of FOLDER (i.e. 21A329)")
+	sbomCmd.Flags().StringP("output", "o", "", "File to write the SBOM to")
+	sbomCmd.Flags().String("proxy", "", "HTTP/HTTPS proxy")
+	sbomCmd.Flags().Bool("insecure", false, "do not verify ssl certs")
+	viper.BindPFlag("sbom.format", sbomCmd.Flags().Lookup("format"))
+	viper.BindPFlag("sbom.dsc", sbomCmd.Flags().Lookup("dsc"))
+	viper.BindPFlag("sbom.oss", sbomCmd.Flags().Lookup("oss"))
+	viper.BindPFlag("sbom.oss-release", sbomCmd.Flags().Lookup("oss-release"))
+	viper.BindPFlag("sbom.name", sbomCmd.Flags().Lookup("name"))
+	viper.BindPFlag("sbom.version", sbomCmd.Flags().Lookup("version"))
+	viper.BindPFlag("sbom.build", sbomCmd.Flags().Lookup("build"))
+	viper.BindPFlag("sbom.output", sbomCmd.Flags().Lookup("output"))
+	viper.BindPFlag("sbom.proxy", sbomCmd.Flags().Lookup("proxy"))
+	viper.BindPFlag("sbom.insecure", sbomCmd.Flags().Lookup("insecure"))
+}
+
+// sbomCmd represents the sbom command
+var sbomCmd = &cobra.Command{
+	Use:   "sbom <IPSW|FOLDER>",
+	Short: "Generate an SPDX/CycloneDX SBOM for a build",
+	Example: `  # Generate a CycloneDX SBOM of an IPSW's filesystem
+  ❯ ipsw sbom iPhone15,2_17.0_21A329_Restore.ipsw -o 21A329.cdx.json
+
+  # Generate an SPDX SBOM matched against the build's opensource.apple.com release
+  ❯ ipsw sbom --format spdx --oss iPhone15,2_17.0_21A329_Restore.ipsw -o 21A329.spdx.json
+
+  # Generate an SBOM of a mounted filesystem
+  ❯ ipsw sbom --name macOS --version 14.0 --build 23A344 --oss /Volumes/Sonoma`,
+	Args:          cobra.ExactArgs(1),
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+
+		if Verbose {
+			log.SetLevel(log.DebugLevel)
+		}
+
+		format := strings.ToLower(viper.GetString("sbom.format"))
+		if !utils.StrSliceHas(sbom.Formats, format) {
+			return fmt.Errorf("invalid --format '%s' (must be one of: %s)", format, strings.Join(sbom.Formats, ", "))
+		}
+
+		conf := &sbom.Config{
+			Name:            viper.GetString("sbom.name"),
+			Version:         viper.GetString("sbom.version"),
+			Build:           viper.GetString("sbom.build"),
+			DyldSharedCache: viper.GetBool("sbom.dsc"),
+			OSS:             viper.GetBool("sbom.oss"),
+			OSSRelease:      viper.GetString("sbom.oss-release"),
+			Proxy:           viper.GetString("sbom.proxy"),
+			Insecure:        viper.GetBool("sbom.insecure"),
+		}
+
+		fi, err := os.Stat(args[0])
+		if err != nil {
+			return fmt.Errorf("failed to stat %s: %v", args[0], err)
+		}
+		if fi.IsDir() {
+			conf.Folder = filepath.Clean(args[0])
+		} else {
+			conf.IPSW = filepath.Clean(args[0])
+		}
+
+		log.Info("Scanning build for binaries")
+		bom, err := sbom.Generate(conf)
+		if err != nil {
+			return fmt.Errorf("failed to generate SBOM: %v", err)
+		}
+		log.WithFields(log.Fields{
+			"binaries": len(bom.Binaries),
+			"oss":      len(bom.OSS),
+		}).Info("Found")
+
+		var dat []byte
+		switch format {
+		case "spdx":
+			dat, err = bom.SPDX(strings.TrimSpace(AppVersion))
+		default:
+			dat, err = bom.CycloneDX(strings.TrimSpace(AppVersion))
+		}
+		if err != nil {
+			return fmt.Errorf("failed to encode SBOM: %v", err)
+		}
+
+		if out := viper.GetString("sbom.output"); len(out) > 0 {
+			if err := os.MkdirAll(filepath.Dir(out), 0750); err != nil {
+				return fmt.Errorf("failed to create directory %s: %v", filepath.Dir(out), err)
+			}
+			log.Infof("Writing SBOM to %s", out)
+			return os.WriteFile(out, dat, 0660)
+		}
+		fmt.Println(string(dat))
+		return nil
+	},
+}
@@ -0,0 +1,156 @@
+/*
+Copyright © 2024 blacktop
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/apex/log"
+	"github.com/blacktop/ipsw/api/rest"
+	"github.com/blacktop/ipsw/api/rpc"
+	"github.com/blacktop/ipsw/internal/download"
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+
+	serveCmd.Flags().Bool("grpc", false, "Serve the gRPC API (see api/rpc/ipswpb/ipsw.proto) instead of the REST API")
+	serveCmd.Flags().String("host", "localhost", "Host/IP to listen on (use 0.0.0.0 to listen on all interfaces)")
+	serveCmd.Flags().IntP("port", "p", 3995, "Port to listen on")
+	serveCmd.Flags().StringP("output", "o", "", "Folder to save the submitted downloads to")
+	serveCmd.Flags().Int("workers", 2, "Number of downloads to run at once")
+	serveCmd.Flags().String("proxy", "", "HTTP/HTTPS proxy")
+	serveCmd.Flags().Bool("insecure", false, "do not verify ssl certs")
+	serveCmd.MarkFlagDirname("output")
+	viper.BindPFlag("serve.grpc", serveCmd.Flags().Lookup("grpc"))
+	viper.BindPFlag("serve.host", serveCmd.Flags().Lookup("host"))
+	viper.BindPFlag("serve.port", serveCmd.Flags().Lookup("port"))
+	viper.BindPFlag("serve.output", serveCmd.Flags().Lookup("output"))
+	viper.BindPFlag("serve.workers", serveCmd.Flags().Lookup("workers"))
+	viper.BindPFlag("serve.proxy", serveCmd.Flags().Lookup("proxy"))
+	viper.BindPFlag("serve.insecure", serveCmd.Flags().Lookup("insecure"))
+}
+
+// serveCmd represents the serve command
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve the device, firmware and download APIs to other services",
+	Example: `  # Serve the REST API on localhost:3995 and save the downloads to /srv/ipsws
+  ❯ ipsw serve --output /srv/ipsws
+  # List the signed IPSWs of a device
+  ❯ curl 'localhost:3995/device/iPhone15,2/firmwares?signed=true'
+  # Queue the download of a device's build and check on its job
+  ❯ curl -X POST localhost:3995/downloads -d '{"identifier": "iPhone15,2", "build": "21A329"}'
+  ❯ curl localhost:3995/jobs/1
+  # Serve the gRPC API on localhost:3995 and save the downloads to /srv/ipsws
+  ❯ ipsw serve --grpc --output /srv/ipsws
+  # List the services with grpcurl (the server supports reflection)
+  ❯ grpcurl -plaintext localhost:3995 list
+  # Queue a download and watch its progress
+  ❯ grpcurl -plaintext -d '{"url": "https://updates.cdn-apple.com/...ipsw"}' localhost:3995 ipsw.v1.IPSW/SubmitDownload
+  ❯ grpcurl -plaintext -d '{"id": "1"}' localhost:3995 ipsw.v1.IPSW/WatchDownload`,
+	Args:          cobra.NoArgs,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if Verbose {
+			log.SetLevel(log.DebugLevel)
+		}
+
+		output := viper.GetString("serve.output")
+		if len(output) > 0 {
+			if err := os.MkdirAll(output, 0750); err != nil {
+				return fmt.Errorf("failed to create output folder %s: %v", output, err)
+			}
+		}
+
+		addr := net.JoinHostPort(viper.GetString("serve.host"), strconv.Itoa(viper.GetInt("serve.port")))
+		lis, err := net.Listen("tcp", addr)
+		if err != nil {
+			return fmt.Errorf("failed to listen on %s: %v", addr, err)
+		}
+
+		manager := download.NewManager(viper.GetInt("serve.workers"))
+		ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+		defer stop()
+
+		if viper.GetBool("serve.grpc") {
+			srv := rpc.NewGRPCServer(rpc.Config{
+				Output:   output,
+				Proxy:    viper.GetString("serve.proxy"),
+				Insecure: viper.GetBool("serve.insecure"),
+				Manager:  manager,
+			})
+			go func() {
+				<-ctx.Done()
+				log.Warn("Shutting down (canceling the running downloads)")
+				manager.Close()
+				srv.GracefulStop()
+			}()
+
+			log.Infof("Serving the gRPC API on %s", lis.Addr())
+			if err := srv.Serve(lis); err != nil {
+				return fmt.Errorf("gRPC server failed: %v", err)
+			}
+			return nil
+		}
+
+		if !Verbose {
+			gin.SetMode(gin.ReleaseMode)
+		}
+		srv := &http.Server{
+			Handler: rest.NewHandler(rest.Config{
+				Output:   output,
+				Proxy:    viper.GetString("serve.proxy"),
+				Insecure: viper.GetBool("serve.insecure"),
+				Manager:  manager,
+			}),
+			ReadHeaderTimeout: 10 * time.Second,
+		}
+		go func() {
+			<-ctx.Done()
+			log.Warn("Shutting down (canceling the running downloads)")
+			manager.Close()
+			sctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			srv.Shutdown(sctx)
+		}()
+
+		log.Infof("Serving the REST API on http://%s", lis.Addr())
+		if err := srv.Serve(lis); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			return fmt.Errorf("HTTP server failed: %v", err)
+		}
+		return nil
+	},
+}
@@ -0,0 +1,164 @@
+/*
+Copyright © 2023 blacktop
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/apex/log"
+	"github.com/blacktop/ipsw/internal/commands/sig"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func init() {
+	rootCmd.AddCommand(sigCmd)
+
+	sigCmd.Flags().StringP("id", "i", "", "Signing identifier to search for")
+	sigCmd.Flags().StringP("team", "t", "", "TeamID to search for")
+	sigCmd.Flags().StringP("cdhash", "c", "", "CDHash (prefix) to search for")
+	sigCmd.Flags().StringP("file", "f", "", "File path (substring) to search for")
+	sigCmd.Flags().StringP("output", "o", "", "Folder to r/w code signature databases")
+	sigCmd.MarkFlagDirname("output")
+	sigCmd.Flags().BoolP("diff", "d", false, "Diff code signatures")
+	sigCmd.Flags().BoolP("md", "m", false, "Markdown style output")
+	sigCmd.Flags().Bool("teams", false, "List all TeamIDs in IPSW")
+	sigCmd.Flags().Bool("json", false, "Output as JSON")
+	viper.BindPFlag("sig.id", sigCmd.Flags().Lookup("id"))
+	viper.BindPFlag("sig.team", sigCmd.Flags().Lookup("team"))
+	viper.BindPFlag("sig.cdhash", sigCmd.Flags().Lookup("cdhash"))
+	viper.BindPFlag("sig.file", sigCmd.Flags().Lookup("file"))
+	viper.BindPFlag("sig.output", sigCmd.Flags().Lookup("output"))
+	viper.BindPFlag("sig.diff", sigCmd.Flags().Lookup("diff"))
+	viper.BindPFlag("sig.md", sigCmd.Flags().Lookup("md"))
+	viper.BindPFlag("sig.teams", sigCmd.Flags().Lookup("teams"))
+	viper.BindPFlag("sig.json", sigCmd.Flags().Lookup("json"))
+}
+
+// sigCmd represents the sig command
+var sigCmd = &cobra.Command{
+	Use:          "sig <IPSW> [IPSW]",
+	Aliases:      []string{"sigs"},
+	Short:        "Inventory the code signatures (TeamID, identifier, cdhash) of all MachOs in an IPSW",
+	Args:         cobra.RangeArgs(1, 2),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+
+		if Verbose {
+			log.SetLevel(log.DebugLevel)
+		}
+		color.NoColor = !viper.GetBool("color")
+
+		asJSON := viper.GetBool("sig.json")
+
+		if viper.GetBool("sig.diff") && len(args) < 2 {
+			return fmt.Errorf("you must specify two IPSWs to diff")
+		}
+
+		ipswPath := filepath.Clean(args[0])
+
+		sigDB, err := sig.GetDatabase(ipswPath, sig.DBPath(ipswPath, viper.GetString("sig.output")))
+		if err != nil {
+			return fmt.Errorf("failed to get code signature database: %v", err)
+		}
+
+		if viper.GetBool("sig.diff") { // DIFF CODE SIGNATURES
+			ipswPath2 := filepath.Clean(args[1])
+			sigDB2, err := sig.GetDatabase(ipswPath2, sig.DBPath(ipswPath2, viper.GetString("sig.output")))
+			if err != nil {
+				return fmt.Errorf("failed to get code signature database: %v", err)
+			}
+			out, err := sig.DiffDatabases(sigDB, sigDB2, &sig.Config{
+				Markdown: viper.GetBool("sig.md"),
+				Color:    viper.GetBool("color"),
+			})
+			if err != nil {
+				return err
+			}
+			fmt.Println(out)
+			return nil
+		}
+
+		if viper.GetBool("sig.teams") { // LIST ALL TEAM IDS
+			teams := sigDB.TeamIDs()
+			if asJSON {
+				dat, err := json.Marshal(teams)
+				if err != nil {
+					return err
+				}
+				fmt.Println(string(dat))
+				return nil
+			}
+			var ids []string
+			for t := range teams {
+				ids = append(ids, t)
+			}
+			sort.Strings(ids)
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', 0)
+			for _, t := range ids {
+				fmt.Fprintf(w, "%s\t%d\n", colorKey(t), teams[t])
+			}
+			w.Flush()
+			return nil
+		}
+
+		results := sigDB.Query(sig.Query{
+			ID:     viper.GetString("sig.id"),
+			TeamID: viper.GetString("sig.team"),
+			CDHash: viper.GetString("sig.cdhash"),
+			File:   viper.GetString("sig.file"),
+		})
+
+		if asJSON {
+			dat, err := json.Marshal(results)
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(dat))
+			return nil
+		}
+
+		if len(results) == 0 {
+			log.Warn("No matching MachOs found")
+			return nil
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', 0)
+		for _, f := range results.Files() {
+			s := results[f]
+			if !s.Signed() {
+				fmt.Fprintf(w, "%s\t%s\n", colorBin(f), "unsigned")
+				continue
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", colorBin(f), colorKey(s.ID), colorValue(s.TeamID), s.CDHash)
+		}
+		w.Flush()
+
+		return nil
+	},
+}
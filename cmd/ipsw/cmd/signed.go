@@ -0,0 +1,246 @@
+/*
+Copyright © 2023 blacktop
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+	"time"
+
+	"github.com/apex/log"
+	"github.com/blacktop/ipsw/internal/db"
+	"github.com/blacktop/ipsw/internal/download"
+	"github.com/blacktop/ipsw/internal/models"
+	"github.com/blacktop/ipsw/internal/utils"
+	"github.com/blacktop/ipsw/pkg/tss"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func init() {
+	rootCmd.AddCommand(signedCmd)
+
+	signedCmd.Flags().Bool("history", false, "Show the recorded signing windows")
+	signedCmd.Flags().StringP("build", "b", "", "Only show the given build")
+	signedCmd.Flags().Bool("json", false, "Output as JSON")
+	signedCmd.Flags().String("as-of", "", "Show the builds that were signed at a past date (i.e. 2023-06-01)")
+	signedCmd.Flags().String("db", "", "Signing history database (default: ~/.ipsw/signing.db)")
+	signedCmd.Flags().String("proxy", "", "HTTP/HTTPS proxy")
+	signedCmd.Flags().Bool("insecure", false, "do not verify ssl certs")
+	viper.BindPFlag("signed.history", signedCmd.Flags().Lookup("history"))
+	viper.BindPFlag("signed.build", signedCmd.Flags().Lookup("build"))
+	viper.BindPFlag("signed.json", signedCmd.Flags().Lookup("json"))
+	viper.BindPFlag("signed.as-of", signedCmd.Flags().Lookup("as-of"))
+	viper.BindPFlag("signed.db", signedCmd.Flags().Lookup("db"))
+	viper.BindPFlag("signed.proxy", signedCmd.Flags().Lookup("proxy"))
+	viper.BindPFlag("signed.insecure", signedCmd.Flags().Lookup("insecure"))
+}
+
+// openSigningDB opens the signing history database (defaults to ~/.ipsw/signing.db)
+func openSigningDB(path string) (*db.SigningDB, error) {
+	if len(path) == 0 {
+		var err error
+		if path, err = db.DefaultSigningPath(); err != nil {
+			return nil, err
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return nil, fmt.Errorf("failed to create directory %s: %v", filepath.Dir(path), err)
+	}
+	return db.NewSigningDB(path)
+}
+
+// recentBuildAge is how long after their release builds are still TSS checked
+const recentBuildAge = 365 * 24 * time.Hour
+
+// checkSigning asks TSS whether the device's builds are signed and records the results.
+// The builds checked are the ones released within recentBuildAge (whatever ipsw.me says
+// about their signing status), the ones ipsw.me lists as signed and the ones with an open signing window.
+// It returns the statuses that changed since the last check.
+func checkSigning(sdb *db.SigningDB, device, proxy string, insecure bool) ([]*tss.SigningStatus, error) {
+	builds := make(map[string]bool)
+	ipsws, err := download.GetDeviceIPSWs(device)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s IPSWs: %v", device, err)
+	}
+	for _, i := range ipsws {
+		if i.Signed || time.Since(i.ReleaseDate) < recentBuildAge {
+			builds[i.BuildID] = true
+		}
+	}
+	open, err := sdb.Open(device)
+	if err != nil {
+		return nil, err
+	}
+	for _, w := range open {
+		builds[w.Build] = true
+	}
+
+	var changed []*tss.SigningStatus
+	for build := range builds {
+		status, err := tss.GetSigningStatus(device, build, proxy, insecure)
+		if err != nil {
+			log.WithFields(log.Fields{"device": device, "build": build}).Errorf("failed to check signing status: %v", err)
+			continue
+		}
+		ok, err := sdb.Record(device, status.Version, status.Build, status.Signed, time.Now())
+		if err != nil {
+			return nil, fmt.Errorf("failed to record signing status: %v", err)
+		}
+		if ok {
+			changed = append(changed, status)
+		}
+	}
+	return changed, nil
+}
+
+func printSigningHistory(windows []models.SigningWindow) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "DEVICE\tVERSION\tBUILD\tSIGNED\tUNSIGNED\tWINDOW")
+	for _, sw := range windows {
+		stop := "-"
+		window := time.Since(sw.Start)
+		if !sw.Open() {
+			stop = sw.Stop.Format(time.DateTime)
+			window = sw.Stop.Sub(sw.Start)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", sw.Device, sw.Version, sw.Build, sw.Start.Format(time.DateTime), stop, window.Round(time.Hour))
+	}
+	w.Flush()
+}
+
+// signedCmd represents the signed command
+var signedCmd = &cobra.Command{
+	Use:   "signed [DEVICE...]",
+	Short: "Check which builds Apple is signing (and when they were signed)",
+	Example: `  # Check which builds are signed for the iPhone15,2 (and record them)
+  ❯ ipsw signed iPhone15,2
+  # Show when the iPhone15,2 builds were signed
+  ❯ ipsw signed --history iPhone15,2
+  # Show which builds were signed for the iPhone12,1 on June 1st 2023
+  ❯ ipsw signed --as-of 2023-06-01 iPhone12,1
+  # Record signing windows every hour
+  ❯ ipsw watch --tss --device iPhone15,2 --device iPhone16,1 --timeout 1h`,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+
+		if Verbose {
+			log.SetLevel(log.DebugLevel)
+		}
+
+		sdb, err := openSigningDB(viper.GetString("signed.db"))
+		if err != nil {
+			return err
+		}
+		defer sdb.Close()
+
+		if viper.GetBool("signed.history") {
+			devices := args
+			if len(devices) == 0 {
+				devices = []string{""}
+			}
+			var windows []models.SigningWindow
+			for _, device := range devices {
+				ws, err := sdb.History(device, viper.GetString("signed.build"))
+				if err != nil {
+					return fmt.Errorf("failed to get signing history: %v", err)
+				}
+				windows = append(windows, ws...)
+			}
+			if viper.GetBool("signed.json") {
+				return json.NewEncoder(os.Stdout).Encode(windows)
+			}
+			if len(windows) == 0 {
+				log.Warn("No signing history recorded (use `ipsw watch --tss` to record it)")
+				return nil
+			}
+			printSigningHistory(windows)
+			return nil
+		}
+
+		if asOf := viper.GetString("signed.as-of"); len(asOf) > 0 {
+			at, err := db.ParseAsOf(asOf)
+			if err != nil {
+				return err
+			}
+			devices := args
+			if len(devices) == 0 {
+				devices = []string{""}
+			}
+			var windows []models.SigningWindow
+			for _, device := range devices {
+				ws, err := sdb.AsOf(device, at)
+				if err != nil {
+					return fmt.Errorf("failed to get signing history: %v", err)
+				}
+				for _, w := range ws {
+					if len(viper.GetString("signed.build")) > 0 && w.Build != viper.GetString("signed.build") {
+						continue
+					}
+					windows = append(windows, w)
+				}
+			}
+			if viper.GetBool("signed.json") {
+				return json.NewEncoder(os.Stdout).Encode(windows)
+			}
+			if len(windows) == 0 {
+				log.Warnf("No builds were recorded as signed on %s (use `ipsw watch --tss` to record signing windows)", at.Format(time.DateTime))
+				return nil
+			}
+			printSigningHistory(windows)
+			return nil
+		}
+
+		if len(args) == 0 {
+			return fmt.Errorf("you must supply a DEVICE (or use --history)")
+		}
+
+		for _, device := range args {
+			if _, err := checkSigning(sdb, device, viper.GetString("signed.proxy"), viper.GetBool("signed.insecure")); err != nil {
+				return err
+			}
+			windows, err := sdb.Open(device)
+			if err != nil {
+				return err
+			}
+			if viper.GetBool("signed.json") {
+				if err := json.NewEncoder(os.Stdout).Encode(windows); err != nil {
+					return err
+				}
+				continue
+			}
+			log.WithField("device", device).Info("Signed")
+			for _, sw := range windows {
+				if len(viper.GetString("signed.build")) > 0 && sw.Build != viper.GetString("signed.build") {
+					continue
+				}
+				utils.Indent(log.WithField("since", sw.Start.Format(time.DateTime)).Info, 2)(fmt.Sprintf("%s (%s)", sw.Version, sw.Build))
+			}
+		}
+
+		return nil
+	},
+}
@@ -117,9 +117,12 @@ var sshDebugserverCmd = &cobra.Command{
 					Message: "Select the DeveloperDiskImage you want to extract the debugserver from:",
 					Options: images,
 				}
-				if err := survey.AskOne(prompt, &choice); err == terminal.InterruptErr {
-					log.Warn("Exiting...")
-					return nil
+				if err := utils.AskOne(prompt, &choice); err != nil {
+					if err == terminal.InterruptErr {
+						log.Warn("Exiting...")
+						return nil
+					}
+					return err
 				}
 				imagePath = images[choice]
 			}
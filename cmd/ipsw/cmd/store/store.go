@@ -0,0 +1,95 @@
+/*
+Copyright © 2023 blacktop
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package store
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/blacktop/ipsw/internal/db"
+	"github.com/blacktop/ipsw/internal/download"
+	"github.com/blacktop/ipsw/internal/models"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// StoreCmd represents the store command
+var StoreCmd = &cobra.Command{
+	Use:   "store",
+	Short: "Tag and annotate the firmware in the local store",
+	Args:  cobra.NoArgs,
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		viper.BindPFlag("color", cmd.Flags().Lookup("color"))
+		viper.BindPFlag("verbose", cmd.Flags().Lookup("verbose"))
+		viper.BindPFlag("diff-tool", cmd.Flags().Lookup("diff-tool"))
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+	},
+}
+
+// StorePath returns the local store database path (the `store-db` config key or ~/.ipsw/store.db)
+func StorePath() (string, error) {
+	if path := viper.GetString("store-db"); len(path) > 0 {
+		return path, nil
+	}
+	return db.DefaultStorePath()
+}
+
+// OpenStoreDB opens (or creates) the local store database
+func OpenStoreDB() (*db.StoreDB, error) {
+	path, err := StorePath()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return nil, fmt.Errorf("failed to create directory %s: %v", filepath.Dir(path), err)
+	}
+	return db.NewStoreDB(path)
+}
+
+// storedFirmware returns the stored firmware of a file (adding it to the store if needed)
+func storedFirmware(sdb *db.StoreDB, file string) (*models.StoredFirmware, error) {
+	path, err := filepath.Abs(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get absolute path of %s: %v", file, err)
+	}
+	if fw, err := sdb.Get(path); err != nil {
+		return nil, fmt.Errorf("failed to query store DB: %v", err)
+	} else if fw != nil {
+		return fw, nil
+	}
+	if _, err := os.Stat(path); err != nil {
+		return nil, fmt.Errorf("%s is not in the store: %v", file, err)
+	}
+	device, version, build := download.ParseIpswURLString(path)
+	if err := sdb.Add(&models.StoredFirmware{
+		Path:    path,
+		Device:  device,
+		Version: version,
+		Build:   build,
+	}); err != nil {
+		return nil, fmt.Errorf("failed to add %s to the store: %v", file, err)
+	}
+	return sdb.Get(path)
+}
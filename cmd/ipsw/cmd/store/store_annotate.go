@@ -0,0 +1,69 @@
+/*
+Copyright © 2023 blacktop
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package store
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func init() {
+	StoreCmd.AddCommand(storeAnnotateCmd)
+}
+
+// storeAnnotateCmd represents the store annotate command
+var storeAnnotateCmd = &cobra.Command{
+	Use:           "annotate <FIRMWARE> [NOTES]",
+	Short:         "Attach notes to a firmware file in the local store (no notes clears them)",
+	Example:       `  ❯ ipsw store annotate iPhone15,2_17.0_21A329_Restore.ipsw "baseline for project X"`,
+	Args:          cobra.MinimumNArgs(1),
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+
+		if viper.GetBool("verbose") {
+			log.SetLevel(log.DebugLevel)
+		}
+
+		sdb, err := OpenStoreDB()
+		if err != nil {
+			return fmt.Errorf("failed to open store DB: %v", err)
+		}
+		defer sdb.Close()
+
+		fw, err := storedFirmware(sdb, args[0])
+		if err != nil {
+			return err
+		}
+
+		if err := sdb.Annotate(fw, strings.Join(args[1:], " ")); err != nil {
+			return fmt.Errorf("failed to annotate %s: %v", args[0], err)
+		}
+		log.Infof("Annotated %s", fw.Path)
+
+		return nil
+	},
+}
@@ -0,0 +1,91 @@
+/*
+Copyright © 2023 blacktop
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/apex/log"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func init() {
+	StoreCmd.AddCommand(storeLsCmd)
+
+	storeLsCmd.Flags().StringArrayP("tag", "t", []string{}, "Only list firmware with the tag (can be repeated)")
+	storeLsCmd.Flags().Bool("json", false, "Output as JSON")
+	viper.BindPFlag("store.ls.tag", storeLsCmd.Flags().Lookup("tag"))
+	viper.BindPFlag("store.ls.json", storeLsCmd.Flags().Lookup("json"))
+}
+
+// storeLsCmd represents the store ls command
+var storeLsCmd = &cobra.Command{
+	Use:     "ls",
+	Aliases: []string{"list"},
+	Short:   "List the tagged and annotated firmware in the local store",
+	Example: `  # List the firmware tagged with both project-x AND baseline
+  ❯ ipsw store ls --tag project-x --tag baseline`,
+	Args:          cobra.NoArgs,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+
+		if viper.GetBool("verbose") {
+			log.SetLevel(log.DebugLevel)
+		}
+
+		sdb, err := OpenStoreDB()
+		if err != nil {
+			return fmt.Errorf("failed to open store DB: %v", err)
+		}
+		defer sdb.Close()
+
+		fws, err := sdb.List(viper.GetStringSlice("store.ls.tag"))
+		if err != nil {
+			return fmt.Errorf("failed to query store DB: %v", err)
+		}
+
+		if viper.GetBool("store.ls.json") {
+			return json.NewEncoder(os.Stdout).Encode(fws)
+		}
+
+		if len(fws) == 0 {
+			log.Warn("No firmware found")
+			return nil
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "FILE\tDEVICE\tVERSION\tBUILD\tTAGS\tNOTES")
+		for _, fw := range fws {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", filepath.Base(fw.Path), fw.Device, fw.Version, fw.Build, strings.Join(fw.TagNames(), ","), fw.Notes)
+		}
+		w.Flush()
+
+		return nil
+	},
+}
@@ -0,0 +1,92 @@
+/*
+Copyright © 2023 blacktop
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package store
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/apex/log"
+	"github.com/blacktop/ipsw/internal/commands/store"
+	"github.com/blacktop/ipsw/internal/utils"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func init() {
+	StoreCmd.AddCommand(storePublishCmd)
+
+	storePublishCmd.Flags().String("base-url", "", "URL the firmware files are served from (links are relative to the output folder by default)")
+	storePublishCmd.Flags().String("title", "", "Title of the index page")
+	storePublishCmd.Flags().StringArrayP("tag", "t", []string{}, "Only publish firmware with the tag (can be repeated)")
+	viper.BindPFlag("store.publish.base-url", storePublishCmd.Flags().Lookup("base-url"))
+	viper.BindPFlag("store.publish.title", storePublishCmd.Flags().Lookup("title"))
+	viper.BindPFlag("store.publish.tag", storePublishCmd.Flags().Lookup("tag"))
+}
+
+// storePublishCmd represents the store publish command
+var storePublishCmd = &cobra.Command{
+	Use:   "publish <FOLDER>",
+	Short: "Generate a static JSON/HTML index of the local store",
+	Long: `Generate a browsable static index (index.json and index.html) of the firmware in the
+local store that can be served from any web server as a team-internal mirror listing.`,
+	Example: `  # Publish the store next to the mirrored firmware
+  ❯ ipsw store publish /srv/ipsw
+
+  # Publish the project's firmware linking to a file server
+  ❯ ipsw store publish /var/www/html --tag project-x --base-url https://mirror.internal/ipsw`,
+	Args:          cobra.ExactArgs(1),
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+
+		if viper.GetBool("verbose") {
+			log.SetLevel(log.DebugLevel)
+		}
+
+		sdb, err := OpenStoreDB()
+		if err != nil {
+			return fmt.Errorf("failed to open store DB: %v", err)
+		}
+		defer sdb.Close()
+
+		fws, err := sdb.List(viper.GetStringSlice("store.publish.tag"))
+		if err != nil {
+			return fmt.Errorf("failed to query store DB: %v", err)
+		}
+
+		idx, err := store.Publish(fws, &store.PublishConfig{
+			Output:  args[0],
+			BaseURL: viper.GetString("store.publish.base-url"),
+			Title:   viper.GetString("store.publish.title"),
+		})
+		if err != nil {
+			return err
+		}
+
+		log.Infof("Published %d files", len(idx.Firmware))
+		utils.Indent(log.Info, 2)("Created: " + filepath.Join(args[0], "index.json"))
+		utils.Indent(log.Info, 2)("Created: " + filepath.Join(args[0], "index.html"))
+
+		return nil
+	},
+}
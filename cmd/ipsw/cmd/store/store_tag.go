@@ -0,0 +1,83 @@
+/*
+Copyright © 2023 blacktop
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package store
+
+import (
+	"fmt"
+
+	"github.com/apex/log"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func init() {
+	StoreCmd.AddCommand(storeTagCmd)
+
+	storeTagCmd.Flags().BoolP("remove", "r", false, "Remove the tags instead")
+	viper.BindPFlag("store.tag.remove", storeTagCmd.Flags().Lookup("remove"))
+}
+
+// storeTagCmd represents the store tag command
+var storeTagCmd = &cobra.Command{
+	Use:   "tag <FIRMWARE> <TAG>...",
+	Short: "Tag a firmware file in the local store",
+	Example: `  # Mark the IPSW as the baseline of a project
+  ❯ ipsw store tag iPhone15,2_17.0_21A329_Restore.ipsw project-x baseline
+
+  # List the project's firmware
+  ❯ ipsw store ls --tag project-x`,
+	Args:          cobra.MinimumNArgs(2),
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+
+		if viper.GetBool("verbose") {
+			log.SetLevel(log.DebugLevel)
+		}
+
+		sdb, err := OpenStoreDB()
+		if err != nil {
+			return fmt.Errorf("failed to open store DB: %v", err)
+		}
+		defer sdb.Close()
+
+		fw, err := storedFirmware(sdb, args[0])
+		if err != nil {
+			return err
+		}
+
+		if viper.GetBool("store.tag.remove") {
+			if err := sdb.Untag(fw, args[1:]...); err != nil {
+				return fmt.Errorf("failed to untag %s: %v", args[0], err)
+			}
+			log.Infof("Untagged %s", fw.Path)
+			return nil
+		}
+
+		if err := sdb.Tag(fw, args[1:]...); err != nil {
+			return fmt.Errorf("failed to tag %s: %v", args[0], err)
+		}
+		log.Infof("Tagged %s", fw.Path)
+
+		return nil
+	},
+}
@@ -0,0 +1,103 @@
+/*
+Copyright © 2023 blacktop
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package tss
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/apex/log"
+	"github.com/blacktop/ipsw/internal/download"
+	"github.com/blacktop/ipsw/pkg/tss"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func init() {
+	TssCmd.AddCommand(tssStatusCmd)
+
+	tssStatusCmd.Flags().StringP("device", "d", "", "iOS Device (i.e. iPhone15,2)")
+	tssStatusCmd.Flags().StringP("build", "b", "", "iOS BuildID (i.e. 21A329)")
+	tssStatusCmd.Flags().StringP("version", "v", "", "iOS Version (i.e. 17.0)")
+	tssStatusCmd.Flags().String("proxy", "", "HTTP/HTTPS proxy")
+	tssStatusCmd.Flags().Bool("insecure", false, "do not verify ssl certs")
+	tssStatusCmd.Flags().Bool("json", false, "Output as JSON")
+	tssStatusCmd.MarkFlagRequired("device")
+	tssStatusCmd.MarkFlagsMutuallyExclusive("build", "version")
+	viper.BindPFlag("tss.status.device", tssStatusCmd.Flags().Lookup("device"))
+	viper.BindPFlag("tss.status.build", tssStatusCmd.Flags().Lookup("build"))
+	viper.BindPFlag("tss.status.version", tssStatusCmd.Flags().Lookup("version"))
+	viper.BindPFlag("tss.status.proxy", tssStatusCmd.Flags().Lookup("proxy"))
+	viper.BindPFlag("tss.status.insecure", tssStatusCmd.Flags().Lookup("insecure"))
+	viper.BindPFlag("tss.status.json", tssStatusCmd.Flags().Lookup("json"))
+}
+
+// tssStatusCmd represents the tss status command
+var tssStatusCmd = &cobra.Command{
+	Use:     "status",
+	Aliases: []string{"s", "signed"},
+	Short:   "Check if Apple is still signing a build",
+	Example: `  # Check if iOS 17.0 (21A329) is still being signed for the iPhone 14 Pro
+  ❯ ipsw tss status --device iPhone15,2 --build 21A329`,
+	Args:          cobra.NoArgs,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+
+		if viper.GetBool("verbose") {
+			log.SetLevel(log.DebugLevel)
+		}
+
+		device := viper.GetString("tss.status.device")
+		build := viper.GetString("tss.status.build")
+		version := viper.GetString("tss.status.version")
+
+		if len(build) == 0 {
+			if len(version) == 0 {
+				return fmt.Errorf("must specify either --build or --version")
+			}
+			var err error
+			build, err = download.GetBuildID(version, device)
+			if err != nil {
+				return fmt.Errorf("failed to get build for %s %s: %v", device, version, err)
+			}
+		}
+
+		status, err := tss.GetSigningStatus(device, build, viper.GetString("tss.status.proxy"), viper.GetBool("tss.status.insecure"))
+		if err != nil {
+			return err
+		}
+
+		if viper.GetBool("tss.status.json") {
+			return json.NewEncoder(os.Stdout).Encode(status)
+		}
+
+		if status.Signed {
+			log.Infof("✅ %s (%s) is still being signed for %s", status.Version, status.Build, status.Device)
+		} else {
+			log.Errorf("🔥 %s (%s) is NO LONGER being signed for %s: %s", status.Version, status.Build, status.Device, status.Message)
+		}
+
+		return nil
+	},
+}
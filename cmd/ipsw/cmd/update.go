@@ -34,7 +34,6 @@ import (
 	"github.com/apex/log"
 	"github.com/blacktop/ipsw/internal/download"
 	"github.com/blacktop/ipsw/internal/utils"
-	"github.com/dustin/go-humanize"
 	"github.com/hashicorp/go-version"
 	"github.com/spf13/cobra"
 )
@@ -160,7 +159,9 @@ var updateCmd = &cobra.Command{
 					Message: "Select the file you would like to download:",
 					Options: assetFiles,
 				}
-				survey.AskOne(prompt, &choice)
+				if err := utils.AskOne(prompt, &choice); err != nil {
+					return err
+				}
 				asset = latestRelease.Assets[choice]
 			} else {
 				return fmt.Errorf("release %s contained 0 assets", latestRelease.Tag)
@@ -185,8 +186,8 @@ var updateCmd = &cobra.Command{
 		if _, err := os.Stat(fname); os.IsNotExist(err) {
 			log.WithFields(log.Fields{
 				"version":        latestRelease.Tag,
-				"published_at":   latestRelease.PublishedAt.Format("02Jan2006 15:04:05"),
-				"size":           humanize.Bytes(uint64(asset.Size)),
+				"published_at":   utils.FormatTime(latestRelease.PublishedAt),
+				"size":           utils.FormatBytes(uint64(asset.Size)),
 				"download_count": asset.DownloadCount,
 			}).Info("Getting Update")
 			// download file
@@ -42,6 +42,7 @@ func init() {
 	updateDBCmd.Flags().StringP("urls", "u", "", "Path to file containing list of URLs to scan (one per line)")
 	updateDBCmd.Flags().StringP("remote", "r", "", "Remote IPSW/OTA URL to parse")
 	updateDBCmd.Flags().StringP("db", "d", "", "Path to ipsw device DB JSON")
+	updateDBCmd.Flags().BoolP("models", "m", false, "Merge model numbers (A-numbers, order numbers) from theapplewiki.com into DB")
 }
 
 // updateDBCmd represents the updatedb command
@@ -61,6 +62,7 @@ var updateDBCmd = &cobra.Command{
 		urlList, _ := cmd.Flags().GetString("urls")
 		remoteURL, _ := cmd.Flags().GetString("remote")
 		dbPath, _ := cmd.Flags().GetString("db")
+		addModels, _ := cmd.Flags().GetBool("models")
 
 		mut := "Creating"
 		if _, err := os.Stat(dbPath); err == nil {
@@ -152,6 +154,12 @@ var updateDBCmd = &cobra.Command{
 					log.WithError(err).Fatal("failed to get devices")
 				}
 			}
+		} else if addModels {
+			models, err := download.GetWikiModels("", false)
+			if err != nil {
+				log.WithError(err).Fatal("failed to get models")
+			}
+			devices.AddModels(models)
 		} else { // TODO: add default "latest" URL streams here to collect new devices
 			itunes, err := download.NewMacOsXML()
 			if err != nil {
@@ -173,6 +181,8 @@ var updateDBCmd = &cobra.Command{
 			}
 		}
 
+		devices.UpdateTypes()
+
 		// OUTPUT JSON
 		dat, err := json.Marshal(devices)
 		if err != nil {
@@ -24,14 +24,21 @@ package cmd
 import (
 	"encoding/json"
 	"fmt"
+	"net"
+	"net/http"
 	"os"
+	"os/signal"
+	"path/filepath"
 	"regexp"
 	"strings"
+	"syscall"
 	"time"
 
 	"github.com/apex/log"
 	"github.com/blacktop/ipsw/internal/commands/watch"
+	"github.com/blacktop/ipsw/internal/db"
 	"github.com/blacktop/ipsw/internal/download"
+	"github.com/blacktop/ipsw/internal/utils"
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -69,6 +76,23 @@ func init() {
 	watchCmd.Flags().DurationP("timeout", "t", 0, "Timeout for watch attempts (default: 0s = no timeout/run once)")
 	watchCmd.Flags().String("discord-id", "", "Discord Webhook ID")
 	watchCmd.Flags().String("discord-token", "", "Discord Webhook Token")
+	watchCmd.Flags().Bool("catalog", false, "Watch the mesu MobileAsset catalogs and pallas for new assets and asset types")
+	watchCmd.Flags().StringSlice("asset-type", download.MesuAssetTypes, "MobileAsset types to watch with --catalog")
+	watchCmd.Flags().StringSlice("pallas-platform", []string{"ios", "macos"}, "Platforms to query the pallas OTAs of with --catalog")
+	watchCmd.Flags().String("state", "", "Catalog snapshot file to diff against between polls (default: ~/.ipsw/catalogs.json)")
+	watchCmd.Flags().Bool("tss", false, "Watch the TSS signing status of --device(s) builds and record the signing windows")
+	watchCmd.Flags().StringArray("device", []string{}, "Device(s) to watch with --tss (i.e. iPhone15,2)")
+	watchCmd.Flags().String("db", "", "Signing history database for --tss (default: ~/.ipsw/signing.db)")
+	watchCmd.Flags().String("webhook", "", "Listen on address (i.e. :3994) for new build events POSTed to /webhook (localhost only without --webhook-secret)")
+	watchCmd.Flags().String("webhook-secret", "", "Shared secret webhook events must be signed with (or send as a Bearer token)")
+	watchCmd.Flags().Bool("download", false, "Download the IPSW of new builds (found with --tss or --webhook)")
+	watchCmd.Flags().StringSlice("extract", []string{}, "Extract from the IPSW of new builds (kernel, dyld)")
+	watchCmd.Flags().StringP("output", "o", "", "Folder to download/extract new builds to")
+	watchCmd.Flags().Bool("daemon", false, "Mirror new builds matching the config's watch.daemon.rules (or --device(s)) to --output")
+	watchCmd.Flags().StringSlice("source", watch.MirrorSources, "Sources to poll for new builds with --daemon")
+	watchCmd.Flags().String("mirror-db", "", "Mirror state database for --daemon (default: ~/.ipsw/mirror.db)")
+	watchCmd.Flags().Bool("backfill", false, "Also mirror the builds released before the --daemon first ran")
+	watchCmd.MarkFlagDirname("output")
 	viper.BindPFlag("watch.branch", watchCmd.Flags().Lookup("branch"))
 	viper.BindPFlag("watch.file", watchCmd.Flags().Lookup("file"))
 	viper.BindPFlag("watch.pattern", watchCmd.Flags().Lookup("pattern"))
@@ -78,6 +102,360 @@ func init() {
 	viper.BindPFlag("watch.timeout", watchCmd.Flags().Lookup("timeout"))
 	viper.BindPFlag("watch.discord-id", watchCmd.Flags().Lookup("discord-id"))
 	viper.BindPFlag("watch.discord-token", watchCmd.Flags().Lookup("discord-token"))
+	viper.BindPFlag("watch.catalog", watchCmd.Flags().Lookup("catalog"))
+	viper.BindPFlag("watch.asset-type", watchCmd.Flags().Lookup("asset-type"))
+	viper.BindPFlag("watch.pallas-platform", watchCmd.Flags().Lookup("pallas-platform"))
+	viper.BindPFlag("watch.state", watchCmd.Flags().Lookup("state"))
+	viper.BindPFlag("watch.tss", watchCmd.Flags().Lookup("tss"))
+	viper.BindPFlag("watch.device", watchCmd.Flags().Lookup("device"))
+	viper.BindPFlag("watch.db", watchCmd.Flags().Lookup("db"))
+	viper.BindPFlag("watch.webhook", watchCmd.Flags().Lookup("webhook"))
+	viper.BindPFlag("watch.webhook-secret", watchCmd.Flags().Lookup("webhook-secret"))
+	viper.BindPFlag("watch.download", watchCmd.Flags().Lookup("download"))
+	viper.BindPFlag("watch.extract", watchCmd.Flags().Lookup("extract"))
+	viper.BindPFlag("watch.output", watchCmd.Flags().Lookup("output"))
+	viper.BindPFlag("watch.daemon.enabled", watchCmd.Flags().Lookup("daemon"))
+	viper.BindPFlag("watch.daemon.sources", watchCmd.Flags().Lookup("source"))
+	viper.BindPFlag("watch.daemon.db", watchCmd.Flags().Lookup("mirror-db"))
+	viper.BindPFlag("watch.daemon.backfill", watchCmd.Flags().Lookup("backfill"))
+}
+
+// newBuildPipeline returns the pipeline to run on new builds (nil if --download/--extract were not set)
+func newBuildPipeline() *watch.Pipeline {
+	if !viper.GetBool("watch.download") && len(viper.GetStringSlice("watch.extract")) == 0 {
+		return nil
+	}
+	return &watch.Pipeline{
+		Output:  viper.GetString("watch.output"),
+		Extract: viper.GetStringSlice("watch.extract"),
+	}
+}
+
+// runBuildPipeline runs the pipeline on a new build and reports the result
+func runBuildPipeline(pipeline *watch.Pipeline, ev watch.Event, announce, asJSON bool) error {
+	res, err := pipeline.Run(ev)
+	if err != nil {
+		return err
+	}
+	msg := fmt.Sprintf("Downloaded %s to %s", res.Event, res.IPSW)
+	if len(res.Extracted) > 0 {
+		msg += fmt.Sprintf(" (extracted %s)", strings.Join(res.Extracted, ", "))
+	}
+	if announce {
+		if err := watch.DiscordAnnounce(msg, &watch.Config{
+			DiscordWebhookID:    viper.GetString("watch.discord-id"),
+			DiscordWebhookToken: viper.GetString("watch.discord-token"),
+			DiscordColor:        "4535172",
+			DiscordAuthor:       "ipsw watch",
+			DiscordIconURL:      "https://raw.githubusercontent.com/blacktop/ipsw/master/www/static/img/logo/ipsw@3x.png",
+		}); err != nil {
+			return fmt.Errorf("discord announce failed: %v", err)
+		}
+	} else if asJSON {
+		json.NewEncoder(os.Stdout).Encode(res)
+	} else {
+		log.Info(msg)
+	}
+	return nil
+}
+
+func watchWebhook(announce, asJSON bool) error {
+	pipeline := newBuildPipeline()
+	if pipeline == nil {
+		pipeline = &watch.Pipeline{Output: viper.GetString("watch.output")}
+	}
+
+	receiver := watch.NewReceiver(viper.GetString("watch.webhook-secret"), 100)
+
+	addr := viper.GetString("watch.webhook")
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return fmt.Errorf("invalid --webhook address '%s': %v", addr, err)
+	}
+	if len(receiver.Secret) == 0 { // anyone who can reach the webhook could trigger downloads
+		if len(host) == 0 {
+			addr = net.JoinHostPort("127.0.0.1", port)
+		} else if ip := net.ParseIP(host); host != "localhost" && (ip == nil || !ip.IsLoopback()) {
+			return fmt.Errorf("a --webhook-secret is required to listen on %s (without one the webhook only listens on localhost)", addr)
+		}
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/webhook", receiver)
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		log.Infof("Listening for new build events on http://%s/webhook", srv.Addr)
+		errCh <- srv.ListenAndServe()
+	}()
+
+	for {
+		select {
+		case err := <-errCh:
+			return fmt.Errorf("webhook server failed: %v", err)
+		case ev := <-receiver.Events:
+			if err := runBuildPipeline(pipeline, ev, announce, asJSON); err != nil {
+				log.Errorf("failed to process %s: %v", ev, err)
+			}
+		}
+	}
+}
+
+func watchSigning(announce, asJSON bool) error {
+	devices := viper.GetStringSlice("watch.device")
+	if len(devices) == 0 {
+		return fmt.Errorf("you must supply at least one --device to watch with --tss")
+	}
+
+	sdb, err := openSigningDB(viper.GetString("watch.db"))
+	if err != nil {
+		return err
+	}
+	defer sdb.Close()
+
+	pipeline := newBuildPipeline()
+
+	for {
+		for _, device := range devices {
+			changed, err := checkSigning(sdb, device, "", false)
+			if err != nil {
+				log.Errorf("failed to check %s signing status: %v", device, err)
+				continue
+			}
+			for _, status := range changed {
+				msg := fmt.Sprintf("%s %s (%s) is no longer signed", status.Device, status.Version, status.Build)
+				if status.Signed {
+					msg = fmt.Sprintf("%s %s (%s) is now signed", status.Device, status.Version, status.Build)
+				}
+				if announce {
+					if err := watch.DiscordAnnounce(msg, &watch.Config{
+						DiscordWebhookID:    viper.GetString("watch.discord-id"),
+						DiscordWebhookToken: viper.GetString("watch.discord-token"),
+						DiscordColor:        "4535172",
+						DiscordAuthor:       "gs.apple.com",
+						DiscordIconURL:      "https://raw.githubusercontent.com/blacktop/ipsw/master/www/static/img/logo/ipsw@3x.png",
+					}); err != nil {
+						return fmt.Errorf("discord announce failed: %v", err)
+					}
+				} else if asJSON {
+					json.NewEncoder(os.Stdout).Encode(status)
+				} else {
+					fmt.Println(msg)
+				}
+				if pipeline != nil && status.Signed {
+					if err := runBuildPipeline(pipeline, watch.Event{
+						Device:  status.Device,
+						Version: status.Version,
+						Build:   status.Build,
+						Source:  "gs.apple.com",
+					}, announce, asJSON); err != nil {
+						log.Errorf("failed to process %s (%s): %v", status.Device, status.Build, err)
+					}
+				}
+			}
+		}
+
+		if viper.GetDuration("watch.timeout") == 0 { // if timeout is 0 then just run once
+			break
+		}
+
+		time.Sleep(viper.GetDuration("watch.timeout"))
+	}
+
+	return nil
+}
+
+func watchCatalogs(announce, asJSON bool) error {
+	statePath := viper.GetString("watch.state")
+	if len(statePath) == 0 {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return fmt.Errorf("failed to get user home directory: %v", err)
+		}
+		statePath = filepath.Join(home, ".ipsw", "catalogs.json")
+	}
+
+	prev, err := watch.LoadCatalogSnapshot(statePath)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			return err
+		}
+		log.Info("No previous catalog snapshot found (will only report changes from now on)")
+	}
+
+	for {
+		snap, err := watch.GetCatalogSnapshot(
+			viper.GetStringSlice("watch.asset-type"),
+			viper.GetStringSlice("watch.pallas-platform"),
+			"", false)
+		if err != nil {
+			if viper.GetDuration("watch.timeout") == 0 {
+				return err
+			}
+			log.Errorf("failed to get catalog snapshot: %v", err)
+		} else if prev != nil {
+			diff := watch.DiffCatalogSnapshots(prev, snap)
+			if !diff.Empty() {
+				if announce {
+					msg := diff.String()
+					if len(msg) > 4000 { // discord embed description limit is 4096
+						msg = msg[:4000]
+						if i := strings.LastIndexByte(msg, '\n'); i > 0 { // trim on a line boundary
+							msg = msg[:i]
+						} else { // or at least a rune one
+							msg = strings.ToValidUTF8(msg, "")
+						}
+						msg += "\n..."
+					}
+					if err := watch.DiscordAnnounce(msg, &watch.Config{
+						DiscordWebhookID:    viper.GetString("watch.discord-id"),
+						DiscordWebhookToken: viper.GetString("watch.discord-token"),
+						DiscordColor:        "4535172",
+						DiscordAuthor:       "mesu.apple.com",
+						DiscordIconURL:      "https://raw.githubusercontent.com/blacktop/ipsw/master/www/static/img/logo/ipsw@3x.png",
+					}); err != nil {
+						return fmt.Errorf("discord announce failed: %v", err)
+					}
+				} else if asJSON {
+					json.NewEncoder(os.Stdout).Encode(diff)
+				} else {
+					fmt.Println(diff)
+				}
+			} else {
+				log.Debug("No catalog changes found")
+			}
+		}
+
+		if snap != nil {
+			if err := snap.Save(statePath); err != nil {
+				return fmt.Errorf("failed to save catalog snapshot: %v", err)
+			}
+			prev = snap
+		}
+
+		if viper.GetDuration("watch.timeout") == 0 { // if timeout is 0 then just run once
+			break
+		}
+
+		time.Sleep(viper.GetDuration("watch.timeout"))
+	}
+
+	return nil
+}
+
+// openMirrorDB opens the mirror state database (defaults to ~/.ipsw/mirror.db)
+func openMirrorDB(path string) (*db.MirrorDB, error) {
+	if len(path) == 0 {
+		var err error
+		if path, err = db.DefaultMirrorPath(); err != nil {
+			return nil, err
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return nil, fmt.Errorf("failed to create directory %s: %v", filepath.Dir(path), err)
+	}
+	return db.NewMirrorDB(path)
+}
+
+// watchDaemon mirrors the new builds matching the rules of the config file i.e.
+//
+//	watch:
+//	  output: /srv/ipsw
+//	  timeout: 30m
+//	  daemon:
+//	    rules:
+//	      - device: iPhone15,2
+//	        version: "17"
+//	      - device: watchos
+//	        signed: true
+func watchDaemon(announce, asJSON bool) error {
+	var rules []watch.Rule
+	if err := viper.UnmarshalKey("watch.daemon.rules", &rules); err != nil {
+		return fmt.Errorf("failed to parse watch.daemon.rules: %v", err)
+	}
+	for _, device := range viper.GetStringSlice("watch.device") {
+		rules = append(rules, watch.Rule{Device: device})
+	}
+	if len(rules) == 0 {
+		return fmt.Errorf("you must supply --device(s) or watch.daemon.rules in the config file to watch with --daemon")
+	}
+	if len(viper.GetString("watch.output")) == 0 {
+		return fmt.Errorf("you must supply the --output mirror folder to watch with --daemon")
+	}
+	interval := viper.GetDuration("watch.timeout")
+	if interval == 0 {
+		interval = time.Hour
+	}
+
+	state, err := openMirrorDB(viper.GetString("watch.daemon.db"))
+	if err != nil {
+		return err
+	}
+	defer state.Close()
+
+	pipeline := newBuildPipeline()
+	if pipeline == nil {
+		pipeline = &watch.Pipeline{Output: viper.GetString("watch.output")}
+	}
+	mirror := &watch.Mirror{
+		Rules:    rules,
+		Sources:  viper.GetStringSlice("watch.daemon.sources"),
+		Pipeline: pipeline,
+		State:    state,
+	}
+
+	log.Infof("Mirroring new builds to %s every %s", pipeline.Output, interval)
+	for _, r := range rules {
+		utils.Indent(log.Info, 2)(r.String())
+	}
+
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(sigs)
+
+	backfill := viper.GetBool("watch.daemon.backfill")
+	for {
+		events, err := mirror.Poll(backfill)
+		if err != nil {
+			log.Errorf("failed to poll for new builds: %v", err)
+		}
+		backfill = false
+		for _, ev := range events {
+			msg := fmt.Sprintf("Mirrored %s %s (%s) to %s", ev.Build.Device, ev.Build.Version, ev.Build.Build, ev.Build.Path)
+			if ev.Err != nil {
+				msg = fmt.Sprintf("Failed to mirror %s %s (%s): %v", ev.Build.Device, ev.Build.Version, ev.Build.Build, ev.Err)
+			}
+			if announce {
+				if err := watch.DiscordAnnounce(msg, &watch.Config{
+					DiscordWebhookID:    viper.GetString("watch.discord-id"),
+					DiscordWebhookToken: viper.GetString("watch.discord-token"),
+					DiscordColor:        "4535172",
+					DiscordAuthor:       "ipsw watch",
+					DiscordIconURL:      "https://raw.githubusercontent.com/blacktop/ipsw/master/www/static/img/logo/ipsw@3x.png",
+				}); err != nil {
+					log.Errorf("discord announce failed: %v", err)
+				}
+			} else if asJSON {
+				json.NewEncoder(os.Stdout).Encode(ev.Build)
+			} else if ev.Err != nil {
+				log.Error(msg)
+			} else {
+				log.Info(msg)
+			}
+		}
+
+		select {
+		case sig := <-sigs:
+			log.Infof("Received %s, stopping the mirror", sig)
+			return nil
+		case <-time.After(interval):
+		}
+	}
 }
 
 // TODO: add support for watching local repos so that we can leverage `git log -L :func:file` to watch a single function
@@ -85,8 +463,8 @@ func init() {
 // watchCmd represents the watch command
 var watchCmd = &cobra.Command{
 	Use:           "watch <ORG/REPO>",
-	Short:         "Watch Github Commits",
-	Args:          cobra.ExactArgs(1),
+	Short:         "Watch Github Commits (or the mesu MobileAsset catalogs, TSS signing status, webhook build events or mirror new builds)",
+	Args:          cobra.MaximumNArgs(1),
 	SilenceUsage:  true,
 	SilenceErrors: true,
 	RunE: func(cmd *cobra.Command, args []string) error {
@@ -103,6 +481,18 @@ var watchCmd = &cobra.Command{
 			annouce = true
 		}
 
+		if viper.GetBool("watch.daemon.enabled") {
+			return watchDaemon(annouce, asJSON)
+		} else if len(viper.GetString("watch.webhook")) > 0 {
+			return watchWebhook(annouce, asJSON)
+		} else if viper.GetBool("watch.catalog") {
+			return watchCatalogs(annouce, asJSON)
+		} else if viper.GetBool("watch.tss") {
+			return watchSigning(annouce, asJSON)
+		} else if len(args) == 0 {
+			return fmt.Errorf("you must supply an <ORG/REPO> to watch (or use --catalog, --tss or --webhook)")
+		}
+
 		if len(apiToken) == 0 {
 			if val, ok := os.LookupEnv("GITHUB_TOKEN"); ok {
 				apiToken = val
@@ -176,7 +566,7 @@ var watchCmd = &cobra.Command{
 						fmt.Sprintf("commit: %s (author: %s, date: %s)",
 							commit.OID,
 							commit.Author.Name,
-							commit.Author.Date.Format("02Jan2006 15:04:05")),
+							utils.FormatTime(commit.Author.Date.Time)),
 					))
 					body := re.ReplaceAllStringFunc(string(commit.MsgBody), func(s string) string {
 						return colorHighlight(s)
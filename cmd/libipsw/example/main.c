@@ -0,0 +1,72 @@
+/*
+ * Looks up a device on ipsw.me with libipsw
+ *
+ *   make build-libipsw
+ *   cc -Idist/libipsw -o example cmd/libipsw/example/main.c -Ldist/libipsw -lipsw
+ *   (or make libipsw-example to link it statically)
+ *   ./example iPhone15,2
+ */
+#include <stdio.h>
+#include <string.h>
+
+#include "libipsw.h"
+
+static const char *log_levels[] = {"DEBUG", "INFO", "WARN", "ERROR", "FATAL"};
+
+// log_line prints the library's log lines with their fields (i.e. the trace ID of a lookup)
+static void log_line(int level, const char *message, const char *fields_json, void *user_data) {
+	(void)user_data;
+	fprintf(stderr, "[libipsw %s] %s %s\n", level >= 0 && level <= LIBIPSW_LOG_FATAL ? log_levels[level] : "?", message, fields_json);
+}
+
+int main(int argc, char *argv[]) {
+	const char *device = argc > 1 ? argv[1] : "iPhone15,2";
+	char *out = NULL, *err = NULL;
+	unsigned int outLen = 0, errLen = 0;
+	int ret = 0;
+
+	if (!libipsw_init()) {
+		fprintf(stderr, "failed to initialize libipsw\n");
+		return 1;
+	}
+
+	c_libipsw_set_log_callback(log_line, LIBIPSW_LOG_INFO, NULL);
+
+	char *version = libipsw_version();
+	printf("libipsw %s (header %s)\n", version, LIBIPSW_VERSION);
+	c_libipsw_free_string(version);
+
+	if (c_libipsw_capabilities(&out, &outLen, &err, &errLen)) {
+		printf("capabilities: %.*s\n", (int)outLen, out);
+		c_libipsw_free_string(out);
+	} else {
+		c_libipsw_free_string(err);
+	}
+
+	if (c_internal_download_ipsw_me_GetDevice((char *)device, (unsigned int)strlen(device), &out, &outLen, &err, &errLen)) {
+		printf("%.*s\n", (int)outLen, out);
+		c_libipsw_free_string(out);
+	} else {
+		int code = c_libipsw_error_code(err);
+		char *name = c_libipsw_error_code_name(code);
+		fprintf(stderr, "error %d (%s): %.*s\n", code, name, (int)errLen, err);
+		c_libipsw_free_string(name);
+		c_libipsw_free_string(err);
+		ret = code == LIBIPSW_ERR_NOT_FOUND ? 3 : 1;
+	}
+
+	// the same lookup of the offline Xcode device database through the generic entrypoint
+	char params[128];
+	snprintf(params, sizeof(params), "{\"product_type\":\"%s\"}", device);
+	const char *method = "xcode.device";
+	if (c_libipsw_call("", 0, (char *)method, (unsigned int)strlen(method), params, (unsigned int)strlen(params), &out, &outLen, &err, &errLen)) {
+		printf("%s: %.*s\n", method, (int)outLen, out);
+		c_libipsw_free_string(out);
+	} else {
+		fprintf(stderr, "%s: %.*s\n", method, (int)errLen, err);
+		c_libipsw_free_string(err);
+	}
+
+	libipsw_shutdown();
+	return ret;
+}
@@ -0,0 +1,132 @@
+/*
+ * Calls libipsw from many threads at once to check that the C API is thread-safe
+ * (make libipsw-stress builds it against a race detector enabled libipsw)
+ *
+ *   ./stress [threads] [iterations]
+ */
+#include <pthread.h>
+#include <stdio.h>
+#include <stdlib.h>
+#include <string.h>
+
+#include "libipsw.h"
+
+static int iterations = 100;
+
+#define CHECK(cond, ...)                                                                                               \
+	do {                                                                                                               \
+		if (!(cond)) {                                                                                                 \
+			fprintf(stderr, __VA_ARGS__);                                                                              \
+			fprintf(stderr, "\n");                                                                                     \
+			failures++;                                                                                                \
+		}                                                                                                              \
+	} while (0)
+
+/* expect_error checks that a call failed with code (and frees its error message)
+ *
+ * NOTE: make the call before passing its err: C does not specify the order arguments are evaluated in */
+static int expect_error(char ok, char *err, unsigned int errLen, int code, const char *call) {
+	if (ok) {
+		fprintf(stderr, "%s: succeeded but should have failed\n", call);
+		return 1;
+	}
+	int got = c_libipsw_error_code(err);
+	if (got != code) {
+		fprintf(stderr, "%s: error code %d (want %d): %.*s\n", call, got, code, (int)errLen, err);
+	}
+	c_libipsw_free_string(err);
+	return got != code;
+}
+
+static void *worker(void *arg) {
+	long failures = 0;
+	(void)arg;
+
+	for (int i = 0; i < iterations; i++) {
+		char *out = NULL, *err = NULL, *handle = NULL, *trace = NULL;
+		unsigned int outLen = 0, errLen = 0, handleLen = 0, traceLen = 0;
+		char ok;
+
+		/* handles */
+		CHECK(c_libipsw_handle_new(&handle, &handleLen), "c_libipsw_handle_new failed");
+		if (c_libipsw_handle_trace(handle, handleLen, &trace, &traceLen, &err, &errLen)) {
+			CHECK(traceLen > 0, "c_libipsw_handle_trace: empty trace ID");
+			c_libipsw_free_string(trace);
+		} else {
+			CHECK(0, "c_libipsw_handle_trace: %.*s", (int)errLen, err);
+			c_libipsw_free_string(err);
+		}
+		if (!c_libipsw_cancel(handle, handleLen, &err, &errLen)) {
+			CHECK(0, "c_libipsw_cancel: %.*s", (int)errLen, err);
+			c_libipsw_free_string(err);
+		}
+		c_libipsw_handle_free(handle, handleLen);
+		ok = c_libipsw_cancel(handle, handleLen, &err, &errLen);
+		failures += expect_error(ok, err, errLen, LIBIPSW_ERR_INVALID_ARG, "c_libipsw_cancel (freed handle)");
+		c_libipsw_free_string(handle);
+
+		/* device queries */
+		if (c_pkg_xcode_xcode_GetDeviceForProd("iPhone15,2", 10, &out, &outLen, &err, &errLen)) {
+			CHECK(strstr(out, "iPhone15,2") != NULL, "c_pkg_xcode_xcode_GetDeviceForProd: wrong device %.*s", (int)outLen, out);
+			c_libipsw_free_string(out);
+		} else {
+			CHECK(0, "c_pkg_xcode_xcode_GetDeviceForProd: %.*s", (int)errLen, err);
+			c_libipsw_free_string(err);
+		}
+		ok = c_pkg_xcode_xcode_GetDevicesForChip("t0000", 5, &out, &outLen, &err, &errLen);
+		failures += expect_error(ok, err, errLen, LIBIPSW_ERR_NOT_FOUND, "c_pkg_xcode_xcode_GetDevicesForChip");
+
+		/* download manager and results */
+		if (c_internal_download_manager_List(&out, &outLen, &err, &errLen)) {
+			c_libipsw_free_string(out);
+		} else {
+			CHECK(0, "c_internal_download_manager_List: %.*s", (int)errLen, err);
+			c_libipsw_free_string(err);
+		}
+		ok = c_internal_download_manager_Cancel("dl-0", 4, &err, &errLen);
+		failures += expect_error(ok, err, errLen, LIBIPSW_ERR_INVALID_ARG, "c_internal_download_manager_Cancel");
+		ok = c_download_start("", 0, "", 0, "", 0, "", 0, "", 0, 0, &out, &outLen, &err, &errLen);
+		failures += expect_error(ok, err, errLen, LIBIPSW_ERR_INVALID_ARG, "c_download_start (no url)");
+		ok = c_download_status("job-0", 5, &out, &outLen, &err, &errLen);
+		failures += expect_error(ok, err, errLen, LIBIPSW_ERR_INVALID_ARG, "c_download_status (unknown job)");
+
+		char *version = libipsw_version();
+		CHECK(strcmp(version, LIBIPSW_VERSION) == 0, "libipsw_version: %s (want %s)", version, LIBIPSW_VERSION);
+		c_libipsw_free_string(version);
+	}
+
+	return (void *)failures;
+}
+
+int main(int argc, char *argv[]) {
+	int threads = argc > 1 ? atoi(argv[1]) : 16;
+	if (argc > 2) {
+		iterations = atoi(argv[2]);
+	}
+	if (threads < 1 || iterations < 1) {
+		fprintf(stderr, "usage: %s [threads] [iterations]\n", argv[0]);
+		return 2;
+	}
+
+	if (!libipsw_init()) {
+		fprintf(stderr, "failed to initialize libipsw\n");
+		return 1;
+	}
+
+	pthread_t *tids = calloc((size_t)threads, sizeof(pthread_t));
+	for (int i = 0; i < threads; i++) {
+		pthread_create(&tids[i], NULL, worker, NULL);
+	}
+	long failures = 0;
+	for (int i = 0; i < threads; i++) {
+		void *ret = NULL;
+		pthread_join(tids[i], &ret);
+		failures += (long)ret;
+	}
+	free(tids);
+
+	libipsw_shutdown();
+
+	printf("%d threads x %d iterations: %ld failure(s)\n", threads, iterations, failures);
+	return failures > 0 ? 1 : 0;
+}
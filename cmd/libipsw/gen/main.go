@@ -0,0 +1,703 @@
+// Command gen generates libipsw.h, the C header of the functions exported with //export by the
+// given packages (and the schemas of the JSON they return), from their Go sources; with -py it also
+// generates the ctypes bindings of the Python package.
+//
+//	go run ./gen -o include/libipsw.h -py ../../python/libipsw ../../internal/download ../../pkg/xcode .
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// jsonOutputs are the Go types serialized to the JSON out parameter (outJson/outputJson)
+// or the result (outResult) of the exports that return JSON
+var jsonOutputs = map[string]string{
+	"c_internal_download_ipsw_me_GetAllDevices":         "[]download.Device",
+	"c_internal_download_ipsw_me_GetDevice":             "download.Device",
+	"c_internal_download_ipsw_me_GetDeviceWithHandle":   "download.Device",
+	"c_internal_download_ipsw_me_GetDeviceIPSWs":        "[]download.IPSW",
+	"c_internal_download_ipsw_me_GetDeviceOTAs":         "[]download.IPSW",
+	"c_internal_download_ipsw_me_GetAllIPSW":            "[]download.IPSW",
+	"c_internal_download_ipsw_me_GetIPSW":               "download.IPSW",
+	"c_internal_download_ipsw_me_GetVersion":            "string",
+	"c_internal_download_ipsw_me_GetBuildID":            "string",
+	"c_internal_download_iphonewiki_GetWikiIPSWs":       "[]download.WikiFirmware",
+	"c_internal_download_manager_Status":                "download.DownloadStatus",
+	"c_internal_download_manager_List":                  "[]download.DownloadStatus",
+	"c_internal_download_Download":                      "download.Stats",
+	"c_internal_download_DownloadWithHandle":            "download.Stats",
+	"c_internal_download_ipsw_me_GetAllDevicesResult":   "[]download.Device",
+	"c_internal_download_ipsw_me_GetDeviceIPSWsResult":  "[]download.IPSW",
+	"c_internal_download_ipsw_me_GetAllIPSWResult":      "[]download.IPSW",
+	"c_internal_download_iphonewiki_GetWikiIPSWsResult": "[]download.WikiFirmware",
+	"c_pkg_xcode_xcode_GetDevices":                      "[]xcode.Device",
+	"c_pkg_xcode_xcode_GetDeviceForProd":                "xcode.Device",
+	"c_pkg_xcode_xcode_GetDeviceForModel":               "xcode.Device",
+	"c_pkg_xcode_xcode_GetDeviceForBoard":               "xcode.Device",
+	"c_pkg_xcode_xcode_GetDevicesForIdiom":              "[]xcode.Device",
+	"c_pkg_xcode_xcode_GetDevicesForChip":               "[]xcode.Device",
+	"c_devportal_list_downloads":                        "download.DevDownloads",
+	"c_download_status":                                 "download.DownloadStatus",
+	"c_libipsw_version":                                 "download.BuildInfo",
+	"c_libipsw_capabilities":                            "download.Capabilities",
+	"c_libipsw_call":                                    "any",
+}
+
+// jsonInputs are the Go types the JSON arguments of the exports are decoded into
+var jsonInputs = map[string]string{
+	"c_internal_download_iphonewiki_GetWikiIPSWs":       "download.WikiConfig",
+	"c_internal_download_iphonewiki_GetWikiIPSWsResult": "download.WikiConfig",
+	"c_devportal_new": "download.DevSessionConfig",
+}
+
+// cTypes maps the cgo type names to C
+var cTypes = map[string]string{
+	"char":      "char",
+	"schar":     "signed char",
+	"uchar":     "unsigned char",
+	"short":     "short",
+	"ushort":    "unsigned short",
+	"int":       "int",
+	"uint":      "unsigned int",
+	"long":      "long",
+	"ulong":     "unsigned long",
+	"longlong":  "long long",
+	"ulonglong": "unsigned long long",
+	"float":     "float",
+	"double":    "double",
+	"size_t":    "size_t",
+}
+
+type export struct {
+	Name   string
+	Pkg    string
+	Doc    []string
+	Params []string
+	Args   []param
+	Result string
+	pos    token.Position
+}
+
+// param is a parameter of an export
+type param struct {
+	Type string // C type (i.e. "char **")
+	Name string
+}
+
+type pkg struct {
+	Name     string
+	Path     string
+	Preamble []string
+	Exports  []export
+	types    map[string]*ast.TypeSpec
+	methods  map[string]map[string]bool
+	version  string
+}
+
+func main() {
+	out := flag.String("o", "libipsw.h", "header to write")
+	py := flag.String("py", "", "Python package directory to write _ffi.py and _api.py to")
+	flag.Parse()
+	if flag.NArg() == 0 {
+		log.Fatal("usage: gen -o libipsw.h <package dir>...")
+	}
+
+	var pkgs []*pkg
+	for _, dir := range flag.Args() {
+		p, err := parseDir(dir)
+		if err != nil {
+			log.Fatal(err)
+		}
+		pkgs = append(pkgs, p)
+	}
+
+	header, err := generate(pkgs)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(*out), 0755); err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile(*out, header, 0644); err != nil {
+		log.Fatal(err)
+	}
+
+	if len(*py) > 0 {
+		ffi, api, err := generatePython(pkgs)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := os.MkdirAll(*py, 0755); err != nil {
+			log.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(*py, "_ffi.py"), ffi, 0644); err != nil {
+			log.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(*py, "_api.py"), api, 0644); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
+
+func parseDir(dir string) (*pkg, error) {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, func(fi os.FileInfo) bool {
+		return !strings.HasSuffix(fi.Name(), "_test.go")
+	}, parser.ParseComments)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %v", dir, err)
+	}
+	if len(pkgs) != 1 {
+		return nil, fmt.Errorf("expected one package in %s, found %d", dir, len(pkgs))
+	}
+
+	p := &pkg{
+		Path:    modulePath(dir),
+		types:   make(map[string]*ast.TypeSpec),
+		methods: make(map[string]map[string]bool),
+	}
+	var files []string
+	for _, ap := range pkgs {
+		p.Name = ap.Name
+		for fname := range ap.Files {
+			files = append(files, fname)
+		}
+	}
+	sort.Strings(files)
+
+	for _, fname := range files {
+		f := pkgs[p.Name].Files[fname]
+		for _, decl := range f.Decls {
+			switch d := decl.(type) {
+			case *ast.GenDecl:
+				if d.Tok == token.IMPORT {
+					for _, spec := range d.Specs {
+						if is := spec.(*ast.ImportSpec); is.Path.Value == `"C"` {
+							doc := d.Doc
+							if is.Doc != nil {
+								doc = is.Doc
+							}
+							if doc != nil {
+								p.Preamble = append(p.Preamble, preamble(doc.Text())...)
+							}
+						}
+					}
+				}
+				for _, spec := range d.Specs {
+					switch s := spec.(type) {
+					case *ast.TypeSpec:
+						p.types[s.Name.Name] = s
+					case *ast.ValueSpec:
+						for i, name := range s.Names {
+							if name.Name == "CAPIVersion" && i < len(s.Values) {
+								if lit, ok := s.Values[i].(*ast.BasicLit); ok {
+									p.version, _ = strconv.Unquote(lit.Value)
+								}
+							}
+						}
+					}
+				}
+			case *ast.FuncDecl:
+				if d.Recv != nil && len(d.Recv.List) == 1 {
+					recv := d.Recv.List[0].Type
+					if star, ok := recv.(*ast.StarExpr); ok {
+						recv = star.X
+					}
+					if id, ok := recv.(*ast.Ident); ok {
+						if p.methods[id.Name] == nil {
+							p.methods[id.Name] = make(map[string]bool)
+						}
+						p.methods[id.Name][d.Name.Name] = true
+					}
+					continue
+				}
+				e, ok, err := parseExport(fset, p.Name, d)
+				if err != nil {
+					return nil, err
+				}
+				if ok {
+					p.Exports = append(p.Exports, e)
+				}
+			}
+		}
+	}
+
+	return p, nil
+}
+
+// modulePath returns dir relative to the root of its module (for the header comments)
+func modulePath(dir string) string {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return filepath.ToSlash(filepath.Clean(dir))
+	}
+	for root := abs; root != filepath.Dir(root); root = filepath.Dir(root) {
+		if _, err := os.Stat(filepath.Join(root, "go.mod")); err == nil {
+			if rel, err := filepath.Rel(root, abs); err == nil {
+				return filepath.ToSlash(rel)
+			}
+			break
+		}
+	}
+	return filepath.ToSlash(filepath.Clean(dir))
+}
+
+// preamble returns the declarations of a cgo preamble that belong in the public header:
+// its /* */ comments, typedefs and enums (not the #cgo/#include directives or static helpers)
+func preamble(text string) []string {
+	var blocks []string
+	lines := strings.Split(text, "\n")
+	for i := 0; i < len(lines); i++ {
+		trimmed := strings.TrimSpace(lines[i])
+		switch {
+		case strings.HasPrefix(trimmed, "/*"):
+			var block []string
+			for ; i < len(lines); i++ {
+				block = append(block, lines[i])
+				if strings.Contains(lines[i], "*/") {
+					break
+				}
+			}
+			blocks = append(blocks, strings.Join(block, "\n"))
+		case strings.HasPrefix(trimmed, "typedef"):
+			blocks = append(blocks, trimmed)
+		case strings.HasPrefix(trimmed, "enum"):
+			var block []string
+			for ; i < len(lines); i++ {
+				block = append(block, lines[i])
+				if strings.HasPrefix(strings.TrimSpace(lines[i]), "};") {
+					break
+				}
+			}
+			blocks = append(blocks, strings.Join(block, "\n"))
+		case strings.HasPrefix(trimmed, "static"):
+			for ; i < len(lines); i++ { // skip the body
+				if strings.HasPrefix(lines[i], "}") {
+					break
+				}
+			}
+		}
+	}
+	return blocks
+}
+
+func parseExport(fset *token.FileSet, pkgName string, fn *ast.FuncDecl) (export, bool, error) {
+	if fn.Doc == nil {
+		return export{}, false, nil
+	}
+	e := export{Pkg: pkgName, pos: fset.Position(fn.Pos())}
+	for _, c := range fn.Doc.List {
+		if name, ok := strings.CutPrefix(c.Text, "//export "); ok {
+			e.Name = strings.TrimSpace(name)
+		}
+	}
+	if len(e.Name) == 0 {
+		return export{}, false, nil
+	}
+
+	for _, line := range strings.Split(strings.TrimSpace(fn.Doc.Text()), "\n") {
+		if !strings.HasPrefix(line, "export ") {
+			e.Doc = append(e.Doc, line)
+		}
+	}
+	for len(e.Doc) > 0 && len(strings.TrimSpace(e.Doc[len(e.Doc)-1])) == 0 {
+		e.Doc = e.Doc[:len(e.Doc)-1]
+	}
+
+	for _, field := range fn.Type.Params.List {
+		ctype, err := cType(field.Type)
+		if err != nil {
+			return export{}, false, fmt.Errorf("%s: %s: %v", e.pos, e.Name, err)
+		}
+		for _, name := range field.Names {
+			e.Params = append(e.Params, cDecl(ctype, name.Name))
+			e.Args = append(e.Args, param{Type: ctype, Name: name.Name})
+		}
+	}
+	if len(e.Params) == 0 {
+		e.Params = []string{"void"}
+	}
+
+	e.Result = "void"
+	if fn.Type.Results != nil {
+		if len(fn.Type.Results.List) != 1 || len(fn.Type.Results.List[0].Names) > 1 {
+			return export{}, false, fmt.Errorf("%s: %s: exported functions must have at most one result", e.pos, e.Name)
+		}
+		ctype, err := cType(fn.Type.Results.List[0].Type)
+		if err != nil {
+			return export{}, false, fmt.Errorf("%s: %s: %v", e.pos, e.Name, err)
+		}
+		e.Result = ctype
+	}
+
+	return e, true, nil
+}
+
+// cType returns the C type of a cgo parameter type (i.e. **C.char is "char **")
+func cType(expr ast.Expr) (string, error) {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		elem, err := cType(t.X)
+		if err != nil {
+			return "", err
+		}
+		if strings.HasSuffix(elem, "*") {
+			return elem + "*", nil
+		}
+		return elem + " *", nil
+	case *ast.SelectorExpr:
+		pkg, ok := t.X.(*ast.Ident)
+		if !ok {
+			break
+		}
+		switch {
+		case pkg.Name == "C":
+			if c, ok := cTypes[t.Sel.Name]; ok {
+				return c, nil
+			}
+			return t.Sel.Name, nil // a typedef from the preamble
+		case pkg.Name == "unsafe" && t.Sel.Name == "Pointer":
+			return "void *", nil
+		}
+	}
+	return "", fmt.Errorf("unsupported parameter type %s", exprString(expr))
+}
+
+func cDecl(ctype, name string) string {
+	if strings.HasSuffix(ctype, "*") {
+		return ctype + name
+	}
+	return ctype + " " + name
+}
+
+// exprString returns the Go source of a type expression
+func exprString(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		return t.Name
+	case *ast.StarExpr:
+		return "*" + exprString(t.X)
+	case *ast.SelectorExpr:
+		return exprString(t.X) + "." + t.Sel.Name
+	case *ast.ArrayType:
+		return "[]" + exprString(t.Elt)
+	case *ast.MapType:
+		return "map[" + exprString(t.Key) + "]" + exprString(t.Value)
+	case *ast.InterfaceType:
+		return "any"
+	case *ast.StructType:
+		return "struct{...}"
+	}
+	return fmt.Sprintf("%T", expr)
+}
+
+func generate(pkgs []*pkg) ([]byte, error) {
+	var version string
+	byName := make(map[string]*pkg)
+	for _, p := range pkgs {
+		byName[p.Name] = p
+		if len(p.version) > 0 {
+			version = p.version
+		}
+	}
+	if len(version) == 0 {
+		return nil, fmt.Errorf("CAPIVersion not found in %d packages", len(pkgs))
+	}
+	semver := strings.SplitN(version, ".", 3)
+	if len(semver) != 3 {
+		return nil, fmt.Errorf("invalid CAPIVersion %q", version)
+	}
+
+	seen := make(map[string]bool)
+	for _, p := range pkgs {
+		for _, e := range p.Exports {
+			if seen[e.Name] {
+				return nil, fmt.Errorf("%s: %s is exported twice", e.pos, e.Name)
+			}
+			seen[e.Name] = true
+			if _, ok := jsonOutputs[e.Name]; !ok && returnsJSON(e) {
+				return nil, fmt.Errorf("%s: %s returns JSON but has no jsonOutputs entry", e.pos, e.Name)
+			}
+		}
+	}
+	for name := range jsonOutputs {
+		if !seen[name] {
+			return nil, fmt.Errorf("jsonOutputs entry %s is not exported", name)
+		}
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "/* Code generated by cmd/libipsw/gen; DO NOT EDIT. */\n\n")
+	b.WriteString("/*\n * libipsw - the C API of ipsw\n *\n")
+	b.WriteString(" * Build the library with `make build-libipsw` (c-shared and c-archive) and call libipsw_init\n")
+	b.WriteString(" * before any other function. Strings are passed as a pointer and a length (they do not need a NUL\n")
+	b.WriteString(" * terminator) and functions returning char report success with 1 and failure with 0.\n *\n")
+	b.WriteString(" * All functions can be called from multiple threads at once (after libipsw_init returns): handles,\n")
+	b.WriteString(" * results and downloads can be used from any thread and every handle has its own HTTP client. The\n")
+	b.WriteString(" * calls on one c_devportal session are serialized. Free error messages with c_libipsw_free_string\n")
+	b.WriteString(" * (not free) so that their error code is forgotten before malloc reuses the address.\n */\n\n")
+	b.WriteString("#ifndef LIBIPSW_H\n#define LIBIPSW_H\n\n")
+	b.WriteString("#include <stddef.h>\n\n")
+	fmt.Fprintf(&b, "#define LIBIPSW_VERSION \"%s\"\n", version)
+	fmt.Fprintf(&b, "#define LIBIPSW_VERSION_MAJOR %s\n", semver[0])
+	fmt.Fprintf(&b, "#define LIBIPSW_VERSION_MINOR %s\n", semver[1])
+	fmt.Fprintf(&b, "#define LIBIPSW_VERSION_PATCH %s\n\n", semver[2])
+	b.WriteString("#ifdef __cplusplus\nextern \"C\" {\n#endif\n")
+
+	done := make(map[string]bool)
+	for _, p := range pkgs {
+		for _, block := range p.Preamble {
+			if done[block] {
+				continue
+			}
+			done[block] = true
+			b.WriteString("\n" + block + "\n")
+		}
+	}
+
+	var schemas []string
+	for _, p := range pkgs {
+		if len(p.Exports) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "\n/* %s */\n", p.Path)
+		for _, e := range p.Exports {
+			b.WriteString("\n")
+			doc := append([]string(nil), e.Doc...)
+			if typ, ok := jsonInputs[e.Name]; ok {
+				if len(doc) > 0 {
+					doc = append(doc, "")
+				}
+				doc = append(doc, "JSON argument: "+typ+" (see the schemas at the end)")
+				schemas = append(schemas, typ)
+			}
+			if typ, ok := jsonOutputs[e.Name]; ok {
+				if _, ok := jsonInputs[e.Name]; !ok && len(doc) > 0 {
+					doc = append(doc, "")
+				}
+				if typ == "any" {
+					doc = append(doc, "Returns JSON: any value (it depends on the arguments)")
+				} else {
+					doc = append(doc, "Returns JSON: "+typ+" (see the schemas at the end)")
+					schemas = append(schemas, typ)
+				}
+			}
+			if len(doc) > 0 {
+				b.WriteString("/*\n")
+				for _, line := range doc {
+					b.WriteString(strings.TrimRight(" * "+line, " ") + "\n")
+				}
+				b.WriteString(" */\n")
+			}
+			fmt.Fprintf(&b, "extern %s(%s);\n", cDecl(e.Result, e.Name), strings.Join(e.Params, ", "))
+		}
+	}
+
+	b.WriteString("\n/*\n * JSON schemas\n *\n")
+	b.WriteString(" * Fields marked with ? are omitted when empty, times are RFC 3339 strings and\n")
+	b.WriteString(" * durations are numbers of nanoseconds.\n")
+	sw := &schemaWriter{pkgs: byName, done: make(map[string]bool)}
+	sort.Strings(schemas)
+	for _, typ := range schemas {
+		if err := sw.write(strings.TrimPrefix(typ, "[]")); err != nil {
+			return nil, err
+		}
+	}
+	b.WriteString(sw.b.String())
+	b.WriteString(" */\n")
+
+	b.WriteString("\n#ifdef __cplusplus\n}\n#endif\n\n#endif /* LIBIPSW_H */\n")
+	return b.Bytes(), nil
+}
+
+func returnsJSON(e export) bool {
+	for _, p := range e.Params {
+		for _, name := range []string{"outJson", "outputJson", "outResult"} {
+			if strings.HasSuffix(p, "*"+name) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+type schemaWriter struct {
+	pkgs map[string]*pkg
+	done map[string]bool
+	b    strings.Builder
+}
+
+// write writes the schema of a named struct type (pkg.Type) and then of the struct types it references
+func (sw *schemaWriter) write(name string) error {
+	pkgName, typeName, ok := strings.Cut(name, ".")
+	if !ok || sw.done[name] {
+		return nil
+	}
+	sw.done[name] = true
+	p, ok := sw.pkgs[pkgName]
+	if !ok {
+		return fmt.Errorf("unknown package in JSON type %s", name)
+	}
+	spec, ok := p.types[typeName]
+	if !ok {
+		return fmt.Errorf("unknown JSON type %s", name)
+	}
+	st, ok := spec.Type.(*ast.StructType)
+	if !ok {
+		return fmt.Errorf("JSON type %s is not a struct", name)
+	}
+
+	var refs []string
+	fmt.Fprintf(&sw.b, " *\n * %s {\n", name)
+	if err := sw.fields(p, st, &refs); err != nil {
+		return fmt.Errorf("%s: %v", name, err)
+	}
+	sw.b.WriteString(" * }\n")
+
+	for _, ref := range refs {
+		if err := sw.write(ref); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (sw *schemaWriter) fields(p *pkg, st *ast.StructType, refs *[]string) error {
+	for _, f := range jsonFields(p, st) {
+		jt, err := sw.jsonType(p, f.Type, refs)
+		if err != nil {
+			return fmt.Errorf("field %s: %v", f.Name, err)
+		}
+		opt := ""
+		if f.OmitEmpty {
+			opt = "?"
+		}
+		fmt.Fprintf(&sw.b, " *   %q%s: %s\n", f.Key, opt, jt)
+	}
+	return nil
+}
+
+// jsonField is a field of a struct as encoding/json serializes it
+type jsonField struct {
+	Name      string // Go field name
+	Key       string // JSON object key
+	OmitEmpty bool
+	Type      ast.Expr
+}
+
+// jsonFields returns the serialized fields of a struct type (the fields of embedded structs are inlined)
+func jsonFields(p *pkg, st *ast.StructType) []jsonField {
+	var fields []jsonField
+	for _, field := range st.Fields.List {
+		key, omitempty, skip := "", false, false
+		if field.Tag != nil {
+			tag, _ := strconv.Unquote(field.Tag.Value)
+			if jt, ok := reflect.StructTag(tag).Lookup("json"); ok {
+				name, opts, _ := strings.Cut(jt, ",")
+				if name == "-" && len(opts) == 0 {
+					skip = true
+				}
+				key = name
+				omitempty = strings.Contains(","+opts+",", ",omitempty,")
+			}
+		}
+		if skip {
+			continue
+		}
+		if len(field.Names) == 0 { // embedded
+			typ := field.Type
+			if star, ok := typ.(*ast.StarExpr); ok {
+				typ = star.X
+			}
+			if id, ok := typ.(*ast.Ident); ok && len(key) == 0 {
+				if spec, ok := p.types[id.Name]; ok {
+					if est, ok := spec.Type.(*ast.StructType); ok {
+						fields = append(fields, jsonFields(p, est)...)
+					}
+				}
+			}
+			continue
+		}
+		for _, name := range field.Names {
+			if !name.IsExported() {
+				continue
+			}
+			k := key
+			if len(k) == 0 {
+				k = name.Name
+			}
+			fields = append(fields, jsonField{Name: name.Name, Key: k, OmitEmpty: omitempty, Type: field.Type})
+		}
+	}
+	return fields
+}
+
+// jsonType returns the JSON type of a Go type (named struct types are returned as pkg.Type and added to refs)
+func (sw *schemaWriter) jsonType(p *pkg, expr ast.Expr, refs *[]string) (string, error) {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return sw.jsonType(p, t.X, refs)
+	case *ast.ArrayType:
+		if id, ok := t.Elt.(*ast.Ident); ok && id.Name == "byte" {
+			return "string (base64)", nil
+		}
+		elem, err := sw.jsonType(p, t.Elt, refs)
+		if err != nil {
+			return "", err
+		}
+		return "[" + elem + "]", nil
+	case *ast.MapType:
+		elem, err := sw.jsonType(p, t.Value, refs)
+		if err != nil {
+			return "", err
+		}
+		return "{string: " + elem + "}", nil
+	case *ast.InterfaceType:
+		return "any", nil
+	case *ast.SelectorExpr:
+		switch exprString(t) {
+		case "time.Time":
+			return "string (time)", nil
+		case "time.Duration":
+			return "number (duration)", nil
+		}
+		return "any", nil
+	case *ast.Ident:
+		switch t.Name {
+		case "string":
+			return "string", nil
+		case "bool":
+			return "boolean", nil
+		case "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64", "float32", "float64", "byte", "rune":
+			return "number", nil
+		case "any":
+			return "any", nil
+		}
+		spec, ok := p.types[t.Name]
+		if !ok {
+			return "", fmt.Errorf("unknown type %s", t.Name)
+		}
+		if m := p.methods[t.Name]; m["MarshalJSON"] || m["MarshalText"] {
+			return "any (custom)", nil
+		}
+		if _, ok := spec.Type.(*ast.StructType); ok {
+			ref := p.Name + "." + t.Name
+			*refs = append(*refs, ref)
+			return ref, nil
+		}
+		return sw.jsonType(p, spec.Type, refs)
+	}
+	return "", fmt.Errorf("unsupported type %s", exprString(expr))
+}
@@ -0,0 +1,637 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// pyOptional are the string parameters that default to "" in the Python wrappers
+var pyOptional = map[string]bool{
+	"dest":         true,
+	"sha1":         true,
+	"sha256":       true,
+	"proxy":        true,
+	"folder":       true,
+	"downloadType": true,
+	"username":     true,
+	"password":     true,
+}
+
+// pySkip are the exports that get no Python wrapper (the runtime calls them directly)
+var pySkip = map[string]bool{
+	"c_libipsw_result_read": true, // fills a caller buffer
+}
+
+// ctypesTypes maps the C types of the exports to ctypes (char * is a c_void_p so that returned
+// strings can be freed with c_libipsw_free_string)
+var ctypesTypes = map[string]string{
+	"void":                 "None",
+	"char":                 "ctypes.c_byte",
+	"int":                  "ctypes.c_int",
+	"unsigned int":         "ctypes.c_uint",
+	"long long":            "ctypes.c_longlong",
+	"unsigned long long":   "ctypes.c_ulonglong",
+	"double":               "ctypes.c_double",
+	"char *":               "ctypes.c_void_p",
+	"void *":               "ctypes.c_void_p",
+	"char **":              "ctypes.POINTER(ctypes.c_void_p)",
+	"int *":                "ctypes.POINTER(ctypes.c_int)",
+	"unsigned int *":       "ctypes.POINTER(ctypes.c_uint)",
+	"unsigned long long *": "ctypes.POINTER(ctypes.c_ulonglong)",
+}
+
+var pyKeywords = map[string]bool{
+	"and": true, "as": true, "assert": true, "async": true, "await": true, "break": true, "class": true,
+	"continue": true, "def": true, "del": true, "elif": true, "else": true, "except": true, "finally": true,
+	"for": true, "from": true, "global": true, "if": true, "import": true, "in": true, "is": true,
+	"lambda": true, "nonlocal": true, "not": true, "or": true, "pass": true, "raise": true, "return": true,
+	"try": true, "while": true, "with": true, "yield": true, "None": true, "True": true, "False": true,
+}
+
+// snake returns the snake_case of a Go or JSON name (i.e. GetWikiIPSWs is get_wiki_ipsws
+// and GetAllIPSWResult is get_all_ipsw_result)
+func snake(name string) string {
+	rs := []rune(name)
+	var sb strings.Builder
+	for i, r := range rs {
+		if i > 0 && unicode.IsUpper(r) {
+			prev := rs[i-1]
+			// the start of a word after an acronym (but not the plural s of the acronym)
+			acronym := unicode.IsUpper(prev) && i+1 < len(rs) && unicode.IsLower(rs[i+1]) &&
+				!(rs[i+1] == 's' && (i+2 == len(rs) || !unicode.IsLower(rs[i+2])))
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || acronym {
+				sb.WriteRune('_')
+			}
+		}
+		sb.WriteRune(unicode.ToLower(r))
+	}
+	s := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' {
+			return r
+		}
+		return '_'
+	}, sb.String())
+	if len(s) > 0 && unicode.IsDigit(rune(s[0])) {
+		s = "_" + s
+	}
+	if pyKeywords[s] {
+		s += "_"
+	}
+	return s
+}
+
+var pkgPathRE = regexp.MustCompile(`^c_(?:(?:internal|pkg)_[a-z0-9]+_)?`)
+
+// pyFuncName returns the Python name of an export without its package path
+// (i.e. c_internal_download_ipsw_me_GetDevice is ipsw_me_get_device)
+func pyFuncName(e export) string {
+	return snake(pkgPathRE.ReplaceAllString(e.Name, ""))
+}
+
+// pyClassName returns the Python class of a Go struct (the types of other packages than download are prefixed)
+func pyClassName(pkgName, typeName string) string {
+	name := strings.ToUpper(typeName[:1]) + typeName[1:]
+	if pkgName == "download" {
+		return name
+	}
+	return strings.ToUpper(pkgName[:1]) + pkgName[1:] + name
+}
+
+// pyType is the Python type of a JSON value
+type pyType struct {
+	Kind  string // str, bool, int, float, any, list, map or struct
+	Elem  *pyType
+	Class string // struct
+}
+
+func (t *pyType) annotation() string {
+	switch t.Kind {
+	case "list":
+		return "List[" + t.Elem.annotation() + "]"
+	case "map":
+		return "Dict[str, " + t.Elem.annotation() + "]"
+	case "struct":
+		return "'" + t.Class + "'"
+	case "any":
+		return "Any"
+	}
+	return t.Kind
+}
+
+// decoder returns the Python callable that converts the decoded JSON value (empty if none is needed)
+func (t *pyType) decoder() string {
+	switch t.Kind {
+	case "struct":
+		return t.Class + "._decode"
+	case "list", "map":
+		if dec := t.Elem.decoder(); len(dec) > 0 {
+			return "_" + t.Kind + "(" + dec + ")"
+		}
+	}
+	return ""
+}
+
+type pyWriter struct {
+	pkgs    map[string]*pkg
+	done    map[string]bool
+	order   []string // class definitions in the order they were found
+	classes map[string]string
+	fields  map[string]string // the _fields assignments (set after all the classes are defined)
+}
+
+// class generates the dataclass of a named struct type (pkg.Type) and the ones it references
+func (pw *pyWriter) class(name string) (string, error) {
+	pkgName, typeName, ok := strings.Cut(name, ".")
+	if !ok {
+		return "", fmt.Errorf("invalid JSON type %s", name)
+	}
+	class := pyClassName(pkgName, typeName)
+	if pw.done[name] {
+		return class, nil
+	}
+	pw.done[name] = true
+	p, ok := pw.pkgs[pkgName]
+	if !ok {
+		return "", fmt.Errorf("unknown package in JSON type %s", name)
+	}
+	spec, ok := p.types[typeName]
+	if !ok {
+		return "", fmt.Errorf("unknown JSON type %s", name)
+	}
+	st, ok := spec.Type.(*ast.StructType)
+	if !ok {
+		return "", fmt.Errorf("JSON type %s is not a struct", name)
+	}
+	pw.order = append(pw.order, class)
+
+	var b, fb strings.Builder
+	fmt.Fprintf(&b, "@dataclass\nclass %s(_Struct):\n    \"\"\"%s\"\"\"\n\n", class, name)
+	fmt.Fprintf(&fb, "%s._fields = (\n", class)
+	seen := make(map[string]bool)
+	fields := jsonFields(p, st)
+	for _, f := range fields {
+		t, err := pw.typeOf(p, f.Type)
+		if err != nil {
+			return "", fmt.Errorf("%s: field %s: %v", name, f.Name, err)
+		}
+		attr := snake(f.Key)
+		for seen[attr] {
+			attr += "_"
+		}
+		seen[attr] = true
+		fmt.Fprintf(&b, "    %s: Optional[%s] = None\n", attr, t.annotation())
+		dec := t.decoder()
+		if len(dec) == 0 {
+			dec = "None"
+		}
+		omit := "False"
+		if f.OmitEmpty {
+			omit = "True"
+		}
+		fmt.Fprintf(&fb, "    (%q, %q, %s, %s),\n", attr, f.Key, dec, omit)
+	}
+	if len(fields) == 0 {
+		b.WriteString("    pass\n")
+	}
+	fb.WriteString(")\n")
+	pw.classes[class] = b.String()
+	pw.fields[class] = fb.String()
+	return class, nil
+}
+
+// typeOf returns the Python type of a Go type (like schemaWriter.jsonType)
+func (pw *pyWriter) typeOf(p *pkg, expr ast.Expr) (*pyType, error) {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return pw.typeOf(p, t.X)
+	case *ast.ArrayType:
+		if id, ok := t.Elt.(*ast.Ident); ok && id.Name == "byte" {
+			return &pyType{Kind: "str"}, nil // base64
+		}
+		elem, err := pw.typeOf(p, t.Elt)
+		if err != nil {
+			return nil, err
+		}
+		return &pyType{Kind: "list", Elem: elem}, nil
+	case *ast.MapType:
+		elem, err := pw.typeOf(p, t.Value)
+		if err != nil {
+			return nil, err
+		}
+		return &pyType{Kind: "map", Elem: elem}, nil
+	case *ast.InterfaceType:
+		return &pyType{Kind: "any"}, nil
+	case *ast.SelectorExpr:
+		switch exprString(t) {
+		case "time.Time":
+			return &pyType{Kind: "str"}, nil
+		case "time.Duration":
+			return &pyType{Kind: "int"}, nil
+		}
+		return &pyType{Kind: "any"}, nil
+	case *ast.Ident:
+		switch t.Name {
+		case "string":
+			return &pyType{Kind: "str"}, nil
+		case "bool":
+			return &pyType{Kind: "bool"}, nil
+		case "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64", "byte", "rune":
+			return &pyType{Kind: "int"}, nil
+		case "float32", "float64":
+			return &pyType{Kind: "float"}, nil
+		case "any":
+			return &pyType{Kind: "any"}, nil
+		}
+		spec, ok := p.types[t.Name]
+		if !ok {
+			return nil, fmt.Errorf("unknown type %s", t.Name)
+		}
+		if m := p.methods[t.Name]; m["MarshalJSON"] || m["MarshalText"] {
+			return &pyType{Kind: "any"}, nil
+		}
+		if _, ok := spec.Type.(*ast.StructType); ok {
+			class, err := pw.class(p.Name + "." + t.Name)
+			if err != nil {
+				return nil, err
+			}
+			return &pyType{Kind: "struct", Class: class}, nil
+		}
+		return pw.typeOf(p, spec.Type)
+	}
+	return nil, fmt.Errorf("unsupported type %s", exprString(expr))
+}
+
+// jsonTypeOf returns the Python type of a jsonOutputs/jsonInputs type (i.e. []download.Device)
+func (pw *pyWriter) jsonTypeOf(typ string) (*pyType, error) {
+	if elem, ok := strings.CutPrefix(typ, "[]"); ok {
+		t, err := pw.jsonTypeOf(elem)
+		if err != nil {
+			return nil, err
+		}
+		return &pyType{Kind: "list", Elem: t}, nil
+	}
+	switch typ {
+	case "string":
+		return &pyType{Kind: "str"}, nil
+	case "any":
+		return &pyType{Kind: "any"}, nil
+	}
+	class, err := pw.class(typ)
+	if err != nil {
+		return nil, err
+	}
+	return &pyType{Kind: "struct", Class: class}, nil
+}
+
+// pyOutput is a value returned through out parameters
+type pyOutput struct {
+	Name string // Python variable
+	Type *pyType
+	Args []param // the out parameters
+	Kind string  // string, json, result or number
+}
+
+// pyFunc generates the Python wrapper of an export (ok is false if it has parameters that can't be wrapped)
+func (pw *pyWriter) pyFunc(e export) (string, bool, error) {
+	n := len(e.Args)
+	if pySkip[e.Name] || e.Result != "char" || n < 2 || e.Args[n-2] != (param{"char **", "err"}) || e.Args[n-1] != (param{"unsigned int *", "errLen"}) {
+		return "", false, nil
+	}
+	args := e.Args[:n-2]
+
+	type pyParam struct {
+		decl, def string
+	}
+	var pyParams []pyParam
+	var prep, callArgs []string
+	var outs []*pyOutput
+	for i := 0; i < len(args); i++ {
+		a := args[i]
+		var next param
+		if i+1 < len(args) {
+			next = args[i+1]
+		}
+		paired := next.Name == a.Name+"Len"
+		switch {
+		case a.Type == "char *" && paired && (next.Type == "unsigned int" || next.Type == "int"):
+			name := snake(a.Name)
+			if typ, ok := jsonInputs[e.Name]; ok && strings.HasSuffix(a.Name, "Json") {
+				name = snake(strings.TrimSuffix(a.Name, "Json"))
+				t, err := pw.jsonTypeOf(typ)
+				if err != nil {
+					return "", false, err
+				}
+				pyParams = append(pyParams, pyParam{fmt.Sprintf("%s: Optional[%s]", name, t.annotation()), "None"})
+				prep = append(prep, fmt.Sprintf("%s_b = _rt.encode_json(%s)", name, name))
+			} else {
+				def := ""
+				if pyOptional[a.Name] {
+					def = `""`
+				}
+				pyParams = append(pyParams, pyParam{name + ": str", def})
+				prep = append(prep, fmt.Sprintf("%s_b = _rt.to_bytes(%s)", name, name))
+			}
+			callArgs = append(callArgs, name+"_b", "len("+name+"_b)")
+			i++
+		case a.Type == "char":
+			name := snake(a.Name)
+			pyParams = append(pyParams, pyParam{name + ": bool", "False"})
+			callArgs = append(callArgs, "1 if "+name+" else 0")
+		case a.Type == "char **" && paired && (next.Type == "unsigned int *" || next.Type == "int *"):
+			out := &pyOutput{Name: snake(a.Name), Args: []param{a, next}, Kind: "string", Type: &pyType{Kind: "str"}}
+			if typ, ok := jsonOutputs[e.Name]; ok {
+				switch a.Name {
+				case "outJson", "outputJson":
+					out.Kind = "json"
+				case "outResult":
+					out.Kind = "result"
+					if i+2 < len(args) && args[i+2].Type == "unsigned long long *" && args[i+2].Name == "outSize" {
+						out.Args = append(out.Args, args[i+2])
+						i++
+					}
+				}
+				if out.Kind != "string" {
+					t, err := pw.jsonTypeOf(typ)
+					if err != nil {
+						return "", false, err
+					}
+					out.Type = t
+				}
+			}
+			outs = append(outs, out)
+			i++
+		case a.Type == "unsigned int *" || a.Type == "int *" || a.Type == "unsigned long long *":
+			outs = append(outs, &pyOutput{Name: snake(a.Name), Args: []param{a}, Kind: "number", Type: &pyType{Kind: "int"}})
+		default:
+			return "", false, nil
+		}
+	}
+	for _, out := range outs {
+		for _, a := range out.Args {
+			elem := strings.TrimSuffix(ctypesTypes[a.Type], ")")
+			elem = strings.TrimPrefix(elem, "ctypes.POINTER(")
+			prep = append(prep, fmt.Sprintf("%s = %s()", snake(a.Name), elem))
+			callArgs = append(callArgs, "ctypes.byref("+snake(a.Name)+")")
+		}
+	}
+	prep = append(prep, "err, err_len = ctypes.c_void_p(), ctypes.c_uint()")
+	callArgs = append(callArgs, "ctypes.byref(err)", "ctypes.byref(err_len)")
+
+	var results, annotations []string
+	for _, out := range outs {
+		var expr string
+		switch out.Kind {
+		case "string":
+			expr = fmt.Sprintf("_rt.take_string(%s, %s)", snake(out.Args[0].Name), snake(out.Args[1].Name))
+		case "json":
+			expr = fmt.Sprintf("_rt.take_json(%s, %s)", snake(out.Args[0].Name), snake(out.Args[1].Name))
+		case "result":
+			expr = fmt.Sprintf("_rt.take_result(%s, %s)", snake(out.Args[0].Name), snake(out.Args[1].Name))
+		case "number":
+			expr = snake(out.Args[0].Name) + ".value"
+		}
+		if dec := out.Type.decoder(); len(dec) > 0 && out.Kind != "string" {
+			expr = dec + "(" + expr + ")"
+		}
+		results = append(results, expr)
+		annotations = append(annotations, out.Type.annotation())
+	}
+
+	ret := "None"
+	switch len(annotations) {
+	case 0:
+	case 1:
+		ret = annotations[0]
+	default:
+		ret = "Tuple[" + strings.Join(annotations, ", ") + "]"
+	}
+
+	// only the trailing parameters keep their defaults
+	decls := make([]string, len(pyParams))
+	defaults := true
+	for i := len(pyParams) - 1; i >= 0; i-- {
+		defaults = defaults && len(pyParams[i].def) > 0
+		decls[i] = pyParams[i].decl
+		if defaults {
+			decls[i] += " = " + pyParams[i].def
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "def %s(%s) -> %s:\n", pyFuncName(e), strings.Join(decls, ", "), ret)
+	doc := append([]string(nil), e.Doc...)
+	if len(doc) > 0 {
+		doc = append(doc, "")
+	}
+	doc = append(doc, "Wraps "+e.Name+" (raises LibipswError on failure).")
+	b.WriteString("    \"\"\"")
+	for i, line := range doc {
+		if i > 0 && len(line) > 0 {
+			b.WriteString("    ")
+		}
+		b.WriteString(strings.ReplaceAll(line, `"""`, `'''`) + "\n")
+	}
+	b.WriteString("    \"\"\"\n")
+	for _, line := range prep {
+		b.WriteString("    " + line + "\n")
+	}
+	fmt.Fprintf(&b, "    if not _rt.lib().%s(%s):\n", e.Name, strings.Join(callArgs, ", "))
+	b.WriteString("        _rt.raise_error(err, err_len)\n")
+	switch len(results) {
+	case 0:
+	case 1:
+		b.WriteString("    return " + results[0] + "\n")
+	default:
+		b.WriteString("    return " + strings.Join(results, ", ") + "\n")
+	}
+	return b.String(), true, nil
+}
+
+var enumRE = regexp.MustCompile(`(?m)^\s*(LIBIPSW_[A-Z0-9_]+)\s*=\s*(\d+)`)
+
+// generatePython returns the generated _ffi.py (ctypes prototypes, error codes and JSON dataclasses)
+// and _api.py (the wrappers of the exports) of the Python package
+func generatePython(pkgs []*pkg) (ffi, api []byte, err error) {
+	var version string
+	for _, p := range pkgs {
+		if len(p.version) > 0 {
+			version = p.version
+		}
+	}
+	pw := &pyWriter{
+		pkgs:    make(map[string]*pkg),
+		done:    make(map[string]bool),
+		classes: make(map[string]string),
+		fields:  make(map[string]string),
+	}
+	for _, p := range pkgs {
+		pw.pkgs[p.Name] = p
+	}
+
+	var funcs, names []string
+	for _, p := range pkgs {
+		for _, e := range p.Exports {
+			fn, ok, err := pw.pyFunc(e)
+			if err != nil {
+				return nil, nil, fmt.Errorf("%s: %v", e.Name, err)
+			}
+			if ok {
+				funcs = append(funcs, fn)
+				names = append(names, pyFuncName(e))
+			}
+		}
+	}
+	var inputs []string
+	for _, typ := range jsonInputs {
+		inputs = append(inputs, typ)
+	}
+	sort.Strings(inputs)
+	for _, typ := range inputs {
+		if _, err := pw.jsonTypeOf(typ); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	var b bytes.Buffer
+	b.WriteString("# Code generated by cmd/libipsw/gen; DO NOT EDIT.\n")
+	b.WriteString("\"\"\"ctypes prototypes, error codes and JSON types of the libipsw C API\"\"\"\n\n")
+	b.WriteString("import ctypes\nimport enum\nfrom dataclasses import dataclass\nfrom typing import Any, Callable, Dict, List, Optional, Tuple\n\n")
+	fmt.Fprintf(&b, "VERSION = %q\n\n", version)
+
+	// the LIBIPSW_LOG_* levels of c_libipsw_set_log_callback get their own enum
+	var codes, levels []string
+	seen := make(map[string]bool)
+	for _, p := range pkgs {
+		for _, block := range p.Preamble {
+			for _, m := range enumRE.FindAllStringSubmatch(block, -1) {
+				if seen[m[1]] {
+					continue
+				}
+				seen[m[1]] = true
+				if name, ok := strings.CutPrefix(m[1], "LIBIPSW_LOG_"); ok {
+					levels = append(levels, fmt.Sprintf("    %s = %s\n", name, m[2]))
+				} else {
+					name := strings.TrimPrefix(strings.TrimPrefix(m[1], "LIBIPSW_"), "ERR_")
+					codes = append(codes, fmt.Sprintf("    %s = %s\n", name, m[2]))
+				}
+			}
+		}
+	}
+	if len(codes) == 0 {
+		return nil, nil, fmt.Errorf("no LIBIPSW_ error codes found in the preambles")
+	}
+	b.WriteString("\nclass ErrorCode(enum.IntEnum):\n    \"\"\"the stable error codes of c_libipsw_error_code\"\"\"\n\n")
+	b.WriteString(strings.Join(codes, ""))
+	if len(levels) > 0 {
+		b.WriteString("\n\nclass LogLevel(enum.IntEnum):\n    \"\"\"the levels of c_libipsw_set_log_callback\"\"\"\n\n")
+		b.WriteString(strings.Join(levels, ""))
+	}
+
+	b.WriteString("\n\n# argtypes and restype of the exports\nPROTOTYPES = {\n")
+	for _, p := range pkgs {
+		for _, e := range p.Exports {
+			var argtypes []string
+			for _, a := range e.Args {
+				ct, ok := ctypesTypes[a.Type]
+				if !ok {
+					ct = "ctypes.c_void_p" // function pointer typedefs
+				}
+				argtypes = append(argtypes, ct)
+			}
+			restype, ok := ctypesTypes[e.Result]
+			if !ok {
+				return nil, nil, fmt.Errorf("%s: unsupported result type %s", e.Name, e.Result)
+			}
+			fmt.Fprintf(&b, "    %q: (%s, [%s]),\n", e.Name, restype, strings.Join(argtypes, ", "))
+		}
+	}
+	b.WriteString("}\n\n\n")
+	b.WriteString(`def configure(lib: ctypes.CDLL) -> None:
+    """sets the prototypes of the exports on the loaded library"""
+    for name, (restype, argtypes) in PROTOTYPES.items():
+        fn = getattr(lib, name)
+        fn.restype = restype
+        fn.argtypes = argtypes
+
+
+def _list(decode: Callable[[Any], Any]) -> Callable[[Any], Any]:
+    return lambda v: None if v is None else [decode(x) for x in v]
+
+
+def _map(decode: Callable[[Any], Any]) -> Callable[[Any], Any]:
+    return lambda v: None if v is None else {k: decode(x) for k, x in v.items()}
+
+
+def _encode(v: Any) -> Any:
+    if isinstance(v, _Struct):
+        return v._encode()
+    if isinstance(v, (list, tuple)):
+        return [_encode(x) for x in v]
+    if isinstance(v, dict):
+        return {k: _encode(x) for k, x in v.items()}
+    return v
+
+
+class _Struct:
+    """base of the JSON types (_fields are the (attribute, JSON key, decoder, omitempty) of the fields)"""
+
+    _fields: Tuple[Tuple[str, str, Optional[Callable[[Any], Any]], bool], ...] = ()
+
+    @classmethod
+    def _decode(cls, d: Optional[Dict[str, Any]]) -> Any:
+        if d is None:
+            return None
+        kwargs = {}
+        for attr, key, decode, _ in cls._fields:
+            v = d.get(key)
+            kwargs[attr] = decode(v) if decode is not None and v is not None else v
+        return cls(**kwargs)
+
+    def _encode(self) -> Dict[str, Any]:
+        out = {}
+        for attr, key, _, omitempty in self._fields:
+            v = _encode(getattr(self, attr))
+            if v is None or (omitempty and v in ("", 0, False, [], {})):
+                continue
+            out[key] = v
+        return out
+`)
+	for _, class := range pw.order {
+		b.WriteString("\n\n" + pw.classes[class])
+	}
+	b.WriteString("\n")
+	for _, class := range pw.order {
+		b.WriteString("\n" + pw.fields[class])
+	}
+
+	var a bytes.Buffer
+	a.WriteString("# Code generated by cmd/libipsw/gen; DO NOT EDIT.\n")
+	a.WriteString("\"\"\"Python wrappers of the libipsw C exports\"\"\"\n\n")
+	a.WriteString("import ctypes\nfrom typing import Any, Dict, List, Optional, Tuple\n\n")
+	a.WriteString("from . import _runtime as _rt\n")
+	if len(pw.order) > 0 {
+		classes := append([]string(nil), pw.order...)
+		sort.Strings(classes)
+		a.WriteString("from ._ffi import (\n")
+		for _, class := range classes {
+			fmt.Fprintf(&a, "    %s,\n", class)
+		}
+		a.WriteString(")\n")
+	}
+	a.WriteString("from ._ffi import _list, _map  # noqa: F401\n\n")
+	a.WriteString("__all__ = [\n")
+	for _, name := range names {
+		fmt.Fprintf(&a, "    %q,\n", name)
+	}
+	a.WriteString("]\n")
+	for _, fn := range funcs {
+		a.WriteString("\n\n" + fn)
+	}
+
+	return b.Bytes(), a.Bytes(), nil
+}
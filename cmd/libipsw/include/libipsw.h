@@ -0,0 +1,619 @@
+/* Code generated by cmd/libipsw/gen; DO NOT EDIT. */
+
+/*
+ * libipsw - the C API of ipsw
+ *
+ * Build the library with `make build-libipsw` (c-shared and c-archive) and call libipsw_init
+ * before any other function. Strings are passed as a pointer and a length (they do not need a NUL
+ * terminator) and functions returning char report success with 1 and failure with 0.
+ *
+ * All functions can be called from multiple threads at once (after libipsw_init returns): handles,
+ * results and downloads can be used from any thread and every handle has its own HTTP client. The
+ * calls on one c_devportal session are serialized. Free error messages with c_libipsw_free_string
+ * (not free) so that their error code is forgotten before malloc reuses the address.
+ */
+
+#ifndef LIBIPSW_H
+#define LIBIPSW_H
+
+#include <stddef.h>
+
+#define LIBIPSW_VERSION "1.5.0"
+#define LIBIPSW_VERSION_MAJOR 1
+#define LIBIPSW_VERSION_MINOR 5
+#define LIBIPSW_VERSION_PATCH 0
+
+#ifdef __cplusplus
+extern "C" {
+#endif
+
+/*
+ * Error codes
+ *
+ * Every failing call (returning 0) sets err to a message, c_libipsw_error_code(err) returns
+ * the stable numeric code of the failure so that callers do not have to parse the message:
+ *
+ *    0  LIBIPSW_OK                no error (err is NULL)
+ *    1  LIBIPSW_ERR_UNKNOWN       unclassified error
+ *    2  LIBIPSW_ERR_INVALID_ARG   missing/invalid argument (i.e. an unknown handle or download ID)
+ *    3  LIBIPSW_ERR_NOT_FOUND     nothing matched the given device, version or build
+ *    4  LIBIPSW_ERR_NETWORK       connection or HTTP error
+ *    5  LIBIPSW_ERR_PARSE         malformed JSON input or API response
+ *    6  LIBIPSW_ERR_AUTH          authentication or authorization failed
+ *    7  LIBIPSW_ERR_CANCELED      canceled (c_libipsw_cancel or c_internal_download_manager_Cancel)
+ *    8  LIBIPSW_ERR_TIMEOUT       a deadline or timeout expired
+ *    9  LIBIPSW_ERR_CHECKSUM      checksum verification failed
+ *   10  LIBIPSW_ERR_UNSUPPORTED   not supported on this platform
+ *
+ * Codes are never renumbered (new ones are only appended). The code must be read before the
+ * message is freed with c_libipsw_free_string.
+ */
+
+enum {
+	LIBIPSW_OK = 0,
+	LIBIPSW_ERR_UNKNOWN = 1,
+	LIBIPSW_ERR_INVALID_ARG = 2,
+	LIBIPSW_ERR_NOT_FOUND = 3,
+	LIBIPSW_ERR_NETWORK = 4,
+	LIBIPSW_ERR_PARSE = 5,
+	LIBIPSW_ERR_AUTH = 6,
+	LIBIPSW_ERR_CANCELED = 7,
+	LIBIPSW_ERR_TIMEOUT = 8,
+	LIBIPSW_ERR_CHECKSUM = 9,
+	LIBIPSW_ERR_UNSUPPORTED = 10,
+};
+
+enum {
+	LIBIPSW_LOG_DEBUG = 0,
+	LIBIPSW_LOG_INFO = 1,
+	LIBIPSW_LOG_WARN = 2,
+	LIBIPSW_LOG_ERROR = 3,
+	LIBIPSW_LOG_FATAL = 4,
+};
+
+typedef void (*ipsw_log_cb)(int level, const char *message, const char *fields_json, void *user_data);
+
+typedef void (*ipsw_progress_cb)(const char *id, long long done, long long total, double speed, long long eta_ms, void *user_data);
+
+/*
+ * Memory ownership
+ *
+ * Every char* returned through an out parameter (outJson, outID, outHandle, outResult, err, ...)
+ * is allocated by libipsw with malloc and is owned by the caller: release it with
+ * c_libipsw_free_string once it has been read (NULL is ignored). The matching *Len out
+ * parameter is the length of the string without its NUL terminator.
+ *
+ * Out parameters are only set when the function says so: on success (1) the outputs are set
+ * and err is not, on failure (0) only err is set.
+ *
+ * Buffers passed in by the caller (i.e. to c_libipsw_result_read) stay owned by the caller,
+ * and strings passed to callbacks (i.e. the id of ipsw_progress_cb) are only valid for the
+ * duration of the call.
+ *
+ * Handles and result IDs are strings too (free them with c_libipsw_free_string), but the state
+ * they refer to is released separately with c_libipsw_handle_free and c_libipsw_result_free.
+ */
+
+/* internal/download */
+
+/*
+ * c_devportal_new opens a dev portal session (an empty config uses the defaults); free it with c_devportal_free
+ *
+ * JSON argument: download.DevSessionConfig (see the schemas at the end)
+ */
+extern char c_devportal_new(char *configJson, unsigned int configJsonLen, char **outHandle, unsigned int *outHandleLen, char **err, unsigned int *errLen);
+
+/*
+ * c_devportal_login logs in to the dev portal (empty credentials are read from the session's vault)
+ */
+extern char c_devportal_login(char *handle, unsigned int handleLen, char *username, unsigned int usernameLen, char *password, unsigned int passwordLen, char **err, unsigned int *errLen);
+
+/*
+ * c_devportal_list_downloads lists the downloads of a type (os or more, empty for both) of a logged in session
+ *
+ * Returns JSON: download.DevDownloads (see the schemas at the end)
+ */
+extern char c_devportal_list_downloads(char *handle, unsigned int handleLen, char *downloadType, unsigned int downloadTypeLen, char **outJson, unsigned int *outJsonLen, char **err, unsigned int *errLen);
+
+/*
+ * c_devportal_download downloads a dev portal URL (i.e. a url from c_devportal_list_downloads) into folder
+ */
+extern char c_devportal_download(char *handle, unsigned int handleLen, char *url, unsigned int urlLen, char *folder, unsigned int folderLen, char **err, unsigned int *errLen);
+
+/*
+ * c_devportal_free releases a dev portal session handle
+ */
+extern void c_devportal_free(char *handle, unsigned int handleLen);
+
+/*
+ * c_libipsw_call calls a library method by name (i.e. ipsw_me.device, see libipsw.methods for the list)
+ * with paramsJson, a JSON object of its parameters (empty for none), and returns its JSON result; pass a
+ * handle of c_libipsw_handle_new to cancel the call with c_libipsw_cancel (empty for none). An unknown
+ * method fails with LIBIPSW_ERR_UNSUPPORTED.
+ *
+ * Returns JSON: any value (it depends on the arguments)
+ */
+extern char c_libipsw_call(char *handle, unsigned int handleLen, char *method, unsigned int methodLen, char *paramsJson, unsigned int paramsJsonLen, char **outJson, unsigned int *outJsonLen, char **err, unsigned int *errLen);
+
+/*
+ * c_internal_download_Download downloads url to dest (resuming a partial download) and blocks until it is done;
+ * the checksums are optional and the download's Stats are returned as JSON
+ * (use c_internal_download_manager_Add to download in the background)
+ *
+ * Returns JSON: download.Stats (see the schemas at the end)
+ */
+extern char c_internal_download_Download(char *url, unsigned int urlLen, char *dest, unsigned int destLen, char *sha1, unsigned int sha1Len, char *sha256, unsigned int sha256Len, char *proxy, unsigned int proxyLen, char insecure, char **outJson, unsigned int *outJsonLen, char **err, unsigned int *errLen);
+
+/*
+ * c_internal_download_DownloadWithHandle is c_internal_download_Download that can be canceled with c_libipsw_cancel
+ *
+ * Returns JSON: download.Stats (see the schemas at the end)
+ */
+extern char c_internal_download_DownloadWithHandle(char *handle, unsigned int handleLen, char *url, unsigned int urlLen, char *dest, unsigned int destLen, char *sha1, unsigned int sha1Len, char *sha256, unsigned int sha256Len, char *proxy, unsigned int proxyLen, char insecure, char **outJson, unsigned int *outJsonLen, char **err, unsigned int *errLen);
+
+/*
+ * c_libipsw_error_code returns the error code (see the LIBIPSW_ERR_* constants) of an error message set by a libipsw function
+ */
+extern int c_libipsw_error_code(char *err);
+
+/*
+ * c_libipsw_error_code_name returns the name of an error code (i.e. "not-found"); free it with c_libipsw_free_string
+ */
+extern char *c_libipsw_error_code_name(int code);
+
+/*
+ * c_libipsw_handle_new returns a handle to pass to the *WithHandle functions so that
+ * the operation can be canceled from another thread with c_libipsw_cancel
+ */
+extern char c_libipsw_handle_new(char **outHandle, unsigned int *outHandleLen);
+
+/*
+ * c_libipsw_cancel cancels the in-flight operation of a handle (or the download of a download manager ID);
+ * the canceled call returns an error once its requests are aborted
+ */
+extern char c_libipsw_cancel(char *handle, unsigned int handleLen, char **err, unsigned int *errLen);
+
+/*
+ * c_libipsw_handle_trace returns the correlation ID of a handle's operation (it is included in the
+ * operation's log lines and error messages)
+ */
+extern char c_libipsw_handle_trace(char *handle, unsigned int handleLen, char **outTrace, unsigned int *outTraceLen, char **err, unsigned int *errLen);
+
+/*
+ * c_libipsw_handle_free releases a handle once its operation has returned
+ */
+extern void c_libipsw_handle_free(char *handle, unsigned int handleLen);
+
+/*
+ * Returns JSON: download.Device (see the schemas at the end)
+ */
+extern char c_internal_download_ipsw_me_GetDeviceWithHandle(char *handle, unsigned int handleLen, char *identifier, unsigned int identifierLen, char **outJson, unsigned int *outJsonLen, char **err, unsigned int *errLen);
+
+/*
+ * JSON argument: download.WikiConfig (see the schemas at the end)
+ * Returns JSON: []download.WikiFirmware (see the schemas at the end)
+ */
+extern char c_internal_download_iphonewiki_GetWikiIPSWs(char *configJson, int configJsonLen, char *proxy, int proxyLen, char insecure, char **outputJson, int *outputJsonLen, char **err, unsigned int *errLen);
+
+/*
+ * Returns JSON: []download.Device (see the schemas at the end)
+ */
+extern char c_internal_download_ipsw_me_GetAllDevices(char **outJson, unsigned int *outJsonLen, char **err, unsigned int *errLen);
+
+/*
+ * Returns JSON: download.Device (see the schemas at the end)
+ */
+extern char c_internal_download_ipsw_me_GetDevice(char *identifier, unsigned int identifierLen, char **outJson, unsigned int *outJsonLen, char **err, unsigned int *errLen);
+
+/*
+ * Returns JSON: []download.IPSW (see the schemas at the end)
+ */
+extern char c_internal_download_ipsw_me_GetDeviceIPSWs(char *identifier, unsigned int identifierLen, char **outJson, unsigned int *outJsonLen, char **err, unsigned int *errLen);
+
+/*
+ * c_internal_download_ipsw_me_GetDeviceOTAs returns the OTAs of a device (i.e. iPhone16,1) from ipsw.me
+ *
+ * Returns JSON: []download.IPSW (see the schemas at the end)
+ */
+extern char c_internal_download_ipsw_me_GetDeviceOTAs(char *identifier, unsigned int identifierLen, char **outJson, unsigned int *outJsonLen, char **err, unsigned int *errLen);
+
+/*
+ * Returns JSON: []download.IPSW (see the schemas at the end)
+ */
+extern char c_internal_download_ipsw_me_GetAllIPSW(char *version, unsigned int versionLen, char **outJson, unsigned int *outJsonLen, char **err, unsigned int *errLen);
+
+/*
+ * Returns JSON: download.IPSW (see the schemas at the end)
+ */
+extern char c_internal_download_ipsw_me_GetIPSW(char *identifier, unsigned int identifierLen, char *buildID, unsigned int buildIDLen, char **outJson, unsigned int *outJsonLen, char **err, unsigned int *errLen);
+
+/*
+ * c_internal_download_ipsw_me_GetVersion returns the version as a JSON string (i.e. "17.0")
+ *
+ * Returns JSON: string (see the schemas at the end)
+ */
+extern char c_internal_download_ipsw_me_GetVersion(char *buildID, unsigned int buildIDLen, char **outJson, unsigned int *outJsonLen, char **err, unsigned int *errLen);
+
+/*
+ * c_internal_download_ipsw_me_GetBuildID returns the build ID as a JSON string (i.e. "21A329")
+ *
+ * Returns JSON: string (see the schemas at the end)
+ */
+extern char c_internal_download_ipsw_me_GetBuildID(char *version, unsigned int versionLen, char *identifier, unsigned int identifierLen, char **outJson, unsigned int *outJsonLen, char **err, unsigned int *errLen);
+
+/*
+ * c_download_start downloads url to dest (the URL's file name in the current directory if empty) in the background;
+ * the checksums are optional and the job handle must be released with c_download_free
+ */
+extern char c_download_start(char *url, unsigned int urlLen, char *dest, unsigned int destLen, char *sha1, unsigned int sha1Len, char *sha256, unsigned int sha256Len, char *proxy, unsigned int proxyLen, char insecure, char **outJob, unsigned int *outJobLen, char **err, unsigned int *errLen);
+
+/*
+ * c_download_status returns the status of a download job
+ *
+ * Returns JSON: download.DownloadStatus (see the schemas at the end)
+ */
+extern char c_download_status(char *job, unsigned int jobLen, char **outJson, unsigned int *outJsonLen, char **err, unsigned int *errLen);
+
+/*
+ * c_download_cancel cancels a download job (canceling a finished job does nothing); it returns right away
+ * and the job's state becomes canceled once its transfer is aborted
+ */
+extern char c_download_cancel(char *job, unsigned int jobLen, char **err, unsigned int *errLen);
+
+/*
+ * c_download_free releases a download job handle
+ */
+extern void c_download_free(char *job, unsigned int jobLen);
+
+/*
+ * c_libipsw_set_log_callback sends the log lines at or above level (a LIBIPSW_LOG_* constant) to cb instead
+ * of stderr (NULL restores stderr); fields_json is a JSON object of the line's fields (i.e. the trace ID),
+ * both strings are only valid for the duration of the call and cb can be called from any thread
+ */
+extern void c_libipsw_set_log_callback(ipsw_log_cb cb, int level, void *userData);
+
+extern char c_internal_download_manager_Add(char *url, unsigned int urlLen, char *dest, unsigned int destLen, char *sha1, unsigned int sha1Len, char *proxy, unsigned int proxyLen, char insecure, char **outID, unsigned int *outIDLen, char **err, unsigned int *errLen);
+
+extern char c_internal_download_manager_Pause(char *id, unsigned int idLen, char **err, unsigned int *errLen);
+
+extern char c_internal_download_manager_Resume(char *id, unsigned int idLen, char **err, unsigned int *errLen);
+
+/*
+ * c_internal_download_manager_SetProgressCallback calls cb with the progress of the download
+ * (bytes done, total bytes, bytes per second and the ETA in milliseconds) at most every 250ms;
+ * user_data is passed back to cb as is and the id is only valid for the duration of the call
+ */
+extern char c_internal_download_manager_SetProgressCallback(char *id, unsigned int idLen, ipsw_progress_cb cb, void *userData, char **err, unsigned int *errLen);
+
+extern char c_internal_download_manager_Cancel(char *id, unsigned int idLen, char **err, unsigned int *errLen);
+
+/*
+ * Returns JSON: download.DownloadStatus (see the schemas at the end)
+ */
+extern char c_internal_download_manager_Status(char *id, unsigned int idLen, char **outJson, unsigned int *outJsonLen, char **err, unsigned int *errLen);
+
+/*
+ * Returns JSON: []download.DownloadStatus (see the schemas at the end)
+ */
+extern char c_internal_download_manager_List(char **outJson, unsigned int *outJsonLen, char **err, unsigned int *errLen);
+
+/*
+ * c_libipsw_free_string frees a string returned by a libipsw function
+ */
+extern void c_libipsw_free_string(char *s);
+
+/*
+ * c_libipsw_free_buffer frees a buffer allocated by libipsw (for bindings that
+ * track the returned strings as raw byte buffers)
+ */
+extern void c_libipsw_free_buffer(void *buf);
+
+extern char c_libipsw_result_size(char *result, unsigned int resultLen, unsigned long long *outSize, char **err, unsigned int *errLen);
+
+/*
+ * c_libipsw_result_read copies up to bufLen bytes of the result's JSON into buf (buf is not NUL terminated)
+ */
+extern char c_libipsw_result_read(char *result, unsigned int resultLen, char *buf, unsigned int bufLen, unsigned int *outN, char **err, unsigned int *errLen);
+
+extern void c_libipsw_result_free(char *result, unsigned int resultLen);
+
+/*
+ * Returns JSON: []download.Device (see the schemas at the end)
+ */
+extern char c_internal_download_ipsw_me_GetAllDevicesResult(char **outResult, unsigned int *outResultLen, unsigned long long *outSize, char **err, unsigned int *errLen);
+
+/*
+ * Returns JSON: []download.IPSW (see the schemas at the end)
+ */
+extern char c_internal_download_ipsw_me_GetDeviceIPSWsResult(char *identifier, unsigned int identifierLen, char **outResult, unsigned int *outResultLen, unsigned long long *outSize, char **err, unsigned int *errLen);
+
+/*
+ * Returns JSON: []download.IPSW (see the schemas at the end)
+ */
+extern char c_internal_download_ipsw_me_GetAllIPSWResult(char *version, unsigned int versionLen, char **outResult, unsigned int *outResultLen, unsigned long long *outSize, char **err, unsigned int *errLen);
+
+/*
+ * JSON argument: download.WikiConfig (see the schemas at the end)
+ * Returns JSON: []download.WikiFirmware (see the schemas at the end)
+ */
+extern char c_internal_download_iphonewiki_GetWikiIPSWsResult(char *configJson, int configJsonLen, char *proxy, int proxyLen, char insecure, char **outResult, unsigned int *outResultLen, unsigned long long *outSize, char **err, unsigned int *errLen);
+
+/*
+ * c_libipsw_version returns the version of the library as JSON: the ipsw module version, the C API
+ * version, the git commit, the Go version and the capabilities of c_libipsw_capabilities
+ *
+ * Returns JSON: download.BuildInfo (see the schemas at the end)
+ */
+extern char c_libipsw_version(char **outJson, unsigned int *outJsonLen, char **err, unsigned int *errLen);
+
+/*
+ * c_libipsw_capabilities returns what the library supports as JSON: its sources (i.e. ipsw.me,
+ * developer.apple.com) and whether it was built with cgo or for wasm
+ *
+ * Returns JSON: download.Capabilities (see the schemas at the end)
+ */
+extern char c_libipsw_capabilities(char **outJson, unsigned int *outJsonLen, char **err, unsigned int *errLen);
+
+/*
+ * Returns JSON: []xcode.Device (see the schemas at the end)
+ */
+extern char c_pkg_xcode_xcode_GetDevices(char **outJson, unsigned int *outJsonLen, char **err, unsigned int *errLen);
+
+/*
+ * c_pkg_xcode_xcode_GetDeviceForProd returns the device with a product type (i.e. iPhone16,1)
+ *
+ * Returns JSON: xcode.Device (see the schemas at the end)
+ */
+extern char c_pkg_xcode_xcode_GetDeviceForProd(char *prod, unsigned int prodLen, char **outJson, unsigned int *outJsonLen, char **err, unsigned int *errLen);
+
+/*
+ * c_pkg_xcode_xcode_GetDeviceForModel returns the device with a model (i.e. A2848)
+ *
+ * Returns JSON: xcode.Device (see the schemas at the end)
+ */
+extern char c_pkg_xcode_xcode_GetDeviceForModel(char *model, unsigned int modelLen, char **outJson, unsigned int *outJsonLen, char **err, unsigned int *errLen);
+
+/*
+ * c_pkg_xcode_xcode_GetDeviceForBoard returns the device with a board config (i.e. D83AP or d83)
+ *
+ * Returns JSON: xcode.Device (see the schemas at the end)
+ */
+extern char c_pkg_xcode_xcode_GetDeviceForBoard(char *board, unsigned int boardLen, char **outJson, unsigned int *outJsonLen, char **err, unsigned int *errLen);
+
+/*
+ * c_pkg_xcode_xcode_GetDevicesForIdiom returns the devices of an idiom (phone, pad, watch or tv)
+ *
+ * Returns JSON: []xcode.Device (see the schemas at the end)
+ */
+extern char c_pkg_xcode_xcode_GetDevicesForIdiom(char *idiom, unsigned int idiomLen, char **outJson, unsigned int *outJsonLen, char **err, unsigned int *errLen);
+
+/*
+ * c_pkg_xcode_xcode_GetDevicesForChip returns the devices with a chip (i.e. t8130)
+ *
+ * Returns JSON: []xcode.Device (see the schemas at the end)
+ */
+extern char c_pkg_xcode_xcode_GetDevicesForChip(char *chip, unsigned int chipLen, char **outJson, unsigned int *outJsonLen, char **err, unsigned int *errLen);
+
+/* cmd/libipsw */
+
+/*
+ * libipsw_init initializes the library; call it before any other function (calling it again does nothing).
+ * Only warnings and errors are logged (to stderr) unless $LIBIPSW_LOG_LEVEL is set (i.e. debug, info)
+ * or a log callback is set with c_libipsw_set_log_callback, and no progress bars are rendered (use the
+ * progress callbacks).
+ */
+extern char libipsw_init(void);
+
+/*
+ * libipsw_shutdown cancels the in-flight operations and downloads and releases the handles and results;
+ * call it before unloading the library
+ */
+extern void libipsw_shutdown(void);
+
+/*
+ * libipsw_version returns the version of the C API (LIBIPSW_VERSION of the header the library was built with);
+ * free it with c_libipsw_free_string (c_libipsw_version also returns the git commit and capabilities)
+ */
+extern char *libipsw_version(void);
+
+/*
+ * JSON schemas
+ *
+ * Fields marked with ? are omitted when empty, times are RFC 3339 strings and
+ * durations are numbers of nanoseconds.
+ *
+ * download.Device {
+ *   "name"?: string
+ *   "identifier"?: string
+ *   "boardconfig"?: string
+ *   "platform"?: string
+ *   "cpid"?: number
+ *   "bdid"?: number
+ *   "firmwares"?: [download.IPSW]
+ * }
+ *
+ * download.IPSW {
+ *   "identifier"?: string
+ *   "version"?: string
+ *   "buildid"?: string
+ *   "sha1sum"?: string
+ *   "md5sum"?: string
+ *   "filesize"?: number
+ *   "url"?: string
+ *   "releasedate"?: string (time)
+ *   "uploaddate"?: string (time)
+ *   "signed"?: boolean
+ * }
+ *
+ * download.DownloadStatus {
+ *   "id"?: string
+ *   "url"?: string
+ *   "dest"?: string
+ *   "state"?: string
+ *   "size"?: number
+ *   "downloaded"?: number
+ *   "error"?: string
+ *   "stats"?: download.Stats
+ * }
+ *
+ * download.Stats {
+ *   "url"?: string
+ *   "host"?: string
+ *   "dest"?: string
+ *   "state"?: string
+ *   "error"?: string
+ *   "started"?: string (time)
+ *   "duration"?: number (duration)
+ *   "active"?: number (duration)
+ *   "bytes": number
+ *   "resumed_bytes": number
+ *   "retries": number
+ *   "avg_speed": number
+ *   "peak_speed": number
+ *   "verification"?: download.Verification
+ *   "trace"?: string
+ * }
+ *
+ * download.Verification {
+ *   "algorithm": string
+ *   "expected": string
+ *   "actual": string
+ *   "verified": boolean
+ *   "attempts": number
+ * }
+ *
+ * download.WikiFirmware {
+ *   "version"?: string
+ *   "version_extra"?: string
+ *   "prerequisite_version"?: string
+ *   "build"?: string
+ *   "prerequisite_build"?: string
+ *   "product"?: string
+ *   "board_id"?: string
+ *   "keys"?: [string]
+ *   "baseband"?: string
+ *   "release_date"?: string (time)
+ *   "url"?: string
+ *   "sha1"?: string
+ *   "file_size"?: number
+ *   "doc"?: [string]
+ * }
+ *
+ * xcode.Device {
+ *   "target"?: string
+ *   "target_type"?: string
+ *   "target_variant"?: string
+ *   "platform"?: string
+ *   "product_type"?: string
+ *   "product_description"?: string
+ *   "compatible_device_fallback"?: string
+ *   "traits"?: xcode.DeviceTrait
+ * }
+ *
+ * xcode.DeviceTrait {
+ *   "preferred_architecture"?: string
+ *   "artwork_device_idiom"?: string
+ *   "artwork_hosted_idioms"?: string
+ *   "artwork_scale_factor"?: number
+ *   "artwork_device_subtype"?: number
+ *   "artwork_display_gamut"?: string
+ *   "artwork_dynamic_display_mode"?: string
+ *   "device_performance_memory_class"?: number
+ *   "graphics_feature_set_class"?: string
+ *   "graphics_feature_set_fallbacks"?: string
+ * }
+ *
+ * download.BuildInfo {
+ *   "version": string
+ *   "c_api_version": string
+ *   "commit"?: string
+ *   "modified"?: boolean
+ *   "go_version": string
+ *   "capabilities": download.Capabilities
+ * }
+ *
+ * download.Capabilities {
+ *   "sources": [string]
+ *   "cgo": boolean
+ *   "wasm": boolean
+ *   "os": string
+ *   "arch": string
+ * }
+ *
+ * download.DevDownloads {
+ *   "os"?: {string: [download.DevDownload]}
+ *   "more"?: [download.MoreDownload]
+ * }
+ *
+ * download.DevDownload {
+ *   "title"?: string
+ *   "build"?: string
+ *   "url"?: string
+ *   "type"?: string
+ * }
+ *
+ * download.MoreDownload {
+ *   "name"?: string
+ *   "description"?: string
+ *   "isReleased"?: number
+ *   "datePublished"?: string
+ *   "dateCreated"?: string
+ *   "dateModified"?: string
+ *   "categories"?: [download.category]
+ *   "files"?: [download.dfile]
+ * }
+ *
+ * download.category {
+ *   "id"?: number
+ *   "name"?: string
+ *   "sortOrder"?: number
+ * }
+ *
+ * download.dfile {
+ *   "filename"?: string
+ *   "displayName"?: string
+ *   "remotePath"?: string
+ *   "fileSize"?: number
+ *   "sortOrder"?: number
+ *   "dateCreated"?: string
+ *   "dateModified"?: string
+ *   "fileFormat"?: download.fformat
+ * }
+ *
+ * download.fformat {
+ *   "extension"?: string
+ *   "description"?: string
+ * }
+ *
+ * download.DevSessionConfig {
+ *   "proxy"?: string
+ *   "insecure"?: boolean
+ *   "config_dir"?: string
+ *   "vault_password"?: string
+ *   "keyring_backend"?: string
+ *   "account"?: string
+ *   "trust_token"?: string
+ *   "cache_ttl"?: string
+ *   "refresh_cache"?: boolean
+ *   "skip_all"?: boolean
+ *   "resume_all"?: boolean
+ *   "restart_all"?: boolean
+ *   "remove_commas"?: boolean
+ *   "prefer_sms"?: boolean
+ * }
+ *
+ * download.WikiConfig {
+ *   "Device": string
+ *   "Version": string
+ *   "Build": string
+ *   "IPSW": boolean
+ *   "OTA": boolean
+ *   "Beta": boolean
+ * }
+ */
+
+#ifdef __cplusplus
+}
+#endif
+
+#endif /* LIBIPSW_H */
@@ -0,0 +1,80 @@
+//go:build ffi
+
+/*
+Copyright © 2024 blacktop
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+// Command libipsw is the entrypoint of the libipsw C library (build it with -tags ffi and -buildmode=c-shared
+// or c-archive; without the ffi tag the packages do not export the C API and so do not need cgo);
+// include/libipsw.h is generated from the exported functions with go generate -tags ffi.
+package main
+
+//#include <stdlib.h>
+import "C"
+
+import (
+	"os"
+	"sync"
+
+	"github.com/apex/log"
+	"github.com/blacktop/ipsw/internal/download"
+	"github.com/blacktop/ipsw/internal/utils"
+)
+
+//go:generate go run ./gen -o include/libipsw.h -py ../../python/libipsw ../../internal/download ../../pkg/xcode .
+
+var initOnce sync.Once
+
+// libipsw_init initializes the library; call it before any other function (calling it again does nothing).
+// Only warnings and errors are logged (to stderr) unless $LIBIPSW_LOG_LEVEL is set (i.e. debug, info)
+// or a log callback is set with c_libipsw_set_log_callback, and no progress bars are rendered (use the
+// progress callbacks).
+//
+//export libipsw_init
+func libipsw_init() C.char {
+	initOnce.Do(func() {
+		level := log.WarnLevel
+		if lvl, err := log.ParseLevel(os.Getenv("LIBIPSW_LOG_LEVEL")); err == nil {
+			level = lvl
+		}
+		log.SetLevel(level)
+		utils.Plain = true // the host app renders progress with the progress callbacks
+	})
+	return C.char(1)
+}
+
+// libipsw_shutdown cancels the in-flight operations and downloads and releases the handles and results;
+// call it before unloading the library
+//
+//export libipsw_shutdown
+func libipsw_shutdown() {
+	download.Shutdown()
+}
+
+// libipsw_version returns the version of the C API (LIBIPSW_VERSION of the header the library was built with);
+// free it with c_libipsw_free_string (c_libipsw_version also returns the git commit and capabilities)
+//
+//export libipsw_version
+func libipsw_version() *C.char {
+	return C.CString(download.CAPIVersion)
+}
+
+func main() {}
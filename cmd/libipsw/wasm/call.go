@@ -0,0 +1,63 @@
+//go:build wasm
+
+/*
+Copyright © 2024 blacktop
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+// Command wasm is the WebAssembly build of libipsw: the metadata lookups (devices, firmwares and their
+// signing status) of the Call methods, for web frontends (GOOS=js) and WASI hosts (GOOS=wasip1).
+//
+// The js build sets globalThis.libipsw:
+//
+//	libipsw.call(method, params?) returns a Promise of the method's result (params and the result are
+//	                              JSON strings); it is rejected with an Error whose code is a LIBIPSW_ERR_*
+//	libipsw.methods()             returns the method names
+//	libipsw.version()             returns the BuildInfo JSON
+//
+// The wasip1 build calls the method of its arguments (i.e. `ipsw_me.device '{"identifier":"iPhone16,1"}'`)
+// or, without arguments, reads one {"id", "method", "params"} JSON request per line on stdin and writes
+// one {"id", "result"} or {"id", "error"} line per request on stdout; the host must provide the
+// libipsw.fetch and libipsw.fetch_read functions (see internal/download/fetch_wasip1.go).
+package main
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/blacktop/ipsw/internal/download"
+)
+
+// callError is the error of a failed call
+type callError struct {
+	Code    download.ErrorCode `json:"code"`
+	Name    string             `json:"name"`
+	Message string             `json:"message"`
+}
+
+// call calls a method and returns its JSON result or its callError
+func call(ctx context.Context, method string, params []byte) (json.RawMessage, *callError) {
+	out, err := download.Call(ctx, method, params)
+	if err != nil {
+		code := download.ErrorCodeOf(err)
+		return nil, &callError{Code: code, Name: code.String(), Message: err.Error()}
+	}
+	return out, nil
+}
@@ -0,0 +1,82 @@
+//go:build js && wasm
+
+/*
+Copyright © 2024 blacktop
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"syscall/js"
+
+	"github.com/blacktop/ipsw/internal/download"
+)
+
+func main() {
+	js.Global().Set("libipsw", js.ValueOf(map[string]any{
+		"call":    js.FuncOf(jsCall),
+		"methods": js.FuncOf(jsMethods),
+		"version": js.FuncOf(jsVersion),
+	}))
+	select {} // the functions are called until the page is closed
+}
+
+// jsCall returns a Promise of the result of call(method, params); the call runs on its own goroutine
+// as the fetch requests need the event loop
+func jsCall(_ js.Value, args []js.Value) any {
+	var method, params string
+	if len(args) > 0 {
+		method = args[0].String()
+	}
+	if len(args) > 1 && args[1].Type() == js.TypeString {
+		params = args[1].String()
+	}
+	return js.Global().Get("Promise").New(js.FuncOf(func(_ js.Value, cb []js.Value) any {
+		resolve, reject := cb[0], cb[1]
+		go func() {
+			out, cerr := call(context.Background(), method, []byte(params))
+			if cerr != nil {
+				err := js.Global().Get("Error").New(cerr.Message)
+				err.Set("code", int(cerr.Code))
+				err.Set("codeName", cerr.Name)
+				reject.Invoke(err)
+				return
+			}
+			resolve.Invoke(string(out))
+		}()
+		return nil
+	}))
+}
+
+func jsMethods(js.Value, []js.Value) any {
+	var names []any
+	for _, name := range download.Methods() {
+		names = append(names, name)
+	}
+	return js.ValueOf(names)
+}
+
+func jsVersion(js.Value, []js.Value) any {
+	out, _ := json.Marshal(download.Version())
+	return string(out)
+}
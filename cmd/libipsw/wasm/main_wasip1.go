@@ -0,0 +1,88 @@
+//go:build wasip1
+
+/*
+Copyright © 2024 blacktop
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/blacktop/ipsw/internal/download"
+)
+
+// request is a call read from stdin
+type request struct {
+	ID     json.RawMessage `json:"id,omitempty"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params,omitempty"`
+}
+
+// response is the result (or error) of a request written to stdout
+type response struct {
+	ID     json.RawMessage `json:"id,omitempty"`
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  *callError      `json:"error,omitempty"`
+}
+
+func main() {
+	ctx := context.Background()
+	if len(os.Args) > 1 {
+		var params []byte
+		if len(os.Args) > 2 {
+			params = []byte(os.Args[2])
+		}
+		out, cerr := call(ctx, os.Args[1], params)
+		if cerr != nil {
+			fmt.Fprintf(os.Stderr, "error %d (%s): %s\n", cerr.Code, cerr.Name, cerr.Message)
+			os.Exit(1)
+		}
+		fmt.Println(string(out))
+		return
+	}
+
+	scanner := bufio.NewScanner(os.Stdin)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	enc := json.NewEncoder(os.Stdout)
+	for scanner.Scan() {
+		var req request
+		var resp response
+		if err := json.Unmarshal(scanner.Bytes(), &req); err != nil {
+			resp.Error = &callError{Code: download.ErrCodeParse, Name: download.ErrCodeParse.String(), Message: fmt.Sprintf("failed to parse request: %v", err)}
+		} else {
+			resp.ID = req.ID
+			resp.Result, resp.Error = call(ctx, req.Method, req.Params)
+		}
+		if err := enc.Encode(resp); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to write response: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to read requests: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -18,6 +18,7 @@ import (
 	"github.com/blacktop/go-plist"
 	"github.com/blacktop/ipsw/internal/download"
 	"github.com/blacktop/ipsw/internal/utils"
+	"github.com/blacktop/ipsw/pkg/aea"
 	"github.com/blacktop/ipsw/pkg/dyld"
 	"github.com/blacktop/ipsw/pkg/img4"
 	"github.com/blacktop/ipsw/pkg/info"
@@ -52,6 +53,8 @@ type Config struct {
 	Progress bool `json:"progress,omitempty"`
 	// output directory to write extracted files to
 	Output string `json:"output,omitempty"`
+	// normalize the timestamps, permissions and order of the packed files (see Reproducible)
+	Reproducible bool `json:"reproducible,omitempty"`
 }
 
 func isURL(str string) bool {
@@ -308,7 +311,29 @@ func DMG(c *Config) ([]string, error) {
 		}
 	}
 
-	return utils.SearchZip(zr.File, regexp.MustCompile(dmgPath), filepath.Join(filepath.Clean(c.Output), folder), c.Flatten, c.Progress)
+	out, err := utils.SearchZip(zr.File, regexp.MustCompile(dmgPath), filepath.Join(filepath.Clean(c.Output), folder), c.Flatten, c.Progress)
+	if err != nil {
+		return nil, err
+	}
+
+	// iOS 18+ DMGs are AEA encrypted
+	for idx, fname := range out {
+		if filepath.Ext(fname) != ".aea" {
+			continue
+		}
+		dec, err := aea.Decrypt(&aea.DecryptConfig{
+			Input:    fname,
+			Proxy:    c.Proxy,
+			Insecure: c.Insecure,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt %s: %v", fname, err)
+		}
+		os.Remove(fname)
+		out[idx] = dec
+	}
+
+	return out, nil
 }
 
 // Keybags extracts the keybags from an IPSW
@@ -0,0 +1,258 @@
+package extract
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/apex/log"
+	"github.com/blacktop/ipsw/internal/utils"
+	"github.com/ulikunitz/xz"
+)
+
+// PackFormats are the archive extensions supported by Pack
+var PackFormats = []string{".tar", ".tar.gz", ".tgz", ".tar.xz", ".txz", ".tar.zst", ".tzst", ".zip"}
+
+// archiveWriter writes entries to a tar or zip archive
+type archiveWriter interface {
+	Add(name string, mode os.FileMode, mtime time.Time, size int64, r io.Reader) error
+	Close() error
+}
+
+type tarWriter struct {
+	tw       *tar.Writer
+	closers  []io.Closer // compressors (or the pipe to zstd), closed in order after the tar writer
+	cmd      *exec.Cmd   // external compressor (zstd)
+	f        *os.File
+	finished bool
+}
+
+func (t *tarWriter) Add(name string, mode os.FileMode, mtime time.Time, size int64, r io.Reader) error {
+	if err := t.tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     name,
+		Mode:     int64(mode.Perm()),
+		Size:     size,
+		ModTime:  mtime,
+		Format:   tar.FormatPAX,
+	}); err != nil {
+		return err
+	}
+	_, err := io.Copy(t.tw, r)
+	return err
+}
+
+func (t *tarWriter) Close() error {
+	if t.finished {
+		return nil
+	}
+	t.finished = true
+	err := t.tw.Close()
+	for _, c := range t.closers {
+		if cerr := c.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}
+	if t.cmd != nil {
+		if werr := t.cmd.Wait(); werr != nil && err == nil {
+			err = fmt.Errorf("zstd failed: %v", werr)
+		}
+	}
+	if cerr := t.f.Close(); cerr != nil && err == nil {
+		err = cerr
+	}
+	return err
+}
+
+type zipWriter struct {
+	zw *zip.Writer
+	f  *os.File
+}
+
+func (z *zipWriter) Add(name string, mode os.FileMode, mtime time.Time, size int64, r io.Reader) error {
+	hdr := &zip.FileHeader{
+		Name:     name,
+		Method:   zip.Deflate,
+		Modified: mtime,
+	}
+	hdr.SetMode(mode)
+	w, err := z.zw.CreateHeader(hdr)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(w, r)
+	return err
+}
+
+func (z *zipWriter) Close() error {
+	err := z.zw.Close()
+	if cerr := z.f.Close(); cerr != nil && err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// newArchiveWriter creates the archive at out with the format of its extension (see PackFormats)
+func newArchiveWriter(out string) (archiveWriter, error) {
+	lower := strings.ToLower(out)
+	var format string
+	for _, ext := range PackFormats {
+		if strings.HasSuffix(lower, ext) && len(ext) > len(format) {
+			format = ext
+		}
+	}
+	if len(format) == 0 {
+		return nil, fmt.Errorf("unsupported archive format %s (must be one of: %s)", filepath.Base(out), strings.Join(PackFormats, ", "))
+	}
+
+	var zstd string
+	if format == ".tar.zst" || format == ".tzst" {
+		var err error
+		if zstd, err = exec.LookPath("zstd"); err != nil {
+			return nil, fmt.Errorf("'zstd' not found (required to create %s archives): %v", format, err)
+		}
+	}
+
+	f, err := os.Create(out)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s: %v", out, err)
+	}
+
+	switch format {
+	case ".zip":
+		return &zipWriter{zw: zip.NewWriter(f), f: f}, nil
+	case ".tar":
+		return &tarWriter{tw: tar.NewWriter(f), f: f}, nil
+	case ".tar.gz", ".tgz":
+		gw := gzip.NewWriter(f)
+		return &tarWriter{tw: tar.NewWriter(gw), closers: []io.Closer{gw}, f: f}, nil
+	case ".tar.xz", ".txz":
+		xw, err := xz.NewWriter(f)
+		if err != nil {
+			f.Close()
+			return nil, fmt.Errorf("failed to create xz writer: %v", err)
+		}
+		return &tarWriter{tw: tar.NewWriter(xw), closers: []io.Closer{xw}, f: f}, nil
+	default: // zstd
+		cmd := exec.Command(zstd, "-q", "-T0", "-c")
+		cmd.Stdout = f
+		cmd.Stderr = os.Stderr
+		stdin, err := cmd.StdinPipe()
+		if err != nil {
+			f.Close()
+			return nil, fmt.Errorf("failed to create zstd pipe: %v", err)
+		}
+		if err := cmd.Start(); err != nil {
+			f.Close()
+			return nil, fmt.Errorf("failed to start zstd: %v", err)
+		}
+		return &tarWriter{tw: tar.NewWriter(stdin), closers: []io.Closer{stdin}, cmd: cmd, f: f}, nil
+	}
+}
+
+// Pack streams the IPSW entries matching c.Pattern straight into the archive at out
+// (nothing is extracted to disk) and returns the names of the packed entries
+//
+// NOTE: only the entries of the IPSW zip itself can be packed (not the files inside its DMGs)
+func Pack(c *Config, out string) ([]string, error) {
+	if len(c.Pattern) == 0 {
+		return nil, fmt.Errorf("no pattern provided")
+	}
+	re, err := regexp.Compile(c.Pattern)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile regexp '%s': %v", c.Pattern, err)
+	}
+
+	var files []*zip.File
+	var folder string
+	if len(c.IPSW) > 0 {
+		_, folder, err = getFolder(c)
+		if err != nil {
+			return nil, err
+		}
+		zr, err := zip.OpenReader(c.IPSW)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open IPSW: %v", err)
+		}
+		defer zr.Close()
+		files = zr.File
+	} else if len(c.URL) > 0 {
+		if !isURL(c.URL) {
+			return nil, fmt.Errorf("invalid URL provided: %s", c.URL)
+		}
+		var zr *zip.Reader
+		_, zr, folder, err = getRemoteFolder(c)
+		if err != nil {
+			return nil, err
+		}
+		files = zr.File
+	} else {
+		return nil, fmt.Errorf("no IPSW or URL provided")
+	}
+
+	var matches []*zip.File
+	for _, f := range files {
+		if !f.FileInfo().IsDir() && re.MatchString(f.Name) {
+			matches = append(matches, f)
+		}
+	}
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("no files matching pattern '%s' found", c.Pattern)
+	}
+	if c.Reproducible {
+		sort.Slice(matches, func(i, j int) bool { return matches[i].Name < matches[j].Name })
+	}
+
+	aw, err := newArchiveWriter(out)
+	if err != nil {
+		return nil, err
+	}
+	defer aw.Close()
+
+	var packed []string
+	for _, f := range matches {
+		name := path.Clean(f.Name)
+		if c.Flatten {
+			name = path.Base(name)
+		}
+		name = path.Join(filepath.ToSlash(folder), name)
+
+		mode, mtime := f.Mode(), f.Modified
+		if mode.Perm() == 0 {
+			mode |= 0644
+		}
+		if c.Reproducible {
+			mode, mtime = 0644, SourceDateEpoch()
+			if f.Mode()&0111 != 0 {
+				mode = 0755
+			}
+		}
+
+		rc, err := f.Open()
+		if err != nil {
+			return nil, fmt.Errorf("failed to open %s: %v", f.Name, err)
+		}
+		utils.Indent(log.Debug, 2)(fmt.Sprintf("Packing %s", name))
+		err = aw.Add(name, mode, mtime, int64(f.UncompressedSize64), rc)
+		rc.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to pack %s into %s: %v", f.Name, out, err)
+		}
+		packed = append(packed, name)
+	}
+
+	if err := aw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write %s: %v", out, err)
+	}
+	return packed, nil
+}
@@ -0,0 +1,73 @@
+package extract
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SourceDateEpoch returns the modification time given to reproducibly extracted files
+// ($SOURCE_DATE_EPOCH if set, see https://reproducible-builds.org/specs/source-date-epoch, otherwise the Unix epoch)
+func SourceDateEpoch() time.Time {
+	if epoch, err := strconv.ParseInt(os.Getenv("SOURCE_DATE_EPOCH"), 10, 64); err == nil {
+		return time.Unix(epoch, 0).UTC()
+	}
+	return time.Unix(0, 0).UTC()
+}
+
+// Reproducible normalizes the extracted files, and the folders they were created in under the output folder,
+// so that extraction trees hash the same across machines: modification times are set to SourceDateEpoch,
+// permissions to 0644 (0755 for folders and executables) and the returned paths are sorted
+func Reproducible(c *Config, files []string) ([]string, error) {
+	mtime := SourceDateEpoch()
+
+	root, err := filepath.Abs(filepath.Clean(c.Output))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get absolute path of %s: %v", c.Output, err)
+	}
+
+	dirs := make(map[string]bool)
+	for _, f := range files {
+		fi, err := os.Lstat(f)
+		if err != nil {
+			return nil, fmt.Errorf("failed to stat %s: %v", f, err)
+		}
+		if fi.Mode()&os.ModeSymlink == 0 { // chmod/chtimes would follow the link
+			perm := os.FileMode(0644)
+			if fi.IsDir() || fi.Mode()&0111 != 0 {
+				perm = 0755
+			}
+			if err := os.Chmod(f, perm); err != nil {
+				return nil, fmt.Errorf("failed to chmod %s: %v", f, err)
+			}
+			if err := os.Chtimes(f, mtime, mtime); err != nil {
+				return nil, fmt.Errorf("failed to set modification time of %s: %v", f, err)
+			}
+		}
+		abs, err := filepath.Abs(f)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get absolute path of %s: %v", f, err)
+		}
+		for dir := filepath.Dir(abs); dir != root && strings.HasPrefix(dir, root+string(filepath.Separator)); dir = filepath.Dir(dir) {
+			dirs[dir] = true
+		}
+	}
+
+	// folders last as creating the files above updated their modification times
+	for dir := range dirs {
+		if err := os.Chmod(dir, 0755); err != nil {
+			return nil, fmt.Errorf("failed to chmod %s: %v", dir, err)
+		}
+		if err := os.Chtimes(dir, mtime, mtime); err != nil {
+			return nil, fmt.Errorf("failed to set modification time of %s: %v", dir, err)
+		}
+	}
+
+	sorted := append([]string(nil), files...)
+	sort.Strings(sorted)
+	return sorted, nil
+}
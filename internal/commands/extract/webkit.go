@@ -0,0 +1,106 @@
+package extract
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/blacktop/go-plist"
+	"github.com/blacktop/ipsw/internal/utils"
+	"github.com/blacktop/ipsw/pkg/info"
+)
+
+// the WebKit framework and Safari app Info.plists (in the filesystem or the OS/App cryptexes)
+var (
+	webkitPlistRegex = regexp.MustCompile(`/Frameworks/WebKit\.framework/(Versions/A/Resources/)?Info\.plist$`)
+	safariPlistRegex = regexp.MustCompile(`/(Mobile)?Safari\.app/(Contents/)?Info\.plist$`)
+)
+
+// WebKitVersions are the WebKit and Safari versions shipped in a build
+type WebKitVersions struct {
+	Version     string `json:"version,omitempty"`
+	Build       string `json:"build,omitempty"`
+	WebKit      string `json:"webkit,omitempty"`       // WebKit.framework CFBundleVersion (i.e. 19616.1.27.211.1)
+	Safari      string `json:"safari,omitempty"`       // Safari CFBundleShortVersionString (i.e. 17.0)
+	SafariBuild string `json:"safari_build,omitempty"` // Safari CFBundleVersion (i.e. 19616.1.27.211.1)
+}
+
+func (w WebKitVersions) String() string {
+	return fmt.Sprintf("WebKit: %s\nSafari: %s (%s)", w.WebKit, w.Safari, w.SafariBuild)
+}
+
+type bundleInfo struct {
+	CFBundleShortVersionString string `plist:"CFBundleShortVersionString,omitempty"`
+	CFBundleVersion            string `plist:"CFBundleVersion,omitempty"`
+}
+
+func readBundleInfo(path string) (*bundleInfo, error) {
+	dat, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %v", path, err)
+	}
+	var bi bundleInfo
+	if err := plist.NewDecoder(bytes.NewReader(dat)).Decode(&bi); err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %v", path, err)
+	}
+	return &bi, nil
+}
+
+// WebKit extracts the WebKit and Safari versions from an IPSW's filesystem and cryptex DMGs
+func WebKit(path string) (*WebKitVersions, error) {
+	ipswPath := filepath.Clean(path)
+
+	i, err := info.Parse(ipswPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse IPSW: %v", err)
+	}
+
+	wk := &WebKitVersions{
+		Version: i.Plists.BuildManifest.ProductVersion,
+		Build:   i.Plists.BuildManifest.ProductBuildVersion,
+	}
+
+	tmpDir, err := os.MkdirTemp("", "ipsw_webkit")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp directory: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	pattern := regexp.MustCompile(webkitPlistRegex.String() + "|" + safariPlistRegex.String())
+	// newer builds ship WebKit and Safari in the SystemOS and AppOS cryptexes
+	for _, getDMG := range []func() (string, error){i.GetFileSystemOsDmg, i.GetSystemOsDmg, i.GetAppOsDmg} {
+		dmg, err := getDMG()
+		if err != nil {
+			continue
+		}
+		extracted, err := utils.ExtractFromDMG(ipswPath, dmg, tmpDir, pattern)
+		if err != nil {
+			return nil, fmt.Errorf("failed to extract Info.plists from %s: %v", dmg, err)
+		}
+		for _, fname := range extracted {
+			bi, err := readBundleInfo(fname)
+			if err != nil {
+				log.Debug(err.Error())
+				continue
+			}
+			rel := strings.TrimPrefix(fname, tmpDir)
+			switch {
+			case webkitPlistRegex.MatchString(rel) && len(wk.WebKit) == 0:
+				wk.WebKit = bi.CFBundleVersion
+			case safariPlistRegex.MatchString(rel) && len(wk.Safari) == 0:
+				wk.Safari = bi.CFBundleShortVersionString
+				wk.SafariBuild = bi.CFBundleVersion
+			}
+		}
+	}
+
+	if len(wk.WebKit) == 0 && len(wk.Safari) == 0 {
+		return nil, fmt.Errorf("failed to find WebKit or Safari in %s", ipswPath)
+	}
+
+	return wk, nil
+}
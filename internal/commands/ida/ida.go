@@ -80,7 +80,7 @@ func NewClient(ctx context.Context, conf *Config) (*Client, error) {
 					Message: "Multiple IDA Pro Versions Found:",
 					Options: matches,
 				}
-				if err := survey.AskOne(prompt, &path); err != nil {
+				if err := utils.AskOne(prompt, &path); err != nil {
 					if err == terminal.InterruptErr {
 						log.Warn("Exiting...")
 						os.Exit(0)
@@ -0,0 +1,154 @@
+package locale
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/blacktop/ipsw/internal/utils"
+)
+
+// LocaleChange is how a locale's localized bundles changed between builds
+type LocaleChange struct {
+	Name    string   `json:"name"`
+	Added   []string `json:"added,omitempty"`   // bundles newly localized into the locale
+	Removed []string `json:"removed,omitempty"` // bundles no longer localized into the locale
+}
+
+// AppChange is how a preinstalled app changed between builds
+type AppChange struct {
+	ID             string   `json:"id"`
+	OldVersion     string   `json:"old_version,omitempty"`
+	NewVersion     string   `json:"new_version,omitempty"`
+	AddedLocales   []string `json:"added_locales,omitempty"`
+	RemovedLocales []string `json:"removed_locales,omitempty"`
+}
+
+// Diff is the difference between the localizations and apps of two builds
+type Diff struct {
+	Old            string         `json:"old"`
+	New            string         `json:"new"`
+	NewLocales     []string       `json:"new_locales,omitempty"`
+	RemovedLocales []string       `json:"removed_locales,omitempty"`
+	ChangedLocales []LocaleChange `json:"changed_locales,omitempty"`
+	NewApps        []App          `json:"new_apps,omitempty"`
+	RemovedApps    []App          `json:"removed_apps,omitempty"`
+	UpdatedApps    []AppChange    `json:"updated_apps,omitempty"`
+}
+
+func localeNames(inv *Inventory) []string {
+	var names []string
+	for _, l := range inv.Locales {
+		names = append(names, l.Name)
+	}
+	return names
+}
+
+func appsByID(inv *Inventory) map[string]App {
+	apps := make(map[string]App)
+	for _, app := range inv.Apps {
+		apps[app.ID] = app
+	}
+	return apps
+}
+
+// Compare diffs the localizations and apps of an old and new build
+func Compare(old, new *Inventory) *Diff {
+	d := &Diff{Old: old.Label(), New: new.Label()}
+
+	d.NewLocales = utils.Difference(localeNames(new), localeNames(old))
+	d.RemovedLocales = utils.Difference(localeNames(old), localeNames(new))
+	for _, nl := range new.Locales {
+		ol := old.Locale(nl.Name)
+		if ol == nil {
+			continue
+		}
+		lc := LocaleChange{
+			Name:    nl.Name,
+			Added:   utils.Difference(nl.Bundles, ol.Bundles),
+			Removed: utils.Difference(ol.Bundles, nl.Bundles),
+		}
+		if len(lc.Added) > 0 || len(lc.Removed) > 0 {
+			d.ChangedLocales = append(d.ChangedLocales, lc)
+		}
+	}
+
+	oldApps := appsByID(old)
+	newApps := appsByID(new)
+	for id, na := range newApps {
+		oa, ok := oldApps[id]
+		if !ok {
+			d.NewApps = append(d.NewApps, na)
+			continue
+		}
+		ac := AppChange{
+			ID:             id,
+			OldVersion:     oa.Version,
+			NewVersion:     na.Version,
+			AddedLocales:   utils.Difference(na.Locales, oa.Locales),
+			RemovedLocales: utils.Difference(oa.Locales, na.Locales),
+		}
+		if ac.OldVersion != ac.NewVersion || len(ac.AddedLocales) > 0 || len(ac.RemovedLocales) > 0 {
+			d.UpdatedApps = append(d.UpdatedApps, ac)
+		}
+	}
+	for id, oa := range oldApps {
+		if _, ok := newApps[id]; !ok {
+			d.RemovedApps = append(d.RemovedApps, oa)
+		}
+	}
+	sort.Slice(d.NewApps, func(i, j int) bool { return d.NewApps[i].ID < d.NewApps[j].ID })
+	sort.Slice(d.RemovedApps, func(i, j int) bool { return d.RemovedApps[i].ID < d.RemovedApps[j].ID })
+	sort.Slice(d.UpdatedApps, func(i, j int) bool { return d.UpdatedApps[i].ID < d.UpdatedApps[j].ID })
+
+	return d
+}
+
+func (d *Diff) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "## %s .vs %s\n", d.Old, d.New)
+
+	if len(d.NewLocales) > 0 || len(d.RemovedLocales) > 0 || len(d.ChangedLocales) > 0 {
+		sb.WriteString("\n### Locales\n\n")
+		for _, l := range d.NewLocales {
+			fmt.Fprintf(&sb, "- 🆕 `%s`\n", l)
+		}
+		for _, l := range d.RemovedLocales {
+			fmt.Fprintf(&sb, "- ❌ `%s`\n", l)
+		}
+		for _, lc := range d.ChangedLocales {
+			fmt.Fprintf(&sb, "- ⬆️ `%s`: +%d/-%d bundles\n", lc.Name, len(lc.Added), len(lc.Removed))
+			for _, b := range lc.Added {
+				fmt.Fprintf(&sb, "  - + `%s`\n", b)
+			}
+			for _, b := range lc.Removed {
+				fmt.Fprintf(&sb, "  - - `%s`\n", b)
+			}
+		}
+	}
+
+	if len(d.NewApps) > 0 || len(d.RemovedApps) > 0 || len(d.UpdatedApps) > 0 {
+		sb.WriteString("\n### Apps\n\n")
+		for _, app := range d.NewApps {
+			fmt.Fprintf(&sb, "- 🆕 `%s` %s\n", app.ID, app.Version)
+		}
+		for _, app := range d.RemovedApps {
+			fmt.Fprintf(&sb, "- ❌ `%s` %s\n", app.ID, app.Version)
+		}
+		for _, ac := range d.UpdatedApps {
+			fmt.Fprintf(&sb, "- ⬆️ `%s`", ac.ID)
+			if ac.OldVersion != ac.NewVersion {
+				fmt.Fprintf(&sb, " %s -> %s", ac.OldVersion, ac.NewVersion)
+			}
+			if len(ac.AddedLocales) > 0 {
+				fmt.Fprintf(&sb, " +locales: %s", strings.Join(ac.AddedLocales, ", "))
+			}
+			if len(ac.RemovedLocales) > 0 {
+				fmt.Fprintf(&sb, " -locales: %s", strings.Join(ac.RemovedLocales, ", "))
+			}
+			sb.WriteString("\n")
+		}
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,268 @@
+// Package locale enumerates the localizations and preinstalled apps of a firmware build
+package locale
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/blacktop/go-plist"
+	"github.com/blacktop/ipsw/internal/commands/mount"
+	"github.com/blacktop/ipsw/internal/utils"
+	"github.com/blacktop/ipsw/pkg/info"
+)
+
+// legacyNames are the pre-ISO .lproj names still found in older bundles
+var legacyNames = map[string]string{
+	"English":  "en",
+	"French":   "fr",
+	"German":   "de",
+	"Italian":  "it",
+	"Japanese": "ja",
+	"Spanish":  "es",
+	"Dutch":    "nl",
+}
+
+// bundleExts are the bundle types whose .lproj folders are counted
+var bundleExts = []string{".app", ".appex", ".bundle", ".framework", ".plugin", ".kext", ".xpc"}
+
+// Locale is a language/region a build is localized into
+type Locale struct {
+	Name          string   `json:"name"`                     // .lproj name (i.e. en_GB)
+	Bundles       []string `json:"bundles"`                  // paths of the bundles localized into it
+	AssetCatalogs []string `json:"asset_catalogs,omitempty"` // paths of its localized asset catalogs (.car)
+}
+
+// App is a preinstalled app
+type App struct {
+	Path    string   `json:"path"`
+	ID      string   `json:"id,omitempty"`
+	Name    string   `json:"name,omitempty"`
+	Version string   `json:"version,omitempty"`
+	Locales []string `json:"locales,omitempty"`
+}
+
+// Inventory is the localizations and preinstalled apps of a build
+type Inventory struct {
+	Version string   `json:"version,omitempty"`
+	Build   string   `json:"build,omitempty"`
+	Locales []Locale `json:"locales"`
+	Apps    []App    `json:"apps"`
+}
+
+// Label returns the inventory's build (i.e. 17.0 (21A329))
+func (inv Inventory) Label() string {
+	if len(inv.Build) == 0 {
+		return inv.Version
+	}
+	return strings.TrimSpace(fmt.Sprintf("%s (%s)", inv.Version, inv.Build))
+}
+
+// Locale returns the named locale (nil if the build isn't localized into it)
+func (inv Inventory) Locale(name string) *Locale {
+	for i := range inv.Locales {
+		if inv.Locales[i].Name == name {
+			return &inv.Locales[i]
+		}
+	}
+	return nil
+}
+
+func (inv Inventory) String() string {
+	var sb strings.Builder
+	if label := inv.Label(); len(label) > 0 {
+		fmt.Fprintf(&sb, "## %s\n\n", label)
+	}
+	fmt.Fprintf(&sb, "### Locales (%d)\n\n", len(inv.Locales))
+	for _, l := range inv.Locales {
+		fmt.Fprintf(&sb, "- `%s`: %d bundles", l.Name, len(l.Bundles))
+		if len(l.AssetCatalogs) > 0 {
+			fmt.Fprintf(&sb, ", %d asset catalogs", len(l.AssetCatalogs))
+		}
+		sb.WriteString("\n")
+	}
+	fmt.Fprintf(&sb, "\n### Apps (%d)\n\n", len(inv.Apps))
+	for _, app := range inv.Apps {
+		fmt.Fprintf(&sb, "- `%s` %s (%d locales)\n", app.ID, app.Version, len(app.Locales))
+	}
+	return sb.String()
+}
+
+// Scan enumerates the localizations and apps in an IPSW's filesystem and cryptex DMGs (or in a folder)
+func Scan(path string) (*Inventory, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat %s: %v", path, err)
+	}
+	if fi.IsDir() {
+		return scanFolder(filepath.Clean(path))
+	}
+
+	i, err := info.Parse(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse IPSW: %v", err)
+	}
+
+	var folders []*Inventory
+	for _, typ := range []string{"fs", "sys", "app"} {
+		ctx, err := mount.DmgInIPSW(path, typ)
+		if err != nil {
+			log.Debugf("failed to mount %s DMG: %v", typ, err)
+			continue
+		}
+		utils.Indent(log.Info, 2)(fmt.Sprintf("Scanning %s DMG", typ))
+		inv, err := scanFolder(ctx.MountPoint)
+		if !ctx.AlreadyMounted {
+			if uerr := ctx.Unmount(); uerr != nil {
+				utils.Indent(log.Error, 3)(uerr.Error())
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+		folders = append(folders, inv)
+	}
+	if len(folders) == 0 {
+		return nil, fmt.Errorf("failed to mount any filesystem DMGs in %s", path)
+	}
+
+	inv := merge(folders...)
+	inv.Version = i.Plists.BuildManifest.ProductVersion
+	inv.Build = i.Plists.BuildManifest.ProductBuildVersion
+	return inv, nil
+}
+
+// localeName returns the locale of an .lproj folder (empty for Base.lproj)
+func localeName(lproj string) string {
+	name := strings.TrimSuffix(filepath.Base(lproj), ".lproj")
+	if name == "Base" {
+		return ""
+	}
+	if iso, ok := legacyNames[name]; ok {
+		return iso
+	}
+	return strings.ReplaceAll(name, "-", "_")
+}
+
+// bundleOf returns the bundle an .lproj folder belongs to (i.e. /Applications/Maps.app)
+func bundleOf(lproj string) string {
+	for dir := filepath.Dir(lproj); dir != "/" && dir != "."; dir = filepath.Dir(dir) {
+		if utils.StrSliceHas(bundleExts, filepath.Ext(dir)) {
+			return dir
+		}
+	}
+	return filepath.Dir(lproj)
+}
+
+func readApp(root, rel string) App {
+	app := App{Path: rel}
+	for _, ipath := range []string{"Info.plist", "Contents/Info.plist"} {
+		dat, err := os.ReadFile(filepath.Join(root, rel, ipath))
+		if err != nil {
+			continue
+		}
+		var bi struct {
+			CFBundleIdentifier         string `plist:"CFBundleIdentifier,omitempty"`
+			CFBundleName               string `plist:"CFBundleName,omitempty"`
+			CFBundleShortVersionString string `plist:"CFBundleShortVersionString,omitempty"`
+		}
+		if err := plist.NewDecoder(bytes.NewReader(dat)).Decode(&bi); err != nil {
+			log.Debugf("failed to decode %s Info.plist: %v", rel, err)
+			break
+		}
+		app.ID = bi.CFBundleIdentifier
+		app.Name = bi.CFBundleName
+		app.Version = bi.CFBundleShortVersionString
+		break
+	}
+	if len(app.ID) == 0 {
+		app.ID = strings.TrimSuffix(filepath.Base(rel), ".app")
+	}
+	return app
+}
+
+func scanFolder(root string) (*Inventory, error) {
+	locales := make(map[string]*Locale)
+	apps := make(map[string]*App)
+
+	if err := filepath.Walk(root, func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			log.Debugf("failed to walk %s: %v", path, err)
+			return nil
+		}
+		rel := "/" + strings.TrimPrefix(filepath.ToSlash(strings.TrimPrefix(path, root)), "/")
+		switch {
+		case fi.IsDir() && filepath.Ext(path) == ".app":
+			if _, ok := apps[rel]; !ok {
+				app := readApp(root, rel)
+				apps[rel] = &app
+			}
+		case fi.IsDir() && filepath.Ext(path) == ".lproj":
+			name := localeName(rel)
+			if len(name) == 0 {
+				return filepath.SkipDir
+			}
+			l, ok := locales[name]
+			if !ok {
+				l = &Locale{Name: name}
+				locales[name] = l
+			}
+			bundle := bundleOf(rel)
+			l.Bundles = utils.UniqueAppend(l.Bundles, bundle)
+			if app, ok := apps[bundle]; ok {
+				app.Locales = utils.UniqueAppend(app.Locales, name)
+			}
+		case fi.Mode().IsRegular() && filepath.Ext(path) == ".car" && filepath.Ext(filepath.Dir(path)) == ".lproj":
+			if l, ok := locales[localeName(filepath.Dir(rel))]; ok {
+				l.AssetCatalogs = append(l.AssetCatalogs, rel)
+			}
+		}
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("failed to walk files in %s: %v", root, err)
+	}
+
+	inv := &Inventory{}
+	for _, l := range locales {
+		sort.Strings(l.Bundles)
+		inv.Locales = append(inv.Locales, *l)
+	}
+	for _, app := range apps {
+		sort.Strings(app.Locales)
+		inv.Apps = append(inv.Apps, *app)
+	}
+	inv.sort()
+	return inv, nil
+}
+
+// merge combines the inventories of a build's filesystem and cryptexes
+func merge(invs ...*Inventory) *Inventory {
+	out := &Inventory{}
+	for _, inv := range invs {
+		for _, l := range inv.Locales {
+			if ol := out.Locale(l.Name); ol != nil {
+				for _, b := range l.Bundles {
+					ol.Bundles = utils.UniqueAppend(ol.Bundles, b)
+				}
+				for _, c := range l.AssetCatalogs {
+					ol.AssetCatalogs = utils.UniqueAppend(ol.AssetCatalogs, c)
+				}
+				sort.Strings(ol.Bundles)
+				continue
+			}
+			out.Locales = append(out.Locales, l)
+		}
+		out.Apps = append(out.Apps, inv.Apps...)
+	}
+	out.sort()
+	return out
+}
+
+func (inv *Inventory) sort() {
+	sort.Slice(inv.Locales, func(i, j int) bool { return inv.Locales[i].Name < inv.Locales[j].Name })
+	sort.Slice(inv.Apps, func(i, j int) bool { return inv.Apps[i].Path < inv.Apps[j].Path })
+}
@@ -0,0 +1,405 @@
+// Package rpc is the line-delimited JSON-RPC 2.0 server of `ipsw rpc`
+package rpc
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"path"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/blacktop/ipsw/internal/download"
+)
+
+// the JSON-RPC 2.0 error codes (the libipsw ErrorCode of a failed call is in the error's data)
+const (
+	codeParseError     = -32700
+	codeInvalidRequest = -32600
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+	codeServerError    = -32000
+)
+
+// ProgressMethod is the method of the notifications sent with the progress of the downloads
+// submitted with "progress": true
+const ProgressMethod = "download.progress"
+
+const waitInterval = 250 * time.Millisecond
+
+// Request is a JSON-RPC request (a notification if it has no ID)
+type Request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// Response is the result (or error) of a Request
+type Response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+}
+
+// Error is the error of a failed Request
+type Error struct {
+	Code    int        `json:"code"`
+	Message string     `json:"message"`
+	Data    *ErrorData `json:"data,omitempty"`
+}
+
+// ErrorData is the libipsw error of a failed call
+type ErrorData struct {
+	Code download.ErrorCode `json:"code"`
+	Name string             `json:"name"`
+}
+
+// notification is a message sent by the server (i.e. the progress of a download)
+type notification struct {
+	JSONRPC string `json:"jsonrpc"`
+	Method  string `json:"method"`
+	Params  any    `json:"params"`
+}
+
+// Config is the JSON-RPC server config
+type Config struct {
+	Output   string            // folder relative download dests are saved to (defaults to the current folder)
+	Proxy    string            // HTTP/HTTPS proxy of the downloads
+	Insecure bool              // do not verify ssl certs
+	Manager  *download.Manager // the download queue (defaults to download.DefaultManager())
+}
+
+type method func(ctx context.Context, params json.RawMessage) (any, error)
+
+// Server serves the methods of download.Call and the download queue methods (see Methods) over a stream:
+// one request per line in and one response (or notification) per line out
+type Server struct {
+	conf    Config
+	manager *download.Manager
+	methods map[string]method
+
+	wmu sync.Mutex
+	enc *json.Encoder
+
+	mu       sync.Mutex
+	inflight map[string]context.CancelFunc
+}
+
+// NewServer creates a new JSON-RPC server
+func NewServer(conf Config) *Server {
+	s := &Server{
+		conf:     conf,
+		manager:  conf.Manager,
+		inflight: make(map[string]context.CancelFunc),
+	}
+	if s.manager == nil {
+		s.manager = download.DefaultManager()
+	}
+	if len(s.conf.Output) == 0 {
+		s.conf.Output = "."
+	}
+	s.methods = map[string]method{
+		"rpc.methods":     func(context.Context, json.RawMessage) (any, error) { return s.Methods(), nil },
+		"rpc.cancel":      s.cancelRequest,
+		"download.submit": s.submitDownload,
+		"download.status": s.downloadStatus,
+		"download.list":   func(context.Context, json.RawMessage) (any, error) { return s.manager.List(), nil },
+		"download.cancel": s.cancelDownload,
+		"download.remove": s.removeDownload,
+		"download.wait":   s.waitDownload,
+	}
+	return s
+}
+
+// Methods returns the sorted names of the methods of the server
+func (s *Server) Methods() []string {
+	names := download.Methods()
+	for name := range s.methods {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Serve reads the requests from r and writes their responses to w until r is closed or ctx is canceled;
+// the requests are handled concurrently so the responses can be out of order (match them by ID)
+func (s *Server) Serve(ctx context.Context, r io.Reader, w io.Writer) error {
+	s.enc = json.NewEncoder(w)
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		var req Request
+		if err := json.Unmarshal(line, &req); err != nil {
+			s.write(Response{Error: &Error{Code: codeParseError, Message: fmt.Sprintf("failed to parse request: %v", err)}})
+			continue
+		}
+		if req.JSONRPC != "2.0" || len(req.Method) == 0 {
+			s.write(Response{ID: req.ID, Error: &Error{Code: codeInvalidRequest, Message: "invalid JSON-RPC 2.0 request"}})
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.handle(ctx, req)
+		}()
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read requests: %v", err)
+	}
+	return nil
+}
+
+// write writes a response or notification line
+func (s *Server) write(v any) {
+	if resp, ok := v.(Response); ok {
+		resp.JSONRPC = "2.0"
+		if resp.ID == nil {
+			resp.ID = json.RawMessage("null")
+		}
+		v = resp
+	}
+	s.wmu.Lock()
+	defer s.wmu.Unlock()
+	s.enc.Encode(v) // NOTE: a closed stdout ends the session when the client closes stdin
+}
+
+func (s *Server) handle(ctx context.Context, req Request) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	key := string(req.ID)
+	if req.ID != nil {
+		s.mu.Lock()
+		s.inflight[key] = cancel
+		s.mu.Unlock()
+		defer func() {
+			s.mu.Lock()
+			delete(s.inflight, key)
+			s.mu.Unlock()
+		}()
+	}
+
+	var (
+		result []byte
+		err    error
+	)
+	if fn, ok := s.methods[req.Method]; ok {
+		var res any
+		if len(req.Params) == 0 {
+			req.Params = json.RawMessage("{}")
+		}
+		if res, err = fn(ctx, req.Params); err == nil {
+			result, err = json.Marshal(res)
+		}
+	} else {
+		result, err = download.Call(ctx, req.Method, req.Params)
+	}
+	if req.ID == nil {
+		return // a notification
+	}
+	if err != nil {
+		s.write(Response{ID: req.ID, Error: toError(err, s.hasMethod(req.Method))})
+		return
+	}
+	s.write(Response{ID: req.ID, Result: result})
+}
+
+func (s *Server) hasMethod(name string) bool {
+	if _, ok := s.methods[name]; ok {
+		return true
+	}
+	for _, m := range download.Methods() {
+		if m == name {
+			return true
+		}
+	}
+	return false
+}
+
+// toError converts the error of a call to its JSON-RPC Error (known is false for an unknown method)
+func toError(err error, known bool) *Error {
+	code := download.ErrorCodeOf(err)
+	e := &Error{Code: codeServerError, Message: err.Error(), Data: &ErrorData{Code: code, Name: code.String()}}
+	switch {
+	case !known:
+		e.Code = codeMethodNotFound
+	case code == download.ErrCodeInvalidArgument:
+		e.Code = codeInvalidParams
+	}
+	return e
+}
+
+// decodeParams decodes the params of method into v
+func decodeParams(method string, params json.RawMessage, v any) error {
+	if err := json.Unmarshal(params, v); err != nil {
+		return fmt.Errorf("failed to parse %s params: %v: %w", method, err, download.ErrInvalidArgument)
+	}
+	return nil
+}
+
+type idParams struct {
+	ID json.RawMessage `json:"id"`
+}
+
+// cancelRequest cancels the in-flight request with the given ID (i.e. a download.wait)
+func (s *Server) cancelRequest(_ context.Context, params json.RawMessage) (any, error) {
+	var p idParams
+	if err := decodeParams("rpc.cancel", params, &p); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	cancel, ok := s.inflight[string(p.ID)]
+	s.mu.Unlock()
+	if !ok {
+		return false, nil // already done
+	}
+	cancel()
+	return true, nil
+}
+
+type submitParams struct {
+	URL        string `json:"url"`        // URL to download
+	Identifier string `json:"identifier"` // or the device whose build IPSW to download (i.e. iPhone15,2)
+	Build      string `json:"build"`      // i.e. 21A329
+	Dest       string `json:"dest"`       // path to save the download to (defaults to the URL's filename in the output folder)
+	Sha1       string `json:"sha1"`       // expected sha1 of the download (looked up for a device's build)
+	Progress   bool   `json:"progress"`   // send download.progress notifications
+}
+
+// progressParams are the params of the download.progress notifications
+type progressParams struct {
+	ID string `json:"id"`
+	download.Progress
+}
+
+func (s *Server) submitDownload(ctx context.Context, params json.RawMessage) (any, error) {
+	var p submitParams
+	if err := decodeParams("download.submit", params, &p); err != nil {
+		return nil, err
+	}
+	switch {
+	case len(p.URL) > 0:
+	case len(p.Identifier) > 0 && len(p.Build) > 0:
+		i, err := download.GetIPSWWithContext(ctx, p.Identifier, p.Build)
+		if err != nil {
+			return nil, err
+		}
+		p.URL = i.URL
+		if len(p.Sha1) == 0 {
+			p.Sha1 = i.SHA1
+		}
+	default:
+		return nil, fmt.Errorf("download.submit: url (or identifier and build) is required: %w", download.ErrInvalidArgument)
+	}
+	u, err := url.Parse(p.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return nil, fmt.Errorf("download.submit: invalid URL '%s': %w", p.URL, download.ErrInvalidArgument)
+	}
+	if len(p.Dest) == 0 {
+		p.Dest = path.Base(u.Path)
+	}
+	if !filepath.IsAbs(p.Dest) {
+		p.Dest = filepath.Join(s.conf.Output, p.Dest)
+	}
+
+	// NOTE: resume partial downloads instead of prompting
+	d := download.NewDownload(s.conf.Proxy, s.conf.Insecure, false, true, false, false, false)
+	d.URL = p.URL
+	d.Sha1 = p.Sha1
+	d.DestName = filepath.Clean(p.Dest)
+
+	id := s.manager.Add(d)
+	if p.Progress {
+		s.manager.SetProgressFunc(id, func(progress download.Progress) {
+			s.write(notification{JSONRPC: "2.0", Method: ProgressMethod, Params: progressParams{ID: id, Progress: progress}})
+		})
+	}
+	return s.manager.Status(id)
+}
+
+func (s *Server) jobID(method string, params json.RawMessage) (string, error) {
+	var p struct {
+		ID string `json:"id"`
+	}
+	if err := decodeParams(method, params, &p); err != nil {
+		return "", err
+	}
+	if len(p.ID) == 0 {
+		return "", fmt.Errorf("%s: id is required: %w", method, download.ErrInvalidArgument)
+	}
+	return p.ID, nil
+}
+
+func (s *Server) downloadStatus(_ context.Context, params json.RawMessage) (any, error) {
+	id, err := s.jobID("download.status", params)
+	if err != nil {
+		return nil, err
+	}
+	return s.manager.Status(id)
+}
+
+func (s *Server) cancelDownload(_ context.Context, params json.RawMessage) (any, error) {
+	id, err := s.jobID("download.cancel", params)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.manager.Cancel(id); err != nil {
+		return nil, err
+	}
+	return s.manager.Status(id)
+}
+
+// removeDownload removes a finished, failed or canceled download and returns its last status
+func (s *Server) removeDownload(_ context.Context, params json.RawMessage) (any, error) {
+	id, err := s.jobID("download.remove", params)
+	if err != nil {
+		return nil, err
+	}
+	status, err := s.manager.Status(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.manager.Remove(id); err != nil {
+		return nil, err
+	}
+	return status, nil
+}
+
+// waitDownload returns the status of a download once it is done, failed or canceled
+func (s *Server) waitDownload(ctx context.Context, params json.RawMessage) (any, error) {
+	id, err := s.jobID("download.wait", params)
+	if err != nil {
+		return nil, err
+	}
+	ticker := time.NewTicker(waitInterval)
+	defer ticker.Stop()
+	for {
+		st, err := s.manager.Status(id)
+		if err != nil {
+			return nil, err
+		}
+		switch st.State {
+		case download.StateDone, download.StateFailed, download.StateCanceled:
+			return st, nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("download.wait: %w", ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package rpc_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/blacktop/ipsw/internal/commands/rpc"
+	"github.com/blacktop/ipsw/internal/download"
+	"github.com/blacktop/ipsw/pkg/testsupport"
+)
+
+type message struct {
+	rpc.Response
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+}
+
+func TestServer(t *testing.T) {
+	testsupport.NewFirmwareServer(t)
+	data := testsupport.FirmwareData()
+
+	output := t.TempDir()
+	stdin, w := io.Pipe()
+	r, stdout := io.Pipe()
+	manager := download.NewManager(1)
+	t.Cleanup(manager.Close)
+	srv := rpc.NewServer(rpc.Config{Output: output, Manager: manager})
+	done := make(chan error, 1)
+	go func() {
+		done <- srv.Serve(context.Background(), stdin, stdout)
+		stdout.Close()
+	}()
+	scanner := bufio.NewScanner(r)
+
+	// call sends the request line and reads the next message
+	responses := make(map[string]message)
+	call := func(request string) {
+		t.Helper()
+		if _, err := io.WriteString(w, request+"\n"); err != nil {
+			t.Fatal(err)
+		}
+		if !scanner.Scan() {
+			t.Fatalf("no response to %s: %v", request, scanner.Err())
+		}
+		var msg message
+		if err := json.Unmarshal(scanner.Bytes(), &msg); err != nil {
+			t.Fatalf("failed to parse %q: %v", scanner.Text(), err)
+		}
+		if msg.JSONRPC != "2.0" {
+			t.Errorf("response %s has no jsonrpc version", scanner.Text())
+		}
+		responses[string(msg.ID)] = msg
+	}
+
+	call(`{"jsonrpc": "2.0", "id": 1, "method": "ipsw_me.device", "params": {"identifier": "iPhone15,2"}}`)
+	call(`{"jsonrpc": "2.0", "id": 2, "method": "ipsw_me.device", "params": {}}`)
+	call(`{"jsonrpc": "2.0", "id": 3, "method": "nope"}`)
+	call(`not json`)
+	io.WriteString(w, `{"jsonrpc": "2.0", "method": "rpc.methods"}`+"\n") // a notification (no response)
+	call(`{"jsonrpc": "2.0", "id": "dl", "method": "download.submit", "params": {"identifier": "iPhone15,2", "build": "21A329", "dest": "17.0.ipsw"}}`)
+	call(`{"jsonrpc": "2.0", "id": "wait", "method": "download.wait", "params": {"id": "1"}}`)
+	w.Close()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if scanner.Scan() {
+		t.Errorf("unexpected message %s", scanner.Text())
+	}
+
+	var device download.Device
+	if err := json.Unmarshal(responses["1"].Result, &device); err != nil || device.Identifier != "iPhone15,2" {
+		t.Errorf("ipsw_me.device = %s (%v)", responses["1"].Result, err)
+	}
+	for id, want := range map[string]int{"2": -32602, "3": -32601, "null": -32700} {
+		if e := responses[id].Error; e == nil || e.Code != want {
+			t.Errorf("response %s error = %+v, want code %d", id, e, want)
+		}
+	}
+	if e := responses["2"].Error; e == nil || e.Data == nil || e.Data.Code != download.ErrCodeInvalidArgument {
+		t.Errorf("ipsw_me.device without an identifier error = %+v, want its libipsw code", e)
+	}
+
+	var status download.DownloadStatus
+	if err := json.Unmarshal(responses[`"wait"`].Result, &status); err != nil || status.State != download.StateDone {
+		t.Fatalf("download.wait = %s (%v), want a done download", responses[`"wait"`].Result, err)
+	}
+	f, err := os.Open(filepath.Join(output, "17.0.ipsw"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if got, _ := io.ReadAll(f); !bytes.Equal(got, data) {
+		t.Fatal("downloaded IPSW does not match")
+	}
+}
@@ -0,0 +1,166 @@
+package sbom
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CycloneDX 1.5 JSON (https://cyclonedx.org/docs/1.5/json)
+
+type cdxBOM struct {
+	BOMFormat    string         `json:"bomFormat"`
+	SpecVersion  string         `json:"specVersion"`
+	SerialNumber string         `json:"serialNumber"`
+	Version      int            `json:"version"`
+	Metadata     cdxMetadata    `json:"metadata"`
+	Components   []cdxComponent `json:"components"`
+	Dependencies []cdxDep       `json:"dependencies,omitempty"`
+}
+
+type cdxMetadata struct {
+	Timestamp string       `json:"timestamp"`
+	Tools     []cdxTool    `json:"tools,omitempty"`
+	Component cdxComponent `json:"component"`
+}
+
+type cdxTool struct {
+	Vendor  string `json:"vendor,omitempty"`
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+}
+
+type cdxComponent struct {
+	Type       string        `json:"type"`
+	BOMRef     string        `json:"bom-ref"`
+	Supplier   *cdxSupplier  `json:"supplier,omitempty"`
+	Name       string        `json:"name"`
+	Version    string        `json:"version,omitempty"`
+	Hashes     []cdxHash     `json:"hashes,omitempty"`
+	PURL       string        `json:"purl,omitempty"`
+	ExtRefs    []cdxExtRef   `json:"externalReferences,omitempty"`
+	Properties []cdxProperty `json:"properties,omitempty"`
+}
+
+type cdxSupplier struct {
+	Name string `json:"name"`
+}
+
+type cdxHash struct {
+	Alg     string `json:"alg"`
+	Content string `json:"content"`
+}
+
+type cdxExtRef struct {
+	Type string `json:"type"`
+	URL  string `json:"url"`
+}
+
+type cdxProperty struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type cdxDep struct {
+	Ref       string   `json:"ref"`
+	DependsOn []string `json:"dependsOn,omitempty"`
+}
+
+var apple = &cdxSupplier{Name: "Apple Inc."}
+
+// CycloneDX returns the BOM as a CycloneDX 1.5 JSON document
+func (b *BOM) CycloneDX(toolVersion string) ([]byte, error) {
+	root := cdxComponent{
+		Type:     "operating-system",
+		BOMRef:   "os",
+		Supplier: apple,
+		Name:     b.Name,
+		Version:  b.Version,
+		Properties: []cdxProperty{
+			{Name: "ipsw:build", Value: b.Build},
+		},
+	}
+	for _, dev := range b.Devices {
+		root.Properties = append(root.Properties, cdxProperty{Name: "ipsw:device", Value: dev})
+	}
+	if len(b.OSSRelease) > 0 {
+		root.Properties = append(root.Properties, cdxProperty{Name: "ipsw:oss_release", Value: b.OSSRelease})
+	}
+
+	doc := cdxBOM{
+		BOMFormat:    "CycloneDX",
+		SpecVersion:  "1.5",
+		SerialNumber: "urn:uuid:" + uuid.NewString(),
+		Version:      1,
+		Metadata: cdxMetadata{
+			Timestamp: b.Created.Format(time.RFC3339),
+			Tools:     []cdxTool{{Vendor: "blacktop", Name: "ipsw", Version: toolVersion}},
+			Component: root,
+		},
+		Components: []cdxComponent{},
+	}
+
+	rootDep := cdxDep{Ref: root.BOMRef}
+	refs := make(map[string]string) // binary path => bom-ref
+	for i, bin := range b.Binaries {
+		comp := cdxComponent{
+			Type:     "file",
+			BOMRef:   fmt.Sprintf("bin-%d", i),
+			Supplier: apple,
+			Name:     bin.Path,
+			Version:  bin.Version(),
+		}
+		if len(bin.SHA256) > 0 {
+			comp.Hashes = []cdxHash{{Alg: "SHA-1", Content: bin.SHA1}, {Alg: "SHA-256", Content: bin.SHA256}}
+		}
+		for _, prop := range []cdxProperty{
+			{Name: "ipsw:source_version", Value: bin.SourceVersion},
+			{Name: "ipsw:dylib_version", Value: bin.DylibVersion},
+			{Name: "ipsw:identifier", Value: bin.Identifier},
+			{Name: "ipsw:team_id", Value: bin.TeamID},
+			{Name: "ipsw:uuid", Value: bin.UUID},
+		} {
+			if len(prop.Value) > 0 {
+				comp.Properties = append(comp.Properties, prop)
+			}
+		}
+		if bin.InCache {
+			comp.Properties = append(comp.Properties, cdxProperty{Name: "ipsw:dyld_shared_cache", Value: "true"})
+		}
+		refs[bin.Path] = comp.BOMRef
+		rootDep.DependsOn = append(rootDep.DependsOn, comp.BOMRef)
+		doc.Components = append(doc.Components, comp)
+	}
+
+	deps := []cdxDep{rootDep}
+	built := make(map[string]int) // binary bom-ref => index in deps
+	for i, oss := range b.OSS {
+		comp := cdxComponent{
+			Type:     "library",
+			BOMRef:   fmt.Sprintf("oss-%d", i),
+			Supplier: apple,
+			Name:     oss.Name,
+			Version:  oss.Version,
+			PURL:     oss.PURL(),
+		}
+		if len(oss.URL) > 0 {
+			comp.ExtRefs = []cdxExtRef{{Type: "distribution", URL: oss.URL}}
+		}
+		doc.Components = append(doc.Components, comp)
+		// the matched binaries are built from the project
+		for _, path := range oss.Binaries {
+			ref := refs[path]
+			if idx, ok := built[ref]; ok {
+				deps[idx].DependsOn = append(deps[idx].DependsOn, comp.BOMRef)
+				continue
+			}
+			built[ref] = len(deps)
+			deps = append(deps, cdxDep{Ref: ref, DependsOn: []string{comp.BOMRef}})
+		}
+	}
+	doc.Dependencies = deps
+
+	return json.MarshalIndent(doc, "", "  ")
+}
@@ -0,0 +1,383 @@
+// Package sbom generates software bills of materials (SPDX/CycloneDX) for firmware builds
+package sbom
+
+import (
+	"archive/zip"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/apex/log"
+	"github.com/blacktop/go-macho"
+	"github.com/blacktop/ipsw/internal/download"
+	"github.com/blacktop/ipsw/internal/utils"
+	"github.com/blacktop/ipsw/pkg/dyld"
+	"github.com/blacktop/ipsw/pkg/info"
+)
+
+// Formats are the supported SBOM formats
+var Formats = []string{"cyclonedx", "spdx"}
+
+// Config is the configuration for generating an SBOM
+type Config struct {
+	// path to the IPSW (its filesystem DMGs are mounted and scanned)
+	IPSW string
+	// path to an extracted/mounted filesystem to scan instead of an IPSW
+	Folder string
+	// name, version and build of the OS in Folder (read from the IPSW when scanning one)
+	Name    string
+	Version string
+	Build   string
+	// include the images in the dyld_shared_caches found in the filesystem
+	DyldSharedCache bool
+	// match the binaries against the opensource.apple.com release of the build
+	OSS bool
+	// opensource.apple.com release to match against (i.e. "macOS 14.0"; default: the build's OS and version)
+	OSSRelease string
+	Proxy      string
+	Insecure   bool
+}
+
+// Binary is a Mach-O found in a build
+type Binary struct {
+	Path          string `json:"path"`
+	SourceVersion string `json:"source_version,omitempty"` // LC_SOURCE_VERSION (the project's version)
+	DylibVersion  string `json:"dylib_version,omitempty"`  // LC_ID_DYLIB current version
+	Identifier    string `json:"identifier,omitempty"`     // code signing identifier (i.e. com.apple.xpc.launchd)
+	TeamID        string `json:"team_id,omitempty"`
+	UUID          string `json:"uuid,omitempty"`
+	SHA1          string `json:"sha1,omitempty"`   // (not set for dyld_shared_cache images)
+	SHA256        string `json:"sha256,omitempty"` // (not set for dyld_shared_cache images)
+	InCache       bool   `json:"in_cache,omitempty"`
+}
+
+// Name returns the binary's file name
+func (b Binary) Name() string {
+	return filepath.Base(b.Path)
+}
+
+// Version returns the binary's best version (its source version or else its dylib version)
+func (b Binary) Version() string {
+	if len(b.SourceVersion) > 0 {
+		return b.SourceVersion
+	}
+	return b.DylibVersion
+}
+
+// OSSComponent is an opensource.apple.com project that binaries in the build were built from
+type OSSComponent struct {
+	download.OSSProject
+	Version  string   `json:"version"`
+	Binaries []string `json:"binaries"` // paths of the matched binaries
+}
+
+// PURL returns the project's package URL (i.e. pkg:github/apple-oss-distributions/xnu@xnu-10002.1.13)
+func (c OSSComponent) PURL() string {
+	return fmt.Sprintf("pkg:github/apple-oss-distributions/%s@%s", c.Name, c.Tag)
+}
+
+// BOM is the software inventory of a build
+type BOM struct {
+	Name       string         `json:"name"` // OS name (i.e. iOS)
+	Version    string         `json:"version"`
+	Build      string         `json:"build"`
+	Devices    []string       `json:"devices,omitempty"`
+	Created    time.Time      `json:"created"`
+	Binaries   []Binary       `json:"binaries"`
+	OSS        []OSSComponent `json:"oss,omitempty"`
+	OSSRelease string         `json:"oss_release,omitempty"`
+}
+
+// osName returns the OS name of a build's supported devices
+func osName(devices []string) string {
+	for _, dev := range devices {
+		switch {
+		case strings.HasPrefix(dev, "iPhone"), strings.HasPrefix(dev, "iPad"), strings.HasPrefix(dev, "iPod"):
+			return "iOS"
+		case strings.HasPrefix(dev, "Mac"), strings.HasPrefix(dev, "iMac"), strings.HasPrefix(dev, "VirtualMac"):
+			return "macOS"
+		case strings.HasPrefix(dev, "Watch"):
+			return "watchOS"
+		case strings.HasPrefix(dev, "AppleTV"):
+			return "tvOS"
+		case strings.HasPrefix(dev, "AudioAccessory"):
+			return "audioOS"
+		case strings.HasPrefix(dev, "RealityDevice"):
+			return "visionOS"
+		}
+	}
+	return "Apple OS"
+}
+
+// Generate scans a build's binaries (and matches them against Apple's OSS releases)
+func Generate(c *Config) (*BOM, error) {
+	bom := &BOM{
+		Name:    c.Name,
+		Version: c.Version,
+		Build:   c.Build,
+		Created: time.Now().UTC(),
+	}
+
+	switch {
+	case len(c.Folder) > 0:
+		bins, err := scanFolder(c.Folder, c.DyldSharedCache)
+		if err != nil {
+			return nil, err
+		}
+		bom.Binaries = bins
+	case len(c.IPSW) > 0:
+		i, err := info.Parse(c.IPSW)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse IPSW: %v", err)
+		}
+		bom.Version = i.Plists.BuildManifest.ProductVersion
+		bom.Build = i.Plists.BuildManifest.ProductBuildVersion
+		bom.Devices = i.Plists.BuildManifest.SupportedProductTypes
+		if len(bom.Name) == 0 {
+			bom.Name = osName(bom.Devices)
+		}
+		seen := make(map[string]bool)
+		for _, getDMG := range []func() (string, error){i.GetAppOsDmg, i.GetSystemOsDmg, i.GetFileSystemOsDmg} {
+			dmg, err := getDMG()
+			if err != nil {
+				continue
+			}
+			bins, err := scanDMG(c.IPSW, dmg, c.DyldSharedCache)
+			if err != nil {
+				return nil, fmt.Errorf("failed to scan %s: %v", dmg, err)
+			}
+			for _, bin := range bins {
+				if !seen[bin.Path] {
+					seen[bin.Path] = true
+					bom.Binaries = append(bom.Binaries, bin)
+				}
+			}
+		}
+	default:
+		return nil, fmt.Errorf("no IPSW or folder provided")
+	}
+	sort.Slice(bom.Binaries, func(i, j int) bool { return bom.Binaries[i].Path < bom.Binaries[j].Path })
+
+	if c.OSS {
+		releases, err := download.GetOSSReleases(c.Proxy, c.Insecure)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get opensource.apple.com releases: %v", err)
+		}
+		name := c.OSSRelease
+		if len(name) == 0 {
+			name = strings.TrimSpace(bom.Name + " " + bom.Version)
+		}
+		rel, err := releases.Get(name)
+		if err != nil && len(c.OSSRelease) == 0 && len(bom.Version) > 0 {
+			rel, err = releases.Get(bom.Version) // i.e. an iOS build without an iOS OSS release
+		}
+		if err != nil {
+			return nil, err
+		}
+		bom.OSSRelease = rel.Name
+		bom.OSS = MatchOSS(bom.Binaries, rel.Projects)
+	}
+
+	return bom, nil
+}
+
+func scanDMG(ipswPath, dmgPath string, dsc bool) ([]Binary, error) {
+	// check if the DMG already exists (due to a previous mount command)
+	if _, err := os.Stat(dmgPath); os.IsNotExist(err) {
+		dmgs, err := utils.Unzip(ipswPath, "", func(f *zip.File) bool {
+			return strings.EqualFold(filepath.Base(f.Name), dmgPath)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to extract %s from IPSW: %v", dmgPath, err)
+		}
+		if len(dmgs) == 0 {
+			return nil, fmt.Errorf("failed to find %s in IPSW", dmgPath)
+		}
+		defer os.Remove(dmgs[0])
+	}
+
+	utils.Indent(log.Debug, 2)(fmt.Sprintf("Mounting %s", dmgPath))
+	mountPoint, alreadyMounted, err := utils.MountDMG(dmgPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to mount DMG: %v", err)
+	}
+	if !alreadyMounted {
+		defer func() {
+			utils.Indent(log.Debug, 2)(fmt.Sprintf("Unmounting %s", dmgPath))
+			if err := utils.Retry(3, 2*time.Second, func() error {
+				return utils.Unmount(mountPoint, true)
+			}); err != nil {
+				utils.Indent(log.Error, 3)(fmt.Sprintf("failed to unmount %s at %s: %v", dmgPath, mountPoint, err))
+			}
+		}()
+	}
+
+	return scanFolder(mountPoint, dsc)
+}
+
+var dscRegex = regexp.MustCompile(`dyld_shared_cache_[a-z0-9_]+$`)
+
+func scanFolder(root string, dsc bool) ([]Binary, error) {
+	var bins []Binary
+	if err := filepath.Walk(root, func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			log.Debugf("failed to walk %s: %v", path, err)
+			return nil
+		}
+		if !fi.Mode().IsRegular() {
+			return nil
+		}
+		rel := "/" + strings.TrimPrefix(filepath.ToSlash(strings.TrimPrefix(path, root)), "/")
+		if dsc && dscRegex.MatchString(path) {
+			imgs, err := scanDSC(path)
+			if err != nil {
+				log.Errorf("failed to scan dyld_shared_cache %s: %v", rel, err)
+			}
+			bins = append(bins, imgs...)
+			return nil
+		}
+		m, err := macho.Open(path)
+		if err != nil {
+			if fat, ferr := macho.OpenFat(path); ferr == nil { // universal binaries (use the first arch)
+				defer fat.Close()
+				if len(fat.Arches) > 0 {
+					m = fat.Arches[0].File
+				}
+			}
+			if m == nil {
+				return nil
+			}
+		} else {
+			defer m.Close()
+		}
+		bin := newBinary(rel, m)
+		if bin.SHA1, bin.SHA256, err = hashFile(path); err != nil {
+			log.Debugf("failed to hash %s: %v", rel, err)
+		}
+		bins = append(bins, bin)
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("failed to walk files in %s: %v", root, err)
+	}
+	return bins, nil
+}
+
+func scanDSC(path string) ([]Binary, error) {
+	f, err := dyld.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var bins []Binary
+	for _, img := range f.Images {
+		m, err := img.GetPartialMacho()
+		if err != nil {
+			log.Debugf("failed to parse %s in dyld_shared_cache: %v", img.Name, err)
+			continue
+		}
+		bin := newBinary(img.Name, m)
+		bin.InCache = true
+		bins = append(bins, bin)
+	}
+	return bins, nil
+}
+
+func newBinary(path string, m *macho.File) Binary {
+	bin := Binary{Path: path}
+	if sv := m.SourceVersion(); sv != nil {
+		bin.SourceVersion = trimVersion(sv.Version.String())
+	}
+	if id := m.DylibID(); id != nil {
+		bin.DylibVersion = trimVersion(id.CurrentVersion.String())
+	}
+	if u := m.UUID(); u != nil {
+		bin.UUID = u.String()
+	}
+	if cs := m.CodeSignature(); cs != nil && len(cs.CodeDirectories) > 0 {
+		bin.Identifier = cs.CodeDirectories[0].ID
+		bin.TeamID = cs.CodeDirectories[0].TeamID
+	}
+	return bin
+}
+
+// trimVersion removes the trailing zero components of a version (i.e. 1122.1.2.0.0 => 1122.1.2)
+func trimVersion(v string) string {
+	for strings.HasSuffix(v, ".0") && strings.Count(v, ".") > 1 {
+		v = strings.TrimSuffix(v, ".0")
+	}
+	if v == "0.0" {
+		return ""
+	}
+	return v
+}
+
+func hashFile(path string) (string, string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", "", err
+	}
+	defer f.Close()
+	h1 := sha1.New()
+	h256 := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(h1, h256), f); err != nil {
+		return "", "", err
+	}
+	return hex.EncodeToString(h1.Sum(nil)), hex.EncodeToString(h256.Sum(nil)), nil
+}
+
+var nonAlnum = regexp.MustCompile(`[^a-z0-9]+`)
+
+// normalize returns a binary or project name's comparable form (i.e. libxml2.2.dylib => xml2)
+func normalize(name string) string {
+	name = strings.ToLower(name)
+	if base, _, ok := strings.Cut(name, ".dylib"); ok {
+		name = base
+	}
+	if base, _, ok := strings.Cut(name, "."); ok && len(base) > 0 {
+		name = base // drop version suffixes (i.e. libz.1)
+	}
+	name = strings.TrimPrefix(name, "lib")
+	return nonAlnum.ReplaceAllString(name, "")
+}
+
+// MatchOSS matches binaries to the opensource.apple.com projects they were built from
+//
+// A binary matches a project if their names match, or if the binary's source version is the
+// project's tag version and one name contains the other.
+func MatchOSS(bins []Binary, projects []download.OSSProject) []OSSComponent {
+	var comps []OSSComponent
+	for _, proj := range projects {
+		pname := normalize(proj.Name)
+		if len(pname) == 0 {
+			continue
+		}
+		comp := OSSComponent{OSSProject: proj, Version: strings.TrimPrefix(proj.Tag, proj.Name+"-")}
+		for _, bin := range bins {
+			bname := normalize(bin.Name())
+			if len(bname) == 0 {
+				continue
+			}
+			switch {
+			case bname == pname:
+			case bin.SourceVersion == comp.Version && len(bname) > 2 && len(pname) > 2 &&
+				(strings.Contains(bname, pname) || strings.Contains(pname, bname)):
+			default:
+				continue
+			}
+			comp.Binaries = append(comp.Binaries, bin.Path)
+		}
+		if len(comp.Binaries) > 0 {
+			comps = append(comps, comp)
+		}
+	}
+	return comps
+}
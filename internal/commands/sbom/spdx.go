@@ -0,0 +1,185 @@
+package sbom
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SPDX 2.3 JSON (https://spdx.github.io/spdx-spec/v2.3)
+
+type spdxDocument struct {
+	SPDXVersion       string             `json:"spdxVersion"`
+	DataLicense       string             `json:"dataLicense"`
+	SPDXID            string             `json:"SPDXID"`
+	Name              string             `json:"name"`
+	DocumentNamespace string             `json:"documentNamespace"`
+	CreationInfo      spdxCreationInfo   `json:"creationInfo"`
+	Packages          []spdxPackage      `json:"packages"`
+	Files             []spdxFile         `json:"files,omitempty"`
+	Relationships     []spdxRelationship `json:"relationships"`
+}
+
+type spdxCreationInfo struct {
+	Created  string   `json:"created"`
+	Creators []string `json:"creators"`
+}
+
+type spdxPackage struct {
+	SPDXID           string       `json:"SPDXID"`
+	Name             string       `json:"name"`
+	VersionInfo      string       `json:"versionInfo,omitempty"`
+	Supplier         string       `json:"supplier"`
+	DownloadLocation string       `json:"downloadLocation"`
+	FilesAnalyzed    bool         `json:"filesAnalyzed"`
+	PrimaryPurpose   string       `json:"primaryPackagePurpose,omitempty"`
+	ExternalRefs     []spdxExtRef `json:"externalRefs,omitempty"`
+	Comment          string       `json:"comment,omitempty"`
+}
+
+type spdxExtRef struct {
+	Category string `json:"referenceCategory"`
+	Type     string `json:"referenceType"`
+	Locator  string `json:"referenceLocator"`
+}
+
+type spdxSum struct {
+	Algorithm string `json:"algorithm"`
+	Value     string `json:"checksumValue"`
+}
+
+type spdxFile struct {
+	SPDXID    string    `json:"SPDXID"`
+	FileName  string    `json:"fileName"`
+	FileTypes []string  `json:"fileTypes"`
+	Checksums []spdxSum `json:"checksums"`
+	Comment   string    `json:"comment,omitempty"`
+}
+
+type spdxRelationship struct {
+	Element string `json:"spdxElementId"`
+	Type    string `json:"relationshipType"`
+	Related string `json:"relatedSpdxElement"`
+}
+
+// SPDX returns the BOM as an SPDX 2.3 JSON document
+//
+// Binaries are files of the OS package, except for dyld_shared_cache images which have no checksum
+// of their own (SPDX files must have a SHA1 checksum) and are packages instead.
+func (b *BOM) SPDX(toolVersion string) ([]byte, error) {
+	name := strings.TrimSpace(fmt.Sprintf("%s %s %s", b.Name, b.Version, b.Build))
+	doc := spdxDocument{
+		SPDXVersion:       "SPDX-2.3",
+		DataLicense:       "CC0-1.0",
+		SPDXID:            "SPDXRef-DOCUMENT",
+		Name:              name,
+		DocumentNamespace: "https://github.com/blacktop/ipsw/spdx/" + uuid.NewString(),
+		CreationInfo: spdxCreationInfo{
+			Created:  b.Created.Format(time.RFC3339),
+			Creators: []string{"Organization: blacktop", "Tool: ipsw-" + toolVersion},
+		},
+	}
+
+	osPkg := spdxPackage{
+		SPDXID:           "SPDXRef-OS",
+		Name:             b.Name,
+		VersionInfo:      b.Version,
+		Supplier:         "Organization: Apple Inc.",
+		DownloadLocation: "NOASSERTION",
+		FilesAnalyzed:    false,
+		PrimaryPurpose:   "OPERATING-SYSTEM",
+		Comment:          fmt.Sprintf("build %s", b.Build),
+	}
+	if len(b.Devices) > 0 {
+		osPkg.Comment += fmt.Sprintf(" for %s", strings.Join(b.Devices, ", "))
+	}
+	doc.Packages = append(doc.Packages, osPkg)
+	doc.Relationships = append(doc.Relationships, spdxRelationship{
+		Element: doc.SPDXID, Type: "DESCRIBES", Related: osPkg.SPDXID,
+	})
+
+	ids := make(map[string]string) // binary path => SPDXID
+	for i, bin := range b.Binaries {
+		if bin.InCache || len(bin.SHA256) == 0 {
+			pkg := spdxPackage{
+				SPDXID:           fmt.Sprintf("SPDXRef-Binary-%d", i),
+				Name:             bin.Path,
+				VersionInfo:      bin.Version(),
+				Supplier:         "Organization: Apple Inc.",
+				DownloadLocation: "NOASSERTION",
+				FilesAnalyzed:    false,
+				PrimaryPurpose:   "LIBRARY",
+				Comment:          binComment(bin, false),
+			}
+			if !strings.Contains(bin.Path, ".dylib") && !strings.Contains(bin.Path, ".framework/") {
+				pkg.PrimaryPurpose = "APPLICATION"
+			}
+			doc.Packages = append(doc.Packages, pkg)
+			doc.Relationships = append(doc.Relationships, spdxRelationship{
+				Element: osPkg.SPDXID, Type: "CONTAINS", Related: pkg.SPDXID,
+			})
+			ids[bin.Path] = pkg.SPDXID
+			continue
+		}
+		file := spdxFile{
+			SPDXID:    fmt.Sprintf("SPDXRef-File-%d", i),
+			FileName:  "." + bin.Path,
+			FileTypes: []string{"BINARY"},
+			Checksums: []spdxSum{{Algorithm: "SHA1", Value: bin.SHA1}, {Algorithm: "SHA256", Value: bin.SHA256}},
+			Comment:   binComment(bin, true),
+		}
+		doc.Files = append(doc.Files, file)
+		doc.Relationships = append(doc.Relationships, spdxRelationship{
+			Element: osPkg.SPDXID, Type: "CONTAINS", Related: file.SPDXID,
+		})
+		ids[bin.Path] = file.SPDXID
+	}
+
+	for i, oss := range b.OSS {
+		pkg := spdxPackage{
+			SPDXID:           fmt.Sprintf("SPDXRef-OSS-%d", i),
+			Name:             oss.Name,
+			VersionInfo:      oss.Version,
+			Supplier:         "Organization: Apple Inc.",
+			DownloadLocation: "NOASSERTION",
+			FilesAnalyzed:    false,
+			PrimaryPurpose:   "SOURCE",
+			ExternalRefs: []spdxExtRef{{
+				Category: "PACKAGE-MANAGER",
+				Type:     "purl",
+				Locator:  oss.PURL(),
+			}},
+		}
+		if len(oss.URL) > 0 {
+			pkg.DownloadLocation = oss.URL
+		}
+		doc.Packages = append(doc.Packages, pkg)
+		for _, path := range oss.Binaries {
+			doc.Relationships = append(doc.Relationships, spdxRelationship{
+				Element: ids[path], Type: "GENERATED_FROM", Related: pkg.SPDXID,
+			})
+		}
+	}
+
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+func binComment(bin Binary, version bool) string {
+	var parts []string
+	if version && len(bin.Version()) > 0 {
+		parts = append(parts, "version "+bin.Version())
+	}
+	if len(bin.Identifier) > 0 {
+		parts = append(parts, "identifier "+bin.Identifier)
+	}
+	if len(bin.TeamID) > 0 {
+		parts = append(parts, "team "+bin.TeamID)
+	}
+	if len(bin.UUID) > 0 {
+		parts = append(parts, "uuid "+bin.UUID)
+	}
+	return strings.Join(parts, "; ")
+}
@@ -0,0 +1,289 @@
+// Package sig contains functions to inventory the code signatures of the MachOs in an IPSW
+package sig
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/blacktop/go-macho"
+	"github.com/blacktop/ipsw/internal/search"
+	"github.com/blacktop/ipsw/internal/utils"
+	"github.com/fatih/color"
+)
+
+// Signature is the code signing identity of a MachO
+type Signature struct {
+	ID     string `json:"id,omitempty"`
+	TeamID string `json:"team_id,omitempty"`
+	CDHash string `json:"cdhash,omitempty"`
+}
+
+// Signed returns true if the MachO had a code directory
+func (s Signature) Signed() bool {
+	return len(s.CDHash) > 0
+}
+
+func (s Signature) String() string {
+	if !s.Signed() {
+		return "unsigned"
+	}
+	if len(s.TeamID) > 0 {
+		return fmt.Sprintf("id=%s team=%s cdhash=%s", s.ID, s.TeamID, s.CDHash)
+	}
+	return fmt.Sprintf("id=%s cdhash=%s", s.ID, s.CDHash)
+}
+
+// Database is a map of file paths to their code signing identity
+type Database map[string]Signature
+
+// Config is the configuration for the signature commands
+type Config struct {
+	Markdown bool
+	Color    bool
+}
+
+// Query is a signature database query
+type Query struct {
+	ID     string
+	TeamID string
+	CDHash string
+	File   string
+}
+
+// GetDatabase returns the code signature database for the given IPSW
+func GetDatabase(ipswPath, sigDBPath string) (Database, error) {
+	sigDB := make(Database)
+
+	if _, err := os.Stat(sigDBPath); err == nil {
+		log.Info("Found ipsw code signature database file...")
+		return Load(sigDBPath)
+	}
+
+	utils.Indent(log.Info, 2)("Generating code signature database file...")
+
+	if err := search.ForEachMachoInIPSW(ipswPath, func(path string, m *macho.File) error {
+		sigDB[path] = GetSignature(m)
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("failed to scan IPSW MachOs: %v", err)
+	}
+
+	if len(sigDBPath) > 0 {
+		if err := sigDB.Save(sigDBPath); err != nil {
+			return nil, err
+		}
+	}
+
+	return sigDB, nil
+}
+
+// GetSignature returns the code signing identity of the first code directory in a MachO
+func GetSignature(m *macho.File) Signature {
+	cs := m.CodeSignature()
+	if cs == nil || len(cs.CodeDirectories) == 0 {
+		return Signature{}
+	}
+	// the last code directory is the alternate (SHA256) one if present
+	cd := cs.CodeDirectories[len(cs.CodeDirectories)-1]
+	return Signature{
+		ID:     cd.ID,
+		TeamID: cd.TeamID,
+		CDHash: cd.CDHash,
+	}
+}
+
+// Load reads a gzipped code signature database from disk
+func Load(sigDBPath string) (Database, error) {
+	sigDB := make(Database)
+
+	f, err := os.Open(sigDBPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open code signature database file %s; %v", sigDBPath, err)
+	}
+	defer f.Close()
+
+	gzr, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create gzip reader: %v", err)
+	}
+	defer gzr.Close()
+
+	if err := gob.NewDecoder(gzr).Decode(&sigDB); err != nil {
+		return nil, fmt.Errorf("failed to decode code signature database; %v", err)
+	}
+
+	return sigDB, nil
+}
+
+// Save writes the code signature database to disk as a gzipped gob
+func (db Database) Save(sigDBPath string) error {
+	buff := new(bytes.Buffer)
+	if err := gob.NewEncoder(buff).Encode(db); err != nil {
+		return fmt.Errorf("failed to encode code signature db to binary: %v", err)
+	}
+
+	of, err := os.Create(sigDBPath)
+	if err != nil {
+		return fmt.Errorf("failed to create file %s: %v", sigDBPath, err)
+	}
+	defer of.Close()
+
+	gzw := gzip.NewWriter(of)
+	defer gzw.Close()
+
+	if _, err := buff.WriteTo(gzw); err != nil {
+		return fmt.Errorf("failed to write code signature db to gzip file: %v", err)
+	}
+
+	return nil
+}
+
+// Files returns the sorted file paths in the database
+func (db Database) Files() []string {
+	files := make([]string, 0, len(db))
+	for f := range db {
+		files = append(files, f)
+	}
+	sort.Strings(files)
+	return files
+}
+
+// Query returns the subset of the database that matches ALL of the non-empty query fields
+func (db Database) Query(q Query) Database {
+	out := make(Database)
+	for f, s := range db {
+		if len(q.File) > 0 && !strings.Contains(strings.ToLower(f), strings.ToLower(q.File)) {
+			continue
+		}
+		if len(q.ID) > 0 && !strings.Contains(s.ID, q.ID) {
+			continue
+		}
+		if len(q.TeamID) > 0 && !strings.EqualFold(s.TeamID, q.TeamID) {
+			continue
+		}
+		if len(q.CDHash) > 0 && !strings.HasPrefix(strings.ToLower(s.CDHash), strings.ToLower(q.CDHash)) {
+			continue
+		}
+		out[f] = s
+	}
+	return out
+}
+
+// TeamIDs returns a map of team IDs to the number of binaries signed with them
+func (db Database) TeamIDs() map[string]int {
+	teams := make(map[string]int)
+	for _, s := range db {
+		if len(s.TeamID) > 0 {
+			teams[s.TeamID]++
+		}
+	}
+	return teams
+}
+
+// MarshalJSON exports the database as a list sorted by file path
+func (db Database) MarshalJSON() ([]byte, error) {
+	type entry struct {
+		Path string `json:"path"`
+		Signature
+	}
+	entries := make([]entry, 0, len(db))
+	for _, f := range db.Files() {
+		entries = append(entries, entry{Path: f, Signature: db[f]})
+	}
+	return json.Marshal(entries)
+}
+
+// DiffDatabases compares two code signature databases and returns a diff
+func DiffDatabases(db1, db2 Database, conf *Config) (string, error) {
+	var dat bytes.Buffer
+	buf := bufio.NewWriter(&dat)
+
+	var added, removed, changed []string
+
+	for _, f2 := range db2.Files() {
+		s2 := db2[f2]
+		s1, ok := db1[f2]
+		if !ok {
+			added = append(added, fmt.Sprintf("%s\t(%s)", f2, s2))
+			continue
+		}
+		var deltas []string
+		if s1.ID != s2.ID {
+			deltas = append(deltas, fmt.Sprintf("id: %s -> %s", s1.ID, s2.ID))
+		}
+		if s1.TeamID != s2.TeamID {
+			deltas = append(deltas, fmt.Sprintf("team: %s -> %s", s1.TeamID, s2.TeamID))
+		}
+		if s1.Signed() != s2.Signed() {
+			deltas = append(deltas, fmt.Sprintf("signed: %t -> %t", s1.Signed(), s2.Signed()))
+		} else if s1.CDHash != s2.CDHash {
+			deltas = append(deltas, fmt.Sprintf("cdhash: %s -> %s", s1.CDHash, s2.CDHash))
+		}
+		if len(deltas) > 0 {
+			changed = append(changed, fmt.Sprintf("%s\t(%s)", f2, strings.Join(deltas, ", ")))
+		}
+	}
+	for _, f1 := range db1.Files() {
+		if _, ok := db2[f1]; !ok {
+			removed = append(removed, fmt.Sprintf("%s\t(%s)", f1, db1[f1]))
+		}
+	}
+
+	colorize := func(attrs ...color.Attribute) *color.Color {
+		c := color.New(attrs...)
+		if conf.Color {
+			c.EnableColor()
+		} else {
+			c.DisableColor()
+		}
+		return c
+	}
+
+	section := func(title string, items []string, c *color.Color) {
+		if len(items) == 0 {
+			return
+		}
+		if conf.Markdown {
+			buf.WriteString(fmt.Sprintf("\n### %s\n\n", title))
+			for _, i := range items {
+				path, rest, _ := strings.Cut(i, "\t")
+				buf.WriteString(fmt.Sprintf("- `%s` %s\n", path, rest))
+			}
+		} else {
+			buf.WriteString(colorize(color.Bold).Sprintf("\n%s\n\n", title))
+			for _, i := range items {
+				buf.WriteString(fmt.Sprintf("  %s\n", c.Sprint(i)))
+			}
+		}
+	}
+
+	section("🆕 new binaries", added, colorize(color.FgGreen))
+	section("❌ removed binaries", removed, colorize(color.FgRed))
+	section("⚠️ changed signing identities", changed, colorize(color.FgYellow))
+
+	if len(added) == 0 && len(removed) == 0 && len(changed) == 0 {
+		buf.WriteString("- No differences found\n")
+	}
+
+	buf.Flush()
+
+	return dat.String(), nil
+}
+
+// DBPath returns the default code signature database path for an IPSW
+func DBPath(ipswPath, folder string) string {
+	sigDBPath := strings.TrimSuffix(ipswPath, filepath.Ext(ipswPath)) + ".sigDB"
+	if len(folder) > 0 {
+		sigDBPath = filepath.Join(folder, filepath.Base(sigDBPath))
+	}
+	return sigDBPath
+}
@@ -0,0 +1,151 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/apex/log"
+	"github.com/blacktop/ipsw/internal/models"
+	"github.com/blacktop/ipsw/internal/utils"
+)
+
+const indexTemplate = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{ .Title }}</title>
+<style>
+body { font-family: -apple-system, sans-serif; margin: 2em; }
+table { border-collapse: collapse; width: 100%; }
+th, td { text-align: left; padding: 4px 8px; border-bottom: 1px solid #ddd; }
+th { background: #f4f4f4; }
+.tag { background: #e8eefc; border-radius: 4px; padding: 1px 6px; margin-right: 4px; font-size: 0.9em; }
+</style>
+</head>
+<body>
+<h1>{{ .Title }}</h1>
+<p>{{ len .Firmware }} files (generated {{ .Generated.Format "2006-01-02 15:04:05 MST" }}, <a href="index.json">index.json</a>)</p>
+<table>
+<tr><th>File</th><th>Device</th><th>Version</th><th>Build</th><th>Size</th><th>Tags</th><th>Notes</th></tr>
+{{- range .Firmware }}
+<tr><td><a href="{{ .URL }}">{{ .Name }}</a></td><td>{{ .Device }}</td><td>{{ .Version }}</td><td>{{ .Build }}</td><td>{{ .Size | bytes }}</td><td>{{ range .Tags }}<span class="tag">{{ . }}</span>{{ end }}</td><td>{{ .Notes }}</td></tr>
+{{- end }}
+</table>
+</body>
+</html>
+`
+
+// Entry is a firmware file in the published index
+type Entry struct {
+	Name    string    `json:"name"`
+	URL     string    `json:"url"`
+	Device  string    `json:"device,omitempty"`
+	Version string    `json:"version,omitempty"`
+	Build   string    `json:"build,omitempty"`
+	Size    int64     `json:"size"`
+	Updated time.Time `json:"updated"`
+	Tags    []string  `json:"tags,omitempty"`
+	Notes   string    `json:"notes,omitempty"`
+}
+
+// Index is the published index of the local store
+type Index struct {
+	Title     string    `json:"title"`
+	Generated time.Time `json:"generated"`
+	Firmware  []Entry   `json:"firmware"`
+}
+
+// PublishConfig is the config for Publish
+type PublishConfig struct {
+	Output  string // folder to write index.json and index.html to
+	BaseURL string // URL the files are served from (links are relative to Output if empty)
+	Title   string
+}
+
+// link returns the URL of a stored file in the index
+func (c *PublishConfig) link(path string) (string, error) {
+	if len(c.BaseURL) > 0 {
+		return strings.TrimSuffix(c.BaseURL, "/") + "/" + url.PathEscape(filepath.Base(path)), nil
+	}
+	out, err := filepath.Abs(c.Output)
+	if err != nil {
+		return "", err
+	}
+	rel, err := filepath.Rel(out, path)
+	if err != nil {
+		return "", err
+	}
+	parts := strings.Split(filepath.ToSlash(rel), "/")
+	for i, p := range parts {
+		parts[i] = url.PathEscape(p)
+	}
+	return strings.Join(parts, "/"), nil
+}
+
+// Publish writes a static index.json and index.html of the stored firmware to conf.Output
+// (files that no longer exist are left out)
+func Publish(fws []models.StoredFirmware, conf *PublishConfig) (*Index, error) {
+	idx := &Index{Title: conf.Title, Generated: time.Now(), Firmware: []Entry{}}
+	if len(idx.Title) == 0 {
+		idx.Title = "Firmware Mirror"
+	}
+
+	for _, fw := range fws {
+		fi, err := os.Stat(fw.Path)
+		if err != nil {
+			log.Warnf("Skipping %s: %v", fw.Path, err)
+			continue
+		}
+		link, err := conf.link(fw.Path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to link %s: %v", fw.Path, err)
+		}
+		idx.Firmware = append(idx.Firmware, Entry{
+			Name:    filepath.Base(fw.Path),
+			URL:     link,
+			Device:  fw.Device,
+			Version: fw.Version,
+			Build:   fw.Build,
+			Size:    fi.Size(),
+			Updated: fi.ModTime(),
+			Tags:    fw.TagNames(),
+			Notes:   fw.Notes,
+		})
+	}
+
+	if err := os.MkdirAll(conf.Output, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create directory %s: %v", conf.Output, err)
+	}
+
+	dat, err := json.MarshalIndent(idx, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal index: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(conf.Output, "index.json"), dat, 0644); err != nil {
+		return nil, fmt.Errorf("failed to write index.json: %v", err)
+	}
+
+	tmpl := template.Must(template.New("index").
+		Funcs(template.FuncMap{
+			"bytes": func(size int64) string {
+				return utils.FormatBytes(uint64(size))
+			},
+		}).
+		Parse(indexTemplate))
+	f, err := os.Create(filepath.Join(conf.Output, "index.html"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create index.html: %v", err)
+	}
+	defer f.Close()
+	if err := tmpl.Execute(f, idx); err != nil {
+		return nil, fmt.Errorf("failed to execute index template: %v", err)
+	}
+
+	return idx, nil
+}
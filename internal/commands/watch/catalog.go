@@ -0,0 +1,249 @@
+package watch
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/blacktop/ipsw/internal/download"
+	"github.com/blacktop/ipsw/pkg/ota/types"
+	semver "github.com/hashicorp/go-version"
+)
+
+// CatalogSnapshot is the state of the mesu catalogs and pallas assets at a point in time
+type CatalogSnapshot struct {
+	Date time.Time `json:"date"`
+	// Assets is a map of asset type => asset URL => asset summary
+	Assets map[string]map[string]string `json:"assets"`
+}
+
+// CatalogDiff is the difference between two catalog snapshots
+type CatalogDiff struct {
+	NewAssetTypes     []string            `json:"new_asset_types,omitempty"`
+	RemovedAssetTypes []string            `json:"removed_asset_types,omitempty"`
+	NewAssets         map[string][]string `json:"new_assets,omitempty"`
+}
+
+// Empty returns true if there are no differences
+func (d CatalogDiff) Empty() bool {
+	return len(d.NewAssetTypes) == 0 && len(d.RemovedAssetTypes) == 0 && len(d.NewAssets) == 0
+}
+
+func (d CatalogDiff) String() string {
+	var sb strings.Builder
+	if len(d.NewAssetTypes) > 0 {
+		sb.WriteString("**New asset types:**\n")
+		for _, t := range d.NewAssetTypes {
+			sb.WriteString(fmt.Sprintf("- `%s`\n", t))
+		}
+	}
+	if len(d.RemovedAssetTypes) > 0 {
+		sb.WriteString("**Removed asset types:**\n")
+		for _, t := range d.RemovedAssetTypes {
+			sb.WriteString(fmt.Sprintf("- `%s`\n", t))
+		}
+	}
+	if len(d.NewAssets) > 0 {
+		var types []string
+		for t := range d.NewAssets {
+			types = append(types, t)
+		}
+		sort.Strings(types)
+		for _, t := range types {
+			sb.WriteString(fmt.Sprintf("**New `%s` assets:**\n", t))
+			for _, a := range d.NewAssets[t] {
+				sb.WriteString(fmt.Sprintf("- %s\n", a))
+			}
+		}
+	}
+	return sb.String()
+}
+
+// GetCatalogSnapshot downloads the mesu catalogs for the given MobileAsset types and
+// queries pallas for its asset sets and the OTAs of the given platforms
+func GetCatalogSnapshot(assetTypes, pallasPlatforms []string, proxy string, insecure bool) (*CatalogSnapshot, error) {
+	snap := &CatalogSnapshot{
+		Date:   time.Now(),
+		Assets: make(map[string]map[string]string),
+	}
+
+	for _, atype := range assetTypes {
+		cat, err := download.GetMesuCatalog(atype, proxy, insecure)
+		if err != nil {
+			return nil, err
+		}
+		if cat == nil { // not published (yet)
+			continue
+		}
+		for _, asset := range cat.Assets {
+			// catalogs can contain assets of a different type than the catalog itself (i.e. RSRs)
+			t := atype
+			if at, ok := asset["AssetType"].(string); ok && len(at) > 0 {
+				t = at
+			}
+			snap.add(t, download.AssetURL(asset), assetSummary(asset))
+		}
+	}
+
+	as, err := download.GetAssetSets(proxy, insecure)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pallas asset sets: %v", err)
+	}
+	for set, platforms := range map[string]map[string][]download.AssetSet{
+		"PublicAssetSets": as.PublicAssetSets,
+		"AssetSets":       as.AssetSets,
+	} {
+		for platform, sets := range platforms {
+			t := fmt.Sprintf("pallas:%s/%s", set, platform)
+			for _, aset := range sets {
+				snap.add(t, aset.ProductVersion+"@"+aset.PostingDate, assetSetSummary(aset))
+			}
+		}
+	}
+
+	for _, platform := range pallasPlatforms {
+		o, err := download.NewOTA(as, download.OtaConf{
+			Platform: strings.ToLower(platform),
+			Version:  semver.Must(semver.NewVersion("0")),
+			Build:    "0",
+			Proxy:    proxy,
+			Insecure: insecure,
+			Timeout:  90,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to query %s pallas OTAs: %v", platform, err)
+		}
+		otas, err := o.GetPallasOTAs()
+		if err != nil {
+			return nil, fmt.Errorf("failed to query %s pallas OTAs: %v", platform, err)
+		}
+		for _, ota := range otas {
+			snap.add("pallas:"+ota.AssetType, ota.BaseURL+ota.RelativePath, otaSummary(ota))
+		}
+	}
+
+	return snap, nil
+}
+
+func (s *CatalogSnapshot) add(atype, url, summary string) {
+	if _, ok := s.Assets[atype]; !ok {
+		s.Assets[atype] = make(map[string]string)
+	}
+	s.Assets[atype][url] = summary
+}
+
+func assetSetSummary(aset download.AssetSet) string {
+	parts := []string{fmt.Sprintf("ProductVersion: %s", aset.ProductVersion)}
+	if len(aset.PostingDate) > 0 {
+		parts = append(parts, fmt.Sprintf("PostingDate: %s", aset.PostingDate))
+	}
+	if len(aset.ExpirationDate) > 0 {
+		parts = append(parts, fmt.Sprintf("ExpirationDate: %s", aset.ExpirationDate))
+	}
+	if len(aset.SupportedDevices) > 0 {
+		parts = append(parts, fmt.Sprintf("devices: %d", len(aset.SupportedDevices)))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func otaSummary(ota types.Asset) string {
+	var parts []string
+	for _, kv := range [][2]string{
+		{"SUDocumentationID", ota.DocumentationID},
+		{"OSVersion", ota.Version()},
+		{"Build", ota.Build},
+		{"PrerequisiteBuild", ota.PrerequisiteBuild},
+	} {
+		if len(kv[1]) > 0 {
+			parts = append(parts, fmt.Sprintf("%s: %s", kv[0], kv[1]))
+		}
+	}
+	if len(ota.SupportedDevices) > 0 {
+		parts = append(parts, fmt.Sprintf("devices: %d", len(ota.SupportedDevices)))
+	}
+	if len(parts) == 0 {
+		return filepath.Base(ota.RelativePath)
+	}
+	return strings.Join(parts, ", ")
+}
+
+func assetSummary(asset map[string]any) string {
+	var parts []string
+	for _, key := range []string{"SUDocumentationID", "OSVersion", "ProductVersionExtra", "Build", "PrerequisiteBuild", "FontInfo4", "_CompatibilityVersion"} {
+		switch v := asset[key].(type) {
+		case string:
+			if len(v) > 0 {
+				parts = append(parts, fmt.Sprintf("%s: %s", key, v))
+			}
+		case int, int64, uint64:
+			parts = append(parts, fmt.Sprintf("%s: %d", key, v))
+		}
+	}
+	if devs, ok := asset["SupportedDevices"].([]any); ok && len(devs) > 0 {
+		parts = append(parts, fmt.Sprintf("devices: %d", len(devs)))
+	}
+	if len(parts) == 0 {
+		return filepath.Base(download.AssetURL(asset))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// DiffCatalogSnapshots returns the asset types and assets that are in new but not in old
+func DiffCatalogSnapshots(old, new *CatalogSnapshot) CatalogDiff {
+	diff := CatalogDiff{NewAssets: make(map[string][]string)}
+
+	for atype, assets := range new.Assets {
+		oldAssets, ok := old.Assets[atype]
+		if !ok {
+			diff.NewAssetTypes = append(diff.NewAssetTypes, atype)
+		}
+		for url, summary := range assets {
+			if _, seen := oldAssets[url]; !seen {
+				diff.NewAssets[atype] = append(diff.NewAssets[atype], summary)
+			}
+		}
+		sort.Strings(diff.NewAssets[atype])
+	}
+	for atype := range old.Assets {
+		if _, ok := new.Assets[atype]; !ok {
+			diff.RemovedAssetTypes = append(diff.RemovedAssetTypes, atype)
+		}
+	}
+
+	sort.Strings(diff.NewAssetTypes)
+	sort.Strings(diff.RemovedAssetTypes)
+	if len(diff.NewAssets) == 0 {
+		diff.NewAssets = nil
+	}
+
+	return diff
+}
+
+// LoadCatalogSnapshot reads a catalog snapshot from disk
+func LoadCatalogSnapshot(path string) (*CatalogSnapshot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var snap CatalogSnapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return nil, fmt.Errorf("failed to parse catalog snapshot %s: %v", path, err)
+	}
+	return &snap, nil
+}
+
+// Save writes the catalog snapshot to disk
+func (s *CatalogSnapshot) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return fmt.Errorf("failed to create directory %s: %v", filepath.Dir(path), err)
+	}
+	data, err := json.Marshal(s)
+	if err != nil {
+		return fmt.Errorf("failed to marshal catalog snapshot: %v", err)
+	}
+	return os.WriteFile(path, data, 0644)
+}
@@ -0,0 +1,200 @@
+package watch
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/blacktop/ipsw/internal/db"
+	"github.com/blacktop/ipsw/internal/download"
+	"github.com/blacktop/ipsw/internal/models"
+	"github.com/blacktop/ipsw/internal/utils"
+)
+
+// MirrorSources are where the mirror looks for new builds
+var MirrorSources = []string{"ipsw.me", "itunes"}
+
+// maxMirrorTries is the number of polls a failed download is retried on
+const maxMirrorTries = 3
+
+// Rule selects the builds to mirror (i.e. `{device: iPhone15,2, version: "17"}`)
+type Rule struct {
+	Device  string `json:"device" mapstructure:"device"`   // device identifier or class (i.e. iPhone15,2, iPhone or ios)
+	Version string `json:"version" mapstructure:"version"` // version (prefix) to match (i.e. 17 or 17.1)
+	Signed  bool   `json:"signed" mapstructure:"signed"`   // only builds that are still signed
+}
+
+func (r Rule) String() string {
+	s := r.Device
+	if len(r.Version) > 0 {
+		s += " " + r.Version
+	}
+	if r.Signed {
+		s += " (signed)"
+	}
+	return s
+}
+
+// Match returns true if the build matches the rule
+func (r Rule) Match(i download.IPSW) bool {
+	if len(r.Device) > 0 && !download.MatchDeviceFilter(i.Identifier, r.Device) {
+		return false
+	}
+	if len(r.Version) > 0 && i.Version != r.Version && !strings.HasPrefix(i.Version, r.Version+".") {
+		return false
+	}
+	if r.Signed && !i.Signed {
+		return false
+	}
+	return true
+}
+
+// Mirror polls sources for new builds matching its rules and downloads them to a mirror folder
+type Mirror struct {
+	Rules    []Rule
+	Sources  []string // where to look for new builds (see MirrorSources)
+	Pipeline *Pipeline
+	State    *db.MirrorDB
+}
+
+// MirrorEvent is a build the mirror downloaded (or failed to)
+type MirrorEvent struct {
+	Build models.MirroredBuild `json:"build"`
+	Err   error                `json:"-"`
+}
+
+// candidates returns the builds the sources have that match a rule
+func (m *Mirror) candidates() (map[string]download.IPSW, map[string]string, error) {
+	builds := make(map[string]download.IPSW)
+	sources := make(map[string]string)
+	add := func(source string, i download.IPSW) {
+		for _, r := range m.Rules {
+			if r.Match(i) {
+				key := i.Identifier + "/" + i.BuildID
+				if _, ok := builds[key]; !ok {
+					builds[key] = i
+					sources[key] = source
+				}
+				return
+			}
+		}
+	}
+
+	for _, source := range m.Sources {
+		switch source {
+		case "ipsw.me":
+			devices, err := download.GetAllDevices()
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to get devices from ipsw.me: %v", err)
+			}
+			for _, d := range devices {
+				if !m.wantsDevice(d.Identifier) {
+					continue
+				}
+				ipsws, err := download.GetDeviceIPSWs(d.Identifier)
+				if err != nil {
+					return nil, nil, fmt.Errorf("failed to get %s IPSWs from ipsw.me: %v", d.Identifier, err)
+				}
+				for _, i := range ipsws {
+					if len(i.Identifier) == 0 {
+						i.Identifier = d.Identifier
+					}
+					add(source, i)
+				}
+			}
+		case "itunes":
+			vm, err := download.NewiTunesVersionMaster()
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to get the iTunes version catalog: %v", err)
+			}
+			for _, i := range vm.GetIPSWs("", "", "") {
+				i.Signed = true // the catalog only lists the builds being signed
+				add(source, i)
+			}
+		default:
+			return nil, nil, fmt.Errorf("unknown mirror source '%s' (must be one of: %s)", source, strings.Join(MirrorSources, ", "))
+		}
+	}
+
+	return builds, sources, nil
+}
+
+func (m *Mirror) wantsDevice(identifier string) bool {
+	for _, r := range m.Rules {
+		if len(r.Device) == 0 || download.MatchDeviceFilter(identifier, r.Device) {
+			return true
+		}
+	}
+	return false
+}
+
+// Poll looks for new builds and downloads them
+//
+// The builds found by the first poll of an empty state DB are recorded as the baseline (and not
+// downloaded) unless backfill is set, so that the mirror starts with the builds released from then on.
+func (m *Mirror) Poll(backfill bool) ([]MirrorEvent, error) {
+	builds, sources, err := m.candidates()
+	if err != nil {
+		return nil, err
+	}
+
+	baseline := false
+	if !backfill {
+		if baseline, err = m.State.Empty(); err != nil {
+			return nil, err
+		}
+	}
+
+	var events []MirrorEvent
+	for key, i := range builds {
+		prev, err := m.State.Get(i.Identifier, i.BuildID)
+		if err != nil {
+			return events, err
+		}
+		b := models.MirroredBuild{
+			Device:  i.Identifier,
+			Version: i.Version,
+			Build:   i.BuildID,
+			URL:     i.URL,
+			Source:  sources[key],
+		}
+		if prev != nil {
+			if prev.Status != models.MirrorFailed || prev.Tries >= maxMirrorTries {
+				continue
+			}
+			b = *prev
+		}
+		if baseline {
+			b.Status = models.MirrorBaseline
+			if err := m.State.Save(&b); err != nil {
+				return events, err
+			}
+			continue
+		}
+
+		utils.Indent(log.Info, 2)(fmt.Sprintf("New build %s %s (%s) from %s", b.Device, b.Version, b.Build, b.Source))
+		p := *m.Pipeline
+		p.Output = filepath.Join(m.Pipeline.Output, b.Device)
+		res, perr := p.Run(Event{Device: b.Device, Version: b.Version, Build: b.Build, URL: i.URL, Sha1: i.SHA1, Source: b.Source})
+		b.Tries++
+		if perr != nil {
+			b.Status = models.MirrorFailed
+			b.Error = perr.Error()
+		} else {
+			b.Status = models.MirrorDownloaded
+			b.Path = res.IPSW
+			b.Error = ""
+		}
+		if err := m.State.Save(&b); err != nil {
+			return events, err
+		}
+		events = append(events, MirrorEvent{Build: b, Err: perr})
+	}
+
+	if baseline {
+		log.Infof("Recorded %d existing builds as the mirror baseline (only new builds are downloaded from now on)", len(builds))
+	}
+
+	return events, nil
+}
@@ -0,0 +1,144 @@
+package watch
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/blacktop/ipsw/internal/commands/extract"
+	"github.com/blacktop/ipsw/internal/download"
+	"github.com/blacktop/ipsw/internal/utils"
+)
+
+// Extractions are the artifacts a Pipeline can extract from a new build's IPSW
+var Extractions = []string{"kernel", "dyld"}
+
+// Event is a new build found by a watcher (or POSTed to the webhook receiver)
+type Event struct {
+	Device  string `json:"device"`            // device identifier (i.e. iPhone15,2)
+	Version string `json:"version,omitempty"` // OS version (i.e. 17.0)
+	Build   string `json:"build"`             // build ID (i.e. 21A329)
+	URL     string `json:"url,omitempty"`     // IPSW URL (looked up on ipsw.me if empty)
+	Sha1    string `json:"sha1,omitempty"`    // IPSW sha1 (looked up on ipsw.me if the URL is)
+	Source  string `json:"source,omitempty"`  // who reported the build (i.e. appledb)
+}
+
+func (e Event) String() string {
+	s := fmt.Sprintf("%s %s (%s)", e.Device, e.Version, e.Build)
+	if len(e.Version) == 0 {
+		s = fmt.Sprintf("%s (%s)", e.Device, e.Build)
+	}
+	if len(e.Source) > 0 {
+		s += " from " + e.Source
+	}
+	return s
+}
+
+// Validate checks that the event has enough information to fetch the build
+func (e Event) Validate() error {
+	if len(e.URL) > 0 {
+		return nil
+	}
+	if len(e.Device) == 0 || len(e.Build) == 0 {
+		return fmt.Errorf("event must have a url or a device and build")
+	}
+	return nil
+}
+
+// Pipeline downloads the IPSW of new builds and extracts artifacts from it
+type Pipeline struct {
+	Output   string   // folder to download and extract to
+	Extract  []string // artifacts to extract (see Extractions)
+	Proxy    string
+	Insecure bool
+}
+
+// Result is the outcome of running a Pipeline on an Event
+type Result struct {
+	Event     Event    `json:"event"`
+	IPSW      string   `json:"ipsw,omitempty"`
+	Extracted []string `json:"extracted,omitempty"`
+}
+
+// Run downloads the event's IPSW (unless it already exists) and extracts the pipeline's artifacts
+func (p *Pipeline) Run(ev Event) (*Result, error) {
+	if err := ev.Validate(); err != nil {
+		return nil, err
+	}
+	for _, x := range p.Extract {
+		if !utils.StrSliceHas(Extractions, x) {
+			return nil, fmt.Errorf("unknown extraction '%s' (must be one of: %s)", x, strings.Join(Extractions, ", "))
+		}
+	}
+
+	if len(ev.URL) == 0 {
+		ipsw, err := download.GetIPSW(ev.Device, ev.Build)
+		if err != nil {
+			return nil, fmt.Errorf("failed to look up IPSW for %s: %v", ev, err)
+		}
+		ev.URL, ev.Sha1 = ipsw.URL, ipsw.SHA1
+		if len(ev.Version) == 0 {
+			ev.Version = ipsw.Version
+		}
+	}
+
+	u, err := url.Parse(ev.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid url '%s': %v", ev.URL, err)
+	}
+	name := path.Base(u.Path)
+	if !filepath.IsLocal(name) || !strings.HasSuffix(strings.ToLower(name), ".ipsw") {
+		return nil, fmt.Errorf("url '%s' is not an IPSW file", ev.URL)
+	}
+
+	res := &Result{Event: ev, IPSW: filepath.Join(p.Output, name)}
+	if err := os.MkdirAll(filepath.Dir(res.IPSW), 0750); err != nil {
+		return nil, fmt.Errorf("failed to create directory %s: %v", filepath.Dir(res.IPSW), err)
+	}
+
+	if _, err := os.Stat(res.IPSW); os.IsNotExist(err) {
+		log.WithField("build", ev.String()).Info("Downloading IPSW")
+		d := download.NewDownload(p.Proxy, p.Insecure, false, true, false, false, false)
+		d.URL = ev.URL
+		d.Sha1 = ev.Sha1
+		d.DestName = res.IPSW
+		if err := d.Do(); err != nil {
+			return nil, fmt.Errorf("failed to download %s: %v", ev.URL, err)
+		}
+	} else {
+		log.Warnf("IPSW already exists: %s", res.IPSW)
+	}
+
+	conf := &extract.Config{
+		IPSW:     res.IPSW,
+		Proxy:    p.Proxy,
+		Insecure: p.Insecure,
+		Output:   p.Output,
+	}
+	for _, x := range p.Extract {
+		switch x {
+		case "kernel":
+			kcs, err := extract.Kernelcache(conf)
+			if err != nil {
+				return res, fmt.Errorf("failed to extract kernelcache: %v", err)
+			}
+			for kc := range kcs {
+				res.Extracted = append(res.Extracted, kc)
+			}
+			sort.Strings(res.Extracted)
+		case "dyld":
+			dscs, err := extract.DSC(conf)
+			if err != nil {
+				return res, fmt.Errorf("failed to extract dyld_shared_cache: %v", err)
+			}
+			res.Extracted = append(res.Extracted, dscs...)
+		}
+	}
+
+	return res, nil
+}
@@ -0,0 +1,139 @@
+package watch
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/apex/log"
+)
+
+const maxWebhookBody = 1 << 20
+
+// AppleCDNHosts are the hosts the IPSW URLs of webhook events may point to
+var AppleCDNHosts = []string{"updates.cdn-apple.com", "updates-http.cdn-apple.com", "appldnld.apple.com", "secure-appldnld.apple.com"}
+
+// Receiver is an http.Handler that queues the new build Events POSTed by external systems
+// (i.e. an AppleDB webhook or an internal release trigger)
+//
+// The body is an Event, a list of Events or an object with an "events" list; the IPSW URL of an
+// event must be on one of the AppleCDNHosts. When a Secret is set requests must authenticate with
+// it as a Bearer token or sign the body with it in a GitHub style X-Hub-Signature-256 header
+// (without one the Receiver must only be reachable from localhost).
+type Receiver struct {
+	Secret string
+	Events chan Event
+}
+
+// NewReceiver creates a Receiver that queues up to queue events
+func NewReceiver(secret string, queue int) *Receiver {
+	if queue < 1 {
+		queue = 1
+	}
+	return &Receiver{Secret: secret, Events: make(chan Event, queue)}
+}
+
+func (r *Receiver) authorized(req *http.Request, body []byte) bool {
+	if len(r.Secret) == 0 {
+		return true
+	}
+	if token, ok := strings.CutPrefix(req.Header.Get("Authorization"), "Bearer "); ok {
+		return subtle.ConstantTimeCompare([]byte(token), []byte(r.Secret)) == 1
+	}
+	if sig, ok := strings.CutPrefix(req.Header.Get("X-Hub-Signature-256"), "sha256="); ok {
+		got, err := hex.DecodeString(sig)
+		if err != nil {
+			return false
+		}
+		mac := hmac.New(sha256.New, []byte(r.Secret))
+		mac.Write(body)
+		return hmac.Equal(got, mac.Sum(nil))
+	}
+	return false
+}
+
+func parseEvents(body []byte) ([]Event, error) {
+	body = bytes.TrimSpace(body)
+	var events []Event
+	switch {
+	case bytes.HasPrefix(body, []byte("[")):
+		if err := json.Unmarshal(body, &events); err != nil {
+			return nil, err
+		}
+	default:
+		var payload struct {
+			Event
+			Events []Event `json:"events"`
+		}
+		if err := json.Unmarshal(body, &payload); err != nil {
+			return nil, err
+		}
+		events = payload.Events
+		if len(events) == 0 {
+			events = []Event{payload.Event}
+		}
+	}
+	for _, ev := range events {
+		if err := ev.Validate(); err != nil {
+			return nil, err
+		}
+		if len(ev.URL) > 0 {
+			u, err := url.Parse(ev.URL)
+			if err != nil {
+				return nil, fmt.Errorf("invalid url '%s': %v", ev.URL, err)
+			}
+			if (u.Scheme != "https" && u.Scheme != "http") || !slices.Contains(AppleCDNHosts, strings.ToLower(u.Hostname())) {
+				return nil, fmt.Errorf("url '%s' is not on an Apple CDN host (%s)", ev.URL, strings.Join(AppleCDNHosts, ", "))
+			}
+		}
+	}
+	return events, nil
+}
+
+func (r *Receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := io.ReadAll(io.LimitReader(req.Body, maxWebhookBody))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to read body: %v", err), http.StatusBadRequest)
+		return
+	}
+	if !r.authorized(req, body) {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	events, err := parseEvents(body)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("invalid event: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	var queued int
+	for _, ev := range events {
+		select {
+		case r.Events <- ev:
+			log.WithField("build", ev.String()).Info("Queued webhook event")
+			queued++
+		default:
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			json.NewEncoder(w).Encode(map[string]any{"queued": queued, "error": "event queue is full"})
+			return
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]any{"queued": queued})
+}
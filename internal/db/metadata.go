@@ -0,0 +1,322 @@
+package db
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/apex/log"
+	"github.com/blacktop/ipsw/internal/download"
+	"github.com/blacktop/ipsw/internal/exitcode"
+	"github.com/blacktop/ipsw/internal/models"
+	"github.com/blacktop/ipsw/internal/utils"
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// MetadataDB is a sqlite mirror of the ipsw.me devices, firmwares, OTAs and signing status.
+// It implements download.MetadataSource so lookups can be resolved offline.
+// Each sync also records the signing windows of the IPSWs so that they can be queried as of a past date.
+type MetadataDB struct {
+	Path string
+
+	db *gorm.DB
+}
+
+// MetadataStats are the number of rows in the metadata database.
+type MetadataStats struct {
+	Devices   int64     `json:"devices"`
+	Firmwares int64     `json:"firmwares"`
+	OTAs      int64     `json:"otas"`
+	Signed    int64     `json:"signed"`
+	SyncedAt  time.Time `json:"synced_at,omitempty"`
+}
+
+// DefaultMetadataPath returns the default metadata database path (~/.ipsw/metadata.db).
+func DefaultMetadataPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user home directory: %v", err)
+	}
+	return filepath.Join(home, ".ipsw", "metadata.db"), nil
+}
+
+// NewMetadataDB opens (or creates) the metadata database at path.
+func NewMetadataDB(path string) (*MetadataDB, error) {
+	db, err := gorm.Open(sqlite.Open(path), &gorm.Config{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect sqlite database: %w", err)
+	}
+	if err := db.AutoMigrate(&models.Device{}, &models.Firmware{}, &models.SigningWindow{}); err != nil {
+		return nil, fmt.Errorf("failed to migrate metadata database: %w", err)
+	}
+	return &MetadataDB{Path: path, db: db}, nil
+}
+
+// Sync mirrors all the devices and their firmwares (and OTAs if withOTAs) from the ipsw.me API.
+// NOTE: the download package's metadata source must not be this database while syncing (or it syncs from itself)
+func (m *MetadataDB) Sync(withOTAs bool) error {
+	devices, err := download.GetAllDevices()
+	if err != nil {
+		return fmt.Errorf("failed to get all devices from ipsw.me API: %v", err)
+	}
+
+	now := time.Now()
+	for _, d := range devices {
+		utils.Indent(log.Debug, 2)(fmt.Sprintf("Syncing %s (%s)", d.Name, d.Identifier))
+		dev, err := download.GetDevice(d.Identifier)
+		if err != nil {
+			return fmt.Errorf("failed to get device %s: %v", d.Identifier, err)
+		}
+		firmwares := toFirmwares(dev.Identifier, dev.Firmwares, false)
+		if withOTAs {
+			otas, err := download.GetDeviceOTAs(d.Identifier)
+			if err != nil {
+				return fmt.Errorf("failed to get device %s OTAs: %v", d.Identifier, err)
+			}
+			firmwares = append(firmwares, toFirmwares(dev.Identifier, otas, true)...)
+		}
+		if err := m.db.Transaction(func(tx *gorm.DB) error {
+			var known []string
+			if err := tx.Model(&models.Firmware{}).Where("identifier = ?", dev.Identifier).Pluck("url", &known).Error; err != nil {
+				return err
+			}
+			for _, f := range firmwares {
+				if f.OTA {
+					continue
+				}
+				// a build seen for the first time has been signed since it was released
+				at := now
+				if !slices.Contains(known, f.URL) && f.Signed && !f.ReleaseDate.IsZero() {
+					at = f.ReleaseDate
+				}
+				if _, err := recordSigning(tx, f.Identifier, f.Version, f.BuildID, f.Signed, at); err != nil {
+					return err
+				}
+			}
+			if err := tx.Clauses(clause.OnConflict{UpdateAll: true}).Create(&models.Device{
+				Identifier:  dev.Identifier,
+				Name:        dev.Name,
+				BoardConfig: dev.BoardConfig,
+				Platform:    dev.Platform,
+				CpID:        dev.CpID,
+				BdID:        dev.BdID,
+				SyncedAt:    now,
+			}).Error; err != nil {
+				return err
+			}
+			if len(firmwares) == 0 {
+				return nil
+			}
+			return tx.Clauses(clause.OnConflict{
+				Columns: []clause.Column{{Name: "url"}},
+				DoUpdates: clause.AssignmentColumns([]string{
+					"identifier", "version", "build_id", "sha1", "md5", "file_size", "release_date", "upload_date", "signed", "ota", "updated_at",
+				}),
+			}).CreateInBatches(firmwares, 100).Error
+		}); err != nil {
+			return fmt.Errorf("failed to save device %s: %v", d.Identifier, err)
+		}
+	}
+
+	return nil
+}
+
+func toFirmwares(identifier string, ipsws []download.IPSW, ota bool) []models.Firmware {
+	var firmwares []models.Firmware
+	for _, i := range ipsws {
+		if len(i.URL) == 0 {
+			continue
+		}
+		if len(i.Identifier) == 0 {
+			i.Identifier = identifier
+		}
+		firmwares = append(firmwares, models.Firmware{
+			Identifier:  i.Identifier,
+			Version:     i.Version,
+			BuildID:     i.BuildID,
+			SHA1:        i.SHA1,
+			MD5:         i.MD5,
+			FileSize:    i.FileSize,
+			URL:         i.URL,
+			ReleaseDate: i.ReleaseDate,
+			UploadDate:  i.UploadDate,
+			Signed:      i.Signed,
+			OTA:         ota,
+		})
+	}
+	return firmwares
+}
+
+func toIPSW(f models.Firmware) download.IPSW {
+	return download.IPSW{
+		Identifier:  f.Identifier,
+		Version:     f.Version,
+		BuildID:     f.BuildID,
+		SHA1:        f.SHA1,
+		MD5:         f.MD5,
+		FileSize:    f.FileSize,
+		URL:         f.URL,
+		ReleaseDate: f.ReleaseDate,
+		UploadDate:  f.UploadDate,
+		Signed:      f.Signed,
+	}
+}
+
+// GetDevice returns a synced device and its IPSWs (newest first like the ipsw.me API).
+func (m *MetadataDB) GetDevice(identifier string) (download.Device, error) {
+	var dev models.Device
+	if err := m.db.Where("identifier = ?", identifier).Limit(1).Find(&dev).Error; err != nil {
+		return download.Device{}, err
+	}
+	if len(dev.Identifier) == 0 {
+		return download.Device{}, exitcode.Errorf(exitcode.NotFound, "device %s not found in metadata DB %s", identifier, m.Path)
+	}
+	var firmwares []models.Firmware
+	if err := m.db.Where("identifier = ? AND ota = ?", identifier, false).Order("release_date desc").Find(&firmwares).Error; err != nil {
+		return download.Device{}, err
+	}
+	d := download.Device{
+		Name:        dev.Name,
+		Identifier:  dev.Identifier,
+		BoardConfig: dev.BoardConfig,
+		Platform:    dev.Platform,
+		CpID:        dev.CpID,
+		BdID:        dev.BdID,
+	}
+	for _, f := range firmwares {
+		d.Firmwares = append(d.Firmwares, toIPSW(f))
+	}
+	return d, nil
+}
+
+// GetBuild returns the first synced IPSW of a build ID.
+func (m *MetadataDB) GetBuild(buildID string) (download.IPSW, error) {
+	var firmwares []models.Firmware
+	if err := m.db.Where("build_id = ? AND ota = ?", buildID, false).Order("identifier desc").Limit(1).Find(&firmwares).Error; err != nil {
+		return download.IPSW{}, err
+	}
+	if len(firmwares) == 0 {
+		return download.IPSW{}, exitcode.Errorf(exitcode.NotFound, "build %s not found in metadata DB %s", buildID, m.Path)
+	}
+	return toIPSW(firmwares[0]), nil
+}
+
+// GetBuildID returns the build ID of a device's version.
+func (m *MetadataDB) GetBuildID(version, identifier string) (string, error) {
+	var firmwares []models.Firmware
+	if err := m.db.Where("version = ? AND identifier = ? AND ota = ?", version, identifier, false).Limit(1).Find(&firmwares).Error; err != nil {
+		return "", err
+	}
+	if len(firmwares) == 0 {
+		return "", exitcode.Errorf(exitcode.NotFound, "no build found for version %s and device %s in metadata DB %s", version, identifier, m.Path)
+	}
+	return firmwares[0].BuildID, nil
+}
+
+// FirmwareQuery filters the synced firmwares.
+type FirmwareQuery struct {
+	Device string    // device identifier (all devices if empty)
+	Signed bool      // only the signed firmwares
+	Latest bool      // only the latest firmware of each device
+	OTA    bool      // OTAs instead of IPSWs
+	AsOf   time.Time // as of a past date (now if zero)
+}
+
+// Firmwares returns the synced firmwares that match the query (newest first).
+//
+// As of a past date, the firmwares released after it are excluded and the signing status is
+// the one of the signing windows recorded by the syncs (so it is only known since the first sync).
+func (m *MetadataDB) Firmwares(q *FirmwareQuery) ([]download.IPSW, error) {
+	var firmwares []models.Firmware
+	tx := m.db.Where("ota = ?", q.OTA)
+	if len(q.Device) > 0 {
+		tx = tx.Where("identifier = ?", q.Device)
+	}
+	if !q.AsOf.IsZero() {
+		tx = tx.Where("release_date <= ?", q.AsOf)
+	}
+	if err := tx.Order("release_date desc").Find(&firmwares).Error; err != nil {
+		return nil, err
+	}
+
+	var signed map[string]bool
+	if !q.AsOf.IsZero() && !q.OTA {
+		windows, err := signedAsOf(m.db, q.Device, q.AsOf)
+		if err != nil {
+			return nil, err
+		}
+		signed = make(map[string]bool)
+		for _, w := range windows {
+			signed[w.Device+"/"+w.Build] = true
+		}
+	}
+
+	var ipsws []download.IPSW
+	latest := make(map[string]bool)
+	for _, f := range firmwares {
+		if signed != nil {
+			f.Signed = signed[f.Identifier+"/"+f.BuildID]
+		}
+		if q.Signed && !f.Signed {
+			continue
+		}
+		if q.Latest {
+			if latest[f.Identifier] {
+				continue
+			}
+			latest[f.Identifier] = true
+		}
+		ipsws = append(ipsws, toIPSW(f))
+	}
+	return ipsws, nil
+}
+
+// FirstSync returns when the database was first synced (the signing status before it is incomplete).
+func (m *MetadataDB) FirstSync() (time.Time, error) {
+	var first []models.Firmware
+	if err := m.db.Order("created_at").Limit(1).Find(&first).Error; err != nil {
+		return time.Time{}, err
+	}
+	if len(first) == 0 {
+		return time.Time{}, nil
+	}
+	return first[0].CreatedAt, nil
+}
+
+// Stats returns the number of synced devices, firmwares and OTAs.
+func (m *MetadataDB) Stats() (*MetadataStats, error) {
+	var s MetadataStats
+	if err := m.db.Model(&models.Device{}).Count(&s.Devices).Error; err != nil {
+		return nil, err
+	}
+	if err := m.db.Model(&models.Firmware{}).Where("ota = ?", false).Count(&s.Firmwares).Error; err != nil {
+		return nil, err
+	}
+	if err := m.db.Model(&models.Firmware{}).Where("ota = ?", true).Count(&s.OTAs).Error; err != nil {
+		return nil, err
+	}
+	if err := m.db.Model(&models.Firmware{}).Where("signed = ?", true).Count(&s.Signed).Error; err != nil {
+		return nil, err
+	}
+	var last []models.Device
+	if err := m.db.Order("synced_at desc").Limit(1).Find(&last).Error; err != nil {
+		return nil, err
+	}
+	if len(last) > 0 {
+		s.SyncedAt = last[0].SyncedAt
+	}
+	return &s, nil
+}
+
+// Close closes the database.
+func (m *MetadataDB) Close() error {
+	db, err := m.db.DB()
+	if err != nil {
+		return err
+	}
+	return db.Close()
+}
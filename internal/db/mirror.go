@@ -0,0 +1,91 @@
+package db
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/blacktop/ipsw/internal/models"
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+)
+
+// MirrorDB is a sqlite database of the builds seen (and downloaded) by the firmware mirror daemon.
+type MirrorDB struct {
+	Path string
+
+	db *gorm.DB
+}
+
+// DefaultMirrorPath returns the default mirror state database path (~/.ipsw/mirror.db).
+func DefaultMirrorPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user home directory: %v", err)
+	}
+	return filepath.Join(home, ".ipsw", "mirror.db"), nil
+}
+
+// NewMirrorDB opens (or creates) the mirror state database at path.
+func NewMirrorDB(path string) (*MirrorDB, error) {
+	db, err := gorm.Open(sqlite.Open(path), &gorm.Config{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect sqlite database: %w", err)
+	}
+	if err := db.AutoMigrate(&models.MirroredBuild{}); err != nil {
+		return nil, fmt.Errorf("failed to migrate mirror database: %w", err)
+	}
+	return &MirrorDB{Path: path, db: db}, nil
+}
+
+// Empty returns true if no build was ever recorded.
+func (m *MirrorDB) Empty() (bool, error) {
+	var count int64
+	if err := m.db.Model(&models.MirroredBuild{}).Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count == 0, nil
+}
+
+// Get returns the recorded state of a device's build (nil if it was never seen).
+func (m *MirrorDB) Get(device, build string) (*models.MirroredBuild, error) {
+	var builds []models.MirroredBuild
+	if err := m.db.Where("device = ? AND build = ?", device, build).Limit(1).Find(&builds).Error; err != nil {
+		return nil, err
+	}
+	if len(builds) == 0 {
+		return nil, nil
+	}
+	return &builds[0], nil
+}
+
+// Save records the state of a build.
+func (m *MirrorDB) Save(b *models.MirroredBuild) error {
+	if b.ID == 0 {
+		if prev, err := m.Get(b.Device, b.Build); err != nil {
+			return err
+		} else if prev != nil {
+			b.Model = prev.Model
+		}
+	}
+	return m.db.Save(b).Error
+}
+
+// List returns the recorded builds (with a status if not empty) newest first.
+func (m *MirrorDB) List(status string) ([]models.MirroredBuild, error) {
+	var builds []models.MirroredBuild
+	tx := m.db.Model(&models.MirroredBuild{})
+	if len(status) > 0 {
+		tx = tx.Where("status = ?", status)
+	}
+	return builds, tx.Order("updated_at desc").Find(&builds).Error
+}
+
+// Close closes the database.
+func (m *MirrorDB) Close() error {
+	db, err := m.db.DB()
+	if err != nil {
+		return err
+	}
+	return db.Close()
+}
@@ -0,0 +1,141 @@
+package db
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/blacktop/ipsw/internal/models"
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+)
+
+// SigningDB is a sqlite database of firmware signing windows.
+type SigningDB struct {
+	Path string
+
+	db *gorm.DB
+}
+
+// DefaultSigningPath returns the default signing window database path (~/.ipsw/signing.db).
+func DefaultSigningPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user home directory: %v", err)
+	}
+	return filepath.Join(home, ".ipsw", "signing.db"), nil
+}
+
+// NewSigningDB opens (or creates) the signing window database at path.
+func NewSigningDB(path string) (*SigningDB, error) {
+	db, err := gorm.Open(sqlite.Open(path), &gorm.Config{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect sqlite database: %w", err)
+	}
+	if err := db.AutoMigrate(&models.SigningWindow{}); err != nil {
+		return nil, fmt.Errorf("failed to migrate signing database: %w", err)
+	}
+	return &SigningDB{Path: path, db: db}, nil
+}
+
+// Record records a TSS check of a device's build.
+// It opens a signing window when the build becomes signed and closes it when it stops being signed.
+// It returns true if the signing status of the build changed.
+func (s *SigningDB) Record(device, version, build string, signed bool, at time.Time) (bool, error) {
+	return recordSigning(s.db, device, version, build, signed, at)
+}
+
+// recordSigning opens or closes the signing window of a device's build (see SigningDB.Record).
+func recordSigning(db *gorm.DB, device, version, build string, signed bool, at time.Time) (bool, error) {
+	var windows []models.SigningWindow
+	if err := db.Where("device = ? AND build = ? AND stop IS NULL", device, build).Order("start desc").Limit(1).Find(&windows).Error; err != nil {
+		return false, err
+	}
+	found := len(windows) > 0
+	var open models.SigningWindow
+	if found {
+		open = windows[0]
+	}
+
+	switch {
+	case signed && !found: // signing started
+		return true, db.Create(&models.SigningWindow{
+			Device:      device,
+			Version:     version,
+			Build:       build,
+			Start:       at,
+			LastChecked: at,
+		}).Error
+	case !signed && found: // signing stopped
+		open.Stop = &at
+		open.LastChecked = at
+		return true, db.Save(&open).Error
+	case found:
+		open.LastChecked = at
+		return false, db.Save(&open).Error
+	}
+	return false, nil
+}
+
+// Open returns the signing windows that are still open (for a device if not empty).
+func (s *SigningDB) Open(device string) ([]models.SigningWindow, error) {
+	var windows []models.SigningWindow
+	tx := s.db.Where("stop IS NULL")
+	if len(device) > 0 {
+		tx = tx.Where("device = ?", device)
+	}
+	return windows, tx.Order("start").Find(&windows).Error
+}
+
+// History returns the signing windows (optionally filtered by device and build) oldest first.
+func (s *SigningDB) History(device, build string) ([]models.SigningWindow, error) {
+	var windows []models.SigningWindow
+	tx := s.db.Model(&models.SigningWindow{})
+	if len(device) > 0 {
+		tx = tx.Where("device = ?", device)
+	}
+	if len(build) > 0 {
+		tx = tx.Where("build = ?", build)
+	}
+	return windows, tx.Order("start").Find(&windows).Error
+}
+
+// AsOf returns the signing windows that were open at a time (for a device if not empty).
+func (s *SigningDB) AsOf(device string, at time.Time) ([]models.SigningWindow, error) {
+	return signedAsOf(s.db, device, at)
+}
+
+func signedAsOf(db *gorm.DB, device string, at time.Time) ([]models.SigningWindow, error) {
+	var windows []models.SigningWindow
+	tx := db.Where("start <= ? AND (stop IS NULL OR stop > ?)", at, at)
+	if len(device) > 0 {
+		tx = tx.Where("device = ?", device)
+	}
+	return windows, tx.Order("start").Find(&windows).Error
+}
+
+// ParseAsOf parses the date of a time-travel query (i.e. 2023-06-01 or 2023-06-01T12:00:00Z).
+// A date without a time is the end of that day (so the builds of that day are included).
+func ParseAsOf(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation(time.DateTime, s, time.Local); err == nil {
+		return t, nil
+	}
+	t, err := time.ParseInLocation(time.DateOnly, s, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %#v (expected YYYY-MM-DD or RFC3339)", s)
+	}
+	return t.AddDate(0, 0, 1).Add(-time.Nanosecond), nil
+}
+
+// Close closes the database.
+func (s *SigningDB) Close() error {
+	db, err := s.db.DB()
+	if err != nil {
+		return err
+	}
+	return db.Close()
+}
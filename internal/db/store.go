@@ -0,0 +1,102 @@
+package db
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/blacktop/ipsw/internal/models"
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// StoreDB is a sqlite database of the firmware files in the local store (with their tags and notes).
+type StoreDB struct {
+	Path string
+
+	db *gorm.DB
+}
+
+// DefaultStorePath returns the default local store database path (~/.ipsw/store.db).
+func DefaultStorePath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user home directory: %v", err)
+	}
+	return filepath.Join(home, ".ipsw", "store.db"), nil
+}
+
+// NewStoreDB opens (or creates) the local store database at path.
+func NewStoreDB(path string) (*StoreDB, error) {
+	db, err := gorm.Open(sqlite.Open(path), &gorm.Config{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect sqlite database: %w", err)
+	}
+	if err := db.AutoMigrate(&models.StoredFirmware{}, &models.StoreTag{}); err != nil {
+		return nil, fmt.Errorf("failed to migrate store database: %w", err)
+	}
+	return &StoreDB{Path: path, db: db}, nil
+}
+
+// Get returns the stored firmware at path (nil if it is not in the store).
+func (s *StoreDB) Get(path string) (*models.StoredFirmware, error) {
+	var fws []models.StoredFirmware
+	if err := s.db.Preload("Tags").Where("path = ?", path).Limit(1).Find(&fws).Error; err != nil {
+		return nil, err
+	}
+	if len(fws) == 0 {
+		return nil, nil
+	}
+	return &fws[0], nil
+}
+
+// Add adds a firmware to the store (or updates its device, version and build if already stored).
+func (s *StoreDB) Add(fw *models.StoredFirmware) error {
+	return s.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "path"}},
+		DoUpdates: clause.AssignmentColumns([]string{"updated_at", "device", "version", "build"}),
+	}).Omit("Tags").Create(fw).Error
+}
+
+// Tag attaches tags to a stored firmware.
+func (s *StoreDB) Tag(fw *models.StoredFirmware, tags ...string) error {
+	for _, tag := range tags {
+		if err := s.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.StoreTag{
+			StoredFirmwareID: fw.ID,
+			Name:             tag,
+		}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Untag removes tags from a stored firmware.
+func (s *StoreDB) Untag(fw *models.StoredFirmware, tags ...string) error {
+	return s.db.Where("stored_firmware_id = ? AND name IN ?", fw.ID, tags).Delete(&models.StoreTag{}).Error
+}
+
+// Annotate sets the notes of a stored firmware.
+func (s *StoreDB) Annotate(fw *models.StoredFirmware, notes string) error {
+	return s.db.Model(fw).Update("notes", notes).Error
+}
+
+// List returns the stored firmware that has all of the tags (every firmware if there are none).
+func (s *StoreDB) List(tags []string) ([]models.StoredFirmware, error) {
+	var fws []models.StoredFirmware
+	tx := s.db.Preload("Tags")
+	for _, tag := range tags {
+		tx = tx.Where("id IN (?)", s.db.Model(&models.StoreTag{}).Select("stored_firmware_id").Where("name = ?", tag))
+	}
+	return fws, tx.Order("device, build").Find(&fws).Error
+}
+
+// Close closes the database.
+func (s *StoreDB) Close() error {
+	db, err := s.db.DB()
+	if err != nil {
+		return err
+	}
+	return db.Close()
+}
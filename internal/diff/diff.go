@@ -19,6 +19,7 @@ import (
 	"github.com/blacktop/ipsw/internal/commands/dwarf"
 	"github.com/blacktop/ipsw/internal/commands/ent"
 	"github.com/blacktop/ipsw/internal/commands/extract"
+	"github.com/blacktop/ipsw/internal/commands/sig"
 	"github.com/blacktop/ipsw/internal/utils"
 	"github.com/blacktop/ipsw/pkg/dyld"
 	"github.com/blacktop/ipsw/pkg/info"
@@ -72,6 +73,7 @@ type Diff struct {
 	Kexts  string
 	KDKs   string
 	Ents   string
+	Sigs   string
 	Dylibs struct {
 		New     string
 		Removed string
@@ -202,6 +204,12 @@ func (d *Diff) Diff() (err error) {
 		return err
 	}
 
+	log.Info("Diffing CODE SIGNATURES")
+	d.Sigs, err = d.parseSignatures()
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -506,6 +514,23 @@ func (d *Diff) parseEntitlements() (string, error) {
 	})
 }
 
+func (d *Diff) parseSignatures() (string, error) {
+	oldDB, err := sig.GetDatabase(d.Old.IPSWPath, filepath.Join(d.tmpDir, filepath.Base(d.Old.IPSWPath+".sigDB")))
+	if err != nil {
+		return "", err
+	}
+
+	newDB, err := sig.GetDatabase(d.New.IPSWPath, filepath.Join(d.tmpDir, filepath.Base(d.New.IPSWPath+".sigDB")))
+	if err != nil {
+		return "", err
+	}
+
+	return sig.DiffDatabases(oldDB, newDB, &sig.Config{
+		Markdown: true,
+		Color:    false,
+	})
+}
+
 func (d *Diff) parseLaunchdPlists() error {
 	oldConfig, err := extract.LaunchdConfig(d.Old.IPSWPath)
 	if err != nil {
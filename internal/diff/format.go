@@ -28,6 +28,9 @@ const diffMarkdownTemplate = `
 		- [Kexts](#kexts)
 {{- if .Ents }}
 	- [Entitlements](#entitlements)
+{{- end }}
+{{- if .Sigs }}
+	- [Code Signatures](#code-signatures)
 {{- end }}		
 	- [DSC](#dsc)
 		- [WebKit](#webkit)
@@ -74,6 +77,11 @@ const diffMarkdownTemplate = `
 
 {{ .Ents | noescape }}
 
+{{ if .Sigs }}
+## Code Signatures
+{{ .Sigs | noescape }}
+{{end -}}
+
 {{ if .Launchd }}
 ## launchd Config
 {{ .Launchd | noescape }}
@@ -0,0 +1,178 @@
+package diff
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/blacktop/go-macho"
+	"github.com/blacktop/go-macho/types"
+	"github.com/blacktop/ipsw/internal/utils"
+	"github.com/blacktop/ipsw/pkg/kernelcache"
+)
+
+// KernelDiff is the kext and symbol diff of two (decompressed) kernelcaches
+type KernelDiff struct {
+	Title string
+
+	OldPath    string
+	NewPath    string
+	OldVersion *kernelcache.Version
+	NewVersion *kernelcache.Version
+
+	Kexts          string   // diff of the kext bundle IDs and versions
+	NewSymbols     []string // "<fileset entry>: <symbol>"
+	RemovedSymbols []string // "<fileset entry>: <symbol>"
+}
+
+// kernelVersion returns the version of the kernelcache at path
+func kernelVersion(path string) (*kernelcache.Version, error) {
+	m, err := macho.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open kernelcache %s: %v", path, err)
+	}
+	defer m.Close()
+	return kernelcache.GetVersion(m)
+}
+
+// KernelSymbols returns the sorted symbols of the kernelcache at path prefixed with the fileset entry they are in
+func KernelSymbols(path string) ([]string, error) {
+	m, err := macho.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open kernelcache %s: %v", path, err)
+	}
+	defer m.Close()
+
+	seen := make(map[string]bool)
+	add := func(entry string, f *macho.File) {
+		if f.Symtab == nil {
+			return
+		}
+		for _, sym := range f.Symtab.Syms {
+			if len(sym.Name) > 0 {
+				seen[fmt.Sprintf("%s: %s", entry, sym.Name)] = true
+			}
+		}
+	}
+
+	if m.FileTOC.FileHeader.Type == types.MH_FILESET {
+		for _, fe := range m.FileSets() {
+			entry, err := m.GetFileSetFileByName(fe.EntryID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse file-set entry %s: %v", fe.EntryID, err)
+			}
+			add(fe.EntryID, entry)
+		}
+	} else {
+		add("kernel", m)
+	}
+
+	syms := make([]string, 0, len(seen))
+	for sym := range seen {
+		syms = append(syms, sym)
+	}
+	sort.Strings(syms)
+	return syms, nil
+}
+
+// DiffKernelcaches diffs the kexts and symbols of two kernelcaches
+func DiffKernelcaches(title, oldPath, newPath string) (*KernelDiff, error) {
+	var err error
+
+	kd := &KernelDiff{
+		Title:   title,
+		OldPath: oldPath,
+		NewPath: newPath,
+	}
+
+	if kd.OldVersion, err = kernelVersion(oldPath); err != nil {
+		return nil, fmt.Errorf("failed to get 'Old' kernelcache version: %v", err)
+	}
+	if kd.NewVersion, err = kernelVersion(newPath); err != nil {
+		return nil, fmt.Errorf("failed to get 'New' kernelcache version: %v", err)
+	}
+
+	oldKexts, err := kernelcache.KextList(oldPath, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list 'Old' kernelcache kexts: %v", err)
+	}
+	newKexts, err := kernelcache.KextList(newPath, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list 'New' kernelcache kexts: %v", err)
+	}
+	out, err := utils.GitDiff(
+		strings.Join(oldKexts, "\n")+"\n",
+		strings.Join(newKexts, "\n")+"\n",
+		&utils.GitDiffConfig{Color: false, Tool: "git"})
+	if err != nil {
+		return nil, err
+	}
+	kd.Kexts = out
+
+	oldSyms, err := KernelSymbols(oldPath)
+	if err != nil {
+		return nil, err
+	}
+	newSyms, err := KernelSymbols(newPath)
+	if err != nil {
+		return nil, err
+	}
+	kd.NewSymbols, kd.RemovedSymbols = sortedSetDiff(oldSyms, newSyms)
+
+	return kd, nil
+}
+
+// sortedSetDiff returns the strings only in b (added) and only in a (removed); a and b must be sorted
+func sortedSetDiff(a, b []string) (added, removed []string) {
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			i++
+			j++
+		case a[i] < b[j]:
+			removed = append(removed, a[i])
+			i++
+		default:
+			added = append(added, b[j])
+			j++
+		}
+	}
+	removed = append(removed, a[i:]...)
+	added = append(added, b[j:]...)
+	return added, removed
+}
+
+// String returns the diff as a markdown report
+func (kd *KernelDiff) String() string {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "# %s\n\n", kd.Title)
+
+	sb.WriteString("## Version\n\n")
+	fmt.Fprintf(&sb, "- Old: `%s`\n", strings.ReplaceAll(kd.OldVersion.String(), "\n", " "))
+	fmt.Fprintf(&sb, "- New: `%s`\n\n", strings.ReplaceAll(kd.NewVersion.String(), "\n", " "))
+
+	sb.WriteString("## Kexts\n\n")
+	if len(kd.Kexts) == 0 {
+		sb.WriteString("- No differences found\n\n")
+	} else {
+		sb.WriteString("```diff\n" + kd.Kexts + "\n```\n\n")
+	}
+
+	sb.WriteString("## Symbols\n\n")
+	if len(kd.NewSymbols) == 0 && len(kd.RemovedSymbols) == 0 {
+		sb.WriteString("- No differences found\n")
+		return sb.String()
+	}
+	if len(kd.NewSymbols) > 0 {
+		fmt.Fprintf(&sb, "### 🆕 symbols (%d)\n\n", len(kd.NewSymbols))
+		sb.WriteString("```\n" + strings.Join(kd.NewSymbols, "\n") + "\n```\n\n")
+	}
+	if len(kd.RemovedSymbols) > 0 {
+		fmt.Fprintf(&sb, "### ❌ symbols (%d)\n\n", len(kd.RemovedSymbols))
+		sb.WriteString("```\n" + strings.Join(kd.RemovedSymbols, "\n") + "\n```\n")
+	}
+
+	return sb.String()
+}
@@ -1,7 +1,6 @@
 package download
 
 import (
-	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -14,7 +13,6 @@ import (
 	"strings"
 	"time"
 
-	"github.com/apex/log"
 	"github.com/blacktop/ipsw/internal/utils"
 )
 
@@ -163,6 +161,36 @@ func (fs OsFiles) Query(query *ADBQuery) []OsFileSource {
 	return sources
 }
 
+// LatestBetas returns the n most recently released betas for device (newest first)
+func (fs OsFiles) LatestBetas(device string, n int) OsFiles {
+	var betas OsFiles
+	for _, f := range fs {
+		if f.Beta && len(f.IPSWURL(device)) > 0 {
+			betas = append(betas, f)
+		}
+	}
+	sort.Stable(betas)
+	if len(betas) > n {
+		betas = betas[:n]
+	}
+	return betas
+}
+
+// IPSWURL returns the URL of the IPSW for device (empty if there is none)
+func (f AppleDbOsFile) IPSWURL(device string) string {
+	for _, source := range f.Sources {
+		if source.Type != "ipsw" || !slices.Contains(source.DeviceMap, device) {
+			continue
+		}
+		for _, link := range source.Links {
+			if link.Active {
+				return link.URL
+			}
+		}
+	}
+	return ""
+}
+
 type ADBQuery struct {
 	OSes      []string
 	Version   string
@@ -177,10 +205,20 @@ type ADBQuery struct {
 }
 
 func LocalAppleDBQuery(q *ADBQuery) ([]OsFileSource, error) {
+	osfiles, err := LocalAppleDBOsFiles(q)
+	if err != nil {
+		return nil, err
+	}
+	return osfiles.Query(q), nil
+}
+
+// LocalAppleDBOsFiles returns the osFiles of the local copy of the AppleDB repo (cloned/refreshed in q.ConfigDir)
+// for q.OSes that match q.Version and q.Build
+func LocalAppleDBOsFiles(q *ADBQuery) (OsFiles, error) {
 	var osfiles OsFiles
 
 	if _, err := os.Stat(filepath.Join(q.ConfigDir, "appledb")); os.IsNotExist(err) {
-		utils.Indent(log.Info, 2)(fmt.Sprintf("Git cloning local 'appledb' to %s", filepath.Join(q.ConfigDir, "appledb")))
+		utils.Indent(getLogger().Info, 2)(fmt.Sprintf("Git cloning local 'appledb' to %s", filepath.Join(q.ConfigDir, "appledb")))
 		if _, err := utils.GitClone(AppleDBGitURL, filepath.Join(q.ConfigDir, "appledb")); err != nil {
 			return nil, fmt.Errorf("failed to create local copy of 'appledb' repo: %v", err)
 		}
@@ -223,7 +261,7 @@ func LocalAppleDBQuery(q *ADBQuery) ([]OsFileSource, error) {
 					return err
 				}
 				if err := json.Unmarshal(dat, &osfile); err != nil {
-					log.Errorf("failed to unmarshal osfile for version %s (%s): %v", osfile.Version, osfile.Build, err)
+					getLogger().Errorf("failed to unmarshal osfile for version %s (%s): %v", osfile.Version, osfile.Build, err)
 					return nil
 				}
 				osfiles = append(osfiles, osfile)
@@ -234,11 +272,51 @@ func LocalAppleDBQuery(q *ADBQuery) ([]OsFileSource, error) {
 		}
 	}
 
-	return osfiles.Query(q), nil
+	return osfiles, nil
 }
 
 func AppleDBQuery(q *ADBQuery) ([]OsFileSource, error) {
+	return NewAppleDBClient().Query(q)
+}
+
+// AppleDBOsFiles returns the osFiles for q.OSes that match q.Version and q.Build using the Github API
+func AppleDBOsFiles(q *ADBQuery) (OsFiles, error) {
+	return NewAppleDBClient().OsFiles(q)
+}
+
+// AppleDBClient is a client of the AppleDB Github API with its own transport (see WithTransport)
+type AppleDBClient struct {
+	transport http.RoundTripper
+}
+
+// NewAppleDBClient returns a new AppleDB client (without WithTransport it uses the shared transport
+// of each query's proxy and insecure settings)
+func NewAppleDBClient(opts ...ClientOption) *AppleDBClient {
+	o := newClientOptions(opts)
+	return &AppleDBClient{transport: o.transport}
+}
+
+func (c *AppleDBClient) httpClient(q *ADBQuery) *http.Client {
+	rt := c.transport
+	if rt == nil {
+		rt = NewTransport(q.Proxy, q.Insecure)
+	}
+	return &http.Client{Transport: BudgetTransport(rt)}
+}
+
+// Query returns the sources of the osFiles that match q using the Github API
+func (c *AppleDBClient) Query(q *ADBQuery) ([]OsFileSource, error) {
+	osfiles, err := c.OsFiles(q)
+	if err != nil {
+		return nil, err
+	}
+	return osfiles.Query(q), nil
+}
+
+// OsFiles returns the osFiles for q.OSes that match q.Version and q.Build using the Github API
+func (c *AppleDBClient) OsFiles(q *ADBQuery) (OsFiles, error) {
 	var osfiles OsFiles
+	client := c.httpClient(q)
 
 	for _, os := range q.OSes {
 		qurl, err := url.JoinPath("osFiles", os)
@@ -246,7 +324,7 @@ func AppleDBQuery(q *ADBQuery) ([]OsFileSource, error) {
 			return nil, err
 		}
 
-		folders, err := queryGithubAPI(qurl, q.Proxy, q.APIToken, q.Insecure)
+		folders, err := queryGithubAPI(client, qurl, q.APIToken)
 		if err != nil {
 			return nil, err
 		}
@@ -267,13 +345,13 @@ func AppleDBQuery(q *ADBQuery) ([]OsFileSource, error) {
 			if err != nil {
 				return nil, err
 			}
-			files, err := queryGithubAPI(qurl, q.Proxy, q.APIToken, q.Insecure)
+			files, err := queryGithubAPI(client, qurl, q.APIToken)
 			if err != nil {
 				return nil, err
 			}
 
 			for _, file := range files {
-				of, err := getOsFiles(file.DownloadURL, q.Proxy, q.APIToken, q.Insecure)
+				of, err := getOsFiles(client, file.DownloadURL, q.APIToken)
 				if err != nil {
 					return nil, err
 				}
@@ -282,10 +360,10 @@ func AppleDBQuery(q *ADBQuery) ([]OsFileSource, error) {
 		}
 	}
 
-	return osfiles.Query(q), nil
+	return osfiles, nil
 }
 
-func queryGithubAPI(path, proxy, api string, insecure bool) ([]GithubContentsResponse, error) {
+func queryGithubAPI(client *http.Client, path, api string) ([]GithubContentsResponse, error) {
 	var contents []GithubContentsResponse
 
 	req, err := http.NewRequest("GET", ApiContentsURL+path, nil)
@@ -299,13 +377,6 @@ func queryGithubAPI(path, proxy, api string, insecure bool) ([]GithubContentsRes
 		req.Header.Add("Authorization", "token "+api)
 	}
 
-	client := &http.Client{
-		Transport: &http.Transport{
-			Proxy:           GetProxy(proxy),
-			TLSClientConfig: &tls.Config{InsecureSkipVerify: insecure},
-		},
-	}
-
 	res, err := client.Do(req)
 	if err != nil {
 		return nil, err
@@ -329,7 +400,7 @@ func queryGithubAPI(path, proxy, api string, insecure bool) ([]GithubContentsRes
 	return contents, nil
 }
 
-func getOsFiles(path, proxy, api string, insecure bool) (*AppleDbOsFile, error) {
+func getOsFiles(client *http.Client, path, api string) (*AppleDbOsFile, error) {
 	var osfile AppleDbOsFile
 
 	req, err := http.NewRequest("GET", path, nil)
@@ -342,13 +413,6 @@ func getOsFiles(path, proxy, api string, insecure bool) (*AppleDbOsFile, error)
 		req.Header.Add("Authorization", "token "+api)
 	}
 
-	client := &http.Client{
-		Transport: &http.Transport{
-			Proxy:           GetProxy(proxy),
-			TLSClientConfig: &tls.Config{InsecureSkipVerify: insecure},
-		},
-	}
-
 	res, err := client.Do(req)
 	if err != nil {
 		return nil, err
@@ -1,11 +1,10 @@
-//go:build !ios
+//go:build !ios && !wasm
 
 package download
 
 import (
 	"archive/zip"
 	"bytes"
-	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -63,9 +62,10 @@ type AppStoreConfig struct {
 	PageSize     int
 	Verbose      bool
 	// extra config
-	StoreFront    string
-	VaultPassword string
-	ConfigDir     string
+	StoreFront     string
+	VaultPassword  string
+	KeyringBackend string
+	ConfigDir      string
 }
 
 type AppStore struct {
@@ -248,11 +248,8 @@ func NewAppStore(config *AppStoreConfig) *AppStore {
 
 	as := AppStore{
 		Client: &http.Client{
-			Jar: jar,
-			Transport: &http.Transport{
-				Proxy:           GetProxy(config.Proxy),
-				TLSClientConfig: &tls.Config{InsecureSkipVerify: config.Insecure},
-			},
+			Jar:       jar,
+			Transport: RetryTransport(NewTransport(config.Proxy, config.Insecure)),
 		},
 		config: config,
 	}
@@ -263,7 +260,7 @@ func NewAppStore(config *AppStoreConfig) *AppStore {
 // Init AppStore
 func (as *AppStore) Init() (err error) {
 	// create credential vault (if it doesn't exist)
-	as.Vault, err = keyring.Open(keyring.Config{
+	as.Vault, err = OpenVault(keyring.Config{
 		ServiceName:                    KeychainServiceName,
 		KeychainSynchronizable:         false,
 		KeychainAccessibleWhenUnlocked: true,
@@ -278,9 +275,9 @@ func (as *AppStore) Init() (err error) {
 				prompt := &survey.Password{
 					Message: msg,
 				}
-				if err := survey.AskOne(prompt, &as.config.VaultPassword); err != nil {
+				if err := utils.AskOne(prompt, &as.config.VaultPassword); err != nil {
 					if err == terminal.InterruptErr {
-						log.Warn("Exiting...")
+						getLogger().Warn("Exiting...")
 						os.Exit(0)
 					}
 					return "", err
@@ -288,7 +285,7 @@ func (as *AppStore) Init() (err error) {
 			}
 			return as.config.VaultPassword, nil
 		},
-	})
+	}, as.config.KeyringBackend, true)
 	if err != nil {
 		return fmt.Errorf("failed to open vault: %s", err)
 	}
@@ -300,15 +297,15 @@ func (as *AppStore) Login(username, password string) error {
 	if len(username) == 0 || len(password) == 0 {
 		creds, err := as.Vault.Get(VaultName)
 		if err != nil { // failed to get credentials from vault (prompt user for credentials)
-			log.Errorf("failed to get credentials from vault: %v", err)
+			getLogger().Errorf("failed to get credentials from vault: %v", err)
 			// get username
 			if len(username) == 0 {
 				prompt := &survey.Input{
 					Message: "Please type your username:",
 				}
-				if err := survey.AskOne(prompt, &username); err != nil {
+				if err := utils.AskOne(prompt, &username); err != nil {
 					if err == terminal.InterruptErr {
-						log.Warn("Exiting...")
+						getLogger().Warn("Exiting...")
 						os.Exit(0)
 					}
 					return err
@@ -319,9 +316,9 @@ func (as *AppStore) Login(username, password string) error {
 				prompt := &survey.Password{
 					Message: "Please type your password:",
 				}
-				if err := survey.AskOne(prompt, &password); err != nil {
+				if err := utils.AskOne(prompt, &password); err != nil {
 					if err == terminal.InterruptErr {
-						log.Warn("Exiting...")
+						getLogger().Warn("Exiting...")
 						os.Exit(0)
 					}
 					return err
@@ -404,7 +401,7 @@ func (as *AppStore) signIn(username, password, code string, attempt int) error {
 		return err
 	}
 
-	log.Debugf("POST Login: (%d):\n%s\n", response.StatusCode, string(body))
+	getLogger().Debugf("POST Login: (%d):\n%s\n", response.StatusCode, string(body))
 
 	// os.WriteFile("login.xml", body, 0644)
 
@@ -422,9 +419,9 @@ func (as *AppStore) signIn(username, password, code string, attempt int) error {
 			prompt := &survey.Password{
 				Message: "Please type your verification code:",
 			}
-			if err := survey.AskOne(prompt, &code); err != nil {
+			if err := utils.AskOne(prompt, &code); err != nil {
 				if err == terminal.InterruptErr {
-					log.Warn("Exiting...")
+					getLogger().Warn("Exiting...")
 					os.Exit(0)
 				}
 				return err
@@ -533,7 +530,7 @@ func (as *AppStore) Search(searchTerm string, limit int) (Apps, error) {
 		return nil, err
 	}
 
-	log.Debugf("GET appstore Search (%d):\n%s\n", response.StatusCode, string(body))
+	getLogger().Debugf("GET appstore Search (%d):\n%s\n", response.StatusCode, string(body))
 
 	if 200 > response.StatusCode || 300 <= response.StatusCode {
 		return nil, fmt.Errorf("failed to search appstore: response received %s", response.Status)
@@ -580,7 +577,7 @@ func (as *AppStore) Lookup(bundleID string) (*App, error) {
 		return nil, err
 	}
 
-	log.Debugf("GET appstore Lookup (%d):\n%s\n", response.StatusCode, string(body))
+	getLogger().Debugf("GET appstore Lookup (%d):\n%s\n", response.StatusCode, string(body))
 
 	if 200 > response.StatusCode || 300 <= response.StatusCode {
 		return nil, fmt.Errorf("failed to lookup bundleID in appstore: response received %s", response.Status)
@@ -662,7 +659,7 @@ func (as *AppStore) Purchase(bundleID string) error {
 		return err
 	}
 
-	log.Debugf("POST Purchase: (%d):\n%s\n", response.StatusCode, string(body))
+	getLogger().Debugf("POST Purchase: (%d):\n%s\n", response.StatusCode, string(body))
 
 	// os.WriteFile("purchase.xml", body, 0644)
 
@@ -747,7 +744,7 @@ func (as *AppStore) Download(bundleID, output string) error {
 		return err
 	}
 
-	log.Debugf("POST Download: (%d):\n%s\n", response.StatusCode, string(body))
+	getLogger().Debugf("POST Download: (%d):\n%s\n", response.StatusCode, string(body))
 
 	// os.WriteFile("download.xml", body, 0644)
 
@@ -779,7 +776,7 @@ func (as *AppStore) Download(bundleID, output string) error {
 		return fmt.Errorf("failed to apply app patches: %v", err)
 	}
 
-	log.Infof("Created %s", dst)
+	getLogger().Infof("Created %s", dst)
 
 	return nil
 }
@@ -804,7 +801,7 @@ func (as *AppStore) download(url string) (string, error) {
 		return "", fmt.Errorf("failed to create temp file: %v", err)
 	}
 
-	log.WithFields(log.Fields{
+	getLogger().WithFields(log.Fields{
 		"file": dest.Name(),
 	}).Info("Downloading")
 
@@ -0,0 +1,318 @@
+//go:build !wasm
+
+package download
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/blacktop/ipsw/internal/exitcode"
+	"github.com/blacktop/ipsw/internal/utils"
+	"github.com/vbauerster/mpb/v7"
+	"github.com/vbauerster/mpb/v7/decor"
+)
+
+// DefaultAria2RPC is the default JSON-RPC endpoint of aria2c (started with --enable-rpc)
+const DefaultAria2RPC = "http://localhost:6800/jsonrpc"
+
+// aria2ChecksumError is aria2's exit status of a download with an incorrect checksum
+const aria2ChecksumError = "32"
+
+// Aria2Config is the config of the aria2 download backend
+type Aria2Config struct {
+	RPC          string        // JSON-RPC endpoint (DefaultAria2RPC if empty)
+	Secret       string        // --rpc-secret of aria2c (optional)
+	PollInterval time.Duration // how often the download's status is polled (2s if 0)
+}
+
+// Aria2 hands downloads to a running aria2c through its JSON-RPC interface
+//
+// NOTE: aria2c writes the files itself so it must see the same paths (i.e. run on the same host or share the output folder)
+type Aria2 struct {
+	conf   Aria2Config
+	client *http.Client
+}
+
+// NewAria2 returns an aria2 JSON-RPC client
+func NewAria2(conf *Aria2Config) *Aria2 {
+	a := &Aria2{conf: *conf, client: &http.Client{Timeout: 30 * time.Second}}
+	if len(a.conf.RPC) == 0 {
+		a.conf.RPC = DefaultAria2RPC
+	}
+	if a.conf.PollInterval <= 0 {
+		a.conf.PollInterval = 2 * time.Second
+	}
+	return a
+}
+
+var aria2Backend struct {
+	sync.Mutex
+	a *Aria2
+}
+
+// SetAria2 makes Download.Do hand its downloads to aria2c (nil downloads in-process again)
+func SetAria2(a *Aria2) {
+	aria2Backend.Lock()
+	defer aria2Backend.Unlock()
+	aria2Backend.a = a
+}
+
+func getAria2() *Aria2 {
+	aria2Backend.Lock()
+	defer aria2Backend.Unlock()
+	return aria2Backend.a
+}
+
+type aria2Request struct {
+	JSONRPC string `json:"jsonrpc"`
+	ID      string `json:"id"`
+	Method  string `json:"method"`
+	Params  []any  `json:"params"`
+}
+
+type aria2Response struct {
+	Result json.RawMessage `json:"result"`
+	Error  *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// aria2Status is the status of a download (aria2.tellStatus)
+type aria2Status struct {
+	GID             string `json:"gid"`
+	Status          string `json:"status"` // active, waiting, paused, error, complete or removed
+	TotalLength     string `json:"totalLength"`
+	CompletedLength string `json:"completedLength"`
+	DownloadSpeed   string `json:"downloadSpeed"`
+	ErrorCode       string `json:"errorCode"`
+	ErrorMessage    string `json:"errorMessage"`
+}
+
+func (s *aria2Status) progress() Progress {
+	var p Progress
+	p.Total, _ = strconv.ParseInt(s.TotalLength, 10, 64)
+	p.Done, _ = strconv.ParseInt(s.CompletedLength, 10, 64)
+	p.Speed, _ = strconv.ParseFloat(s.DownloadSpeed, 64)
+	if p.Speed > 0 && p.Total > p.Done {
+		p.ETA = time.Duration(float64(p.Total-p.Done)/p.Speed) * time.Second
+	}
+	return p
+}
+
+// call sends a JSON-RPC request to aria2c and decodes its result into result
+func (a *Aria2) call(ctx context.Context, method string, result any, params ...any) error {
+	if len(a.conf.Secret) > 0 {
+		params = append([]any{"token:" + a.conf.Secret}, params...)
+	}
+	if params == nil {
+		params = []any{}
+	}
+	body, err := json.Marshal(aria2Request{JSONRPC: "2.0", ID: "ipsw", Method: method, Params: params})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.conf.RPC, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create aria2 request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	res, err := a.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call aria2 %s: %w", method, err)
+	}
+	defer res.Body.Close()
+
+	var resp aria2Response
+	if err := json.NewDecoder(res.Body).Decode(&resp); err != nil {
+		return fmt.Errorf("failed to decode aria2 %s response (%s): %v", method, res.Status, err)
+	}
+	if resp.Error != nil {
+		return fmt.Errorf("aria2 %s failed: %s (code %d)", method, resp.Error.Message, resp.Error.Code)
+	}
+	if result != nil {
+		return json.Unmarshal(resp.Result, result)
+	}
+	return nil
+}
+
+// Version returns the version of aria2c (i.e. to check that the RPC endpoint is reachable)
+func (a *Aria2) Version(ctx context.Context) (string, error) {
+	var v struct {
+		Version string `json:"version"`
+	}
+	if err := a.call(ctx, "aria2.getVersion", &v); err != nil {
+		return "", err
+	}
+	return v.Version, nil
+}
+
+// aria2Checksum returns the download's checksum as an aria2 checksum option (i.e. sha-1=...)
+func (d *Download) aria2Checksum() string {
+	alg, expected, _ := d.checksum()
+	switch alg {
+	case "sha1":
+		return "sha-1=" + expected
+	case "sha256":
+		return "sha-256=" + expected
+	case "md5":
+		return "md5=" + expected
+	}
+	return ""
+}
+
+// Add queues the download in aria2c and returns its GID
+func (a *Aria2) Add(ctx context.Context, d *Download) (string, error) {
+	dest, err := filepath.Abs(d.DestName)
+	if err != nil {
+		return "", fmt.Errorf("failed to get absolute path of %s: %v", d.DestName, err)
+	}
+	opts := map[string]any{
+		"dir":             filepath.Dir(dest),
+		"out":             filepath.Base(dest),
+		"continue":        "true",
+		"allow-overwrite": "false",
+	}
+	if checksum := d.aria2Checksum(); len(checksum) > 0 {
+		opts["checksum"] = checksum
+	}
+	if d.Threads > 1 {
+		opts["split"] = strconv.Itoa(d.Threads)
+		opts["max-connection-per-server"] = strconv.Itoa(min(d.Threads, 16))
+	}
+	if d.RateLimit > 0 {
+		opts["max-download-limit"] = strconv.FormatInt(d.RateLimit, 10)
+	}
+	if len(d.Headers) > 0 {
+		var headers []string
+		for k, v := range d.Headers {
+			headers = append(headers, k+": "+v)
+		}
+		opts["header"] = headers
+	}
+	var gid string
+	if err := a.call(ctx, "aria2.addUri", &gid, []string{d.URL}, opts); err != nil {
+		return "", err
+	}
+	return gid, nil
+}
+
+// Status returns the status of a download
+func (a *Aria2) Status(ctx context.Context, gid string) (*aria2Status, error) {
+	var status aria2Status
+	if err := a.call(ctx, "aria2.tellStatus", &status, gid,
+		[]string{"gid", "status", "totalLength", "completedLength", "downloadSpeed", "errorCode", "errorMessage"}); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// Remove stops a download (its partial file is kept by aria2c)
+func (a *Aria2) Remove(ctx context.Context, gid string) error {
+	return a.call(ctx, "aria2.forceRemove", nil, gid)
+}
+
+// Download queues the download in aria2c and polls it until it completes
+func (a *Aria2) Download(ctx context.Context, d *Download) error {
+	gid, err := a.Add(ctx, d)
+	if err != nil {
+		return err
+	}
+	utils.Indent(d.logger().WithField("gid", gid).Info, 2)(fmt.Sprintf("Handed %s to aria2", filepath.Base(d.DestName)))
+
+	var p *utils.Progress
+	var bar *mpb.Bar
+	if d.OnProgress == nil {
+		if utils.Plain {
+			p = utils.NewProgress(filepath.Base(d.DestName), mpb.WithOutput(nil))
+		} else {
+			p = utils.NewProgress(filepath.Base(d.DestName))
+		}
+		bar = p.Add(0,
+			mpb.NewBarFiller(mpb.BarStyle().Lbound("[").Filler("=").Tip(">").Padding("-").Rbound("|")),
+			mpb.PrependDecorators(
+				decor.CountersKibiByte("\t% .2f / % .2f"),
+			),
+			mpb.AppendDecorators(
+				decor.OnComplete(decor.AverageETA(decor.ET_STYLE_GO), "✅ "),
+				decor.Name(" ] "),
+				decor.AverageSpeed(decor.UnitKiB, "% .2f"),
+			),
+		)
+	}
+	done := func(err error) error {
+		if bar != nil {
+			if err != nil {
+				bar.Abort(false)
+			} else {
+				bar.SetTotal(-1, true)
+			}
+			p.Wait()
+		}
+		return err
+	}
+
+	ticker := time.NewTicker(a.conf.PollInterval)
+	defer ticker.Stop()
+	for {
+		if err := d.interrupted(ctx); err != nil && (errors.Is(err, ErrCanceled) || ctx.Err() != nil) {
+			// use a fresh context as ctx may be the reason for stopping
+			rctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			if rerr := a.Remove(rctx, gid); rerr != nil {
+				d.logger().WithError(rerr).Warnf("failed to remove aria2 download %s", gid)
+			}
+			cancel()
+			if ctx.Err() != nil {
+				return done(ctx.Err())
+			}
+			return done(err)
+		}
+
+		status, err := a.Status(ctx, gid)
+		if err != nil {
+			return done(err)
+		}
+		prog := status.progress()
+		d.mu.Lock()
+		d.size = prog.Total
+		d.stats.Bytes = prog.Done
+		fn := d.OnProgress
+		d.mu.Unlock()
+		if fn != nil {
+			fn(prog)
+		} else if bar != nil && prog.Total > 0 {
+			bar.SetTotal(prog.Total, false)
+			bar.SetCurrent(prog.Done)
+		}
+
+		switch status.Status {
+		case "complete":
+			if alg, expected, newHash := d.checksum(); newHash != nil {
+				d.mu.Lock()
+				d.stats.Verification = &Verification{Algorithm: alg, Expected: expected, Actual: expected, Verified: true, Attempts: 1}
+				d.mu.Unlock()
+			}
+			return done(nil)
+		case "error":
+			if status.ErrorCode == aria2ChecksumError {
+				return done(exitcode.Wrap(exitcode.Verification, fmt.Errorf("%w: %s (aria2: %s)", ErrBadChecksum, d.DestName, status.ErrorMessage)))
+			}
+			return done(fmt.Errorf("aria2 download %s failed: %s (code %s)", gid, status.ErrorMessage, status.ErrorCode))
+		case "removed":
+			return done(fmt.Errorf("aria2 download %s was removed", gid))
+		}
+
+		select {
+		case <-ctx.Done():
+		case <-ticker.C:
+		}
+	}
+}
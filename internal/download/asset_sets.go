@@ -1,7 +1,6 @@
 package download
 
 import (
-	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -38,10 +37,7 @@ func GetAssetSets(proxy string, insecure bool) (*AssetSets, error) {
 	req.Header.Add("User-Agent", utils.RandomAgent())
 
 	client := &http.Client{
-		Transport: &http.Transport{
-			Proxy:           GetProxy(proxy),
-			TLSClientConfig: &tls.Config{InsecureSkipVerify: insecure},
-		},
+		Transport: RetryTransport(NewTransport(proxy, insecure)),
 	}
 
 	res, err := client.Do(req)
@@ -127,7 +123,7 @@ func (a *AssetSets) LatestVersion(platform string) string {
 				versionsRaw = append(versionsRaw, asset.ProductVersion)
 			}
 		case "visionos":
-			if utils.StrSliceContains(asset.SupportedDevices, "xrOS") {
+			if utils.StrSliceContains(asset.SupportedDevices, "RealityDevice") {
 				versionsRaw = append(versionsRaw, asset.ProductVersion)
 			}
 		case "recovery":
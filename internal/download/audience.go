@@ -0,0 +1,137 @@
+package download
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/blacktop/go-plist"
+)
+
+// SeedProgram is a beta seed program
+type SeedProgram string
+
+const (
+	SeedDeveloper SeedProgram = "developer"
+	SeedPublic    SeedProgram = "public"
+	SeedAppleSeed SeedProgram = "appleseed"
+)
+
+// SeedPrograms are the supported beta seed programs
+var SeedPrograms = []string{string(SeedDeveloper), string(SeedPublic), string(SeedAppleSeed)}
+
+// ParseSeedProgram parses a seed program name (also accepts macOS seedutil names, i.e. DeveloperSeed)
+func ParseSeedProgram(name string) (SeedProgram, error) {
+	switch strings.ToLower(name) {
+	case "developer", "dev", "developerseed", "developer-beta":
+		return SeedDeveloper, nil
+	case "public", "publicseed", "public-beta":
+		return SeedPublic, nil
+	case "appleseed", "customer", "customerseed", "appleseed-beta":
+		return SeedAppleSeed, nil
+	}
+	return "", fmt.Errorf("invalid seed program %#v (must be one of %s)", name, strings.Join(SeedPrograms, ", "))
+}
+
+// audiencePlatform returns the audience DB key for an OTA platform
+func audiencePlatform(platform string) string {
+	switch platform {
+	case "accessory", "recovery", "macos":
+		return "macos"
+	}
+	return platform
+}
+
+// GetSeedAudience returns the asset audience of a seed program for a platform's major version (or the latest if empty)
+func (a AssetAudienceIDs) GetSeedAudience(platform, major string, seed SeedProgram) (string, error) {
+	platform = audiencePlatform(platform)
+	if _, ok := a[platform]; !ok {
+		return "", fmt.Errorf("no asset audiences for platform %s", platform)
+	}
+	if len(major) == 0 || major == "0" {
+		major = a.LatestVersion(platform)
+	}
+	v, ok := a[platform].Versions[major]
+	if !ok {
+		return "", fmt.Errorf("no %s seed audiences for %s %s.x (must be one of %s)", seed, platform, major, strings.Join(a.GetVersions(platform), ", "))
+	}
+	var audience string
+	switch seed {
+	case SeedDeveloper:
+		audience = v.DeveloperBeta
+	case SeedPublic:
+		audience = v.PublicBeta
+	case SeedAppleSeed:
+		audience = v.AppleSeedBeta
+	}
+	if len(audience) == 0 {
+		return "", fmt.Errorf("no %s seed audience for %s %s.x", seed, platform, major)
+	}
+	return audience, nil
+}
+
+var (
+	plistRE    = regexp.MustCompile(`(?s)<\?xml.*</plist>`)
+	audienceRE = regexp.MustCompile(`(?i)^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`)
+)
+
+type enrollmentProfile struct {
+	PayloadContent []struct {
+		PayloadType   string `plist:"PayloadType,omitempty"`
+		AssetAudience string `plist:"MobileAssetAssetAudience,omitempty"`
+	} `plist:"PayloadContent,omitempty"`
+}
+
+// ParseEnrollmentProfile returns the asset audience from a beta seed enrollment profile (.mobileconfig)
+func ParseEnrollmentProfile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read enrollment profile: %v", err)
+	}
+	// signed profiles wrap the plist in a CMS envelope
+	if m := plistRE.Find(data); m != nil {
+		data = m
+	}
+	var profile enrollmentProfile
+	if err := plist.NewDecoder(bytes.NewReader(data)).Decode(&profile); err != nil {
+		return "", fmt.Errorf("failed to parse enrollment profile: %v", err)
+	}
+	for _, payload := range profile.PayloadContent {
+		if len(payload.AssetAudience) > 0 {
+			if !audienceRE.MatchString(payload.AssetAudience) {
+				return "", fmt.Errorf("invalid asset audience %#v in enrollment profile", payload.AssetAudience)
+			}
+			return payload.AssetAudience, nil
+		}
+	}
+	return "", fmt.Errorf("no MobileAssetAssetAudience found in enrollment profile %s", path)
+}
+
+// ResolveAssetAudience returns the asset audience for a seed program, enrollment profile or audience UUID
+func ResolveAssetAudience(platform, version, seed string) (string, error) {
+	if audienceRE.MatchString(seed) {
+		return seed, nil
+	}
+	if strings.HasSuffix(strings.ToLower(seed), ".mobileconfig") {
+		return ParseEnrollmentProfile(seed)
+	}
+	program, err := ParseSeedProgram(seed)
+	if err != nil {
+		return "", err
+	}
+	db, err := GetAssetAudienceIDs()
+	if err != nil {
+		return "", err
+	}
+	var major string
+	if len(version) > 0 {
+		major, _, _ = strings.Cut(version, ".")
+		if _, err := strconv.Atoi(major); err != nil {
+			return "", fmt.Errorf("invalid version %s", version)
+		}
+	}
+	return db.GetSeedAudience(platform, major, program)
+}
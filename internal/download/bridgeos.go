@@ -0,0 +1,147 @@
+package download
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/blacktop/ipsw/internal/utils"
+)
+
+var bridgeModelRegex = regexp.MustCompile(`\b[A-Z]\d{3}[a-z]?AP\b`)
+
+// BridgeOSInfo is a bridgeOS firmware update (for T2 Macs) found in the macOS software update catalogs
+type BridgeOSInfo struct {
+	ProductID string
+	Version   string
+	Build     string
+	PostDate  time.Time
+	Title     string
+	Models    []string
+	Packages  []Package
+}
+
+func (i BridgeOSInfo) String() string {
+	return fmt.Sprintf("Title: %s, Version: %s, Build: %s, Models: %s, PostDate: %s",
+		i.Title,
+		i.Version,
+		i.Build,
+		strings.Join(i.Models, ","),
+		utils.FormatTime(i.PostDate))
+}
+
+// BridgeOSInfos is a list of bridgeOS firmware updates
+type BridgeOSInfos []BridgeOSInfo
+
+// FilterByModel filters out bridgeOS updates that do not support the given T2 board model (i.e. J680AP)
+func (infos BridgeOSInfos) FilterByModel(model string) BridgeOSInfos {
+	var out BridgeOSInfos
+	for _, i := range infos {
+		if len(i.Models) == 0 { // distribution did not list any models so we can't rule it out
+			out = append(out, i)
+			continue
+		}
+		for _, m := range i.Models {
+			if strings.EqualFold(m, model) {
+				out = append(out, i)
+				break
+			}
+		}
+	}
+	return out
+}
+
+// FilterByVersion filters out bridgeOS updates that do not match the given (macOS) version
+func (infos BridgeOSInfos) FilterByVersion(version string) BridgeOSInfos {
+	var out BridgeOSInfos
+	for _, i := range infos {
+		if version == i.Version {
+			out = append(out, i)
+		}
+	}
+	return out
+}
+
+// FilterByBuild filters out bridgeOS updates that do not match the given (macOS) build
+func (infos BridgeOSInfos) FilterByBuild(build string) BridgeOSInfos {
+	var out BridgeOSInfos
+	for _, i := range infos {
+		if build == i.Build {
+			out = append(out, i)
+		}
+	}
+	return out
+}
+
+// GetLatest returns the bridgeOS updates posted on the same day as the most recent one
+func (infos BridgeOSInfos) GetLatest() BridgeOSInfos {
+	var out BridgeOSInfos
+	if len(infos) == 0 {
+		return out
+	}
+	lastDate := infos[len(infos)-1].PostDate
+	for _, i := range infos {
+		if i.PostDate.YearDay() == lastDate.YearDay() && i.PostDate.Year() == lastDate.Year() {
+			out = append(out, i)
+		}
+	}
+	return out
+}
+
+func isBridgeOSPackage(pkg Package) bool {
+	for _, u := range []string{pkg.URL, pkg.MetadataURL} {
+		if strings.Contains(strings.ToLower(filepath.Base(u)), "bridgeos") {
+			return true
+		}
+	}
+	return false
+}
+
+// GetBridgeOSInfo downloads and parses the macOS software update catalogs for bridgeOS firmware updates
+func GetBridgeOSInfo() (BridgeOSInfos, error) {
+	var infos BridgeOSInfos
+
+	cat, err := getSUCatalog()
+	if err != nil {
+		return nil, err
+	}
+
+	for key, prod := range cat.Products {
+		var pkgs []Package
+		for _, pkg := range prod.Packages {
+			if isBridgeOSPackage(pkg) {
+				pkgs = append(pkgs, pkg)
+			}
+		}
+		if len(pkgs) == 0 {
+			continue
+		}
+
+		pInfo, err := getProductInfo(key, prod)
+		if err != nil {
+			return nil, err
+		}
+
+		models := utils.Unique(bridgeModelRegex.FindAllString(string(pInfo.distributionData), -1))
+		sort.Strings(models)
+
+		infos = append(infos, BridgeOSInfo{
+			ProductID: key,
+			Version:   pInfo.Version,
+			Build:     pInfo.Build,
+			PostDate:  pInfo.PostDate,
+			Title:     pInfo.Title,
+			Models:    models,
+			Packages:  pkgs,
+		})
+	}
+
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].PostDate.Before(infos[j].PostDate)
+	})
+
+	return infos, nil
+}
@@ -0,0 +1,48 @@
+//go:build !wasm
+
+package download
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/apex/log"
+	"github.com/blacktop/ipsw/internal/utils"
+	"github.com/pkg/errors"
+)
+
+// Download downloads the bridgeOS update packages to the given folder
+func (i *BridgeOSInfo) Download(folder, proxy string, insecure, skipAll, resumeAll, restartAll, ignoreSha1 bool) error {
+
+	downloader := NewDownload(proxy, insecure, skipAll, resumeAll, restartAll, ignoreSha1, true)
+
+	folder = filepath.Join(folder, fmt.Sprintf("bridgeOS_%s_%s_%s", i.Version, i.Build, i.ProductID))
+	if err := os.MkdirAll(folder, 0750); err != nil {
+		return fmt.Errorf("failed to create folder %s: %v", folder, err)
+	}
+
+	for _, pkg := range i.Packages {
+		pkgURL := pkg.URL
+		if len(pkgURL) == 0 {
+			pkgURL = pkg.MetadataURL
+		}
+		destName := filepath.Join(folder, getDestName(pkgURL, false))
+		if _, err := os.Stat(destName); os.IsNotExist(err) {
+			getLogger().WithFields(log.Fields{
+				"size":     utils.FormatBytes(uint64(pkg.Size)),
+				"destName": destName,
+			}).Info("Getting Package")
+			downloader.URL = pkgURL
+			downloader.Sha1 = pkg.Digest
+			downloader.DestName = destName
+			if err := downloader.Do(); err != nil {
+				return errors.Wrap(err, "failed to download file")
+			}
+		} else {
+			getLogger().Warnf("pkg already exists: %s", destName)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,236 @@
+package download
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	budgetWindow = time.Hour
+	// budgetSlowDown is the fraction of a provider's budget after which requests are spaced out
+	budgetSlowDown = 0.8
+	// budgetSaveInterval is how often the request history is persisted while requests are made (see SaveBudgets)
+	budgetSaveInterval = 30 * time.Second
+)
+
+// DefaultBudgets are the requests per hour allowed for the community APIs
+var DefaultBudgets = map[string]int{
+	"api.ipsw.me":      1000,
+	"theapplewiki.com": 500,
+}
+
+// Budget is a provider's request history for the current window
+type Budget struct {
+	Limit    int         `json:"limit"`
+	Requests []time.Time `json:"requests,omitempty"`
+}
+
+func (b *Budget) prune(now time.Time) {
+	var reqs []time.Time
+	for _, t := range b.Requests {
+		if now.Sub(t) < budgetWindow {
+			reqs = append(reqs, t)
+		}
+	}
+	b.Requests = reqs
+}
+
+// BudgetTracker tracks per-provider request budgets (requests per hour) across runs
+type BudgetTracker struct {
+	mu      sync.Mutex
+	path    string
+	loaded  bool
+	dirty   bool      // requests were recorded since the last save
+	saved   time.Time // time of the last save
+	warned  map[string]bool
+	Budgets map[string]*Budget `json:"budgets"`
+}
+
+var budgets = &BudgetTracker{
+	Budgets: make(map[string]*Budget),
+	warned:  make(map[string]bool),
+}
+
+func init() {
+	for provider, limit := range DefaultBudgets {
+		budgets.Budgets[provider] = &Budget{Limit: limit}
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		budgets.path = filepath.Join(home, ".ipsw", "budgets.json")
+	}
+}
+
+// SetBudget sets the requests per hour for a provider (host); a limit <= 0 disables tracking
+func SetBudget(provider string, perHour int) {
+	budgets.mu.Lock()
+	defer budgets.mu.Unlock()
+	if perHour <= 0 {
+		delete(budgets.Budgets, provider)
+		return
+	}
+	if b, ok := budgets.Budgets[provider]; ok {
+		b.Limit = perHour
+	} else {
+		budgets.Budgets[provider] = &Budget{Limit: perHour}
+	}
+}
+
+// SetBudgetFile sets the path the request budgets are persisted to
+func SetBudgetFile(path string) {
+	budgets.mu.Lock()
+	defer budgets.mu.Unlock()
+	budgets.path = path
+	budgets.loaded = false
+}
+
+// load merges the persisted request history into the tracker (must hold the lock)
+func (t *BudgetTracker) load() {
+	if t.loaded || len(t.path) == 0 {
+		return
+	}
+	t.loaded = true
+	data, err := os.ReadFile(t.path)
+	if err != nil {
+		return
+	}
+	var saved BudgetTracker
+	if err := json.Unmarshal(data, &saved); err != nil {
+		getLogger().Debugf("failed to parse request budgets %s: %v", t.path, err)
+		return
+	}
+	for provider, sb := range saved.Budgets {
+		if b, ok := t.Budgets[provider]; ok {
+			b.Requests = append(sb.Requests, b.Requests...)
+		}
+	}
+}
+
+// save persists the request history (must hold the lock)
+func (t *BudgetTracker) save() {
+	if len(t.path) == 0 {
+		return
+	}
+	t.dirty = false
+	t.saved = time.Now()
+	data, err := json.Marshal(t)
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(t.path), 0750); err != nil {
+		getLogger().Debugf("failed to create directory %s: %v", filepath.Dir(t.path), err)
+		return
+	}
+	if err := os.WriteFile(t.path, data, 0644); err != nil {
+		getLogger().Debugf("failed to save request budgets %s: %v", t.path, err)
+	}
+}
+
+// Wait records a request to the provider, first blocking if its budget is exhausted
+// and spacing out requests once it is nearly spent (it returns ctx.Err() if ctx is done first)
+func (t *BudgetTracker) Wait(ctx context.Context, provider string) error {
+	if delay := t.reserve(provider); delay > 0 {
+		getLogger().Debugf("Waiting %s before next %s request", delay.Round(time.Millisecond), provider)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+	return nil
+}
+
+// reserve records the time the next request to the provider may be sent and returns how long to wait for it
+func (t *BudgetTracker) reserve(provider string) time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	b, ok := t.Budgets[provider]
+	if !ok {
+		return 0
+	}
+	t.load()
+
+	now := time.Now()
+	b.prune(now)
+
+	var delay time.Duration
+	used := len(b.Requests)
+	switch {
+	case used >= b.Limit:
+		delay = b.Requests[used-b.Limit].Add(budgetWindow).Sub(now)
+		getLogger().Warnf("%s request budget exhausted (%d/%d per hour): waiting %s", provider, used, b.Limit, delay.Round(time.Second))
+	case float64(used) >= float64(b.Limit)*budgetSlowDown:
+		// spread what is left of the budget over the rest of the window
+		delay = b.Requests[0].Add(budgetWindow).Sub(now) / time.Duration(b.Limit-used+1)
+		if !t.warned[provider] {
+			getLogger().Warnf("%s request budget nearly spent (%d/%d per hour): slowing down requests", provider, used, b.Limit)
+			t.warned[provider] = true
+		}
+	}
+	if delay < 0 {
+		delay = 0
+	}
+
+	b.Requests = append(b.Requests, now.Add(delay))
+	t.dirty = true
+	if now.Sub(t.saved) >= budgetSaveInterval {
+		t.save()
+	}
+
+	return delay
+}
+
+// Status returns the requests used and the limit for a provider in the current window
+func (t *BudgetTracker) Status(provider string) (int, int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	b, ok := t.Budgets[provider]
+	if !ok {
+		return 0, 0, fmt.Errorf("no request budget for %s", provider)
+	}
+	t.load()
+	b.prune(time.Now())
+	return len(b.Requests), b.Limit, nil
+}
+
+// SaveBudgets persists the request history recorded since it was last saved (call before exiting)
+func SaveBudgets() {
+	budgets.mu.Lock()
+	defer budgets.mu.Unlock()
+	if budgets.dirty {
+		budgets.save()
+	}
+}
+
+// GetBudgetStatus returns the requests used and the limit for a provider in the current window
+func GetBudgetStatus(provider string) (int, int, error) {
+	return budgets.Status(provider)
+}
+
+type budgetTransport struct {
+	base http.RoundTripper
+}
+
+// RoundTrip waits on the request budget of the request's host before sending it
+func (bt *budgetTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := budgets.Wait(req.Context(), strings.TrimPrefix(req.URL.Hostname(), "www.")); err != nil {
+		return nil, err
+	}
+	return bt.base.RoundTrip(req)
+}
+
+// BudgetTransport wraps a transport so that requests to tracked providers count against their budgets
+// (every retry included) and are retried according to the retry policy (see SetRetryPolicy)
+func BudgetTransport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = sharedTransport
+	}
+	return RetryTransport(&budgetTransport{base: base})
+}
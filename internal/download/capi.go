@@ -0,0 +1,44 @@
+//go:build !wasm
+
+package download
+
+// The C API is safe to call from multiple threads at once: the handle, result, download and dev portal
+// session tables are guarded by mutexes, every handle gets its own HTTP client and the process-wide
+// settings (log level, progress bar rendering) are only set once by libipsw_init. The calls on one
+// dev portal session are serialized (a session is a single login), calls on different sessions are not.
+
+// Shutdown cancels the operations started through the C API (handle operations, download jobs and the
+// default Manager's downloads), releases their handles, jobs, results and dev portal sessions and
+// forgets the log callback
+func Shutdown() {
+	handles.Lock()
+	for h, op := range handles.m {
+		op.close()
+		delete(handles.m, h)
+	}
+	handles.Unlock()
+
+	DefaultManager().CancelAll()
+
+	jobs.Lock()
+	js := jobs.m
+	jobs.m = make(map[string]*Download)
+	jobs.Unlock()
+	for _, d := range js {
+		d.Cancel() // finished downloads can't be canceled
+	}
+
+	results.Lock()
+	rs := results.m
+	results.m = make(map[string]*Result)
+	results.Unlock()
+	for _, r := range rs {
+		r.Close()
+	}
+
+	devSessions.Lock()
+	devSessions.m = make(map[string]*devSession)
+	devSessions.Unlock()
+
+	SetLogger(nil)
+}
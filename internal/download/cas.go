@@ -0,0 +1,203 @@
+package download
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/blacktop/ipsw/internal/utils"
+)
+
+// CASIndexName is the name of the index file in the root of a CAS
+const CASIndexName = "index.json"
+
+// CAS is a content-addressable store of downloaded files
+//
+// Files are stored once under blobs/sha256/<xx>/<digest> and the download's output path is replaced
+// with a symlink to its blob, so firmware that is identical across devices (shared OTAs, KDKs, etc.)
+// only takes up space once. The index file maps every device+build+name to its blob.
+type CAS struct {
+	Root string
+
+	mu sync.Mutex
+}
+
+// CASEntry is a file in the CAS index
+type CASEntry struct {
+	Name   string    `json:"name"`
+	Device string    `json:"device,omitempty"`
+	Build  string    `json:"build,omitempty"`
+	Digest string    `json:"sha256"`
+	Size   int64     `json:"size"`
+	Blob   string    `json:"blob"` // path of the blob relative to the CAS root
+	Added  time.Time `json:"added"`
+}
+
+// Key returns the index key of the entry (device/build/name)
+func (e *CASEntry) Key() string {
+	return filepath.ToSlash(filepath.Join(e.Device, e.Build, e.Name))
+}
+
+// NewCAS creates (if needed) and returns the CAS in root
+func NewCAS(root string) (*CAS, error) {
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get absolute path of %s: %v", root, err)
+	}
+	if err := os.MkdirAll(filepath.Join(root, "blobs", "sha256"), 0750); err != nil {
+		return nil, fmt.Errorf("failed to create CAS %s: %v", root, err)
+	}
+	return &CAS{Root: root}, nil
+}
+
+var casStore struct {
+	sync.Mutex
+	c *CAS
+}
+
+// SetCAS makes StoreCAS move the downloaded files into c (nil disables it)
+func SetCAS(c *CAS) {
+	casStore.Lock()
+	defer casStore.Unlock()
+	casStore.c = c
+}
+
+func getCAS() *CAS {
+	casStore.Lock()
+	defer casStore.Unlock()
+	return casStore.c
+}
+
+// StoreCAS moves a downloaded file into the CAS set with SetCAS (if any)
+func StoreCAS(path, device, build string) error {
+	c := getCAS()
+	if c == nil {
+		return nil
+	}
+	e, err := c.Put(path, device, build)
+	if err != nil {
+		return err
+	}
+	utils.Indent(getLogger().WithField("sha256", e.Digest).Info, 2)("Stored in CAS: " + e.Blob)
+	return nil
+}
+
+// Index returns the entries of the CAS index
+func (c *CAS) Index() (map[string]*CASEntry, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.readIndex()
+}
+
+func (c *CAS) readIndex() (map[string]*CASEntry, error) {
+	index := make(map[string]*CASEntry)
+	dat, err := os.ReadFile(filepath.Join(c.Root, CASIndexName))
+	if os.IsNotExist(err) {
+		return index, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read CAS index: %v", err)
+	}
+	if err := json.Unmarshal(dat, &index); err != nil {
+		return nil, fmt.Errorf("failed to parse CAS index: %v", err)
+	}
+	return index, nil
+}
+
+func (c *CAS) writeIndex(index map[string]*CASEntry) error {
+	dat, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal CAS index: %v", err)
+	}
+	// write then rename so concurrent invocations never read a partial index
+	path := filepath.Join(c.Root, CASIndexName)
+	tmp := path + ".tmp" + strconv.Itoa(os.Getpid())
+	if err := os.WriteFile(tmp, dat, 0644); err != nil {
+		return fmt.Errorf("failed to write CAS index: %v", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write CAS index: %v", err)
+	}
+	return nil
+}
+
+func (c *CAS) blobPath(digest string) string {
+	return filepath.Join(c.Root, "blobs", "sha256", digest[:2], digest)
+}
+
+// Put moves the file at path into the CAS (or drops it if an identical blob is already stored),
+// replaces it with a link to its blob and adds it to the index
+func (c *CAS) Put(path, device, build string) (*CASEntry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %v", path, err)
+	}
+	h := sha256.New()
+	size, err := io.Copy(h, f)
+	f.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash %s: %v", path, err)
+	}
+	digest := hex.EncodeToString(h.Sum(nil))
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	blob := c.blobPath(digest)
+	if _, err := os.Stat(blob); err == nil {
+		utils.Indent(getLogger().Debug, 2)(fmt.Sprintf("%s is already stored as %s", path, digest))
+		if err := os.Remove(path); err != nil {
+			return nil, fmt.Errorf("failed to remove duplicate %s: %v", path, err)
+		}
+	} else {
+		if err := os.MkdirAll(filepath.Dir(blob), 0750); err != nil {
+			return nil, fmt.Errorf("failed to create directory %s: %v", filepath.Dir(blob), err)
+		}
+		if err := os.Rename(path, blob); err != nil { // different volumes
+			if err := utils.Copy(path, blob); err != nil {
+				return nil, fmt.Errorf("failed to copy %s to CAS: %v", path, err)
+			}
+			if err := os.Remove(path); err != nil {
+				return nil, fmt.Errorf("failed to remove %s: %v", path, err)
+			}
+		}
+		os.Chmod(blob, 0444) // blobs are shared so they must not be modified in place
+	}
+
+	// symlinks need special privileges on Windows, so fall back to a hard link
+	if err := os.Symlink(blob, path); err != nil {
+		if err := os.Link(blob, path); err != nil {
+			return nil, fmt.Errorf("failed to link %s to %s: %v", path, blob, err)
+		}
+	}
+
+	e := &CASEntry{
+		Name:   filepath.Base(path),
+		Device: device,
+		Build:  build,
+		Digest: digest,
+		Size:   size,
+		Added:  time.Now(),
+	}
+	if e.Blob, err = filepath.Rel(c.Root, blob); err != nil {
+		e.Blob = blob
+	}
+	e.Blob = filepath.ToSlash(e.Blob)
+
+	index, err := c.readIndex()
+	if err != nil {
+		return nil, err
+	}
+	index[e.Key()] = e
+	if err := c.writeIndex(index); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
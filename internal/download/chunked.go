@@ -0,0 +1,287 @@
+//go:build !wasm
+
+package download
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/blacktop/ipsw/internal/utils"
+	"github.com/vbauerster/mpb/v7"
+	"github.com/vbauerster/mpb/v7/decor"
+)
+
+const (
+	// minChunkSize is the smallest range fetched by a single connection in chunked mode
+	minChunkSize = 16 * 1024 * 1024
+	// maxChunkRetries is the number of times a single chunk is retried before giving up
+	maxChunkRetries = 3
+)
+
+// chunk is a byte range [Start, End] of a chunked download (Done is the number of bytes written)
+type chunk struct {
+	Start int64 `json:"start"`
+	End   int64 `json:"end"`
+	Done  int64 `json:"done"`
+}
+
+func (c *chunk) complete() bool {
+	return c.Start+c.Done > c.End
+}
+
+// useChunks returns true if the download should be fetched with multiple ranged connections
+func (d *Download) useChunks() bool {
+	if !d.canResume || d.size < 2*minChunkSize {
+		return false
+	}
+	if d.Threads > 1 {
+		return true
+	}
+	// a previous chunked download must be finished in chunked mode (the partial file has holes)
+	state := d.loadState()
+	return state != nil && len(state.Chunks) > 0
+}
+
+// loadChunks returns the chunked download's sidecar state (nil if missing, the remote file changed
+// or the partial download it describes is gone)
+func (d *Download) loadChunks() *resumeState {
+	state := d.loadState()
+	if state == nil || len(state.Chunks) == 0 {
+		return nil
+	}
+	if !state.matches(d) {
+		utils.Indent(d.logger().WithField("file", d.DestName).Warn, 2)("Remote file changed since the partial download was started, restarting")
+		return nil
+	}
+	// the chunks written so far are only in the partial download (which is preallocated to the full size)
+	if f, err := os.Stat(d.DestName + ".download"); err != nil || f.Size() != d.size {
+		utils.Indent(d.logger().WithField("file", d.DestName).Warn, 2)("Partial download is missing or truncated, restarting")
+		return nil
+	}
+	return state
+}
+
+func (d *Download) newChunkState(threads int) *resumeState {
+	chunkSize := d.size / int64(threads)
+	if chunkSize < minChunkSize {
+		chunkSize = minChunkSize
+	}
+	state := d.newState()
+	for start := int64(0); start < d.size; start += chunkSize {
+		end := start + chunkSize - 1
+		if end >= d.size-chunkSize/2 { // fold a small tail into the last chunk
+			end = d.size - 1
+		}
+		state.Chunks = append(state.Chunks, &chunk{Start: start, End: end})
+		if end == d.size-1 {
+			break
+		}
+	}
+	return state
+}
+
+// doChunked downloads the file as concurrent ranged chunks written in place to the partial download
+func (d *Download) doChunked(ctx context.Context) error {
+	threads := d.Threads
+	if threads < 1 {
+		threads = 1
+	}
+
+	state := d.loadChunks()
+	if state != nil {
+		if d.skipAll {
+			return nil
+		} else if d.restartAll {
+			d.logger().Infof("Downloading %s - RESTARTED", d.DestName+".download")
+			state = nil
+		} else {
+			utils.Indent(d.logger().WithField("file", d.DestName).Warn, 2)("Resuming a previous chunked download")
+			state.restoreChecksums(d)
+		}
+	}
+	if state == nil {
+		state = d.newChunkState(threads)
+		os.Remove(d.DestName + ".download")
+		if err := d.saveState(state); err != nil {
+			return fmt.Errorf("failed to save download state: %v", err)
+		}
+	}
+
+	dest, err := os.OpenFile(d.DestName+".download", os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("cannot open %s: %v", d.DestName+".download", err)
+	}
+	if err := dest.Truncate(d.size); err != nil {
+		dest.Close()
+		return fmt.Errorf("failed to allocate %s: %v", d.DestName+".download", err)
+	}
+
+	mu := &state.mu // guards chunk progress
+	var resumed int64
+	for _, c := range state.Chunks {
+		resumed += c.Done
+	}
+	d.mu.Lock()
+	if d.stats.Bytes == 0 {
+		d.stats.ResumedBytes = resumed
+	}
+	d.mu.Unlock()
+
+	p := d.newProgress()
+	bar := p.Add(d.size,
+		mpb.NewBarFiller(mpb.BarStyle().Lbound("[").Filler("=").Tip(">").Padding("-").Rbound("|")),
+		d.barPriority(),
+		mpb.PrependDecorators(
+			decor.Name(d.barName()),
+			decor.CountersKibiByte("\t% .2f / % .2f"),
+		),
+		mpb.AppendDecorators(
+			decor.OnComplete(decor.AverageETA(decor.ET_STYLE_GO), "✅ "),
+			decor.Name(" ] "),
+			decor.AverageSpeed(decor.UnitKiB, "% .2f"),
+		),
+	)
+	if resumed > 0 {
+		bar.SetRefill(resumed)
+		bar.IncrInt64(resumed)
+	}
+
+	cctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	errs := make(chan error, len(state.Chunks))
+	sem := make(chan struct{}, threads)
+	for _, c := range state.Chunks {
+		if c.complete() {
+			continue
+		}
+		wg.Add(1)
+		go func(c *chunk) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			var err error
+			for attempt := 0; attempt <= maxChunkRetries; attempt++ {
+				if cctx.Err() != nil {
+					return
+				}
+				if attempt > 0 {
+					d.mu.Lock()
+					d.stats.Retries++
+					d.mu.Unlock()
+					utils.Indent(d.logger().WithError(err).Debug, 3)(fmt.Sprintf("retrying chunk %d-%d", c.Start, c.End))
+					select {
+					case <-cctx.Done():
+						return
+					case <-time.After(time.Duration(attempt) * time.Second):
+					}
+				}
+				if err = d.fetchChunk(cctx, dest, c, mu, bar); err == nil {
+					// flush the chunk to disk before the state file records it as done
+					if err = dest.Sync(); err != nil {
+						break
+					}
+					d.saveState(state)
+					return
+				}
+			}
+			errs <- err
+			cancel() // stop the other connections
+		}(c)
+	}
+	wg.Wait()
+	close(errs)
+
+	if bar.Completed() {
+		d.waitProgress(p)
+	} else {
+		bar.Abort(false)
+		d.waitProgress(p)
+	}
+	dest.Sync()
+	dest.Close()
+
+	if ierr := d.interrupted(ctx); ierr != nil { // paused or canceled
+		d.saveState(state)
+		return ierr
+	}
+	if err := <-errs; err != nil {
+		d.saveState(state)
+		return fmt.Errorf("failed to download chunk: %w", err)
+	}
+
+	if err := d.verify(nil); err != nil {
+		return err
+	}
+
+	d.removeState()
+	if err := os.Rename(d.DestName+".download", d.DestName); err != nil {
+		return fmt.Errorf("failed to rename %s to %s: %v", d.DestName+".download", d.DestName, err)
+	}
+
+	return nil
+}
+
+// fetchChunk downloads the rest of chunk c and writes it in place
+func (d *Download) fetchChunk(ctx context.Context, dest *os.File, c *chunk, mu *sync.Mutex, bar *mpb.Bar) error {
+	mu.Lock()
+	offset := c.Start + c.Done
+	mu.Unlock()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", d.URL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create http GET request: %v", err)
+	}
+	req.Header.Add("User-Agent", utils.RandomAgent())
+	for k, v := range d.Headers {
+		req.Header.Add(k, v)
+	}
+	req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", offset, c.End))
+	if v := d.ifRange(); v != "" {
+		req.Header.Set("If-Range", v)
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusPartialContent {
+		return fmt.Errorf("server return status: %s (expected 206 Partial Content)", resp.Status)
+	}
+
+	body := d.statsReader(d.throttle(ctx, resp.Body))
+	buf := make([]byte, 256*1024)
+	for offset <= c.End {
+		n, rerr := body.Read(buf)
+		if n > 0 {
+			if int64(n) > c.End-offset+1 {
+				n = int(c.End - offset + 1)
+			}
+			if _, err := dest.WriteAt(buf[:n], offset); err != nil {
+				return fmt.Errorf("failed to write chunk: %v", err)
+			}
+			offset += int64(n)
+			mu.Lock()
+			c.Done += int64(n)
+			mu.Unlock()
+			bar.IncrBy(n)
+		}
+		if rerr == io.EOF {
+			break
+		} else if rerr != nil {
+			return rerr
+		}
+	}
+	if offset <= c.End {
+		return fmt.Errorf("chunk %d-%d ended early at %d: %w", c.Start, c.End, offset, io.ErrUnexpectedEOF)
+	}
+	return nil
+}
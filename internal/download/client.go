@@ -0,0 +1,114 @@
+package download
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ClientConfig is the connection config of the HTTP transports shared by all download sources
+//
+// NOTE: there is no overall request timeout as firmware downloads can take hours
+// (use the *WithContext functions to set deadlines)
+type ClientConfig struct {
+	DialTimeout           time.Duration
+	KeepAlive             time.Duration
+	TLSHandshakeTimeout   time.Duration
+	ResponseHeaderTimeout time.Duration // time to wait for the response headers once the request is sent
+	IdleConnTimeout       time.Duration
+	MaxIdleConns          int
+	MaxIdleConnsPerHost   int
+	DisableHTTP2          bool
+	RootCAs               *x509.CertPool // (optional) CAs to trust instead of the system's (i.e. behind a TLS inspecting proxy)
+	MinTLSVersion         uint16
+}
+
+// DefaultClientConfig is the default ClientConfig
+var DefaultClientConfig = ClientConfig{
+	DialTimeout:           30 * time.Second,
+	KeepAlive:             30 * time.Second,
+	TLSHandshakeTimeout:   15 * time.Second,
+	ResponseHeaderTimeout: 60 * time.Second,
+	IdleConnTimeout:       90 * time.Second,
+	MaxIdleConns:          100,
+	MaxIdleConnsPerHost:   16,
+	MinTLSVersion:         tls.VersionTLS12,
+}
+
+type transportKey struct {
+	proxy    string
+	insecure bool
+}
+
+var transports = struct {
+	sync.Mutex
+	conf ClientConfig
+	base http.RoundTripper // injected with SetTransport
+	m    map[transportKey]*http.Transport
+}{conf: DefaultClientConfig, m: make(map[transportKey]*http.Transport)}
+
+// SetClientConfig sets the connection config of the transports returned by NewTransport
+// (the idle connections of the previous transports are closed)
+func SetClientConfig(conf ClientConfig) {
+	transports.Lock()
+	defer transports.Unlock()
+	for _, t := range transports.m {
+		t.CloseIdleConnections()
+	}
+	transports.conf = conf
+	transports.m = make(map[transportKey]*http.Transport)
+}
+
+// SetTransport makes every download source send its requests through rt (i.e. a library user's own
+// instrumented or pre-configured transport); the proxy and insecure settings are then up to rt (nil resets it)
+func SetTransport(rt http.RoundTripper) {
+	transports.Lock()
+	defer transports.Unlock()
+	transports.base = rt
+}
+
+// NewTransport returns the shared transport of a proxy and TLS verification setting
+//
+// Transports are pooled so that the sources reuse their connections (keep-alive and HTTP/2)
+// instead of opening new ones for every request.
+func NewTransport(proxy string, insecure bool) http.RoundTripper {
+	transports.Lock()
+	defer transports.Unlock()
+	if transports.base != nil {
+		return transports.base
+	}
+	key := transportKey{proxy: proxy, insecure: insecure}
+	if t, ok := transports.m[key]; ok {
+		return t
+	}
+	conf := transports.conf
+	dialer := &net.Dialer{
+		Timeout:   conf.DialTimeout,
+		KeepAlive: conf.KeepAlive,
+	}
+	t := &http.Transport{
+		Proxy:       GetProxy(proxy),
+		DialContext: dialer.DialContext,
+		TLSClientConfig: &tls.Config{
+			InsecureSkipVerify: insecure,
+			RootCAs:            conf.RootCAs,
+			MinVersion:         conf.MinTLSVersion,
+		},
+		TLSHandshakeTimeout:   conf.TLSHandshakeTimeout,
+		ResponseHeaderTimeout: conf.ResponseHeaderTimeout,
+		IdleConnTimeout:       conf.IdleConnTimeout,
+		MaxIdleConns:          conf.MaxIdleConns,
+		MaxIdleConnsPerHost:   conf.MaxIdleConnsPerHost,
+		ForceAttemptHTTP2:     !conf.DisableHTTP2,
+		ExpectContinueTimeout: 1 * time.Second,
+	}
+	if conf.DisableHTTP2 {
+		// a non-nil empty map disables HTTP/2
+		t.TLSNextProto = make(map[string]func(string, *tls.Conn) http.RoundTripper)
+	}
+	transports.m[key] = t
+	return t
+}
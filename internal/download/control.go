@@ -0,0 +1,203 @@
+//go:build !wasm
+
+package download
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// DownloadState is the state of a download
+type DownloadState string
+
+const (
+	StateQueued   DownloadState = "queued"
+	StateRunning  DownloadState = "running"
+	StatePaused   DownloadState = "paused"
+	StateCanceled DownloadState = "canceled"
+	StateDone     DownloadState = "done"
+	StateFailed   DownloadState = "failed"
+)
+
+// ErrPaused is returned internally when a running download is paused
+var ErrPaused = errors.New("download paused")
+
+// DownloadStatus is a snapshot of a download's progress
+type DownloadStatus struct {
+	ID         string        `json:"id,omitempty"`
+	URL        string        `json:"url,omitempty"`
+	DestName   string        `json:"dest,omitempty"`
+	State      DownloadState `json:"state,omitempty"`
+	Size       int64         `json:"size,omitempty"`
+	Downloaded int64         `json:"downloaded,omitempty"`
+	Error      string        `json:"error,omitempty"`
+	Stats      *Stats        `json:"stats,omitempty"`
+}
+
+// State returns the current state of the download
+func (d *Download) State() DownloadState {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if len(d.state) == 0 {
+		return StateQueued
+	}
+	return d.state
+}
+
+// Status returns a snapshot of the download's progress
+func (d *Download) Status() DownloadStatus {
+	status := DownloadStatus{
+		URL:      d.URL,
+		DestName: d.DestName,
+		State:    d.State(),
+	}
+	d.mu.Lock()
+	status.Size = d.size
+	if d.err != nil {
+		status.Error = d.err.Error()
+	}
+	d.mu.Unlock()
+	if stats := d.Stats(); !stats.Started.IsZero() {
+		status.Stats = &stats
+	}
+	switch status.State {
+	case StateDone:
+		if fi, err := os.Stat(d.DestName); err == nil {
+			status.Downloaded = fi.Size()
+		}
+	default:
+		if fi, err := os.Stat(d.DestName + ".download"); err == nil {
+			status.Downloaded = fi.Size()
+		}
+	}
+	return status
+}
+
+// Pause pauses a queued or running download (the partial download is kept)
+func (d *Download) Pause() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	switch d.state {
+	case "", StateQueued, StateRunning:
+	case StatePaused:
+		return nil
+	default:
+		return fmt.Errorf("cannot pause %s download", d.state)
+	}
+	d.paused = true
+	d.pauses++
+	d.resumeCh = make(chan struct{})
+	d.state = StatePaused
+	if d.cancel != nil {
+		d.cancel()
+	}
+	return nil
+}
+
+// Resume resumes a paused download (a download paused before it started is queued again)
+func (d *Download) Resume() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if !d.paused {
+		return fmt.Errorf("cannot resume %s download", d.state)
+	}
+	d.paused = false
+	if d.stats.Started.IsZero() {
+		d.state = StateQueued
+	} else {
+		d.state = StateRunning
+	}
+	close(d.resumeCh)
+	return nil
+}
+
+// Cancel cancels the download and removes the partial download
+func (d *Download) Cancel() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	switch d.state {
+	case StateDone, StateFailed, StateCanceled:
+		return fmt.Errorf("cannot cancel %s download", d.state)
+	}
+	d.canceled = true
+	if d.paused {
+		d.paused = false
+		close(d.resumeCh)
+	}
+	if d.cancel != nil {
+		d.cancel()
+	}
+	if len(d.state) == 0 || d.state == StateQueued || d.stats.Started.IsZero() {
+		d.state = StateCanceled // it never started so nothing else finishes it
+	}
+	return nil
+}
+
+// TogglePause pauses a running download or resumes a paused one
+func (d *Download) TogglePause() error {
+	if d.State() == StatePaused {
+		return d.Resume()
+	}
+	return d.Pause()
+}
+
+// pausesKey is the context key of the download's Pause count when the request was started
+type pausesKey struct{}
+
+// interrupted returns why an in-flight request was aborted (if it was)
+//
+// A request started before a Pause was aborted by it even if the download was resumed since.
+func (d *Download) interrupted(ctx context.Context) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	pauses, ok := ctx.Value(pausesKey{}).(uint64)
+	switch {
+	case d.canceled:
+		return ErrCanceled
+	case d.paused || (ok && pauses != d.pauses) || ctx.Err() != nil:
+		return ErrPaused
+	}
+	return nil
+}
+
+// waitResume blocks while the download is paused and returns false if it was canceled
+func (d *Download) waitResume() bool {
+	d.mu.Lock()
+	ch := d.resumeCh
+	paused := d.paused
+	d.mu.Unlock()
+	if paused {
+		<-ch
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return !d.canceled
+}
+
+func (d *Download) setState(state DownloadState) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.state = state
+}
+
+func (d *Download) finish(err error) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.cancel = nil
+	d.err = err
+	switch {
+	case errors.Is(err, ErrCanceled):
+		d.state = StateCanceled
+		if len(d.DestName) > 0 { // streamed downloads have no partial download
+			os.Remove(d.DestName + ".download")
+			d.removeState()
+		}
+	case err != nil:
+		d.state = StateFailed
+	default:
+		d.state = StateDone
+	}
+	return err
+}
@@ -0,0 +1,114 @@
+//go:build !wasm
+
+package download
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/99designs/keyring"
+)
+
+// DefaultAccount is the name of the vault credential profile used when no account is given
+const DefaultAccount = "default"
+
+var accountNameRE = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+// DevAccount is a developer account credential profile stored in the vault
+type DevAccount struct {
+	Name     string `json:"name"`
+	Username string `json:"username"`
+	Session  bool   `json:"session"` // has a stored dev portal session
+}
+
+// VaultKey returns the vault key of an account's credentials
+func VaultKey(account string) string {
+	if len(account) == 0 || account == DefaultAccount {
+		return VaultName
+	}
+	return VaultName + "." + account
+}
+
+func (dp *DevPortal) vaultKey() string {
+	return VaultKey(dp.config.Account)
+}
+
+// ListAccounts returns the credential profiles stored in the vault
+func (dp *DevPortal) ListAccounts() ([]DevAccount, error) {
+	keys, err := dp.Vault.Keys()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list vault keys: %v", err)
+	}
+	var accounts []DevAccount
+	for _, key := range keys {
+		var name string
+		if key == VaultName {
+			name = DefaultAccount
+		} else if strings.HasPrefix(key, VaultName+".") {
+			name = strings.TrimPrefix(key, VaultName+".")
+		} else {
+			continue
+		}
+		item, err := dp.Vault.Get(key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get %s credentials from vault: %v", name, err)
+		}
+		var auth AppleAccountAuth
+		if err := json.Unmarshal(item.Data, &auth); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal %s credentials: %v", name, err)
+		}
+		accounts = append(accounts, DevAccount{
+			Name:     name,
+			Username: auth.Credentials.Username,
+			Session:  len(auth.DevPortalSession.SessionID) > 0,
+		})
+		auth = AppleAccountAuth{}
+	}
+	sort.Slice(accounts, func(i, j int) bool {
+		return accounts[i].Name < accounts[j].Name
+	})
+	return accounts, nil
+}
+
+// AddAccount stores (or replaces) an account's credentials in the vault
+func (dp *DevPortal) AddAccount(name, username, password string) error {
+	if len(name) == 0 {
+		name = DefaultAccount
+	}
+	if !accountNameRE.MatchString(name) {
+		return fmt.Errorf("invalid account name %#v (must only contain letters, numbers, '-' and '_')", name)
+	}
+	if len(username) == 0 || len(password) == 0 {
+		return fmt.Errorf("a username and password are required")
+	}
+	dat, err := json.Marshal(&AppleAccountAuth{
+		Credentials: credentials{
+			Username: username,
+			Password: password,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal keychain credentials: %v", err)
+	}
+	return dp.Vault.Set(keyring.Item{
+		Key:         VaultKey(name),
+		Data:        dat,
+		Label:       AppName,
+		Description: "application password",
+	})
+}
+
+// RemoveAccount removes an account's credentials (and session) from the vault
+func (dp *DevPortal) RemoveAccount(name string) error {
+	if err := dp.Vault.Remove(VaultKey(name)); err != nil {
+		if errors.Is(err, keyring.ErrKeyNotFound) {
+			return fmt.Errorf("account %s not found in vault", name)
+		}
+		return fmt.Errorf("failed to remove account %s from vault: %v", name, err)
+	}
+	return nil
+}
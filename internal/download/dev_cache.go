@@ -0,0 +1,100 @@
+//go:build !wasm
+
+package download
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// DefaultDevCacheTTL is how long the parsed dev portal downloads lists are cached on disk
+const DefaultDevCacheTTL = 1 * time.Hour
+
+type devCache struct {
+	Created time.Time       `json:"created"`
+	Data    json.RawMessage `json:"data"`
+}
+
+// cachePath returns the on-disk cache for the given downloads list (per vault account)
+func (dp *DevPortal) cachePath(kind string) string {
+	return filepath.Join(dp.config.ConfigDir, "cache", fmt.Sprintf("%s.%s.json", dp.vaultKey(), kind))
+}
+
+// readCache unmarshals the cached downloads list into v (returns false if missing or stale)
+func (dp *DevPortal) readCache(kind string, v any) bool {
+	if dp.config.CacheTTL <= 0 || dp.config.RefreshCache {
+		return false
+	}
+	dat, err := os.ReadFile(dp.cachePath(kind))
+	if err != nil {
+		return false
+	}
+	var c devCache
+	if err := json.Unmarshal(dat, &c); err != nil {
+		dp.logger().Debugf("failed to parse dev portal cache %s: %v", dp.cachePath(kind), err)
+		return false
+	}
+	if time.Since(c.Created) > dp.config.CacheTTL {
+		return false
+	}
+	if err := json.Unmarshal(c.Data, v); err != nil {
+		dp.logger().Debugf("failed to parse dev portal cache %s: %v", dp.cachePath(kind), err)
+		return false
+	}
+	dp.logger().WithField("age", time.Since(c.Created).Round(time.Second)).Debugf("Using cached '%s' downloads (use --refresh to re-fetch)", kind)
+	return true
+}
+
+// writeCache saves the downloads list to the on-disk cache
+func (dp *DevPortal) writeCache(kind string, v any) error {
+	if dp.config.CacheTTL <= 0 {
+		return nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to marshal dev portal cache: %v", err)
+	}
+	dat, err := json.Marshal(devCache{Created: time.Now(), Data: data})
+	if err != nil {
+		return fmt.Errorf("failed to marshal dev portal cache: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(dp.cachePath(kind)), 0750); err != nil {
+		return fmt.Errorf("failed to create cache dir: %v", err)
+	}
+	return os.WriteFile(dp.cachePath(kind), dat, 0600)
+}
+
+// listDownloads returns the "More Downloads" list (from the on-disk cache if fresh)
+func (dp *DevPortal) listDownloads() (*Downloads, error) {
+	var dloads Downloads
+	if dp.readCache("more", &dloads) {
+		return &dloads, nil
+	}
+	fresh, err := dp.getDownloads()
+	if err != nil {
+		return nil, err
+	}
+	if err := dp.writeCache("more", fresh); err != nil {
+		dp.logger().Warnf("failed to cache dev portal downloads: %v", err)
+	}
+	return fresh, nil
+}
+
+// listDevDownloads returns the OS downloads (from the on-disk cache if fresh)
+func (dp *DevPortal) listDevDownloads() (map[string][]DevDownload, error) {
+	ipsws := make(map[string][]DevDownload)
+	if dp.readCache("os", &ipsws) {
+		return ipsws, nil
+	}
+	fresh, err := dp.getDevDownloads()
+	if err != nil {
+		return nil, err
+	}
+	if err := dp.writeCache("os", fresh); err != nil {
+		dp.logger().Warnf("failed to cache dev portal downloads: %v", err)
+	}
+	return fresh, nil
+}
@@ -1,4 +1,4 @@
-//go:build !ios
+//go:build !ios && !wasm
 
 package download
 
@@ -6,7 +6,6 @@ import (
 	"bytes"
 	"context"
 	"crypto/sha1"
-	"crypto/tls"
 	"encoding/binary"
 	"encoding/json"
 	"fmt"
@@ -16,6 +15,7 @@ import (
 	"net/http/cookiejar"
 	"net/url"
 	"os"
+	"path"
 	"path/filepath"
 	"reflect"
 	"regexp"
@@ -30,6 +30,8 @@ import (
 	"github.com/AlecAivazis/survey/v2/terminal"
 	"github.com/PuerkitoBio/goquery"
 	"github.com/apex/log"
+	"github.com/blacktop/ipsw/internal/exitcode"
+	"github.com/blacktop/ipsw/internal/utils"
 	"github.com/pkg/errors"
 )
 
@@ -63,8 +65,21 @@ const (
 	hashcashHeader         = "X-APPLE-HC"
 	hashcashCallengeHeader = "X-Apple-HC-Challenge"
 	hashcashBitsHeader     = "X-Apple-HC-Bits"
+
+	trustTokenHeader = "X-Apple-TwoSV-Trust-Token"
+	// sessionTTL is how long a stored session is reused (Apple trusts a browser for 2FA for 30 days)
+	sessionTTL = 30 * 24 * time.Hour
 )
 
+// sessionHosts are the hosts whose cookies make up an authenticated dev portal session
+var sessionHosts = []string{
+	"idmsa.apple.com",
+	"appstoreconnect.apple.com",
+	"developer.apple.com",
+	"download.developer.apple.com",
+	"developerservices2.apple.com",
+}
+
 const (
 	ERROR_CODE_TOO_MANY_CODES_SENT      = -22981 // Too many verification codes have been sent.
 	ERROR_CODE_BAD_CREDS                = -20101 // Your Apple ID or password was incorrect.
@@ -93,16 +108,26 @@ type DevConfig struct {
 	Insecure bool
 	// download type config
 	WatchList []string
+	Notifiers []Notifier // notified of NEW watched items
 	// behavior config
-	SkipAll       bool
-	ResumeAll     bool
-	RestartAll    bool
-	RemoveCommas  bool
-	PreferSMS     bool
-	PageSize      int
-	Verbose       bool
-	VaultPassword string
-	ConfigDir     string
+	SkipAll        bool
+	ResumeAll      bool
+	RestartAll     bool
+	RemoveCommas   bool
+	PreferSMS      bool
+	PageSize       int
+	Verbose        bool
+	VaultPassword  string
+	KeyringBackend string // auto, system, env or a keyring backend name (see KeyringBackends)
+	ConfigDir      string
+	Account        string        // vault credential profile (empty for the default account)
+	CacheTTL       time.Duration // how long to cache the downloads lists on disk (0 disables)
+	RefreshCache   bool          // ignore (and re-fetch) the cached downloads lists
+	// headless (CI) config
+	Headless   bool   // never prompt (credentials, vault password and 2FA must be supplied)
+	TrustToken string // pre-seeded 2FA trust token (skips two-factor auth)
+	// Logger (optional) receives the dev portal's log lines instead of the package logger (see SetLogger)
+	Logger log.Interface
 }
 
 // DevPortal is the dev portal object
@@ -119,6 +144,7 @@ type DevPortal struct {
 	olympusSession olympusResponse
 	// header values
 	xAppleIDAccountCountry string
+	trustToken             string
 }
 
 type credentials struct {
@@ -134,6 +160,10 @@ type session struct {
 	WidgetKey string         `json:"widget_key,omitempty"`
 	HashCash  string         `json:"hashcash,omitempty"`
 	Cookies   []*http.Cookie `json:"cookies,omitempty"`
+	// HostCookies are the session cookies (myacinfo, DES trust token, etc) for all the sessionHosts
+	HostCookies map[string][]*http.Cookie `json:"host_cookies,omitempty"`
+	TrustToken  string                    `json:"trust_token,omitempty"`
+	Expires     time.Time                 `json:"expires,omitempty"`
 }
 
 type AppleAccountAuth struct {
@@ -328,19 +358,23 @@ type MoreDownload struct {
 	Files         []dfile    `json:"files,omitempty"`
 }
 
-// NewDevPortal returns a new DevPortal instance
-func NewDevPortal(config *DevConfig) *DevPortal {
+// NewDevPortal returns a new DevPortal instance (WithTransport replaces the transport of config's proxy and
+// insecure settings and is used as is, without retries)
+func NewDevPortal(config *DevConfig, opts ...ClientOption) *DevPortal {
 	jar, _ := cookiejar.New(nil)
 
+	o := newClientOptions(opts)
+	if o.transport == nil {
+		o.transport = RetryTransport(NewTransport(config.Proxy, config.Insecure))
+	}
+
 	dp := DevPortal{
 		Client: &http.Client{
-			Jar: jar,
-			Transport: &http.Transport{
-				Proxy:           GetProxy(config.Proxy),
-				TLSClientConfig: &tls.Config{InsecureSkipVerify: config.Insecure},
-			},
+			Jar:       jar,
+			Transport: o.transport,
 		},
-		config: config,
+		config:     config,
+		trustToken: config.TrustToken,
 	}
 
 	return &dp
@@ -349,7 +383,7 @@ func NewDevPortal(config *DevConfig) *DevPortal {
 // Init DevPortal sets up the DevPortal vault
 func (dp *DevPortal) Init() (err error) {
 	// create credential vault (if it doesn't exist)
-	dp.Vault, err = keyring.Open(keyring.Config{
+	dp.Vault, err = OpenVault(keyring.Config{
 		ServiceName:                    KeychainServiceName,
 		KeychainSynchronizable:         false,
 		KeychainAccessibleWhenUnlocked: true,
@@ -357,16 +391,19 @@ func (dp *DevPortal) Init() (err error) {
 		FileDir:                        dp.config.ConfigDir,
 		FilePasswordFunc: func(string) (string, error) {
 			if len(dp.config.VaultPassword) == 0 {
-				msg := "Enter a password to decrypt your credentials vault: " + filepath.Join(dp.config.ConfigDir, VaultName)
-				if _, err := os.Stat(filepath.Join(dp.config.ConfigDir, VaultName)); errors.Is(err, os.ErrNotExist) {
-					msg = "Enter a password to encrypt your credentials to vault: " + filepath.Join(dp.config.ConfigDir, VaultName)
+				if dp.config.Headless {
+					return "", fmt.Errorf("a vault password is required in headless mode (set --vault-password or IPSW_DOWNLOAD_DEV_VAULT_PASSWORD)")
+				}
+				msg := "Enter a password to decrypt your credentials vault: " + filepath.Join(dp.config.ConfigDir, dp.vaultKey())
+				if _, err := os.Stat(filepath.Join(dp.config.ConfigDir, dp.vaultKey())); errors.Is(err, os.ErrNotExist) {
+					msg = "Enter a password to encrypt your credentials to vault: " + filepath.Join(dp.config.ConfigDir, dp.vaultKey())
 				}
 				prompt := &survey.Password{
 					Message: msg,
 				}
-				if err := survey.AskOne(prompt, &dp.config.VaultPassword); err != nil {
+				if err := utils.AskOne(prompt, &dp.config.VaultPassword); err != nil {
 					if err == terminal.InterruptErr {
-						log.Warn("Exiting...")
+						dp.logger().Warn("Exiting...")
 						os.Exit(0)
 					}
 					return "", err
@@ -374,7 +411,7 @@ func (dp *DevPortal) Init() (err error) {
 			}
 			return dp.config.VaultPassword, nil
 		},
-	})
+	}, dp.config.KeyringBackend, !dp.config.Headless || len(dp.config.VaultPassword) > 0)
 	if err != nil {
 		return fmt.Errorf("failed to open vault: %s", err)
 	}
@@ -382,6 +419,14 @@ func (dp *DevPortal) Init() (err error) {
 	return nil
 }
 
+// logger returns the configured logger of the dev portal (or the package logger)
+func (dp *DevPortal) logger() log.Interface {
+	if dp.config != nil && dp.config.Logger != nil {
+		return dp.config.Logger
+	}
+	return getLogger()
+}
+
 func (dp *DevPortal) GetSessionID() string {
 	return dp.config.SessionID
 }
@@ -395,20 +440,28 @@ func (dp *DevPortal) GetHashcash() string {
 	return dp.config.HashCash
 }
 
+// GetTrustToken returns the 2FA trust token of the session (can be pre-seeded with DevConfig.TrustToken)
+func (dp *DevPortal) GetTrustToken() string {
+	return dp.trustToken
+}
+
 // Login to Apple
 func (dp *DevPortal) Login(username, password string) error {
 	if len(username) == 0 || len(password) == 0 {
-		creds, err := dp.Vault.Get(VaultName)
+		creds, err := dp.Vault.Get(dp.vaultKey())
 		if err != nil { // failed to get credentials from vault (prompt user for credentials)
-			log.Errorf("failed to get credentials from vault: %v", err)
+			if dp.config.Headless {
+				return fmt.Errorf("failed to get credentials from vault (in headless mode set IPSW_DOWNLOAD_DEV_USERNAME and IPSW_DOWNLOAD_DEV_PASSWORD): %v", err)
+			}
+			dp.logger().Errorf("failed to get credentials from vault: %v", err)
 			// get username
 			if len(username) == 0 {
 				prompt := &survey.Input{
 					Message: "Please type your username:",
 				}
-				if err := survey.AskOne(prompt, &username); err != nil {
+				if err := utils.AskOne(prompt, &username); err != nil {
 					if err == terminal.InterruptErr {
-						log.Warn("Exiting...")
+						dp.logger().Warn("Exiting...")
 						os.Exit(0)
 					}
 					return err
@@ -419,9 +472,9 @@ func (dp *DevPortal) Login(username, password string) error {
 				prompt := &survey.Password{
 					Message: "Please type your password:",
 				}
-				if err := survey.AskOne(prompt, &password); err != nil {
+				if err := utils.AskOne(prompt, &password); err != nil {
 					if err == terminal.InterruptErr {
-						log.Warn("Exiting...")
+						dp.logger().Warn("Exiting...")
 						os.Exit(0)
 					}
 					return err
@@ -438,7 +491,7 @@ func (dp *DevPortal) Login(username, password string) error {
 				return fmt.Errorf("failed to marshal keychain credentials: %v", err)
 			}
 			dp.Vault.Set(keyring.Item{
-				Key:         VaultName,
+				Key:         dp.vaultKey(),
 				Data:        dat,
 				Label:       AppName,
 				Description: "application password",
@@ -525,7 +578,7 @@ func (dp *DevPortal) getITCServiceKey() error {
 		return fmt.Errorf("failed to deserialize response body JSON: %v", err)
 	}
 
-	log.Debugf("GET iTC Service Key: (%d):\n%s\n", response.StatusCode, string(body))
+	dp.logger().Debugf("GET iTC Service Key: (%d):\n%s\n", response.StatusCode, string(body))
 
 	if response.StatusCode != 200 {
 		return fmt.Errorf("failed to get iTC Service Key: response received %s", response.Status)
@@ -565,12 +618,18 @@ func (dp *DevPortal) signIn(username, password string) error {
 		return fmt.Errorf("failed to get hashcash headers: %v", err)
 	}
 
+	var trustTokens []string
+	if len(dp.trustToken) > 0 { // skips 2FA if this computer is still trusted
+		trustTokens = []string{dp.trustToken}
+	}
+
 	buf := new(bytes.Buffer)
 
 	json.NewEncoder(buf).Encode(&auth{
 		AccountName: username,
 		Password:    password,
 		RememberMe:  true,
+		TrustTokens: trustTokens,
 	})
 
 	req, err := http.NewRequest("POST", loginURL, buf)
@@ -596,9 +655,12 @@ func (dp *DevPortal) signIn(username, password string) error {
 		return err
 	}
 
-	log.Debugf("POST Login: (%d):\n%s\n", response.StatusCode, string(body))
+	dp.logger().Debugf("POST Login: (%d):\n%s\n", response.StatusCode, string(body))
 
 	if response.StatusCode == 409 {
+		if dp.config.Headless {
+			return fmt.Errorf("two-factor authentication required in headless mode: supply a trust token from an interactive login (see --show-trust-token)")
+		}
 		dp.xAppleIDAccountCountry = response.Header.Get("X-Apple-Id-Account-Country")
 		dp.config.SessionID = response.Header.Get("X-Apple-Id-Session-Id")
 		dp.config.SCNT = response.Header.Get("Scnt")
@@ -625,9 +687,9 @@ func (dp *DevPortal) signIn(username, password string) error {
 				Message: "Choose a phone number to send the SMS code to:",
 				Options: choices,
 			}
-			if err := survey.AskOne(prompt, &phoneNumber); err != nil {
+			if err := utils.AskOne(prompt, &phoneNumber); err != nil {
 				if err == terminal.InterruptErr {
-					log.Warn("Exiting...")
+					dp.logger().Warn("Exiting...")
 					os.Exit(0)
 				}
 				return err
@@ -644,7 +706,7 @@ func (dp *DevPortal) signIn(username, password string) error {
 				if err := dp.requestCode(1); err != nil {
 					if dp.codeRequest.SecurityCode.TooManyCodesSent {
 						codeType = "trusteddevice"
-						log.Warn("you must use the trusted device code (SMS codes have been disabled on your account)")
+						dp.logger().Warn("you must use the trusted device code (SMS codes have been disabled on your account)")
 					} else {
 						return err
 					}
@@ -680,9 +742,9 @@ func (dp *DevPortal) signIn(username, password string) error {
 			prompt := &survey.Password{
 				Message: "Please type your verification code:",
 			}
-			if err := survey.AskOne(prompt, &code); err != nil {
+			if err := utils.AskOne(prompt, &code); err != nil {
 				if err == terminal.InterruptErr {
-					log.Warn("Exiting...")
+					dp.logger().Warn("Exiting...")
 					os.Exit(0)
 				}
 				return err
@@ -727,7 +789,7 @@ func (dp *DevPortal) getAuthOptions() error {
 		return err
 	}
 
-	log.Debugf("GET getAuthOptions (%d):\n%s\n", response.StatusCode, string(body))
+	dp.logger().Debugf("GET getAuthOptions (%d):\n%s\n", response.StatusCode, string(body))
 
 	if err := json.Unmarshal(body, &dp.authOptions); err != nil {
 		return fmt.Errorf("failed to deserialize response body JSON: %v", err)
@@ -767,7 +829,7 @@ func (dp *DevPortal) requestCode(phoneID int) error {
 		return err
 	}
 
-	log.Debugf("PUT requestCode (%d):\n%s\n", response.StatusCode, string(body))
+	dp.logger().Debugf("PUT requestCode (%d):\n%s\n", response.StatusCode, string(body))
 
 	if err := json.Unmarshal(body, &dp.codeRequest); err != nil {
 		return fmt.Errorf("failed to deserialize response body JSON: %v", err)
@@ -783,7 +845,7 @@ func (dp *DevPortal) requestCode(phoneID int) error {
 		}
 
 		if response.StatusCode == 423 { // code rate limiting
-			log.Error(errStr)
+			dp.logger().Error(errStr)
 			return nil
 		}
 
@@ -831,7 +893,7 @@ func (dp *DevPortal) verifyCode(codeType, code string, phoneID int) error {
 		return err
 	}
 
-	log.Debugf("POST verifyCode (%d):\n%s\n", response.StatusCode, string(body))
+	dp.logger().Debugf("POST verifyCode (%d):\n%s\n", response.StatusCode, string(body))
 
 	if 200 > response.StatusCode || 300 <= response.StatusCode {
 		if len(body) > 0 {
@@ -874,7 +936,7 @@ func (dp *DevPortal) trustSession() error {
 		return err
 	}
 
-	log.Debugf("GET trustSession: (%d):\n%s\n", response.StatusCode, string(body))
+	dp.logger().Debugf("GET trustSession: (%d):\n%s\n", response.StatusCode, string(body))
 
 	if 200 > response.StatusCode || 300 <= response.StatusCode {
 		if len(body) > 0 {
@@ -893,6 +955,10 @@ func (dp *DevPortal) trustSession() error {
 		return fmt.Errorf("failed to update to trusted session: response received %s", response.Status)
 	}
 
+	if token := response.Header.Get(trustTokenHeader); len(token) > 0 {
+		dp.trustToken = token
+	}
+
 	return nil
 }
 
@@ -924,7 +990,7 @@ func (dp *DevPortal) getOlympusSession() error {
 		return err
 	}
 
-	log.Debugf("GET getOlympusSession (%d):\n%s\n", response.StatusCode, string(body))
+	dp.logger().Debugf("GET getOlympusSession (%d):\n%s\n", response.StatusCode, string(body))
 
 	if 200 > response.StatusCode || 300 <= response.StatusCode {
 		return fmt.Errorf("failed to get auth options: response received %s", response.Status)
@@ -933,7 +999,7 @@ func (dp *DevPortal) getOlympusSession() error {
 	if err := json.Unmarshal(body, &dp.olympusSession); err != nil {
 		var wat any
 		json.Unmarshal(body, &wat)
-		log.Errorf("%#v", wat)
+		dp.logger().Errorf("%#v", wat)
 		return fmt.Errorf("failed to deserialize response body JSON: %v", err)
 	}
 
@@ -941,23 +1007,39 @@ func (dp *DevPortal) getOlympusSession() error {
 }
 
 func (dp *DevPortal) storeSession() error {
-	// get dev auth from vault
-	sess, err := dp.Vault.Get(VaultName)
-	if err != nil {
+	var auth AppleAccountAuth
+
+	// get dev auth from vault (credentials supplied via flags/env are never stored)
+	sess, err := dp.Vault.Get(dp.vaultKey())
+	if err == nil {
+		if err := json.Unmarshal(sess.Data, &auth); err != nil {
+			return fmt.Errorf("failed to unmarshal dev auth: %v", err)
+		}
+	} else if !errors.Is(err, keyring.ErrKeyNotFound) {
 		return fmt.Errorf("failed to get dev auth from vault: %v", err)
 	}
 
-	var auth AppleAccountAuth
-	if err := json.Unmarshal(sess.Data, &auth); err != nil {
-		return fmt.Errorf("failed to unmarshal dev auth: %v", err)
+	expires := auth.DevPortalSession.Expires
+	if auth.DevPortalSession.SessionID != dp.GetSessionID() || expires.IsZero() {
+		expires = time.Now().Add(sessionTTL) // new session
+	}
+
+	hostCookies := make(map[string][]*http.Cookie)
+	for _, host := range sessionHosts {
+		if cookies := dp.Client.Jar.Cookies(&url.URL{Scheme: "https", Host: host}); len(cookies) > 0 {
+			hostCookies[host] = cookies
+		}
 	}
 
 	auth.DevPortalSession = session{
-		SessionID: dp.GetSessionID(),
-		SCNT:      dp.GetSCNT(),
-		WidgetKey: dp.GetWidgetKey(),
-		HashCash:  dp.GetHashcash(),
-		Cookies:   dp.Client.Jar.Cookies(&url.URL{Scheme: "https", Host: "idmsa.apple.com"}),
+		SessionID:   dp.GetSessionID(),
+		SCNT:        dp.GetSCNT(),
+		WidgetKey:   dp.GetWidgetKey(),
+		HashCash:    dp.GetHashcash(),
+		Cookies:     hostCookies["idmsa.apple.com"],
+		HostCookies: hostCookies,
+		TrustToken:  dp.trustToken,
+		Expires:     expires,
 	}
 
 	// save dev auth to vault
@@ -970,7 +1052,7 @@ func (dp *DevPortal) storeSession() error {
 	auth = AppleAccountAuth{}
 
 	dp.Vault.Set(keyring.Item{
-		Key:         VaultName,
+		Key:         dp.vaultKey(),
 		Data:        data,
 		Label:       AppName,
 		Description: "application password",
@@ -981,7 +1063,7 @@ func (dp *DevPortal) storeSession() error {
 
 func (dp *DevPortal) loadSession() error {
 	// get dev auth from vault
-	sess, err := dp.Vault.Get(VaultName)
+	sess, err := dp.Vault.Get(dp.vaultKey())
 	if err != nil {
 		return fmt.Errorf("failed to get dev auth from vault: %v", err)
 	}
@@ -991,11 +1073,29 @@ func (dp *DevPortal) loadSession() error {
 		return fmt.Errorf("failed to unmarshal dev auth: %v", err)
 	}
 
+	// the trust token is kept even if the session has expired so re-login can skip 2FA
+	if len(dp.trustToken) == 0 {
+		dp.trustToken = auth.DevPortalSession.TrustToken
+	}
+
+	if len(auth.DevPortalSession.SessionID) == 0 {
+		return fmt.Errorf("no stored session")
+	}
+	if !auth.DevPortalSession.Expires.IsZero() && time.Now().After(auth.DevPortalSession.Expires) {
+		return fmt.Errorf("stored session expired on %s", auth.DevPortalSession.Expires.Format(time.RFC1123))
+	}
+
 	dp.config.SessionID = auth.DevPortalSession.SessionID
 	dp.config.SCNT = auth.DevPortalSession.SCNT
 	dp.config.WidgetKey = auth.DevPortalSession.WidgetKey
 	dp.config.HashCash = auth.DevPortalSession.HashCash
-	dp.Client.Jar.SetCookies(&url.URL{Scheme: "https", Host: "idmsa.apple.com"}, auth.DevPortalSession.Cookies)
+	if len(auth.DevPortalSession.HostCookies) > 0 {
+		for host, cookies := range auth.DevPortalSession.HostCookies {
+			dp.Client.Jar.SetCookies(&url.URL{Scheme: "https", Host: host}, cookies)
+		}
+	} else { // sessions stored by older versions
+		dp.Client.Jar.SetCookies(&url.URL{Scheme: "https", Host: "idmsa.apple.com"}, auth.DevPortalSession.Cookies)
+	}
 
 	// clear dev auth mem
 	auth = AppleAccountAuth{}
@@ -1004,7 +1104,10 @@ func (dp *DevPortal) loadSession() error {
 		return err
 	}
 
-	return nil
+	dp.logger().Debug("Reusing stored dev portal session")
+
+	// save any refreshed cookies
+	return dp.storeSession()
 }
 
 // Watch watches for NEW downloads
@@ -1013,6 +1116,9 @@ func (dp *DevPortal) Watch(ctx context.Context, downloadType, folder string, dur
 	var prevDownloads []MoreDownload
 	var prevIPSWs map[string][]DevDownload
 
+	seen := make(map[string]bool)
+	first := true // the first scrape is the baseline (nothing is NEW yet)
+
 	for {
 		// scrape dev portal
 		switch downloadType {
@@ -1043,6 +1149,16 @@ func (dp *DevPortal) Watch(ctx context.Context, downloadType, folder string, dur
 						return fmt.Errorf("failed to compile regex watch pattern '%s': %v", watchPattern, err)
 					}
 					if re.MatchString(dl.Name) {
+						if !seen[dl.Name] {
+							seen[dl.Name] = true
+							if !first {
+								ev := &WatchEvent{Name: dl.Name, Type: downloadType, Found: time.Now()}
+								for _, f := range dl.Files {
+									ev.Files = append(ev.Files, WatchFile{Name: f.Filename, Size: int64(f.FileSize), URL: f.URL()})
+								}
+								dp.notify(ev)
+							}
+						}
 						for _, f := range dl.Files {
 							dp.Download(f.URL(), folder)
 						}
@@ -1076,15 +1192,36 @@ func (dp *DevPortal) Watch(ctx context.Context, downloadType, folder string, dur
 						return fmt.Errorf("failed to compile regex watch pattern '%s': %v", watchPattern, err)
 					}
 					if re.MatchString(version) {
+						ev := &WatchEvent{Name: version, Type: downloadType, Found: time.Now()}
+						for _, ipsw := range ipsws[version] {
+							if !seen[ipsw.URL] {
+								seen[ipsw.URL] = true
+								ev.Files = append(ev.Files, WatchFile{Name: ipsw.Title, URL: ipsw.URL})
+							}
+						}
+						if !first && len(ev.Files) > 0 {
+							dp.notify(ev)
+						}
 						for _, ipsw := range ipsws[version] {
 							if err := dp.Download(ipsw.URL, folder); err != nil {
-								log.Errorf("failed to download %s: %v", ipsw.URL, err)
+								dp.logger().Errorf("failed to download %s: %v", ipsw.URL, err)
 							}
 						}
 					}
 				}
 			}
 		}
+		first = false
+	}
+}
+
+// notify sends a NEW watched item to all the notifiers
+func (dp *DevPortal) notify(ev *WatchEvent) {
+	dp.logger().WithField("files", len(ev.Files)).Infof("Found NEW %s", ev.Name)
+	for _, n := range dp.config.Notifiers {
+		if err := n.Notify(ev); err != nil {
+			dp.logger().Errorf("failed to send notification: %v", err)
+		}
 	}
 }
 
@@ -1092,7 +1229,7 @@ func (dp *DevPortal) Watch(ctx context.Context, downloadType, folder string, dur
 func (dp *DevPortal) DownloadPrompt(downloadType, folder string) error {
 	switch downloadType {
 	case "more":
-		dloads, err := dp.getDownloads()
+		dloads, err := dp.listDownloads()
 		if err != nil {
 			return fmt.Errorf("failed to get the '%s' downloads: %v", downloadType, err)
 		}
@@ -1108,21 +1245,24 @@ func (dp *DevPortal) DownloadPrompt(downloadType, folder string) error {
 			Options:  choices,
 			PageSize: dp.config.PageSize,
 		}
-		if err := survey.AskOne(prompt, &dfiles); err == terminal.InterruptErr {
-			log.Warn("Exiting...")
-			os.Exit(0)
+		if err := utils.AskOne(prompt, &dfiles); err != nil {
+			if err == terminal.InterruptErr {
+				dp.logger().Warn("Exiting...")
+				os.Exit(0)
+			}
+			return err
 		}
 
 		for _, idx := range dfiles {
 			for _, f := range dloads.Downloads[idx].Files {
-				log.Debugf("Downloading: %s", f.URL())
+				dp.logger().Debugf("Downloading: %s", f.URL())
 				if err := dp.Download(f.URL(), folder); err != nil {
-					log.Errorf("failed to download %s: %v", f.URL(), err)
+					dp.logger().Errorf("failed to download %s: %v", f.URL(), err)
 				}
 			}
 		}
 	default:
-		ipsws, err := dp.getDevDownloads()
+		ipsws, err := dp.listDevDownloads()
 		if err != nil {
 			return fmt.Errorf("failed to get the '%s' downloads: %v", downloadType, err)
 		}
@@ -1139,9 +1279,9 @@ func (dp *DevPortal) DownloadPrompt(downloadType, folder string) error {
 			Options:  versions,
 			PageSize: 15,
 		}
-		if err := survey.AskOne(promptVer, &version); err != nil {
+		if err := utils.AskOne(promptVer, &version); err != nil {
 			if err == terminal.InterruptErr {
-				log.Warn("Exiting...")
+				dp.logger().Warn("Exiting...")
 				os.Exit(0)
 			}
 			return err
@@ -1159,9 +1299,9 @@ func (dp *DevPortal) DownloadPrompt(downloadType, folder string) error {
 				Options:  choices,
 				PageSize: dp.config.PageSize,
 			}
-			if err := survey.AskOne(prompt, &dfiles); err != nil {
+			if err := utils.AskOne(prompt, &dfiles); err != nil {
 				if err == terminal.InterruptErr {
-					log.Warn("Exiting...")
+					dp.logger().Warn("Exiting...")
 					os.Exit(0)
 				}
 				return err
@@ -1178,6 +1318,72 @@ func (dp *DevPortal) DownloadPrompt(downloadType, folder string) error {
 	return nil
 }
 
+// DownloadMatching downloads every file whose name matches the regex pattern or glob filter (without prompting)
+// and returns the number of files matched
+func (dp *DevPortal) DownloadMatching(downloadType, pattern, filter, folder string) (int, error) {
+	var re *regexp.Regexp
+	if len(pattern) > 0 {
+		var err error
+		re, err = regexp.Compile(pattern)
+		if err != nil {
+			return 0, fmt.Errorf("failed to compile regex pattern '%s': %v", pattern, err)
+		}
+	}
+	match := func(names ...string) bool {
+		for _, name := range names {
+			if len(name) == 0 {
+				continue
+			}
+			if re != nil && re.MatchString(name) {
+				return true
+			}
+			if len(filter) > 0 {
+				if ok, _ := path.Match(strings.ToLower(filter), strings.ToLower(name)); ok {
+					return true
+				}
+			}
+		}
+		return false
+	}
+
+	var urls []string
+	switch downloadType {
+	case "more":
+		dloads, err := dp.listDownloads()
+		if err != nil {
+			return 0, fmt.Errorf("failed to get the '%s' downloads: %v", downloadType, err)
+		}
+		for _, dl := range dloads.Downloads {
+			for _, f := range dl.Files {
+				if match(dl.Name, f.DisplayName, f.Filename) {
+					urls = append(urls, f.URL())
+				}
+			}
+		}
+	default:
+		ipsws, err := dp.listDevDownloads()
+		if err != nil {
+			return 0, fmt.Errorf("failed to get the '%s' downloads: %v", downloadType, err)
+		}
+		for version, dls := range ipsws {
+			for _, ipsw := range dls {
+				if match(version, ipsw.Title) {
+					urls = append(urls, ipsw.URL)
+				}
+			}
+		}
+	}
+
+	sort.Strings(urls)
+	for i, u := range urls {
+		if err := dp.Download(u, folder); err != nil {
+			return i, exitcode.WrapPartial(i, fmt.Errorf("failed to download %s (%d of %d downloaded): %w", u, i, len(urls), err))
+		}
+	}
+
+	return len(urls), nil
+}
+
 // Download downloads a file that requires a valid dev portal session
 func (dp *DevPortal) Download(url, folder string) error {
 
@@ -1193,13 +1399,21 @@ func (dp *DevPortal) Download(url, folder string) error {
 	)
 	// use authenticated client
 	downloader.client = dp.Client
+	// re-validate the session (and ADCDownloadAuth cookie) if it expires mid-transfer
+	downloader.Refresh = func() error {
+		if err := dp.refreshSession(); err != nil {
+			return err
+		}
+		_, err := dp.getADCDownloadAuth(url)
+		return err
+	}
 
 	destName := getDestName(url, dp.config.RemoveCommas)
 	destName = filepath.Join(filepath.Clean(folder), filepath.Base(destName))
 
 	if _, err := os.Stat(destName); os.IsNotExist(err) {
 
-		log.WithFields(log.Fields{
+		dp.logger().WithFields(log.Fields{
 			"file": destName,
 		}).Info("Downloading")
 
@@ -1209,40 +1423,51 @@ func (dp *DevPortal) Download(url, folder string) error {
 
 		err = downloader.Do()
 		if err != nil {
-			return fmt.Errorf("failed to download file: %v", err)
+			return fmt.Errorf("failed to download file: %w", err)
 		}
 
 	} else {
-		log.Warnf("file already exists: %s", destName)
+		dp.logger().Warnf("file already exists: %s", destName)
 	}
 
 	return nil
 }
 
-// DownloadADC downloads an ADC file that requires a valid ADCDownloadAuth cookie, but not full dev portal session auth
-func (dp *DevPortal) DownloadADC(adcURL string) error {
+// getADCDownloadAuth requests a fresh ADCDownloadAuth cookie for the given ADC download URL
+func (dp *DevPortal) getADCDownloadAuth(adcURL string) (string, error) {
 	var adcDownloadAuth string
 
 	u, err := url.Parse(adcURL)
 	if err != nil {
-		return fmt.Errorf("failed to parse url '%s': %v", adcURL, err)
+		return "", fmt.Errorf("failed to parse url '%s': %v", adcURL, err)
 	}
 
 	req, err := http.NewRequest("GET", adcDownloadURL+u.Path, nil)
 	if err != nil {
-		return fmt.Errorf("failed to create http GET request: %v", err)
+		return "", fmt.Errorf("failed to create http GET request: %v", err)
 	}
 	req.Header.Set("Content-Type", "*/*")
 
 	response, err := dp.Client.Do(req)
 	if err != nil {
-		return err
+		return "", err
 	}
+	response.Body.Close()
 
 	if response.Header.Get("Set-Cookie") != "" {
 		_, adcDownloadAuth, _ = strings.Cut(response.Header.Get("Set-Cookie"), "ADCDownloadAuth=")
 	}
 
+	return adcDownloadAuth, nil
+}
+
+// DownloadADC downloads an ADC file that requires a valid ADCDownloadAuth cookie, but not full dev portal session auth
+func (dp *DevPortal) DownloadADC(adcURL string) error {
+	adcDownloadAuth, err := dp.getADCDownloadAuth(adcURL)
+	if err != nil {
+		return err
+	}
+
 	// proxy, insecure are null because we override the client below
 	downloader := NewDownload(
 		dp.config.Proxy,
@@ -1258,12 +1483,21 @@ func (dp *DevPortal) DownloadADC(adcURL string) error {
 	downloader.client = dp.Client
 	// set auth cookie (for authless downloads)
 	downloader.Headers["Cookie"] = "ADCDownloadAuth=" + adcDownloadAuth
+	// refresh the auth cookie if it expires mid-transfer
+	downloader.Refresh = func() error {
+		adcDownloadAuth, err := dp.getADCDownloadAuth(adcURL)
+		if err != nil {
+			return err
+		}
+		downloader.Headers["Cookie"] = "ADCDownloadAuth=" + adcDownloadAuth
+		return nil
+	}
 
 	// destName := getDestName(adcDownloadURL+path, dp.config.RemoveCommas)
 	destName := getDestName(adcURL, dp.config.RemoveCommas)
 	if _, err := os.Stat(destName); os.IsNotExist(err) {
 
-		log.WithFields(log.Fields{
+		dp.logger().WithFields(log.Fields{
 			"file": destName,
 		}).Info("Downloading")
 
@@ -1274,30 +1508,108 @@ func (dp *DevPortal) DownloadADC(adcURL string) error {
 		return downloader.Do()
 	}
 
-	log.Warnf("file already exists: %s", destName)
+	dp.logger().Warnf("file already exists: %s", destName)
 	return nil
 }
 
-func (dp *DevPortal) DownloadKDK(version, build, folder string) error {
+// GetKDKs returns the Kernel Debug Kits listed in the dev portal's "More" downloads
+func (dp *DevPortal) GetKDKs() ([]MoreDownload, error) {
 	if err := dp.refreshSession(); err != nil {
+		return nil, err
+	}
+
+	dloads, err := dp.listDownloads()
+	if err != nil {
+		return nil, err
+	}
+
+	var kdks []MoreDownload
+	for _, dl := range dloads.Downloads {
+		if strings.Contains(dl.Name, "Kernel Debug Kit") {
+			kdks = append(kdks, dl)
+		}
+	}
+
+	return kdks, nil
+}
+
+var kdkFileRE = regexp.MustCompile(`^Kernel_Debug_Kit_(.+)_build_([0-9A-Za-z]+)\.dmg$`)
+
+// ListKDKs returns the Kernel Debug Kits of the dev portal's "More" downloads (newest first)
+func (dp *DevPortal) ListKDKs() (KDKs, error) {
+	dloads, err := dp.GetKDKs()
+	if err != nil {
+		return nil, err
+	}
+
+	var kdks KDKs
+	for _, dl := range dloads {
+		for _, f := range dl.Files {
+			m := kdkFileRE.FindStringSubmatch(f.Filename)
+			if m == nil {
+				continue
+			}
+			kdk := KDK{
+				Name:     dl.Name,
+				Version:  m[1],
+				Build:    m[2],
+				FileSize: f.FileSize,
+				URL:      f.URL(),
+			}
+			if t, err := time.Parse("01/02/06 15:04", dl.DateCreated); err == nil {
+				kdk.Date = KDKDate(t)
+			}
+			kdks = append(kdks, kdk)
+		}
+	}
+	sort.Sort(kdks)
+
+	return kdks, nil
+}
+
+// DownloadKDK downloads the KDK for the given macOS build (or the current host's build if build is empty)
+func (dp *DevPortal) DownloadKDK(version, build, folder string) error {
+	if len(build) == 0 {
+		binfo, err := utils.GetBuildInfo()
+		if err != nil {
+			return fmt.Errorf("failed to get host build info (you must supply a --build): %v", err)
+		}
+		version = binfo.ProductVersion
+		build = binfo.BuildVersion
+	}
+
+	kdks, err := dp.GetKDKs()
+	if err != nil {
 		return err
 	}
+	for _, kdk := range kdks {
+		for _, f := range kdk.Files {
+			if strings.Contains(f.Filename, "_build_"+build+".") {
+				dp.logger().WithField("url", f.URL()).Info("Downloading KDK")
+				return dp.Download(f.URL(), folder)
+			}
+		}
+	}
+
+	if len(version) == 0 {
+		return fmt.Errorf("failed to find KDK for build %s (try again with the '--version' flag)", build)
+	}
+
 	url := fmt.Sprintf("%s?path=/macOS/Kernel_Debug_Kit_%s_build_%s/Kernel_Debug_Kit_%s_build_%s.dmg", downloadActionURL,
 		version,
 		build,
 		version,
 		build,
 	)
-	log.WithField("url", url).Info("Downloading KDK")
-	err := dp.Download(url, folder)
-	if err != nil {
+	dp.logger().WithField("url", url).Info("Downloading KDK")
+	if err := dp.Download(url, folder); err != nil {
 		url := fmt.Sprintf("%s?path=/Developer_Tools/Kernel_Debug_Kit_%s_build_%s/Kernel_Debug_Kit_%s_build_%s.dmg", downloadActionURL,
 			version,
 			build,
 			version,
 			build,
 		)
-		log.WithField("url", url).Info("Downloading KDK (retry)")
+		dp.logger().WithField("url", url).Info("Downloading KDK (retry)")
 		return dp.Download(url, folder)
 	}
 	return nil
@@ -1306,7 +1618,7 @@ func (dp *DevPortal) DownloadKDK(version, build, folder string) error {
 func (dp *DevPortal) GetDownloadsAsJSON(downloadType string, pretty bool) ([]byte, error) {
 	switch downloadType {
 	case "more":
-		dloads, err := dp.getDownloads()
+		dloads, err := dp.listDownloads()
 		if err != nil {
 			return nil, fmt.Errorf("failed to get the '%s' downloads: %v", downloadType, err)
 		}
@@ -1315,7 +1627,7 @@ func (dp *DevPortal) GetDownloadsAsJSON(downloadType string, pretty bool) ([]byt
 		}
 		return json.Marshal(dloads)
 	default:
-		ipsws, err := dp.getDevDownloads()
+		ipsws, err := dp.listDevDownloads()
 		if err != nil {
 			return nil, fmt.Errorf("failed to get developer downloads: %v", err)
 		}
@@ -1326,6 +1638,35 @@ func (dp *DevPortal) GetDownloadsAsJSON(downloadType string, pretty bool) ([]byt
 	}
 }
 
+// DevDownloads are the dev portal downloads of ListDownloads
+type DevDownloads struct {
+	OS   map[string][]DevDownload `json:"os,omitempty"`   // OS downloads by version
+	More []MoreDownload           `json:"more,omitempty"` // "More Downloads" (newest first)
+}
+
+// ListDownloads returns the dev portal downloads of a type (os or more, empty for both)
+func (dp *DevPortal) ListDownloads(downloadType string) (*DevDownloads, error) {
+	var dls DevDownloads
+	if downloadType != "" && downloadType != "os" && downloadType != "more" {
+		return nil, fmt.Errorf("unsupported download type '%s' (must be os or more): %w", downloadType, ErrInvalidArgument)
+	}
+	if downloadType != "more" {
+		ipsws, err := dp.listDevDownloads()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get developer downloads: %v", err)
+		}
+		dls.OS = ipsws
+	}
+	if downloadType != "os" {
+		dloads, err := dp.listDownloads()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get the 'more' downloads: %v", err)
+		}
+		dls.More = dloads.Downloads
+	}
+	return &dls, nil
+}
+
 // getDownloads returns all the downloads in "More Downloads" - https://developer.apple.com/download/all/
 func (dp *DevPortal) getDownloads() (*Downloads, error) {
 	var downloads Downloads
@@ -1353,7 +1694,7 @@ func (dp *DevPortal) getDownloads() (*Downloads, error) {
 		return nil, fmt.Errorf("failed to deserialize response body JSON: %v", err)
 	}
 
-	log.Debugf("Get Downloads: (%d):\n%s\n", response.StatusCode, string(body))
+	dp.logger().Debugf("Get Downloads: (%d):\n%s\n", response.StatusCode, string(body))
 
 	// sort by file name
 	// sort.Slice(downloads.Downloads, func(i, j int) bool {
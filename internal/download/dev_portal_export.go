@@ -0,0 +1,98 @@
+//go:build ffi
+
+package download
+
+//#cgo LDFLAGS:
+//#include <stdio.h>
+//#include <stdlib.h>
+//#include <string.h>
+import "C"
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/blacktop/ipsw/internal/exitcode"
+)
+
+// The dev portal is exposed as a session handle:
+//
+//	c_devportal_new             opens a (headless) session from a DevSessionConfig JSON object
+//	c_devportal_login           logs in (reusing the stored session when it is still valid)
+//	c_devportal_list_downloads  lists the os and/or more downloads
+//	c_devportal_download        downloads a file that requires the logged in session
+//	c_devportal_free            releases the session handle
+//
+// Sessions never prompt: the credentials (or a vault holding them), the vault password and a 2FA
+// trust token (i.e. from `ipsw download dev --show-trust-token`) must be supplied. The calls on a session
+// are serialized (a download blocks the other calls on its session until it is done).
+
+// c_devportal_new opens a dev portal session (an empty config uses the defaults); free it with c_devportal_free
+//
+//export c_devportal_new
+func c_devportal_new(configJson *C.char, configJsonLen C.uint, outHandle **C.char, outHandleLen *C.uint, err **C.char, errLen *C.uint) C.char {
+	var config DevSessionConfig
+	if configJsonLen > 0 {
+		if jsonErr := json.Unmarshal([]byte(C.GoStringN(configJson, C.int(configJsonLen))), &config); jsonErr != nil {
+			return setCError(jsonErr, fmt.Sprintf("c_devportal_new: Deser failed with %v", jsonErr), err, errLen)
+		}
+	}
+	id, serr := NewDevSession(&config)
+	if serr != nil {
+		return setCError(serr, fmt.Sprintf("c_devportal_new: %v", serr), err, errLen)
+	}
+	cs := C.CString(id)
+	*outHandle = cs
+	*outHandleLen = C.uint(C.strlen(cs))
+	return C.char(1)
+}
+
+// c_devportal_login logs in to the dev portal (empty credentials are read from the session's vault)
+//
+//export c_devportal_login
+func c_devportal_login(handle *C.char, handleLen C.uint, username *C.char, usernameLen C.uint, password *C.char, passwordLen C.uint, err **C.char, errLen *C.uint) C.char {
+	if serr := WithDevSession(C.GoStringN(handle, C.int(handleLen)), func(dp *DevPortal) error {
+		if lerr := dp.Login(C.GoStringN(username, C.int(usernameLen)), C.GoStringN(password, C.int(passwordLen))); lerr != nil {
+			return exitcode.Errorf(exitcode.Auth, "failed to login: %v", lerr)
+		}
+		return nil
+	}); serr != nil {
+		return setCError(serr, fmt.Sprintf("c_devportal_login: %v", serr), err, errLen)
+	}
+	return C.char(1)
+}
+
+// c_devportal_list_downloads lists the downloads of a type (os or more, empty for both) of a logged in session
+//
+//export c_devportal_list_downloads
+func c_devportal_list_downloads(handle *C.char, handleLen C.uint, downloadType *C.char, downloadTypeLen C.uint, outJson **C.char, outJsonLen *C.uint, err **C.char, errLen *C.uint) C.char {
+	var dls *DevDownloads
+	if serr := WithDevSession(C.GoStringN(handle, C.int(handleLen)), func(dp *DevPortal) (lerr error) {
+		dls, lerr = dp.ListDownloads(C.GoStringN(downloadType, C.int(downloadTypeLen)))
+		return lerr
+	}); serr != nil {
+		return setCError(serr, fmt.Sprintf("c_devportal_list_downloads: %v", serr), err, errLen)
+	}
+	return setCJSON(dls, outJson, outJsonLen, err, errLen, "c_devportal_list_downloads", "DevDownloads object")
+}
+
+// c_devportal_download downloads a dev portal URL (i.e. a url from c_devportal_list_downloads) into folder
+//
+//export c_devportal_download
+func c_devportal_download(handle *C.char, handleLen C.uint, url *C.char, urlLen C.uint, folder *C.char, folderLen C.uint, err **C.char, errLen *C.uint) C.char {
+	if urlLen == 0 {
+		return setCError(ErrInvalidArgument, fmt.Sprintf("c_devportal_download: %v: empty url", ErrInvalidArgument), err, errLen)
+	}
+	if serr := WithDevSession(C.GoStringN(handle, C.int(handleLen)), func(dp *DevPortal) error {
+		return dp.Download(C.GoStringN(url, C.int(urlLen)), C.GoStringN(folder, C.int(folderLen)))
+	}); serr != nil {
+		return setCError(serr, fmt.Sprintf("c_devportal_download: %v", serr), err, errLen)
+	}
+	return C.char(1)
+}
+
+// c_devportal_free releases a dev portal session handle
+//
+//export c_devportal_free
+func c_devportal_free(handle *C.char, handleLen C.uint) {
+	ReleaseDevSession(C.GoStringN(handle, C.int(handleLen)))
+}
@@ -0,0 +1,117 @@
+//go:build !wasm
+
+package download
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// DevSessionConfig is the (JSON) config of a dev portal session opened through the C API;
+// sessions are always headless so the credentials, vault password and 2FA trust token must be supplied
+type DevSessionConfig struct {
+	Proxy          string `json:"proxy,omitempty"`
+	Insecure       bool   `json:"insecure,omitempty"`
+	ConfigDir      string `json:"config_dir,omitempty"` // defaults to ~/.ipsw
+	VaultPassword  string `json:"vault_password,omitempty"`
+	KeyringBackend string `json:"keyring_backend,omitempty"` // auto, system, env or a keyring backend name
+	Account        string `json:"account,omitempty"`
+	TrustToken     string `json:"trust_token,omitempty"`
+	CacheTTL       string `json:"cache_ttl,omitempty"` // i.e. 1h (empty disables the downloads list cache)
+	RefreshCache   bool   `json:"refresh_cache,omitempty"`
+	SkipAll        bool   `json:"skip_all,omitempty"`
+	ResumeAll      bool   `json:"resume_all,omitempty"`
+	RestartAll     bool   `json:"restart_all,omitempty"`
+	RemoveCommas   bool   `json:"remove_commas,omitempty"`
+	PreferSMS      bool   `json:"prefer_sms,omitempty"`
+}
+
+// DevConfig returns the headless DevConfig of the session config
+func (c *DevSessionConfig) DevConfig() (*DevConfig, error) {
+	conf := &DevConfig{
+		Proxy:          c.Proxy,
+		Insecure:       c.Insecure,
+		ConfigDir:      c.ConfigDir,
+		VaultPassword:  c.VaultPassword,
+		KeyringBackend: c.KeyringBackend,
+		Account:        c.Account,
+		TrustToken:     c.TrustToken,
+		RefreshCache:   c.RefreshCache,
+		SkipAll:        c.SkipAll,
+		ResumeAll:      c.ResumeAll,
+		RestartAll:     c.RestartAll,
+		RemoveCommas:   c.RemoveCommas,
+		PreferSMS:      c.PreferSMS,
+		Headless:       true,
+	}
+	if len(c.CacheTTL) > 0 {
+		ttl, err := time.ParseDuration(c.CacheTTL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cache_ttl %#v: %w", c.CacheTTL, ErrInvalidArgument)
+		}
+		conf.CacheTTL = ttl
+	}
+	if len(conf.ConfigDir) == 0 {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get user home directory: %v", err)
+		}
+		conf.ConfigDir = filepath.Join(home, ".ipsw")
+	}
+	return conf, nil
+}
+
+// devSession is a dev portal session opened through the C API (its calls are serialized)
+type devSession struct {
+	sync.Mutex
+	dp *DevPortal
+}
+
+// devSessions are the dev portal sessions opened through the C API
+var devSessions = struct {
+	sync.Mutex
+	next uint64
+	m    map[string]*devSession
+}{m: make(map[string]*devSession)}
+
+// NewDevSession creates a headless dev portal (with its credentials vault opened) and returns its session ID
+func NewDevSession(config *DevSessionConfig) (string, error) {
+	conf, err := config.DevConfig()
+	if err != nil {
+		return "", err
+	}
+	dp := NewDevPortal(conf)
+	if err := dp.Init(); err != nil {
+		return "", fmt.Errorf("failed to initialize dev portal: %v", err)
+	}
+	devSessions.Lock()
+	defer devSessions.Unlock()
+	devSessions.next++
+	id := fmt.Sprintf("devportal-%d", devSessions.next)
+	devSessions.m[id] = &devSession{dp: dp}
+	return id, nil
+}
+
+// WithDevSession calls fn with the dev portal of a session ID; calls on the same session
+// are serialized as a DevPortal is not safe for concurrent use
+func WithDevSession(id string, fn func(dp *DevPortal) error) error {
+	devSessions.Lock()
+	s, ok := devSessions.m[id]
+	devSessions.Unlock()
+	if !ok {
+		return fmt.Errorf("unknown dev portal session %s: %w", id, ErrInvalidArgument)
+	}
+	s.Lock()
+	defer s.Unlock()
+	return fn(s.dp)
+}
+
+// ReleaseDevSession forgets a dev portal session (the stored login session and vault are kept)
+func ReleaseDevSession(id string) {
+	devSessions.Lock()
+	defer devSessions.Unlock()
+	delete(devSessions.m, id)
+}
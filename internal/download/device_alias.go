@@ -0,0 +1,62 @@
+package download
+
+import (
+	"strings"
+	"sync"
+)
+
+// deviceAliases are the stale device identifiers (old identifiers, renamed products and merged models)
+// and the identifiers they resolve to
+var deviceAliases = struct {
+	sync.Mutex
+	m      map[string]string // lowercase alias => identifier
+	lookup func(string) (string, bool)
+	warned map[string]bool
+}{m: make(map[string]string), warned: make(map[string]bool)}
+
+// SetDeviceAliases adds aliases to the device alias table (i.e. `device-aliases: {OldDevice1,1: NewDevice1,1}`)
+func SetDeviceAliases(aliases map[string]string) {
+	deviceAliases.Lock()
+	defer deviceAliases.Unlock()
+	for alias, identifier := range aliases {
+		deviceAliases.m[strings.ToLower(alias)] = identifier
+	}
+}
+
+// SetDeviceLookup sets the fallback used to resolve names that are not in the alias table
+// (i.e. marketing names or board configs); it returns the identifier and true if the name was found
+func SetDeviceLookup(lookup func(name string) (string, bool)) {
+	deviceAliases.Lock()
+	defer deviceAliases.Unlock()
+	deviceAliases.lookup = lookup
+}
+
+// ResolveDevice returns the current identifier of a device (or the identifier itself if it is not stale)
+//
+// Stale identifiers keep working in user scripts: they are resolved with a deprecation warning
+// (logged once per identifier) instead of failing with "device not found".
+func ResolveDevice(identifier string) string {
+	if len(identifier) == 0 {
+		return identifier
+	}
+	deviceAliases.Lock()
+	resolved, ok := deviceAliases.m[strings.ToLower(identifier)]
+	lookup := deviceAliases.lookup
+	deviceAliases.Unlock()
+
+	if !ok && lookup != nil {
+		resolved, ok = lookup(identifier)
+	}
+	if !ok || resolved == identifier {
+		return identifier
+	}
+
+	deviceAliases.Lock()
+	warned := deviceAliases.warned[identifier]
+	deviceAliases.warned[identifier] = true
+	deviceAliases.Unlock()
+	if !warned {
+		getLogger().Warnf("device %s is deprecated, using %s (update your scripts to use %s)", identifier, resolved, resolved)
+	}
+	return resolved
+}
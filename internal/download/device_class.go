@@ -0,0 +1,80 @@
+package download
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// deviceClasses maps a device class (OS) to the product type prefixes of its devices
+var deviceClasses = map[string][]string{
+	"ios":      {"iPhone", "iPad", "iPod"},
+	"watchos":  {"Watch"},
+	"tvos":     {"AppleTV"},
+	"audioos":  {"AudioAccessory"},
+	"bridgeos": {"iBridge"},
+	"macos":    {"Mac", "VirtualMac"},
+	"visionos": {"RealityDevice"},
+}
+
+// deviceClassAliases are the other names a device class can be referred to by
+var deviceClassAliases = map[string]string{
+	"ipados":  "ios",
+	"homepod": "audioos",
+	"t2":      "bridgeos",
+	"ibridge": "bridgeos",
+	"xros":    "visionos",
+}
+
+// DeviceClasses returns the supported device classes
+func DeviceClasses() []string {
+	var classes []string
+	for class := range deviceClasses {
+		classes = append(classes, class)
+	}
+	sort.Strings(classes)
+	return classes
+}
+
+// ParseDeviceClass returns the device class for a class name or alias (i.e. audioOS, HomePod, bridgeOS or T2)
+func ParseDeviceClass(name string) (string, error) {
+	class := strings.ToLower(name)
+	if alias, ok := deviceClassAliases[class]; ok {
+		class = alias
+	}
+	if _, ok := deviceClasses[class]; !ok {
+		return "", fmt.Errorf("unknown device class %#v (must be one of %s)", name, strings.Join(DeviceClasses(), ", "))
+	}
+	return class, nil
+}
+
+// DeviceClassForIdentifier returns the device class of a product type (i.e. AudioAccessory5,1 => audioos)
+func DeviceClassForIdentifier(identifier string) string {
+	for class, prefixes := range deviceClasses {
+		for _, prefix := range prefixes {
+			if strings.HasPrefix(strings.ToLower(identifier), strings.ToLower(prefix)) {
+				return class
+			}
+		}
+	}
+	return ""
+}
+
+// MatchDeviceFilter returns true if the product type matches the filter,
+// which is either a device class (i.e. bridgeOS) or a product type prefix (i.e. iPhone14)
+func MatchDeviceFilter(identifier, filter string) bool {
+	if class, err := ParseDeviceClass(filter); err == nil {
+		return DeviceClassForIdentifier(identifier) == class
+	}
+	return strings.HasPrefix(strings.ToLower(identifier), strings.ToLower(filter))
+}
+
+// InDeviceClasses returns true if any of the filters is a device class the product type belongs to
+func InDeviceClasses(identifier string, filters []string) bool {
+	for _, filter := range filters {
+		if class, err := ParseDeviceClass(filter); err == nil && DeviceClassForIdentifier(identifier) == class {
+			return true
+		}
+	}
+	return false
+}
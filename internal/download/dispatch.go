@@ -0,0 +1,237 @@
+package download
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/blacktop/ipsw/pkg/xcode"
+)
+
+// A Method is a function of the library callable by name with Call: params is the JSON object of its
+// parameters (empty when the caller passes none) and the result is serialized to JSON
+type Method func(ctx context.Context, params json.RawMessage) (any, error)
+
+// methods are the registered Methods by name (i.e. ipsw_me.device)
+var methods = struct {
+	sync.RWMutex
+	m map[string]Method
+}{m: make(map[string]Method)}
+
+// RegisterMethod makes fn callable with Call (and so by every language binding through c_libipsw_call);
+// it panics if name is empty or already registered
+func RegisterMethod(name string, fn Method) {
+	if len(name) == 0 || fn == nil {
+		panic("download: RegisterMethod with an empty name or a nil Method")
+	}
+	methods.Lock()
+	defer methods.Unlock()
+	if _, dup := methods.m[name]; dup {
+		panic(fmt.Sprintf("download: method %s registered twice", name))
+	}
+	methods.m[name] = fn
+}
+
+// Methods returns the sorted names of the registered Methods
+func Methods() []string {
+	methods.RLock()
+	defer methods.RUnlock()
+	names := make([]string, 0, len(methods.m))
+	for name := range methods.m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Call calls the registered Method name with params (a JSON object, empty for none) and returns its JSON result;
+// an unknown method fails with errors.ErrUnsupported and missing params with ErrInvalidArgument
+func Call(ctx context.Context, name string, params []byte) ([]byte, error) {
+	methods.RLock()
+	fn, ok := methods.m[name]
+	methods.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown method %#v: %w", name, errors.ErrUnsupported)
+	}
+	if len(params) == 0 {
+		params = []byte("{}")
+	}
+	res, err := fn(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	out, err := json.Marshal(res)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize %s result: %v", name, err)
+	}
+	return out, nil
+}
+
+// decodeParams decodes the params of method into v
+func decodeParams(method string, params json.RawMessage, v any) error {
+	if err := json.Unmarshal(params, v); err != nil {
+		return fmt.Errorf("failed to parse %s params: %w", method, err)
+	}
+	return nil
+}
+
+// required returns an ErrInvalidArgument error for the first empty param of the name, value pairs
+func required(method string, nameValues ...string) error {
+	for i := 0; i+1 < len(nameValues); i += 2 {
+		if len(nameValues[i+1]) == 0 {
+			return fmt.Errorf("%s: %s is required: %w", method, nameValues[i], ErrInvalidArgument)
+		}
+	}
+	return nil
+}
+
+type identifierParams struct {
+	Identifier string `json:"identifier"` // i.e. iPhone16,1
+}
+
+type wikiParams struct {
+	Device   string `json:"device"`
+	Version  string `json:"version"`
+	Build    string `json:"build"`
+	OTA      bool   `json:"ota"`
+	Beta     bool   `json:"beta"`
+	Proxy    string `json:"proxy"`
+	Insecure bool   `json:"insecure"`
+}
+
+type xcodeParams struct {
+	ProductType string `json:"product_type"` // i.e. iPhone16,1
+	Model       string `json:"model"`        // i.e. A2848
+	Board       string `json:"board"`        // i.e. D83AP
+	Idiom       string `json:"idiom"`        // phone, pad, watch or tv
+	Chip        string `json:"chip"`         // i.e. t8130
+}
+
+// identifierMethod returns a Method taking a device identifier
+func identifierMethod[T any](name string, fn func(context.Context, string) (T, error)) Method {
+	return func(ctx context.Context, params json.RawMessage) (any, error) {
+		var p identifierParams
+		if err := decodeParams(name, params, &p); err != nil {
+			return nil, err
+		}
+		if err := required(name, "identifier", p.Identifier); err != nil {
+			return nil, err
+		}
+		return fn(ctx, p.Identifier)
+	}
+}
+
+func init() {
+	RegisterMethod("libipsw.version", func(context.Context, json.RawMessage) (any, error) {
+		return Version(), nil
+	})
+	RegisterMethod("libipsw.capabilities", func(context.Context, json.RawMessage) (any, error) {
+		return GetCapabilities(), nil
+	})
+	RegisterMethod("libipsw.methods", func(context.Context, json.RawMessage) (any, error) {
+		return Methods(), nil
+	})
+
+	RegisterMethod("ipsw_me.devices", func(ctx context.Context, _ json.RawMessage) (any, error) {
+		return GetAllDevicesWithContext(ctx)
+	})
+	RegisterMethod("ipsw_me.device", identifierMethod("ipsw_me.device", GetDeviceWithContext))
+	RegisterMethod("ipsw_me.device_ipsws", identifierMethod("ipsw_me.device_ipsws", GetDeviceIPSWsWithContext))
+	RegisterMethod("ipsw_me.device_otas", identifierMethod("ipsw_me.device_otas", GetDeviceOTAsWithContext))
+	RegisterMethod("ipsw_me.ipsws", func(ctx context.Context, params json.RawMessage) (any, error) {
+		var p struct {
+			Version string `json:"version"`
+		}
+		if err := decodeParams("ipsw_me.ipsws", params, &p); err != nil {
+			return nil, err
+		}
+		if err := required("ipsw_me.ipsws", "version", p.Version); err != nil {
+			return nil, err
+		}
+		return GetAllIPSWWithContext(ctx, p.Version)
+	})
+	RegisterMethod("ipsw_me.ipsw", func(ctx context.Context, params json.RawMessage) (any, error) {
+		var p struct {
+			Identifier string `json:"identifier"`
+			Build      string `json:"build"`
+		}
+		if err := decodeParams("ipsw_me.ipsw", params, &p); err != nil {
+			return nil, err
+		}
+		if err := required("ipsw_me.ipsw", "identifier", p.Identifier, "build", p.Build); err != nil {
+			return nil, err
+		}
+		return GetIPSWWithContext(ctx, p.Identifier, p.Build)
+	})
+	RegisterMethod("ipsw_me.version", func(ctx context.Context, params json.RawMessage) (any, error) {
+		var p struct {
+			Build string `json:"build"`
+		}
+		if err := decodeParams("ipsw_me.version", params, &p); err != nil {
+			return nil, err
+		}
+		if err := required("ipsw_me.version", "build", p.Build); err != nil {
+			return nil, err
+		}
+		return GetVersionWithContext(ctx, p.Build)
+	})
+	RegisterMethod("ipsw_me.build_id", func(ctx context.Context, params json.RawMessage) (any, error) {
+		var p struct {
+			Version    string `json:"version"`
+			Identifier string `json:"identifier"`
+		}
+		if err := decodeParams("ipsw_me.build_id", params, &p); err != nil {
+			return nil, err
+		}
+		if err := required("ipsw_me.build_id", "version", p.Version, "identifier", p.Identifier); err != nil {
+			return nil, err
+		}
+		return GetBuildIDWithContext(ctx, p.Version, p.Identifier)
+	})
+	RegisterMethod("ipsw_me.signed", identifierMethod("ipsw_me.signed", GetSignedIPSWsWithContext))
+
+	RegisterMethod("theapplewiki.firmwares", func(_ context.Context, params json.RawMessage) (any, error) {
+		var p wikiParams
+		if err := decodeParams("theapplewiki.firmwares", params, &p); err != nil {
+			return nil, err
+		}
+		cfg := &WikiConfig{Device: p.Device, Version: p.Version, Build: p.Build, IPSW: !p.OTA, OTA: p.OTA, Beta: p.Beta}
+		if p.OTA {
+			return GetWikiOTAs(cfg, p.Proxy, p.Insecure)
+		}
+		return GetWikiIPSWs(cfg, p.Proxy, p.Insecure)
+	})
+
+	// the xcode methods query the device traits embedded in the library (no network access)
+	RegisterMethod("xcode.device", func(_ context.Context, params json.RawMessage) (any, error) {
+		var p xcodeParams
+		if err := decodeParams("xcode.device", params, &p); err != nil {
+			return nil, err
+		}
+		switch {
+		case len(p.ProductType) > 0:
+			return xcode.GetDeviceForProd(p.ProductType)
+		case len(p.Model) > 0:
+			return xcode.GetDeviceForModel(p.Model)
+		case len(p.Board) > 0:
+			return xcode.GetDeviceForBoard(p.Board)
+		}
+		return nil, fmt.Errorf("xcode.device: product_type, model or board is required: %w", ErrInvalidArgument)
+	})
+	RegisterMethod("xcode.devices", func(_ context.Context, params json.RawMessage) (any, error) {
+		var p xcodeParams
+		if err := decodeParams("xcode.devices", params, &p); err != nil {
+			return nil, err
+		}
+		switch {
+		case len(p.Idiom) > 0:
+			return xcode.GetDevicesForIdiom(p.Idiom)
+		case len(p.Chip) > 0:
+			return xcode.GetDevicesForChip(p.Chip)
+		}
+		return xcode.GetDevices()
+	})
+}
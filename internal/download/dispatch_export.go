@@ -0,0 +1,40 @@
+//go:build ffi
+
+package download
+
+//#cgo LDFLAGS:
+//#include <stdio.h>
+//#include <stdlib.h>
+//#include <string.h>
+import "C"
+import (
+	"context"
+	"fmt"
+	"unsafe"
+)
+
+// c_libipsw_call calls a library method by name (i.e. ipsw_me.device, see libipsw.methods for the list)
+// with paramsJson, a JSON object of its parameters (empty for none), and returns its JSON result; pass a
+// handle of c_libipsw_handle_new to cancel the call with c_libipsw_cancel (empty for none). An unknown
+// method fails with LIBIPSW_ERR_UNSUPPORTED.
+//
+//export c_libipsw_call
+func c_libipsw_call(handle *C.char, handleLen C.uint, method *C.char, methodLen C.uint, paramsJson *C.char, paramsJsonLen C.uint,
+	outJson **C.char, outJsonLen *C.uint, err **C.char, errLen *C.uint) C.char {
+	name := C.GoStringN(method, C.int(methodLen))
+	ctx := context.Background()
+	if h := C.GoStringN(handle, C.int(handleLen)); len(h) > 0 {
+		var herr error
+		if ctx, herr = HandleContext(h); herr != nil {
+			return setCError(herr, fmt.Sprintf("c_libipsw_call: %v", herr), err, errLen)
+		}
+	}
+	out, cerr := Call(ctx, name, C.GoBytes(unsafe.Pointer(paramsJson), C.int(paramsJsonLen)))
+	if cerr != nil {
+		return setCError(cerr, fmt.Sprintf("c_libipsw_call: %s failed with %v", name, cerr), err, errLen)
+	}
+	cs := C.CString(string(out))
+	*outJson = cs
+	*outJsonLen = C.uint(C.strlen(cs))
+	return C.char(1)
+}
@@ -0,0 +1,75 @@
+//go:build ffi
+
+package download
+
+//#cgo LDFLAGS:
+//#include <stdio.h>
+//#include <stdlib.h>
+//#include <string.h>
+import "C"
+import (
+	"context"
+	"fmt"
+	"path"
+	"path/filepath"
+)
+
+// newCDownload creates a download from the C arguments (dest defaults to the URL's file name in the current directory)
+func newCDownload(url, dest, sha1, sha256, proxy string, insecure bool) (*Download, error) {
+	if len(url) == 0 {
+		return nil, fmt.Errorf("url is required: %w", ErrInvalidArgument)
+	}
+	d := NewDownload(proxy, insecure, false, true, false, false, false)
+	d.URL = url
+	d.Sha1 = sha1
+	d.SHA256 = sha256
+	d.DestName = dest
+	if len(d.DestName) == 0 {
+		d.DestName = path.Base(d.URL)
+	}
+	d.DestName = filepath.Clean(d.DestName)
+	return d, nil
+}
+
+func cDownload(ctx context.Context, d *Download, outJson **C.char, outJsonLen *C.uint, err **C.char, errLen *C.uint, name string) C.char {
+	if skip, serr := d.SkipExisting(); serr != nil {
+		return setCError(serr, fmt.Sprintf("%s: %v", name, serr), err, errLen)
+	} else if !skip {
+		if derr := d.DoWithContext(ctx); derr != nil {
+			return setCError(derr, fmt.Sprintf("%s: Download failed with %v", name, derr), err, errLen)
+		}
+	}
+	return setCJSON(d.Stats(), outJson, outJsonLen, err, errLen, name, "Stats object")
+}
+
+// c_internal_download_Download downloads url to dest (resuming a partial download) and blocks until it is done;
+// the checksums are optional and the download's Stats are returned as JSON
+// (use c_internal_download_manager_Add to download in the background)
+//
+//export c_internal_download_Download
+func c_internal_download_Download(url *C.char, urlLen C.uint, dest *C.char, destLen C.uint, sha1 *C.char, sha1Len C.uint, sha256 *C.char, sha256Len C.uint,
+	proxy *C.char, proxyLen C.uint, insecure C.char, outJson **C.char, outJsonLen *C.uint, err **C.char, errLen *C.uint) C.char {
+	d, derr := newCDownload(C.GoStringN(url, C.int(urlLen)), C.GoStringN(dest, C.int(destLen)), C.GoStringN(sha1, C.int(sha1Len)),
+		C.GoStringN(sha256, C.int(sha256Len)), C.GoStringN(proxy, C.int(proxyLen)), insecure == 1)
+	if derr != nil {
+		return setCError(derr, fmt.Sprintf("c_Download: %v", derr), err, errLen)
+	}
+	return cDownload(context.Background(), d, outJson, outJsonLen, err, errLen, "c_Download")
+}
+
+// c_internal_download_DownloadWithHandle is c_internal_download_Download that can be canceled with c_libipsw_cancel
+//
+//export c_internal_download_DownloadWithHandle
+func c_internal_download_DownloadWithHandle(handle *C.char, handleLen C.uint, url *C.char, urlLen C.uint, dest *C.char, destLen C.uint, sha1 *C.char, sha1Len C.uint,
+	sha256 *C.char, sha256Len C.uint, proxy *C.char, proxyLen C.uint, insecure C.char, outJson **C.char, outJsonLen *C.uint, err **C.char, errLen *C.uint) C.char {
+	ctx, herr := HandleContext(C.GoStringN(handle, C.int(handleLen)))
+	if herr != nil {
+		return setCError(herr, fmt.Sprintf("c_DownloadWithHandle: %v", herr), err, errLen)
+	}
+	d, derr := newCDownload(C.GoStringN(url, C.int(urlLen)), C.GoStringN(dest, C.int(destLen)), C.GoStringN(sha1, C.int(sha1Len)),
+		C.GoStringN(sha256, C.int(sha256Len)), C.GoStringN(proxy, C.int(proxyLen)), insecure == 1)
+	if derr != nil {
+		return setCError(derr, fmt.Sprintf("c_DownloadWithHandle: %v", derr), err, errLen)
+	}
+	return cDownload(ctx, d, outJson, outJsonLen, err, errLen, "c_DownloadWithHandle")
+}
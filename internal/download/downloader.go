@@ -1,19 +1,18 @@
+//go:build !wasm
+
 package download
 
 import (
-	"bytes"
-	"crypto/sha1"
-	"crypto/tls"
-	"encoding/hex"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptrace"
-	"net/url"
 	"os"
+	"path/filepath"
 	"strings"
-	"syscall"
+	"sync"
 	"time"
 
 	// "github.com/gofrs/flock"
@@ -23,7 +22,7 @@ import (
 	"github.com/pkg/errors"
 	"github.com/vbauerster/mpb/v7"
 	"github.com/vbauerster/mpb/v7/decor"
-	"golang.org/x/net/http/httpproxy"
+	"golang.org/x/time/rate"
 )
 
 // Download is a downloader object
@@ -32,8 +31,28 @@ type Download struct {
 	Sha1     string
 	DestName string
 	Headers  map[string]string
+	// MD5 and SHA256 (optional) are verified when there is no stronger checksum (SHA256 > SHA1 > MD5)
+	MD5    string
+	SHA256 string
+	// Refresh (optional) re-authenticates when the download's auth expires mid-transfer
+	// so that it can be resumed from the last byte instead of restarting
+	Refresh func() error
+	// Threads is the number of concurrent ranged connections used for large files (<= 1 downloads sequentially)
+	Threads int
+	// RateLimit is the download's max bandwidth in bytes per second shared by its connections (<= 0 is unlimited)
+	RateLimit int64
+	// OnProgress (optional) is called with the download's progress instead of rendering a progress bar
+	OnProgress ProgressFunc
+	// Size (optional) is the file size from the metadata that an existing file is verified against (see SkipExisting)
+	Size int64
+	// TraceID (optional) is the correlation ID added to the download's errors and stats (one is generated if empty)
+	TraceID string
+	// Logger (optional) receives the download's log lines instead of the package logger (see SetLogger)
+	Logger log.Interface
 
 	size         int64
+	etag         string
+	lastModified string
 	bytesResumed int64
 	resume       bool
 	canResume    bool
@@ -42,8 +61,30 @@ type Download struct {
 	restartAll   bool
 	ignoreSha1   bool
 	verbose      bool
+	traceOwn     bool          // the TraceID was generated (and is only logged in verbose mode)
+	ctxLog       log.Interface // the WithLogger logger of the current run's context
+	refreshes    int
+	refetches    int
+	retries      int
 
 	client *http.Client
+	// stateMu serializes writes of the sidecar state file (chunks save it concurrently)
+	stateMu sync.Mutex
+
+	mu       sync.Mutex
+	state    DownloadState
+	err      error
+	paused   bool
+	pauses   uint64 // number of Pause calls (see interrupted)
+	canceled bool
+	resumeCh chan struct{}
+	cancel   context.CancelFunc
+	stats    Stats
+	limiter  *rate.Limiter
+	reporter progressReporter
+	// shared progress container of the Manager (the bar is rendered at position priority)
+	progress *utils.Progress
+	priority int
 }
 
 type geoQuery struct {
@@ -63,6 +104,9 @@ type geoQuery struct {
 	As          string `json:"as,omitempty"`
 }
 
+// maxAuthRefreshes is the max number of times a download will re-authenticate and resume
+const maxAuthRefreshes = 5
+
 // NewDownload creates a new downloader
 func NewDownload(proxy string, insecure, skipAll, resumeAll, restartAll, ignoreSha1, verbose bool) *Download {
 	return &Download{
@@ -75,47 +119,21 @@ func NewDownload(proxy string, insecure, skipAll, resumeAll, restartAll, ignoreS
 		ignoreSha1: ignoreSha1,
 		verbose:    verbose,
 		client: &http.Client{
-			Transport: &http.Transport{
-				Proxy:           GetProxy(proxy),
-				TLSClientConfig: &tls.Config{InsecureSkipVerify: insecure},
-				// MaxConnsPerHost:   50,
-				ForceAttemptHTTP2: true,
-			},
+			Transport: RetryTransport(NewTransport(proxy, insecure)),
 		},
 	}
 }
 
-// GetProxy takes either an input string or read the enviornment and returns a proxy function
-func GetProxy(proxy string) func(*http.Request) (*url.URL, error) {
-	if len(proxy) > 0 {
-		proxyURL, err := url.Parse(proxy)
-		if err != nil {
-			log.WithError(err).Error("bad proxy url")
-		}
-		log.Debugf("proxy set to: %s", proxyURL)
-
-		return http.ProxyURL(proxyURL)
-	}
-
-	conf := httpproxy.FromEnvironment()
-	if len(conf.HTTPProxy) > 0 || len(conf.HTTPSProxy) > 0 {
-		log.WithFields(log.Fields{
-			"http_proxy":  conf.HTTPProxy,
-			"https_proxy": conf.HTTPSProxy,
-			"no_proxy":    conf.NoProxy,
-		}).Debugf("proxy info from environment")
-	}
-
-	return http.ProxyFromEnvironment
-}
-
-func (d *Download) getHEAD() error {
+func (d *Download) getHEAD(ctx context.Context) error {
 
-	req, err := http.NewRequest("HEAD", d.URL, nil)
+	req, err := http.NewRequestWithContext(ctx, "HEAD", d.URL, nil)
 	if err != nil {
 		return errors.Wrap(err, "cannot create http request")
 	}
 	req.Header.Add("User-Agent", utils.RandomAgent())
+	for k, v := range d.Headers {
+		req.Header.Add(k, v)
+	}
 
 	resp, err := d.client.Do(req)
 	if err != nil {
@@ -127,7 +145,11 @@ func (d *Download) getHEAD() error {
 		return fmt.Errorf("content length is not set")
 	}
 
+	d.mu.Lock() // Status reads the size while the download runs
 	d.size = resp.ContentLength
+	d.mu.Unlock()
+	d.etag = resp.Header.Get("ETag")
+	d.lastModified = resp.Header.Get("Last-Modified")
 
 	if resp.Header.Get("Accept-Ranges") == "bytes" {
 		d.canResume = true
@@ -139,9 +161,105 @@ func (d *Download) getHEAD() error {
 // Do will download a url to a local file. It's efficient because it will
 // write as it downloads and not load the whole file into memory. We pass an io.TeeReader
 // into Copy() to report progress on the download.
+//
+// NOTE: Do blocks while the download is paused and returns ErrCanceled if it is canceled
 func (d *Download) Do() error {
+	return d.DoWithContext(context.Background())
+}
 
-	d.getHEAD()
+// DoWithContext downloads the URL to DestName like Do, but aborts the transfer and returns ctx.Err() once
+// ctx is canceled or its deadline expires (the partial download is kept so that it can be resumed)
+func (d *Download) DoWithContext(ctx context.Context) (err error) {
+	ctx = d.startTrace(ctx)
+	defer func() { err = traced(ctx, err) }()
+	defer d.recordStats()
+	if keybindings.Load() {
+		defer d.watchKeys()()
+	}
+	d.mu.Lock()
+	d.stats.Started = time.Now()
+	d.stats.Verification = nil
+	d.mu.Unlock()
+	d.refetches = 0
+	d.retries = 0
+	if a := getAria2(); a != nil {
+		d.setState(StateRunning)
+		start := time.Now()
+		err := a.Download(ctx, d)
+		d.mu.Lock()
+		d.stats.Active += time.Since(start)
+		d.mu.Unlock()
+		return d.finish(err)
+	}
+	for {
+		if !d.waitResume() {
+			return d.finish(ErrCanceled)
+		}
+		if ctx.Err() != nil {
+			return d.finish(ctx.Err())
+		}
+		d.setState(StateRunning)
+		start := time.Now()
+		err := d.do(ctx)
+		d.mu.Lock()
+		d.stats.Active += time.Since(start)
+		d.mu.Unlock()
+		if ctx.Err() != nil {
+			return d.finish(ctx.Err())
+		}
+		if errors.Is(err, ErrPaused) {
+			// pick up the partial download where it left off (without prompting)
+			d.resumeAll, d.skipAll, d.restartAll = true, false, false
+			continue
+		}
+		if policy := getRetryPolicy(); retryableError(err) && d.retries+1 < policy.MaxAttempts {
+			d.retries++
+			delay := policy.Backoff(d.retries)
+			utils.Indent(d.logger().WithError(err).Warn, 2)(fmt.Sprintf("Download interrupted, resuming in %s (attempt %d/%d)", delay.Round(time.Millisecond), d.retries+1, policy.MaxAttempts))
+			d.mu.Lock()
+			d.stats.Retries++
+			d.mu.Unlock()
+			select {
+			case <-ctx.Done():
+				return d.finish(ctx.Err())
+			case <-time.After(delay):
+			}
+			// pick up the partial download where it left off (without prompting)
+			d.resumeAll, d.skipAll, d.restartAll = true, false, false
+			continue
+		}
+		if d.canRefetch(err) {
+			// the bad partial download was removed so this starts over
+			d.refetches++
+			utils.Indent(d.logger().Warn, 2)(fmt.Sprintf("Re-downloading %s (attempt %d/%d)", filepath.Base(d.DestName), d.refetches+1, getVerifyRetries()+1))
+			d.mu.Lock()
+			d.stats.Retries++
+			d.mu.Unlock()
+			continue
+		}
+		if err == nil {
+			d.reportProgress(true)
+		}
+		return d.finish(err)
+	}
+}
+
+func (d *Download) do(parent context.Context) error {
+	ctx, cancel := context.WithCancel(parent)
+	defer cancel()
+	d.mu.Lock()
+	d.cancel = cancel
+	ctx = context.WithValue(ctx, pausesKey{}, d.pauses)
+	d.mu.Unlock()
+	if err := d.interrupted(ctx); err != nil {
+		return err
+	}
+
+	d.getHEAD(ctx)
+
+	if d.useChunks() {
+		return d.doChunked(ctx)
+	}
 
 	req, err := http.NewRequest("GET", d.URL, nil)
 	if err != nil {
@@ -161,10 +279,19 @@ func (d *Download) Do() error {
 			if d.skipAll {
 				d.resume = false
 				return nil
+			} else if state := d.loadState(); state != nil && !d.restartAll {
+				// the sidecar state file identifies the partial download, so resume it without prompting
+				if state.matches(d) {
+					d.resume = true
+					state.restoreChecksums(d)
+				} else {
+					utils.Indent(d.logger().WithField("file", d.DestName).Warn, 2)("Remote file changed since the partial download was started, restarting")
+					d.resume = false
+				}
 			} else if d.resumeAll {
 				d.resume = true
 			} else if d.restartAll {
-				log.Infof("Downloading %s - RESTARTED", d.DestName+".download")
+				d.logger().Infof("Downloading %s - RESTARTED", d.DestName+".download")
 				d.resume = false
 			} else {
 				choice := ""
@@ -172,20 +299,22 @@ func (d *Download) Do() error {
 					Message: fmt.Sprintf("Previous download of %s can be resumed:", d.DestName),
 					Options: []string{"resume", "skip", "skip all", "restart"},
 				}
-				survey.AskOne(prompt, &choice)
+				if err := utils.AskOne(prompt, &choice); err != nil {
+					return err
+				}
 
 				switch choice {
 				case "resume":
 					d.resume = true
 				case "restart":
-					log.Infof("Downloading %s - RESTARTED", d.DestName+".download")
+					d.logger().Infof("Downloading %s - RESTARTED", d.DestName+".download")
 					d.resume = false
 				case "skip":
-					log.Infof("%s - SKIPPED", d.DestName+".download")
+					d.logger().Infof("%s - SKIPPED", d.DestName+".download")
 					d.resume = false
 					return nil
 				case "skip all":
-					log.Info("Skipping ALL active downloads (you are performing a distributed download)")
+					d.logger().Info("Skipping ALL active downloads (you are performing a distributed download)")
 					d.skipAll = true
 					d.resume = false
 					return nil
@@ -194,9 +323,17 @@ func (d *Download) Do() error {
 
 			if d.resume {
 				d.bytesResumed = f.Size()
+				d.mu.Lock()
+				if d.stats.Bytes == 0 {
+					d.stats.ResumedBytes = d.bytesResumed
+				}
+				d.mu.Unlock()
 				rangeHeader := fmt.Sprintf("bytes=%d-", d.bytesResumed)
-				utils.Indent(log.WithField("range", rangeHeader).Debug, 2)("Setting Header")
+				utils.Indent(d.logger().WithField("range", rangeHeader).Debug, 2)("Setting Header")
 				req.Header.Add("Range", rangeHeader)
+				if v := d.ifRange(); v != "" {
+					req.Header.Add("If-Range", v)
+				}
 			}
 		}
 	}
@@ -208,7 +345,7 @@ func (d *Download) Do() error {
 
 				req, err := http.NewRequest("GET", fmt.Sprintf("http://ip-api.com/json/%s", addr), nil)
 				if err != nil {
-					log.Error("failed to create http GET request")
+					d.logger().Error("failed to create http GET request")
 				}
 				req.Header.Add("User-Agent", utils.RandomAgent())
 
@@ -216,31 +353,42 @@ func (d *Download) Do() error {
 					defer res.Body.Close()
 					data := &geoQuery{}
 					json.NewDecoder(res.Body).Decode(data)
-					utils.Indent(log.Debug, 2)(fmt.Sprintf("URL resolved to: %s (%s - %s, %s. %s)", addr, data.Org, data.City, data.Region, data.Country))
+					utils.Indent(d.logger().Debug, 2)(fmt.Sprintf("URL resolved to: %s (%s - %s, %s. %s)", addr, data.Org, data.City, data.Region, data.Country))
 				} else {
-					log.Errorf("failed to lookup IP's geolocation: %v", err)
+					d.logger().Errorf("failed to lookup IP's geolocation: %v", err)
 				}
 			}
 		},
 	}
-	req = req.WithContext(httptrace.WithClientTrace(req.Context(), trace))
+	req = req.WithContext(httptrace.WithClientTrace(ctx, trace))
 
 	// utils.Indent(log.WithField("file", d.DestName).Debug, 2)("Downloading") TODO: should I remove this?
 	resp, err := d.client.Do(req)
 	if err != nil {
-		if errors.Is(err, syscall.ECONNRESET) {
-			utils.Indent(log.Error, 2)(fmt.Sprintf("CONNECTION RESET: %v", err))
-			utils.Indent(log.Warn, 3)("trying again...")
-			return d.Do()
+		if ierr := d.interrupted(ctx); ierr != nil {
+			return ierr
 		}
-		return fmt.Errorf("failed to download file: %v", err)
+		return fmt.Errorf("failed to download file: %w", err)
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		if d.Refresh != nil && d.refreshes < maxAuthRefreshes {
+			resp.Body.Close()
+			return d.refreshAndResume(ctx, fmt.Errorf("server return status: %s", resp.Status))
+		}
+	}
+
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusPartialContent {
 		return fmt.Errorf("server return status: %s", resp.Status)
 	}
 
+	if d.resume && resp.StatusCode == http.StatusOK {
+		// server ignored the Range header (appending would corrupt the partial download)
+		utils.Indent(d.logger().Warn, 2)("Server does not support resuming this download, restarting...")
+		d.resume = false
+	}
+
 	// Apple likes to return 200 OK even when the file is not found/or is not available
 	if resp.Header.Get("Content-type") == "text/html; charset=UTF-8" {
 		// body, err := io.ReadAll(resp.Body)
@@ -257,7 +405,7 @@ func (d *Download) Do() error {
 		// 	return fmt.Errorf("failed to write response body to %s: %v", f.Name(), err)
 		// }
 		// return fmt.Errorf("server returned a html page")
-		log.Warn("Server returned a HTML page")
+		d.logger().Warn("Server returned a HTML page")
 	}
 
 	// fileLock := flock.New(d.DestName + ".download")
@@ -275,7 +423,7 @@ func (d *Download) Do() error {
 
 	var dest *os.File
 	if d.resume {
-		utils.Indent(log.WithField("file", d.DestName).Warn, 2)("Resuming a previous download")
+		utils.Indent(d.logger().WithField("file", d.DestName).Warn, 2)("Resuming a previous download")
 		dest, err = os.OpenFile(d.DestName+".download", os.O_APPEND|os.O_WRONLY, 0644)
 		if err != nil {
 			return fmt.Errorf("cannot open %s: %v", d.DestName+".download", err)
@@ -288,33 +436,39 @@ func (d *Download) Do() error {
 		}
 	}
 
-	var p *mpb.Progress
+	state := d.newState()
+	if d.resume {
+		state.Offset = d.bytesResumed
+	}
+	sw := &stateWriter{w: dest, d: d, state: state}
+	sw.flush()
+
+	var p *utils.Progress
+	var bar *mpb.Bar
 	var reader io.ReadCloser
 
+	body := d.statsReader(d.throttle(ctx, resp.Body))
+
+	// stop the progress bar and close the partial download when paused/canceled
+	abort := func(err error) error {
+		if bar != nil {
+			bar.Abort(false)
+			d.waitProgress(p)
+		}
+		dest.Close()
+		sw.flush()
+		return err
+	}
+
 	if d.size > 0 {
-		p = mpb.New(
-			mpb.WithWidth(60),
-			mpb.WithRefreshRate(180*time.Millisecond),
-		)
-
-		var bar *mpb.Bar
-
-		bar = p.Add(d.size,
-			mpb.NewBarFiller(mpb.BarStyle().Lbound("[").Filler("=").Tip(">").Padding("-").Rbound("|")),
-			mpb.PrependDecorators(
-				decor.CountersKibiByte("\t% .2f / % .2f"),
-			),
-			mpb.AppendDecorators(
-				decor.OnComplete(decor.AverageETA(decor.ET_STYLE_GO), "✅ "),
-				decor.Name(" ] "),
-				decor.AverageSpeed(decor.UnitKiB, "% .2f"),
-			),
-		)
+		p = d.newProgress()
 
 		if d.resume {
 			bar = p.Add(d.size,
 				mpb.NewBarFiller(mpb.BarStyle().Lbound("[").Filler("=").Tip(">").Padding("-").Rbound("|")),
+				d.barPriority(),
 				mpb.PrependDecorators(
+					decor.Name(d.barName()),
 					decor.CountersKibiByte("\t% .2f / % .2f"),
 				),
 				mpb.AppendDecorators(
@@ -326,72 +480,89 @@ func (d *Download) Do() error {
 			// bar.SetCurrent(d.bytesResumed)
 			bar.SetRefill(d.bytesResumed)
 			bar.IncrInt64(d.bytesResumed)
+		} else {
+			bar = p.Add(d.size,
+				mpb.NewBarFiller(mpb.BarStyle().Lbound("[").Filler("=").Tip(">").Padding("-").Rbound("|")),
+				d.barPriority(),
+				mpb.PrependDecorators(
+					decor.Name(d.barName()),
+					decor.CountersKibiByte("\t% .2f / % .2f"),
+				),
+				mpb.AppendDecorators(
+					decor.OnComplete(decor.AverageETA(decor.ET_STYLE_GO), "✅ "),
+					decor.Name(" ] "),
+					decor.AverageSpeed(decor.UnitKiB, "% .2f"),
+				),
+			)
 		}
 
 		// create proxy reader
-		reader = bar.ProxyReader(resp.Body)
+		reader = bar.ProxyReader(body)
 	} else {
-		reader = resp.Body
+		defer utils.StartHeartbeat(filepath.Base(d.DestName))()
+		reader = body
 	}
 	defer reader.Close()
 
 	if d.resume {
-		if _, err := io.Copy(dest, reader); err != nil {
-			return fmt.Errorf("failed to copy body reader data: %v", err)
+		if _, err := io.Copy(sw, reader); err != nil {
+			if ierr := d.interrupted(ctx); ierr != nil {
+				return abort(ierr)
+			}
+			if d.canRefresh() {
+				return d.refreshAndResume(ctx, abort(err))
+			}
+			sw.flush()
+			return fmt.Errorf("failed to copy body reader data: %w", err)
 		}
 
 		if d.size > 0 {
-			p.Wait()
+			d.waitProgress(p)
 		}
 
 		// close file
 		dest.Sync()
 		dest.Close()
 
-		if len(d.Sha1) > 0 && !d.ignoreSha1 {
-			utils.Indent(log.Info, 2)("verifying sha1sum...")
-			if ok, _ := utils.Verify(d.Sha1, d.DestName+".download"); !ok {
-				// fileLock.Unlock()
-				if err := os.Remove(d.DestName + ".download"); err != nil {
-					return fmt.Errorf("cannot remove downloaded file with checksum mismatch: %v", err)
-				}
-				return fmt.Errorf("bad download: ipsw %s sha1 hash is incorrect", d.DestName+".download")
-			}
+		if err := d.verify(nil); err != nil {
+			return err
 		}
 
 	} else {
-		tee := io.TeeReader(reader, dest)
+		tee := io.TeeReader(reader, sw)
 
-		h := sha1.New()
+		var h io.Writer = io.Discard
+		sum := d.newHash()
+		if sum != nil {
+			h = sum
+		}
 		if _, err := io.Copy(h, tee); err != nil {
+			if ierr := d.interrupted(ctx); ierr != nil {
+				return abort(ierr)
+			}
+			if d.canRefresh() {
+				return d.refreshAndResume(ctx, abort(err))
+			}
+			sw.flush()
 			return err
 		}
 
 		if d.size > 0 {
-			p.Wait()
+			d.waitProgress(p)
 		}
 
 		// close file
 		dest.Sync()
 		dest.Close()
 
-		if len(d.Sha1) > 0 && !d.ignoreSha1 {
-			utils.Indent(log.Info, 2)("verifying sha1sum...")
-			checksum, _ := hex.DecodeString(d.Sha1)
-
-			if !bytes.Equal(h.Sum(nil), checksum) {
-				utils.Indent(log.WithFields(log.Fields{
-					"expected": d.Sha1,
-					"actual":   fmt.Sprintf("%x", h.Sum(nil)),
-				}).Error, 3)("❌ BAD CHECKSUM")
-				// fileLock.Unlock()
-				if err := os.Remove(d.DestName); err != nil {
-					return fmt.Errorf("cannot remove downloaded file with checksum mismatch: %v", err)
-				}
+		if sum != nil {
+			if err := d.verify(sum); err != nil {
+				return err
 			}
 		}
 	}
 
+	d.removeState()
 	if err := os.Rename(d.DestName+".download", d.DestName); err != nil {
 		return fmt.Errorf("failed to rename %s to %s: %v", d.DestName+".download", d.DestName, err)
 	}
@@ -399,6 +570,25 @@ func (d *Download) Do() error {
 	return nil
 }
 
+func (d *Download) canRefresh() bool {
+	return d.Refresh != nil && d.canResume && d.refreshes < maxAuthRefreshes
+}
+
+// refreshAndResume re-authenticates and resumes the partial download from the last byte written
+func (d *Download) refreshAndResume(ctx context.Context, cause error) error {
+	d.refreshes++
+	utils.Indent(d.logger().WithError(cause).Warn, 2)(fmt.Sprintf("Download interrupted, refreshing auth and resuming (attempt %d/%d)", d.refreshes, maxAuthRefreshes))
+	if err := d.Refresh(); err != nil {
+		return fmt.Errorf("failed to refresh download auth: %v (after: %v)", err, cause)
+	}
+	d.mu.Lock()
+	d.stats.Retries++
+	d.mu.Unlock()
+	// pick up the partial download where it left off (without prompting)
+	d.resumeAll, d.skipAll, d.restartAll = true, false, false
+	return d.do(ctx)
+}
+
 // func multiDownload(urls []string, proxy string, insecure bool) {
 // 	var wg sync.WaitGroup
 // 	// pass &wg (optional), so p will wait for it eventually
@@ -473,3 +663,37 @@ func (d *Download) Do() error {
 // 		b.IncrBy(incrBy, time.Since(start))
 // 	}
 // }
+
+// startTrace gives the download the correlation ID of ctx (or its own TraceID, generating one if needed)
+// and returns ctx carrying it
+func (d *Download) startTrace(ctx context.Context) context.Context {
+	if id := TraceID(ctx); len(id) > 0 {
+		d.TraceID, d.traceOwn = id, false
+	} else {
+		if len(d.TraceID) == 0 || d.traceOwn { // a new ID for every run of a reused Download
+			d.TraceID, d.traceOwn = NewTraceID(), true
+		}
+		ctx = WithTraceID(ctx, d.TraceID)
+	}
+	d.ctxLog, _ = ctx.Value(loggerKey{}).(log.Interface)
+	d.mu.Lock()
+	d.stats.Trace = d.TraceID
+	d.mu.Unlock()
+	return ctx
+}
+
+// logger returns a log entry of the download's Logger (or the logger of its context, or the package logger) with its correlation ID
+// (only for caller supplied IDs or in verbose mode to keep the CLI output readable)
+func (d *Download) logger() *log.Entry {
+	l := d.Logger
+	if l == nil {
+		l = d.ctxLog
+	}
+	if l == nil {
+		l = getLogger()
+	}
+	if len(d.TraceID) > 0 && (!d.traceOwn || d.verbose) {
+		return l.WithField("trace", d.TraceID)
+	}
+	return l.WithFields(log.Fields{})
+}
@@ -0,0 +1,129 @@
+package download
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+
+	"github.com/blacktop/ipsw/internal/exitcode"
+)
+
+// ErrorCode is the stable numeric code of an error returned through the C API
+//
+// NOTE: the values never change (new codes are only ever appended) so that bindings can switch on them
+type ErrorCode int
+
+const (
+	ErrCodeOK              ErrorCode = 0  // success
+	ErrCodeUnknown         ErrorCode = 1  // unclassified error
+	ErrCodeInvalidArgument ErrorCode = 2  // missing/invalid argument (i.e. an unknown handle or download ID)
+	ErrCodeNotFound        ErrorCode = 3  // nothing matched the given device, version or build
+	ErrCodeNetwork         ErrorCode = 4  // connection or HTTP error
+	ErrCodeParse           ErrorCode = 5  // malformed JSON input or API response
+	ErrCodeAuth            ErrorCode = 6  // authentication or authorization failed
+	ErrCodeCanceled        ErrorCode = 7  // canceled (c_libipsw_cancel or the download was canceled)
+	ErrCodeTimeout         ErrorCode = 8  // a deadline or timeout expired
+	ErrCodeChecksum        ErrorCode = 9  // checksum verification failed
+	ErrCodeUnsupported     ErrorCode = 10 // not supported on this platform
+)
+
+var errorCodeNames = map[ErrorCode]string{
+	ErrCodeOK:              "ok",
+	ErrCodeUnknown:         "unknown",
+	ErrCodeInvalidArgument: "invalid-argument",
+	ErrCodeNotFound:        "not-found",
+	ErrCodeNetwork:         "network",
+	ErrCodeParse:           "parse",
+	ErrCodeAuth:            "auth",
+	ErrCodeCanceled:        "canceled",
+	ErrCodeTimeout:         "timeout",
+	ErrCodeChecksum:        "checksum",
+	ErrCodeUnsupported:     "unsupported",
+}
+
+func (c ErrorCode) String() string {
+	if name, ok := errorCodeNames[c]; ok {
+		return name
+	}
+	return "unknown"
+}
+
+var (
+	// ErrInvalidArgument is returned (wrapped) for missing or invalid arguments
+	ErrInvalidArgument = errors.New("invalid argument")
+	// ErrCanceled is returned by Do when the download is canceled
+	ErrCanceled = exitcode.Wrap(exitcode.Canceled, errors.New("download canceled"))
+	// ErrBadChecksum is wrapped by the error Do returns when a download's checksum is incorrect
+	ErrBadChecksum = errors.New("bad download")
+)
+
+// HTTPStatusError is the error of an unexpected HTTP response
+type HTTPStatusError struct {
+	Status     string
+	StatusCode int
+}
+
+func (e *HTTPStatusError) Error() string {
+	return "api returned status: " + e.Status
+}
+
+// ErrorCodeOf classifies err
+func ErrorCodeOf(err error) ErrorCode {
+	if err == nil {
+		return ErrCodeOK
+	}
+	switch {
+	case errors.Is(err, ErrCanceled), errors.Is(err, context.Canceled):
+		return ErrCodeCanceled
+	case errors.Is(err, context.DeadlineExceeded):
+		return ErrCodeTimeout
+	case errors.Is(err, ErrBadChecksum):
+		return ErrCodeChecksum
+	case errors.Is(err, ErrInvalidArgument):
+		return ErrCodeInvalidArgument
+	case errors.Is(err, errors.ErrUnsupported):
+		return ErrCodeUnsupported
+	}
+	switch exitcode.Of(err) {
+	case exitcode.NotFound:
+		return ErrCodeNotFound
+	case exitcode.Auth:
+		return ErrCodeAuth
+	case exitcode.Verification:
+		return ErrCodeChecksum
+	case exitcode.Usage:
+		return ErrCodeInvalidArgument
+	case exitcode.Canceled:
+		return ErrCodeCanceled
+	}
+	var statusErr *HTTPStatusError
+	if errors.As(err, &statusErr) {
+		switch statusErr.StatusCode {
+		case http.StatusNotFound:
+			return ErrCodeNotFound
+		case http.StatusUnauthorized, http.StatusForbidden:
+			return ErrCodeAuth
+		}
+		return ErrCodeNetwork
+	}
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &syntaxErr) || errors.As(err, &typeErr) {
+		return ErrCodeParse
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		if netErr.Timeout() {
+			return ErrCodeTimeout
+		}
+		return ErrCodeNetwork
+	}
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return ErrCodeNetwork
+	}
+	return ErrCodeUnknown
+}
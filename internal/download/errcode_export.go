@@ -0,0 +1,73 @@
+//go:build ffi
+
+package download
+
+//#cgo LDFLAGS:
+//#include <stdlib.h>
+//
+// /*
+//  * Error codes
+//  *
+//  * Every failing call (returning 0) sets err to a message, c_libipsw_error_code(err) returns
+//  * the stable numeric code of the failure so that callers do not have to parse the message:
+//  *
+//  *    0  LIBIPSW_OK                no error (err is NULL)
+//  *    1  LIBIPSW_ERR_UNKNOWN       unclassified error
+//  *    2  LIBIPSW_ERR_INVALID_ARG   missing/invalid argument (i.e. an unknown handle or download ID)
+//  *    3  LIBIPSW_ERR_NOT_FOUND     nothing matched the given device, version or build
+//  *    4  LIBIPSW_ERR_NETWORK       connection or HTTP error
+//  *    5  LIBIPSW_ERR_PARSE         malformed JSON input or API response
+//  *    6  LIBIPSW_ERR_AUTH          authentication or authorization failed
+//  *    7  LIBIPSW_ERR_CANCELED      canceled (c_libipsw_cancel or c_internal_download_manager_Cancel)
+//  *    8  LIBIPSW_ERR_TIMEOUT       a deadline or timeout expired
+//  *    9  LIBIPSW_ERR_CHECKSUM      checksum verification failed
+//  *   10  LIBIPSW_ERR_UNSUPPORTED   not supported on this platform
+//  *
+//  * Codes are never renumbered (new ones are only appended). The code must be read before the
+//  * message is freed with c_libipsw_free_string.
+//  */
+//enum {
+//	LIBIPSW_OK = 0,
+//	LIBIPSW_ERR_UNKNOWN = 1,
+//	LIBIPSW_ERR_INVALID_ARG = 2,
+//	LIBIPSW_ERR_NOT_FOUND = 3,
+//	LIBIPSW_ERR_NETWORK = 4,
+//	LIBIPSW_ERR_PARSE = 5,
+//	LIBIPSW_ERR_AUTH = 6,
+//	LIBIPSW_ERR_CANCELED = 7,
+//	LIBIPSW_ERR_TIMEOUT = 8,
+//	LIBIPSW_ERR_CHECKSUM = 9,
+//	LIBIPSW_ERR_UNSUPPORTED = 10,
+//};
+import "C"
+import (
+	"sync"
+	"unsafe"
+)
+
+var cErrorCodes sync.Map // error message pointer -> ErrorCode
+
+// c_libipsw_error_code returns the error code (see the LIBIPSW_ERR_* constants) of an error message set by a libipsw function
+//
+//export c_libipsw_error_code
+func c_libipsw_error_code(err *C.char) C.int {
+	if err == nil {
+		return C.int(ErrCodeOK)
+	}
+	if code, ok := cErrorCodes.Load(uintptr(unsafe.Pointer(err))); ok {
+		return C.int(code.(ErrorCode))
+	}
+	return C.int(ErrCodeUnknown)
+}
+
+// c_libipsw_error_code_name returns the name of an error code (i.e. "not-found"); free it with c_libipsw_free_string
+//
+//export c_libipsw_error_code_name
+func c_libipsw_error_code_name(code C.int) *C.char {
+	return C.CString(ErrorCode(code).String())
+}
+
+// forgetCError drops the error code of a freed error message (the address may be reused by malloc)
+func forgetCError(s *C.char) {
+	cErrorCodes.Delete(uintptr(unsafe.Pointer(s)))
+}
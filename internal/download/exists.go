@@ -0,0 +1,208 @@
+//go:build !wasm
+
+package download
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/blacktop/ipsw/internal/utils"
+)
+
+// IfExists is what is done with a download's output file when it already exists
+type IfExists string
+
+const (
+	// IfExistsSkip keeps the existing file without looking at it
+	IfExistsSkip IfExists = "skip"
+	// IfExistsVerify keeps the existing file if its size and checksum match the metadata (and re-downloads it otherwise)
+	IfExistsVerify IfExists = "verify"
+	// IfExistsOverwrite always re-downloads the file
+	IfExistsOverwrite IfExists = "overwrite"
+)
+
+// ParseIfExists parses a skip|verify|overwrite policy
+func ParseIfExists(s string) (IfExists, error) {
+	switch p := IfExists(strings.ToLower(strings.TrimSpace(s))); p {
+	case IfExistsSkip, IfExistsVerify, IfExistsOverwrite:
+		return p, nil
+	case "":
+		return IfExistsSkip, nil
+	}
+	return "", fmt.Errorf("invalid --if-exists policy %q (must be skip, verify or overwrite)", s)
+}
+
+var (
+	ifExistsMu   sync.Mutex
+	ifExists     = IfExistsSkip
+	archiveRoots []string
+)
+
+// SetIfExists sets what SkipExisting does with output files that already exist
+func SetIfExists(p IfExists) {
+	ifExistsMu.Lock()
+	defer ifExistsMu.Unlock()
+	ifExists = p
+}
+
+func getIfExists() IfExists {
+	ifExistsMu.Lock()
+	defer ifExistsMu.Unlock()
+	return ifExists
+}
+
+// SetArchiveRoots sets the folders (i.e. a previous mirror) that SkipExisting searches for an already downloaded copy
+// of a missing output file; a copy with the expected name, size and checksum is linked (or copied) into place instead
+// of being downloaded again
+func SetArchiveRoots(dirs ...string) {
+	ifExistsMu.Lock()
+	defer ifExistsMu.Unlock()
+	archiveRoots = dirs
+}
+
+func getArchiveRoots() []string {
+	ifExistsMu.Lock()
+	defer ifExistsMu.Unlock()
+	return archiveRoots
+}
+
+// SkipExisting returns true if DestName already exists and should be kept (see SetIfExists)
+//
+// Existing files that are overwritten, or that fail verification, are removed so that they are downloaded again
+// without the skip/resume/restart prompt.
+func (d *Download) SkipExisting() (bool, error) {
+	fi, err := os.Stat(d.DestName)
+	if os.IsNotExist(err) {
+		return d.useArchived()
+	} else if err != nil {
+		return false, fmt.Errorf("failed to stat file %s: %v", d.DestName, err)
+	}
+
+	switch getIfExists() {
+	case IfExistsOverwrite:
+		d.logger().Warnf("Overwriting existing file: %s", d.DestName)
+	case IfExistsVerify:
+		reason, err := d.checkExisting(d.DestName, fi.Size(), d.expectedSize())
+		if err != nil {
+			return false, err
+		}
+		if len(reason) == 0 {
+			d.logger().Infof("Already have valid copy: %s", d.DestName)
+			return true, nil
+		}
+		d.logger().Warnf("Existing file is invalid (%s), re-downloading: %s", reason, d.DestName)
+	default:
+		d.logger().Warnf("File already exists: %s", d.DestName)
+		return true, nil
+	}
+
+	if err := os.Remove(d.DestName); err != nil {
+		return false, fmt.Errorf("failed to remove %s: %v", d.DestName, err)
+	}
+	return false, nil
+}
+
+// expectedSize returns the file size from the metadata (or the remote file's size, 0 if unknown)
+func (d *Download) expectedSize() int64 {
+	if d.Size > 0 {
+		return d.Size
+	}
+	if err := d.getHEAD(context.Background()); err == nil {
+		return d.size
+	}
+	return 0
+}
+
+// useArchived links (or copies) a valid copy of DestName from the archive roots into place
+// and returns true if one was found
+func (d *Download) useArchived() (bool, error) {
+	roots := getArchiveRoots()
+	if len(roots) == 0 {
+		return false, nil
+	}
+	name := filepath.Base(d.DestName)
+	expectedSize := d.expectedSize()
+	if _, _, newHash := d.checksum(); newHash == nil && expectedSize <= 0 {
+		utils.Indent(d.logger().Debug, 2)("no size or checksum to verify archived copies against")
+		return false, nil
+	}
+	for _, root := range roots {
+		var found string
+		if err := filepath.WalkDir(root, func(path string, de fs.DirEntry, err error) error {
+			if err != nil {
+				return nil // skip unreadable folders
+			}
+			if de.IsDir() || de.Name() != name {
+				return nil
+			}
+			fi, err := de.Info()
+			if err != nil {
+				return nil
+			}
+			reason, err := d.checkExisting(path, fi.Size(), expectedSize)
+			if err != nil {
+				return err
+			}
+			if len(reason) > 0 {
+				utils.Indent(d.logger().Debug, 2)(fmt.Sprintf("ignoring archived %s: %s", path, reason))
+				return nil
+			}
+			found = path
+			return fs.SkipAll
+		}); err != nil {
+			return false, fmt.Errorf("failed to search archive root %s: %v", root, err)
+		}
+		if len(found) == 0 {
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(d.DestName), 0750); err != nil {
+			return false, fmt.Errorf("failed to create directory %s: %v", filepath.Dir(d.DestName), err)
+		}
+		if err := os.Link(found, d.DestName); err != nil {
+			if !errors.Is(err, os.ErrExist) {
+				if err := utils.Copy(found, d.DestName); err != nil {
+					return false, fmt.Errorf("failed to copy %s to %s: %v", found, d.DestName, err)
+				}
+			}
+		}
+		d.logger().WithField("from", found).Infof("Cached: %s", d.DestName)
+		return true, nil
+	}
+	return false, nil
+}
+
+// checkExisting compares the size and checksum of the file at path to the metadata
+// and returns why it does not match (empty if it does)
+func (d *Download) checkExisting(path string, size, expectedSize int64) (string, error) {
+	if expectedSize > 0 && size != expectedSize {
+		return fmt.Sprintf("size is %s, expected %s", utils.FormatBytes(uint64(size)), utils.FormatBytes(uint64(expectedSize))), nil
+	}
+
+	alg, expected, newHash := d.checksum()
+	if newHash == nil {
+		utils.Indent(d.logger().Debug, 2)("no checksum to verify the existing file against (size only)")
+		return "", nil
+	}
+	utils.Indent(d.logger().Info, 2)(fmt.Sprintf("verifying existing %ssum...", alg))
+	f, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to open %s: %v", path, err)
+	}
+	defer f.Close()
+	h := newHash()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", fmt.Errorf("failed to hash %s: %v", path, err)
+	}
+	if actual := hex.EncodeToString(h.Sum(nil)); actual != strings.ToLower(expected) {
+		return fmt.Sprintf("%s is %s, expected %s", alg, actual, strings.ToLower(expected)), nil
+	}
+	return "", nil
+}
@@ -0,0 +1,217 @@
+package download
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/blacktop/ipsw/internal/utils"
+)
+
+// Firmware URL sources in the failover chain
+const (
+	SourceIPSWMe  = "ipsw.me"
+	SourceAppleDB = "appledb"
+	SourceITunes  = "itunes"
+	SourceCDN     = "cdn"
+)
+
+// DefaultSources is the default failover chain of firmware URL sources
+var DefaultSources = []string{SourceIPSWMe, SourceAppleDB, SourceITunes, SourceCDN}
+
+// appleCDNHosts are the hosts that serve the same firmware paths
+var appleCDNHosts = []string{"updates.cdn-apple.com", "appldnld.apple.com", "secure-appldnld.apple.com"}
+
+// appleDBOSes are the AppleDB osFiles folders of the device classes
+var appleDBOSes = map[string][]string{
+	"ios":      {"iOS", "iPadOS", "iPodOS"},
+	"watchos":  {"watchOS"},
+	"tvos":     {"tvOS"},
+	"audioos":  {"audioOS"},
+	"bridgeos": {"bridgeOS"},
+	"macos":    {"macOS"},
+	"visionos": {"visionOS"},
+}
+
+// FailoverConfig is the config of a firmware URL failover chain
+type FailoverConfig struct {
+	Sources  []string // the sources tried in order (DefaultSources if empty)
+	Proxy    string
+	Insecure bool
+	APIToken string // GitHub API token (for AppleDB)
+}
+
+// ParseSources validates a failover chain of source names
+func ParseSources(sources []string) ([]string, error) {
+	var chain []string
+	for _, s := range sources {
+		s = strings.ToLower(strings.TrimSpace(s))
+		switch s {
+		case SourceIPSWMe, SourceAppleDB, SourceITunes, SourceCDN:
+			chain = utils.UniqueAppend(chain, s)
+		case "ipswme", "ipsw_me":
+			chain = utils.UniqueAppend(chain, SourceIPSWMe)
+		default:
+			return nil, fmt.Errorf("unknown firmware source %#v (must be one of %s)", s, strings.Join(DefaultSources, ", "))
+		}
+	}
+	return chain, nil
+}
+
+// failover resolves the URLs of a firmware from each source (lazily, in chain order)
+type failover struct {
+	conf   *FailoverConfig
+	client *http.Client
+	tried  map[string]bool
+}
+
+// Failover returns the firmware with the URL of the first source in the chain whose URL is alive
+// (i.e. when ipsw.me returns a dead URL, the same device/build is looked up in AppleDB, the iTunes
+// catalog and finally on the other Apple CDN hosts)
+func Failover(i IPSW, conf *FailoverConfig) (IPSW, error) {
+	if conf == nil {
+		conf = &FailoverConfig{}
+	}
+	sources := conf.Sources
+	if len(sources) == 0 {
+		sources = DefaultSources
+	}
+	f := &failover{
+		conf: conf,
+		client: &http.Client{
+			Transport: RetryTransport(NewTransport(conf.Proxy, conf.Insecure)),
+		},
+		tried: make(map[string]bool),
+	}
+
+	// the URL we were given is tried first
+	if len(i.URL) > 0 {
+		if f.alive(i.URL) {
+			return i, nil
+		}
+		getLogger().Warnf("%s (%s) URL is dead: %s", i.Identifier, i.BuildID, i.URL)
+	}
+
+	for _, source := range sources {
+		candidates, err := f.lookup(source, i)
+		if err != nil {
+			utils.Indent(getLogger().Debug, 2)(fmt.Sprintf("failed to look up %s (%s) in %s: %v", i.Identifier, i.BuildID, source, err))
+			continue
+		}
+		for _, c := range candidates {
+			if len(c.URL) == 0 || f.tried[c.URL] {
+				continue
+			}
+			if f.alive(c.URL) {
+				utils.Indent(getLogger().Info, 2)(fmt.Sprintf("Using %s URL: %s", source, c.URL))
+				i.URL = c.URL
+				if len(i.SHA1) == 0 {
+					i.SHA1 = c.SHA1
+				}
+				return i, nil
+			}
+		}
+	}
+
+	return i, fmt.Errorf("no source has a live URL for %s (%s)", i.Identifier, i.BuildID)
+}
+
+// alive sends a HEAD request to check that the URL is still served
+func (f *failover) alive(u string) bool {
+	f.tried[u] = true
+	req, err := http.NewRequest(http.MethodHead, u, nil)
+	if err != nil {
+		return false
+	}
+	req.Header.Add("User-Agent", utils.RandomAgent())
+	resp, err := f.client.Do(req)
+	if err != nil {
+		getLogger().Debugf("HEAD %s failed: %v", u, err)
+		return false
+	}
+	resp.Body.Close()
+	return resp.StatusCode == http.StatusOK
+}
+
+// lookup returns the firmwares a source has for the device/build
+func (f *failover) lookup(source string, i IPSW) ([]IPSW, error) {
+	switch source {
+	case SourceIPSWMe:
+		d, err := GetDevice(i.Identifier)
+		if err != nil {
+			return nil, err
+		}
+		var ipsws []IPSW
+		for _, fw := range d.Firmwares {
+			if strings.EqualFold(fw.BuildID, i.BuildID) {
+				ipsws = append(ipsws, fw)
+			}
+		}
+		return ipsws, nil
+	case SourceAppleDB:
+		oses, ok := appleDBOSes[DeviceClassForIdentifier(i.Identifier)]
+		if !ok {
+			return nil, fmt.Errorf("unknown device class")
+		}
+		sources, err := AppleDBQuery(&ADBQuery{
+			OSes:     oses,
+			Build:    i.BuildID,
+			Device:   i.Identifier,
+			Proxy:    f.conf.Proxy,
+			Insecure: f.conf.Insecure,
+			APIToken: f.conf.APIToken,
+		})
+		if err != nil {
+			return nil, err
+		}
+		var ipsws []IPSW
+		for _, src := range sources {
+			if src.Type != "ipsw" {
+				continue
+			}
+			for _, link := range src.Links {
+				ipsws = append(ipsws, IPSW{Identifier: i.Identifier, BuildID: i.BuildID, SHA1: src.Hashes.Sha1, URL: link.URL})
+			}
+		}
+		return ipsws, nil
+	case SourceITunes:
+		var vm *ITunesVersionMaster
+		var err error
+		switch DeviceClassForIdentifier(i.Identifier) {
+		case "macos":
+			vm, err = NewMacOsXML()
+		case "bridgeos":
+			vm, err = NewIBridgeXML()
+		default:
+			vm, err = NewiTunesVersionMaster()
+		}
+		if err != nil {
+			return nil, err
+		}
+		return vm.GetIPSWs(i.Identifier, "", i.BuildID), nil
+	case SourceCDN:
+		// the same path is served by the other Apple CDN hosts
+		var tried []string
+		for u := range f.tried {
+			tried = append(tried, u)
+		}
+		sort.Strings(tried)
+		var ipsws []IPSW
+		for _, u := range tried {
+			pu, err := url.Parse(u)
+			if err != nil || !utils.StrSliceHas(appleCDNHosts, pu.Host) {
+				continue
+			}
+			for _, host := range appleCDNHosts {
+				alt := *pu
+				alt.Scheme = "https"
+				alt.Host = host
+				ipsws = append(ipsws, IPSW{Identifier: i.Identifier, BuildID: i.BuildID, URL: alt.String()})
+			}
+		}
+		return ipsws, nil
+	}
+	return nil, fmt.Errorf("unknown source %s", source)
+}
@@ -0,0 +1,126 @@
+//go:build js && wasm
+
+package download
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"syscall/js"
+)
+
+// fetchTransport sends the requests with the fetch API of the JavaScript host (a browser, Node.js 18+ or Deno);
+// the metadata APIs allow CORS requests
+type fetchTransport struct{}
+
+func (fetchTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	fetch := js.Global().Get("fetch")
+	if fetch.Type() != js.TypeFunction {
+		return nil, fmt.Errorf("the fetch API is not available: %w", errors.ErrUnsupported)
+	}
+	if err := req.Context().Err(); err != nil {
+		return nil, err
+	}
+
+	headers := js.Global().Get("Headers").New()
+	for name, values := range req.Header {
+		if http.CanonicalHeaderKey(name) == "User-Agent" {
+			continue // a forbidden header name for fetch
+		}
+		for _, value := range values {
+			headers.Call("append", name, value)
+		}
+	}
+	abort := js.Global().Get("AbortController").New()
+	opts := js.Global().Get("Object").New()
+	opts.Set("method", req.Method)
+	opts.Set("headers", headers)
+	opts.Set("mode", "cors")
+	opts.Set("credentials", "omit")
+	opts.Set("signal", abort.Get("signal"))
+	if req.Body != nil {
+		body, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read request body: %v", err)
+		}
+		if len(body) > 0 {
+			buf := js.Global().Get("Uint8Array").New(len(body))
+			js.CopyBytesToJS(buf, body)
+			opts.Set("body", buf)
+		}
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-req.Context().Done():
+			abort.Call("abort")
+		case <-done:
+		}
+	}()
+
+	resp, err := await(fetch.Invoke(req.URL.String(), opts))
+	if err != nil {
+		if ctxErr := req.Context().Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+		return nil, fmt.Errorf("fetch of %s failed: %v", req.URL.Redacted(), err)
+	}
+	header := make(http.Header)
+	forEach := js.FuncOf(func(_ js.Value, args []js.Value) any {
+		header.Add(args[1].String(), args[0].String())
+		return nil
+	})
+	resp.Get("headers").Call("forEach", forEach)
+	forEach.Release()
+
+	data, err := await(resp.Call("arrayBuffer"))
+	if err != nil {
+		if ctxErr := req.Context().Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+		return nil, fmt.Errorf("failed to read response of %s: %v", req.URL.Redacted(), err)
+	}
+	body := make([]byte, data.Get("byteLength").Int())
+	js.CopyBytesToGo(body, js.Global().Get("Uint8Array").New(data))
+
+	status := resp.Get("status").Int()
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", status, resp.Get("statusText").String()),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+// await waits for a JavaScript promise (the calling goroutine blocks, not the event loop)
+func await(promise js.Value) (js.Value, error) {
+	resolved := make(chan js.Value, 1)
+	rejected := make(chan js.Value, 1)
+	onResolve := js.FuncOf(func(_ js.Value, args []js.Value) any {
+		resolved <- args[0]
+		return nil
+	})
+	defer onResolve.Release()
+	onReject := js.FuncOf(func(_ js.Value, args []js.Value) any {
+		rejected <- args[0]
+		return nil
+	})
+	defer onReject.Release()
+	promise.Call("then", onResolve, onReject)
+	select {
+	case v := <-resolved:
+		return v, nil
+	case err := <-rejected:
+		return js.Undefined(), errors.New(err.Call("toString").String())
+	}
+}
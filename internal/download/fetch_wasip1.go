@@ -0,0 +1,95 @@
+//go:build wasip1
+
+package download
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"unsafe"
+)
+
+// WASI has no sockets, so the requests are sent by the host through two functions it provides in the
+// "libipsw" module:
+//
+//	fetch(req_ptr, req_len, resp_len_ptr u32) -> i32
+//		sends the fetchRequest JSON at req_ptr, keeps the fetchResponse JSON and writes its length to
+//		resp_len_ptr; returns 0 (or a negative number if the request could not be sent)
+//	fetch_read(buf_ptr, buf_len u32) -> i32
+//		copies the kept response into buf_ptr and returns the number of bytes copied
+
+//go:wasmimport libipsw fetch
+func hostFetch(req unsafe.Pointer, reqLen uint32, respLen unsafe.Pointer) int32
+
+//go:wasmimport libipsw fetch_read
+func hostFetchRead(buf unsafe.Pointer, bufLen uint32) int32
+
+// fetchRequest is the JSON of a request passed to the host (bodies are base64 encoded)
+type fetchRequest struct {
+	Method  string      `json:"method"`
+	URL     string      `json:"url"`
+	Headers http.Header `json:"headers,omitempty"`
+	Body    []byte      `json:"body,omitempty"`
+}
+
+// fetchResponse is the JSON of a response returned by the host (Error is set if the request failed)
+type fetchResponse struct {
+	Status  int         `json:"status"`
+	Headers http.Header `json:"headers,omitempty"`
+	Body    []byte      `json:"body,omitempty"`
+	Error   string      `json:"error,omitempty"`
+}
+
+// fetchTransport sends the requests with the host's fetch function (i.e. fetch() of a JavaScript host)
+type fetchTransport struct{}
+
+func (fetchTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := req.Context().Err(); err != nil {
+		return nil, err
+	}
+	freq := fetchRequest{Method: req.Method, URL: req.URL.String(), Headers: req.Header}
+	if req.Body != nil {
+		body, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read request body: %v", err)
+		}
+		freq.Body = body
+	}
+	data, err := json.Marshal(freq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize request: %v", err)
+	}
+
+	var respLen uint32
+	if ret := hostFetch(unsafe.Pointer(unsafe.SliceData(data)), uint32(len(data)), unsafe.Pointer(&respLen)); ret < 0 {
+		return nil, fmt.Errorf("host fetch of %s failed with %d", req.URL.Redacted(), ret)
+	}
+	buf := make([]byte, respLen)
+	if respLen > 0 {
+		buf = buf[:hostFetchRead(unsafe.Pointer(unsafe.SliceData(buf)), respLen)]
+	}
+	var fresp fetchResponse
+	if err := json.Unmarshal(buf, &fresp); err != nil {
+		return nil, fmt.Errorf("failed to parse host fetch response: %w", err)
+	}
+	if len(fresp.Error) > 0 {
+		return nil, fmt.Errorf("host fetch of %s failed: %s", req.URL.Redacted(), fresp.Error)
+	}
+	if fresp.Headers == nil {
+		fresp.Headers = make(http.Header)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", fresp.Status, http.StatusText(fresp.Status)),
+		StatusCode:    fresp.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        fresp.Headers,
+		Body:          io.NopCloser(bytes.NewReader(fresp.Body)),
+		ContentLength: int64(len(fresp.Body)),
+		Request:       req,
+	}, nil
+}
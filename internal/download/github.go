@@ -2,7 +2,6 @@ package download
 
 import (
 	"context"
-	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -141,10 +140,7 @@ func GetLatestTag(prod, proxy string, insecure bool, api string) (string, error)
 	}
 
 	client := &http.Client{
-		Transport: &http.Transport{
-			Proxy:           GetProxy(proxy),
-			TLSClientConfig: &tls.Config{InsecureSkipVerify: insecure},
-		},
+		Transport: BudgetTransport(NewTransport(proxy, insecure)),
 	}
 
 	resp, err := client.Do(req)
@@ -181,10 +177,7 @@ func queryAppleGithubRepo(prod, proxy string, insecure bool, api string) (*githu
 	}
 
 	client := &http.Client{
-		Transport: &http.Transport{
-			Proxy:           GetProxy(proxy),
-			TLSClientConfig: &tls.Config{InsecureSkipVerify: insecure},
-		},
+		Transport: BudgetTransport(NewTransport(proxy, insecure)),
 	}
 
 	resp, err := client.Do(req)
@@ -224,10 +217,7 @@ func queryAppleGithubRepos(proxy string, insecure bool, api string) (GithubRepos
 	}
 
 	client := &http.Client{
-		Transport: &http.Transport{
-			Proxy:           GetProxy(proxy),
-			TLSClientConfig: &tls.Config{InsecureSkipVerify: insecure},
-		},
+		Transport: BudgetTransport(NewTransport(proxy, insecure)),
 	}
 
 	resp, err = client.Do(req)
@@ -372,10 +362,7 @@ func queryAppleGithubTags(url, proxy string, insecure bool, api string) (GithubT
 	}
 
 	client := &http.Client{
-		Transport: &http.Transport{
-			Proxy:           GetProxy(proxy),
-			TLSClientConfig: &tls.Config{InsecureSkipVerify: insecure},
-		},
+		Transport: BudgetTransport(NewTransport(proxy, insecure)),
 	}
 
 	resp, err := client.Do(req)
@@ -415,10 +402,7 @@ func GetGithubIPSWReleases(proxy string, insecure bool, api string) (GithubRelea
 	}
 
 	client := &http.Client{
-		Transport: &http.Transport{
-			Proxy:           GetProxy(proxy),
-			TLSClientConfig: &tls.Config{InsecureSkipVerify: insecure},
-		},
+		Transport: BudgetTransport(NewTransport(proxy, insecure)),
 	}
 
 	resp, err := client.Do(req)
@@ -454,10 +438,7 @@ func GetPreprocessedAppleOssTags(proxy string, insecure bool) (map[string]Github
 	req.Header.Set("Accept", "application/json")
 
 	client := &http.Client{
-		Transport: &http.Transport{
-			Proxy:           GetProxy(proxy),
-			TLSClientConfig: &tls.Config{InsecureSkipVerify: insecure},
-		},
+		Transport: BudgetTransport(NewTransport(proxy, insecure)),
 	}
 
 	resp, err := client.Do(req)
@@ -492,10 +473,7 @@ func GetPreprocessedWebKitTags(proxy string, insecure bool) ([]GithubTag, error)
 	req.Header.Set("Accept", "application/json")
 
 	client := &http.Client{
-		Transport: &http.Transport{
-			Proxy:           GetProxy(proxy),
-			TLSClientConfig: &tls.Config{InsecureSkipVerify: insecure},
-		},
+		Transport: BudgetTransport(NewTransport(proxy, insecure)),
 	}
 
 	resp, err := client.Do(req)
@@ -0,0 +1,78 @@
+//go:build ffi
+
+package download
+
+//#cgo LDFLAGS:
+//#include <stdio.h>
+//#include <stdlib.h>
+//#include <string.h>
+import "C"
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// c_libipsw_handle_new returns a handle to pass to the *WithHandle functions so that
+// the operation can be canceled from another thread with c_libipsw_cancel
+//
+//export c_libipsw_handle_new
+func c_libipsw_handle_new(outHandle **C.char, outHandleLen *C.uint) C.char {
+	h, _ := NewHandle()
+	cs := C.CString(h)
+	*outHandle = cs
+	*outHandleLen = C.uint(C.strlen(cs))
+	return C.char(1)
+}
+
+// c_libipsw_cancel cancels the in-flight operation of a handle (or the download of a download manager ID);
+// the canceled call returns an error once its requests are aborted
+//
+//export c_libipsw_cancel
+func c_libipsw_cancel(handle *C.char, handleLen C.uint, err **C.char, errLen *C.uint) C.char {
+	if cerr := CancelHandle(C.GoStringN(handle, C.int(handleLen))); cerr != nil {
+		return setCError(cerr, fmt.Sprintf("c_libipsw_cancel: Cancel failed with %v", cerr), err, errLen)
+	}
+	return C.char(1)
+}
+
+// c_libipsw_handle_trace returns the correlation ID of a handle's operation (it is included in the
+// operation's log lines and error messages)
+//
+//export c_libipsw_handle_trace
+func c_libipsw_handle_trace(handle *C.char, handleLen C.uint, outTrace **C.char, outTraceLen *C.uint, err **C.char, errLen *C.uint) C.char {
+	ctx, herr := HandleContext(C.GoStringN(handle, C.int(handleLen)))
+	if herr != nil {
+		return setCError(herr, fmt.Sprintf("c_libipsw_handle_trace: %v", herr), err, errLen)
+	}
+	cs := C.CString(TraceID(ctx))
+	*outTrace = cs
+	*outTraceLen = C.uint(C.strlen(cs))
+	return C.char(1)
+}
+
+// c_libipsw_handle_free releases a handle once its operation has returned
+//
+//export c_libipsw_handle_free
+func c_libipsw_handle_free(handle *C.char, handleLen C.uint) {
+	ReleaseHandle(C.GoStringN(handle, C.int(handleLen)))
+}
+
+//export c_internal_download_ipsw_me_GetDeviceWithHandle
+func c_internal_download_ipsw_me_GetDeviceWithHandle(handle *C.char, handleLen C.uint, identifier *C.char, identifierLen C.uint, outJson **C.char, outJsonLen *C.uint, err **C.char, errLen *C.uint) C.char {
+	ctx, herr := HandleContext(C.GoStringN(handle, C.int(handleLen)))
+	if herr != nil {
+		return setCError(herr, fmt.Sprintf("c_GetDeviceWithHandle: %v", herr), err, errLen)
+	}
+	device, deviceError := GetDeviceWithContext(ctx, C.GoStringN(identifier, C.int(identifierLen)))
+	if deviceError != nil {
+		return setCError(deviceError, fmt.Sprintf("c_GetDeviceWithHandle: GetDevice failed with %v", deviceError), err, errLen)
+	}
+	fret, jsonErr := json.Marshal(device)
+	if jsonErr != nil {
+		return setCError(jsonErr, fmt.Sprintf("c_GetDeviceWithHandle: Failed to serialize Device object: %v", jsonErr), err, errLen)
+	}
+	cs := C.CString(string(fret))
+	*outJson = cs
+	*outJsonLen = C.uint(C.strlen(cs))
+	return C.char(1)
+}
@@ -0,0 +1,89 @@
+package download
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+)
+
+type handleOp struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	client *http.Client
+}
+
+type clientKey struct{}
+
+// withClient returns a copy of ctx that carries the HTTP client of an operation
+func withClient(ctx context.Context, c *http.Client) context.Context {
+	return context.WithValue(ctx, clientKey{}, c)
+}
+
+// clientFrom returns the HTTP client carried by ctx (or fallback if none)
+func clientFrom(ctx context.Context, fallback *http.Client) *http.Client {
+	if c, ok := ctx.Value(clientKey{}).(*http.Client); ok {
+		return c
+	}
+	return fallback
+}
+
+// close cancels the operation and closes the idle connections of its HTTP client
+func (op handleOp) close() {
+	op.cancel()
+	op.client.CloseIdleConnections()
+}
+
+// handles are the in-flight operations started through the handle-based C API
+var handles = struct {
+	sync.Mutex
+	next uint64
+	m    map[string]handleOp
+}{m: make(map[string]handleOp)}
+
+// NewHandle returns a new operation handle and the context that CancelHandle cancels
+// (the context carries a new correlation ID, see WithTraceID, and the handle's own HTTP client
+// so that operations on different handles share no connection state)
+func NewHandle() (string, context.Context) {
+	client := &http.Client{Transport: CacheTransport(BudgetTransport(nil))}
+	ctx, cancel := context.WithCancel(withClient(WithTraceID(context.Background(), NewTraceID()), client))
+	handles.Lock()
+	defer handles.Unlock()
+	handles.next++
+	h := fmt.Sprintf("op-%d", handles.next)
+	handles.m[h] = handleOp{ctx: ctx, cancel: cancel, client: client}
+	return h, ctx
+}
+
+// HandleContext returns the context of an operation handle
+func HandleContext(h string) (context.Context, error) {
+	handles.Lock()
+	defer handles.Unlock()
+	op, ok := handles.m[h]
+	if !ok {
+		return nil, fmt.Errorf("unknown handle %s: %w", h, ErrInvalidArgument)
+	}
+	return op.ctx, nil
+}
+
+// CancelHandle cancels the operation started with a handle (the ID of a Manager download cancels the download)
+func CancelHandle(h string) error {
+	handles.Lock()
+	op, ok := handles.m[h]
+	handles.Unlock()
+	if ok {
+		op.cancel()
+		return nil
+	}
+	return cancelManagerDownload(h)
+}
+
+// ReleaseHandle cancels (if still running) and forgets an operation handle
+func ReleaseHandle(h string) {
+	handles.Lock()
+	defer handles.Unlock()
+	if op, ok := handles.m[h]; ok {
+		op.close()
+		delete(handles.m, h)
+	}
+}
@@ -0,0 +1,196 @@
+package download
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// maxCachedBody is the largest response body kept in the HTTP cache
+const maxCachedBody = 64 * 1024 * 1024
+
+var httpCache = struct {
+	sync.Mutex
+	dir string
+}{}
+
+func init() {
+	if home, err := os.UserHomeDir(); err == nil {
+		httpCache.dir = filepath.Join(home, ".ipsw", "http-cache")
+	}
+}
+
+// SetHTTPCacheDir sets the folder the metadata API responses are cached in (an empty dir disables the cache)
+func SetHTTPCacheDir(dir string) {
+	httpCache.Lock()
+	defer httpCache.Unlock()
+	httpCache.dir = dir
+}
+
+func getHTTPCacheDir() string {
+	httpCache.Lock()
+	defer httpCache.Unlock()
+	return httpCache.dir
+}
+
+// cachedResponse is a response saved with its validators (ETag/Last-Modified)
+type cachedResponse struct {
+	URL          string      `json:"url"`
+	ETag         string      `json:"etag,omitempty"`
+	LastModified string      `json:"last_modified,omitempty"`
+	Header       http.Header `json:"header,omitempty"`
+	Body         []byte      `json:"body"`
+	Saved        time.Time   `json:"saved"`
+}
+
+func cachePath(dir, url string) string {
+	sum := sha256.Sum256([]byte(url))
+	return filepath.Join(dir, hex.EncodeToString(sum[:])+".json")
+}
+
+func loadCached(path, url string) *cachedResponse {
+	dat, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var cr cachedResponse
+	if err := json.Unmarshal(dat, &cr); err != nil {
+		getLogger().Debugf("failed to parse HTTP cache %s: %v", path, err)
+		return nil
+	}
+	if cr.URL != url {
+		return nil
+	}
+	return &cr
+}
+
+func (cr *cachedResponse) save(path string) {
+	dat, err := json.Marshal(cr)
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		getLogger().Debugf("failed to create directory %s: %v", filepath.Dir(path), err)
+		return
+	}
+	// write then rename so concurrent invocations never read a partial entry
+	tmp := path + ".tmp" + strconv.Itoa(os.Getpid())
+	if err := os.WriteFile(tmp, dat, 0644); err != nil {
+		getLogger().Debugf("failed to save HTTP cache %s: %v", path, err)
+		return
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+	}
+}
+
+// response rebuilds the cached response for req
+func (cr *cachedResponse) response(req *http.Request) *http.Response {
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        cr.Header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(cr.Body)),
+		ContentLength: int64(len(cr.Body)),
+		Request:       req,
+	}
+}
+
+// cacheable returns true if the request is a plain GET (no ranges, credentials or caller validators)
+func cacheable(req *http.Request) bool {
+	return req.Method == http.MethodGet &&
+		req.Header.Get("Range") == "" &&
+		req.Header.Get("Authorization") == "" &&
+		req.Header.Get("If-None-Match") == "" &&
+		req.Header.Get("If-Modified-Since") == ""
+}
+
+type cacheTransport struct {
+	base http.RoundTripper
+}
+
+// RoundTrip sends the request with the validators of its cached response (if any) and
+// answers a 304 Not Modified with the cached body
+func (ct *cacheTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	dir := getHTTPCacheDir()
+	if len(dir) == 0 || !cacheable(req) {
+		return ct.base.RoundTrip(req)
+	}
+	url := req.URL.String()
+	path := cachePath(dir, url)
+
+	cached := loadCached(path, url)
+	if cached != nil {
+		req = req.Clone(req.Context())
+		if len(cached.ETag) > 0 {
+			req.Header.Set("If-None-Match", cached.ETag)
+		}
+		if len(cached.LastModified) > 0 {
+			req.Header.Set("If-Modified-Since", cached.LastModified)
+		}
+	}
+
+	resp, err := ct.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusNotModified && cached != nil {
+		resp.Body.Close()
+		getLogger().Debugf("Using cached response for %s (not modified since %s)", url, cached.Saved.Format(time.RFC3339))
+		return cached.response(req), nil
+	}
+
+	etag, lastModified := resp.Header.Get("ETag"), resp.Header.Get("Last-Modified")
+	if resp.StatusCode != http.StatusOK || (len(etag) == 0 && len(lastModified) == 0) ||
+		resp.ContentLength > maxCachedBody || resp.Header.Get("Cache-Control") == "no-store" {
+		return resp, nil
+	}
+
+	orig := resp.Body
+	body, err := io.ReadAll(io.LimitReader(orig, maxCachedBody+1))
+	if err != nil {
+		orig.Close()
+		return nil, err
+	}
+	if len(body) > maxCachedBody { // too big to cache (pass it through untouched)
+		resp.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(body), orig), orig}
+		return resp, nil
+	}
+	orig.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	(&cachedResponse{
+		URL:          url,
+		ETag:         etag,
+		LastModified: lastModified,
+		Header:       resp.Header.Clone(),
+		Body:         body,
+		Saved:        time.Now(),
+	}).save(path)
+
+	return resp, nil
+}
+
+// CacheTransport wraps a transport so that GET responses with an ETag or Last-Modified header are cached
+// on disk and re-validated with conditional requests (If-None-Match/If-Modified-Since) (see SetHTTPCacheDir)
+func CacheTransport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = sharedTransport
+	}
+	return &cacheTransport{base: base}
+}
@@ -1,17 +1,10 @@
 package download
 
-//#cgo LDFLAGS:
-//#include <stdio.h>
-//#include <stdlib.h>
-//#include <string.h>
-import "C"
 import (
 	"bufio"
 	"container/list"
-	"crypto/tls"
 	"encoding/json"
 	"fmt"
-	"github.com/apex/log"
 	"github.com/blacktop/ipsw/internal/sm"
 	"github.com/blacktop/ipsw/internal/utils"
 	"github.com/blacktop/ipsw/pkg/info"
@@ -35,6 +28,7 @@ const (
 	otaBetaPage      = "Beta OTA Updates"
 	appleTV          = "Apple TV"
 	appleWatch       = "Apple Watch"
+	appleVision      = "Apple Vision Pro"
 	homePod          = "HomePod"
 	macOS            = "Mac"
 	macServer        = "Mac Server"
@@ -209,10 +203,7 @@ type wikiFWKeys struct {
 
 func getWikiPage(page string, proxy string, insecure bool) (*wikiParseResults, error) {
 	client := &http.Client{
-		Transport: &http.Transport{
-			Proxy:           GetProxy(proxy),
-			TLSClientConfig: &tls.Config{InsecureSkipVerify: insecure},
-		},
+		Transport: BudgetTransport(NewTransport(proxy, insecure)),
 	}
 
 	req, err := http.NewRequest("GET", iphoneWikiApiURL, nil)
@@ -253,10 +244,7 @@ func getWikiPage(page string, proxy string, insecure bool) (*wikiParseResults, e
 
 func getWikiTable(page string, proxy string, insecure bool) (*wikiParseResults, error) {
 	client := &http.Client{
-		Transport: &http.Transport{
-			Proxy:           GetProxy(proxy),
-			TLSClientConfig: &tls.Config{InsecureSkipVerify: insecure},
-		},
+		Transport: BudgetTransport(NewTransport(proxy, insecure)),
 	}
 
 	req, err := http.NewRequest("GET", iphoneWikiApiURL, nil)
@@ -371,7 +359,7 @@ func parseWikiTable(text string) ([]WikiFirmware, error) {
 
 	db, err := info.GetIpswDB()
 	if err != nil {
-		log.Fatalf("failed to get ipsw db: %v", err)
+		getLogger().Fatalf("failed to get ipsw db: %v", err)
 	}
 
 	machine := sm.Machine{
@@ -743,12 +731,12 @@ func CreateWikiFilter(cfg *WikiConfig) string {
 
 	db, err := info.GetIpswDB()
 	if err != nil {
-		log.Fatalf("failed to get ipsw db: %v", err)
+		getLogger().Fatalf("failed to get ipsw db: %v", err)
 	}
 
 	dev, err := db.LookupDevice(cfg.Device)
 	if err != nil {
-		log.Fatalf("failed to lookup device '%s': %v", cfg.Device, err)
+		getLogger().Fatalf("failed to lookup device '%s': %v", cfg.Device, err)
 	}
 
 	switch {
@@ -756,13 +744,25 @@ func CreateWikiFilter(cfg *WikiConfig) string {
 		device = iphone
 	case strings.HasPrefix(dev.Name, "iPad"):
 		device = ipad
+	case dev.Type == "audioos":
+		device = homePod
+	case dev.Type == "bridgeos":
+		device = ibridge
+	case dev.Type == "tvos":
+		device = appleTV
+	case dev.Type == "watchos":
+		device = appleWatch
+	case dev.Type == "visionos":
+		device = appleVision
+	case dev.Type == "macos":
+		device = macOS
 	}
 
 	if len(cfg.Version) > 0 {
 		if cfg.IPSW {
 			ver, err := semver.NewVersion(cfg.Version)
 			if err != nil {
-				log.Fatalf("failed to convert version '%s' into semver object", cfg.Version)
+				getLogger().Fatalf("failed to convert version '%s' into semver object", cfg.Version)
 			}
 			major = fmt.Sprintf("%s.x", strconv.Itoa(ver.Segments()[0]))
 		} else {
@@ -777,37 +777,6 @@ func CreateWikiFilter(cfg *WikiConfig) string {
 	return fmt.Sprintf("%s/%s", page, device)
 }
 
-//export c_internal_download_iphonewiki_GetWikiIPSWs
-func c_internal_download_iphonewiki_GetWikiIPSWs(configJson *C.char, configJsonLen C.int, proxy *C.char, proxyLen C.int, insecure C.char,
-	outputJson **C.char, outputJsonLen *C.int, err **C.char, errLen *C.uint) C.char {
-	var wikiConfig WikiConfig
-	jsonErr := json.Unmarshal([]byte(C.GoStringN(configJson, configJsonLen)), &wikiConfig)
-	if jsonErr != nil {
-		outError := fmt.Sprintf("c_getWikiIPSWs: Deser failed with %w", jsonErr)
-		*err = C.CString(outError)
-		*errLen = C.uint(len(outError))
-		return C.char(0)
-	}
-	fw, wfwErr := GetWikiIPSWs(&wikiConfig, C.GoStringN(proxy, proxyLen), bool(insecure == 1))
-	if wfwErr != nil {
-		outError := fmt.Sprintf("c_getWikiIPSWs: GetWikiIPSWs failed with %w", wfwErr)
-		*err = C.CString(outError)
-		*errLen = C.uint(len(outError))
-		return C.char(0)
-	}
-	fret, jsonErr := json.Marshal(fw)
-	if jsonErr != nil {
-		outError := fmt.Sprintf("c_getWikiIPSWs: failed to create request: %w", jsonErr)
-		*err = C.CString(outError)
-		*errLen = C.uint(len(outError))
-		return C.char(0)
-	}
-	cs := C.CString(string(fret))
-	*outputJson = cs
-	*outputJsonLen = C.int(C.strlen(cs))
-	return C.char(1)
-}
-
 // GetWikiIPSWs queries theiphonewiki.com for IPSWs
 func GetWikiIPSWs(cfg *WikiConfig, proxy string, insecure bool) ([]WikiFirmware, error) {
 	var ipsws []WikiFirmware
@@ -815,10 +784,7 @@ func GetWikiIPSWs(cfg *WikiConfig, proxy string, insecure bool) ([]WikiFirmware,
 	filter := CreateWikiFilter(cfg)
 
 	client := &http.Client{
-		Transport: &http.Transport{
-			Proxy:           GetProxy(proxy),
-			TLSClientConfig: &tls.Config{InsecureSkipVerify: insecure},
-		},
+		Transport: BudgetTransport(NewTransport(proxy, insecure)),
 	}
 
 	req, err := http.NewRequest("GET", iphoneWikiApiURL, nil)
@@ -862,7 +828,7 @@ func GetWikiIPSWs(cfg *WikiConfig, proxy string, insecure bool) ([]WikiFirmware,
 				continue
 			}
 
-			log.Debugf("Parsing wiki page: '%s'", link.Link)
+			getLogger().Debugf("Parsing wiki page: '%s'", link.Link)
 
 			wpage, err := getWikiPage(link.Link, proxy, insecure)
 			if err != nil {
@@ -895,10 +861,7 @@ func GetWikiOTAs(cfg *WikiConfig, proxy string, insecure bool) ([]WikiFirmware,
 	filter := CreateWikiFilter(cfg)
 
 	client := &http.Client{
-		Transport: &http.Transport{
-			Proxy:           GetProxy(proxy),
-			TLSClientConfig: &tls.Config{InsecureSkipVerify: insecure},
-		},
+		Transport: BudgetTransport(NewTransport(proxy, insecure)),
 	}
 
 	req, err := http.NewRequest("GET", iphoneWikiApiURL, nil)
@@ -942,7 +905,7 @@ func GetWikiOTAs(cfg *WikiConfig, proxy string, insecure bool) ([]WikiFirmware,
 	for _, link := range parseResp.Parse.Links {
 		if strings.HasPrefix(link.Link, filter) {
 
-			log.Debugf("Parsing wiki page: '%s'", link.Link)
+			getLogger().Debugf("Parsing wiki page: '%s'", link.Link)
 
 			if strings.HasSuffix(link.Link, "iPod") { // skip weird info page
 				continue
@@ -976,10 +939,7 @@ func GetWikiFirmwareKeys(proxy string, insecure bool) ([]WikiFirmware, error) {
 	var otas []WikiFirmware
 
 	client := &http.Client{
-		Transport: &http.Transport{
-			Proxy:           GetProxy(proxy),
-			TLSClientConfig: &tls.Config{InsecureSkipVerify: insecure},
-		},
+		Transport: BudgetTransport(NewTransport(proxy, insecure)),
 	}
 
 	req, err := http.NewRequest("GET", iphoneWikiApiURL, nil)
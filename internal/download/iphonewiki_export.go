@@ -0,0 +1,35 @@
+//go:build ffi
+
+package download
+
+//#cgo LDFLAGS:
+//#include <stdio.h>
+//#include <stdlib.h>
+//#include <string.h>
+import "C"
+import (
+	"encoding/json"
+	"fmt"
+)
+
+//export c_internal_download_iphonewiki_GetWikiIPSWs
+func c_internal_download_iphonewiki_GetWikiIPSWs(configJson *C.char, configJsonLen C.int, proxy *C.char, proxyLen C.int, insecure C.char,
+	outputJson **C.char, outputJsonLen *C.int, err **C.char, errLen *C.uint) C.char {
+	var wikiConfig WikiConfig
+	jsonErr := json.Unmarshal([]byte(C.GoStringN(configJson, configJsonLen)), &wikiConfig)
+	if jsonErr != nil {
+		return setCError(jsonErr, fmt.Sprintf("c_getWikiIPSWs: Deser failed with %v", jsonErr), err, errLen)
+	}
+	fw, wfwErr := GetWikiIPSWs(&wikiConfig, C.GoStringN(proxy, proxyLen), bool(insecure == 1))
+	if wfwErr != nil {
+		return setCError(wfwErr, fmt.Sprintf("c_getWikiIPSWs: GetWikiIPSWs failed with %v", wfwErr), err, errLen)
+	}
+	fret, jsonErr := json.Marshal(fw)
+	if jsonErr != nil {
+		return setCError(jsonErr, fmt.Sprintf("c_getWikiIPSWs: failed to create request: %v", jsonErr), err, errLen)
+	}
+	cs := C.CString(string(fret))
+	*outputJson = cs
+	*outputJsonLen = C.int(C.strlen(cs))
+	return C.char(1)
+}
@@ -1,7 +1,6 @@
 package download
 
 import (
-	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -25,10 +24,7 @@ func GetIpswDB(proxy string, insecure bool) (*info.Devices, error) {
 	req.Header.Add("User-Agent", utils.RandomAgent())
 
 	client := &http.Client{
-		Transport: &http.Transport{
-			Proxy:           GetProxy(proxy),
-			TLSClientConfig: &tls.Config{InsecureSkipVerify: insecure},
-		},
+		Transport: RetryTransport(NewTransport(proxy, insecure)),
 	}
 
 	res, err := client.Do(req)
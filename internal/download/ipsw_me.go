@@ -1,20 +1,20 @@
 package download
 
-//#cgo LDFLAGS:
-//#include <stdio.h>
-//#include <stdlib.h>
-//#include <string.h>
-import "C"
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"time"
+
+	"github.com/blacktop/ipsw/internal/exitcode"
 )
 
 const ipswMeAPI = "https://api.ipsw.me/v4/"
 
+var ipswMeClient = &http.Client{Transport: CacheTransport(BudgetTransport(nil))}
+
 // Device struct
 type Device struct {
 	Name        string `json:"name,omitempty"`
@@ -40,226 +40,213 @@ type IPSW struct {
 	Signed      bool      `json:"signed,omitempty"`
 }
 
-// GetAllDevices returns a list of all devices
-func GetAllDevices() ([]Device, error) {
-	devices := []Device{}
+// getIpswMe GETs an ipsw.me API path and decodes its JSON response into v
+//
+// The lookup gets a correlation ID (see WithTraceID) that is added to its log line and error
+func getIpswMe(ctx context.Context, path string, v any) (err error) {
+	ctx = ensureTraceID(ctx)
+	defer func() { err = traced(ctx, err) }()
+	ctxLogger(ctx).Debugf("GET %s%s", ipswMeAPI, path)
 
-	res, err := http.Get(ipswMeAPI + "devices")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ipswMeAPI+path, nil)
 	if err != nil {
-		return devices, err
+		return err
 	}
-	if res.StatusCode != http.StatusOK {
-		return devices, fmt.Errorf("api returned status: %s", res.Status)
+	res, err := clientFrom(ctx, ipswMeClient).Do(req)
+	if err != nil {
+		return err
 	}
+	defer res.Body.Close()
 
-	body, err := io.ReadAll(res.Body)
-	if err != nil {
-		return devices, err
+	if res.StatusCode != http.StatusOK {
+		return &HTTPStatusError{Status: res.Status, StatusCode: res.StatusCode}
 	}
-	res.Body.Close()
 
-	err = json.Unmarshal(body, &devices)
+	body, err := io.ReadAll(res.Body)
 	if err != nil {
-		return devices, err
+		return err
 	}
 
-	return devices, nil
+	return json.Unmarshal(body, v)
 }
 
-//export c_internal_download_ipsw_me_GetDevice
-func c_internal_download_ipsw_me_GetDevice(identifier *C.char, identifierLen C.uint, outJson **C.char, outJsonLen *C.uint, err **C.char, errLen *C.uint) C.char {
+// GetAllDevices returns a list of all devices
+func GetAllDevices() ([]Device, error) {
+	return GetAllDevicesWithContext(context.Background())
+}
 
-	device, deviceError := GetDevice(C.GoStringN(identifier, C.int(identifierLen)))
-	if deviceError != nil {
-		outError := fmt.Sprintf("c_GetDevice: GetDevice failed with %w", deviceError)
-		*err = C.CString(outError)
-		*errLen = C.uint(len(outError))
-		return C.char(0)
-	}
-	fret, jsonErr := json.Marshal(device)
-	if jsonErr != nil {
-		outError := fmt.Sprintf("c_GetDevice: Failed to serialize Device object: %w", jsonErr)
-		*err = C.CString(outError)
-		*errLen = C.uint(len(outError))
-		return C.char(0)
+// GetAllDevicesWithContext returns a list of all devices (the request is aborted when ctx is canceled)
+func GetAllDevicesWithContext(ctx context.Context) ([]Device, error) {
+	devices := []Device{}
+	if err := getIpswMe(ctx, "devices", &devices); err != nil {
+		return devices, err
 	}
-	cs := C.CString(string(fret))
-	*outJson = cs
-	*outJsonLen = C.uint(C.strlen(cs))
-
-	return C.char(1)
+	return devices, nil
 }
 
 // GetDevice returns a device from it's identifier
 func GetDevice(identifier string) (Device, error) {
-	d := Device{}
-
-	res, err := http.Get(ipswMeAPI + "device" + "/" + identifier)
-	if err != nil {
-		return d, err
-	}
-	if res.StatusCode != http.StatusOK {
-		return d, fmt.Errorf("api returned status: %s", res.Status)
-	}
+	return GetDeviceWithContext(context.Background(), identifier)
+}
 
-	body, err := io.ReadAll(res.Body)
-	if err != nil {
+// GetDeviceWithContext returns a device from it's identifier (the request is aborted when ctx is canceled)
+func GetDeviceWithContext(ctx context.Context, identifier string) (Device, error) {
+	identifier = ResolveDevice(identifier)
+	if d, err, ok := fromMetadata(ctx, "device "+identifier, func(src MetadataSource) (Device, error) {
+		return src.GetDevice(identifier)
+	}); ok {
 		return d, err
 	}
-	res.Body.Close()
 
-	err = json.Unmarshal(body, &d)
-	if err != nil {
+	d := Device{}
+	if err := getIpswMe(ctx, "device/"+identifier, &d); err != nil {
 		return d, err
 	}
-
 	return d, nil
 }
 
-//export c_internal_download_ipsw_me_GetDeviceIPSWs
-func c_internal_download_ipsw_me_GetDeviceIPSWs(identifier *C.char, identifierLen C.uint, outJson **C.char, outJsonLen *C.uint, err **C.char, errLen *C.uint) C.char {
-	device, deviceError := GetDeviceIPSWs(C.GoStringN(identifier, C.int(identifierLen)))
-	if deviceError != nil {
-		outError := fmt.Sprintf("c_GetDeviceIPSWs: GetDeviceIPSWs failed with %w", deviceError)
-		*err = C.CString(outError)
-		*errLen = C.uint(len(outError))
-		return C.char(0)
-	}
-	fret, jsonErr := json.Marshal(device)
-	if jsonErr != nil {
-		outError := fmt.Sprintf("c_GetDeviceIPSWs: Failed to serialize Device object: %w", jsonErr)
-		*err = C.CString(outError)
-		*errLen = C.uint(len(outError))
-		return C.char(0)
+// GetDeviceIPSWs returns a device's IPSWs from it's identifier
+func GetDeviceIPSWs(identifier string) ([]IPSW, error) {
+	return GetDeviceIPSWsWithContext(context.Background(), identifier)
+}
+
+// GetDeviceIPSWsWithContext returns a device's IPSWs from it's identifier (the request is aborted when ctx is canceled)
+func GetDeviceIPSWsWithContext(ctx context.Context, identifier string) ([]IPSW, error) {
+	d, err := GetDeviceWithContext(ctx, identifier)
+	if err != nil {
+		return nil, err
 	}
-	cs := C.CString(string(fret))
-	*outJson = cs
-	*outJsonLen = C.uint(C.strlen(cs))
+	return d.Firmwares, nil
+}
 
-	return C.char(1)
+// GetDeviceOTAs returns a device's OTAs from it's identifier
+func GetDeviceOTAs(identifier string) ([]IPSW, error) {
+	return GetDeviceOTAsWithContext(context.Background(), identifier)
 }
 
-// GetDeviceIPSWs returns a device's IPSWs from it's identifier
-func GetDeviceIPSWs(identifier string) ([]IPSW, error) {
-	d, err := GetDevice(identifier)
-	if err != nil {
+// GetDeviceOTAsWithContext returns a device's OTAs from it's identifier (the request is aborted when ctx is canceled)
+func GetDeviceOTAsWithContext(ctx context.Context, identifier string) ([]IPSW, error) {
+	identifier = ResolveDevice(identifier)
+	var d Device
+	if err := getIpswMe(ctx, "device/"+identifier+"?type=ota", &d); err != nil {
 		return nil, err
 	}
 	return d.Firmwares, nil
 }
 
-// GetAllIPSW finds all IPSW files for a given iOS version
-func GetAllIPSW(version string) ([]IPSW, error) {
-	ipsws := []IPSW{}
+// GetSignedIPSWs returns the IPSWs of a device that Apple is still signing
+func GetSignedIPSWs(identifier string) ([]IPSW, error) {
+	return GetSignedIPSWsWithContext(context.Background(), identifier)
+}
 
-	res, err := http.Get(ipswMeAPI + "ipsw/" + version)
+// GetSignedIPSWsWithContext returns the IPSWs of a device that Apple is still signing (the request is aborted when ctx is canceled)
+func GetSignedIPSWsWithContext(ctx context.Context, identifier string) ([]IPSW, error) {
+	ipsws, err := GetDeviceIPSWsWithContext(ctx, identifier)
 	if err != nil {
-		return ipsws, err
+		return nil, err
 	}
-	if res.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("api returned status: %s", res.Status)
+	signed := []IPSW{}
+	for _, ipsw := range ipsws {
+		if ipsw.Signed {
+			signed = append(signed, ipsw)
+		}
 	}
+	return signed, nil
+}
 
-	body, err := io.ReadAll(res.Body)
-	if err != nil {
-		return ipsws, err
-	}
-	res.Body.Close()
+// GetAllIPSW finds all IPSW files for a given iOS version
+func GetAllIPSW(version string) ([]IPSW, error) {
+	return GetAllIPSWWithContext(context.Background(), version)
+}
 
-	err = json.Unmarshal(body, &ipsws)
-	if err != nil {
-		return ipsws, err
+// GetAllIPSWWithContext finds all IPSW files for a given iOS version (the request is aborted when ctx is canceled)
+func GetAllIPSWWithContext(ctx context.Context, version string) ([]IPSW, error) {
+	ipsws := []IPSW{}
+	if err := getIpswMe(ctx, "ipsw/"+version, &ipsws); err != nil {
+		return nil, err
 	}
-
 	return ipsws, nil
 }
 
 // GetIPSW will get an IPSW when supplied an identifier and build ID
 func GetIPSW(identifier, buildID string) (IPSW, error) {
-	i := IPSW{}
+	return GetIPSWWithContext(context.Background(), identifier, buildID)
+}
 
-	res, err := http.Get(ipswMeAPI + "ipsw/" + identifier + "/" + buildID)
-	if err != nil {
+// GetIPSWWithContext will get an IPSW when supplied an identifier and build ID (the request is aborted when ctx is canceled)
+func GetIPSWWithContext(ctx context.Context, identifier, buildID string) (IPSW, error) {
+	identifier = ResolveDevice(identifier)
+	i := IPSW{}
+	if err := getIpswMe(ctx, "ipsw/"+identifier+"/"+buildID, &i); err != nil {
 		return i, err
 	}
-	defer res.Body.Close()
-
-	if res.StatusCode != http.StatusOK {
-		return i, fmt.Errorf("api returned status: %s", res.Status)
-	}
+	return i, nil
+}
 
-	body, err := io.ReadAll(res.Body)
-	if err != nil {
-		return i, err
-	}
+// GetVersion returns the iOS version for a given build ID
+func GetVersion(buildID string) (string, error) {
+	return GetVersionWithContext(context.Background(), buildID)
+}
 
-	err = json.Unmarshal(body, &i)
+// GetVersionWithContext returns the iOS version for a given build ID (the requests are aborted when ctx is canceled)
+func GetVersionWithContext(ctx context.Context, buildID string) (string, error) {
+	i, err := GetBuildWithContext(ctx, buildID)
 	if err != nil {
-		return i, err
+		return "", err
 	}
+	return i.Version, nil
+}
 
-	return i, nil
+// GetBuild returns the first IPSW found (newest device first) for a given build ID
+func GetBuild(buildID string) (IPSW, error) {
+	return GetBuildWithContext(context.Background(), buildID)
 }
 
-// GetVersion returns the iOS version for a given build ID
-func GetVersion(buildID string) (string, error) {
+// GetBuildWithContext returns the first IPSW found (newest device first) for a given build ID
+// (the requests are aborted when ctx is canceled)
+func GetBuildWithContext(ctx context.Context, buildID string) (IPSW, error) {
+	if i, err, ok := fromMetadata(ctx, "build "+buildID, func(src MetadataSource) (IPSW, error) {
+		return src.GetBuild(buildID)
+	}); ok {
+		return i, err
+	}
 
-	devices, err := GetAllDevices()
+	devices, err := GetAllDevicesWithContext(ctx)
 	if err != nil {
-		return "", fmt.Errorf("failed to get all devices from ipsw.me API: %v", err)
+		return IPSW{}, fmt.Errorf("failed to get all devices from ipsw.me API: %v", err)
 	}
 
 	for i := len(devices) - 1; i >= 0; i-- {
 		var dev Device
-		res, err := http.Get(ipswMeAPI + "device/" + devices[i].Identifier)
-		if err != nil {
-			return "", err
-		}
-		if res.StatusCode != http.StatusOK {
-			return "", fmt.Errorf("api returned status: %s", res.Status)
-		}
-
-		body, err := io.ReadAll(res.Body)
-		if err != nil {
-			return "", err
+		if err := getIpswMe(ctx, "device/"+devices[i].Identifier, &dev); err != nil {
+			return IPSW{}, err
 		}
-		res.Body.Close()
-
-		err = json.Unmarshal(body, &dev)
-		if err != nil {
-			return "", err
-		}
-
 		for _, ipsw := range dev.Firmwares {
 			if ipsw.BuildID == buildID {
-				return ipsw.Version, nil
+				return ipsw, nil
 			}
 		}
 	}
 
-	return "", fmt.Errorf("build did not a version")
+	return IPSW{}, exitcode.Errorf(exitcode.NotFound, "build %s not found", buildID)
 }
 
 // GetBuildID returns the BuildID for a given version and identifier
 func GetBuildID(version, identifier string) (string, error) {
-	var ipsws []IPSW
-
-	res, err := http.Get(ipswMeAPI + "ipsw/" + version)
-	if err != nil {
-		return "", err
-	}
-	if res.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("api returned status: %s", res.Status)
-	}
+	return GetBuildIDWithContext(context.Background(), version, identifier)
+}
 
-	body, err := io.ReadAll(res.Body)
-	if err != nil {
-		return "", err
+// GetBuildIDWithContext returns the BuildID for a given version and identifier (the request is aborted when ctx is canceled)
+func GetBuildIDWithContext(ctx context.Context, version, identifier string) (string, error) {
+	identifier = ResolveDevice(identifier)
+	if build, err, ok := fromMetadata(ctx, "build of "+identifier+" "+version, func(src MetadataSource) (string, error) {
+		return src.GetBuildID(version, identifier)
+	}); ok {
+		return build, err
 	}
-	res.Body.Close()
 
-	err = json.Unmarshal(body, &ipsws)
-	if err != nil {
+	var ipsws []IPSW
+	if err := getIpswMe(ctx, "ipsw/"+version, &ipsws); err != nil {
 		return "", err
 	}
 
@@ -268,8 +255,81 @@ func GetBuildID(version, identifier string) (string, error) {
 			return i.BuildID, nil
 		}
 	}
-	return "", fmt.Errorf("no build found for version %s and device %s", version, identifier)
+	return "", exitcode.Errorf(exitcode.NotFound, "no build found for version %s and device %s", version, identifier)
 }
 
 // https://api.ipsw.me/v4/releases
 // func GetReleases() []Release {}
+
+// IpswMeClient is a client of the ipsw.me API with its own transport (see WithTransport);
+// the package functions use a client with the shared transports
+type IpswMeClient struct {
+	client     *http.Client
+	noMetadata bool
+}
+
+// NewIpswMeClient returns a new ipsw.me API client
+//
+// A client with its own transport only talks to it: its lookups don't resolve from the metadata
+// source (see SetMetadataSource) and its responses aren't cached (see SetHTTPCacheDir).
+func NewIpswMeClient(opts ...ClientOption) *IpswMeClient {
+	o := newClientOptions(opts)
+	if o.transport != nil {
+		return &IpswMeClient{client: &http.Client{Transport: BudgetTransport(o.transport)}, noMetadata: true}
+	}
+	return &IpswMeClient{client: ipswMeClient}
+}
+
+// requestContext returns a copy of ctx that makes the package functions use the client
+func (c *IpswMeClient) requestContext(ctx context.Context) context.Context {
+	ctx = withClient(ctx, c.client)
+	if c.noMetadata {
+		ctx = withoutMetadata(ctx)
+	}
+	return ctx
+}
+
+// GetAllDevices returns a list of all devices (the request is aborted when ctx is canceled)
+func (c *IpswMeClient) GetAllDevices(ctx context.Context) ([]Device, error) {
+	return GetAllDevicesWithContext(c.requestContext(ctx))
+}
+
+// GetDevice returns a device from it's identifier (the request is aborted when ctx is canceled)
+func (c *IpswMeClient) GetDevice(ctx context.Context, identifier string) (Device, error) {
+	return GetDeviceWithContext(c.requestContext(ctx), identifier)
+}
+
+// GetDeviceIPSWs returns a device's IPSWs from it's identifier (the request is aborted when ctx is canceled)
+func (c *IpswMeClient) GetDeviceIPSWs(ctx context.Context, identifier string) ([]IPSW, error) {
+	return GetDeviceIPSWsWithContext(c.requestContext(ctx), identifier)
+}
+
+// GetDeviceOTAs returns a device's OTAs from it's identifier (the request is aborted when ctx is canceled)
+func (c *IpswMeClient) GetDeviceOTAs(ctx context.Context, identifier string) ([]IPSW, error) {
+	return GetDeviceOTAsWithContext(c.requestContext(ctx), identifier)
+}
+
+// GetSignedIPSWs returns the IPSWs of a device that Apple is still signing (the request is aborted when ctx is canceled)
+func (c *IpswMeClient) GetSignedIPSWs(ctx context.Context, identifier string) ([]IPSW, error) {
+	return GetSignedIPSWsWithContext(c.requestContext(ctx), identifier)
+}
+
+// GetAllIPSW finds all IPSW files for a given iOS version (the request is aborted when ctx is canceled)
+func (c *IpswMeClient) GetAllIPSW(ctx context.Context, version string) ([]IPSW, error) {
+	return GetAllIPSWWithContext(c.requestContext(ctx), version)
+}
+
+// GetIPSW will get an IPSW when supplied an identifier and build ID (the request is aborted when ctx is canceled)
+func (c *IpswMeClient) GetIPSW(ctx context.Context, identifier, buildID string) (IPSW, error) {
+	return GetIPSWWithContext(c.requestContext(ctx), identifier, buildID)
+}
+
+// GetVersion returns the iOS version for a given build ID (the requests are aborted when ctx is canceled)
+func (c *IpswMeClient) GetVersion(ctx context.Context, buildID string) (string, error) {
+	return GetVersionWithContext(c.requestContext(ctx), buildID)
+}
+
+// GetBuildID returns the BuildID for a given version and identifier (the request is aborted when ctx is canceled)
+func (c *IpswMeClient) GetBuildID(ctx context.Context, version, identifier string) (string, error) {
+	return GetBuildIDWithContext(c.requestContext(ctx), version, identifier)
+}
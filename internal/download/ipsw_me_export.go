@@ -0,0 +1,108 @@
+//go:build ffi
+
+package download
+
+//#cgo LDFLAGS:
+//#include <stdio.h>
+//#include <stdlib.h>
+//#include <string.h>
+import "C"
+import (
+	"encoding/json"
+	"fmt"
+)
+
+//export c_internal_download_ipsw_me_GetAllDevices
+func c_internal_download_ipsw_me_GetAllDevices(outJson **C.char, outJsonLen *C.uint, err **C.char, errLen *C.uint) C.char {
+	devices, devicesErr := GetAllDevices()
+	if devicesErr != nil {
+		return setCError(devicesErr, fmt.Sprintf("c_GetAllDevices: GetAllDevices failed with %v", devicesErr), err, errLen)
+	}
+	return setCJSON(devices, outJson, outJsonLen, err, errLen, "c_GetAllDevices", "Device objects")
+}
+
+//export c_internal_download_ipsw_me_GetDevice
+func c_internal_download_ipsw_me_GetDevice(identifier *C.char, identifierLen C.uint, outJson **C.char, outJsonLen *C.uint, err **C.char, errLen *C.uint) C.char {
+
+	device, deviceError := GetDevice(C.GoStringN(identifier, C.int(identifierLen)))
+	if deviceError != nil {
+		return setCError(deviceError, fmt.Sprintf("c_GetDevice: GetDevice failed with %v", deviceError), err, errLen)
+	}
+	fret, jsonErr := json.Marshal(device)
+	if jsonErr != nil {
+		return setCError(jsonErr, fmt.Sprintf("c_GetDevice: Failed to serialize Device object: %v", jsonErr), err, errLen)
+	}
+	cs := C.CString(string(fret))
+	*outJson = cs
+	*outJsonLen = C.uint(C.strlen(cs))
+
+	return C.char(1)
+}
+
+//export c_internal_download_ipsw_me_GetDeviceIPSWs
+func c_internal_download_ipsw_me_GetDeviceIPSWs(identifier *C.char, identifierLen C.uint, outJson **C.char, outJsonLen *C.uint, err **C.char, errLen *C.uint) C.char {
+	device, deviceError := GetDeviceIPSWs(C.GoStringN(identifier, C.int(identifierLen)))
+	if deviceError != nil {
+		return setCError(deviceError, fmt.Sprintf("c_GetDeviceIPSWs: GetDeviceIPSWs failed with %v", deviceError), err, errLen)
+	}
+	fret, jsonErr := json.Marshal(device)
+	if jsonErr != nil {
+		return setCError(jsonErr, fmt.Sprintf("c_GetDeviceIPSWs: Failed to serialize Device object: %v", jsonErr), err, errLen)
+	}
+	cs := C.CString(string(fret))
+	*outJson = cs
+	*outJsonLen = C.uint(C.strlen(cs))
+
+	return C.char(1)
+}
+
+// c_internal_download_ipsw_me_GetDeviceOTAs returns the OTAs of a device (i.e. iPhone16,1) from ipsw.me
+//
+//export c_internal_download_ipsw_me_GetDeviceOTAs
+func c_internal_download_ipsw_me_GetDeviceOTAs(identifier *C.char, identifierLen C.uint, outJson **C.char, outJsonLen *C.uint, err **C.char, errLen *C.uint) C.char {
+	otas, otasErr := GetDeviceOTAs(C.GoStringN(identifier, C.int(identifierLen)))
+	if otasErr != nil {
+		return setCError(otasErr, fmt.Sprintf("c_GetDeviceOTAs: GetDeviceOTAs failed with %v", otasErr), err, errLen)
+	}
+	return setCJSON(otas, outJson, outJsonLen, err, errLen, "c_GetDeviceOTAs", "IPSW objects")
+}
+
+//export c_internal_download_ipsw_me_GetAllIPSW
+func c_internal_download_ipsw_me_GetAllIPSW(version *C.char, versionLen C.uint, outJson **C.char, outJsonLen *C.uint, err **C.char, errLen *C.uint) C.char {
+	ipsws, ipswsErr := GetAllIPSW(C.GoStringN(version, C.int(versionLen)))
+	if ipswsErr != nil {
+		return setCError(ipswsErr, fmt.Sprintf("c_GetAllIPSW: GetAllIPSW failed with %v", ipswsErr), err, errLen)
+	}
+	return setCJSON(ipsws, outJson, outJsonLen, err, errLen, "c_GetAllIPSW", "IPSW objects")
+}
+
+//export c_internal_download_ipsw_me_GetIPSW
+func c_internal_download_ipsw_me_GetIPSW(identifier *C.char, identifierLen C.uint, buildID *C.char, buildIDLen C.uint, outJson **C.char, outJsonLen *C.uint, err **C.char, errLen *C.uint) C.char {
+	ipsw, ipswErr := GetIPSW(C.GoStringN(identifier, C.int(identifierLen)), C.GoStringN(buildID, C.int(buildIDLen)))
+	if ipswErr != nil {
+		return setCError(ipswErr, fmt.Sprintf("c_GetIPSW: GetIPSW failed with %v", ipswErr), err, errLen)
+	}
+	return setCJSON(ipsw, outJson, outJsonLen, err, errLen, "c_GetIPSW", "IPSW object")
+}
+
+// c_internal_download_ipsw_me_GetVersion returns the version as a JSON string (i.e. "17.0")
+//
+//export c_internal_download_ipsw_me_GetVersion
+func c_internal_download_ipsw_me_GetVersion(buildID *C.char, buildIDLen C.uint, outJson **C.char, outJsonLen *C.uint, err **C.char, errLen *C.uint) C.char {
+	version, versionErr := GetVersion(C.GoStringN(buildID, C.int(buildIDLen)))
+	if versionErr != nil {
+		return setCError(versionErr, fmt.Sprintf("c_GetVersion: GetVersion failed with %v", versionErr), err, errLen)
+	}
+	return setCJSON(version, outJson, outJsonLen, err, errLen, "c_GetVersion", "version")
+}
+
+// c_internal_download_ipsw_me_GetBuildID returns the build ID as a JSON string (i.e. "21A329")
+//
+//export c_internal_download_ipsw_me_GetBuildID
+func c_internal_download_ipsw_me_GetBuildID(version *C.char, versionLen C.uint, identifier *C.char, identifierLen C.uint, outJson **C.char, outJsonLen *C.uint, err **C.char, errLen *C.uint) C.char {
+	buildID, buildErr := GetBuildID(C.GoStringN(version, C.int(versionLen)), C.GoStringN(identifier, C.int(identifierLen)))
+	if buildErr != nil {
+		return setCError(buildErr, fmt.Sprintf("c_GetBuildID: GetBuildID failed with %v", buildErr), err, errLen)
+	}
+	return setCJSON(buildID, outJson, outJsonLen, err, errLen, "c_GetBuildID", "build ID")
+}
@@ -9,6 +9,7 @@ import (
 	"strings"
 
 	"github.com/blacktop/go-plist"
+	"github.com/blacktop/ipsw/internal/exitcode"
 	"github.com/blacktop/ipsw/internal/utils"
 	"github.com/hashicorp/go-version"
 	"github.com/pkg/errors"
@@ -143,7 +144,7 @@ func (vm *ITunesVersionMaster) GetLatestBuilds(device string) ([]Build, error) {
 	}
 
 	if len(versionsRaw) == 0 {
-		return nil, fmt.Errorf("no versions found for device %s", device)
+		return nil, exitcode.Errorf(exitcode.NotFound, "no versions found for device %s", device)
 	}
 
 	versions := make([]*version.Version, len(versionsRaw))
@@ -322,62 +323,74 @@ func (vm *ITunesVersionMaster) GetSoftwareURLsForBuildID(buildID string) ([]stri
 	return utils.Unique(urls), nil
 }
 
-// NewiTunesVersionMaster downloads and parses the itumes plist
-func NewiTunesVersionMaster() (*ITunesVersionMaster, error) {
-	resp, err := http.Get(iTunesVersionURL)
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to create http client")
-	}
-
-	document, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to read plist")
+// GetIPSWs returns the IPSWs in the catalog that match the given device, version and build (empty matches all)
+func (vm *ITunesVersionMaster) GetIPSWs(device, version, build string) []IPSW {
+	var ipsws []IPSW
+	for _, b := range UniqueBuilds(vm.GetBuilds()) {
+		if len(device) > 0 && !strings.EqualFold(device, b.Identifier) {
+			continue
+		}
+		if len(version) > 0 && version != b.Version {
+			continue
+		}
+		if len(build) > 0 && !strings.EqualFold(build, b.BuildID) {
+			continue
+		}
+		ipsws = append(ipsws, IPSW{
+			Identifier: b.Identifier,
+			Version:    b.Version,
+			BuildID:    b.BuildID,
+			SHA1:       b.FirmwareSHA1,
+			URL:        b.URL,
+		})
 	}
+	return ipsws
+}
 
+// ParseITunesVersionMaster parses an itunes version XML catalog
+func ParseITunesVersionMaster(document []byte) (*ITunesVersionMaster, error) {
 	vm := ITunesVersionMaster{}
 
-	dec := plist.NewDecoder(bytes.NewReader(document))
-	dec.Decode(&vm)
+	if err := plist.NewDecoder(bytes.NewReader(document)).Decode(&vm); err != nil {
+		// some catalogs contain entries that don't fit our types, only fail if nothing was parsed
+		if len(vm.MobileDeviceSoftwareVersionsByVersion) == 0 {
+			return nil, errors.Wrap(err, "failed to parse plist")
+		}
+	}
 
 	return &vm, nil
 }
 
-// NewMacOsXML downloads and parses the macOS IPSW plist
-func NewMacOsXML() (*ITunesVersionMaster, error) {
-	resp, err := http.Get(macOSIpswURL)
+func getITunesVersionMaster(url string) (*ITunesVersionMaster, error) {
+	resp, err := defaultClient.Get(url)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to create http client")
 	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to GET %s: %s", url, resp.Status)
+	}
 
 	document, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to read plist")
 	}
 
-	vm := ITunesVersionMaster{}
+	return ParseITunesVersionMaster(document)
+}
 
-	dec := plist.NewDecoder(bytes.NewReader(document))
-	dec.Decode(&vm)
+// NewiTunesVersionMaster downloads and parses the itumes plist
+func NewiTunesVersionMaster() (*ITunesVersionMaster, error) {
+	return getITunesVersionMaster(iTunesVersionURL)
+}
 
-	return &vm, nil
+// NewMacOsXML downloads and parses the macOS IPSW plist
+func NewMacOsXML() (*ITunesVersionMaster, error) {
+	return getITunesVersionMaster(macOSIpswURL)
 }
 
 // NewIBridgeXML downloads and parses the iBridge IPSW plist
 func NewIBridgeXML() (*ITunesVersionMaster, error) {
-	resp, err := http.Get(iBridgeOSURL)
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to create http client")
-	}
-
-	document, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to read plist")
-	}
-
-	vm := ITunesVersionMaster{}
-
-	dec := plist.NewDecoder(bytes.NewReader(document))
-	dec.Decode(&vm)
-
-	return &vm, nil
+	return getITunesVersionMaster(iBridgeOSURL)
 }
@@ -8,7 +8,6 @@ import (
 	"net/http"
 	"time"
 
-	"github.com/apex/log"
 	"github.com/blacktop/ipsw/internal/utils"
 	"github.com/hashicorp/go-version"
 	"github.com/pkg/errors"
@@ -55,7 +54,7 @@ func (j *Jailbreaks) CanIBreak(iOSVersion string) (bool, int, error) {
 				return false, -1, errors.Wrap(err, "failed to create new version constraint")
 			}
 			if constraints.Check(v) {
-				utils.Indent(log.Debug, 1)(fmt.Sprintf("%s satisfies constraints %s", v.Original(), constraints))
+				utils.Indent(getLogger().Debug, 1)(fmt.Sprintf("%s satisfies constraints %s", v.Original(), constraints))
 				return jb.Jailbroken, idx, nil
 			}
 		} else if len(jb.Firmwares.Start) > 0 {
@@ -64,7 +63,7 @@ func (j *Jailbreaks) CanIBreak(iOSVersion string) (bool, int, error) {
 				return false, -1, errors.Wrap(err, "failed to create new version constraint")
 			}
 			if constraints.Check(v) {
-				utils.Indent(log.Debug, 1)(fmt.Sprintf("%s satisfies constraints %s", v.Original(), constraints))
+				utils.Indent(getLogger().Debug, 1)(fmt.Sprintf("%s satisfies constraints %s", v.Original(), constraints))
 				return jb.Jailbroken, idx, nil
 			}
 		}
@@ -81,7 +80,7 @@ func GetRandomResearcher() string {
 func GetJailbreaks() (Jailbreaks, error) {
 	jbs := Jailbreaks{}
 
-	res, err := http.Get(canIJailbreakURL)
+	res, err := defaultClient.Get(canIJailbreakURL)
 	if err != nil {
 		return jbs, err
 	}
@@ -0,0 +1,92 @@
+//go:build !wasm
+
+package download
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// jobs are the downloads started with StartJob
+var jobs = struct {
+	sync.Mutex
+	next uint64
+	m    map[string]*Download
+}{m: make(map[string]*Download)}
+
+// StartJob starts a download in the background right away (unlike Manager.Add it is not queued)
+// and returns its job ID; existing files are skipped or verified like the CLI does (see SkipExisting)
+func StartJob(d *Download) string {
+	d.setState(StateQueued)
+
+	jobs.Lock()
+	jobs.next++
+	id := fmt.Sprintf("job-%d", jobs.next)
+	jobs.m[id] = d
+	jobs.Unlock()
+
+	go func() {
+		if d.State() == StateCanceled { // canceled before it started
+			return
+		}
+		skip, err := d.SkipExisting()
+		if err != nil {
+			d.finish(err)
+			return
+		}
+		if skip {
+			d.finish(nil)
+			return
+		}
+		d.DoWithContext(WithTraceID(context.Background(), NewTraceID()))
+	}()
+
+	return id
+}
+
+// getJob returns the download of a job ID
+func getJob(id string) (*Download, error) {
+	jobs.Lock()
+	defer jobs.Unlock()
+	d, ok := jobs.m[id]
+	if !ok {
+		return nil, fmt.Errorf("unknown download job %s: %w", id, ErrInvalidArgument)
+	}
+	return d, nil
+}
+
+// JobStatus returns the status of a download job
+func JobStatus(id string) (*DownloadStatus, error) {
+	d, err := getJob(id)
+	if err != nil {
+		return nil, err
+	}
+	status := d.Status()
+	status.ID = id
+	return &status, nil
+}
+
+// CancelJob cancels a download job and removes its partial download (canceling a finished job does nothing)
+func CancelJob(id string) error {
+	d, err := getJob(id)
+	if err != nil {
+		return err
+	}
+	switch d.State() {
+	case StateDone, StateFailed, StateCanceled:
+		return nil
+	}
+	return d.Cancel()
+}
+
+// ReleaseJob cancels (if still running) and forgets a download job
+func ReleaseJob(id string) {
+	jobs.Lock()
+	d, ok := jobs.m[id]
+	delete(jobs.m, id)
+	jobs.Unlock()
+	if ok {
+		d.Cancel() // finished downloads can't be canceled
+	}
+}
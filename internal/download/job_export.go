@@ -0,0 +1,65 @@
+//go:build ffi
+
+package download
+
+//#cgo LDFLAGS:
+//#include <stdio.h>
+//#include <stdlib.h>
+//#include <string.h>
+import "C"
+import (
+	"fmt"
+)
+
+// Download jobs run in the background and can be canceled from any thread:
+//
+//	c_download_start   starts a download and returns its job handle
+//	c_download_status  returns the job's DownloadStatus (poll it for the progress and the final state)
+//	c_download_cancel  cancels the job (the partial download is removed)
+//	c_download_free    releases the job handle (canceling the job if it is still running)
+
+// c_download_start downloads url to dest (the URL's file name in the current directory if empty) in the background;
+// the checksums are optional and the job handle must be released with c_download_free
+//
+//export c_download_start
+func c_download_start(url *C.char, urlLen C.uint, dest *C.char, destLen C.uint, sha1 *C.char, sha1Len C.uint, sha256 *C.char, sha256Len C.uint,
+	proxy *C.char, proxyLen C.uint, insecure C.char, outJob **C.char, outJobLen *C.uint, err **C.char, errLen *C.uint) C.char {
+	d, derr := newCDownload(C.GoStringN(url, C.int(urlLen)), C.GoStringN(dest, C.int(destLen)), C.GoStringN(sha1, C.int(sha1Len)),
+		C.GoStringN(sha256, C.int(sha256Len)), C.GoStringN(proxy, C.int(proxyLen)), insecure == 1)
+	if derr != nil {
+		return setCError(derr, fmt.Sprintf("c_download_start: %v", derr), err, errLen)
+	}
+	cs := C.CString(StartJob(d))
+	*outJob = cs
+	*outJobLen = C.uint(C.strlen(cs))
+	return C.char(1)
+}
+
+// c_download_status returns the status of a download job
+//
+//export c_download_status
+func c_download_status(job *C.char, jobLen C.uint, outJson **C.char, outJsonLen *C.uint, err **C.char, errLen *C.uint) C.char {
+	status, serr := JobStatus(C.GoStringN(job, C.int(jobLen)))
+	if serr != nil {
+		return setCError(serr, fmt.Sprintf("c_download_status: %v", serr), err, errLen)
+	}
+	return setCJSON(status, outJson, outJsonLen, err, errLen, "c_download_status", "DownloadStatus object")
+}
+
+// c_download_cancel cancels a download job (canceling a finished job does nothing); it returns right away
+// and the job's state becomes canceled once its transfer is aborted
+//
+//export c_download_cancel
+func c_download_cancel(job *C.char, jobLen C.uint, err **C.char, errLen *C.uint) C.char {
+	if cerr := CancelJob(C.GoStringN(job, C.int(jobLen))); cerr != nil {
+		return setCError(cerr, fmt.Sprintf("c_download_cancel: %v", cerr), err, errLen)
+	}
+	return C.char(1)
+}
+
+// c_download_free releases a download job handle
+//
+//export c_download_free
+func c_download_free(job *C.char, jobLen C.uint) {
+	ReleaseJob(C.GoStringN(job, C.int(jobLen)))
+}
@@ -2,10 +2,12 @@ package download
 
 import (
 	"encoding/json"
+	"fmt"
 	"io"
-	"net/http"
 	"strings"
 	"time"
+
+	"github.com/blacktop/ipsw/internal/utils"
 )
 
 // shout out to dhinakg for the KDK manifest ❤️
@@ -32,6 +34,9 @@ func (r KDKDate) Format(s string) string {
 	t := time.Time(r)
 	return t.Format(s)
 }
+func (r KDKDate) Time() time.Time {
+	return time.Time(r)
+}
 
 // KDK is a Kernel Development Kit download object
 type KDK struct {
@@ -61,7 +66,7 @@ func (ks KDKs) Swap(i, j int) {
 
 // ListKDKs returns a list of KDKs
 func ListKDKs() (KDKs, error) {
-	resp, err := http.Get(kdkURL)
+	resp, err := defaultClient.Get(kdkURL)
 	if err != nil {
 		return nil, err
 	}
@@ -79,3 +84,31 @@ func ListKDKs() (KDKs, error) {
 
 	return kdks, nil
 }
+
+// GetByBuild returns the KDK for the given macOS build
+func (ks KDKs) GetByBuild(build string) (*KDK, error) {
+	for _, kdk := range ks {
+		if strings.EqualFold(kdk.Build, build) {
+			return &kdk, nil
+		}
+	}
+	return nil, fmt.Errorf("failed to find KDK for build %s", build)
+}
+
+// GetKDK returns the KDK for the given macOS build (or the current host's build if build is empty)
+func GetKDK(build string) (*KDK, error) {
+	if len(build) == 0 {
+		binfo, err := utils.GetBuildInfo()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get host build info: %v", err)
+		}
+		build = binfo.BuildVersion
+	}
+
+	kdks, err := ListKDKs()
+	if err != nil {
+		return nil, err
+	}
+
+	return kdks.GetByBuild(build)
+}
@@ -0,0 +1,111 @@
+//go:build !wasm
+
+package download
+
+import (
+	"os"
+	"os/exec"
+	"os/signal"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"golang.org/x/term"
+)
+
+var (
+	keybindings atomic.Bool
+	// only one download at a time can own the keyboard
+	keysOwned atomic.Bool
+)
+
+// EnableKeybindings lets the user pause/resume ('p') and cancel ('c') downloads from the terminal
+func EnableKeybindings() {
+	keybindings.Store(runtime.GOOS != "windows" && term.IsTerminal(int(os.Stdin.Fd())))
+}
+
+func stty(args ...string) (string, error) {
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = os.Stdin
+	out, err := cmd.Output()
+	return strings.TrimSpace(string(out)), err
+}
+
+// watchKeys puts the terminal in cbreak mode and maps key presses to the download's controls;
+// the returned func restores the terminal
+func (d *Download) watchKeys() func() {
+	if !keysOwned.CompareAndSwap(false, true) {
+		return func() {}
+	}
+
+	saved, err := stty("-g")
+	if err != nil {
+		keysOwned.Store(false)
+		return func() {}
+	}
+	// non-blocking reads (return after 100ms) so the watcher can be stopped
+	if _, err := stty("-icanon", "-echo", "min", "0", "time", "1"); err != nil {
+		keysOwned.Store(false)
+		return func() {}
+	}
+
+	var once sync.Once
+	restore := func() {
+		once.Do(func() {
+			stty(saved)
+			keysOwned.Store(false)
+		})
+	}
+
+	// the terminal must be restored if the user hits Ctrl+C
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, os.Interrupt)
+
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		buf := make([]byte, 1)
+		for {
+			select {
+			case <-done:
+				return
+			case <-sigs:
+				restore()
+				os.Exit(130)
+			default:
+			}
+			n, err := os.Stdin.Read(buf)
+			if err != nil || n == 0 {
+				continue
+			}
+			switch buf[0] {
+			case 'p', 'P', ' ':
+				if err := d.TogglePause(); err != nil {
+					d.logger().Error(err.Error())
+				} else if d.State() == StatePaused {
+					d.logger().Warnf("Paused %s (press 'p' to resume)", d.DestName)
+				} else {
+					d.logger().Infof("Resuming %s", d.DestName)
+				}
+			case 'c', 'C':
+				if err := d.Cancel(); err != nil {
+					d.logger().Error(err.Error())
+				} else {
+					d.logger().Warnf("Canceled %s", d.DestName)
+				}
+			}
+		}
+	}()
+
+	d.logger().Info("Press 'p' to pause/resume or 'c' to cancel the download")
+
+	return func() {
+		close(done)
+		wg.Wait()
+		signal.Stop(sigs)
+		restore()
+	}
+}
@@ -0,0 +1,56 @@
+//go:build ffi
+
+package download
+
+//#cgo LDFLAGS:
+//#include <stdio.h>
+//#include <stdlib.h>
+//#include <string.h>
+//
+//enum {
+//	LIBIPSW_LOG_DEBUG = 0,
+//	LIBIPSW_LOG_INFO = 1,
+//	LIBIPSW_LOG_WARN = 2,
+//	LIBIPSW_LOG_ERROR = 3,
+//	LIBIPSW_LOG_FATAL = 4,
+//};
+//
+//typedef void (*ipsw_log_cb)(int level, const char *message, const char *fields_json, void *user_data);
+//
+//static inline void call_ipsw_log_cb(ipsw_log_cb cb, int level, const char *message, const char *fields_json, void *user_data) {
+//	cb(level, message, fields_json, user_data);
+//}
+import "C"
+import (
+	"encoding/json"
+	"unsafe"
+
+	"github.com/apex/log"
+)
+
+// c_libipsw_set_log_callback sends the log lines at or above level (a LIBIPSW_LOG_* constant) to cb instead
+// of stderr (NULL restores stderr); fields_json is a JSON object of the line's fields (i.e. the trace ID),
+// both strings are only valid for the duration of the call and cb can be called from any thread
+//
+//export c_libipsw_set_log_callback
+func c_libipsw_set_log_callback(cb C.ipsw_log_cb, level C.int, userData unsafe.Pointer) {
+	if cb == nil {
+		SetLogger(nil)
+		return
+	}
+	SetLogger(&log.Logger{
+		Level: log.Level(level),
+		Handler: log.HandlerFunc(func(e *log.Entry) error {
+			fields, err := json.Marshal(e.Fields)
+			if err != nil {
+				fields = []byte("{}")
+			}
+			msg := C.CString(e.Message)
+			defer C.free(unsafe.Pointer(msg))
+			cfields := C.CString(string(fields))
+			defer C.free(unsafe.Pointer(cfields))
+			C.call_ipsw_log_cb(cb, C.int(e.Level), msg, cfields, userData)
+			return nil
+		}),
+	})
+}
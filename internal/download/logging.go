@@ -0,0 +1,44 @@
+package download
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/apex/log"
+)
+
+// packageLogger is the logger set with SetLogger (nil logs to the global apex logger)
+var packageLogger atomic.Pointer[log.Interface]
+
+type loggerKey struct{}
+
+// SetLogger sends the log lines of the package to l instead of the global apex logger (nil restores it);
+// the Logger of a client (i.e. Download.Logger or DevConfig.Logger) takes precedence
+func SetLogger(l log.Interface) {
+	if l == nil {
+		packageLogger.Store(nil)
+		return
+	}
+	packageLogger.Store(&l)
+}
+
+// getLogger returns the logger set with SetLogger (or the global apex logger)
+func getLogger() log.Interface {
+	if l := packageLogger.Load(); l != nil {
+		return *l
+	}
+	return log.Log
+}
+
+// WithLogger returns a copy of ctx that sends the log lines of the lookups and downloads run with it to l
+func WithLogger(ctx context.Context, l log.Interface) context.Context {
+	return context.WithValue(ctx, loggerKey{}, l)
+}
+
+// loggerFrom returns the logger of ctx (or the package logger)
+func loggerFrom(ctx context.Context) log.Interface {
+	if l, ok := ctx.Value(loggerKey{}).(log.Interface); ok && l != nil {
+		return l
+	}
+	return getLogger()
+}
@@ -6,21 +6,16 @@ import (
 	"encoding/xml"
 	"fmt"
 	"io"
-	"net/http"
 	"os"
 	"path"
-	"path/filepath"
 	"runtime"
 	"sort"
 	"strings"
 	"time"
 
-	"github.com/apex/log"
 	"github.com/blacktop/go-plist"
 	"github.com/blacktop/ipsw/internal/utils"
-	"github.com/dustin/go-humanize"
 	"github.com/olekukonko/tablewriter"
-	"github.com/pkg/errors"
 )
 
 // CREDIT: https://github.com/munki/macadmin-scripts
@@ -115,7 +110,7 @@ func (i ProductInfo) String() string {
 		i.Title,
 		i.Version,
 		i.Build,
-		i.PostDate.Format("02Jan2006 15:04:05"))
+		utils.FormatTime(i.PostDate))
 }
 
 type ProductInfos []ProductInfo
@@ -172,7 +167,7 @@ func (infos ProductInfos) String() string {
 				pinfo.Title,
 				pinfo.Version,
 				pinfo.Build,
-				pinfo.PostDate.In(location).Format("02Jan2006 15:04:05"),
+				utils.FormatTime(pinfo.PostDate.In(location)),
 			})
 		}
 	}
@@ -208,11 +203,8 @@ func getDestName(url string, removeCommas bool) string {
 	return destName
 }
 
-// GetProductInfo downloads and parses the macOS installer product infos
-func GetProductInfo() (ProductInfos, error) {
-
+func getSUCatalog() (*Catalog, error) {
 	var catData []byte
-	var prods ProductInfos
 
 	if runtime.GOOS == "darwin" {
 		data, err := os.ReadFile(seedCatalogsPlist)
@@ -226,7 +218,7 @@ func GetProductInfo() (ProductInfos, error) {
 		}
 
 		// resp, err := http.Get(seed.CustomerSeed)
-		resp, err := http.Get(seed.DeveloperSeed)
+		resp, err := defaultClient.Get(seed.DeveloperSeed)
 		if err != nil {
 			return nil, fmt.Errorf("failed to downoad the sucatalogs: %v", err)
 		}
@@ -254,7 +246,7 @@ func GetProductInfo() (ProductInfos, error) {
 		catData = buff.Bytes()
 
 	} else {
-		resp, err := http.Get(sucatalogsLatest)
+		resp, err := defaultClient.Get(sucatalogsLatest)
 		if err != nil {
 			return nil, fmt.Errorf("failed to downoad the sucatalogs: %v", err)
 		}
@@ -270,216 +262,106 @@ func GetProductInfo() (ProductInfos, error) {
 		return nil, fmt.Errorf("failed to decode sucatalogs plist: %v", err)
 	}
 
-	for key, prod := range cat.Products {
-
-		// filter
-		if len(prod.ExtendedMetaInfo.InstallAssistantPackageIdentifiers) == 0 {
-			continue
-		}
-
-		pInfo := ProductInfo{ProductID: key, PostDate: prod.PostDate, Product: prod}
-
-		if len(prod.ServerMetadataURL) > 0 {
-			resp, err := http.Get(prod.ServerMetadataURL)
-			if err != nil {
-				return nil, fmt.Errorf("failed to download the server metadata %s: %v", prod.ServerMetadataURL, err)
-			}
-
-			serverMetadata, err := io.ReadAll(resp.Body)
-			if err != nil {
-				return nil, fmt.Errorf("failed to read server metadata: %v", err)
-			}
-
-			smeta := ServerMetadata{}
-			if err := plist.NewDecoder(bytes.NewReader(serverMetadata)).Decode(&smeta); err != nil {
-				return nil, fmt.Errorf("failed to decode server metadata plist: %v", err)
-			}
-
-			for lang, loc := range smeta.Localization {
-				if strings.HasPrefix(strings.ToLower(lang), "english") {
-					pInfo.Title = loc.Title
-					pInfo.Version = smeta.CFBundleShortVersionString
-					break
-				}
-			}
-		}
+	return &cat, nil
+}
 
-		var distURL string
-		if dist, ok := prod.Distributions["English"]; ok {
-			distURL = dist
-		} else {
-			if dist, ok := prod.Distributions["en"]; ok {
-				distURL = dist
-			} else {
-				return nil, fmt.Errorf("failed to find English distribution for product: %s", key)
-			}
-		}
+func getProductInfo(key string, prod Product) (*ProductInfo, error) {
+	pInfo := ProductInfo{ProductID: key, PostDate: prod.PostDate, Product: prod}
 
-		resp, err := http.Get(distURL)
+	if len(prod.ServerMetadataURL) > 0 {
+		resp, err := defaultClient.Get(prod.ServerMetadataURL)
 		if err != nil {
-			return nil, fmt.Errorf("failed to download the distribution: %v", err)
+			return nil, fmt.Errorf("failed to download the server metadata %s: %v", prod.ServerMetadataURL, err)
 		}
 
-		pInfo.distributionData, err = io.ReadAll(resp.Body)
+		serverMetadata, err := io.ReadAll(resp.Body)
 		if err != nil {
-			return nil, fmt.Errorf("failed to read distribution data: %v", err)
+			return nil, fmt.Errorf("failed to read server metadata: %v", err)
 		}
 
-		dist := distribution{}
-		if err := xml.Unmarshal(pInfo.distributionData, &dist); err != nil {
-			return nil, fmt.Errorf("failed decode distribution XML data: %v", err)
+		smeta := ServerMetadata{}
+		if err := plist.NewDecoder(bytes.NewReader(serverMetadata)).Decode(&smeta); err != nil {
+			return nil, fmt.Errorf("failed to decode server metadata plist: %v", err)
 		}
 
-		if !strings.EqualFold(dist.Title, "SU_TITLE") {
-			pInfo.Title = dist.Title
-		}
-
-		if len(dist.AuxInfo.Keys) > 0 {
-			info, err := zipArrays(dist.AuxInfo.Keys, dist.AuxInfo.Values)
-			if err != nil {
-				return nil, fmt.Errorf("failed to zip distribution auxinfo: %v", err)
+		for lang, loc := range smeta.Localization {
+			if strings.HasPrefix(strings.ToLower(lang), "english") {
+				pInfo.Title = loc.Title
+				pInfo.Version = smeta.CFBundleShortVersionString
+				break
 			}
-			pInfo.Build = info["build"]
-			pInfo.Version = info["version"]
 		}
-
-		prods = append(prods, pInfo)
 	}
 
-	sort.Slice(prods[:], func(i, j int) bool {
-		return prods[i].PostDate.Before(prods[j].PostDate)
-	})
-
-	return prods, nil
-}
-
-func (i *ProductInfo) DownloadInstaller(workDir, proxy string, insecure, skipAll, resumeAll, restartAll, ignoreSha1, assistantOnly bool) error {
-
-	downloader := NewDownload(proxy, insecure, skipAll, resumeAll, restartAll, ignoreSha1, true)
-
-	folder := filepath.Join(workDir, fmt.Sprintf("%s_%s_%s", strings.ReplaceAll(i.Title, " ", "_"), i.Version, i.Build))
-
-	os.MkdirAll(folder, 0750)
-
-	log.Info("Downloading packages")
-	for _, pkg := range i.Product.Packages {
-		if len(pkg.URL) > 0 {
-			if assistantOnly && !strings.HasSuffix(pkg.URL, "InstallAssistant.pkg") {
-				continue
-			}
-			destName := getDestName(pkg.URL, false)
-			if _, err := os.Stat(filepath.Join(folder, destName)); os.IsNotExist(err) {
-				log.WithFields(log.Fields{
-					"size":     humanize.Bytes(uint64(pkg.Size)),
-					"destName": destName,
-				}).Info("Getting Package")
-				// download file
-				downloader.URL = pkg.URL
-				downloader.Sha1 = pkg.Digest
-				downloader.DestName = filepath.Join(folder, destName)
-				err = downloader.Do()
-				if err != nil {
-					return errors.Wrap(err, "failed to download file")
-				}
-
-			} else {
-				log.Warnf("pkg already exists: %s", filepath.Join(folder, destName))
-			}
-
-		} else if len(pkg.MetadataURL) > 0 {
-			if assistantOnly && !strings.HasSuffix(pkg.MetadataURL, "InstallAssistant.pkg") {
-				continue
-			}
-			destName := getDestName(pkg.MetadataURL, false)
-			if _, err := os.Stat(filepath.Join(folder, destName)); os.IsNotExist(err) {
-				log.WithFields(log.Fields{
-					"size":     humanize.Bytes(uint64(pkg.Size)),
-					"destName": destName,
-				}).Info("Getting Package")
-				// download file
-				downloader.URL = pkg.URL
-				downloader.Sha1 = pkg.Digest
-				downloader.DestName = filepath.Join(folder, destName)
-
-				err = downloader.Do()
-				if err != nil {
-					return errors.Wrap(err, "failed to download file")
-				}
-
-			} else {
-				log.Warnf("pkg already exists: %s", filepath.Join(folder, destName))
-			}
+	var distURL string
+	if dist, ok := prod.Distributions["English"]; ok {
+		distURL = dist
+	} else {
+		if dist, ok := prod.Distributions["en"]; ok {
+			distURL = dist
+		} else {
+			return nil, fmt.Errorf("failed to find English distribution for product: %s", key)
 		}
 	}
 
-	if assistantOnly {
-		return nil
+	resp, err := defaultClient.Get(distURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download the distribution: %v", err)
 	}
 
-	volumeName := fmt.Sprintf("Install_macOS_%s-%s", i.Version, i.Build)
-	sparseDiskimagePath := filepath.Join(folder, volumeName+".sparseimage")
+	pInfo.distributionData, err = io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read distribution data: %v", err)
+	}
 
-	if _, err := os.Stat(sparseDiskimagePath); os.IsNotExist(err) {
-		log.Info("Creating empty sparseimage")
-		sparseDiskimagePath, err = utils.CreateSparseDiskImage(volumeName, sparseDiskimagePath)
-		if err != nil {
-			return err
-		}
-		defer os.Remove(sparseDiskimagePath)
+	dist := distribution{}
+	if err := xml.Unmarshal(pInfo.distributionData, &dist); err != nil {
+		return nil, fmt.Errorf("failed decode distribution XML data: %v", err)
 	}
 
-	sparseDiskimageMount := fmt.Sprintf("/tmp/sparseimage_%s-%s", i.Version, i.Build)
-	if _, err := os.Stat(sparseDiskimageMount); os.IsNotExist(err) {
-		log.Infof("Mounting %s", sparseDiskimageMount)
-		if err := utils.Mount(sparseDiskimagePath, sparseDiskimageMount); err != nil {
-			return err
-		}
+	if !strings.EqualFold(dist.Title, "SU_TITLE") {
+		pInfo.Title = dist.Title
 	}
 
-	distPath := filepath.Join(folder, getDestName(i.Product.Distributions["English"], false))
-	if _, err := os.Stat(sparseDiskimagePath); os.IsNotExist(err) {
-		if err := os.WriteFile(distPath, i.distributionData, 0660); err != nil {
-			return err
+	if len(dist.AuxInfo.Keys) > 0 {
+		info, err := zipArrays(dist.AuxInfo.Keys, dist.AuxInfo.Values)
+		if err != nil {
+			return nil, fmt.Errorf("failed to zip distribution auxinfo: %v", err)
 		}
+		pInfo.Build = info["build"]
+		pInfo.Version = info["version"]
 	}
 
-	log.Infof("Creating installer from distribution %s", distPath)
-	if err := utils.CreateInstaller(distPath, sparseDiskimageMount); err != nil {
-		// return err
-		log.Error(err.Error())
-	}
+	return &pInfo, nil
+}
 
-	var appPath string
-	filepath.Walk(sparseDiskimageMount, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-		if filepath.Ext(info.Name()) == ".app" {
-			appPath = path
-		}
-		return nil
-	})
-	if len(appPath) == 0 {
-		return fmt.Errorf("app not found in sparse disk image mount")
+// GetProductInfo downloads and parses the macOS installer product infos
+func GetProductInfo() (ProductInfos, error) {
+	var prods ProductInfos
+
+	cat, err := getSUCatalog()
+	if err != nil {
+		return nil, err
 	}
 
-	// if err := xattr.Set(appPath, "SeedProgram", []byte(sucatalogs20)); err != nil {
-	// 	return err
-	// }
+	for key, prod := range cat.Products {
 
-	dmgPath := filepath.Join(folder, volumeName+".dmg")
-	if _, err := os.Stat(dmgPath); os.IsNotExist(err) {
-		log.Infof("Creating compressed DMG %s", dmgPath)
-		if err := utils.CreateCompressedDMG(appPath, dmgPath); err != nil {
-			return err
+		// filter
+		if len(prod.ExtendedMetaInfo.InstallAssistantPackageIdentifiers) == 0 {
+			continue
 		}
-	}
 
-	if _, err := os.Stat(sparseDiskimageMount); os.IsExist(err) {
-		if err := utils.Unmount(sparseDiskimageMount, false); err != nil {
-			return err
+		pInfo, err := getProductInfo(key, prod)
+		if err != nil {
+			return nil, err
 		}
+
+		prods = append(prods, *pInfo)
 	}
 
-	return nil
+	sort.Slice(prods[:], func(i, j int) bool {
+		return prods[i].PostDate.Before(prods[j].PostDate)
+	})
+
+	return prods, nil
 }
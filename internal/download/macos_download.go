@@ -0,0 +1,145 @@
+//go:build !wasm
+
+package download
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/blacktop/ipsw/internal/utils"
+	"github.com/pkg/errors"
+)
+
+func (i *ProductInfo) DownloadInstaller(workDir, proxy string, insecure, skipAll, resumeAll, restartAll, ignoreSha1, assistantOnly bool) error {
+
+	downloader := NewDownload(proxy, insecure, skipAll, resumeAll, restartAll, ignoreSha1, true)
+
+	folder := filepath.Join(workDir, fmt.Sprintf("%s_%s_%s", strings.ReplaceAll(i.Title, " ", "_"), i.Version, i.Build))
+
+	os.MkdirAll(folder, 0750)
+
+	getLogger().Info("Downloading packages")
+	for _, pkg := range i.Product.Packages {
+		if len(pkg.URL) > 0 {
+			if assistantOnly && !strings.HasSuffix(pkg.URL, "InstallAssistant.pkg") {
+				continue
+			}
+			destName := getDestName(pkg.URL, false)
+			if _, err := os.Stat(filepath.Join(folder, destName)); os.IsNotExist(err) {
+				getLogger().WithFields(log.Fields{
+					"size":     utils.FormatBytes(uint64(pkg.Size)),
+					"destName": destName,
+				}).Info("Getting Package")
+				// download file
+				downloader.URL = pkg.URL
+				downloader.Sha1 = pkg.Digest
+				downloader.DestName = filepath.Join(folder, destName)
+				err = downloader.Do()
+				if err != nil {
+					return errors.Wrap(err, "failed to download file")
+				}
+
+			} else {
+				getLogger().Warnf("pkg already exists: %s", filepath.Join(folder, destName))
+			}
+
+		} else if len(pkg.MetadataURL) > 0 {
+			if assistantOnly && !strings.HasSuffix(pkg.MetadataURL, "InstallAssistant.pkg") {
+				continue
+			}
+			destName := getDestName(pkg.MetadataURL, false)
+			if _, err := os.Stat(filepath.Join(folder, destName)); os.IsNotExist(err) {
+				getLogger().WithFields(log.Fields{
+					"size":     utils.FormatBytes(uint64(pkg.Size)),
+					"destName": destName,
+				}).Info("Getting Package")
+				// download file
+				downloader.URL = pkg.URL
+				downloader.Sha1 = pkg.Digest
+				downloader.DestName = filepath.Join(folder, destName)
+
+				err = downloader.Do()
+				if err != nil {
+					return errors.Wrap(err, "failed to download file")
+				}
+
+			} else {
+				getLogger().Warnf("pkg already exists: %s", filepath.Join(folder, destName))
+			}
+		}
+	}
+
+	if assistantOnly {
+		return nil
+	}
+
+	volumeName := fmt.Sprintf("Install_macOS_%s-%s", i.Version, i.Build)
+	sparseDiskimagePath := filepath.Join(folder, volumeName+".sparseimage")
+
+	if _, err := os.Stat(sparseDiskimagePath); os.IsNotExist(err) {
+		getLogger().Info("Creating empty sparseimage")
+		sparseDiskimagePath, err = utils.CreateSparseDiskImage(volumeName, sparseDiskimagePath)
+		if err != nil {
+			return err
+		}
+		defer os.Remove(sparseDiskimagePath)
+	}
+
+	sparseDiskimageMount := fmt.Sprintf("/tmp/sparseimage_%s-%s", i.Version, i.Build)
+	if _, err := os.Stat(sparseDiskimageMount); os.IsNotExist(err) {
+		getLogger().Infof("Mounting %s", sparseDiskimageMount)
+		if err := utils.Mount(sparseDiskimagePath, sparseDiskimageMount); err != nil {
+			return err
+		}
+	}
+
+	distPath := filepath.Join(folder, getDestName(i.Product.Distributions["English"], false))
+	if _, err := os.Stat(sparseDiskimagePath); os.IsNotExist(err) {
+		if err := os.WriteFile(distPath, i.distributionData, 0660); err != nil {
+			return err
+		}
+	}
+
+	getLogger().Infof("Creating installer from distribution %s", distPath)
+	if err := utils.CreateInstaller(distPath, sparseDiskimageMount); err != nil {
+		// return err
+		getLogger().Error(err.Error())
+	}
+
+	var appPath string
+	filepath.Walk(sparseDiskimageMount, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if filepath.Ext(info.Name()) == ".app" {
+			appPath = path
+		}
+		return nil
+	})
+	if len(appPath) == 0 {
+		return fmt.Errorf("app not found in sparse disk image mount")
+	}
+
+	// if err := xattr.Set(appPath, "SeedProgram", []byte(sucatalogs20)); err != nil {
+	// 	return err
+	// }
+
+	dmgPath := filepath.Join(folder, volumeName+".dmg")
+	if _, err := os.Stat(dmgPath); os.IsNotExist(err) {
+		getLogger().Infof("Creating compressed DMG %s", dmgPath)
+		if err := utils.CreateCompressedDMG(appPath, dmgPath); err != nil {
+			return err
+		}
+	}
+
+	if _, err := os.Stat(sparseDiskimageMount); os.IsExist(err) {
+		if err := utils.Unmount(sparseDiskimageMount, false); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
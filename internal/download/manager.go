@@ -0,0 +1,400 @@
+//go:build !wasm
+
+package download
+
+import (
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"sync"
+
+	"github.com/blacktop/ipsw/internal/utils"
+	"github.com/vbauerster/mpb/v7"
+)
+
+// Manager is a queue of downloads that can be paused, resumed and canceled individually
+//
+// Up to workers downloads run at once (and at most the host limit from the same host);
+// queued downloads start in the order they were added. Only the last retention
+// finished downloads are kept (see SetRetention and Remove).
+type Manager struct {
+	mu        sync.Mutex
+	cond      *sync.Cond
+	nextID    int
+	order     []string
+	jobs      map[string]*Download
+	pending   []*Download
+	active    map[string]int // running downloads by host
+	hostLimit int
+	retention int
+	progress  *utils.Progress
+	wg        sync.WaitGroup
+	closed    bool
+}
+
+// DefaultRetention is the number of finished downloads a Manager keeps by default
+const DefaultRetention = 100
+
+var (
+	defaultManager     *Manager
+	defaultManagerOnce sync.Once
+)
+
+// NewManager creates a download manager that runs up to workers downloads at once
+func NewManager(workers int) *Manager {
+	if workers < 1 {
+		workers = 1
+	}
+	m := &Manager{
+		jobs:      make(map[string]*Download),
+		active:    make(map[string]int),
+		retention: DefaultRetention,
+	}
+	m.cond = sync.NewCond(&m.mu)
+	for i := 0; i < workers; i++ {
+		go m.worker()
+	}
+	return m
+}
+
+// DefaultManager returns the process wide download manager (used by the daemon and C API)
+func DefaultManager() *Manager {
+	defaultManagerOnce.Do(func() {
+		defaultManager = NewManager(2)
+	})
+	return defaultManager
+}
+
+// SetHostLimit limits the number of downloads from the same host that run at once (0 is unlimited)
+func (m *Manager) SetHostLimit(limit int) {
+	m.mu.Lock()
+	m.hostLimit = limit
+	m.mu.Unlock()
+	m.cond.Broadcast()
+}
+
+// SetRetention sets the number of finished, failed and canceled downloads that are kept
+// (the oldest are removed first; a negative limit keeps them all)
+func (m *Manager) SetRetention(limit int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.retention = limit
+	m.prune()
+}
+
+func finished(d *Download) bool {
+	switch d.State() {
+	case StateDone, StateFailed, StateCanceled:
+		return true
+	}
+	return false
+}
+
+// prune removes the oldest finished downloads above the retention limit (the caller must hold m.mu)
+func (m *Manager) prune() {
+	if m.retention < 0 {
+		return
+	}
+	var n int
+	for _, id := range m.order {
+		if finished(m.jobs[id]) {
+			n++
+		}
+	}
+	for i := 0; n > m.retention && i < len(m.order); {
+		id := m.order[i]
+		if !finished(m.jobs[id]) {
+			i++
+			continue
+		}
+		delete(m.jobs, id)
+		m.order = append(m.order[:i], m.order[i+1:]...)
+		n--
+	}
+}
+
+// ShowProgress renders the progress bars of the queued downloads in one container,
+// in the order they were added (call before adding downloads; Wait waits for the bars)
+func (m *Manager) ShowProgress() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.progress == nil {
+		m.progress = utils.NewProgress("downloads")
+	}
+}
+
+func hostOf(d *Download) string {
+	if u, err := url.Parse(d.URL); err == nil {
+		return u.Host
+	}
+	return ""
+}
+
+// next removes and returns the first pending download that isn't paused and whose host is below
+// the host limit (the caller must hold m.mu)
+func (m *Manager) next() *Download {
+	for i, d := range m.pending {
+		if d.State() == StatePaused {
+			continue
+		}
+		if m.hostLimit > 0 && m.active[hostOf(d)] >= m.hostLimit {
+			continue
+		}
+		m.pending = append(m.pending[:i], m.pending[i+1:]...)
+		return d
+	}
+	return nil
+}
+
+func (m *Manager) worker() {
+	for {
+		m.mu.Lock()
+		d := m.next()
+		for d == nil {
+			if m.closed {
+				m.mu.Unlock()
+				return
+			}
+			m.cond.Wait()
+			d = m.next()
+		}
+		host := hostOf(d)
+		m.active[host]++
+		m.mu.Unlock()
+
+		if d.State() != StateCanceled {
+			d.Do()
+		}
+
+		m.mu.Lock()
+		if m.active[host]--; m.active[host] == 0 {
+			delete(m.active, host)
+		}
+		m.prune()
+		m.mu.Unlock()
+		m.cond.Broadcast()
+		m.wg.Done()
+	}
+}
+
+// Add queues a download and returns its ID
+func (m *Manager) Add(d *Download) string {
+	m.mu.Lock()
+	m.nextID++
+	id := strconv.Itoa(m.nextID)
+	m.order = append(m.order, id)
+	m.jobs[id] = d
+	if m.progress != nil {
+		d.shareProgress(m.progress, m.nextID)
+	}
+	d.setState(StateQueued)
+	m.wg.Add(1)
+	m.pending = append(m.pending, d)
+	m.mu.Unlock()
+	m.cond.Signal()
+
+	return id
+}
+
+// Get returns the download with the given ID
+func (m *Manager) Get(id string) (*Download, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if d, ok := m.jobs[id]; ok {
+		return d, nil
+	}
+	return nil, fmt.Errorf("download %s not found: %w", id, ErrInvalidArgument)
+}
+
+// Status returns the status of the download with the given ID
+func (m *Manager) Status(id string) (*DownloadStatus, error) {
+	d, err := m.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	status := d.Status()
+	status.ID = id
+	return &status, nil
+}
+
+// List returns the status of all downloads in the order they were added
+func (m *Manager) List() []DownloadStatus {
+	m.mu.Lock()
+	m.prune()
+	ids := append([]string(nil), m.order...)
+	m.mu.Unlock()
+
+	var statuses []DownloadStatus
+	for _, id := range ids {
+		if status, err := m.Status(id); err == nil {
+			statuses = append(statuses, *status)
+		}
+	}
+	return statuses
+}
+
+// Pause pauses the download with the given ID
+func (m *Manager) Pause(id string) error {
+	d, err := m.Get(id)
+	if err != nil {
+		return err
+	}
+	return d.Pause()
+}
+
+// Resume resumes the download with the given ID
+func (m *Manager) Resume(id string) error {
+	d, err := m.Get(id)
+	if err != nil {
+		return err
+	}
+	if err := d.Resume(); err != nil {
+		return err
+	}
+	m.cond.Broadcast() // a paused download that never started can be picked up again
+	return nil
+}
+
+// SetProgressFunc sets the progress callback of the download with the given ID
+func (m *Manager) SetProgressFunc(id string, fn ProgressFunc) error {
+	d, err := m.Get(id)
+	if err != nil {
+		return err
+	}
+	d.SetProgressFunc(fn)
+	return nil
+}
+
+// Cancel cancels the download with the given ID
+func (m *Manager) Cancel(id string) error {
+	d, err := m.Get(id)
+	if err != nil {
+		return err
+	}
+	if err := d.Cancel(); err != nil {
+		return err
+	}
+	m.cond.Broadcast() // a paused download that never started is removed from the queue
+	return nil
+}
+
+// Remove removes the finished, failed or canceled download with the given ID
+func (m *Manager) Remove(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	d, ok := m.jobs[id]
+	if !ok {
+		return fmt.Errorf("download %s not found: %w", id, ErrInvalidArgument)
+	}
+	if !finished(d) {
+		return fmt.Errorf("download %s is %s (cancel it first): %w", id, d.State(), ErrInvalidArgument)
+	}
+	delete(m.jobs, id)
+	for i, oid := range m.order {
+		if oid == id {
+			m.order = append(m.order[:i], m.order[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+// CancelAll cancels the queued and running downloads
+func (m *Manager) CancelAll() {
+	m.mu.Lock()
+	jobs := make([]*Download, 0, len(m.jobs))
+	for _, id := range m.order {
+		jobs = append(jobs, m.jobs[id])
+	}
+	m.mu.Unlock()
+	for _, d := range jobs {
+		d.Cancel() // finished downloads can't be canceled
+	}
+	m.cond.Broadcast()
+}
+
+// Close cancels the queued and running downloads and stops the workers once they have finished
+// (downloads added afterwards never start)
+func (m *Manager) Close() {
+	m.CancelAll()
+	m.mu.Lock()
+	m.closed = true
+	m.mu.Unlock()
+	m.cond.Broadcast()
+}
+
+// Wait blocks until all queued downloads have finished, failed or been canceled
+func (m *Manager) Wait() {
+	m.wg.Wait()
+	m.mu.Lock()
+	p := m.progress
+	m.progress = nil
+	m.mu.Unlock()
+	if p != nil {
+		p.Wait()
+	}
+}
+
+// Errors returns the errors of the failed downloads by ID (in the order they were added)
+func (m *Manager) Errors() []error {
+	var errs []error
+	for _, status := range m.List() {
+		if status.State == StateFailed {
+			errs = append(errs, fmt.Errorf("download %s (%s) failed: %s", status.ID, status.URL, status.Error))
+		}
+	}
+	return errs
+}
+
+// shareProgress renders the download's progress bar in the Manager's container at position priority
+func (d *Download) shareProgress(p *utils.Progress, priority int) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.progress = p
+	d.priority = priority
+}
+
+// newProgress returns the container for the download's progress bar
+func (d *Download) newProgress() *utils.Progress {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.progress != nil {
+		return d.progress
+	}
+	if d.OnProgress != nil { // reported to the callback instead
+		return utils.NewProgress(filepath.Base(d.DestName), mpb.WithOutput(nil))
+	}
+	return utils.NewProgress(filepath.Base(d.DestName))
+}
+
+// waitProgress waits for the download's progress bar to finish rendering (shared containers
+// are waited on by their Manager)
+func (d *Download) waitProgress(p *utils.Progress) {
+	d.mu.Lock()
+	shared := p == d.progress
+	d.mu.Unlock()
+	if !shared {
+		p.Wait()
+	}
+}
+
+// barPriority keeps queued downloads' progress bars in the order they were added
+func (d *Download) barPriority() mpb.BarOption {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.progress == nil {
+		return nil
+	}
+	return mpb.BarPriority(d.priority)
+}
+
+// barName labels queued downloads' progress bars with their filename
+func (d *Download) barName() string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.progress == nil {
+		return ""
+	}
+	return filepath.Base(d.DestName) + " "
+}
@@ -0,0 +1,143 @@
+//go:build ffi
+
+package download
+
+//#cgo LDFLAGS:
+//#include <stdio.h>
+//#include <stdlib.h>
+//#include <string.h>
+//
+//typedef void (*ipsw_progress_cb)(const char *id, long long done, long long total, double speed, long long eta_ms, void *user_data);
+//
+//static inline void call_ipsw_progress_cb(ipsw_progress_cb cb, const char *id, long long done, long long total, double speed, long long eta_ms, void *user_data) {
+//	cb(id, done, total, speed, eta_ms, user_data);
+//}
+import "C"
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"path/filepath"
+	"unsafe"
+)
+
+// setCError sets the malloc'd error message of a failed call and records the ErrorCode of its cause
+// (returned by c_libipsw_error_code)
+func setCError(cause error, msg string, err **C.char, errLen *C.uint) C.char {
+	*err = C.CString(msg)
+	*errLen = C.uint(len(msg))
+	code := ErrorCodeOf(cause)
+	if code == ErrCodeOK {
+		code = ErrCodeUnknown
+	}
+	cErrorCodes.Store(uintptr(unsafe.Pointer(*err)), code)
+	return C.char(0)
+}
+
+// setCJSON serializes v into a malloc'd JSON string (name and typ are used in the error message)
+func setCJSON(v any, outJson **C.char, outJsonLen *C.uint, err **C.char, errLen *C.uint, name, typ string) C.char {
+	fret, jsonErr := json.Marshal(v)
+	if jsonErr != nil {
+		return setCError(jsonErr, fmt.Sprintf("%s: Failed to serialize %s: %v", name, typ, jsonErr), err, errLen)
+	}
+	cs := C.CString(string(fret))
+	*outJson = cs
+	*outJsonLen = C.uint(C.strlen(cs))
+	return C.char(1)
+}
+
+//export c_internal_download_manager_Add
+func c_internal_download_manager_Add(url *C.char, urlLen C.uint, dest *C.char, destLen C.uint, sha1 *C.char, sha1Len C.uint,
+	proxy *C.char, proxyLen C.uint, insecure C.char, outID **C.char, outIDLen *C.uint, err **C.char, errLen *C.uint) C.char {
+	d := NewDownload(C.GoStringN(proxy, C.int(proxyLen)), insecure == 1, false, true, false, false, false)
+	d.URL = C.GoStringN(url, C.int(urlLen))
+	d.Sha1 = C.GoStringN(sha1, C.int(sha1Len))
+	d.DestName = C.GoStringN(dest, C.int(destLen))
+	if len(d.URL) == 0 {
+		return setCError(ErrInvalidArgument, "c_Add: url is required", err, errLen)
+	}
+	if len(d.DestName) == 0 {
+		d.DestName = path.Base(d.URL)
+	}
+	d.DestName = filepath.Clean(d.DestName)
+
+	id := DefaultManager().Add(d)
+
+	cs := C.CString(id)
+	*outID = cs
+	*outIDLen = C.uint(C.strlen(cs))
+	return C.char(1)
+}
+
+//export c_internal_download_manager_Pause
+func c_internal_download_manager_Pause(id *C.char, idLen C.uint, err **C.char, errLen *C.uint) C.char {
+	if perr := DefaultManager().Pause(C.GoStringN(id, C.int(idLen))); perr != nil {
+		return setCError(perr, fmt.Sprintf("c_Pause: Pause failed with %v", perr), err, errLen)
+	}
+	return C.char(1)
+}
+
+//export c_internal_download_manager_Resume
+func c_internal_download_manager_Resume(id *C.char, idLen C.uint, err **C.char, errLen *C.uint) C.char {
+	if rerr := DefaultManager().Resume(C.GoStringN(id, C.int(idLen))); rerr != nil {
+		return setCError(rerr, fmt.Sprintf("c_Resume: Resume failed with %v", rerr), err, errLen)
+	}
+	return C.char(1)
+}
+
+// c_internal_download_manager_SetProgressCallback calls cb with the progress of the download
+// (bytes done, total bytes, bytes per second and the ETA in milliseconds) at most every 250ms;
+// user_data is passed back to cb as is and the id is only valid for the duration of the call
+//
+//export c_internal_download_manager_SetProgressCallback
+func c_internal_download_manager_SetProgressCallback(id *C.char, idLen C.uint, cb C.ipsw_progress_cb, userData unsafe.Pointer, err **C.char, errLen *C.uint) C.char {
+	goID := C.GoStringN(id, C.int(idLen))
+	var fn ProgressFunc
+	if cb != nil {
+		fn = func(p Progress) {
+			cid := C.CString(goID)
+			defer C.free(unsafe.Pointer(cid))
+			C.call_ipsw_progress_cb(cb, cid, C.longlong(p.Done), C.longlong(p.Total), C.double(p.Speed), C.longlong(p.ETA.Milliseconds()), userData)
+		}
+	}
+	if serr := DefaultManager().SetProgressFunc(goID, fn); serr != nil {
+		return setCError(serr, fmt.Sprintf("c_SetProgressCallback: SetProgressCallback failed with %v", serr), err, errLen)
+	}
+	return C.char(1)
+}
+
+//export c_internal_download_manager_Cancel
+func c_internal_download_manager_Cancel(id *C.char, idLen C.uint, err **C.char, errLen *C.uint) C.char {
+	if cerr := DefaultManager().Cancel(C.GoStringN(id, C.int(idLen))); cerr != nil {
+		return setCError(cerr, fmt.Sprintf("c_Cancel: Cancel failed with %v", cerr), err, errLen)
+	}
+	return C.char(1)
+}
+
+//export c_internal_download_manager_Status
+func c_internal_download_manager_Status(id *C.char, idLen C.uint, outJson **C.char, outJsonLen *C.uint, err **C.char, errLen *C.uint) C.char {
+	status, serr := DefaultManager().Status(C.GoStringN(id, C.int(idLen)))
+	if serr != nil {
+		return setCError(serr, fmt.Sprintf("c_Status: Status failed with %v", serr), err, errLen)
+	}
+	fret, jsonErr := json.Marshal(status)
+	if jsonErr != nil {
+		return setCError(jsonErr, fmt.Sprintf("c_Status: Failed to serialize DownloadStatus object: %v", jsonErr), err, errLen)
+	}
+	cs := C.CString(string(fret))
+	*outJson = cs
+	*outJsonLen = C.uint(C.strlen(cs))
+	return C.char(1)
+}
+
+//export c_internal_download_manager_List
+func c_internal_download_manager_List(outJson **C.char, outJsonLen *C.uint, err **C.char, errLen *C.uint) C.char {
+	fret, jsonErr := json.Marshal(DefaultManager().List())
+	if jsonErr != nil {
+		return setCError(jsonErr, fmt.Sprintf("c_List: Failed to serialize DownloadStatus objects: %v", jsonErr), err, errLen)
+	}
+	cs := C.CString(string(fret))
+	*outJson = cs
+	*outJsonLen = C.uint(C.strlen(cs))
+	return C.char(1)
+}
@@ -0,0 +1,94 @@
+//go:build !wasm
+
+package download
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ManifestEntry is a downloaded file in a checksum manifest
+type ManifestEntry struct {
+	Name   string `json:"name"`
+	Device string `json:"device,omitempty"`
+	Build  string `json:"build,omitempty"`
+	Size   int64  `json:"size"`
+	SHA1   string `json:"sha1"`
+	SHA256 string `json:"sha256"`
+	URL    string `json:"url,omitempty"`
+}
+
+var manifestHeader = []string{"name", "device", "build", "size", "sha1", "sha256", "url"}
+
+// NewManifestEntry hashes a downloaded file into a manifest entry
+//
+// The file is read once: sha256 is always computed, sha1 only when the download's verification did not already check it
+func NewManifestEntry(path, device, build, url string, v *Verification) (*ManifestEntry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %v", path, err)
+	}
+	defer f.Close()
+
+	e := &ManifestEntry{
+		Name:   filepath.Base(path),
+		Device: device,
+		Build:  build,
+		URL:    url,
+	}
+
+	h256 := sha256.New()
+	var w io.Writer = h256
+	h1 := sha1.New()
+	if v != nil && v.Verified && v.Algorithm == "sha1" {
+		e.SHA1 = v.Actual
+	} else {
+		w = io.MultiWriter(h256, h1)
+	}
+	if e.Size, err = io.Copy(w, f); err != nil {
+		return nil, fmt.Errorf("failed to hash %s: %v", path, err)
+	}
+	e.SHA256 = hex.EncodeToString(h256.Sum(nil))
+	if len(e.SHA1) == 0 {
+		e.SHA1 = hex.EncodeToString(h1.Sum(nil))
+	}
+	return e, nil
+}
+
+// WriteManifest writes the manifest entries to path as CSV (.csv extension) or JSON (anything else)
+func WriteManifest(path string, entries []*ManifestEntry) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create manifest %s: %v", path, err)
+	}
+	defer f.Close()
+
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		w := csv.NewWriter(f)
+		w.Write(manifestHeader)
+		for _, e := range entries {
+			w.Write([]string{e.Name, e.Device, e.Build, strconv.FormatInt(e.Size, 10), e.SHA1, e.SHA256, e.URL})
+		}
+		w.Flush()
+		if err := w.Error(); err != nil {
+			return fmt.Errorf("failed to write manifest %s: %v", path, err)
+		}
+		return nil
+	}
+
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(entries); err != nil {
+		return fmt.Errorf("failed to write manifest %s: %v", path, err)
+	}
+	return nil
+}
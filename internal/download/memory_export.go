@@ -0,0 +1,48 @@
+//go:build ffi
+
+package download
+
+//#cgo LDFLAGS:
+//#include <stdlib.h>
+//
+// /*
+//  * Memory ownership
+//  *
+//  * Every char* returned through an out parameter (outJson, outID, outHandle, outResult, err, ...)
+//  * is allocated by libipsw with malloc and is owned by the caller: release it with
+//  * c_libipsw_free_string once it has been read (NULL is ignored). The matching *Len out
+//  * parameter is the length of the string without its NUL terminator.
+//  *
+//  * Out parameters are only set when the function says so: on success (1) the outputs are set
+//  * and err is not, on failure (0) only err is set.
+//  *
+//  * Buffers passed in by the caller (i.e. to c_libipsw_result_read) stay owned by the caller,
+//  * and strings passed to callbacks (i.e. the id of ipsw_progress_cb) are only valid for the
+//  * duration of the call.
+//  *
+//  * Handles and result IDs are strings too (free them with c_libipsw_free_string), but the state
+//  * they refer to is released separately with c_libipsw_handle_free and c_libipsw_result_free.
+//  */
+import "C"
+import "unsafe"
+
+// c_libipsw_free_string frees a string returned by a libipsw function
+//
+//export c_libipsw_free_string
+func c_libipsw_free_string(s *C.char) {
+	if s != nil {
+		forgetCError(s)
+		C.free(unsafe.Pointer(s))
+	}
+}
+
+// c_libipsw_free_buffer frees a buffer allocated by libipsw (for bindings that
+// track the returned strings as raw byte buffers)
+//
+//export c_libipsw_free_buffer
+func c_libipsw_free_buffer(buf unsafe.Pointer) {
+	if buf != nil {
+		forgetCError((*C.char)(buf))
+		C.free(buf)
+	}
+}
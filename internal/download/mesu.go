@@ -0,0 +1,102 @@
+package download
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/blacktop/go-plist"
+	"github.com/blacktop/ipsw/internal/utils"
+)
+
+const mesuAssetsURL = "https://mesu.apple.com/assets"
+
+// MesuAssetTypes are the MobileAsset types that are published as public mesu catalogs
+var MesuAssetTypes = []string{
+	"com.apple.MobileAsset.SoftwareUpdate",
+	"com.apple.MobileAsset.SoftwareUpdateDocumentation",
+	"com.apple.MobileAsset.SplatSoftwareUpdate",
+	"com.apple.MobileAsset.MacSoftwareUpdate",
+	"com.apple.MobileAsset.MacSplatSoftwareUpdate",
+	"com.apple.MobileAsset.SFRSoftwareUpdate",
+	"com.apple.MobileAsset.RecoveryOSUpdate",
+	"com.apple.MobileAsset.WatchSoftwareUpdateDocumentation",
+	"com.apple.MobileAsset.MobileAccessoryUpdate.A2032_EA",
+	"com.apple.MobileAsset.MobileAccessoryUpdate.A2084_EA",
+	"com.apple.MobileAsset.MobileAccessoryUpdate.A2564_EA",
+	"com.apple.MobileAsset.MobileAccessoryUpdate.DurianFirmware",
+	"com.apple.MobileAsset.Font7",
+	"com.apple.MobileAsset.DictionaryServices.dictionaryOSX",
+	"com.apple.MobileAsset.TimeZoneUpdate",
+	"com.apple.MobileAsset.CoreLocationConfig",
+}
+
+// MesuCatalog is a public mesu.apple.com MobileAsset catalog
+type MesuCatalog struct {
+	AssetType string           `json:"asset_type,omitempty"`
+	URL       string           `json:"url,omitempty"`
+	Assets    []map[string]any `plist:"Assets,omitempty" json:"assets,omitempty"`
+}
+
+// MesuCatalogURL returns the public mesu catalog URL for a MobileAsset type
+func MesuCatalogURL(assetType string) string {
+	name := strings.ReplaceAll(assetType, ".", "_")
+	return fmt.Sprintf("%s/%s/%s.xml", mesuAssetsURL, name, name)
+}
+
+// GetMesuCatalog downloads and parses the public mesu catalog for a MobileAsset type
+//
+// NOTE: returns a nil catalog (and no error) if the catalog is not published
+func GetMesuCatalog(assetType, proxy string, insecure bool) (*MesuCatalog, error) {
+	cat := MesuCatalog{
+		AssetType: assetType,
+		URL:       MesuCatalogURL(assetType),
+	}
+
+	req, err := http.NewRequest("GET", cat.URL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create http request: %v", err)
+	}
+	req.Header.Set("User-Agent", utils.RandomAgent())
+
+	client := &http.Client{
+		Transport: RetryTransport(NewTransport(proxy, insecure)),
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to GET %s: %v", cat.URL, err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound, http.StatusForbidden:
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("failed to GET %s: %s", cat.URL, resp.Status)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %v", cat.URL, err)
+	}
+
+	if err := plist.NewDecoder(bytes.NewReader(body)).Decode(&cat); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %v", cat.URL, err)
+	}
+
+	return &cat, nil
+}
+
+// AssetURL returns the download URL of a mesu catalog asset
+func AssetURL(asset map[string]any) string {
+	base, _ := asset["__BaseURL"].(string)
+	rel, _ := asset["__RelativePath"].(string)
+	if len(base) == 0 && len(rel) == 0 {
+		return ""
+	}
+	return strings.TrimSuffix(base, "/") + "/" + strings.TrimPrefix(rel, "/")
+}
@@ -0,0 +1,58 @@
+package download
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// MetadataSource resolves device and build lookups without the network (i.e. the local DB synced by `ipsw db sync`)
+type MetadataSource interface {
+	GetDevice(identifier string) (Device, error)
+	GetBuild(buildID string) (IPSW, error)
+	GetBuildID(version, identifier string) (string, error)
+}
+
+var metadata struct {
+	sync.Mutex
+	src     MetadataSource
+	offline bool
+}
+
+// SetMetadataSource makes GetDevice, GetBuild, GetBuildID and GetVersion resolve from src first
+// (when offline is true they never fall back to the ipsw.me API, even without a src)
+func SetMetadataSource(src MetadataSource, offline bool) {
+	metadata.Lock()
+	defer metadata.Unlock()
+	metadata.src = src
+	metadata.offline = offline
+}
+
+type noMetadataKey struct{}
+
+// withoutMetadata returns a copy of ctx whose lookups always go to the network (see fromMetadata)
+func withoutMetadata(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noMetadataKey{}, true)
+}
+
+// fromMetadata resolves a lookup from the metadata source; ok is false if the lookup should go to the network
+func fromMetadata[T any](ctx context.Context, what string, lookup func(MetadataSource) (T, error)) (res T, err error, ok bool) {
+	if skip, _ := ctx.Value(noMetadataKey{}).(bool); skip {
+		return res, nil, false
+	}
+	metadata.Lock()
+	src, offline := metadata.src, metadata.offline
+	metadata.Unlock()
+	if src == nil {
+		if offline {
+			return res, fmt.Errorf("cannot resolve %s offline without a metadata DB (run `ipsw db sync` first)", what), true
+		}
+		return res, nil, false
+	}
+	res, err = lookup(src)
+	if err == nil || offline {
+		return res, err, true
+	}
+	getLogger().Debugf("failed to resolve %s from metadata DB (using the ipsw.me API): %v", what, err)
+	return res, nil, false
+}
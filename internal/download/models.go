@@ -0,0 +1,175 @@
+package download
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/blacktop/ipsw/pkg/info"
+)
+
+const modelsPage = "Models"
+
+var (
+	wikiLinkRegex    = regexp.MustCompile(`\[\[(?:[^\]|]*\|)?([^\]]*)\]\]`)
+	wikiBreakRegex   = regexp.MustCompile(`(?i)<br\s*/?>`)
+	wikiRefRegex     = regexp.MustCompile(`(?s)<ref[^>]*>.*?</ref>|<ref[^>]*/>`)
+	wikiTagRegex     = regexp.MustCompile(`<[^>]+>`)
+	wikiAttrRegex    = regexp.MustCompile(`^\s*(?:[a-zA-Z-]+\s*=\s*(?:"[^"]*"|[^\s|"]+)\s*)+\|`)
+	wikiRowspanRegex = regexp.MustCompile(`rowspan\s*=\s*"?\s*(\d+)`)
+	aNumRegex        = regexp.MustCompile(`A\d{4}`)
+)
+
+type wikiCell struct {
+	Value string
+	Span  int
+}
+
+func parseWikiCell(cell string) wikiCell {
+	c := wikiCell{Span: 1}
+	if attr := wikiAttrRegex.FindString(cell); len(attr) > 0 {
+		if m := wikiRowspanRegex.FindStringSubmatch(attr); m != nil {
+			if span, err := strconv.Atoi(m[1]); err == nil && span > 0 {
+				c.Span = span
+			}
+		}
+		cell = strings.TrimPrefix(cell, attr)
+	}
+	cell = wikiRefRegex.ReplaceAllString(cell, "")
+	cell = wikiLinkRegex.ReplaceAllString(cell, "$1")
+	cell = wikiBreakRegex.ReplaceAllString(cell, "\n")
+	cell = wikiTagRegex.ReplaceAllString(cell, "")
+	cell = strings.NewReplacer("'''", "", "''", "").Replace(cell)
+	c.Value = strings.TrimSpace(cell)
+	return c
+}
+
+func wikiLines(value string) []string {
+	var lines []string
+	for _, line := range strings.Split(value, "\n") {
+		if line = strings.TrimSpace(line); len(line) > 0 {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// parseWikiModels parses the wikitables on theapplewiki.com Models page
+func parseWikiModels(text string) []info.Model {
+	var models []info.Model
+
+	var headers []string
+	var cells []wikiCell
+	pending := make(map[int]*wikiCell)
+
+	flushRow := func() {
+		if len(cells) == 0 || len(headers) == 0 {
+			cells = nil
+			return
+		}
+		// columns still covered by a rowspan from a previous row are filled in first
+		values := make(map[string]string)
+		for col := 0; col < len(headers); col++ {
+			if p, ok := pending[col]; ok && p.Span > 0 {
+				values[headers[col]] = p.Value
+				p.Span--
+				continue
+			}
+			if len(cells) == 0 {
+				continue
+			}
+			c := cells[0]
+			cells = cells[1:]
+			values[headers[col]] = c.Value
+			if c.Span > 1 {
+				pending[col] = &wikiCell{Value: c.Value, Span: c.Span - 1}
+			} else {
+				delete(pending, col)
+			}
+		}
+		cells = nil
+
+		var m info.Model
+		for header, value := range values {
+			switch {
+			case strings.Contains(header, "generation"):
+				if lines := wikiLines(value); len(lines) > 0 {
+					m.Generation = lines[0]
+				}
+			case strings.HasPrefix(header, "a number"):
+				m.ANumbers = aNumRegex.FindAllString(value, -1)
+			case strings.HasPrefix(header, "fcc"):
+				m.FCCIDs = strings.Fields(value)
+			case strings.HasPrefix(header, "internal name"):
+				if lines := wikiLines(value); len(lines) > 0 {
+					m.InternalName = strings.ToUpper(lines[0])
+				}
+			case header == "model":
+				for _, field := range strings.Fields(value) {
+					if part, _, err := info.ParseModelNumber(field); err == nil {
+						m.ModelNumbers = append(m.ModelNumbers, part)
+					}
+				}
+			}
+		}
+		// a single row can cover multiple identifiers (i.e. iPad7,11 iPad7,12)
+		for _, ident := range strings.Fields(strings.ReplaceAll(values["identifier"], ",\n", ",")) {
+			if !strings.Contains(ident, ",") {
+				continue
+			}
+			im := m
+			im.Identifier = ident
+			models = append(models, im)
+		}
+	}
+
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(line, "{|"):
+			headers = nil
+			cells = nil
+			pending = make(map[int]*wikiCell)
+		case strings.HasPrefix(line, "|}"):
+			flushRow()
+			headers = nil
+		case strings.HasPrefix(line, "|-"):
+			flushRow()
+		case strings.HasPrefix(line, "!"):
+			for _, h := range strings.Split(strings.TrimPrefix(line, "!"), "!!") {
+				h = parseWikiCell(h).Value
+				headers = append(headers, strings.ToLower(strings.TrimSpace(strings.ReplaceAll(h, `"`, ""))))
+			}
+		case strings.HasPrefix(line, "|"):
+			for _, c := range strings.Split(strings.TrimPrefix(line, "|"), "||") {
+				cells = append(cells, parseWikiCell(c))
+			}
+		default:
+			// multi-line cell
+			if len(cells) > 0 && len(line) > 0 {
+				last := &cells[len(cells)-1]
+				last.Value = strings.TrimSpace(last.Value + "\n" + parseWikiCell(line).Value)
+			}
+		}
+	}
+
+	return models
+}
+
+// GetWikiModels queries theapplewiki.com for the model number (A-number, order number) to identifier mappings
+func GetWikiModels(proxy string, insecure bool) ([]info.Model, error) {
+	wtable, err := getWikiTable(modelsPage, proxy, insecure)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get wikitable for %s: %w", modelsPage, err)
+	}
+
+	models := parseWikiModels(wtable.Parse.WikiText.Text)
+	if len(models) == 0 {
+		return nil, fmt.Errorf("failed to parse any models from %s page", modelsPage)
+	}
+
+	getLogger().Debugf("Parsed %d models from %s page", len(models), modelsPage)
+
+	return models, nil
+}
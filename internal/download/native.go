@@ -0,0 +1,25 @@
+//go:build !wasm
+
+package download
+
+import (
+	"net/http"
+	"slices"
+)
+
+// the native counterparts of wasm.go
+
+func init() {
+	sources = slices.Insert(sources, 1, "developer.apple.com") // the dev portal needs the Download engine
+}
+
+// sharedTransport is the base transport of the clients without their own proxy or TLS settings
+// (so that they also honor ALL_PROXY, socks5 proxies, the SetProxyAuth credentials and SetTransport)
+var sharedTransport http.RoundTripper = transportFunc(func(req *http.Request) (*http.Response, error) {
+	return NewTransport("", false).RoundTrip(req)
+})
+
+// cancelManagerDownload cancels a download of the default Manager (CancelHandle falls back to it)
+func cancelManagerDownload(id string) error {
+	return DefaultManager().Cancel(id)
+}
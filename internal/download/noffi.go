@@ -0,0 +1,6 @@
+//go:build !ffi || !cgo
+
+package download
+
+// ffiEnabled reports whether the package was built with the ffi tag and cgo (and so exports the C API)
+const ffiEnabled = false
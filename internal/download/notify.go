@@ -0,0 +1,138 @@
+package download
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/blacktop/ipsw/internal/utils"
+)
+
+// WatchFile is a file of a newly found dev portal item
+type WatchFile struct {
+	Name string `json:"name"`
+	Size int64  `json:"size,omitempty"`
+	URL  string `json:"url"`
+}
+
+// WatchEvent is a newly found dev portal item that matched a watch pattern
+type WatchEvent struct {
+	Name  string      `json:"name"`
+	Type  string      `json:"type"` // os or more
+	Found time.Time   `json:"found"`
+	Files []WatchFile `json:"files"`
+}
+
+func (e *WatchEvent) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "New dev portal download: %s\n", e.Name)
+	for _, f := range e.Files {
+		if f.Size > 0 {
+			fmt.Fprintf(&sb, "  • %s (%s) %s\n", f.Name, utils.FormatBytes(uint64(f.Size)), f.URL)
+		} else {
+			fmt.Fprintf(&sb, "  • %s %s\n", f.Name, f.URL)
+		}
+	}
+	return sb.String()
+}
+
+// Notifier is notified when Watch finds a new dev portal item
+type Notifier interface {
+	Notify(*WatchEvent) error
+}
+
+// NotifyConfig is the config for the notifiers
+type NotifyConfig struct {
+	WebhookURL        string // generic webhook (the WatchEvent is POSTed as JSON)
+	SlackWebhookURL   string
+	DiscordWebhookURL string
+	Proxy             string
+	Insecure          bool
+}
+
+// NewNotifiers returns the notifiers that are configured
+func NewNotifiers(conf *NotifyConfig) []Notifier {
+	client := &http.Client{
+		Timeout:   30 * time.Second,
+		Transport: NewTransport(conf.Proxy, conf.Insecure),
+	}
+	var notifiers []Notifier
+	if len(conf.WebhookURL) > 0 {
+		notifiers = append(notifiers, &WebhookNotifier{URL: conf.WebhookURL, client: client})
+	}
+	if len(conf.SlackWebhookURL) > 0 {
+		notifiers = append(notifiers, &SlackNotifier{URL: conf.SlackWebhookURL, client: client})
+	}
+	if len(conf.DiscordWebhookURL) > 0 {
+		notifiers = append(notifiers, &DiscordNotifier{URL: conf.DiscordWebhookURL, client: client})
+	}
+	return notifiers
+}
+
+func postJSON(client *http.Client, url string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to marshal notification: %v", err)
+	}
+	resp, err := client.Post(url, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to POST notification: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("failed to POST notification: %s", resp.Status)
+	}
+	return nil
+}
+
+// WebhookNotifier POSTs the WatchEvent as JSON to a URL
+type WebhookNotifier struct {
+	URL    string
+	client *http.Client
+}
+
+func (n *WebhookNotifier) Notify(e *WatchEvent) error {
+	return postJSON(n.client, n.URL, e)
+}
+
+// SlackNotifier posts to a Slack incoming webhook
+type SlackNotifier struct {
+	URL    string
+	client *http.Client
+}
+
+func (n *SlackNotifier) Notify(e *WatchEvent) error {
+	return postJSON(n.client, n.URL, map[string]string{"text": e.String()})
+}
+
+// DiscordNotifier posts an embed to a Discord webhook
+type DiscordNotifier struct {
+	URL    string
+	client *http.Client
+}
+
+func (n *DiscordNotifier) Notify(e *WatchEvent) error {
+	var fields []map[string]any
+	for _, f := range e.Files {
+		value := f.URL
+		if f.Size > 0 {
+			value = fmt.Sprintf("%s (%s)", f.URL, utils.FormatBytes(uint64(f.Size)))
+		}
+		if len(fields) < 25 { // discord embed field limit
+			fields = append(fields, map[string]any{"name": f.Name, "value": value})
+		}
+	}
+	return postJSON(n.client, n.URL, map[string]any{
+		"username": "ipsw",
+		"embeds": []map[string]any{{
+			"title":     e.Name,
+			"url":       "https://developer.apple.com/download/",
+			"color":     4535172,
+			"timestamp": e.Found.Format(time.RFC3339),
+			"fields":    fields,
+		}},
+	})
+}
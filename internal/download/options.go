@@ -0,0 +1,28 @@
+package download
+
+import "net/http"
+
+// A ClientOption configures the client of a download source (see NewIpswMeClient, NewAppleDBClient and NewDevPortal)
+type ClientOption func(*clientOptions)
+
+type clientOptions struct {
+	transport http.RoundTripper
+}
+
+// WithTransport makes the client send its requests through rt (i.e. a mock, a recorder, the transport of a
+// TLS intercepting corporate proxy or one that signs the requests) instead of the shared transports of
+// NewTransport; the proxy and insecure settings are then up to rt (and the request budgets of the
+// community APIs still apply)
+func WithTransport(rt http.RoundTripper) ClientOption {
+	return func(o *clientOptions) {
+		o.transport = rt
+	}
+}
+
+func newClientOptions(opts []ClientOption) clientOptions {
+	var o clientOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
@@ -0,0 +1,232 @@
+package download
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/apex/log"
+	"github.com/blacktop/ipsw/internal/exitcode"
+	"github.com/blacktop/ipsw/internal/utils"
+)
+
+const (
+	ossReleasesURL = "https://opensource.apple.com/releases/"
+	ossTarballURL  = "https://github.com/apple-oss-distributions/%s/archive/refs/tags/%s.tar.gz"
+)
+
+var ossTagRegex = regexp.MustCompile(`github\.com/apple-oss-distributions/([^/]+)/(?:tree|releases/tag)/([^/?#]+)`)
+var ossReleaseRegex = regexp.MustCompile(`^(macOS|iOS|OS X|Mac OS X|Xcode|Developer Tools)\s+[\d.]+`)
+
+// OSSProject is an opensource.apple.com project release
+type OSSProject struct {
+	Name string `json:"name,omitempty"`
+	Tag  string `json:"tag,omitempty"`
+	URL  string `json:"url,omitempty"`
+}
+
+// Download downloads the project tarball to the given folder
+func (p OSSProject) Download(folder, proxy string, insecure bool) error {
+	destName := filepath.Join(folder, p.Tag+".tar.gz")
+
+	if _, err := os.Stat(destName); err == nil {
+		getLogger().Warnf("file already exists: %s", destName)
+		return nil
+	}
+
+	if err := os.MkdirAll(folder, 0750); err != nil {
+		return fmt.Errorf("failed to create folder %s: %v", folder, err)
+	}
+
+	getLogger().WithFields(log.Fields{
+		"file": destName,
+	}).Info("Downloading")
+
+	req, err := http.NewRequest("GET", p.URL, nil)
+	if err != nil {
+		return fmt.Errorf("cannot create http request: %v", err)
+	}
+
+	client := &http.Client{
+		Transport: RetryTransport(NewTransport(proxy, insecure)),
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("client failed to perform request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to connect to URL: %s", resp.Status)
+	}
+
+	f, err := os.Create(destName)
+	if err != nil {
+		return fmt.Errorf("failed to create file %s: %v", destName, err)
+	}
+	defer f.Close()
+
+	if _, err := io.Copy(f, resp.Body); err != nil {
+		return fmt.Errorf("failed to write file %s: %v", destName, err)
+	}
+
+	return nil
+}
+
+// OSSRelease is an opensource.apple.com release manifest (i.e. macOS 14.0)
+type OSSRelease struct {
+	Name     string       `json:"name,omitempty"`
+	Projects []OSSProject `json:"projects,omitempty"`
+}
+
+// Filter returns the projects whose names match ANY of the glob patterns (i.e. xnu, dyld*, Security)
+func (r OSSRelease) Filter(patterns []string) ([]OSSProject, error) {
+	if len(patterns) == 0 {
+		return r.Projects, nil
+	}
+	var projs []OSSProject
+	for _, p := range r.Projects {
+		for _, pattern := range patterns {
+			match, err := path.Match(strings.ToLower(pattern), strings.ToLower(p.Name))
+			if err != nil {
+				return nil, fmt.Errorf("invalid glob pattern %s: %v", pattern, err)
+			}
+			if match {
+				projs = append(projs, p)
+				break
+			}
+		}
+	}
+	return projs, nil
+}
+
+// OSSReleases is a list of opensource.apple.com release manifests
+type OSSReleases []OSSRelease
+
+// Get returns the release with the given name or version (i.e. "macOS 14.0" or "14.0")
+func (rs OSSReleases) Get(name string) (*OSSRelease, error) {
+	for _, r := range rs {
+		if strings.EqualFold(r.Name, name) {
+			return &r, nil
+		}
+	}
+	for _, r := range rs {
+		if strings.HasSuffix(r.Name, " "+name) {
+			return &r, nil
+		}
+	}
+	return nil, fmt.Errorf("opensource.apple.com release %s not found", name)
+}
+
+// ossReleaseOS maps a device class to the OS of the opensource.apple.com releases its builds are published under
+// (tvOS and audioOS share iOS's version numbers and sources)
+var ossReleaseOS = map[string]string{
+	"ios":     "iOS",
+	"tvos":    "iOS",
+	"audioos": "iOS",
+	"macos":   "macOS",
+}
+
+// ForVersion returns the release of a device class's OS version (i.e. macos, 14.0 => "macOS 14.0")
+//
+// Point releases without their own sources fall back to the release they were built from (i.e. iOS 17.0.3 => iOS 17.0)
+func (rs OSSReleases) ForVersion(class, version string) (*OSSRelease, error) {
+	osName, ok := ossReleaseOS[class]
+	if !ok {
+		return nil, exitcode.Errorf(exitcode.NotFound, "opensource.apple.com does not publish %s releases", class)
+	}
+	for v := version; len(v) > 0; {
+		for _, r := range rs {
+			if strings.EqualFold(r.Name, osName+" "+v) {
+				return &r, nil
+			}
+		}
+		idx := strings.LastIndex(v, ".")
+		if idx < 0 {
+			break
+		}
+		v = v[:idx]
+	}
+	return nil, exitcode.Errorf(exitcode.NotFound, "opensource.apple.com release %s %s not found", osName, version)
+}
+
+// ForBuild returns the release of a build ID (i.e. 23A344 => "macOS 14.0") looked up on ipsw.me
+func (rs OSSReleases) ForBuild(buildID string) (*OSSRelease, error) {
+	i, err := GetBuild(buildID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lookup build %s: %w", buildID, err)
+	}
+	return rs.ForVersion(DeviceClassForIdentifier(i.Identifier), i.Version)
+}
+
+// GetOSSReleases scrapes the opensource.apple.com releases page for the release manifests
+func GetOSSReleases(proxy string, insecure bool) (OSSReleases, error) {
+	var releases OSSReleases
+
+	req, err := http.NewRequest("GET", ossReleasesURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create http GET request: %v", err)
+	}
+	req.Header.Add("User-Agent", utils.RandomAgent())
+
+	client := &http.Client{
+		Transport: RetryTransport(NewTransport(proxy, insecure)),
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to connect to URL: %s", resp.Status)
+	}
+
+	doc, err := goquery.NewDocumentFromReader(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse opensource.apple.com releases page: %v", err)
+	}
+
+	current := -1
+	// walk the headers and links in document order; each release header is followed by its project links
+	doc.Find("h1, h2, h3, h4, a").Each(func(_ int, s *goquery.Selection) {
+		if goquery.NodeName(s) != "a" {
+			title := strings.TrimSpace(s.Text())
+			if ossReleaseRegex.MatchString(title) {
+				releases = append(releases, OSSRelease{Name: title})
+				current = len(releases) - 1
+			}
+			return
+		}
+		if current < 0 {
+			return
+		}
+		href, ok := s.Attr("href")
+		if !ok {
+			return
+		}
+		if m := ossTagRegex.FindStringSubmatch(href); m != nil {
+			releases[current].Projects = append(releases[current].Projects, OSSProject{
+				Name: m[1],
+				Tag:  m[2],
+				URL:  fmt.Sprintf(ossTarballURL, m[1], m[2]),
+			})
+		}
+	})
+
+	if len(releases) == 0 {
+		return nil, fmt.Errorf("failed to find any releases on %s", ossReleasesURL)
+	}
+
+	getLogger().Debugf("Found %d opensource.apple.com releases", len(releases))
+
+	return releases, nil
+}
@@ -1,8 +1,9 @@
+//go:build !wasm
+
 package download
 
 import (
 	"bytes"
-	"crypto/tls"
 	"fmt"
 	"io"
 	"net/http"
@@ -39,7 +40,7 @@ func (p *project) Download() error {
 	destName := getDestName(p.URL, false)
 	if _, err := os.Stat(destName); os.IsNotExist(err) {
 
-		log.WithFields(log.Fields{
+		getLogger().WithFields(log.Fields{
 			"file": destName,
 		}).Info("Downloading")
 
@@ -53,7 +54,7 @@ func (p *project) Download() error {
 		}
 
 	} else {
-		log.Warnf("file already exists: %s", destName)
+		getLogger().Warnf("file already exists: %s", destName)
 	}
 
 	return nil
@@ -69,10 +70,7 @@ func NewOSS(macOSVersion, proxy string, insecure bool) (*Oss, error) {
 	req.Header.Add("User-Agent", utils.RandomAgent())
 
 	client := &http.Client{
-		Transport: &http.Transport{
-			Proxy:           GetProxy(proxy),
-			TLSClientConfig: &tls.Config{InsecureSkipVerify: insecure},
-		},
+		Transport: RetryTransport(NewTransport(proxy, insecure)),
 	}
 
 	resp, err := client.Do(req)
@@ -4,7 +4,6 @@ import (
 	"bytes"
 	"compress/gzip"
 	"context"
-	"crypto/tls"
 	_ "embed"
 	"encoding/base64"
 	"encoding/json"
@@ -76,18 +75,25 @@ type Ota struct {
 
 // OtaConf is an OTA download configuration
 type OtaConf struct {
-	Platform        string
-	Beta            bool
-	RSR             bool
-	Device          string
-	Model           string
-	Version         *version.Version
-	Build           string
+	Platform string
+	Beta     bool
+	RSR      bool
+	Device   string
+	Model    string
+	Version  *version.Version
+	Build    string
+	// delta OTAs are matched by the build/version they update from
+	PrerequisiteVersion string
+	PrerequisiteBuild   string
+	// AssetAudience overrides the asset audience(s) queried (i.e. a seed program's)
+	AssetAudience   string
 	DeviceWhiteList []string
 	DeviceBlackList []string
 	Proxy           string
 	Insecure        bool
 	Timeout         time.Duration
+	// Logger (optional) receives the OTA queries' log lines instead of the package logger (see SetLogger)
+	Logger log.Interface
 }
 
 type pallasRequest struct {
@@ -197,10 +203,7 @@ func NewOTA(as *AssetSets, conf OtaConf) (*Ota, error) {
 	req.Header.Add("User-Agent", utils.RandomAgent())
 
 	client := &http.Client{
-		Transport: &http.Transport{
-			Proxy:           GetProxy(conf.Proxy),
-			TLSClientConfig: &tls.Config{InsecureSkipVerify: conf.Insecure},
-		},
+		Transport: RetryTransport(NewTransport(conf.Proxy, conf.Insecure)),
 	}
 
 	resp, err := client.Do(req)
@@ -235,6 +238,14 @@ func NewOTA(as *AssetSets, conf OtaConf) (*Ota, error) {
 	return &o, nil
 }
 
+// logger returns the configured logger of the OTA queries (or the package logger)
+func (o *Ota) logger() log.Interface {
+	if o.Config.Logger != nil {
+		return o.Config.Logger
+	}
+	return getLogger()
+}
+
 func (o *Ota) GetLatest() *semver.Version {
 	latest := o.as.LatestVersion(o.Config.Platform)
 	ver, err := semver.NewVersion(strings.TrimPrefix(latest, "9.9."))
@@ -305,11 +316,17 @@ func (o *Ota) getRequestAssetTypes() ([]assetType, error) {
 		return []assetType{macSoftwareUpdate}, nil
 	case "recovery":
 		return []assetType{recoveryOsSoftwareUpdate}, nil
+	case "bridgeos":
+		return nil, fmt.Errorf("bridgeOS is not served as OTAs (it ships inside macOS updates); use `ipsw download bridgeos` or `ipsw download ipsw --ibridge` instead")
 	}
 	return nil, fmt.Errorf("unsupported platform %s", o.Config.Platform)
 }
 
 func (o *Ota) getRequestAudienceIDs() ([]string, error) {
+	if len(o.Config.AssetAudience) > 0 {
+		return []string{o.Config.AssetAudience}, nil
+	}
+
 	assetAudienceDB, err := GetAssetAudienceIDs()
 	if err != nil {
 		return nil, err
@@ -373,6 +390,11 @@ func (o *Ota) getRequestAudienceIDs() ([]string, error) {
 						v.DeveloperBeta,
 						v.AppleSeedBeta,
 						v.PublicBeta}, nil
+				} else if len(assetAudienceDB[o.Config.Platform].Versions) == 0 { // i.e. visionOS
+					o.logger().Warnf("no %s beta asset audiences known (use --seed with an enrollment profile or asset audience)", o.Config.Platform)
+					return []string{
+						assetAudienceDB[o.Config.Platform].Release,
+						assetAudienceDB[o.Config.Platform].Generic}, nil
 				} else {
 					return nil, fmt.Errorf(
 						"invalid version %s (must be one of %s)",
@@ -407,6 +429,14 @@ func (o *Ota) getRequests(atype assetType, audienceID string) (reqs []pallasRequ
 		req.DelayRequested = false
 	}
 
+	// pallas only offers delta OTAs for the version/build the device is currently running
+	if len(o.Config.PrerequisiteVersion) > 0 {
+		req.ProductVersion = o.Config.PrerequisiteVersion
+	}
+	if len(o.Config.PrerequisiteBuild) > 0 {
+		req.BuildVersion = o.Config.PrerequisiteBuild
+	}
+
 	if o.Config.Beta {
 		switch o.Config.Platform {
 		case "ios", "audioos", "tvos", "visionos":
@@ -495,11 +525,8 @@ func sendPostAsync(body []byte, rc chan *http.Response, config *OtaConf) error {
 	// req.Header.Add("User-Agent", "Configurator/2.15 (Macintosh; OS X 11.0.0; 16G29) AppleWebKit/2603.3.8")
 
 	client := &http.Client{
-		Transport: &http.Transport{
-			Proxy:           GetProxy(config.Proxy),
-			TLSClientConfig: &tls.Config{InsecureSkipVerify: config.Insecure},
-		},
-		Timeout: config.Timeout * time.Second,
+		Transport: RetryTransport(NewTransport(config.Proxy, config.Insecure)),
+		Timeout:   config.Timeout * time.Second,
 	}
 
 	resp, err := client.Do(req)
@@ -512,6 +539,14 @@ func sendPostAsync(body []byte, rc chan *http.Response, config *OtaConf) error {
 
 // GetPallasOTAs returns an OTA assets for a given config using the newstyle OTA - CREDIT: https://gist.github.com/Siguza/0331c183c8c59e4850cd0b62fd501424
 func (o *Ota) GetPallasOTAs() ([]types.Asset, error) {
+	otas, err := o.getPallasOTAs()
+	if err != nil {
+		return nil, err
+	}
+	return o.filterPrerequisite(otas), nil
+}
+
+func (o *Ota) getPallasOTAs() ([]types.Asset, error) {
 	var err error
 
 	oassets := o.QueryPublicXML()
@@ -541,20 +576,20 @@ func (o *Ota) GetPallasOTAs() ([]types.Asset, error) {
 	for resp := range c {
 
 		if resp.StatusCode >= 500 {
-			log.Debugf("[ERROR]\n%s", resp.Status)
+			o.logger().Debugf("[ERROR]\n%s", resp.Status)
 			continue
 		}
 
 		body, err := io.ReadAll(resp.Body)
 		if err != nil {
-			log.Errorf("failed to read response body: %v", err)
+			o.logger().Errorf("failed to read response body: %v", err)
 			continue
 		}
 
 		// repair/parse base64 response data
 		parts := strings.Split(string(body), ".")
 		if len(parts) < 2 {
-			log.Errorf("failed to base64 decode pallas response: cannot split response body \"%s\" ", string(body))
+			o.logger().Errorf("failed to base64 decode pallas response: cannot split response body \"%s\" ", string(body))
 			continue
 		}
 		b64Str := parts[1]
@@ -564,18 +599,18 @@ func (o *Ota) GetPallasOTAs() ([]types.Asset, error) {
 		// bas64 decode the results
 		b64data, err := base64.StdEncoding.WithPadding(base64.NoPadding).DecodeString(b64Str)
 		if err != nil {
-			log.Errorf("failed to base64 decode pallas response: %v", err)
+			o.logger().Errorf("failed to base64 decode pallas response: %v", err)
 			continue
 		}
 
 		if resp.StatusCode != 200 {
-			log.Debugf("[ERROR]\n%s", string(b64data))
+			o.logger().Debugf("[ERROR]\n%s", string(b64data))
 			continue
 		}
 
 		res := ota{}
 		if err := json.Unmarshal(b64data, &res); err != nil {
-			log.Errorf("failed to unmarshall JSON: %v", err)
+			o.logger().Errorf("failed to unmarshall JSON: %v", err)
 			continue
 		}
 
@@ -589,7 +624,7 @@ func (o *Ota) GetPallasOTAs() ([]types.Asset, error) {
 	}
 
 	if err := g.Wait(); err != nil {
-		log.Errorf("failed to get pallas OTA assets (wait group error): %v", err)
+		o.logger().Errorf("failed to get pallas OTA assets (wait group error): %v", err)
 		// return nil, fmt.Errorf("failed to get pallas OTA assets (wait group error): %v", err)
 	}
 
@@ -613,12 +648,33 @@ func (o *Ota) GetPallasOTAs() ([]types.Asset, error) {
 	oassets = uniqueOTAs(oassets)
 
 	for _, oa := range oassets {
-		log.Debug(oa.String())
+		o.logger().Debug(oa.String())
 	}
 
 	return o.filterOTADevices(oassets), nil
 }
 
+// filterPrerequisite keeps only the delta OTAs that update from the configured prerequisite build/version
+func (o *Ota) filterPrerequisite(otas []types.Asset) []types.Asset {
+	if len(o.Config.PrerequisiteBuild) == 0 && len(o.Config.PrerequisiteVersion) == 0 {
+		return otas
+	}
+	var filtered []types.Asset
+	for _, ota := range otas {
+		if len(ota.PrerequisiteBuild) == 0 && len(ota.PrerequisiteOSVersion) == 0 {
+			continue // full update
+		}
+		if len(o.Config.PrerequisiteBuild) > 0 && !strings.EqualFold(ota.PrerequisiteBuild, o.Config.PrerequisiteBuild) {
+			continue
+		}
+		if len(o.Config.PrerequisiteVersion) > 0 && strings.TrimPrefix(ota.PrerequisiteOSVersion, "9.9.") != o.Config.PrerequisiteVersion {
+			continue
+		}
+		filtered = append(filtered, ota)
+	}
+	return filtered
+}
+
 func uniqueOTAs(otas []types.Asset) []types.Asset {
 	unique := make(map[string]bool, len(otas))
 	os := make([]types.Asset, len(unique))
@@ -674,11 +730,11 @@ func (o *Ota) filterOTADevices(otas []types.Asset) []types.Asset { // FIXME: thi
 
 	for _, device := range devices {
 		if len(o.Config.DeviceWhiteList) > 0 {
-			if utils.StrSliceHas(o.Config.DeviceWhiteList, device) {
+			if utils.StrSliceHas(o.Config.DeviceWhiteList, device) || InDeviceClasses(device, o.Config.DeviceWhiteList) {
 				filteredDevices = append(filteredDevices, device)
 			}
 		} else if len(o.Config.DeviceBlackList) > 0 {
-			if !utils.StrSliceHas(o.Config.DeviceBlackList, device) {
+			if !utils.StrSliceHas(o.Config.DeviceBlackList, device) && !InDeviceClasses(device, o.Config.DeviceBlackList) {
 				filteredDevices = append(filteredDevices, device)
 			}
 		} else {
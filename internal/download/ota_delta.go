@@ -0,0 +1,133 @@
+package download
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/blacktop/ipsw/pkg/info"
+	"github.com/blacktop/ipsw/pkg/ota/types"
+	semver "github.com/hashicorp/go-version"
+
+	"github.com/blacktop/ipsw/internal/exitcode"
+)
+
+// OtaArtifact is a downloadable OTA asset
+type OtaArtifact struct {
+	URL                 string `json:"url"`
+	Size                int    `json:"size"`
+	UnarchivedSize      int    `json:"unarchived_size,omitempty"`
+	Version             string `json:"version,omitempty"`
+	Build               string `json:"build,omitempty"`
+	PrerequisiteVersion string `json:"prerequisite_version,omitempty"`
+	PrerequisiteBuild   string `json:"prerequisite_build,omitempty"`
+}
+
+func newOtaArtifact(a types.Asset) *OtaArtifact {
+	return &OtaArtifact{
+		URL:                 a.BaseURL + a.RelativePath,
+		Size:                a.DownloadSize,
+		UnarchivedSize:      a.UnarchivedSize,
+		Version:             strings.TrimPrefix(a.OSVersion, "9.9."),
+		Build:               a.Build,
+		PrerequisiteVersion: strings.TrimPrefix(a.PrerequisiteOSVersion, "9.9."),
+		PrerequisiteBuild:   a.PrerequisiteBuild,
+	}
+}
+
+// OtaUpdate are the OTA assets that update a device from one build to another
+type OtaUpdate struct {
+	Device    string       `json:"device"`
+	FromBuild string       `json:"from_build"`
+	ToBuild   string       `json:"to_build"`
+	Delta     *OtaArtifact `json:"delta,omitempty"`
+	Full      *OtaArtifact `json:"full,omitempty"`
+}
+
+// HasDelta returns true if a delta OTA exists for the update
+func (u *OtaUpdate) HasDelta() bool {
+	return u.Delta != nil
+}
+
+// Best returns the smallest asset that performs the update
+func (u *OtaUpdate) Best() *OtaArtifact {
+	if u.Delta != nil && (u.Full == nil || u.Delta.Size <= u.Full.Size) {
+		return u.Delta
+	}
+	return u.Full
+}
+
+// GetOtaUpdate checks whether a delta OTA exists that updates the device from fromBuild to toBuild
+// and resolves it alongside the full OTA
+func GetOtaUpdate(device, fromBuild, toBuild, proxy string, insecure bool) (*OtaUpdate, error) {
+	db, err := info.GetIpswDB()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ipsw db: %v", err)
+	}
+	dev, err := db.LookupDevice(device)
+	if err != nil {
+		return nil, err
+	}
+
+	as, err := GetAssetSets(proxy, insecure)
+	if err != nil {
+		return nil, err
+	}
+
+	// NOTE: pallas needs the version the device is running to offer deltas
+	conf := OtaConf{
+		Platform:          dev.Type,
+		Device:            device,
+		Build:             toBuild,
+		PrerequisiteBuild: fromBuild,
+		Proxy:             proxy,
+		Insecure:          insecure,
+		Timeout:           90,
+	}
+	if i, err := GetIPSW(device, fromBuild); err == nil {
+		conf.PrerequisiteVersion = i.Version
+	}
+	version := "0"
+	if i, err := GetIPSW(device, toBuild); err == nil {
+		version = i.Version
+	}
+	conf.Version, err = semver.NewVersion(version)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse version %s: %v", version, err)
+	}
+
+	o, err := NewOTA(as, conf)
+	if err != nil {
+		return nil, err
+	}
+	otas, err := o.getPallasOTAs()
+	if err != nil {
+		return nil, err
+	}
+
+	update := &OtaUpdate{
+		Device:    device,
+		FromBuild: fromBuild,
+		ToBuild:   toBuild,
+	}
+	for _, a := range otas {
+		if !strings.EqualFold(a.Build, toBuild) || a.SplatOnly {
+			continue
+		}
+		switch {
+		case len(a.PrerequisiteBuild) == 0:
+			if update.Full == nil {
+				update.Full = newOtaArtifact(a)
+			}
+		case strings.EqualFold(a.PrerequisiteBuild, fromBuild):
+			if update.Delta == nil {
+				update.Delta = newOtaArtifact(a)
+			}
+		}
+	}
+
+	if update.Delta == nil && update.Full == nil {
+		return nil, exitcode.Errorf(exitcode.NotFound, "no OTA found for %s %s", device, toBuild)
+	}
+
+	return update, nil
+}
@@ -0,0 +1,97 @@
+//go:build !wasm
+
+package download
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	// progressInterval is the most often a ProgressFunc is called
+	progressInterval = 250 * time.Millisecond
+	// progressSmoothing is the weight of the latest speed sample in the reported speed
+	progressSmoothing = 0.3
+)
+
+// Progress is a snapshot of a running download's progress
+type Progress struct {
+	Done  int64         `json:"done"`  // bytes downloaded (including resumed bytes)
+	Total int64         `json:"total"` // size in bytes (0 if unknown)
+	Speed float64       `json:"speed"` // bytes per second (smoothed)
+	ETA   time.Duration `json:"eta"`   // time left at the current speed (0 if unknown)
+}
+
+// Percent returns how much of the download is done (0-100, or 0 if the size is unknown)
+func (p Progress) Percent() float64 {
+	if p.Total <= 0 {
+		return 0
+	}
+	return float64(p.Done) / float64(p.Total) * 100
+}
+
+// ProgressFunc is called with a download's progress while it is transferring
+// (at most every 250ms, and once more when the transfer completes)
+//
+// NOTE: it is called from the download's goroutine(s) (one call at a time) so it must not block
+type ProgressFunc func(Progress)
+
+type progressReporter struct {
+	mu       sync.Mutex
+	last     time.Time
+	lastDone int64
+	speed    float64
+}
+
+// SetProgressFunc sets the download's OnProgress callback (safe to call while it is running)
+func (d *Download) SetProgressFunc(fn ProgressFunc) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.OnProgress = fn
+}
+
+// reportProgress calls the download's OnProgress callback (if it is due or force is set)
+func (d *Download) reportProgress(force bool) {
+	d.mu.Lock()
+	fn := d.OnProgress
+	done := d.stats.ResumedBytes + d.stats.Bytes
+	total := d.size
+	d.mu.Unlock()
+	if fn == nil {
+		return
+	}
+	if total > 0 && done > total {
+		done = total // restarted transfers count their bytes again
+	}
+
+	r := &d.reporter
+	r.mu.Lock()
+	now := time.Now()
+	if r.last.IsZero() {
+		r.last, r.lastDone = now, done
+		if !force {
+			r.mu.Unlock()
+			return
+		}
+	}
+	elapsed := now.Sub(r.last)
+	if !force && elapsed < progressInterval {
+		r.mu.Unlock()
+		return
+	}
+	if elapsed > 0 && done >= r.lastDone {
+		sample := float64(done-r.lastDone) / elapsed.Seconds()
+		if r.speed == 0 {
+			r.speed = sample
+		} else {
+			r.speed = progressSmoothing*sample + (1-progressSmoothing)*r.speed
+		}
+	}
+	r.last, r.lastDone = now, done
+	p := Progress{Done: done, Total: total, Speed: r.speed}
+	if total > 0 && r.speed > 0 {
+		p.ETA = time.Duration(float64(total-done) / r.speed * float64(time.Second)).Round(time.Second)
+	}
+	fn(p) // under the lock so the callback sees the progress in order
+	r.mu.Unlock()
+}
@@ -2,7 +2,6 @@ package download
 
 import (
 	"archive/zip"
-	"crypto/tls"
 	"net/http"
 	"net/url"
 
@@ -29,10 +28,7 @@ func NewRemoteZipReader(zipURL string, config *RemoteConfig) (*zip.Reader, error
 		URL:       url,
 		UserAgent: utils.RandomAgent(),
 		Client: &http.Client{
-			Transport: &http.Transport{
-				Proxy:           GetProxy(config.Proxy),
-				TLSClientConfig: &tls.Config{InsecureSkipVerify: config.Insecure},
-			},
+			Transport: RetryTransport(NewTransport(config.Proxy, config.Insecure)),
 		},
 	})
 	if err != nil {
@@ -0,0 +1,19 @@
+//go:build !wasm
+
+package download
+
+import (
+	"regexp"
+
+	"github.com/blacktop/ipsw/internal/utils"
+)
+
+// RemoteExtract extracts the files matching pattern from a remote zip (only its central directory
+// and the matching files are downloaded using HTTP Range requests)
+func RemoteExtract(zipURL string, pattern *regexp.Regexp, dest string, config *RemoteConfig) ([]string, error) {
+	zr, err := NewRemoteZipReader(zipURL, config)
+	if err != nil {
+		return nil, err
+	}
+	return utils.SearchZip(zr.File, pattern, dest, false, true)
+}
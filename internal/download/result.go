@@ -0,0 +1,127 @@
+package download
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// Result is a query result that is JSON encoded into caller-provided buffers as they are read
+// (so the whole JSON document is never held in memory, only the result and one element's JSON)
+type Result struct {
+	encode func(io.Writer) error
+
+	once sync.Once
+	size int64
+	err  error
+
+	pr *io.PipeReader
+}
+
+// NewResult returns a Result that encodes items as a JSON array
+func NewResult[T any](items []T) *Result {
+	return &Result{encode: func(w io.Writer) error { return streamJSON(w, items) }}
+}
+
+// streamJSON writes items as a JSON array one element at a time
+func streamJSON[T any](w io.Writer, items []T) error {
+	if _, err := io.WriteString(w, "["); err != nil {
+		return err
+	}
+	for i, item := range items {
+		dat, err := json.Marshal(item)
+		if err != nil {
+			return fmt.Errorf("failed to serialize item %d: %v", i, err)
+		}
+		if i > 0 {
+			if _, err := io.WriteString(w, ","); err != nil {
+				return err
+			}
+		}
+		if _, err := w.Write(dat); err != nil {
+			return err
+		}
+	}
+	_, err := io.WriteString(w, "]")
+	return err
+}
+
+type countWriter int64
+
+func (c *countWriter) Write(p []byte) (int, error) {
+	*c += countWriter(len(p))
+	return len(p), nil
+}
+
+// Size returns the number of bytes of the encoded result (the buffer size needed to read it at once)
+func (r *Result) Size() (int64, error) {
+	r.once.Do(func() {
+		var c countWriter
+		r.err = r.encode(&c)
+		r.size = int64(c)
+	})
+	return r.size, r.err
+}
+
+// Read reads the next bytes of the encoded result into p (filling it unless the end of the result is reached)
+// NOTE: a result is read by one reader at a time
+func (r *Result) Read(p []byte) (int, error) {
+	if r.pr == nil {
+		pr, pw := io.Pipe()
+		go func() { pw.CloseWithError(r.encode(pw)) }()
+		r.pr = pr
+	}
+	n, err := io.ReadFull(r.pr, p)
+	if err == io.ErrUnexpectedEOF {
+		err = io.EOF
+	}
+	return n, err
+}
+
+// Close stops encoding the result
+func (r *Result) Close() error {
+	if r.pr != nil {
+		return r.pr.Close()
+	}
+	return nil
+}
+
+// results are the results handed out through the C API
+var results = struct {
+	sync.Mutex
+	next uint64
+	m    map[string]*Result
+}{m: make(map[string]*Result)}
+
+// AddResult registers a result and returns its ID
+func AddResult(r *Result) string {
+	results.Lock()
+	defer results.Unlock()
+	results.next++
+	id := fmt.Sprintf("result-%d", results.next)
+	results.m[id] = r
+	return id
+}
+
+// GetResult returns a registered result
+func GetResult(id string) (*Result, error) {
+	results.Lock()
+	defer results.Unlock()
+	r, ok := results.m[id]
+	if !ok {
+		return nil, fmt.Errorf("unknown result %s: %w", id, ErrInvalidArgument)
+	}
+	return r, nil
+}
+
+// ReleaseResult closes and forgets a registered result
+func ReleaseResult(id string) {
+	results.Lock()
+	r, ok := results.m[id]
+	delete(results.m, id)
+	results.Unlock()
+	if ok {
+		r.Close()
+	}
+}
@@ -0,0 +1,115 @@
+//go:build ffi
+
+package download
+
+//#cgo LDFLAGS:
+//#include <stdio.h>
+//#include <stdlib.h>
+//#include <string.h>
+import "C"
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"unsafe"
+)
+
+// Large results are returned as a result ID instead of a malloc'd JSON string:
+//
+//	c_libipsw_result_size   returns the buffer size needed to read the whole JSON document
+//	c_libipsw_result_read   fills a caller-provided buffer with the next bytes of the document (0 bytes at the end)
+//	c_libipsw_result_free   releases the result
+//
+// so a consumer can either allocate the required size once or read through a small fixed buffer.
+
+func setCResult(r *Result, outResult **C.char, outResultLen *C.uint, outSize *C.ulonglong, err **C.char, errLen *C.uint, name string) C.char {
+	size, serr := r.Size()
+	if serr != nil {
+		return setCError(serr, fmt.Sprintf("%s: Failed to serialize result: %v", name, serr), err, errLen)
+	}
+	cs := C.CString(AddResult(r))
+	*outResult = cs
+	*outResultLen = C.uint(C.strlen(cs))
+	*outSize = C.ulonglong(size)
+	return C.char(1)
+}
+
+//export c_libipsw_result_size
+func c_libipsw_result_size(result *C.char, resultLen C.uint, outSize *C.ulonglong, err **C.char, errLen *C.uint) C.char {
+	r, rerr := GetResult(C.GoStringN(result, C.int(resultLen)))
+	if rerr != nil {
+		return setCError(rerr, fmt.Sprintf("c_libipsw_result_size: %v", rerr), err, errLen)
+	}
+	size, serr := r.Size()
+	if serr != nil {
+		return setCError(serr, fmt.Sprintf("c_libipsw_result_size: Failed to serialize result: %v", serr), err, errLen)
+	}
+	*outSize = C.ulonglong(size)
+	return C.char(1)
+}
+
+// c_libipsw_result_read copies up to bufLen bytes of the result's JSON into buf (buf is not NUL terminated)
+//
+//export c_libipsw_result_read
+func c_libipsw_result_read(result *C.char, resultLen C.uint, buf *C.char, bufLen C.uint, outN *C.uint, err **C.char, errLen *C.uint) C.char {
+	r, rerr := GetResult(C.GoStringN(result, C.int(resultLen)))
+	if rerr != nil {
+		return setCError(rerr, fmt.Sprintf("c_libipsw_result_read: %v", rerr), err, errLen)
+	}
+	*outN = 0
+	if bufLen == 0 {
+		return C.char(1)
+	}
+	n, rerr := r.Read(unsafe.Slice((*byte)(unsafe.Pointer(buf)), int(bufLen)))
+	if rerr != nil && rerr != io.EOF {
+		return setCError(rerr, fmt.Sprintf("c_libipsw_result_read: Failed to serialize result: %v", rerr), err, errLen)
+	}
+	*outN = C.uint(n)
+	return C.char(1)
+}
+
+//export c_libipsw_result_free
+func c_libipsw_result_free(result *C.char, resultLen C.uint) {
+	ReleaseResult(C.GoStringN(result, C.int(resultLen)))
+}
+
+//export c_internal_download_ipsw_me_GetAllDevicesResult
+func c_internal_download_ipsw_me_GetAllDevicesResult(outResult **C.char, outResultLen *C.uint, outSize *C.ulonglong, err **C.char, errLen *C.uint) C.char {
+	devices, devicesErr := GetAllDevices()
+	if devicesErr != nil {
+		return setCError(devicesErr, fmt.Sprintf("c_GetAllDevicesResult: GetAllDevices failed with %v", devicesErr), err, errLen)
+	}
+	return setCResult(NewResult(devices), outResult, outResultLen, outSize, err, errLen, "c_GetAllDevicesResult")
+}
+
+//export c_internal_download_ipsw_me_GetDeviceIPSWsResult
+func c_internal_download_ipsw_me_GetDeviceIPSWsResult(identifier *C.char, identifierLen C.uint, outResult **C.char, outResultLen *C.uint, outSize *C.ulonglong, err **C.char, errLen *C.uint) C.char {
+	ipsws, ipswsErr := GetDeviceIPSWs(C.GoStringN(identifier, C.int(identifierLen)))
+	if ipswsErr != nil {
+		return setCError(ipswsErr, fmt.Sprintf("c_GetDeviceIPSWsResult: GetDeviceIPSWs failed with %v", ipswsErr), err, errLen)
+	}
+	return setCResult(NewResult(ipsws), outResult, outResultLen, outSize, err, errLen, "c_GetDeviceIPSWsResult")
+}
+
+//export c_internal_download_ipsw_me_GetAllIPSWResult
+func c_internal_download_ipsw_me_GetAllIPSWResult(version *C.char, versionLen C.uint, outResult **C.char, outResultLen *C.uint, outSize *C.ulonglong, err **C.char, errLen *C.uint) C.char {
+	ipsws, ipswsErr := GetAllIPSW(C.GoStringN(version, C.int(versionLen)))
+	if ipswsErr != nil {
+		return setCError(ipswsErr, fmt.Sprintf("c_GetAllIPSWResult: GetAllIPSW failed with %v", ipswsErr), err, errLen)
+	}
+	return setCResult(NewResult(ipsws), outResult, outResultLen, outSize, err, errLen, "c_GetAllIPSWResult")
+}
+
+//export c_internal_download_iphonewiki_GetWikiIPSWsResult
+func c_internal_download_iphonewiki_GetWikiIPSWsResult(configJson *C.char, configJsonLen C.int, proxy *C.char, proxyLen C.int, insecure C.char,
+	outResult **C.char, outResultLen *C.uint, outSize *C.ulonglong, err **C.char, errLen *C.uint) C.char {
+	var wikiConfig WikiConfig
+	if jsonErr := json.Unmarshal([]byte(C.GoStringN(configJson, configJsonLen)), &wikiConfig); jsonErr != nil {
+		return setCError(jsonErr, fmt.Sprintf("c_GetWikiIPSWsResult: Deser failed with %v", jsonErr), err, errLen)
+	}
+	fw, wfwErr := GetWikiIPSWs(&wikiConfig, C.GoStringN(proxy, proxyLen), insecure == 1)
+	if wfwErr != nil {
+		return setCError(wfwErr, fmt.Sprintf("c_GetWikiIPSWsResult: GetWikiIPSWs failed with %v", wfwErr), err, errLen)
+	}
+	return setCResult(NewResult(fw), outResult, outResultLen, outSize, err, errLen, "c_GetWikiIPSWsResult")
+}
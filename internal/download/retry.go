@@ -0,0 +1,153 @@
+package download
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math"
+	"math/rand"
+	"net"
+	"net/http"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// RetryPolicy is how failed HTTP requests and interrupted downloads are retried
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts (<= 1 disables retries)
+	MaxAttempts int
+	// BaseDelay is the delay before the first retry (it doubles with each retry)
+	BaseDelay time.Duration
+	// MaxDelay caps the delay between retries
+	MaxDelay time.Duration
+	// Jitter is the fraction of each delay that is randomized (0-1) so clients don't retry in lockstep
+	Jitter float64
+}
+
+// DefaultRetryPolicy is the retry policy used unless SetRetryPolicy is called
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	BaseDelay:   time.Second,
+	MaxDelay:    30 * time.Second,
+	Jitter:      0.2,
+}
+
+var (
+	retryPolicyMu sync.Mutex
+	retryPolicy   = DefaultRetryPolicy
+)
+
+// SetRetryPolicy sets the retry policy of all HTTP requests and downloads
+func SetRetryPolicy(p RetryPolicy) {
+	retryPolicyMu.Lock()
+	defer retryPolicyMu.Unlock()
+	if p.Jitter < 0 {
+		p.Jitter = 0
+	} else if p.Jitter > 1 {
+		p.Jitter = 1
+	}
+	retryPolicy = p
+}
+
+func getRetryPolicy() RetryPolicy {
+	retryPolicyMu.Lock()
+	defer retryPolicyMu.Unlock()
+	return retryPolicy
+}
+
+// Backoff returns the delay before retry number n (starting at 1)
+func (p RetryPolicy) Backoff(n int) time.Duration {
+	delay := float64(p.BaseDelay) * math.Pow(2, float64(n-1))
+	if p.MaxDelay > 0 && delay > float64(p.MaxDelay) {
+		delay = float64(p.MaxDelay)
+	}
+	if p.Jitter > 0 {
+		delay += delay * p.Jitter * (2*rand.Float64() - 1)
+	}
+	return time.Duration(delay)
+}
+
+// retryableStatus returns true if a response status is worth retrying (server errors and rate limiting)
+func retryableStatus(code int) bool {
+	return code >= http.StatusInternalServerError || code == http.StatusTooManyRequests
+}
+
+// retryableError returns true if err is a transient network error (timeouts, resets and dropped connections)
+func retryableError(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	var nerr net.Error
+	if errors.As(err, &nerr) && nerr.Timeout() {
+		return true
+	}
+	return errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.EPIPE) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, io.EOF) // the server closed the connection before responding
+}
+
+// idempotent returns true if the request can be safely sent again
+func idempotent(req *http.Request) bool {
+	switch req.Method {
+	case "", http.MethodGet, http.MethodHead, http.MethodOptions:
+		return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+	}
+	return false
+}
+
+type retryTransport struct {
+	base http.RoundTripper
+}
+
+// RoundTrip sends the request, retrying idempotent requests on 5xx responses and transient errors
+func (rt *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	p := getRetryPolicy()
+	if p.MaxAttempts <= 1 || !idempotent(req) {
+		return rt.base.RoundTrip(req)
+	}
+	for attempt := 1; ; attempt++ {
+		if attempt > 1 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+		resp, err := rt.base.RoundTrip(req)
+		if attempt >= p.MaxAttempts {
+			return resp, err
+		}
+		switch {
+		case err != nil && retryableError(err):
+			getLogger().WithError(err).Debugf("%s %s failed", req.Method, req.URL)
+		case err == nil && retryableStatus(resp.StatusCode):
+			getLogger().Debugf("%s %s returned status: %s", req.Method, req.URL, resp.Status)
+			io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024)) // so the connection can be reused
+			resp.Body.Close()
+		default:
+			return resp, err
+		}
+		delay := p.Backoff(attempt)
+		getLogger().Debugf("Retrying in %s (attempt %d/%d)", delay.Round(time.Millisecond), attempt+1, p.MaxAttempts)
+		select {
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		case <-time.After(delay):
+		}
+	}
+}
+
+// RetryTransport wraps a transport so that requests are retried according to the retry policy (see SetRetryPolicy)
+func RetryTransport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = sharedTransport
+	}
+	return &retryTransport{base: base}
+}
+
+// defaultClient is used for the (metadata) requests that don't need their own proxy or TLS settings
+var defaultClient = &http.Client{Transport: CacheTransport(RetryTransport(nil))}
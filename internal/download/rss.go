@@ -65,7 +65,7 @@ type Rss struct {
 
 // GetRSS returns the developer.apple.com/news/releases RSS feed as Rss object
 func GetRSS() (*Rss, error) {
-	resp, err := http.Get(rssURL)
+	resp, err := defaultClient.Get(rssURL)
 	if err != nil {
 		return nil, fmt.Errorf("failed to GET RSS URL: %v", err)
 	}
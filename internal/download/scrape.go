@@ -7,6 +7,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/blacktop/ipsw/internal/exitcode"
 	"github.com/blacktop/ipsw/internal/utils"
 	"github.com/blacktop/ipsw/pkg/info"
 	"github.com/gocolly/colly/v2"
@@ -213,7 +214,7 @@ func ScrapeIPSWs(beta bool) ([]string, error) {
 	c.Wait()
 
 	if len(ipsws) == 0 {
-		return nil, fmt.Errorf("no IPSWs found")
+		return nil, exitcode.Errorf(exitcode.NotFound, "no IPSWs found")
 	}
 
 	return utils.Unique(ipsws), nil
@@ -260,7 +261,7 @@ func ScrapeOTAs(beta bool) ([]string, error) {
 	c.Wait()
 
 	if len(otas) == 0 {
-		return nil, fmt.Errorf("no OTAs found")
+		return nil, exitcode.Errorf(exitcode.NotFound, "no OTAs found")
 	}
 
 	return utils.Unique(otas), nil
@@ -351,7 +352,7 @@ func ScrapeURLs(build string) (map[string]BetaIPSW, error) {
 	c.Wait()
 
 	if len(ipsws) == 0 {
-		return nil, fmt.Errorf("no ipsws found for build %s", build)
+		return nil, exitcode.Errorf(exitcode.NotFound, "no ipsws found for build %s", build)
 	}
 
 	return ipsws, nil
@@ -0,0 +1,145 @@
+//go:build !wasm
+
+package download
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// stateExt is the suffix of the sidecar file that records a partial download's progress
+	stateExt = ".download.state"
+	// stateSaveInterval is how often the sidecar file is updated while downloading
+	stateSaveInterval = 2 * time.Second
+)
+
+// resumeState is persisted next to the partial download so that it can be resumed exactly
+// where it stopped (even after a reboot or when started again by a different subcommand)
+type resumeState struct {
+	URL          string    `json:"url"`
+	Size         int64     `json:"size"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"last_modified,omitempty"`
+	Sha1         string    `json:"sha1,omitempty"`
+	MD5          string    `json:"md5,omitempty"`
+	SHA256       string    `json:"sha256,omitempty"`
+	Offset       int64     `json:"offset"`           // bytes written (sequential downloads)
+	Chunks       []*chunk  `json:"chunks,omitempty"` // byte ranges (chunked downloads)
+	Updated      time.Time `json:"updated"`
+
+	mu sync.Mutex
+}
+
+func (d *Download) newState() *resumeState {
+	return &resumeState{
+		URL:          d.URL,
+		Size:         d.size,
+		ETag:         d.etag,
+		LastModified: d.lastModified,
+		Sha1:         d.Sha1,
+		MD5:          d.MD5,
+		SHA256:       d.SHA256,
+	}
+}
+
+// restoreChecksums fills in the checksums of a resumed download that were not given again
+func (s *resumeState) restoreChecksums(d *Download) {
+	if len(d.Sha1) == 0 {
+		d.Sha1 = s.Sha1
+	}
+	if len(d.MD5) == 0 {
+		d.MD5 = s.MD5
+	}
+	if len(d.SHA256) == 0 {
+		d.SHA256 = s.SHA256
+	}
+}
+
+// loadState returns the partial download's sidecar state (nil if missing or unreadable)
+func (d *Download) loadState() *resumeState {
+	dat, err := os.ReadFile(d.DestName + stateExt)
+	if err != nil {
+		return nil
+	}
+	var state resumeState
+	if err := json.Unmarshal(dat, &state); err != nil {
+		d.logger().Debugf("ignoring unreadable download state %s: %v", d.DestName+stateExt, err)
+		return nil
+	}
+	return &state
+}
+
+// matches returns true if the remote file is the same one the partial download was started from
+func (s *resumeState) matches(d *Download) bool {
+	if s.Size != d.size {
+		return false
+	}
+	if s.ETag != "" && d.etag != "" {
+		return s.ETag == d.etag
+	}
+	if s.LastModified != "" && d.lastModified != "" {
+		return s.LastModified == d.lastModified
+	}
+	return true
+}
+
+// saveState writes the sidecar state file (atomically, so an interrupted write never corrupts it)
+func (d *Download) saveState(s *resumeState) error {
+	s.mu.Lock()
+	s.Updated = time.Now()
+	dat, err := json.Marshal(s)
+	s.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	d.stateMu.Lock()
+	defer d.stateMu.Unlock()
+	if err := os.WriteFile(d.DestName+stateExt+".tmp", dat, 0644); err != nil {
+		return err
+	}
+	return os.Rename(d.DestName+stateExt+".tmp", d.DestName+stateExt)
+}
+
+func (d *Download) removeState() {
+	os.Remove(d.DestName + stateExt)
+}
+
+// ifRange returns the validator to send as the If-Range header when resuming
+// (weak ETags are not allowed in If-Range)
+func (d *Download) ifRange() string {
+	if d.etag != "" && !strings.HasPrefix(d.etag, "W/") {
+		return d.etag
+	}
+	return d.lastModified
+}
+
+// stateWriter records the bytes written to the partial download in its sidecar state file
+type stateWriter struct {
+	w     io.Writer
+	d     *Download
+	state *resumeState
+	saved time.Time
+}
+
+func (sw *stateWriter) Write(p []byte) (int, error) {
+	n, err := sw.w.Write(p)
+	sw.state.mu.Lock()
+	sw.state.Offset += int64(n)
+	sw.state.mu.Unlock()
+	if time.Since(sw.saved) > stateSaveInterval {
+		sw.flush()
+	}
+	return n, err
+}
+
+func (sw *stateWriter) flush() {
+	sw.saved = time.Now()
+	if err := sw.d.saveState(sw.state); err != nil {
+		getLogger().Debugf("failed to save download state %s: %v", sw.d.DestName+stateExt, err)
+	}
+}
@@ -0,0 +1,207 @@
+//go:build !wasm
+
+package download
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/apex/log"
+	"github.com/blacktop/ipsw/internal/utils"
+)
+
+const (
+	// historyMax is the number of download stats kept in the history
+	historyMax = 1000
+	// speedSample is the window peak speed is measured over
+	speedSample = time.Second
+)
+
+// Stats are a download's bandwidth statistics
+type Stats struct {
+	URL          string        `json:"url,omitempty"`
+	Host         string        `json:"host,omitempty"`
+	DestName     string        `json:"dest,omitempty"`
+	State        DownloadState `json:"state,omitempty"`
+	Error        string        `json:"error,omitempty"`
+	Started      time.Time     `json:"started,omitempty"`
+	Duration     time.Duration `json:"duration,omitempty"`
+	Active       time.Duration `json:"active,omitempty"` // time spent transferring (excludes pauses)
+	Bytes        int64         `json:"bytes"`            // bytes transferred by this download
+	ResumedBytes int64         `json:"resumed_bytes"`    // bytes picked up from a previous partial download
+	Retries      int           `json:"retries"`
+	AvgSpeed     float64       `json:"avg_speed"`  // bytes per second
+	PeakSpeed    float64       `json:"peak_speed"` // bytes per second
+	Verification *Verification `json:"verification,omitempty"`
+	Trace        string        `json:"trace,omitempty"` // correlation ID of the download (see WithTraceID)
+}
+
+func (s Stats) String() string {
+	return fmt.Sprintf("%s in %s (avg %s/s, peak %s/s, %d retries, %s resumed)",
+		utils.FormatBytes(uint64(s.Bytes)),
+		s.Duration.Round(time.Second),
+		utils.FormatBytes(uint64(s.AvgSpeed)),
+		utils.FormatBytes(uint64(s.PeakSpeed)),
+		s.Retries,
+		utils.FormatBytes(uint64(s.ResumedBytes)),
+	)
+}
+
+// statsReader counts the bytes read and tracks the peak speed
+type statsReader struct {
+	io.ReadCloser
+	d           *Download
+	windowStart time.Time
+	windowBytes int64
+}
+
+func (d *Download) statsReader(r io.ReadCloser) io.ReadCloser {
+	return &statsReader{ReadCloser: r, d: d, windowStart: time.Now()}
+}
+
+func (r *statsReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.windowBytes += int64(n)
+	r.d.mu.Lock()
+	r.d.stats.Bytes += int64(n)
+	if elapsed := time.Since(r.windowStart); elapsed >= speedSample {
+		if speed := float64(r.windowBytes) / elapsed.Seconds(); speed > r.d.stats.PeakSpeed {
+			r.d.stats.PeakSpeed = speed
+		}
+		r.windowStart = time.Now()
+		r.windowBytes = 0
+	}
+	r.d.mu.Unlock()
+	r.d.reportProgress(false)
+	return n, err
+}
+
+// Stats returns a snapshot of the download's bandwidth statistics
+func (d *Download) Stats() Stats {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	s := d.stats
+	s.URL = d.URL
+	s.DestName = d.DestName
+	if u, err := url.Parse(d.URL); err == nil {
+		s.Host = u.Host
+	}
+	if !s.Started.IsZero() && s.Duration == 0 {
+		s.Duration = time.Since(s.Started)
+	}
+	if s.Active > 0 {
+		s.AvgSpeed = float64(s.Bytes) / s.Active.Seconds()
+	}
+	if s.PeakSpeed < s.AvgSpeed {
+		s.PeakSpeed = s.AvgSpeed // transfers shorter than a sample window
+	}
+	return s
+}
+
+// recordStats prints the download's stats and adds them to the history
+func (d *Download) recordStats() {
+	d.mu.Lock()
+	d.stats.State = d.state
+	if d.err != nil {
+		d.stats.Error = d.err.Error()
+	}
+	if !d.stats.Started.IsZero() {
+		d.stats.Duration = time.Since(d.stats.Started)
+	}
+	d.mu.Unlock()
+
+	s := d.Stats()
+	if s.Bytes == 0 && s.State == StateDone {
+		return // skipped
+	}
+	if s.State == StateDone {
+		utils.Indent(d.logger().WithFields(log.Fields{
+			"avg":     utils.FormatBytes(uint64(s.AvgSpeed)) + "/s",
+			"peak":    utils.FormatBytes(uint64(s.PeakSpeed)) + "/s",
+			"size":    utils.FormatBytes(uint64(s.Bytes)),
+			"resumed": utils.FormatBytes(uint64(s.ResumedBytes)),
+			"retries": s.Retries,
+		}).Info, 2)(fmt.Sprintf("Downloaded in %s", s.Duration.Round(time.Second)))
+	}
+	if err := history.Add(s); err != nil {
+		d.logger().Debugf("failed to save download history: %v", err)
+	}
+}
+
+// History is the persisted download stats history
+type History struct {
+	mu    sync.Mutex
+	path  string
+	Stats []Stats `json:"downloads"`
+}
+
+var history = &History{}
+
+func init() {
+	if home, err := os.UserHomeDir(); err == nil {
+		history.path = filepath.Join(home, ".ipsw", "history.json")
+	}
+}
+
+// SetHistoryFile sets the path the download history is persisted to
+func SetHistoryFile(path string) {
+	history.mu.Lock()
+	defer history.mu.Unlock()
+	history.path = path
+}
+
+func (h *History) load() ([]Stats, error) {
+	if len(h.path) == 0 {
+		return nil, nil
+	}
+	data, err := os.ReadFile(h.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var saved History
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return nil, fmt.Errorf("failed to parse download history %s: %v", h.path, err)
+	}
+	return saved.Stats, nil
+}
+
+// Add appends a download's stats to the history
+func (h *History) Add(s Stats) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if len(h.path) == 0 {
+		return nil
+	}
+	stats, err := h.load()
+	if err != nil {
+		return err
+	}
+	stats = append(stats, s)
+	if len(stats) > historyMax {
+		stats = stats[len(stats)-historyMax:]
+	}
+	data, err := json.Marshal(&History{Stats: stats})
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(h.path), 0750); err != nil {
+		return fmt.Errorf("failed to create directory %s: %v", filepath.Dir(h.path), err)
+	}
+	return os.WriteFile(h.path, data, 0644)
+}
+
+// GetHistory returns the persisted download stats (oldest first)
+func GetHistory() ([]Stats, error) {
+	history.mu.Lock()
+	defer history.mu.Unlock()
+	return history.load()
+}
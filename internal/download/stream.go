@@ -0,0 +1,209 @@
+//go:build !wasm
+
+package download
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"time"
+
+	"github.com/blacktop/ipsw/internal/exitcode"
+	"github.com/blacktop/ipsw/internal/utils"
+	"github.com/vbauerster/mpb/v7"
+	"github.com/vbauerster/mpb/v7/decor"
+)
+
+// Stream downloads a URL into w (i.e. a pipe or an object storage upload) without touching the local disk
+func Stream(ctx context.Context, url string, w io.Writer) error {
+	d := NewDownload("", false, false, false, false, false, false)
+	d.URL = url
+	return d.Stream(ctx, w)
+}
+
+// streamWriter hashes and counts the bytes written to a stream (and keeps its write errors apart from the download's)
+type streamWriter struct {
+	w    io.Writer
+	hash hash.Hash
+	n    int64
+	err  error
+}
+
+func (sw *streamWriter) Write(p []byte) (int, error) {
+	n, err := sw.w.Write(p)
+	if sw.hash != nil {
+		sw.hash.Write(p[:n])
+	}
+	sw.n += int64(n)
+	if err != nil {
+		sw.err = err
+	}
+	return n, err
+}
+
+// Stream downloads the URL into w instead of DestName
+//
+// Interrupted transfers are resumed with a Range request from the last byte written to w (so w sees
+// every byte once) and the checksum is verified as the bytes stream through. As w has already
+// received the bytes when the checksum is incorrect, a bad download is reported but not re-fetched.
+//
+// NOTE: progress is rendered to stderr so that w can be os.Stdout
+func (d *Download) Stream(ctx context.Context, w io.Writer) (err error) {
+	ctx = d.startTrace(ctx)
+	defer func() { err = traced(ctx, err) }()
+	defer d.recordStats()
+	d.mu.Lock()
+	d.stats.Started = time.Now()
+	d.stats.Verification = nil
+	d.mu.Unlock()
+	d.retries = 0
+
+	d.getHEAD(ctx)
+
+	sw := &streamWriter{w: w, hash: d.newHash()}
+
+	var p *utils.Progress
+	var bar *mpb.Bar
+	if d.size > 0 {
+		name := path.Base(d.URL)
+		if d.OnProgress != nil || utils.Plain {
+			p = utils.NewProgress(name, mpb.WithOutput(nil))
+		} else {
+			p = utils.NewProgress(name, mpb.WithOutput(os.Stderr))
+		}
+		bar = p.Add(d.size,
+			mpb.NewBarFiller(mpb.BarStyle().Lbound("[").Filler("=").Tip(">").Padding("-").Rbound("|")),
+			mpb.PrependDecorators(
+				decor.CountersKibiByte("\t% .2f / % .2f"),
+			),
+			mpb.AppendDecorators(
+				decor.OnComplete(decor.AverageETA(decor.ET_STYLE_GO), "✅ "),
+				decor.Name(" ] "),
+				decor.AverageSpeed(decor.UnitKiB, "% .2f"),
+			),
+		)
+	}
+	done := func(err error) error {
+		if bar != nil {
+			if err != nil {
+				bar.Abort(false)
+			}
+			p.Wait()
+		}
+		return d.finish(err)
+	}
+
+	for {
+		if !d.waitResume() {
+			return done(ErrCanceled)
+		}
+		d.setState(StateRunning)
+		start := time.Now()
+		err := d.stream(ctx, sw, bar)
+		d.mu.Lock()
+		d.stats.Active += time.Since(start)
+		d.mu.Unlock()
+		if err == nil {
+			break
+		}
+		if sw.err != nil {
+			return done(fmt.Errorf("failed to write download: %v", sw.err))
+		}
+		if ctx.Err() != nil {
+			return done(ctx.Err())
+		}
+		if errors.Is(err, ErrPaused) {
+			continue
+		}
+		policy := getRetryPolicy()
+		if !retryableError(err) || !d.canResume || d.retries+1 >= policy.MaxAttempts {
+			return done(err)
+		}
+		d.retries++
+		delay := policy.Backoff(d.retries)
+		utils.Indent(d.logger().WithError(err).Warn, 2)(fmt.Sprintf("Download interrupted, resuming at byte %d in %s (attempt %d/%d)", sw.n, delay.Round(time.Millisecond), d.retries+1, policy.MaxAttempts))
+		d.mu.Lock()
+		d.stats.Retries++
+		d.mu.Unlock()
+		select {
+		case <-ctx.Done():
+			return done(ctx.Err())
+		case <-time.After(delay):
+		}
+	}
+
+	if sw.hash != nil {
+		alg, expected, _ := d.checksum()
+		if v := d.verification(alg, expected, sw.hash); !v.Verified {
+			return done(exitcode.Wrap(exitcode.Verification, fmt.Errorf("%w: %s %s hash is incorrect", ErrBadChecksum, d.URL, alg)))
+		}
+	}
+
+	d.reportProgress(true)
+	return done(nil)
+}
+
+// stream sends a single (ranged) request and copies its body into sw
+func (d *Download) stream(ctx context.Context, sw *streamWriter, bar *mpb.Bar) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	d.mu.Lock()
+	d.cancel = cancel
+	ctx = context.WithValue(ctx, pausesKey{}, d.pauses)
+	d.mu.Unlock()
+	if err := d.interrupted(ctx); err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", d.URL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create http GET request: %v", err)
+	}
+	req.Header.Add("User-Agent", utils.RandomAgent())
+	for k, v := range d.Headers {
+		req.Header.Add(k, v)
+	}
+	if sw.n > 0 {
+		req.Header.Add("Range", fmt.Sprintf("bytes=%d-", sw.n))
+		if v := d.ifRange(); v != "" {
+			req.Header.Add("If-Range", v)
+		}
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		if ierr := d.interrupted(ctx); ierr != nil {
+			return ierr
+		}
+		return fmt.Errorf("failed to download file: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if sw.n > 0 && resp.StatusCode != http.StatusPartialContent {
+		// the bytes already written can't be taken back (so restarting would corrupt the stream)
+		return fmt.Errorf("server cannot resume the download at byte %d: %s", sw.n, resp.Status)
+	}
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusPartialContent {
+		return fmt.Errorf("server return status: %s", resp.Status)
+	}
+
+	var body io.ReadCloser = d.statsReader(d.throttle(ctx, resp.Body))
+	if bar != nil {
+		body = bar.ProxyReader(body)
+	}
+	if _, err := io.Copy(sw, body); err != nil {
+		if ierr := d.interrupted(ctx); ierr != nil {
+			return ierr
+		}
+		return fmt.Errorf("failed to copy body reader data: %w", err)
+	}
+	if d.size > 0 && sw.n < d.size {
+		return fmt.Errorf("connection closed at byte %d of %d: %w", sw.n, d.size, io.ErrUnexpectedEOF)
+	}
+	return nil
+}
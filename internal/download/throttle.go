@@ -0,0 +1,114 @@
+//go:build !wasm
+
+package download
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/dustin/go-humanize"
+	"golang.org/x/time/rate"
+)
+
+// throttleBurst is the most bytes a throttled reader reads at once
+const throttleBurst = 64 * 1024
+
+var (
+	globalLimitMu sync.Mutex
+	globalLimit   *rate.Limiter
+)
+
+// SetRateLimit limits the combined bandwidth of all downloads to bytesPerSec (<= 0 is unlimited)
+func SetRateLimit(bytesPerSec int64) {
+	globalLimitMu.Lock()
+	defer globalLimitMu.Unlock()
+	globalLimit = newLimiter(bytesPerSec)
+}
+
+// ParseRate parses a curl style --limit-rate value (i.e. 500K, 10M or 1G) into bytes per second
+//
+// The K, M and G suffixes are powers of 1024 (as in curl); an optional "/s" suffix is ignored.
+func ParseRate(s string) (int64, error) {
+	v := strings.TrimSuffix(strings.TrimSpace(s), "/s")
+	switch {
+	case len(v) == 0:
+		return 0, fmt.Errorf("invalid rate '%s'", s)
+	case strings.ContainsAny(v[len(v)-1:], "kKmMgG"):
+		v += "iB"
+	}
+	n, err := humanize.ParseBytes(v)
+	if err != nil {
+		return 0, fmt.Errorf("invalid rate '%s': %v", s, err)
+	}
+	return int64(n), nil
+}
+
+func newLimiter(bytesPerSec int64) *rate.Limiter {
+	if bytesPerSec <= 0 {
+		return nil
+	}
+	burst := throttleBurst
+	if bytesPerSec < throttleBurst {
+		burst = int(bytesPerSec)
+	}
+	return rate.NewLimiter(rate.Limit(bytesPerSec), burst)
+}
+
+// limiters returns the rate limiters that apply to the download (its own and the global one)
+func (d *Download) limiters() []*rate.Limiter {
+	var limiters []*rate.Limiter
+	d.mu.Lock()
+	if d.limiter == nil && d.RateLimit > 0 {
+		d.limiter = newLimiter(d.RateLimit)
+	}
+	if d.limiter != nil {
+		limiters = append(limiters, d.limiter)
+	}
+	d.mu.Unlock()
+	globalLimitMu.Lock()
+	if globalLimit != nil {
+		limiters = append(limiters, globalLimit)
+	}
+	globalLimitMu.Unlock()
+	return limiters
+}
+
+// throttledReader is a rate limited io.ReadCloser
+type throttledReader struct {
+	io.ReadCloser
+	ctx      context.Context
+	limiters []*rate.Limiter
+}
+
+// throttle rate limits r with the download's limiters (the connections of a chunked download share them)
+func (d *Download) throttle(ctx context.Context, r io.ReadCloser) io.ReadCloser {
+	limiters := d.limiters()
+	if len(limiters) == 0 {
+		return r
+	}
+	return &throttledReader{ReadCloser: r, ctx: ctx, limiters: limiters}
+}
+
+func (r *throttledReader) Read(p []byte) (int, error) {
+	burst := throttleBurst
+	for _, l := range r.limiters {
+		if b := l.Burst(); b < burst {
+			burst = b
+		}
+	}
+	if len(p) > burst {
+		p = p[:burst]
+	}
+	n, err := r.ReadCloser.Read(p)
+	if n > 0 {
+		for _, l := range r.limiters {
+			if werr := l.WaitN(r.ctx, n); werr != nil && err == nil {
+				err = werr
+			}
+		}
+	}
+	return n, err
+}
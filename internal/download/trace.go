@@ -0,0 +1,79 @@
+package download
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/apex/log"
+)
+
+type traceKey struct{}
+
+// NewTraceID returns a random correlation ID for an operation
+func NewTraceID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "00000000"
+	}
+	return hex.EncodeToString(b)
+}
+
+// WithTraceID returns a copy of ctx that carries the correlation ID of an operation; the downloads and
+// metadata lookups run with it add the ID to their log lines and errors (see TracedError)
+func WithTraceID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, traceKey{}, id)
+}
+
+// TraceID returns the correlation ID carried by ctx (empty if none)
+func TraceID(ctx context.Context) string {
+	if id, ok := ctx.Value(traceKey{}).(string); ok {
+		return id
+	}
+	return ""
+}
+
+// ensureTraceID returns ctx with a new correlation ID if it does not already carry one
+func ensureTraceID(ctx context.Context) context.Context {
+	if len(TraceID(ctx)) > 0 {
+		return ctx
+	}
+	return WithTraceID(ctx, NewTraceID())
+}
+
+// TracedError is the error of an operation with a correlation ID
+type TracedError struct {
+	ID  string
+	Err error
+}
+
+func (e *TracedError) Error() string {
+	return fmt.Sprintf("%v (trace %s)", e.Err, e.ID)
+}
+
+func (e *TracedError) Unwrap() error {
+	return e.Err
+}
+
+// traced wraps err with the correlation ID of ctx (errors that already carry it are returned as is)
+func traced(ctx context.Context, err error) error {
+	id := TraceID(ctx)
+	if err == nil || len(id) == 0 {
+		return err
+	}
+	var te *TracedError
+	if errors.As(err, &te) && te.ID == id {
+		return err
+	}
+	return &TracedError{ID: id, Err: err}
+}
+
+// ctxLogger returns a log entry of the logger of ctx with its correlation ID (if any)
+func ctxLogger(ctx context.Context) *log.Entry {
+	if id := TraceID(ctx); len(id) > 0 {
+		return loggerFrom(ctx).WithField("trace", id)
+	}
+	return loggerFrom(ctx).WithFields(log.Fields{})
+}
@@ -0,0 +1,90 @@
+package download
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/99designs/keyring"
+	"github.com/blacktop/ipsw/internal/utils"
+)
+
+// Keyring backends (in addition to the platform specific keyring.BackendType names)
+const (
+	KeyringBackendAuto   = "auto"   // system keyring, falling back to the file vault and then env-only mode
+	KeyringBackendSystem = "system" // any available OS credential store (Keychain, Secret Service, wincred, etc.)
+	KeyringBackendEnv    = "env"    // in-memory only (credentials must come from flags/env and nothing is persisted)
+)
+
+// KeyringBackends returns the list of supported keyring backends
+func KeyringBackends() []string {
+	return []string{
+		KeyringBackendAuto,
+		KeyringBackendSystem,
+		KeyringBackendEnv,
+		string(keyring.KeychainBackend),
+		string(keyring.SecretServiceBackend),
+		string(keyring.KWalletBackend),
+		string(keyring.KeyCtlBackend),
+		string(keyring.WinCredBackend),
+		string(keyring.PassBackend),
+		string(keyring.FileBackend),
+	}
+}
+
+// OpenVault opens the credential vault using the requested keyring backend.
+//
+// In auto mode a failure to open the system keyring (e.g. no D-Bus or cgo keychain
+// support in a minimal container) falls back to the encrypted file vault, and if that
+// also fails to an in-memory vault where credentials must be supplied via flags or env.
+func OpenVault(config keyring.Config, backend string, canUseFile bool) (keyring.Keyring, error) {
+	switch backend = strings.ToLower(backend); backend {
+	case "", KeyringBackendAuto:
+		vault, err := keyring.Open(config)
+		if err == nil {
+			return vault, nil
+		}
+		getLogger().WithError(err).Warn("failed to open system keyring")
+		if canUseFile {
+			config.AllowedBackends = []keyring.BackendType{keyring.FileBackend}
+			if vault, err = keyring.Open(config); err == nil {
+				utils.Indent(getLogger().Warn, 2)("falling back to encrypted file vault in " + config.FileDir)
+				return vault, nil
+			}
+			getLogger().WithError(err).Warn("failed to open file vault")
+		}
+		utils.Indent(getLogger().Warn, 2)("falling back to env-only mode (credentials will NOT be saved; set --keyring-backend to silence this warning)")
+		return keyring.NewArrayKeyring(nil), nil
+	case KeyringBackendSystem:
+		var backends []keyring.BackendType
+		for _, b := range keyring.AvailableBackends() {
+			if b != keyring.FileBackend {
+				backends = append(backends, b)
+			}
+		}
+		if len(backends) == 0 {
+			return nil, fmt.Errorf("no system keyring backends available (use --keyring-backend file or env): %w", utils.ErrUnsupported)
+		}
+		config.AllowedBackends = backends
+		return keyring.Open(config)
+	case KeyringBackendEnv:
+		return keyring.NewArrayKeyring(nil), nil
+	default:
+		if !slices.Contains(KeyringBackends(), backend) {
+			return nil, fmt.Errorf("invalid keyring backend '%s' (must be one of: %s)", backend, strings.Join(KeyringBackends(), ", "))
+		}
+		if !slices.Contains(keyring.AvailableBackends(), keyring.BackendType(backend)) {
+			var available []string
+			for _, b := range keyring.AvailableBackends() {
+				available = append(available, string(b))
+			}
+			return nil, fmt.Errorf("keyring backend '%s' is not available on this platform (available: %s): %w", backend, strings.Join(available, ", "), utils.ErrUnsupported)
+		}
+		config.AllowedBackends = []keyring.BackendType{keyring.BackendType(backend)}
+		vault, err := keyring.Open(config)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open '%s' keyring: %v", backend, err)
+		}
+		return vault, nil
+	}
+}
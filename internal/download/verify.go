@@ -0,0 +1,137 @@
+//go:build !wasm
+
+package download
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/apex/log"
+	"github.com/blacktop/ipsw/internal/exitcode"
+	"github.com/blacktop/ipsw/internal/utils"
+)
+
+// DefaultVerifyRetries is the number of times a download is re-fetched when its checksum is incorrect
+const DefaultVerifyRetries = 2
+
+var (
+	verifyRetriesMu sync.Mutex
+	verifyRetries   = DefaultVerifyRetries
+)
+
+// SetVerifyRetries sets the number of times a download is deleted and re-fetched when its checksum is incorrect
+func SetVerifyRetries(n int) {
+	verifyRetriesMu.Lock()
+	defer verifyRetriesMu.Unlock()
+	if n < 0 {
+		n = 0
+	}
+	verifyRetries = n
+}
+
+func getVerifyRetries() int {
+	verifyRetriesMu.Lock()
+	defer verifyRetriesMu.Unlock()
+	return verifyRetries
+}
+
+// Verification is the result of verifying a download's checksum
+type Verification struct {
+	Algorithm string `json:"algorithm"`
+	Expected  string `json:"expected"`
+	Actual    string `json:"actual"`
+	Verified  bool   `json:"verified"`
+	Attempts  int    `json:"attempts"` // number of times the file was downloaded
+}
+
+// checksum returns the strongest checksum the download can be verified against (alg is empty if there is none)
+func (d *Download) checksum() (alg, expected string, newHash func() hash.Hash) {
+	switch {
+	case d.ignoreSha1:
+		return "", "", nil
+	case len(d.SHA256) > 0:
+		return "sha256", d.SHA256, sha256.New
+	case len(d.Sha1) > 0:
+		return "sha1", d.Sha1, sha1.New
+	case len(d.MD5) > 0:
+		return "md5", d.MD5, md5.New
+	}
+	return "", "", nil
+}
+
+// newHash returns a hash to stream the download through (nil if there is nothing to verify)
+func (d *Download) newHash() hash.Hash {
+	if _, _, newHash := d.checksum(); newHash != nil {
+		return newHash()
+	}
+	return nil
+}
+
+// verify checks the partial download's checksum and removes it on mismatch so that it can be re-fetched
+//
+// h is the hash of the bytes streamed to the partial download (nil hashes the file instead)
+func (d *Download) verify(h hash.Hash) error {
+	alg, expected, newHash := d.checksum()
+	if newHash == nil {
+		return nil
+	}
+
+	utils.Indent(d.logger().Info, 2)(fmt.Sprintf("verifying %ssum...", alg))
+	if h == nil {
+		f, err := os.Open(d.DestName + ".download")
+		if err != nil {
+			return fmt.Errorf("failed to open %s: %v", d.DestName+".download", err)
+		}
+		defer f.Close()
+		h = newHash()
+		if _, err := io.Copy(h, f); err != nil {
+			return fmt.Errorf("failed to hash %s: %v", d.DestName+".download", err)
+		}
+	}
+
+	if v := d.verification(alg, expected, h); v.Verified {
+		return nil
+	}
+
+	d.removeState()
+	if err := os.Remove(d.DestName + ".download"); err != nil {
+		return fmt.Errorf("cannot remove downloaded file with checksum mismatch: %v", err)
+	}
+	return exitcode.Wrap(exitcode.Verification, fmt.Errorf("%w: %s %s hash is incorrect", ErrBadChecksum, d.DestName, alg))
+}
+
+// verification records the result of comparing the download's hash to its expected checksum
+func (d *Download) verification(alg, expected string, h hash.Hash) *Verification {
+	v := &Verification{
+		Algorithm: alg,
+		Expected:  strings.ToLower(expected),
+		Actual:    hex.EncodeToString(h.Sum(nil)),
+		Attempts:  d.refetches + 1,
+	}
+	v.Verified = v.Expected == v.Actual
+	d.mu.Lock()
+	d.stats.Verification = v
+	d.mu.Unlock()
+
+	if !v.Verified {
+		utils.Indent(d.logger().WithFields(log.Fields{
+			"expected": v.Expected,
+			"actual":   v.Actual,
+		}).Error, 3)("❌ BAD CHECKSUM")
+	}
+	return v
+}
+
+// canRefetch returns true if a download with an incorrect checksum should be downloaded again
+func (d *Download) canRefetch(err error) bool {
+	return errors.Is(err, ErrBadChecksum) && d.refetches < getVerifyRetries()
+}
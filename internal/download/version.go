@@ -0,0 +1,92 @@
+package download
+
+import (
+	"runtime"
+	"runtime/debug"
+)
+
+// CAPIVersion is the version of the C API (libipsw.h): the major version is bumped when an exported
+// function or JSON schema changes incompatibly, the minor version when one is added
+const CAPIVersion = "1.5.0"
+
+// buildCommit is the git commit of the build (set with -ldflags "-X github.com/blacktop/ipsw/internal/download.buildCommit=<sha>"
+// when the build has no VCS information, i.e. a build outside of a git checkout)
+var buildCommit string
+
+// sources are the metadata and download sources compiled into the package (the native builds add
+// developer.apple.com in native.go)
+var sources = []string{"ipsw.me", "ota", "theapplewiki", "appledb", "xcode"}
+
+// Capabilities describes what a build of the package supports
+type Capabilities struct {
+	// Sources are the metadata and download sources of the build (i.e. ipsw.me, developer.apple.com)
+	Sources []string `json:"sources"`
+	CGO     bool     `json:"cgo"`  // the C API is available
+	WASM    bool     `json:"wasm"` // js/wasm or wasip1 build
+	OS      string   `json:"os"`
+	Arch    string   `json:"arch"`
+}
+
+// BuildInfo is the version of the package (and of the C API) and what it was built with
+type BuildInfo struct {
+	Version      string       `json:"version"` // the ipsw module version ((devel) for a source build)
+	CAPIVersion  string       `json:"c_api_version"`
+	Commit       string       `json:"commit,omitempty"`
+	Modified     bool         `json:"modified,omitempty"` // the build had uncommitted changes
+	GoVersion    string       `json:"go_version"`
+	Capabilities Capabilities `json:"capabilities"`
+}
+
+// GetCapabilities returns the Capabilities of the build
+func GetCapabilities() Capabilities {
+	return Capabilities{
+		Sources: append([]string(nil), sources...),
+		CGO:     ffiEnabled,
+		WASM:    runtime.GOARCH == "wasm",
+		OS:      runtime.GOOS,
+		Arch:    runtime.GOARCH,
+	}
+}
+
+// Version returns the BuildInfo of the package so that callers (i.e. language bindings) can check
+// their compatibility at runtime
+func Version() BuildInfo {
+	info := BuildInfo{
+		Version:      "(devel)",
+		CAPIVersion:  CAPIVersion,
+		Commit:       buildCommit,
+		GoVersion:    runtime.Version(),
+		Capabilities: GetCapabilities(),
+	}
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+	if bi.Main.Path == "github.com/blacktop/ipsw" {
+		if len(bi.Main.Version) > 0 {
+			info.Version = bi.Main.Version
+		}
+	} else {
+		for _, dep := range bi.Deps {
+			if dep.Path == "github.com/blacktop/ipsw" {
+				info.Version = dep.Version
+				if dep.Replace != nil && len(dep.Replace.Version) > 0 {
+					info.Version = dep.Replace.Version
+				}
+				break
+			}
+		}
+		return info // the VCS settings are the ones of the main module
+	}
+	for _, s := range bi.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			if len(info.Commit) == 0 {
+				info.Commit = s.Value
+			}
+		case "vcs.modified":
+			info.Modified = s.Value == "true"
+		}
+	}
+	return info
+}
@@ -0,0 +1,25 @@
+//go:build ffi
+
+package download
+
+//#cgo LDFLAGS:
+//#include <stdio.h>
+//#include <stdlib.h>
+//#include <string.h>
+import "C"
+
+// c_libipsw_version returns the version of the library as JSON: the ipsw module version, the C API
+// version, the git commit, the Go version and the capabilities of c_libipsw_capabilities
+//
+//export c_libipsw_version
+func c_libipsw_version(outJson **C.char, outJsonLen *C.uint, err **C.char, errLen *C.uint) C.char {
+	return setCJSON(Version(), outJson, outJsonLen, err, errLen, "c_libipsw_version", "BuildInfo")
+}
+
+// c_libipsw_capabilities returns what the library supports as JSON: its sources (i.e. ipsw.me,
+// developer.apple.com) and whether it was built with cgo or for wasm
+//
+//export c_libipsw_capabilities
+func c_libipsw_capabilities(outJson **C.char, outJsonLen *C.uint, err **C.char, errLen *C.uint) C.char {
+	return setCJSON(GetCapabilities(), outJson, outJsonLen, err, errLen, "c_libipsw_capabilities", "Capabilities")
+}
@@ -0,0 +1,17 @@
+//go:build wasm
+
+package download
+
+import "fmt"
+
+// The wasm builds (js/wasm for browsers and wasip1) contain the metadata lookups but not the downloads:
+// the Download engine, its Manager and the dev portal render progress bars and prompts that need a
+// terminal, so their files are built with !wasm. The lookups' requests go through fetchTransport.
+
+// sharedTransport is the base transport of the metadata clients
+var sharedTransport = fetchTransport{}
+
+// cancelManagerDownload fails: the wasm builds have no download Manager
+func cancelManagerDownload(id string) error {
+	return fmt.Errorf("unknown handle %s: %w", id, ErrInvalidArgument)
+}
@@ -0,0 +1,77 @@
+package download
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	semver "github.com/hashicorp/go-version"
+)
+
+// WatchRestoreNote explains why watchOS firmware can't simply be restored like an iPhone's
+const WatchRestoreNote = "watchOS is installed OTA by the paired iPhone; Apple Watch IPSWs can only be restored over the hidden diagnostic port (Series 0-2) or by Apple"
+
+// WatchCompatibility are the pairing constraints of a watchOS major version
+type WatchCompatibility struct {
+	WatchOS   int    `json:"watchos"`
+	MinIOS    string `json:"min_ios"`    // minimum iOS version the paired iPhone must run
+	MinIPhone string `json:"min_iphone"` // oldest iPhone that can pair
+	Note      string `json:"note,omitempty"`
+}
+
+func (c WatchCompatibility) String() string {
+	return fmt.Sprintf("watchOS %d requires an %s or later running iOS %s+", c.WatchOS, c.MinIPhone, c.MinIOS)
+}
+
+// watchCompatibility is the iPhone/iOS a watchOS major version must be paired with
+var watchCompatibility = []WatchCompatibility{
+	{WatchOS: 1, MinIOS: "8.2", MinIPhone: "iPhone 5"},
+	{WatchOS: 2, MinIOS: "9.0", MinIPhone: "iPhone 5"},
+	{WatchOS: 3, MinIOS: "10.0", MinIPhone: "iPhone 5"},
+	{WatchOS: 4, MinIOS: "11.0", MinIPhone: "iPhone 5s"},
+	{WatchOS: 5, MinIOS: "12.0", MinIPhone: "iPhone 5s"},
+	{WatchOS: 6, MinIOS: "13.0", MinIPhone: "iPhone 6s"},
+	{WatchOS: 7, MinIOS: "14.0", MinIPhone: "iPhone 6s"},
+	{WatchOS: 8, MinIOS: "15.0", MinIPhone: "iPhone 6s"},
+	{WatchOS: 9, MinIOS: "16.0", MinIPhone: "iPhone 8"},
+	{WatchOS: 10, MinIOS: "17.0", MinIPhone: "iPhone XS"},
+	{WatchOS: 11, MinIOS: "18.0", MinIPhone: "iPhone XS"},
+	{WatchOS: 26, MinIOS: "26.0", MinIPhone: "iPhone 11"},
+}
+
+// GetWatchCompatibility returns the pairing constraints for a watchOS version
+func GetWatchCompatibility(version string) (*WatchCompatibility, error) {
+	version = strings.TrimPrefix(version, "9.9.") // OTA versions are prefixed
+	major, _, _ := strings.Cut(version, ".")
+	m, err := strconv.Atoi(major)
+	if err != nil {
+		return nil, fmt.Errorf("invalid watchOS version %#v", version)
+	}
+	for _, c := range watchCompatibility {
+		if c.WatchOS == m {
+			c.Note = WatchRestoreNote
+			return &c, nil
+		}
+	}
+	// newer releases follow the same (year based) iOS version
+	if last := watchCompatibility[len(watchCompatibility)-1]; m > last.WatchOS {
+		return &WatchCompatibility{WatchOS: m, MinIOS: fmt.Sprintf("%d.0", m), MinIPhone: last.MinIPhone, Note: WatchRestoreNote}, nil
+	}
+	return nil, fmt.Errorf("unknown watchOS version %s", version)
+}
+
+// CheckPairedIOS returns an error if an iPhone running iOS version can't pair with (and install) this watchOS
+func (c WatchCompatibility) CheckPairedIOS(iosVersion string) error {
+	have, err := semver.NewVersion(iosVersion)
+	if err != nil {
+		return fmt.Errorf("invalid iOS version %s: %v", iosVersion, err)
+	}
+	need, err := semver.NewVersion(c.MinIOS)
+	if err != nil {
+		return err
+	}
+	if have.LessThan(need) {
+		return fmt.Errorf("watchOS %d can't be installed from an iPhone running iOS %s (requires iOS %s+)", c.WatchOS, iosVersion, c.MinIOS)
+	}
+	return nil
+}
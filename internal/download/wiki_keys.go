@@ -0,0 +1,219 @@
+package download
+
+import (
+	"crypto/aes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var wikiKeysTemplateRegex = regexp.MustCompile(`^\s*\|\s*([A-Za-z0-9_]+)\s*=\s*(.*?)\s*$`)
+
+// FirmwareKey is the decryption key info for a single firmware component
+type FirmwareKey struct {
+	Filename string `json:"filename,omitempty"`
+	IV       string `json:"iv,omitempty"`
+	Key      string `json:"key,omitempty"`
+	KBAG     string `json:"kbag,omitempty"`
+}
+
+// IVKey returns the decoded AES iv and key
+func (k FirmwareKey) IVKey() ([]byte, []byte, error) {
+	if len(k.IV) == 0 || len(k.Key) == 0 {
+		return nil, nil, fmt.Errorf("missing iv or key for %s", k.Filename)
+	}
+	iv, err := hex.DecodeString(k.IV)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to decode iv for %s: %v", k.Filename, err)
+	}
+	if len(iv) != aes.BlockSize {
+		return nil, nil, fmt.Errorf("invalid iv length %d for %s", len(iv), k.Filename)
+	}
+	key, err := hex.DecodeString(k.Key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to decode key for %s: %v", k.Filename, err)
+	}
+	return iv, key, nil
+}
+
+// FirmwareKeys are the firmware decryption keys for a device/build
+type FirmwareKeys struct {
+	Version     string                 `json:"version,omitempty"`
+	Build       string                 `json:"build,omitempty"`
+	Device      string                 `json:"device,omitempty"`
+	Codename    string                 `json:"codename,omitempty"`
+	Baseband    string                 `json:"baseband,omitempty"`
+	DownloadURL string                 `json:"download_url,omitempty"`
+	Page        string                 `json:"page,omitempty"`
+	Keys        map[string]FirmwareKey `json:"keys,omitempty"`
+}
+
+// Components returns the sorted names of the components with keys
+func (fk FirmwareKeys) Components() []string {
+	var comps []string
+	for name := range fk.Keys {
+		comps = append(comps, name)
+	}
+	sort.Strings(comps)
+	return comps
+}
+
+// Get returns the key for a component name (i.e. iBoot, Kernelcache) OR im4p filename (i.e. iBoot.d83.RELEASE.im4p)
+func (fk FirmwareKeys) Get(name string) (*FirmwareKey, error) {
+	for comp, k := range fk.Keys {
+		if strings.EqualFold(comp, name) {
+			return &k, nil
+		}
+	}
+	base := filepath.Base(name)
+	for _, k := range fk.Keys {
+		if len(k.Filename) > 0 && strings.EqualFold(k.Filename, base) {
+			return &k, nil
+		}
+	}
+	return nil, fmt.Errorf("no keys found for %s in %s (%s); available: %s", name, fk.Build, fk.Device, strings.Join(fk.Components(), ", "))
+}
+
+// parseWikiKeys normalizes a theapplewiki.com {{keys}} template into FirmwareKeys
+func parseWikiKeys(text string) (*FirmwareKeys, error) {
+	fields := make(map[string]string)
+	for _, line := range strings.Split(text, "\n") {
+		if m := wikiKeysTemplateRegex.FindStringSubmatch(line); m != nil {
+			fields[m[1]] = m[2]
+		}
+	}
+
+	fk := &FirmwareKeys{
+		Version:     fields["Version"],
+		Build:       fields["Build"],
+		Device:      fields["Device"],
+		Codename:    fields["Codename"],
+		Baseband:    fields["Baseband"],
+		DownloadURL: fields["DownloadURL"],
+		Keys:        make(map[string]FirmwareKey),
+	}
+
+	if len(fk.Build) == 0 {
+		return nil, fmt.Errorf("failed to find keys template")
+	}
+
+	valid := func(val string) bool {
+		return len(val) > 0 && !strings.EqualFold(val, "unknown") && !strings.EqualFold(val, "not encrypted") && !strings.HasPrefix(val, "TODO")
+	}
+
+	for name, val := range fields {
+		var comp, kind string
+		switch {
+		case strings.HasSuffix(name, "KBAG"):
+			comp, kind = strings.TrimSuffix(name, "KBAG"), "kbag"
+		case strings.HasSuffix(name, "IV"):
+			comp, kind = strings.TrimSuffix(name, "IV"), "iv"
+		case strings.HasSuffix(name, "Key"):
+			comp, kind = strings.TrimSuffix(name, "Key"), "key"
+		default:
+			continue
+		}
+		if len(comp) == 0 || !valid(val) {
+			continue
+		}
+		k := fk.Keys[comp]
+		k.Filename = fields[comp]
+		switch kind {
+		case "kbag":
+			k.KBAG = strings.ToLower(val)
+		case "iv":
+			k.IV = strings.ToLower(val)
+		case "key":
+			k.Key = strings.ToLower(val)
+		}
+		fk.Keys[comp] = k
+	}
+
+	return fk, nil
+}
+
+// findWikiKeysPage searches theapplewiki.com for the keys page of a device/build (i.e. "Keys:Yukon 17A577 (iPhone12,1)")
+func findWikiKeysPage(device, build, proxy string, insecure bool) (string, error) {
+	client := &http.Client{
+		Transport: BudgetTransport(NewTransport(proxy, insecure)),
+	}
+
+	req, err := http.NewRequest("GET", iphoneWikiApiURL, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+
+	q := req.URL.Query()
+	q.Add("format", "json")
+	q.Add("action", "query")
+	q.Add("list", "search")
+	q.Add("srsearch", fmt.Sprintf("%s %s", build, device))
+	q.Add("srwhat", "title")
+	q.Add("srnamespace", "*")
+	q.Add("srlimit", "50")
+	req.URL.RawQuery = q.Encode()
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to get response: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to get response: %s", resp.Status)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read response: %w", err)
+	}
+
+	var searchResp struct {
+		Query struct {
+			Search []struct {
+				Title string `json:"title"`
+			} `json:"search"`
+		} `json:"query"`
+	}
+	if err := json.Unmarshal(data, &searchResp); err != nil {
+		return "", fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	suffix := fmt.Sprintf(" %s (%s)", build, device)
+	for _, result := range searchResp.Query.Search {
+		if strings.HasPrefix(result.Title, "Keys:") && strings.HasSuffix(strings.ToLower(result.Title), strings.ToLower(suffix)) {
+			return result.Title, nil
+		}
+	}
+
+	return "", fmt.Errorf("no keys page found for %s (%s)", build, device)
+}
+
+// GetWikiFirmwareKeysForBuild queries theapplewiki.com for the firmware decryption keys of a device/build
+func GetWikiFirmwareKeysForBuild(device, build, proxy string, insecure bool) (*FirmwareKeys, error) {
+	page, err := findWikiKeysPage(device, build, proxy, insecure)
+	if err != nil {
+		return nil, err
+	}
+
+	getLogger().Debugf("Parsing wiki page: '%s'", page)
+
+	wtable, err := getWikiTable(page, proxy, insecure)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get wikitext for %s: %w", page, err)
+	}
+
+	fk, err := parseWikiKeys(wtable.Parse.WikiText.Text)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse keys page %s: %w", page, err)
+	}
+	fk.Page = page
+
+	return fk, nil
+}
@@ -8,7 +8,6 @@ import (
 	"encoding/xml"
 	"fmt"
 	"io"
-	"net/http"
 	"path"
 	"sort"
 	"strings"
@@ -67,7 +66,7 @@ type DVTDownloadable struct {
 // GetDVTDownloadableIndex returns the DVTDownloadableIndex plist
 func GetDVTDownloadableIndex() (*DVTDownloadable, error) {
 
-	resp, err := http.Get(dvtURL)
+	resp, err := defaultClient.Get(dvtURL)
 	if err != nil {
 		return nil, err
 	}
@@ -88,6 +87,54 @@ func GetDVTDownloadableIndex() (*DVTDownloadable, error) {
 	return &dvt, nil
 }
 
+var simPlatforms = map[string]string{
+	"ios":      "com.apple.platform.iphoneos",
+	"watchos":  "com.apple.platform.watchos",
+	"tvos":     "com.apple.platform.appletvos",
+	"visionos": "com.apple.platform.xros",
+	"xros":     "com.apple.platform.xros",
+}
+
+// IsSimulatorRuntime returns true if the downloadable is a simulator runtime
+func (d Downloadable) IsSimulatorRuntime() bool {
+	return d.Category == "simulator" || strings.HasSuffix(d.Name, "Simulator")
+}
+
+// RequiresAuth returns true if the downloadable requires a dev portal session
+func (d Downloadable) RequiresAuth() bool {
+	return len(d.Authentication) > 0
+}
+
+// GetSimulatorRuntimes returns the simulator runtimes for the given platform (ios, watchos, tvos, visionos) and version
+// NOTE: an empty platform or version matches all
+func (dvt *DVTDownloadable) GetSimulatorRuntimes(platform, version string) ([]Downloadable, error) {
+	var runtimes []Downloadable
+
+	var platformID string
+	if len(platform) > 0 {
+		var ok bool
+		platformID, ok = simPlatforms[strings.ToLower(platform)]
+		if !ok {
+			return nil, fmt.Errorf("unsupported simulator platform %s (supported: ios, watchos, tvos, visionos)", platform)
+		}
+	}
+
+	for _, dl := range dvt.Downloadables {
+		if !dl.IsSimulatorRuntime() {
+			continue
+		}
+		if len(platformID) > 0 && dl.Platform != platformID {
+			continue
+		}
+		if len(version) > 0 && !strings.HasPrefix(dl.SimulatorVersion.Version, version) {
+			continue
+		}
+		runtimes = append(runtimes, dl)
+	}
+
+	return runtimes, nil
+}
+
 type Contents struct {
 	Key            string
 	Generation     int64
@@ -124,7 +171,7 @@ type ListBucketResult struct {
 }
 
 func ListXCodes() (*ListBucketResult, error) {
-	resp, err := http.Get(XcodeDlURL)
+	resp, err := defaultClient.Get(XcodeDlURL)
 	if err != nil {
 		return nil, err
 	}
@@ -256,7 +303,7 @@ type XCodeRelease struct {
 func QueryXcodeReleasesAPI(name string) (string, error) {
 	name = strings.Replace(name, "-", "_", -1)
 
-	resp, err := http.Get(xcodeReleasesAPI)
+	resp, err := defaultClient.Get(xcodeReleasesAPI)
 	if err != nil {
 		return "", err
 	}
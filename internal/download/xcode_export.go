@@ -0,0 +1,83 @@
+//go:build ffi
+
+package download
+
+//#cgo LDFLAGS:
+//#include <stdio.h>
+//#include <stdlib.h>
+//#include <string.h>
+import "C"
+import (
+	"fmt"
+
+	"github.com/blacktop/ipsw/pkg/xcode"
+)
+
+// The xcode exports query the device traits embedded in the library (no network access);
+// a query that matches no device fails with LIBIPSW_ERR_NOT_FOUND.
+
+//export c_pkg_xcode_xcode_GetDevices
+func c_pkg_xcode_xcode_GetDevices(outJson **C.char, outJsonLen *C.uint, err **C.char, errLen *C.uint) C.char {
+	devices, devicesError := xcode.GetDevices()
+	if devicesError != nil {
+		return setCError(devicesError, fmt.Sprintf("c_pkg_xcode_xcode_GetDevices: GetDevices failed with %v", devicesError), err, errLen)
+	}
+	return setCJSON(devices, outJson, outJsonLen, err, errLen, "c_pkg_xcode_xcode_GetDevices", "Device objects")
+}
+
+// c_pkg_xcode_xcode_GetDeviceForProd returns the device with a product type (i.e. iPhone16,1)
+//
+//export c_pkg_xcode_xcode_GetDeviceForProd
+func c_pkg_xcode_xcode_GetDeviceForProd(prod *C.char, prodLen C.uint, outJson **C.char, outJsonLen *C.uint, err **C.char, errLen *C.uint) C.char {
+	return cXcodeDevice("c_pkg_xcode_xcode_GetDeviceForProd", xcode.GetDeviceForProd, C.GoStringN(prod, C.int(prodLen)), outJson, outJsonLen, err, errLen)
+}
+
+// c_pkg_xcode_xcode_GetDeviceForModel returns the device with a model (i.e. A2848)
+//
+//export c_pkg_xcode_xcode_GetDeviceForModel
+func c_pkg_xcode_xcode_GetDeviceForModel(model *C.char, modelLen C.uint, outJson **C.char, outJsonLen *C.uint, err **C.char, errLen *C.uint) C.char {
+	return cXcodeDevice("c_pkg_xcode_xcode_GetDeviceForModel", xcode.GetDeviceForModel, C.GoStringN(model, C.int(modelLen)), outJson, outJsonLen, err, errLen)
+}
+
+// c_pkg_xcode_xcode_GetDeviceForBoard returns the device with a board config (i.e. D83AP or d83)
+//
+//export c_pkg_xcode_xcode_GetDeviceForBoard
+func c_pkg_xcode_xcode_GetDeviceForBoard(board *C.char, boardLen C.uint, outJson **C.char, outJsonLen *C.uint, err **C.char, errLen *C.uint) C.char {
+	return cXcodeDevice("c_pkg_xcode_xcode_GetDeviceForBoard", xcode.GetDeviceForBoard, C.GoStringN(board, C.int(boardLen)), outJson, outJsonLen, err, errLen)
+}
+
+// c_pkg_xcode_xcode_GetDevicesForIdiom returns the devices of an idiom (phone, pad, watch or tv)
+//
+//export c_pkg_xcode_xcode_GetDevicesForIdiom
+func c_pkg_xcode_xcode_GetDevicesForIdiom(idiom *C.char, idiomLen C.uint, outJson **C.char, outJsonLen *C.uint, err **C.char, errLen *C.uint) C.char {
+	return cXcodeDevices("c_pkg_xcode_xcode_GetDevicesForIdiom", xcode.GetDevicesForIdiom, C.GoStringN(idiom, C.int(idiomLen)), outJson, outJsonLen, err, errLen)
+}
+
+// c_pkg_xcode_xcode_GetDevicesForChip returns the devices with a chip (i.e. t8130)
+//
+//export c_pkg_xcode_xcode_GetDevicesForChip
+func c_pkg_xcode_xcode_GetDevicesForChip(chip *C.char, chipLen C.uint, outJson **C.char, outJsonLen *C.uint, err **C.char, errLen *C.uint) C.char {
+	return cXcodeDevices("c_pkg_xcode_xcode_GetDevicesForChip", xcode.GetDevicesForChip, C.GoStringN(chip, C.int(chipLen)), outJson, outJsonLen, err, errLen)
+}
+
+func cXcodeDevice(name string, query func(string) (*xcode.Device, error), arg string, outJson **C.char, outJsonLen *C.uint, err **C.char, errLen *C.uint) C.char {
+	if len(arg) == 0 {
+		return setCError(ErrInvalidArgument, fmt.Sprintf("%s: %v: empty query", name, ErrInvalidArgument), err, errLen)
+	}
+	device, qerr := query(arg)
+	if qerr != nil {
+		return setCError(qerr, fmt.Sprintf("%s: %s: %v", name, arg, qerr), err, errLen)
+	}
+	return setCJSON(device, outJson, outJsonLen, err, errLen, name, "Device object")
+}
+
+func cXcodeDevices(name string, query func(string) ([]xcode.Device, error), arg string, outJson **C.char, outJsonLen *C.uint, err **C.char, errLen *C.uint) C.char {
+	if len(arg) == 0 {
+		return setCError(ErrInvalidArgument, fmt.Sprintf("%s: %v: empty query", name, ErrInvalidArgument), err, errLen)
+	}
+	devices, qerr := query(arg)
+	if qerr != nil {
+		return setCError(qerr, fmt.Sprintf("%s: %s: %v", name, arg, qerr), err, errLen)
+	}
+	return setCJSON(devices, outJson, outJsonLen, err, errLen, name, "Device objects")
+}
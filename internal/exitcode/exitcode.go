@@ -0,0 +1,89 @@
+// Package exitcode defines the ipsw CLI's exit codes so scripts can branch on the type of failure
+package exitcode
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Code is a process exit code
+type Code int
+
+const (
+	OK           Code = 0   // success
+	Failure      Code = 1   // unclassified error
+	Usage        Code = 2   // invalid flags/args or input required in --non-interactive mode
+	NotFound     Code = 3   // nothing matched the given device/version/build/pattern
+	Auth         Code = 4   // authentication or authorization failed
+	Partial      Code = 5   // some items succeeded before a later one failed
+	Verification Code = 6   // checksum or signature verification failed
+	Canceled     Code = 130 // canceled by the user (Ctrl-C)
+)
+
+// Codes documents every exit code (in order)
+var Codes = []struct {
+	Code        Code
+	Name        string
+	Description string
+}{
+	{OK, "ok", "Success"},
+	{Failure, "failure", "Unclassified error"},
+	{Usage, "usage", "Invalid flags/arguments or input required in --non-interactive mode"},
+	{NotFound, "not-found", "Nothing matched the given device, version, build or pattern"},
+	{Auth, "auth", "Authentication or authorization failed (Apple ID, 2FA, session, vault)"},
+	{Partial, "partial", "Some items succeeded before a later one failed"},
+	{Verification, "verification", "Checksum or signature verification failed"},
+	{Canceled, "canceled", "Canceled by the user"},
+}
+
+// Error is an error that carries the exit code the CLI should exit with
+type Error struct {
+	Code Code
+	Err  error
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Wrap attaches an exit code to err (returns nil if err is nil)
+func Wrap(code Code, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &Error{Code: code, Err: err}
+}
+
+// Errorf formats an error with an exit code
+func Errorf(code Code, format string, a ...any) error {
+	return &Error{Code: code, Err: fmt.Errorf(format, a...)}
+}
+
+// WrapPartial marks err as a partial success if done items already succeeded
+// (unless err already carries a more specific exit code)
+func WrapPartial(done int, err error) error {
+	if err == nil || done == 0 || Of(err) != Failure {
+		return err
+	}
+	return Wrap(Partial, err)
+}
+
+// Of returns the exit code for err
+func Of(err error) Code {
+	if err == nil {
+		return OK
+	}
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Code
+	}
+	if errors.Is(err, interruptErr) || errors.Is(err, context.Canceled) {
+		return Canceled
+	}
+	return Failure
+}
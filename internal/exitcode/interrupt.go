@@ -0,0 +1,8 @@
+//go:build !wasm
+
+package exitcode
+
+import "github.com/AlecAivazis/survey/v2/terminal"
+
+// interruptErr is the error of a prompt interrupted with Ctrl-C
+var interruptErr = terminal.InterruptErr
@@ -0,0 +1,8 @@
+//go:build wasm
+
+package exitcode
+
+import "errors"
+
+// interruptErr is the error of a prompt interrupted with Ctrl-C (there are no prompts in wasm builds)
+var interruptErr = errors.New("interrupt")
@@ -0,0 +1,602 @@
+// Package graphql is a minimal GraphQL executor for read-only APIs
+//
+// It supports queries with variables, arguments, aliases, nested selections and the
+// @skip/@include directives (no mutations, subscriptions, fragments or introspection;
+// use Schema.SDL to publish the schema instead).
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Scalars are the built-in scalar types
+var Scalars = []string{"ID", "String", "Int", "Float", "Boolean"}
+
+// Object is a GraphQL object type
+type Object struct {
+	Name        string
+	Description string
+	Fields      []*Field
+}
+
+func (o *Object) field(name string) *Field {
+	for _, f := range o.Fields {
+		if f.Name == name {
+			return f
+		}
+	}
+	return nil
+}
+
+// Field is a field of an object type
+type Field struct {
+	Name        string
+	Type        string // type reference (i.e. "[Device!]!")
+	Description string
+	Args        []*Arg
+	// Resolve returns the field's value (nil resolves the source struct field with the
+	// same json name or the source map key)
+	Resolve ResolveFunc
+}
+
+// Arg is a field argument
+type Arg struct {
+	Name        string
+	Type        string // type reference (i.e. "String!")
+	Default     any
+	Description string
+}
+
+// ResolveFunc resolves the value of a field
+type ResolveFunc func(p Params) (any, error)
+
+// Params are the inputs of a field resolver
+type Params struct {
+	Context context.Context
+	Source  any            // the parent object's value
+	Args    map[string]any // the coerced field arguments (with defaults)
+}
+
+// String returns the string argument name (or "" if not set)
+func (p Params) String(name string) string {
+	s, _ := p.Args[name].(string)
+	return s
+}
+
+// Int returns the int argument name (or 0 if not set)
+func (p Params) Int(name string) int {
+	n, _ := p.Args[name].(int)
+	return n
+}
+
+// Bool returns the boolean argument name (or false if not set)
+func (p Params) Bool(name string) bool {
+	b, _ := p.Args[name].(bool)
+	return b
+}
+
+// Has returns true if the argument name was set (or has a default)
+func (p Params) Has(name string) bool {
+	v, ok := p.Args[name]
+	return ok && v != nil
+}
+
+// Schema is a GraphQL schema
+type Schema struct {
+	Query *Object
+	types map[string]*Object
+}
+
+// NewSchema creates a schema with the query root type and the object types it references
+func NewSchema(query *Object, types ...*Object) (*Schema, error) {
+	s := &Schema{Query: query, types: make(map[string]*Object)}
+	for _, t := range append([]*Object{query}, types...) {
+		if _, dup := s.types[t.Name]; dup {
+			return nil, fmt.Errorf("graphql: duplicate type %s", t.Name)
+		}
+		s.types[t.Name] = t
+	}
+	for _, t := range s.types {
+		for _, f := range t.Fields {
+			if !s.known(namedType(f.Type)) {
+				return nil, fmt.Errorf("graphql: unknown type %s of field %s.%s", f.Type, t.Name, f.Name)
+			}
+			for _, a := range f.Args {
+				if !isScalar(namedType(a.Type)) {
+					return nil, fmt.Errorf("graphql: argument %s of field %s.%s must be a scalar", a.Name, t.Name, f.Name)
+				}
+			}
+		}
+	}
+	return s, nil
+}
+
+func (s *Schema) known(name string) bool {
+	_, ok := s.types[name]
+	return ok || isScalar(name)
+}
+
+// SDL returns the schema in the GraphQL schema definition language
+func (s *Schema) SDL() string {
+	var names []string
+	for name := range s.types {
+		if name != s.Query.Name {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	names = append([]string{s.Query.Name}, names...)
+
+	var sb strings.Builder
+	if s.Query.Name != "Query" {
+		fmt.Fprintf(&sb, "schema {\n  query: %s\n}\n\n", s.Query.Name)
+	}
+	for i, name := range names {
+		t := s.types[name]
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+		writeDescription(&sb, t.Description, "")
+		fmt.Fprintf(&sb, "type %s {\n", t.Name)
+		for _, f := range t.Fields {
+			writeDescription(&sb, f.Description, "  ")
+			sb.WriteString("  " + f.Name)
+			if len(f.Args) > 0 {
+				var args []string
+				for _, a := range f.Args {
+					arg := a.Name + ": " + a.Type
+					if a.Default != nil {
+						def, _ := json.Marshal(a.Default)
+						arg += " = " + string(def)
+					}
+					args = append(args, arg)
+				}
+				sb.WriteString("(" + strings.Join(args, ", ") + ")")
+			}
+			sb.WriteString(": " + f.Type + "\n")
+		}
+		sb.WriteString("}\n")
+	}
+	return sb.String()
+}
+
+func writeDescription(sb *strings.Builder, desc, indent string) {
+	if len(desc) > 0 {
+		fmt.Fprintf(sb, "%s%q\n", indent, desc)
+	}
+}
+
+// Request is a GraphQL request (as sent in a POST body)
+type Request struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName,omitempty"`
+	Variables     map[string]any `json:"variables,omitempty"`
+}
+
+// Response is a GraphQL response
+type Response struct {
+	Data   any      `json:"data"`
+	Errors []*Error `json:"errors,omitempty"`
+}
+
+// Error is a GraphQL error
+type Error struct {
+	Message string `json:"message"`
+	Path    []any  `json:"path,omitempty"`
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Do executes a request against the schema
+func (s *Schema) Do(ctx context.Context, req Request) *Response {
+	ops, err := parse(req.Query)
+	if err != nil {
+		return &Response{Errors: []*Error{{Message: err.Error()}}}
+	}
+
+	var op *operation
+	if len(req.OperationName) > 0 {
+		for _, o := range ops {
+			if o.name == req.OperationName {
+				op = o
+			}
+		}
+		if op == nil {
+			return &Response{Errors: []*Error{{Message: fmt.Sprintf("unknown operation %q", req.OperationName)}}}
+		}
+	} else if len(ops) == 1 {
+		op = ops[0]
+	} else {
+		return &Response{Errors: []*Error{{Message: "an operationName is required for documents with multiple operations"}}}
+	}
+	if op.kind != "query" {
+		return &Response{Errors: []*Error{{Message: fmt.Sprintf("%s operations are not supported", op.kind)}}}
+	}
+
+	vars, err := coerceVariables(op.vars, req.Variables)
+	if err != nil {
+		return &Response{Errors: []*Error{{Message: err.Error()}}}
+	}
+
+	e := &executor{schema: s, ctx: ctx, vars: vars}
+	data := e.selectionSet(s.Query, nil, op.sel, nil)
+	return &Response{Data: data, Errors: e.errs}
+}
+
+type executor struct {
+	schema *Schema
+	ctx    context.Context
+	vars   map[string]any
+	errs   []*Error
+}
+
+func (e *executor) errorf(path []any, format string, a ...any) {
+	e.errs = append(e.errs, &Error{Message: fmt.Sprintf(format, a...), Path: append([]any(nil), path...)})
+}
+
+func (e *executor) selectionSet(obj *Object, src any, sels []*selection, path []any) *orderedMap {
+	out := &orderedMap{}
+	for _, sel := range sels {
+		key := sel.name
+		if len(sel.alias) > 0 {
+			key = sel.alias
+		}
+		fpath := append(path[:len(path):len(path)], key)
+
+		include, err := e.include(sel.directives)
+		if err != nil {
+			e.errorf(fpath, "%v", err)
+			continue
+		}
+		if !include {
+			continue
+		}
+
+		if sel.name == "__typename" {
+			out.set(key, obj.Name)
+			continue
+		}
+		field := obj.field(sel.name)
+		if field == nil {
+			e.errorf(fpath, "cannot query field %q on type %q", sel.name, obj.Name)
+			continue
+		}
+		args, err := e.coerceArgs(field, sel.args)
+		if err != nil {
+			e.errorf(fpath, "%v", err)
+			out.set(key, nil)
+			continue
+		}
+
+		var val any
+		if field.Resolve != nil {
+			val, err = field.Resolve(Params{Context: e.ctx, Source: src, Args: args})
+		} else {
+			val, err = defaultResolve(src, field.Name)
+		}
+		if err != nil {
+			e.errorf(fpath, "%v", err)
+			out.set(key, nil)
+			continue
+		}
+		out.set(key, e.complete(field.Type, val, sel, fpath))
+	}
+	return out
+}
+
+// complete shapes a resolved value according to its type and the selection's sub-selections
+func (e *executor) complete(typ string, val any, sel *selection, path []any) any {
+	if isNil(val) {
+		return nil
+	}
+	typ = strings.TrimSuffix(typ, "!")
+	if strings.HasPrefix(typ, "[") {
+		rv := reflect.ValueOf(val)
+		if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+			e.errorf(path, "expected a list for %s", typ)
+			return nil
+		}
+		list := make([]any, rv.Len())
+		for i := range list {
+			list[i] = e.complete(typ[1:len(typ)-1], rv.Index(i).Interface(), sel, append(path[:len(path):len(path)], i))
+		}
+		return list
+	}
+	if obj, ok := e.schema.types[typ]; ok {
+		if len(sel.sel) == 0 {
+			e.errorf(path, "field %q of type %q must have a selection of subfields", sel.name, typ)
+			return nil
+		}
+		return e.selectionSet(obj, val, sel.sel, path)
+	}
+	if len(sel.sel) > 0 {
+		e.errorf(path, "field %q of scalar type %q must not have a selection", sel.name, typ)
+		return nil
+	}
+	return val
+}
+
+func (e *executor) include(dirs []*directive) (bool, error) {
+	for _, d := range dirs {
+		if d.name != "skip" && d.name != "include" {
+			return false, fmt.Errorf("unknown directive @%s", d.name)
+		}
+		if len(d.args) != 1 || d.args[0].name != "if" {
+			return false, fmt.Errorf("directive @%s requires an 'if' argument", d.name)
+		}
+		cond, ok := e.value(d.args[0].val).(bool)
+		if !ok {
+			return false, fmt.Errorf("argument 'if' of directive @%s must be a Boolean", d.name)
+		}
+		if (d.name == "skip") == cond {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// value resolves the variables in a value literal
+func (e *executor) value(v any) any {
+	switch v := v.(type) {
+	case variable:
+		return e.vars[string(v)]
+	case []any:
+		list := make([]any, len(v))
+		for i, item := range v {
+			list[i] = e.value(item)
+		}
+		return list
+	case map[string]any:
+		obj := make(map[string]any, len(v))
+		for k, item := range v {
+			obj[k] = e.value(item)
+		}
+		return obj
+	}
+	return v
+}
+
+func (e *executor) coerceArgs(field *Field, args []*argument) (map[string]any, error) {
+	out := make(map[string]any)
+	for _, a := range args {
+		var def *Arg
+		for _, fa := range field.Args {
+			if fa.Name == a.name {
+				def = fa
+			}
+		}
+		if def == nil {
+			return nil, fmt.Errorf("unknown argument %q on field %q", a.name, field.Name)
+		}
+		if v, isVar := a.val.(variable); isVar {
+			if _, set := e.vars[string(v)]; !set {
+				continue // unset variables leave the argument unset
+			}
+		}
+		val, err := coerce(def.Type, e.value(a.val))
+		if err != nil {
+			return nil, fmt.Errorf("argument %q: %v", a.name, err)
+		}
+		out[a.name] = val
+	}
+	for _, fa := range field.Args {
+		if _, set := out[fa.Name]; set {
+			continue
+		}
+		if fa.Default != nil {
+			out[fa.Name] = fa.Default
+		} else if strings.HasSuffix(fa.Type, "!") {
+			return nil, fmt.Errorf("argument %q of type %q is required", fa.Name, fa.Type)
+		}
+	}
+	return out, nil
+}
+
+func coerceVariables(defs []*varDef, vals map[string]any) (map[string]any, error) {
+	vars := make(map[string]any)
+	for _, def := range defs {
+		if !isScalar(namedType(def.typ)) {
+			return nil, fmt.Errorf("variable $%s: type %s is not an input type", def.name, def.typ)
+		}
+		val, ok := vals[def.name]
+		if !ok {
+			if def.hasDef {
+				val, ok = def.def, true
+			} else if strings.HasSuffix(def.typ, "!") {
+				return nil, fmt.Errorf("variable $%s of type %s is required", def.name, def.typ)
+			}
+		}
+		if !ok {
+			continue
+		}
+		cv, err := coerce(def.typ, val)
+		if err != nil {
+			return nil, fmt.Errorf("variable $%s: %v", def.name, err)
+		}
+		vars[def.name] = cv
+	}
+	return vars, nil
+}
+
+// coerce converts an input value (a literal or a JSON variable) to the Go value of typ
+func coerce(typ string, val any) (any, error) {
+	nonNull := strings.HasSuffix(typ, "!")
+	typ = strings.TrimSuffix(typ, "!")
+	if val == nil {
+		if nonNull {
+			return nil, fmt.Errorf("expected a non-null %s", typ)
+		}
+		return nil, nil
+	}
+	if strings.HasPrefix(typ, "[") {
+		inner := typ[1 : len(typ)-1]
+		items, ok := val.([]any)
+		if !ok {
+			items = []any{val} // a single value is coerced to a list of one
+		}
+		list := make([]any, len(items))
+		for i, item := range items {
+			v, err := coerce(inner, item)
+			if err != nil {
+				return nil, err
+			}
+			list[i] = v
+		}
+		return list, nil
+	}
+	switch typ {
+	case "String", "ID":
+		switch v := val.(type) {
+		case string:
+			return v, nil
+		case int:
+			if typ == "ID" {
+				return fmt.Sprint(v), nil
+			}
+		}
+	case "Int":
+		switch v := val.(type) {
+		case int:
+			return v, nil
+		case float64: // JSON numbers
+			if v == float64(int(v)) {
+				return int(v), nil
+			}
+		}
+	case "Float":
+		switch v := val.(type) {
+		case int:
+			return float64(v), nil
+		case float64:
+			return v, nil
+		}
+	case "Boolean":
+		if v, ok := val.(bool); ok {
+			return v, nil
+		}
+	}
+	return nil, fmt.Errorf("expected a %s, got %v", typ, val)
+}
+
+// defaultResolve returns the field of a struct (by json name or case-insensitive field name) or map
+func defaultResolve(src any, name string) (any, error) {
+	rv := reflect.ValueOf(src)
+	for rv.Kind() == reflect.Pointer || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return nil, nil
+		}
+		rv = rv.Elem()
+	}
+	switch rv.Kind() {
+	case reflect.Map:
+		if rv.Type().Key().Kind() == reflect.String {
+			if v := rv.MapIndex(reflect.ValueOf(name).Convert(rv.Type().Key())); v.IsValid() {
+				return v.Interface(), nil
+			}
+			return nil, nil
+		}
+	case reflect.Struct:
+		if v, ok := structField(rv, name); ok {
+			return v.Interface(), nil
+		}
+	}
+	return nil, fmt.Errorf("no resolver for field %q", name)
+}
+
+func structField(rv reflect.Value, name string) (reflect.Value, bool) {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		f := rt.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		if f.Anonymous && f.Type.Kind() == reflect.Struct {
+			if v, ok := structField(rv.Field(i), name); ok {
+				return v, true
+			}
+			continue
+		}
+		tag, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if tag == name || (len(tag) == 0 && strings.EqualFold(f.Name, name)) {
+			return rv.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+func namedType(typ string) string {
+	return strings.Trim(typ, "[]!")
+}
+
+func isScalar(name string) bool {
+	for _, s := range Scalars {
+		if s == name {
+			return true
+		}
+	}
+	return false
+}
+
+func isNil(v any) bool {
+	if v == nil {
+		return true
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Pointer, reflect.Map, reflect.Slice, reflect.Interface:
+		return rv.IsNil()
+	}
+	return false
+}
+
+// orderedMap is a JSON object that keeps the order of the selection set
+type orderedMap struct {
+	keys []string
+	vals map[string]any
+}
+
+func (m *orderedMap) set(key string, val any) {
+	if m.vals == nil {
+		m.vals = make(map[string]any)
+	}
+	if _, ok := m.vals[key]; !ok {
+		m.keys = append(m.keys, key)
+	}
+	m.vals[key] = val
+}
+
+// Get returns the value of key (for tests and in-process callers)
+func (m *orderedMap) Get(key string) any {
+	return m.vals[key]
+}
+
+func (m *orderedMap) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, k := range m.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(k)
+		if err != nil {
+			return nil, err
+		}
+		val, err := json.Marshal(m.vals[k])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(val)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,175 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+type testDevice struct {
+	Identifier string   `json:"identifier"`
+	Name       string   `json:"name"`
+	Builds     []string `json:"builds"`
+}
+
+var testDevices = []testDevice{
+	{Identifier: "iPhone15,2", Name: "iPhone 14 Pro", Builds: []string{"21A329", "21B74"}},
+	{Identifier: "iPhone16,1", Name: "iPhone 15 Pro", Builds: []string{"21B74"}},
+}
+
+func testSchema(t *testing.T) *Schema {
+	t.Helper()
+	device := &Object{
+		Name: "Device",
+		Fields: []*Field{
+			{Name: "identifier", Type: "String!"},
+			{Name: "name", Type: "String"},
+			{Name: "builds", Type: "[String!]!", Args: []*Arg{{Name: "limit", Type: "Int", Default: 10}},
+				Resolve: func(p Params) (any, error) {
+					builds := p.Source.(testDevice).Builds
+					if n := p.Int("limit"); n < len(builds) {
+						builds = builds[:n]
+					}
+					return builds, nil
+				}},
+			{Name: "broken", Type: "String", Resolve: func(p Params) (any, error) {
+				return nil, fmt.Errorf("broken")
+			}},
+		},
+	}
+	query := &Object{
+		Name: "Query",
+		Fields: []*Field{
+			{Name: "device", Type: "Device", Args: []*Arg{{Name: "identifier", Type: "String!"}},
+				Resolve: func(p Params) (any, error) {
+					for _, d := range testDevices {
+						if d.Identifier == p.String("identifier") {
+							return d, nil
+						}
+					}
+					return nil, nil
+				}},
+			{Name: "devices", Type: "[Device!]!", Resolve: func(p Params) (any, error) {
+				return testDevices, nil
+			}},
+		},
+	}
+	s, err := NewSchema(query, device)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestDo(t *testing.T) {
+	s := testSchema(t)
+	tests := []struct {
+		name string
+		req  Request
+		want string
+		errs []string
+	}{
+		{
+			name: "selection",
+			req:  Request{Query: `{ devices { identifier name } }`},
+			want: `{"devices":[{"identifier":"iPhone15,2","name":"iPhone 14 Pro"},{"identifier":"iPhone16,1","name":"iPhone 15 Pro"}]}`,
+		},
+		{
+			name: "variables, aliases and arguments",
+			req: Request{
+				Query:     `query Q($id: String!, $n: Int) { d: device(identifier: $id) { __typename builds(limit: $n) } }`,
+				Variables: map[string]any{"id": "iPhone15,2", "n": float64(1)},
+			},
+			want: `{"d":{"__typename":"Device","builds":["21A329"]}}`,
+		},
+		{
+			name: "default argument",
+			req:  Request{Query: `{ device(identifier: "iPhone15,2") { builds } }`},
+			want: `{"device":{"builds":["21A329","21B74"]}}`,
+		},
+		{
+			name: "directives",
+			req: Request{
+				Query:     `query($skip: Boolean!) { device(identifier: "iPhone16,1") { identifier name @skip(if: $skip) builds @include(if: false) } }`,
+				Variables: map[string]any{"skip": true},
+			},
+			want: `{"device":{"identifier":"iPhone16,1"}}`,
+		},
+		{
+			name: "null result",
+			req:  Request{Query: `{ device(identifier: "iPhone1,1") { name } }`},
+			want: `{"device":null}`,
+		},
+		{
+			name: "field error",
+			req:  Request{Query: `{ device(identifier: "iPhone16,1") { name broken } }`},
+			want: `{"device":{"name":"iPhone 15 Pro","broken":null}}`,
+			errs: []string{"broken"},
+		},
+		{
+			name: "missing argument",
+			req:  Request{Query: `{ device { name } }`},
+			want: `{"device":null}`,
+			errs: []string{`argument "identifier" of type "String!" is required`},
+		},
+		{
+			name: "unknown field",
+			req:  Request{Query: `{ devices { serial } }`},
+			want: `{"devices":[{},{}]}`,
+			errs: []string{`cannot query field "serial" on type "Device"`, `cannot query field "serial" on type "Device"`},
+		},
+		{
+			name: "mutation",
+			req:  Request{Query: `mutation { devices { name } }`},
+			want: `null`,
+			errs: []string{"mutation operations are not supported"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := s.Do(context.Background(), tt.req)
+			data, err := json.Marshal(resp.Data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != tt.want {
+				t.Errorf("Do() data = %s, want %s", data, tt.want)
+			}
+			var errs []string
+			for _, e := range resp.Errors {
+				errs = append(errs, e.Message)
+			}
+			if strings.Join(errs, "\n") != strings.Join(tt.errs, "\n") {
+				t.Errorf("Do() errors = %q, want %q", errs, tt.errs)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, query := range []string{
+		`{ devices { name }`,
+		`{ devices(limit: ) { name } }`,
+		`query { ...F } fragment F on Query { devices { name } }`,
+		`"unterminated`,
+	} {
+		if _, err := parse(query); err == nil {
+			t.Errorf("parse(%q) expected an error", query)
+		}
+	}
+}
+
+func TestSDL(t *testing.T) {
+	sdl := testSchema(t).SDL()
+	for _, want := range []string{
+		"type Query {\n",
+		"  device(identifier: String!): Device\n",
+		"  builds(limit: Int = 10): [String!]!\n",
+	} {
+		if !strings.Contains(sdl, want) {
+			t.Errorf("SDL() missing %q:\n%s", want, sdl)
+		}
+	}
+}
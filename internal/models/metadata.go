@@ -0,0 +1,34 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Device is the model for a device synced from the ipsw.me API.
+type Device struct {
+	Identifier  string    `json:"identifier" gorm:"primaryKey"`
+	Name        string    `json:"name"`
+	BoardConfig string    `json:"boardconfig"`
+	Platform    string    `json:"platform"`
+	CpID        int       `json:"cpid"`
+	BdID        int       `json:"bdid"`
+	SyncedAt    time.Time `json:"synced_at"`
+}
+
+// Firmware is the model for an IPSW or OTA synced from the ipsw.me API.
+type Firmware struct {
+	gorm.Model
+	Identifier  string    `json:"identifier" gorm:"index"`
+	Version     string    `json:"version" gorm:"index"`
+	BuildID     string    `json:"buildid" gorm:"index"`
+	SHA1        string    `json:"sha1sum"`
+	MD5         string    `json:"md5sum"`
+	FileSize    int       `json:"filesize"`
+	URL         string    `json:"url" gorm:"uniqueIndex"`
+	ReleaseDate time.Time `json:"releasedate"`
+	UploadDate  time.Time `json:"uploaddate"`
+	Signed      bool      `json:"signed"`
+	OTA         bool      `json:"ota"`
+}
@@ -0,0 +1,24 @@
+package models
+
+import "gorm.io/gorm"
+
+// Mirror statuses of a build
+const (
+	MirrorBaseline   = "baseline"   // already released when the mirror started (not downloaded)
+	MirrorDownloaded = "downloaded" // downloaded to the mirror
+	MirrorFailed     = "failed"     // the download failed (retried on the next poll)
+)
+
+// MirroredBuild is the model for a build seen by the firmware mirror daemon.
+type MirroredBuild struct {
+	gorm.Model
+	Device  string `json:"device" gorm:"uniqueIndex:idx_mirror_device_build"`
+	Version string `json:"version"`
+	Build   string `json:"build" gorm:"uniqueIndex:idx_mirror_device_build"`
+	URL     string `json:"url"`
+	Source  string `json:"source"`
+	Status  string `json:"status"`
+	Path    string `json:"path,omitempty"`
+	Error   string `json:"error,omitempty"`
+	Tries   int    `json:"tries"`
+}
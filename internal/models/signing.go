@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// SigningWindow is the model for a period Apple's TSS server signed a build for a device.
+type SigningWindow struct {
+	gorm.Model
+	Device      string     `json:"device" gorm:"index:idx_signing_device_build"`
+	Version     string     `json:"version"`
+	Build       string     `json:"build" gorm:"index:idx_signing_device_build"`
+	Start       time.Time  `json:"start"`                  // first TSS check that found the build signed
+	Stop        *time.Time `json:"stop,omitempty"`         // first TSS check that found the build unsigned
+	LastChecked time.Time  `json:"last_checked,omitempty"` // last TSS check (bounds the error of Start/Stop)
+}
+
+// Open returns true if the build is still being signed.
+func (w SigningWindow) Open() bool {
+	return w.Stop == nil
+}
@@ -0,0 +1,39 @@
+package models
+
+import (
+	"encoding/json"
+
+	"gorm.io/gorm"
+)
+
+// StoredFirmware is the model for a firmware file in the local store.
+type StoredFirmware struct {
+	gorm.Model
+	Path    string     `json:"path" gorm:"uniqueIndex"`
+	Device  string     `json:"device,omitempty"`
+	Version string     `json:"version,omitempty"`
+	Build   string     `json:"build,omitempty"`
+	Notes   string     `json:"notes,omitempty"`
+	Tags    []StoreTag `json:"tags,omitempty" gorm:"constraint:OnDelete:CASCADE"`
+}
+
+// StoreTag is the model for a tag attached to a stored firmware.
+type StoreTag struct {
+	ID               uint   `gorm:"primarykey"`
+	StoredFirmwareID uint   `gorm:"uniqueIndex:idx_store_tag"`
+	Name             string `gorm:"uniqueIndex:idx_store_tag"`
+}
+
+// MarshalJSON marshals the tag as its name.
+func (t StoreTag) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.Name)
+}
+
+// TagNames returns the names of the firmware's tags.
+func (f StoredFirmware) TagNames() []string {
+	var names []string
+	for _, t := range f.Tags {
+		names = append(names, t.Name)
+	}
+	return names
+}
@@ -0,0 +1,102 @@
+package utils
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dustin/go-humanize"
+)
+
+// Locale controls how sizes and dates are formatted for humans (set by the global --locale flag)
+//
+// It is a language tag like `de-DE` or `ja` (only the language is used when the region is not
+// known); the empty locale keeps the default formats (i.e. 1.2 GB and 02Jan2006 15:04:05).
+var Locale string
+
+// RawOutput prints sizes as byte counts and dates as RFC3339 UTC timestamps (set by the global --raw flag)
+//
+// This keeps the output stable for log parsers regardless of the locale.
+var RawOutput bool
+
+type localeFormat struct {
+	decimal  string // decimal separator
+	datetime string // time.Format layout of dates with a time
+	date     string // time.Format layout of dates
+}
+
+var defaultFormat = localeFormat{decimal: ".", datetime: "02Jan2006 15:04:05", date: "02Jan2006"}
+
+var localeFormats = map[string]localeFormat{
+	"iso":   {decimal: ".", datetime: "2006-01-02 15:04:05", date: "2006-01-02"},
+	"en-us": {decimal: ".", datetime: "01/02/2006 3:04:05 PM", date: "01/02/2006"},
+	"en":    {decimal: ".", datetime: "02/01/2006 15:04:05", date: "02/01/2006"},
+	"de":    {decimal: ",", datetime: "02.01.2006 15:04:05", date: "02.01.2006"},
+	"fr":    {decimal: ",", datetime: "02/01/2006 15:04:05", date: "02/01/2006"},
+	"es":    {decimal: ",", datetime: "02/01/2006 15:04:05", date: "02/01/2006"},
+	"it":    {decimal: ",", datetime: "02/01/2006 15:04:05", date: "02/01/2006"},
+	"pt":    {decimal: ",", datetime: "02/01/2006 15:04:05", date: "02/01/2006"},
+	"nl":    {decimal: ",", datetime: "02-01-2006 15:04:05", date: "02-01-2006"},
+	"ru":    {decimal: ",", datetime: "02.01.2006 15:04:05", date: "02.01.2006"},
+	"pl":    {decimal: ",", datetime: "02.01.2006 15:04:05", date: "02.01.2006"},
+	"sv":    {decimal: ",", datetime: "2006-01-02 15:04:05", date: "2006-01-02"},
+	"ja":    {decimal: ".", datetime: "2006/01/02 15:04:05", date: "2006/01/02"},
+	"zh":    {decimal: ".", datetime: "2006/01/02 15:04:05", date: "2006/01/02"},
+	"ko":    {decimal: ".", datetime: "2006. 01. 02. 15:04:05", date: "2006. 01. 02."},
+}
+
+// formatOf returns the formats of a locale (by language tag then language) and whether it is known
+func formatOf(locale string) (localeFormat, bool) {
+	if len(locale) == 0 {
+		return defaultFormat, true
+	}
+	tag := strings.ToLower(strings.ReplaceAll(locale, "_", "-"))
+	tag, _, _ = strings.Cut(tag, ".") // i.e. de_DE.UTF-8
+	if f, ok := localeFormats[tag]; ok {
+		return f, true
+	}
+	lang, _, _ := strings.Cut(tag, "-")
+	if f, ok := localeFormats[lang]; ok {
+		return f, true
+	}
+	return defaultFormat, false
+}
+
+func currentFormat() localeFormat {
+	f, _ := formatOf(Locale)
+	return f
+}
+
+// KnownLocale returns true if the locale has its own size/date formats
+func KnownLocale(locale string) bool {
+	_, ok := formatOf(locale)
+	return ok
+}
+
+// FormatBytes formats a size for humans (i.e. 1.2 GB or 1,2 GB) or as a byte count in raw mode
+func FormatBytes(n uint64) string {
+	if RawOutput {
+		return strconv.FormatUint(n, 10)
+	}
+	s := humanize.Bytes(n)
+	if f := currentFormat(); f.decimal != "." {
+		s = strings.Replace(s, ".", f.decimal, 1)
+	}
+	return s
+}
+
+// FormatTime formats a date and time for humans or as an RFC3339 UTC timestamp in raw mode
+func FormatTime(t time.Time) string {
+	if RawOutput {
+		return t.UTC().Format(time.RFC3339)
+	}
+	return t.Format(currentFormat().datetime)
+}
+
+// FormatDate formats a date for humans or as a YYYY-MM-DD date in raw mode
+func FormatDate(t time.Time) string {
+	if RawOutput {
+		return t.UTC().Format(time.DateOnly)
+	}
+	return t.Format(currentFormat().date)
+}
@@ -17,7 +17,6 @@ import (
 
 	"github.com/apex/log"
 	"github.com/blacktop/go-plist"
-	"github.com/blacktop/ipsw/internal/utils/lsof"
 	semver "github.com/hashicorp/go-version"
 )
 
@@ -121,7 +120,7 @@ func CodeSign(filePath, signature string) error {
 		return nil
 	}
 
-	return fmt.Errorf("only supported on macOS")
+	return errMacOSOnly
 }
 
 // CodeSignWithEntitlements codesigns a given binary with given entitlements
@@ -136,7 +135,7 @@ func CodeSignWithEntitlements(filePath, entitlementsPath, signature string) erro
 		return nil
 	}
 
-	return fmt.Errorf("only supported on macOS")
+	return errMacOSOnly
 }
 
 // CodeSignAdHoc codesigns a given binary with ad-hoc signature
@@ -153,7 +152,7 @@ func CodesignVerify(path string) (string, error) {
 		}
 		return string(out), nil
 	}
-	return "", fmt.Errorf("only supported on macOS")
+	return "", errMacOSOnly
 }
 
 func CodesignShow(path string) (string, error) {
@@ -165,7 +164,7 @@ func CodesignShow(path string) (string, error) {
 		}
 		return string(out), nil
 	}
-	return "", fmt.Errorf("only supported on macOS")
+	return "", errMacOSOnly
 }
 
 // CreateSparseDiskImage creates a sparse disk image and returns it's path
@@ -187,7 +186,7 @@ func CreateSparseDiskImage(volumeName, diskPath string) (string, error) {
 		return paths[0], nil
 	}
 
-	return "", fmt.Errorf("only supported on macOS")
+	return "", errMacOSOnly
 }
 
 // CreateCompressedDMG creates a compressed r/o disk image containing Install macOS.app
@@ -204,7 +203,7 @@ func CreateCompressedDMG(appPath, diskimagePath string) error {
 		return nil
 	}
 
-	return fmt.Errorf("only supported on macOS")
+	return errMacOSOnly
 }
 
 // CreateInstaller creates an macOS installer
@@ -237,7 +236,7 @@ func CreateInstaller(distPath, targetVol string) error {
 		return nil
 	}
 
-	return fmt.Errorf("only supported on macOS")
+	return errMacOSOnly
 }
 
 type IORegistryEntryChild struct {
@@ -326,7 +325,7 @@ func GetDeviceID() (*DeviceID, error) {
 
 		return &dID, nil
 	}
-	return nil, fmt.Errorf("only supported on macOS")
+	return nil, errMacOSOnly
 }
 
 type BuildInfo struct {
@@ -366,7 +365,7 @@ func GetBuildInfo() (*BuildInfo, error) {
 
 		return &binfo, nil
 	}
-	return nil, fmt.Errorf("only supported on macOS")
+	return nil, errMacOSOnly
 }
 
 func GetXCodePath() (string, error) {
@@ -378,7 +377,7 @@ func GetXCodePath() (string, error) {
 		}
 		return strings.TrimSpace(string(out)), nil
 	}
-	return "", fmt.Errorf("only supported on macOS")
+	return "", errMacOSOnly
 }
 
 func GetKernelPath() (string, error) {
@@ -403,7 +402,7 @@ func GetKernelPath() (string, error) {
 		}
 		return fmt.Sprintf("/System/Library/Kernels/kernel.release.%s", strings.ToLower(tparts[2])), nil
 	}
-	return "", fmt.Errorf("only supported on macOS")
+	return "", errMacOSOnly
 }
 
 func GetKernelCollectionPath() (string, error) {
@@ -419,58 +418,11 @@ func GetKernelCollectionPath() (string, error) {
 		}
 		return filepath.Join("/System/Volumes/Preboot", strings.TrimSpace(string(out)), "System/Library/Caches/com.apple.kernelcaches/kernelcache"), nil
 	}
-	return "", fmt.Errorf("only supported on macOS")
+	return "", errMacOSOnly
 }
 
 var ErrMountResourceBusy = errors.New("hdiutil: mount failed - Resource busy")
 
-// Mount mounts a DMG with hdiutil
-func Mount(image, mountPoint string) error {
-	if runtime.GOOS == "darwin" {
-		out, err := exec.Command("/usr/bin/hdiutil", "attach", "-noverify", "-mountpoint", mountPoint, image).CombinedOutput()
-		if err != nil {
-			if strings.Contains(string(out), "hdiutil: mount failed - Resource busy") {
-				return ErrMountResourceBusy
-			}
-			return fmt.Errorf("%v: %s", err, out)
-		}
-	} else {
-		out, err := exec.Command("apfs-fuse", image, mountPoint).CombinedOutput()
-		if err != nil {
-			if _, lperr := exec.LookPath("apfs-fuse"); err != nil {
-				return fmt.Errorf("utils.Mount: 'apfs-fuse' not found (required on non-darwin systems): %v: %v: %s", lperr, err, out)
-			}
-			return fmt.Errorf("%v: %s", err, out)
-		}
-	}
-
-	return nil
-}
-
-func IsAlreadyMounted(image, mountPoint string) (string, bool, error) {
-	if runtime.GOOS == "darwin" {
-		info, err := MountInfo()
-		if err != nil {
-			return "", false, err
-		}
-		for _, i := range info.Images {
-			if strings.Contains(i.ImagePath, image) {
-				for _, entry := range i.SystemEntities {
-					if entry.MountPoint != "" {
-						return entry.MountPoint, true, nil
-					}
-				}
-				return "", true, nil
-			}
-		}
-	} else if runtime.GOOS == "linux" {
-		if _, err := os.Stat(filepath.Join(mountPoint, "root")); !os.IsNotExist(err) {
-			return mountPoint, true, nil
-		}
-	}
-	return "", false, nil
-}
-
 func MountDMG(image string) (string, bool, error) {
 	mountPoint := fmt.Sprintf("/tmp/%s.mount", filepath.Base(image))
 
@@ -489,48 +441,12 @@ func MountDMG(image string) (string, bool, error) {
 	}
 
 	if err := Mount(image, mountPoint); err != nil {
-		return "", false, fmt.Errorf("failed to mount %s: %v", image, err)
+		return "", false, fmt.Errorf("failed to mount %s: %w", image, err)
 	}
 
 	return mountPoint, false, nil
 }
 
-// Unmount unmounts a DMG with hdiutil
-func Unmount(mountPoint string, force bool) error {
-	if runtime.GOOS == "darwin" {
-		var cmd *exec.Cmd
-
-		if force {
-			cmd = exec.Command("hdiutil", "detach", mountPoint, "-force")
-		} else {
-			cmd = exec.Command("hdiutil", "detach", mountPoint)
-		}
-
-		if err := cmd.Run(); err != nil {
-			var edetail string
-			if strings.Contains(err.Error(), "exit status 16") {
-				edetail = " (Resource busy)"
-			}
-			procs, lerr := lsof.MountPoint(mountPoint)
-			if lerr == nil {
-				edetail += "\n  Processes using mount point:"
-				for _, proc := range procs {
-					edetail += fmt.Sprintf("\n    %s", proc)
-				}
-				edetail += "\n"
-			}
-			return fmt.Errorf("failed to unmount %s: %v%s", mountPoint, err, edetail)
-		}
-	} else if runtime.GOOS == "linux" {
-		cmd := exec.Command("umount", mountPoint)
-		if err := cmd.Run(); err != nil {
-			return fmt.Errorf("failed to unmount %s: %v", mountPoint, err)
-		}
-	}
-
-	return nil
-}
-
 type systemEntry struct {
 	ContentHint string `plist:"content-hint,omitempty" xml:"content-hint,omitempty"`
 	DevEntry    string `plist:"dev-entry,omitempty" xml:"dev-entry,omitempty"`
@@ -583,7 +499,7 @@ func MountInfo() (*HdiUtilInfo, error) {
 		}
 		return &info, nil
 	}
-	return nil, fmt.Errorf("only supported on macOS")
+	return nil, errMacOSOnly
 }
 
 func ExtractFromDMG(ipswPath, dmgPath, destPath string, pattern *regexp.Regexp) ([]string, error) {
@@ -659,7 +575,7 @@ func PkgUtilExpand(src, dst string) (string, error) {
 		}
 		return outDir, nil
 	}
-	return "", fmt.Errorf("only supported on macOS")
+	return "", errMacOSOnly
 }
 
 func InstallXCodeSimRuntime(path string) error {
@@ -671,7 +587,7 @@ func InstallXCodeSimRuntime(path string) error {
 		}
 		return nil
 	}
-	return fmt.Errorf("only supported on macOS")
+	return errMacOSOnly
 }
 
 func InstallKDK(path string) error {
@@ -693,7 +609,7 @@ func InstallKDK(path string) error {
 		}
 		return nil
 	}
-	return fmt.Errorf("only supported on macOS")
+	return errMacOSOnly
 }
 
 type KMUConfig struct {
@@ -757,5 +673,5 @@ func KmutilCreate(conf *KMUConfig) (err error) {
 		return nil
 	}
 
-	return fmt.Errorf("only supported on macOS")
+	return errMacOSOnly
 }
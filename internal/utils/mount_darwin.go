@@ -0,0 +1,69 @@
+package utils
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/blacktop/ipsw/internal/utils/lsof"
+)
+
+// Mount mounts a DMG with hdiutil
+func Mount(image, mountPoint string) error {
+	out, err := exec.Command("/usr/bin/hdiutil", "attach", "-noverify", "-mountpoint", mountPoint, image).CombinedOutput()
+	if err != nil {
+		if strings.Contains(string(out), "hdiutil: mount failed - Resource busy") {
+			return ErrMountResourceBusy
+		}
+		return fmt.Errorf("%v: %s", err, out)
+	}
+	return nil
+}
+
+// IsAlreadyMounted returns the mount point of image if hdiutil already has it attached
+func IsAlreadyMounted(image, mountPoint string) (string, bool, error) {
+	info, err := MountInfo()
+	if err != nil {
+		return "", false, err
+	}
+	for _, i := range info.Images {
+		if strings.Contains(i.ImagePath, image) {
+			for _, entry := range i.SystemEntities {
+				if entry.MountPoint != "" {
+					return entry.MountPoint, true, nil
+				}
+			}
+			return "", true, nil
+		}
+	}
+	return "", false, nil
+}
+
+// Unmount unmounts a DMG with hdiutil
+func Unmount(mountPoint string, force bool) error {
+	var cmd *exec.Cmd
+
+	if force {
+		cmd = exec.Command("hdiutil", "detach", mountPoint, "-force")
+	} else {
+		cmd = exec.Command("hdiutil", "detach", mountPoint)
+	}
+
+	if err := cmd.Run(); err != nil {
+		var edetail string
+		if strings.Contains(err.Error(), "exit status 16") {
+			edetail = " (Resource busy)"
+		}
+		procs, lerr := lsof.MountPoint(mountPoint)
+		if lerr == nil {
+			edetail += "\n  Processes using mount point:"
+			for _, proc := range procs {
+				edetail += fmt.Sprintf("\n    %s", proc)
+			}
+			edetail += "\n"
+		}
+		return fmt.Errorf("failed to unmount %s: %v%s", mountPoint, err, edetail)
+	}
+
+	return nil
+}
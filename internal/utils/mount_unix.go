@@ -0,0 +1,38 @@
+//go:build !darwin && !windows
+
+package utils
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+// Mount mounts an APFS DMG with apfs-fuse
+func Mount(image, mountPoint string) error {
+	out, err := exec.Command("apfs-fuse", image, mountPoint).CombinedOutput()
+	if err != nil {
+		if _, lperr := exec.LookPath("apfs-fuse"); lperr != nil {
+			return fmt.Errorf("utils.Mount: 'apfs-fuse' not found (required on non-darwin systems): %v: %v: %s", lperr, err, out)
+		}
+		return fmt.Errorf("%v: %s", err, out)
+	}
+	return nil
+}
+
+// IsAlreadyMounted returns true if an APFS volume is already mounted at mountPoint
+func IsAlreadyMounted(image, mountPoint string) (string, bool, error) {
+	if _, err := os.Stat(filepath.Join(mountPoint, "root")); !os.IsNotExist(err) {
+		return mountPoint, true, nil
+	}
+	return "", false, nil
+}
+
+// Unmount unmounts a FUSE mounted DMG
+func Unmount(mountPoint string, force bool) error {
+	if err := exec.Command("umount", mountPoint).Run(); err != nil {
+		return fmt.Errorf("failed to unmount %s: %v", mountPoint, err)
+	}
+	return nil
+}
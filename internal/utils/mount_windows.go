@@ -0,0 +1,18 @@
+package utils
+
+import "fmt"
+
+// Mount is not supported on Windows (there is no APFS driver to mount DMGs with)
+func Mount(image, mountPoint string) error {
+	return fmt.Errorf("failed to mount %s: %w", image, ErrUnsupported)
+}
+
+// IsAlreadyMounted always returns false on Windows
+func IsAlreadyMounted(image, mountPoint string) (string, bool, error) {
+	return "", false, nil
+}
+
+// Unmount is not supported on Windows
+func Unmount(mountPoint string, force bool) error {
+	return fmt.Errorf("failed to unmount %s: %w", mountPoint, ErrUnsupported)
+}
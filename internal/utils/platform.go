@@ -0,0 +1,13 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrUnsupported is returned (wrapped) by the functions that are not available on the running platform
+// (check with errors.Is instead of the error string)
+var ErrUnsupported = errors.ErrUnsupported
+
+// errMacOSOnly is returned by the functions that shell out to macOS tools (codesign, hdiutil, xcrun, etc.)
+var errMacOSOnly = fmt.Errorf("only supported on macOS: %w", ErrUnsupported)
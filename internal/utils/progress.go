@@ -0,0 +1,283 @@
+//go:build !wasm
+
+package utils
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/apex/log"
+	"github.com/vbauerster/mpb/v7"
+	"github.com/vbauerster/mpb/v7/decor"
+)
+
+// Heartbeat is the interval at which long-running operations print a single-line
+// status update (set by the global --heartbeat flag; 0 disables)
+//
+// This keeps CI systems that kill silent jobs happy, as progress bars are not
+// rendered when the output is not a terminal.
+var Heartbeat time.Duration
+
+// Plain disables progress bar rendering (set by the global --plain flag)
+//
+// Progress is instead printed as a log line each time a bar crosses a 10% milestone so
+// that the output is line-based and the same on every run (for screen readers and CI logs).
+var Plain bool
+
+// Progress is a mpb.Progress that also prints heartbeat status lines for its bars
+type Progress struct {
+	*mpb.Progress
+
+	name    string
+	counts  bool
+	started time.Time
+	mu      sync.Mutex
+	bars    []trackedBar
+	stop    chan struct{}
+	once    sync.Once
+}
+
+type trackedBar struct {
+	bar       *mpb.Bar
+	total     int64
+	milestone int64 // last 10% milestone printed in plain mode
+}
+
+// NewProgress creates a progress container (with the repo's default bar width and refresh rate)
+// for the named operation
+func NewProgress(name string, options ...mpb.ContainerOption) *Progress {
+	if Plain {
+		options = append(options, mpb.WithOutput(nil))
+	}
+	p := &Progress{
+		Progress: mpb.New(append([]mpb.ContainerOption{
+			mpb.WithWidth(60),
+			mpb.WithRefreshRate(180 * time.Millisecond),
+		}, options...)...),
+		name:    name,
+		started: time.Now(),
+		stop:    make(chan struct{}),
+	}
+	if Heartbeat > 0 {
+		go p.heartbeat()
+	}
+	if Plain {
+		go p.plain()
+	}
+	return p
+}
+
+// Counts makes heartbeat status lines report item counts instead of bytes
+func (p *Progress) Counts() *Progress {
+	p.counts = true
+	return p
+}
+
+// New adds a bar to the container and tracks it for heartbeat status lines
+func (p *Progress) New(total int64, builder mpb.BarFillerBuilder, options ...mpb.BarOption) *mpb.Bar {
+	return p.Add(total, builder.Build(), options...)
+}
+
+// Add adds a bar to the container and tracks it for heartbeat status lines
+func (p *Progress) Add(total int64, filler mpb.BarFiller, options ...mpb.BarOption) *mpb.Bar {
+	bar := p.Progress.Add(total, filler, options...)
+	p.mu.Lock()
+	p.bars = append(p.bars, trackedBar{bar: bar, total: total, milestone: -1})
+	p.mu.Unlock()
+	return bar
+}
+
+// Wait stops the heartbeat and waits for all bars to complete
+func (p *Progress) Wait() {
+	p.once.Do(func() { close(p.stop) })
+	p.Progress.Wait()
+	if Plain {
+		p.milestones()
+	}
+}
+
+func (p *Progress) plain() {
+	ticker := time.NewTicker(250 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.stop:
+			return
+		case <-ticker.C:
+			p.milestones()
+		}
+	}
+}
+
+// milestones prints a line for each 10% milestone the bars have crossed since the last call
+func (p *Progress) milestones() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for i := range p.bars {
+		b := &p.bars[i]
+		if b.total <= 0 {
+			continue
+		}
+		current := b.bar.Current() * 10 / b.total
+		if current > 10 {
+			current = 10
+		}
+		for b.milestone < current {
+			b.milestone++
+			name := p.name
+			if len(p.bars) > 1 {
+				name = fmt.Sprintf("%s [%d/%d]", p.name, i+1, len(p.bars))
+			}
+			if p.counts {
+				log.Infof("%s: %d%% of %d", name, b.milestone*10, b.total)
+			} else {
+				log.Infof("%s: %d%% of %s", name, b.milestone*10, FormatBytes(uint64(b.total)))
+			}
+		}
+	}
+}
+
+func (p *Progress) heartbeat() {
+	ticker := time.NewTicker(Heartbeat)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.stop:
+			return
+		case <-ticker.C:
+			p.beat()
+		}
+	}
+}
+
+func (p *Progress) beat() {
+	p.mu.Lock()
+	bars := append([]trackedBar(nil), p.bars...)
+	p.mu.Unlock()
+
+	var current, total int64
+	var active int
+	for _, b := range bars {
+		current += b.bar.Current()
+		total += b.total
+		if !b.bar.Completed() && !b.bar.Aborted() {
+			active++
+		}
+	}
+	if len(bars) > 0 && active == 0 {
+		return // all done (Wait will stop the heartbeat)
+	}
+	fields := log.Fields{"elapsed": time.Since(p.started).Round(time.Second)}
+	if total > 0 {
+		if p.counts {
+			fields["progress"] = fmt.Sprintf("%d/%d (%.1f%%)", current, total, float64(current)/float64(total)*100)
+		} else {
+			fields["progress"] = fmt.Sprintf("%s / %s (%.1f%%)", FormatBytes(uint64(current)), FormatBytes(uint64(total)), float64(current)/float64(total)*100)
+		}
+	}
+	log.WithFields(fields).Info("[heartbeat] " + p.name)
+}
+
+// StartHeartbeat prints heartbeat status lines for a long-running operation that has
+// no progress bar until the returned stop func is called
+func StartHeartbeat(name string) (stop func()) {
+	if Heartbeat <= 0 {
+		return func() {}
+	}
+	p := &Progress{name: name, started: time.Now(), stop: make(chan struct{})}
+	go p.heartbeat()
+	return func() { p.once.Do(func() { close(p.stop) }) }
+}
+
+// SearchZip searches for files in a zip.Reader
+func SearchZip(files []*zip.File, pattern *regexp.Regexp, folder string, flat, progress bool) ([]string, error) {
+	var fname string
+	var artifacts []string
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current working directory: %v", err)
+	}
+
+	found := false
+	for _, f := range files {
+		if pattern.MatchString(f.Name) {
+			if f.FileInfo().IsDir() {
+				continue
+			}
+			found = true
+			if flat {
+				fname = filepath.Join(folder, filepath.Base(f.Name))
+			} else {
+				fname = filepath.Join(folder, filepath.Clean(f.Name))
+			}
+
+			if err := os.MkdirAll(filepath.Dir(fname), 0750); err != nil {
+				return nil, fmt.Errorf("failed to create directory %s: %v", filepath.Dir(fname), err)
+			}
+
+			var r io.ReadCloser
+			if _, err := os.Stat(fname); os.IsNotExist(err) {
+				rc, err := f.Open()
+				if err != nil {
+					return nil, fmt.Errorf("error opening remote zipped file %s: %v", f.Name, err)
+				}
+				defer rc.Close()
+
+				var p *Progress
+				if progress {
+					// setup progress bar
+					var total = int64(f.UncompressedSize64)
+					p = NewProgress(filepath.Base(fname))
+					bar := p.New(total,
+						mpb.BarStyle().Lbound("[").Filler("=").Tip(">").Padding("-").Rbound("|"),
+						mpb.PrependDecorators(
+							decor.CountersKibiByte("\t% .2f / % .2f"),
+						),
+						mpb.AppendDecorators(
+							decor.OnComplete(decor.AverageETA(decor.ET_STYLE_GO), "✅ "),
+							decor.Name(" ] "),
+							decor.AverageSpeed(decor.UnitKiB, "% .2f"),
+						),
+					)
+					// create proxy reader
+					r = bar.ProxyReader(io.LimitReader(rc, total))
+					defer r.Close()
+				} else {
+					r = rc
+				}
+
+				Indent(log.Debug, 2)(fmt.Sprintf("Extracting %s", strings.TrimPrefix(fname, cwd)))
+				out, err := os.Create(fname)
+				if err != nil {
+					return nil, fmt.Errorf("error creating remote unzipped file destination %s: %v", fname, err)
+				}
+				defer out.Close()
+
+				io.Copy(out, r)
+
+				if progress {
+					// wait for our bar to complete and flush and close remote zip and temp file
+					p.Wait()
+				}
+
+				artifacts = append(artifacts, fname)
+			} else {
+				Indent(log.Warn, 2)(fmt.Sprintf("%s already exists", fname))
+			}
+		}
+	}
+
+	if !found {
+		return nil, fmt.Errorf("no files found matching %s", pattern.String())
+	}
+
+	return artifacts, nil
+}
@@ -0,0 +1,227 @@
+//go:build !wasm
+
+package utils
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/AlecAivazis/survey/v2/core"
+	"github.com/blacktop/ipsw/internal/exitcode"
+	"golang.org/x/term"
+)
+
+// NonInteractive disables all interactive prompts (set by the global --non-interactive flag)
+var NonInteractive bool
+
+// ErrNonInteractive is returned when a prompt would be shown in non-interactive mode
+var ErrNonInteractive = exitcode.Wrap(exitcode.Usage, errors.New("input required in --non-interactive mode"))
+
+// AskOne wraps survey.AskOne and errors instead of prompting when in non-interactive mode
+func AskOne(p survey.Prompt, response any, opts ...survey.AskOpt) error {
+	if NonInteractive {
+		return fmt.Errorf("%w: %s (run the command interactively to answer it)", ErrNonInteractive, promptMessage(p))
+	}
+	if Plain {
+		ans, err := plainAsk(p, nil)
+		if err != nil {
+			return err
+		}
+		return core.WriteAnswer(response, "", ans)
+	}
+	return survey.AskOne(p, response, opts...)
+}
+
+// Ask wraps survey.Ask and errors instead of prompting when in non-interactive mode
+func Ask(qs []*survey.Question, response any, opts ...survey.AskOpt) error {
+	if NonInteractive {
+		if len(qs) > 0 {
+			return fmt.Errorf("%w: %s (run the command interactively to answer it)", ErrNonInteractive, promptMessage(qs[0].Prompt))
+		}
+		return ErrNonInteractive
+	}
+	if Plain {
+		for _, q := range qs {
+			ans, err := plainAsk(q.Prompt, q.Validate)
+			if err != nil {
+				return err
+			}
+			if err := core.WriteAnswer(response, q.Name, ans); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return survey.Ask(qs, response, opts...)
+}
+
+func promptMessage(p survey.Prompt) string {
+	switch p := p.(type) {
+	case *survey.Select:
+		return fmt.Sprintf("%q", p.Message)
+	case *survey.MultiSelect:
+		return fmt.Sprintf("%q", p.Message)
+	case *survey.Confirm:
+		return fmt.Sprintf("%q", p.Message)
+	case *survey.Input:
+		return fmt.Sprintf("%q", p.Message)
+	case *survey.Password:
+		return fmt.Sprintf("%q", p.Message)
+	case *survey.Multiline:
+		return fmt.Sprintf("%q", p.Message)
+	case *survey.Editor:
+		return fmt.Sprintf("%q", p.Message)
+	default:
+		return "prompt"
+	}
+}
+
+var plainIn = bufio.NewReader(os.Stdin)
+
+// plainAsk asks a prompt using plain lines of text (no ANSI escapes or cursor movement) for --plain mode
+func plainAsk(p survey.Prompt, validate survey.Validator) (any, error) {
+	for {
+		ans, err := plainAnswer(p)
+		if err != nil {
+			return nil, err
+		}
+		if validate != nil {
+			if err := validate(ans); err != nil {
+				fmt.Fprintf(os.Stderr, "Invalid answer: %v\n", err)
+				continue
+			}
+		}
+		return ans, nil
+	}
+}
+
+func plainReadLine(msg string) (string, error) {
+	fmt.Fprint(os.Stderr, msg)
+	line, err := plainIn.ReadString('\n')
+	if err != nil && (err != io.EOF || len(line) == 0) {
+		return "", fmt.Errorf("failed to read answer: %v", err)
+	}
+	return strings.TrimSpace(line), nil
+}
+
+func plainOptions(options []string) {
+	for i, opt := range options {
+		fmt.Fprintf(os.Stderr, "  %d) %s\n", i+1, opt)
+	}
+}
+
+// plainChoices parses a comma separated list of option numbers (or option names)
+func plainChoices(line string, options []string) ([]core.OptionAnswer, error) {
+	var answers []core.OptionAnswer
+	for _, choice := range strings.Split(line, ",") {
+		choice = strings.TrimSpace(choice)
+		if len(choice) == 0 {
+			continue
+		}
+		idx, err := strconv.Atoi(choice)
+		if err != nil {
+			idx = 0
+			for i, opt := range options {
+				if strings.EqualFold(opt, choice) {
+					idx = i + 1
+					break
+				}
+			}
+		}
+		if idx < 1 || idx > len(options) {
+			return nil, fmt.Errorf("invalid choice %q", choice)
+		}
+		answers = append(answers, core.OptionAnswer{Value: options[idx-1], Index: idx - 1})
+	}
+	return answers, nil
+}
+
+func plainAnswer(p survey.Prompt) (any, error) {
+	switch p := p.(type) {
+	case *survey.Select:
+		fmt.Fprintln(os.Stderr, p.Message)
+		plainOptions(p.Options)
+		for {
+			line, err := plainReadLine("Enter a number: ")
+			if err != nil {
+				return nil, err
+			}
+			if len(line) == 0 && p.Default != nil {
+				if def, ok := p.Default.(string); ok {
+					line = def
+				}
+			}
+			answers, err := plainChoices(line, p.Options)
+			if err != nil || len(answers) != 1 {
+				fmt.Fprintln(os.Stderr, "Please enter one of the numbers above")
+				continue
+			}
+			return answers[0], nil
+		}
+	case *survey.MultiSelect:
+		fmt.Fprintln(os.Stderr, p.Message)
+		plainOptions(p.Options)
+		for {
+			line, err := plainReadLine("Enter numbers separated by commas: ")
+			if err != nil {
+				return nil, err
+			}
+			answers, err := plainChoices(line, p.Options)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err.Error())
+				continue
+			}
+			return answers, nil
+		}
+	case *survey.Confirm:
+		def := "y/N"
+		if p.Default {
+			def = "Y/n"
+		}
+		for {
+			line, err := plainReadLine(fmt.Sprintf("%s (%s): ", p.Message, def))
+			if err != nil {
+				return nil, err
+			}
+			switch strings.ToLower(line) {
+			case "":
+				return p.Default, nil
+			case "y", "yes":
+				return true, nil
+			case "n", "no":
+				return false, nil
+			}
+			fmt.Fprintln(os.Stderr, "Please answer yes or no")
+		}
+	case *survey.Input:
+		line, err := plainReadLine(p.Message + " ")
+		if err != nil {
+			return nil, err
+		}
+		if len(line) == 0 {
+			return p.Default, nil
+		}
+		return line, nil
+	case *survey.Password:
+		if term.IsTerminal(int(os.Stdin.Fd())) { // don't echo the password
+			fmt.Fprint(os.Stderr, p.Message+" ")
+			pass, err := term.ReadPassword(int(os.Stdin.Fd()))
+			fmt.Fprintln(os.Stderr)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read password: %v", err)
+			}
+			return string(pass), nil
+		}
+		return plainReadLine(p.Message + " ")
+	case *survey.Multiline:
+		return plainReadLine(p.Message + " ")
+	default:
+		return nil, fmt.Errorf("%s is not supported in --plain mode", promptMessage(p))
+	}
+}
@@ -1,3 +1,5 @@
+//go:build !wasm
+
 package utils
 
 import (
@@ -69,7 +71,7 @@ func PickDevice() (*lockdownd.DeviceValues, error) {
 		Message: "Select what iDevice to connect to:",
 		Options: choices,
 	}
-	if err := survey.AskOne(prompt, &picked); err == terminal.InterruptErr {
+	if err := AskOne(prompt, &picked); err == terminal.InterruptErr {
 		log.Warn("Exiting...")
 		os.Exit(0)
 	}
@@ -123,7 +125,7 @@ func PickDevices() ([]*lockdownd.DeviceValues, error) {
 			Message: "Select what iDevices to connect to:",
 			Options: choices,
 		}
-		if err := survey.AskOne(prompt, &selected); err == terminal.InterruptErr {
+		if err := AskOne(prompt, &selected); err == terminal.InterruptErr {
 			log.Warn("Exiting...")
 			os.Exit(0)
 		}
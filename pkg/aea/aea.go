@@ -0,0 +1,387 @@
+// Package aea implements decryption of Apple Encrypted Archives (AEA)
+package aea
+
+import (
+	"bytes"
+	"compress/flate"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/blacktop/ipsw/pkg/lzfse"
+	"github.com/ulikunitz/xz"
+	"golang.org/x/crypto/hkdf"
+)
+
+// NOTES:
+// - https://github.com/dhinakg/aeota
+// - https://theapplewiki.com/wiki/Apple_Encrypted_Archive
+
+const (
+	magic = "AEA1"
+
+	mainKeyInfo          = "AEA_AMK"
+	rootHeaderKeyInfo    = "AEA_RHEK"
+	clusterKeyInfo       = "AEA_CK"
+	clusterHeaderKeyInfo = "AEA_CHEK"
+	segmentKeyInfo       = "AEA_SK"
+
+	// ProfileSymmetric is hkdf_sha256_aesctr_hmac__symmetric__none (used by OTAs and IPSWs)
+	ProfileSymmetric = 1
+)
+
+// Header is the AEA file header
+type Header struct {
+	Magic          [4]byte
+	ProfileID      [3]byte
+	ScryptStrength uint8
+	AuthDataLength uint32
+}
+
+// Profile returns the AEA profile ID
+func (h Header) Profile() uint32 {
+	return uint32(h.ProfileID[0]) | uint32(h.ProfileID[1])<<8 | uint32(h.ProfileID[2])<<16
+}
+
+type prologue struct {
+	Signature        [128]byte
+	Salt             [32]byte
+	RootHeaderMAC    [32]byte
+	RootHeader       [48]byte
+	ClusterHeaderMAC [32]byte
+}
+
+type compressionType uint8
+
+const (
+	compressionNone     compressionType = '-'
+	compressionLZ4      compressionType = '4'
+	compressionLZBITMAP compressionType = 'b'
+	compressionLZFSE    compressionType = 'e'
+	compressionLZVN     compressionType = 'f'
+	compressionLZMA     compressionType = 'x'
+	compressionZLIB     compressionType = 'z'
+)
+
+type checksumType uint8
+
+const (
+	checksumNone checksumType = iota
+	checksumMurmur64
+	checksumSHA256
+)
+
+func (c checksumType) Size() int {
+	switch c {
+	case checksumMurmur64:
+		return 8
+	case checksumSHA256:
+		return 32
+	default:
+		return 0
+	}
+}
+
+type rootHeader struct {
+	FileSize             uint64
+	EncryptedSize        uint64
+	SegmentSize          uint32
+	SegmentsPerCluster   uint32
+	CompressionAlgorithm compressionType
+	ChecksumAlgorithm    checksumType
+	_                    [22]byte
+}
+
+// Metadata is the AEA auth data key/value pairs
+type Metadata map[string][]byte
+
+// AEA is an Apple Encrypted Archive
+type AEA struct {
+	Header
+	Metadata Metadata
+
+	hdrData []byte
+	r       io.ReaderAt
+	closer  io.Closer
+}
+
+// Open opens an AEA file
+func Open(name string) (*AEA, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	a, err := NewAEA(f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	a.closer = f
+	return a, nil
+}
+
+// Close closes the AEA file if it was opened with Open
+func (a *AEA) Close() error {
+	if a.closer != nil {
+		return a.closer.Close()
+	}
+	return nil
+}
+
+// NewAEA parses the AEA header and auth data from r
+func NewAEA(r io.ReaderAt) (*AEA, error) {
+	a := &AEA{r: r, Metadata: make(Metadata)}
+
+	a.hdrData = make([]byte, binary.Size(a.Header))
+	if _, err := r.ReadAt(a.hdrData, 0); err != nil {
+		return nil, fmt.Errorf("failed to read AEA header: %v", err)
+	}
+	if err := binary.Read(bytes.NewReader(a.hdrData), binary.LittleEndian, &a.Header); err != nil {
+		return nil, fmt.Errorf("failed to parse AEA header: %v", err)
+	}
+	if string(a.Magic[:]) != magic {
+		return nil, fmt.Errorf("invalid AEA magic: %#v", string(a.Magic[:]))
+	}
+
+	authData := make([]byte, a.AuthDataLength)
+	if _, err := r.ReadAt(authData, int64(len(a.hdrData))); err != nil {
+		return nil, fmt.Errorf("failed to read AEA auth data: %v", err)
+	}
+	for len(authData) >= 4 {
+		size := binary.LittleEndian.Uint32(authData)
+		if size < 4 || int(size) > len(authData) {
+			return nil, fmt.Errorf("invalid AEA auth data entry size: %d", size)
+		}
+		key, value, _ := bytes.Cut(authData[4:size], []byte{0})
+		a.Metadata[string(key)] = value
+		authData = authData[size:]
+	}
+
+	return a, nil
+}
+
+func deriveKey(ikm, salt []byte, info string, size int) ([]byte, error) {
+	key := make([]byte, size)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, ikm, salt, []byte(info)), key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+func indexInfo(info string, idx int) string {
+	buf := make([]byte, 4)
+	binary.LittleEndian.PutUint32(buf, uint32(idx))
+	return info + string(buf)
+}
+
+// decryptCTR decrypts data with key material laid out as MAC key (32) | AES key (32) | IV (16)
+func decryptCTR(keyMaterial, data []byte) ([]byte, error) {
+	block, err := aes.NewCipher(keyMaterial[32:64])
+	if err != nil {
+		return nil, err
+	}
+	out := make([]byte, len(data))
+	cipher.NewCTR(block, keyMaterial[64:80]).XORKeyStream(out, data)
+	return out, nil
+}
+
+func decompress(algo compressionType, data []byte, size int) ([]byte, error) {
+	switch algo {
+	case compressionNone:
+		return data, nil
+	case compressionLZFSE:
+		return lzfse.NewDecoder(data).DecodeBuffer()
+	case compressionZLIB:
+		out := make([]byte, size)
+		if _, err := io.ReadFull(flate.NewReader(bytes.NewReader(data)), out); err != nil {
+			return nil, err
+		}
+		return out, nil
+	case compressionLZMA:
+		xr, err := xz.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		out := make([]byte, size)
+		if _, err := io.ReadFull(xr, out); err != nil {
+			return nil, err
+		}
+		return out, nil
+	default:
+		return nil, fmt.Errorf("unsupported AEA segment compression '%c'", algo)
+	}
+}
+
+// Decrypt decrypts the archive with the symmetric key and writes the plaintext to w
+func (a *AEA) Decrypt(symmetricKey []byte, w io.Writer) error {
+	if a.Profile() != ProfileSymmetric {
+		return fmt.Errorf("unsupported AEA profile: %d", a.Profile())
+	}
+	if len(symmetricKey) != 32 {
+		return fmt.Errorf("invalid AEA symmetric key length: %d (expected 32)", len(symmetricKey))
+	}
+
+	off := int64(len(a.hdrData)) + int64(a.AuthDataLength)
+
+	var pro prologue
+	if err := binary.Read(io.NewSectionReader(a.r, off, int64(binary.Size(pro))), binary.LittleEndian, &pro); err != nil {
+		return fmt.Errorf("failed to read AEA prologue: %v", err)
+	}
+	off += int64(binary.Size(pro))
+
+	mainKey, err := deriveKey(symmetricKey, pro.Salt[:], mainKeyInfo+string(a.hdrData), 32)
+	if err != nil {
+		return fmt.Errorf("failed to derive main key: %v", err)
+	}
+	rhek, err := deriveKey(mainKey, nil, rootHeaderKeyInfo, 80)
+	if err != nil {
+		return fmt.Errorf("failed to derive root header key: %v", err)
+	}
+	rhData, err := decryptCTR(rhek, pro.RootHeader[:])
+	if err != nil {
+		return fmt.Errorf("failed to decrypt root header: %v", err)
+	}
+	var rh rootHeader
+	if err := binary.Read(bytes.NewReader(rhData), binary.LittleEndian, &rh); err != nil {
+		return fmt.Errorf("failed to parse root header: %v", err)
+	}
+	if rh.SegmentsPerCluster == 0 || rh.SegmentSize == 0 {
+		return fmt.Errorf("invalid AEA root header (wrong key?)")
+	}
+
+	segHdrSize := 8 + rh.ChecksumAlgorithm.Size()
+
+	var written uint64
+	for cluster := 0; written < rh.FileSize; cluster++ {
+		ck, err := deriveKey(mainKey, nil, indexInfo(clusterKeyInfo, cluster), 32)
+		if err != nil {
+			return fmt.Errorf("failed to derive cluster %d key: %v", cluster, err)
+		}
+		chek, err := deriveKey(ck, nil, clusterHeaderKeyInfo, 80)
+		if err != nil {
+			return fmt.Errorf("failed to derive cluster %d header key: %v", cluster, err)
+		}
+
+		encHdr := make([]byte, segHdrSize*int(rh.SegmentsPerCluster))
+		if _, err := a.r.ReadAt(encHdr, off); err != nil {
+			return fmt.Errorf("failed to read cluster %d header: %v", cluster, err)
+		}
+		// skip the cluster header, the next cluster header MAC and the segment MACs
+		off += int64(len(encHdr)) + 32 + 32*int64(rh.SegmentsPerCluster)
+
+		hdr, err := decryptCTR(chek, encHdr)
+		if err != nil {
+			return fmt.Errorf("failed to decrypt cluster %d header: %v", cluster, err)
+		}
+
+		for seg := 0; seg < int(rh.SegmentsPerCluster) && written < rh.FileSize; seg++ {
+			origSize := binary.LittleEndian.Uint32(hdr[seg*segHdrSize:])
+			compSize := binary.LittleEndian.Uint32(hdr[seg*segHdrSize+4:])
+			if origSize == 0 {
+				break
+			}
+
+			data := make([]byte, compSize)
+			if _, err := a.r.ReadAt(data, off); err != nil {
+				return fmt.Errorf("failed to read cluster %d segment %d: %v", cluster, seg, err)
+			}
+			off += int64(compSize)
+
+			sk, err := deriveKey(ck, nil, indexInfo(segmentKeyInfo, seg), 80)
+			if err != nil {
+				return fmt.Errorf("failed to derive cluster %d segment %d key: %v", cluster, seg, err)
+			}
+			data, err = decryptCTR(sk, data)
+			if err != nil {
+				return fmt.Errorf("failed to decrypt cluster %d segment %d: %v", cluster, seg, err)
+			}
+			if compSize < origSize {
+				data, err = decompress(rh.CompressionAlgorithm, data, int(origSize))
+				if err != nil {
+					return fmt.Errorf("failed to decompress cluster %d segment %d: %v", cluster, seg, err)
+				}
+			}
+
+			if _, err := w.Write(data); err != nil {
+				return err
+			}
+			written += uint64(len(data))
+		}
+	}
+
+	return nil
+}
+
+// DecryptConfig is the config for decrypting an AEA file
+type DecryptConfig struct {
+	// path to the AEA file
+	Input string
+	// folder to write the decrypted file to (defaults to the input's folder)
+	Output string
+	// base64 encoded symmetric key (i.e. an OTA asset's ArchiveDecryptionKey)
+	B64SymKey string
+	// PEM encoded fcs-key private key (defaults to fetching it from the archive's fcs-key-url)
+	PemKey []byte
+	// http proxy to use
+	Proxy string
+	// don't verify the certificate chain
+	Insecure bool
+}
+
+// Decrypt decrypts an AEA file and returns the path to the decrypted file
+func Decrypt(c *DecryptConfig) (string, error) {
+	a, err := Open(c.Input)
+	if err != nil {
+		return "", err
+	}
+	defer a.Close()
+
+	var key []byte
+	if len(c.B64SymKey) > 0 {
+		key, err = base64.StdEncoding.DecodeString(c.B64SymKey)
+		if err != nil {
+			return "", fmt.Errorf("failed to decode base64 symmetric key: %v", err)
+		}
+	} else {
+		pemData := c.PemKey
+		if len(pemData) == 0 {
+			pemData, err = a.FetchPrivateKey(c.Proxy, c.Insecure)
+			if err != nil {
+				return "", err
+			}
+		}
+		key, err = a.UnwrapKey(pemData)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	output := filepath.Dir(c.Input)
+	if len(c.Output) > 0 {
+		output = c.Output
+	}
+	if err := os.MkdirAll(output, 0755); err != nil {
+		return "", fmt.Errorf("failed to create output directory %s: %v", output, err)
+	}
+	fname := filepath.Join(output, strings.TrimSuffix(filepath.Base(c.Input), filepath.Ext(c.Input)))
+
+	f, err := os.Create(fname)
+	if err != nil {
+		return "", fmt.Errorf("failed to create %s: %v", fname, err)
+	}
+	defer f.Close()
+
+	if err := a.Decrypt(key, f); err != nil {
+		os.Remove(fname)
+		return "", fmt.Errorf("failed to decrypt %s: %v", c.Input, err)
+	}
+
+	return fname, nil
+}
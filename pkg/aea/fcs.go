@@ -0,0 +1,173 @@
+package aea
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/binary"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/blacktop/ipsw/internal/download"
+	"golang.org/x/crypto/hkdf"
+)
+
+const (
+	fcsKeyURLKey   = "com.apple.wkms.fcs-key-url"
+	fcsResponseKey = "com.apple.wkms.fcs-response"
+)
+
+type fcsResponse struct {
+	EncRequest []byte `json:"enc-request,omitempty"`
+	WrappedKey []byte `json:"wrapped-key,omitempty"`
+}
+
+// FcsKeyURL returns the URL of the archive's fcs-key (unwrapping private key)
+func (a *AEA) FcsKeyURL() (string, error) {
+	u, ok := a.Metadata[fcsKeyURLKey]
+	if !ok {
+		return "", fmt.Errorf("AEA auth data has no %s", fcsKeyURLKey)
+	}
+	return string(u), nil
+}
+
+// FetchPrivateKey downloads the archive's PEM encoded fcs-key
+func (a *AEA) FetchPrivateKey(proxy string, insecure bool) ([]byte, error) {
+	u, err := a.FcsKeyURL()
+	if err != nil {
+		return nil, err
+	}
+
+	client := &http.Client{
+		Transport: &http.Transport{
+			Proxy:           download.GetProxy(proxy),
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: insecure},
+		},
+	}
+
+	resp, err := client.Get(u)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch fcs-key: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch fcs-key %s: %s", u, resp.Status)
+	}
+
+	return io.ReadAll(resp.Body)
+}
+
+// UnwrapKey unwraps the archive's symmetric key with the PEM encoded fcs-key
+func (a *AEA) UnwrapKey(pemData []byte) ([]byte, error) {
+	data, ok := a.Metadata[fcsResponseKey]
+	if !ok {
+		return nil, fmt.Errorf("AEA auth data has no %s", fcsResponseKey)
+	}
+	var fcs fcsResponse
+	if err := json.Unmarshal(data, &fcs); err != nil {
+		return nil, fmt.Errorf("failed to parse fcs-response: %v", err)
+	}
+
+	block, _ := pem.Decode(pemData)
+	if block == nil {
+		return nil, fmt.Errorf("failed to decode fcs-key PEM")
+	}
+	var priv *ecdsa.PrivateKey
+	if k, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+		if priv, ok = k.(*ecdsa.PrivateKey); !ok {
+			return nil, fmt.Errorf("fcs-key is not an EC private key")
+		}
+	} else if priv, err = x509.ParseECPrivateKey(block.Bytes); err != nil {
+		return nil, fmt.Errorf("failed to parse fcs-key: %v", err)
+	}
+	key, err := priv.ECDH()
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert fcs-key: %v", err)
+	}
+
+	return hpkeOpen(key, fcs.EncRequest, fcs.WrappedKey)
+}
+
+/* HPKE (RFC 9180) base mode with DHKEM(P-256, HKDF-SHA256), HKDF-SHA256 and AES-256-GCM */
+
+const (
+	hpkeKEM  = 0x0010
+	hpkeKDF  = 0x0001
+	hpkeAEAD = 0x0002
+)
+
+func i2osp(v, n int) []byte {
+	b := make([]byte, 2)
+	binary.BigEndian.PutUint16(b, uint16(v))
+	return b[2-n:]
+}
+
+func labeledExtract(suiteID, salt []byte, label string, ikm []byte) []byte {
+	labeled := append(append(append([]byte("HPKE-v1"), suiteID...), label...), ikm...)
+	return hkdf.Extract(sha256.New, labeled, salt)
+}
+
+func labeledExpand(suiteID, prk []byte, label string, info []byte, size int) ([]byte, error) {
+	labeled := append(append(append(append(i2osp(size, 2), "HPKE-v1"...), suiteID...), label...), info...)
+	out := make([]byte, size)
+	if _, err := io.ReadFull(hkdf.Expand(sha256.New, prk, labeled), out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func hpkeOpen(priv *ecdh.PrivateKey, enc, ciphertext []byte) ([]byte, error) {
+	pkE, err := ecdh.P256().NewPublicKey(enc)
+	if err != nil {
+		return nil, fmt.Errorf("invalid HPKE encapsulated key: %v", err)
+	}
+	dh, err := priv.ECDH(pkE)
+	if err != nil {
+		return nil, fmt.Errorf("failed HPKE key agreement: %v", err)
+	}
+
+	// decapsulate
+	kemSuite := append([]byte("KEM"), i2osp(hpkeKEM, 2)...)
+	kemContext := append(append([]byte{}, enc...), priv.PublicKey().Bytes()...)
+	sharedSecret, err := labeledExpand(kemSuite, labeledExtract(kemSuite, nil, "eae_prk", dh), "shared_secret", kemContext, 32)
+	if err != nil {
+		return nil, err
+	}
+
+	// key schedule
+	suite := append(append(append([]byte("HPKE"), i2osp(hpkeKEM, 2)...), i2osp(hpkeKDF, 2)...), i2osp(hpkeAEAD, 2)...)
+	ksContext := append([]byte{0x00}, labeledExtract(suite, nil, "psk_id_hash", nil)...)
+	ksContext = append(ksContext, labeledExtract(suite, nil, "info_hash", nil)...)
+	secret := labeledExtract(suite, sharedSecret, "secret", nil)
+	key, err := labeledExpand(suite, secret, "key", ksContext, 32)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := labeledExpand(suite, secret, "base_nonce", ksContext, 12)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	out, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unwrap AEA key: %v", err)
+	}
+
+	return out, nil
+}
@@ -75,6 +75,9 @@ func (d Date) Format(s string) string {
 	t := time.Time(d)
 	return t.Format(s)
 }
+func (d Date) Time() time.Time {
+	return time.Time(d)
+}
 
 type AppStore struct {
 	P8    string
@@ -0,0 +1,71 @@
+// Package compat keeps the previous names and behavior of changed library APIs for at least one release
+// so that Go consumers and bindings get a migration window instead of a hard break.
+//
+// Every adapter logs a deprecation warning (once per process) naming its replacement; set
+// IPSW_NO_DEPRECATION_WARNINGS=1 or call SetWarnings(false) to silence them.
+package compat
+
+import (
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/apex/log"
+)
+
+// Deprecation describes a deprecated API and what replaces it
+type Deprecation struct {
+	Name        string `json:"name"`
+	Replacement string `json:"replacement"`
+	Since       string `json:"since,omitempty"` // release the API was deprecated in
+	Note        string `json:"note,omitempty"`  // how the behavior of the replacement differs
+}
+
+var deprecations = struct {
+	sync.Mutex
+	warn bool
+	used map[string]Deprecation
+}{
+	warn: len(os.Getenv("IPSW_NO_DEPRECATION_WARNINGS")) == 0,
+	used: make(map[string]Deprecation),
+}
+
+// SetWarnings enables or disables the deprecation warnings
+func SetWarnings(enabled bool) {
+	deprecations.Lock()
+	defer deprecations.Unlock()
+	deprecations.warn = enabled
+}
+
+// Warn records the use of a deprecated API and logs a warning the first time it is used
+func Warn(d Deprecation) {
+	deprecations.Lock()
+	_, seen := deprecations.used[d.Name]
+	deprecations.used[d.Name] = d
+	warn := deprecations.warn
+	deprecations.Unlock()
+	if seen || !warn {
+		return
+	}
+	msg := d.Name + " is deprecated"
+	if len(d.Since) > 0 {
+		msg += " since " + d.Since
+	}
+	msg += " and will be removed in a future release, use " + d.Replacement + " instead"
+	if len(d.Note) > 0 {
+		msg += " (" + d.Note + ")"
+	}
+	log.Warn(msg)
+}
+
+// Used returns the deprecated APIs used so far by the process (i.e. to report them in a binding's test suite)
+func Used() []Deprecation {
+	deprecations.Lock()
+	defer deprecations.Unlock()
+	var used []Deprecation
+	for _, d := range deprecations.used {
+		used = append(used, d)
+	}
+	sort.Slice(used, func(i, j int) bool { return used[i].Name < used[j].Name })
+	return used
+}
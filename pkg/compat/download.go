@@ -0,0 +1,42 @@
+package compat
+
+import (
+	"context"
+
+	"github.com/blacktop/ipsw/internal/download"
+)
+
+// GetVersion returns the iOS version of a build ID
+//
+// Deprecated: use download.GetVersionWithContext (the lookup can then be canceled).
+func GetVersion(buildID string) (string, error) {
+	Warn(Deprecation{
+		Name:        "GetVersion",
+		Replacement: "download.GetVersionWithContext",
+		Note:        "builds are resolved from the local metadata DB first when one is set",
+	})
+	return download.GetVersionWithContext(context.Background(), buildID)
+}
+
+// GetBuildID returns the build ID of a version and device identifier
+//
+// Deprecated: use download.GetBuildIDWithContext (the lookup can then be canceled).
+func GetBuildID(version, identifier string) (string, error) {
+	Warn(Deprecation{
+		Name:        "GetBuildID",
+		Replacement: "download.GetBuildIDWithContext",
+		Note:        "renamed device identifiers are resolved to their current name",
+	})
+	return download.GetBuildIDWithContext(context.Background(), version, identifier)
+}
+
+// GetIPSW returns the IPSW of a device identifier and build ID
+//
+// Deprecated: use download.GetIPSWWithContext (the lookup can then be canceled).
+func GetIPSW(identifier, buildID string) (download.IPSW, error) {
+	Warn(Deprecation{
+		Name:        "GetIPSW",
+		Replacement: "download.GetIPSWWithContext",
+	})
+	return download.GetIPSWWithContext(context.Background(), identifier, buildID)
+}
@@ -0,0 +1,27 @@
+package download
+
+import (
+	"context"
+
+	"github.com/blacktop/ipsw/internal/download"
+)
+
+// A Method is a function callable by name with Call (see RegisterMethod)
+type Method = download.Method
+
+// Call calls a method by name (i.e. ipsw_me.device, see Methods) with params, a JSON object of its
+// parameters (nil for none), and returns its JSON result; an unknown method fails with ErrCodeUnsupported
+func Call(ctx context.Context, method string, params []byte) ([]byte, error) {
+	return download.Call(ctx, method, params)
+}
+
+// Methods returns the sorted names of the methods of Call
+func Methods() []string {
+	return download.Methods()
+}
+
+// RegisterMethod makes fn callable with Call and so by every language binding of libipsw through
+// c_libipsw_call (register it in an init function; it panics if the name is taken)
+func RegisterMethod(name string, fn Method) {
+	download.RegisterMethod(name, fn)
+}
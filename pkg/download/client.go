@@ -0,0 +1,25 @@
+package download
+
+import (
+	"net/http"
+
+	"github.com/blacktop/ipsw/internal/download"
+)
+
+// A ClientOption configures a client (see NewIpswMeClient and NewDevPortal)
+type ClientOption = download.ClientOption
+
+// WithTransport makes a client send its requests through rt (i.e. a mock, a recorder, the transport of a
+// TLS intercepting corporate proxy or one that signs the requests); the proxy and insecure settings are
+// then up to rt (and the request budgets of the community APIs still apply)
+func WithTransport(rt http.RoundTripper) ClientOption {
+	return download.WithTransport(rt)
+}
+
+// IpswMeClient is an ipsw.me API client with its own transport (the package functions share one)
+type IpswMeClient = download.IpswMeClient
+
+// NewIpswMeClient returns a new ipsw.me API client
+func NewIpswMeClient(opts ...ClientOption) *IpswMeClient {
+	return download.NewIpswMeClient(opts...)
+}
@@ -0,0 +1,94 @@
+package download
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/blacktop/ipsw/internal/download"
+)
+
+// DevDownloads are the downloads of DevPortal.ListDownloads
+type DevDownloads = download.DevDownloads
+
+// DevDownload is an OS download of the developer portal (i.e. a beta IPSW)
+type DevDownload = download.DevDownload
+
+// MoreDownload is a download of the developer portal's "More Downloads" (i.e. a KDK or Xcode)
+type MoreDownload = download.MoreDownload
+
+// DevConfig is the configuration of a DevPortal
+type DevConfig struct {
+	Proxy    string
+	Insecure bool
+	// ConfigDir is where the credentials vault and the login session are stored (default: ~/.ipsw)
+	ConfigDir string
+	// VaultPassword unlocks the credentials vault when the system keychain is not used
+	VaultPassword string
+	// KeyringBackend is auto, system, env or a keyring backend name (i.e. file)
+	KeyringBackend string
+	// Account is the vault credential profile (empty for the default account)
+	Account string
+	// TrustToken is a 2FA trust token (a login that needs a verification code fails without it)
+	TrustToken string
+	// CacheTTL is how long the downloads lists are cached on disk (0 disables the cache)
+	CacheTTL time.Duration
+	// Logger (optional) is the logger of the session (default: the SetLogger one)
+	Logger Logger
+}
+
+// DevPortal is a session of the Apple developer portal (https://developer.apple.com/download)
+//
+// It never prompts: the credentials, vault password and 2FA trust token must be configured.
+// A DevPortal is not safe for concurrent use.
+type DevPortal struct {
+	dp *download.DevPortal
+}
+
+// NewDevPortal opens the credentials vault of a dev portal session (nil uses the defaults)
+func NewDevPortal(config *DevConfig, opts ...ClientOption) (*DevPortal, error) {
+	if config == nil {
+		config = &DevConfig{}
+	}
+	conf := &download.DevConfig{
+		Proxy:          config.Proxy,
+		Insecure:       config.Insecure,
+		ConfigDir:      config.ConfigDir,
+		VaultPassword:  config.VaultPassword,
+		KeyringBackend: config.KeyringBackend,
+		Account:        config.Account,
+		TrustToken:     config.TrustToken,
+		CacheTTL:       config.CacheTTL,
+		Logger:         config.Logger,
+		ResumeAll:      true,
+		Headless:       true,
+	}
+	if len(conf.ConfigDir) == 0 {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get user home directory: %v", err)
+		}
+		conf.ConfigDir = filepath.Join(home, ".ipsw")
+	}
+	dp := download.NewDevPortal(conf, opts...)
+	if err := dp.Init(); err != nil {
+		return nil, fmt.Errorf("failed to initialize dev portal: %v", err)
+	}
+	return &DevPortal{dp: dp}, nil
+}
+
+// Login logs in to the developer portal (empty credentials are read from the vault)
+func (p *DevPortal) Login(username, password string) error {
+	return p.dp.Login(username, password)
+}
+
+// ListDownloads returns the downloads of a type (os or more, empty for both) of a logged in session
+func (p *DevPortal) ListDownloads(downloadType string) (*DevDownloads, error) {
+	return p.dp.ListDownloads(downloadType)
+}
+
+// Download downloads a developer portal URL (i.e. a DevDownload's) into folder (the current directory if empty)
+func (p *DevPortal) Download(url, folder string) error {
+	return p.dp.Download(url, folder)
+}
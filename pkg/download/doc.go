@@ -0,0 +1,15 @@
+// Package download is the public Go API of ipsw's download clients: the ipsw.me API, the Apple developer
+// portal, Apple's OTA servers (pallas) and resumable, checksum verified file downloads.
+//
+// It is a thin layer over the clients the ipsw CLI and libipsw are built on (internal/download) that only
+// exposes what is meant to stay stable: identifiers are added but not changed or removed outside of a
+// major release of the ipsw module. Nothing in this package prompts or renders progress bars, so it is
+// safe to embed in other programs, and it does not need cgo.
+//
+// Errors can be classified with CodeOf (i.e. to tell a missing device from a network error):
+//
+//	devices, err := download.GetAllDevices(ctx)
+//	if download.CodeOf(err) == download.ErrCodeNetwork {
+//		// retry later
+//	}
+package download
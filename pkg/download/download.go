@@ -0,0 +1,100 @@
+package download
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"path/filepath"
+
+	"github.com/blacktop/ipsw/internal/download"
+)
+
+// Stats are the statistics of a download
+type Stats = download.Stats
+
+// Progress is the progress of a download reported to Options.OnProgress
+type Progress = download.Progress
+
+// ErrorCode classifies the errors of this package (see CodeOf)
+type ErrorCode = download.ErrorCode
+
+// The error codes of CodeOf (they never change, new ones are only appended)
+const (
+	ErrCodeOK              = download.ErrCodeOK
+	ErrCodeUnknown         = download.ErrCodeUnknown
+	ErrCodeInvalidArgument = download.ErrCodeInvalidArgument
+	ErrCodeNotFound        = download.ErrCodeNotFound
+	ErrCodeNetwork         = download.ErrCodeNetwork
+	ErrCodeParse           = download.ErrCodeParse
+	ErrCodeAuth            = download.ErrCodeAuth
+	ErrCodeCanceled        = download.ErrCodeCanceled
+	ErrCodeTimeout         = download.ErrCodeTimeout
+	ErrCodeChecksum        = download.ErrCodeChecksum
+	ErrCodeUnsupported     = download.ErrCodeUnsupported
+)
+
+// ErrInvalidArgument is returned (wrapped) for missing or invalid arguments
+var ErrInvalidArgument = download.ErrInvalidArgument
+
+// CodeOf returns the ErrorCode of an error returned by this package
+func CodeOf(err error) ErrorCode {
+	return download.ErrorCodeOf(err)
+}
+
+// BuildInfo is the version of the package and what it was built with (see Version)
+type BuildInfo = download.BuildInfo
+
+// Capabilities are the sources and features of a build
+type Capabilities = download.Capabilities
+
+// Version returns the version, git commit and capabilities of the package
+func Version() BuildInfo {
+	return download.Version()
+}
+
+// Options are the options of Download
+type Options struct {
+	// expected checksums (the file is verified against the strongest one given)
+	SHA1     string
+	SHA256   string
+	Proxy    string
+	Insecure bool
+	// OnProgress (optional) is called with the download's progress (at most every 250ms)
+	OnProgress func(Progress)
+	// Logger (optional) is the logger of the download (default: the SetLogger/WithLogger one)
+	Logger Logger
+}
+
+// Download downloads url to dest (the URL's file name in the current directory if empty) and returns its Stats;
+// a partial download is resumed and an existing dest is kept
+func Download(ctx context.Context, url, dest string, opts *Options) (*Stats, error) {
+	if len(url) == 0 {
+		return nil, fmt.Errorf("url is required: %w", ErrInvalidArgument)
+	}
+	if opts == nil {
+		opts = &Options{}
+	}
+	d := download.NewDownload(opts.Proxy, opts.Insecure, false, true, false, false, false)
+	d.URL = url
+	d.Sha1 = opts.SHA1
+	d.SHA256 = opts.SHA256
+	d.DestName = dest
+	if len(d.DestName) == 0 {
+		d.DestName = path.Base(url)
+	}
+	d.DestName = filepath.Clean(d.DestName)
+	d.OnProgress = opts.OnProgress
+	d.Logger = opts.Logger
+	if d.OnProgress == nil {
+		d.OnProgress = func(Progress) {} // never render a progress bar
+	}
+	if skip, err := d.SkipExisting(); err != nil {
+		return nil, err
+	} else if !skip {
+		if err := d.DoWithContext(ctx); err != nil {
+			return nil, err
+		}
+	}
+	stats := d.Stats()
+	return &stats, nil
+}
@@ -0,0 +1,364 @@
+package download_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	idownload "github.com/blacktop/ipsw/internal/download"
+	"github.com/blacktop/ipsw/pkg/download"
+	"github.com/blacktop/ipsw/pkg/ota/types"
+	"github.com/blacktop/ipsw/pkg/testsupport"
+)
+
+func TestVersion(t *testing.T) {
+	info := download.Version()
+	if info.CAPIVersion != idownload.CAPIVersion {
+		t.Errorf("CAPIVersion = %s, want %s", info.CAPIVersion, idownload.CAPIVersion)
+	}
+	if len(info.Version) == 0 || len(info.GoVersion) == 0 {
+		t.Errorf("Version() = %+v, want a version and a Go version", info)
+	}
+	if !slices.Contains(info.Capabilities.Sources, "ipsw.me") {
+		t.Errorf("Sources = %v, want ipsw.me", info.Capabilities.Sources)
+	}
+}
+
+func TestIPSWMe(t *testing.T) {
+	testsupport.NewFirmwareServer(t)
+	data := testsupport.FirmwareData()
+
+	ctx := context.Background()
+	fw, err := download.GetIPSW(ctx, "iPhone15,2", "21A329")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fw.URL != testsupport.FirmwareURL {
+		t.Fatalf("GetIPSW() URL = %s, want %s", fw.URL, testsupport.FirmwareURL)
+	}
+	if _, err := download.GetIPSW(ctx, "iPhone15,2", "00A000"); download.CodeOf(err) != download.ErrCodeNotFound {
+		t.Fatalf("GetIPSW() of an unknown build = %v, want a not-found error", err)
+	}
+
+	dest := filepath.Join(t.TempDir(), filepath.Base(fw.URL))
+	var progressed bool
+	stats, err := download.Download(ctx, fw.URL, dest, &download.Options{
+		SHA1:       fw.SHA1,
+		OnProgress: func(download.Progress) { progressed = true },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Bytes != int64(len(data)) || !progressed {
+		t.Fatalf("Download() stats = %+v (progress reported: %t)", stats, progressed)
+	}
+	if got, _ := os.ReadFile(dest); !bytes.Equal(got, data) {
+		t.Fatal("downloaded IPSW does not match")
+	}
+
+	if _, err := download.Download(ctx, "", dest, nil); !errors.Is(err, download.ErrInvalidArgument) {
+		t.Fatalf("Download() without a URL = %v, want ErrInvalidArgument", err)
+	}
+
+	out, err := download.Call(ctx, "ipsw_me.ipsw", []byte(`{"identifier":"iPhone15,2","build":"21A329"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var called download.IPSW
+	if err := json.Unmarshal(out, &called); err != nil {
+		t.Fatal(err)
+	}
+	if called.URL != testsupport.FirmwareURL {
+		t.Fatalf("Call(ipsw_me.ipsw) URL = %s, want %s", called.URL, testsupport.FirmwareURL)
+	}
+}
+
+// newDownload returns a download of url to dest that reports progress instead of rendering a bar
+func newDownload(url, dest string) *idownload.Download {
+	d := idownload.NewDownload("", false, false, true, false, false, false)
+	d.URL = url
+	d.DestName = dest
+	d.OnProgress = func(idownload.Progress) {}
+	return d
+}
+
+func TestChunkedResume(t *testing.T) {
+	s := testsupport.NewFirmwareServer(t)
+	idownload.SetRetryPolicy(idownload.RetryPolicy{MaxAttempts: 1}) // only the chunks are retried
+	t.Cleanup(func() { idownload.SetRetryPolicy(idownload.DefaultRetryPolicy) })
+
+	const chunkSize = 16 * 1024 * 1024 // the smallest chunk
+	data := make([]byte, 2*chunkSize)
+	for i := range data {
+		data[i] = byte(i % 251) // a misplaced chunk doesn't match
+	}
+	url := "https://updates.cdn-apple.com/2023/large.ipsw"
+	if err := s.AddFile(url, data); err != nil {
+		t.Fatal(err)
+	}
+	// the second chunk fails its first attempt and all of its retries
+	if err := s.FailRange(url, chunkSize, http.StatusServiceUnavailable, 4); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	dest := filepath.Join(t.TempDir(), "large.ipsw")
+	d := newDownload(url, dest)
+	d.Threads = 2
+	if err := d.DoWithContext(ctx); err == nil || !strings.Contains(err.Error(), "503") {
+		t.Fatalf("DoWithContext() with a failing chunk = %v, want a 503 error", err)
+	}
+	if _, err := os.Stat(dest + ".download"); err != nil {
+		t.Fatalf("the partial download was not kept: %v", err)
+	}
+
+	d = newDownload(url, dest)
+	d.Sha1 = fmt.Sprintf("%x", sha1.Sum(data))
+	d.Threads = 2
+	if err := d.DoWithContext(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if stats := d.Stats(); stats.ResumedBytes == 0 || stats.ResumedBytes >= int64(len(data)) {
+		t.Errorf("ResumedBytes = %d, want the completed chunk", stats.ResumedBytes)
+	}
+	if got, _ := os.ReadFile(dest); !bytes.Equal(got, data) {
+		t.Fatal("resumed download does not match")
+	}
+	if _, err := os.Stat(dest + ".download"); !os.IsNotExist(err) {
+		t.Errorf("the partial download was not removed: %v", err)
+	}
+}
+
+func TestRetry(t *testing.T) {
+	s := testsupport.NewFirmwareServer(t)
+	data := testsupport.FirmwareData()
+	t.Cleanup(func() { idownload.SetRetryPolicy(idownload.DefaultRetryPolicy) })
+
+	ctx := context.Background()
+	dir := t.TempDir()
+	for _, tt := range []struct {
+		attempts int
+		wantErr  bool
+	}{
+		{1, true}, // retries disabled
+		{2, false},
+	} {
+		idownload.SetRetryPolicy(idownload.RetryPolicy{MaxAttempts: tt.attempts, BaseDelay: time.Millisecond})
+		if err := s.FailFile(testsupport.FirmwareURL, http.StatusServiceUnavailable, 1); err != nil {
+			t.Fatal(err)
+		}
+		dest := filepath.Join(dir, fmt.Sprintf("%d.ipsw", tt.attempts))
+		_, err := download.Download(ctx, testsupport.FirmwareURL, dest, nil)
+		if tt.wantErr {
+			if err == nil || !strings.Contains(err.Error(), "503") {
+				t.Errorf("Download() with %d attempt(s) = %v, want a 503 error", tt.attempts, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("Download() with %d attempts = %v", tt.attempts, err)
+		}
+		if got, _ := os.ReadFile(dest); !bytes.Equal(got, data) {
+			t.Fatal("downloaded IPSW does not match")
+		}
+	}
+}
+
+func TestBadChecksum(t *testing.T) {
+	testsupport.NewFirmwareServer(t)
+	idownload.SetVerifyRetries(1)
+	t.Cleanup(func() { idownload.SetVerifyRetries(idownload.DefaultVerifyRetries) })
+
+	dest := filepath.Join(t.TempDir(), filepath.Base(testsupport.FirmwareURL))
+	d := newDownload(testsupport.FirmwareURL, dest)
+	d.SHA256 = strings.Repeat("0", 64)
+	err := d.DoWithContext(context.Background())
+	if download.CodeOf(err) != download.ErrCodeChecksum {
+		t.Fatalf("DoWithContext() with a bad checksum = %v, want a checksum error", err)
+	}
+	if v := d.Stats().Verification; v == nil || v.Verified || v.Attempts != 2 {
+		t.Errorf("Verification = %+v, want a failed verification after 2 attempts", v)
+	}
+	for _, name := range []string{dest, dest + ".download"} {
+		if _, err := os.Stat(name); !os.IsNotExist(err) {
+			t.Errorf("%s was kept after the checksum mismatch: %v", name, err)
+		}
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	testsupport.NewFirmwareServer(t)
+	data := testsupport.FirmwareData()
+
+	dest := filepath.Join(t.TempDir(), filepath.Base(testsupport.FirmwareURL))
+	d := newDownload(testsupport.FirmwareURL, dest)
+	d.RateLimit = int64(len(data) / 2) // the first half is the limiter's burst
+	start := time.Now()
+	if err := d.DoWithContext(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < 900*time.Millisecond {
+		t.Errorf("download of %d bytes at %d bytes/s took %s, want at least 1s", len(data), d.RateLimit, elapsed)
+	}
+	if got, _ := os.ReadFile(dest); !bytes.Equal(got, data) {
+		t.Fatal("downloaded IPSW does not match")
+	}
+}
+
+// roundTripFunc is a mock transport
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+// staleMetadata is a metadata source that a client with its own transport must not use
+type staleMetadata struct{}
+
+func (staleMetadata) GetDevice(identifier string) (idownload.Device, error) {
+	return idownload.Device{Identifier: identifier, Name: "stale"}, nil
+}
+func (staleMetadata) GetBuild(buildID string) (idownload.IPSW, error) {
+	return idownload.IPSW{BuildID: buildID, Version: "stale"}, nil
+}
+func (staleMetadata) GetBuildID(version, identifier string) (string, error) { return "stale", nil }
+
+func TestIpswMeClientTransport(t *testing.T) {
+	testsupport.TempConfig(t)
+	idownload.SetMetadataSource(staleMetadata{}, false)
+	t.Cleanup(func() { idownload.SetMetadataSource(nil, false) })
+
+	var paths []string
+	client := download.NewIpswMeClient(download.WithTransport(roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		paths = append(paths, req.URL.Path)
+		if req.Header.Get("If-None-Match") != "" {
+			t.Errorf("%s was revalidated from the HTTP cache", req.URL.Path)
+		}
+		header := make(http.Header)
+		header.Set("ETag", `"v1"`)
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     header,
+			Body:       io.NopCloser(strings.NewReader(`{"name": "iPhone 14 Pro", "identifier": "iPhone15,2", "buildid": "21A329", "version": "17.0"}`)),
+			Request:    req,
+		}, nil
+	})))
+
+	ctx := context.Background()
+	fw, err := client.GetIPSW(ctx, "iPhone15,2", "21A329")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fw.Version != "17.0" {
+		t.Errorf("GetIPSW() = %+v, want version 17.0", fw)
+	}
+	// neither the metadata source nor the HTTP cache answer for the transport
+	for i := 0; i < 2; i++ {
+		if dev, err := client.GetDevice(ctx, "iPhone15,2"); err != nil || dev.Name != "iPhone 14 Pro" {
+			t.Fatalf("GetDevice() = %+v, %v, want iPhone 14 Pro from the transport", dev, err)
+		}
+	}
+	want := []string{"/v4/ipsw/iPhone15,2/21A329", "/v4/device/iPhone15,2", "/v4/device/iPhone15,2"}
+	if !slices.Equal(paths, want) {
+		t.Errorf("the transport got %v, want %v", paths, want)
+	}
+}
+
+func TestCall(t *testing.T) {
+	ctx := context.Background()
+	if !slices.Contains(download.Methods(), "ipsw_me.device") {
+		t.Fatalf("Methods() = %v, want ipsw_me.device", download.Methods())
+	}
+	for _, tt := range []struct {
+		method string
+		params string
+		code   download.ErrorCode
+	}{
+		{"no.such_method", "", download.ErrCodeUnsupported},
+		{"ipsw_me.device", "", download.ErrCodeInvalidArgument},
+		{"ipsw_me.ipsw", `{"identifier":"iPhone15,2"}`, download.ErrCodeInvalidArgument},
+		{"ipsw_me.device", `{"identifier":`, download.ErrCodeParse},
+	} {
+		if _, err := download.Call(ctx, tt.method, []byte(tt.params)); download.CodeOf(err) != tt.code {
+			t.Errorf("Call(%s, %s) = %v, want a %s error", tt.method, tt.params, err, tt.code)
+		}
+	}
+	out, err := download.Call(ctx, "libipsw.version", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var info download.BuildInfo
+	if err := json.Unmarshal(out, &info); err != nil || info.CAPIVersion != idownload.CAPIVersion {
+		t.Fatalf("Call(libipsw.version) = %s (%v)", out, err)
+	}
+}
+
+func TestGetOTAs(t *testing.T) {
+	s := testsupport.NewFirmwareServer(t)
+
+	s.AssetSets = idownload.AssetSets{
+		PublicAssetSets: map[string][]idownload.AssetSet{
+			"iOS": {{ProductVersion: "17.0", SupportedDevices: []string{"iPhone15,2"}}},
+		},
+	}
+	s.OTAs = []types.Asset{{
+		OSVersion:        "17.0",
+		Build:            "21A329",
+		SupportedDevices: []string{"iPhone15,2"},
+		BaseURL:          "https://updates.cdn-apple.com/",
+		RelativePath:     "ota.zip",
+	}}
+
+	otas, err := download.GetOTAs(&download.OtaQuery{Platform: "iOS", Device: "iPhone15,2", Version: "17.0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(otas) == 0 || otas[0].Build != "21A329" {
+		t.Fatalf("GetOTAs() = %v", otas)
+	}
+	if _, err := download.GetOTAs(&download.OtaQuery{}); !errors.Is(err, download.ErrInvalidArgument) {
+		t.Fatalf("GetOTAs() without a platform = %v, want ErrInvalidArgument", err)
+	}
+}
+
+func TestDevPortal(t *testing.T) {
+	s := testsupport.NewFirmwareServer(t)
+
+	s.DevDownloads["iOS 17 beta"] = []download.DevDownload{{
+		Title: "iPhone 15 Pro",
+		Build: "21A5248v",
+		URL:   "https://developer.apple.com/services-account/download?path=/iOS/iPhone16,1_21A5248v.ipsw",
+	}}
+
+	dp, err := download.NewDevPortal(&download.DevConfig{
+		ConfigDir:      t.TempDir(),
+		KeyringBackend: "file",
+		VaultPassword:  "vault",
+		TrustToken:     s.TrustToken,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := dp.Login(s.Username, s.Password); err != nil {
+		t.Fatal(err)
+	}
+	dls, err := dp.ListDownloads("os")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dl := dls.OS["iOS 17 beta"]; len(dl) != 1 || dl[0].Build != "21A5248v" {
+		t.Fatalf("ListDownloads() = %v", dls.OS)
+	}
+	if _, err := dp.ListDownloads("tools"); download.CodeOf(err) != download.ErrCodeInvalidArgument {
+		t.Fatalf("ListDownloads() of an unknown type = %v, want an invalid-argument error", err)
+	}
+}
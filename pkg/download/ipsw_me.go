@@ -0,0 +1,58 @@
+package download
+
+import (
+	"context"
+
+	"github.com/blacktop/ipsw/internal/download"
+)
+
+// Device is an ipsw.me device
+type Device = download.Device
+
+// IPSW is an ipsw.me IPSW (the OTAs of GetDeviceOTAs have the same fields)
+type IPSW = download.IPSW
+
+// GetAllDevices returns all the ipsw.me devices (without their firmwares)
+func GetAllDevices(ctx context.Context) ([]Device, error) {
+	return download.GetAllDevicesWithContext(ctx)
+}
+
+// GetDevice returns the ipsw.me device with an identifier (i.e. iPhone16,1) and its IPSWs
+func GetDevice(ctx context.Context, identifier string) (Device, error) {
+	return download.GetDeviceWithContext(ctx, identifier)
+}
+
+// GetDeviceIPSWs returns the IPSWs of a device (i.e. iPhone16,1)
+func GetDeviceIPSWs(ctx context.Context, identifier string) ([]IPSW, error) {
+	return download.GetDeviceIPSWsWithContext(ctx, identifier)
+}
+
+// GetDeviceOTAs returns the OTAs of a device (i.e. iPhone16,1)
+func GetDeviceOTAs(ctx context.Context, identifier string) ([]IPSW, error) {
+	return download.GetDeviceOTAsWithContext(ctx, identifier)
+}
+
+// GetSignedIPSWs returns the IPSWs of a device that Apple is still signing
+func GetSignedIPSWs(ctx context.Context, identifier string) ([]IPSW, error) {
+	return download.GetSignedIPSWsWithContext(ctx, identifier)
+}
+
+// GetAllIPSW returns the IPSWs of a version (i.e. 17.0) for all devices
+func GetAllIPSW(ctx context.Context, version string) ([]IPSW, error) {
+	return download.GetAllIPSWWithContext(ctx, version)
+}
+
+// GetIPSW returns the IPSW of a device and build (i.e. iPhone16,1 and 21A329)
+func GetIPSW(ctx context.Context, identifier, build string) (IPSW, error) {
+	return download.GetIPSWWithContext(ctx, identifier, build)
+}
+
+// GetVersion returns the version of a build (i.e. 17.0 for 21A329)
+func GetVersion(ctx context.Context, build string) (string, error) {
+	return download.GetVersionWithContext(ctx, build)
+}
+
+// GetBuildID returns the build of a version for a device (i.e. 21A329 for 17.0 and iPhone16,1)
+func GetBuildID(ctx context.Context, version, identifier string) (string, error) {
+	return download.GetBuildIDWithContext(ctx, version, identifier)
+}
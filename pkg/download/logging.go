@@ -0,0 +1,21 @@
+package download
+
+import (
+	"context"
+
+	"github.com/apex/log"
+	"github.com/blacktop/ipsw/internal/download"
+)
+
+// Logger is the logger of this package (i.e. a *log.Logger of github.com/apex/log with your own handler)
+type Logger = log.Interface
+
+// SetLogger sets the logger of the calls without their own (nil restores apex/log's default logger)
+func SetLogger(l Logger) {
+	download.SetLogger(l)
+}
+
+// WithLogger returns a context whose calls log to l (it takes precedence over SetLogger's logger)
+func WithLogger(ctx context.Context, l Logger) context.Context {
+	return download.WithLogger(ctx, l)
+}
@@ -0,0 +1,82 @@
+package download
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/blacktop/ipsw/internal/download"
+	"github.com/blacktop/ipsw/pkg/ota/types"
+	semver "github.com/hashicorp/go-version"
+)
+
+// OtaAsset is an OTA of Apple's OTA servers (its URL is BaseURL+RelativePath)
+type OtaAsset = types.Asset
+
+// OtaUpdate are the OTAs that update a device from one build to another (see GetOtaUpdate)
+type OtaUpdate = download.OtaUpdate
+
+// OtaArtifact is a downloadable OTA of an OtaUpdate
+type OtaArtifact = download.OtaArtifact
+
+// OtaQuery selects the OTAs of GetOTAs
+type OtaQuery struct {
+	// Platform is ios, macos, watchos, tvos, audioos, visionos or accessory (required)
+	Platform string
+	Device   string // i.e. iPhone16,1
+	Model    string // i.e. D83AP
+	Version  string // i.e. 17.0
+	Build    string // i.e. 21A329
+	Beta     bool
+	RSR      bool // Rapid Security Responses
+	// delta OTAs are matched by the version/build they update from
+	PrerequisiteVersion string
+	PrerequisiteBuild   string
+	Proxy               string
+	Insecure            bool
+	Timeout             time.Duration // of the requests (default: 90s)
+	Logger              Logger        // (optional) default: the SetLogger one
+}
+
+// GetOTAs queries Apple's OTA servers for the OTAs matching a query
+func GetOTAs(q *OtaQuery) ([]OtaAsset, error) {
+	if q == nil || len(q.Platform) == 0 {
+		return nil, fmt.Errorf("OTA platform is required: %w", download.ErrInvalidArgument)
+	}
+	as, err := download.GetAssetSets(q.Proxy, q.Insecure)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get asset sets: %v", err)
+	}
+	conf := download.OtaConf{
+		Platform:            strings.ToLower(q.Platform),
+		Beta:                q.Beta,
+		RSR:                 q.RSR,
+		Device:              q.Device,
+		Model:               q.Model,
+		Build:               q.Build,
+		PrerequisiteVersion: q.PrerequisiteVersion,
+		PrerequisiteBuild:   q.PrerequisiteBuild,
+		Proxy:               q.Proxy,
+		Insecure:            q.Insecure,
+		Timeout:             90, // OtaConf.Timeout is in seconds
+		Logger:              q.Logger,
+	}
+	if q.Timeout > 0 {
+		conf.Timeout = q.Timeout / time.Second
+	}
+	if len(q.Version) > 0 {
+		if conf.Version, err = semver.NewVersion(q.Version); err != nil {
+			return nil, fmt.Errorf("invalid OTA version %#v: %w", q.Version, download.ErrInvalidArgument)
+		}
+	}
+	o, err := download.NewOTA(as, conf)
+	if err != nil {
+		return nil, err
+	}
+	return o.GetPallasOTAs()
+}
+
+// GetOtaUpdate returns the (delta and full) OTAs that update a device from one build to another
+func GetOtaUpdate(device, fromBuild, toBuild, proxy string, insecure bool) (*OtaUpdate, error) {
+	return download.GetOtaUpdate(device, fromBuild, toBuild, proxy, insecure)
+}
@@ -105,7 +105,7 @@ func ExtractFromDMG(i *info.Info, dmgPath, destPath string, arches []string, dri
 				Options:  matches,
 				PageSize: 15,
 			}
-			if err := survey.AskOne(prompt, &selMatches); err != nil {
+			if err := utils.AskOne(prompt, &selMatches); err != nil {
 				if err == terminal.InterruptErr {
 					log.Warn("Exiting...")
 					return nil, nil
@@ -200,10 +200,7 @@ func ExtractFromRemoteCryptex(zr *zip.Reader, destPath string, arches []string,
 			defer rc.Close()
 			// setup progress bar
 			var total int64 = int64(zf.UncompressedSize64)
-			p := mpb.New(
-				mpb.WithWidth(60),
-				mpb.WithRefreshRate(180*time.Millisecond),
-			)
+			p := utils.NewProgress(filepath.Base(zf.Name))
 			bar := p.New(total,
 				mpb.BarStyle().Lbound("[").Filler("=").Tip(">").Padding("-").Rbound("|"),
 				mpb.PrependDecorators(
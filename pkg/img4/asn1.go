@@ -0,0 +1,194 @@
+package img4
+
+import (
+	"encoding/asn1"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+)
+
+const maxDumpBytes = 32
+
+var universalTagNames = map[int]string{
+	asn1.TagBoolean:         "BOOLEAN",
+	asn1.TagInteger:         "INTEGER",
+	asn1.TagBitString:       "BIT STRING",
+	asn1.TagOctetString:     "OCTET STRING",
+	asn1.TagNull:            "NULL",
+	asn1.TagOID:             "OBJECT IDENTIFIER",
+	asn1.TagEnum:            "ENUMERATED",
+	asn1.TagUTF8String:      "UTF8String",
+	asn1.TagSequence:        "SEQUENCE",
+	asn1.TagSet:             "SET",
+	asn1.TagNumericString:   "NumericString",
+	asn1.TagPrintableString: "PrintableString",
+	asn1.TagT61String:       "T61String",
+	asn1.TagIA5String:       "IA5String",
+	asn1.TagUTCTime:         "UTCTime",
+	asn1.TagGeneralizedTime: "GeneralizedTime",
+	asn1.TagGeneralString:   "GeneralString",
+	asn1.TagBMPString:       "BMPString",
+}
+
+var img4Names = map[string]string{
+	"IMG4": "Image4 container",
+	"IM4P": "Image4 payload",
+	"IM4M": "Image4 manifest",
+	"IM4R": "Image4 restore info",
+	"IM4C": "Image4 certificate",
+	"MANB": "manifest body",
+	"MANP": "manifest properties",
+	"OBJP": "object properties",
+	"BNCN": "boot nonce",
+	"BORN": "boot nonce",
+	"CHIP": "chip ID",
+	"BORD": "board ID",
+	"ECID": "exclusive chip ID",
+	"SDOM": "security domain",
+	"CEPO": "certificate epoch",
+	"CPRO": "certificate production status",
+	"CSEC": "certificate security mode",
+	"DGST": "digest",
+	"EKEY": "effective security mode (key)",
+	"EPRO": "effective production status",
+	"ESEC": "effective security mode",
+	"snon": "SEP nonce",
+	"srvn": "server nonce",
+}
+
+// DumpASN1 writes an annotated tree of the DER encoded data (recognizing IMG4/IM4M tags) to w
+func DumpASN1(w io.Writer, data []byte) error {
+	var offset int // of data in the input
+	for len(data) > 0 {
+		var raw asn1.RawValue
+		rest, err := asn1.Unmarshal(data, &raw)
+		if err != nil {
+			return fmt.Errorf("failed to parse DER at offset %d: %v", offset, err)
+		}
+		dumpNode(w, raw, 0)
+		offset += len(data) - len(rest)
+		data = rest
+	}
+	return nil
+}
+
+func dumpNode(w io.Writer, raw asn1.RawValue, depth int) {
+	indent := strings.Repeat("  ", depth)
+	name := tagName(raw)
+
+	if raw.IsCompound {
+		children, err := parseChildren(raw.Bytes)
+		if err != nil {
+			fmt.Fprintf(w, "%s%s (%d bytes) <failed to parse children: %v>\n", indent, name, len(raw.Bytes), err)
+			return
+		}
+		fmt.Fprintf(w, "%s%s (%d elem)\n", indent, name, len(children))
+		for _, child := range children {
+			dumpNode(w, child, depth+1)
+		}
+		return
+	}
+
+	fmt.Fprintf(w, "%s%s %s\n", indent, name, primitiveValue(raw))
+
+	// OCTET STRINGs in IM4M/IM4R often wrap more DER
+	if raw.Class == asn1.ClassUniversal && raw.Tag == asn1.TagOctetString {
+		if children, err := parseChildren(raw.Bytes); err == nil && len(children) > 0 && children[0].IsCompound {
+			for _, child := range children {
+				dumpNode(w, child, depth+1)
+			}
+		}
+	}
+}
+
+func parseChildren(data []byte) ([]asn1.RawValue, error) {
+	var children []asn1.RawValue
+	for len(data) > 0 {
+		var child asn1.RawValue
+		rest, err := asn1.Unmarshal(data, &child)
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, child)
+		data = rest
+	}
+	return children, nil
+}
+
+func tagName(raw asn1.RawValue) string {
+	switch raw.Class {
+	case asn1.ClassUniversal:
+		if name, ok := universalTagNames[raw.Tag]; ok {
+			return name
+		}
+		return fmt.Sprintf("[UNIVERSAL %d]", raw.Tag)
+	case asn1.ClassApplication:
+		return fmt.Sprintf("[APPLICATION %d]", raw.Tag)
+	case asn1.ClassContextSpecific:
+		return fmt.Sprintf("[%d]", raw.Tag)
+	default:
+		// IMG4 uses private tags that are the big-endian value of a FourCC
+		if fourcc := tagFourCC(raw.Tag); len(fourcc) > 0 {
+			if desc, ok := img4Names[fourcc]; ok {
+				return fmt.Sprintf("[PRIVATE %s] (%s)", fourcc, desc)
+			}
+			return fmt.Sprintf("[PRIVATE %s]", fourcc)
+		}
+		return fmt.Sprintf("[PRIVATE %d]", raw.Tag)
+	}
+}
+
+func tagFourCC(tag int) string {
+	b := make([]byte, 4)
+	binary.BigEndian.PutUint32(b, uint32(tag))
+	for _, c := range b {
+		if c > unicode.MaxASCII || !unicode.IsPrint(rune(c)) {
+			return ""
+		}
+	}
+	return string(b)
+}
+
+func primitiveValue(raw asn1.RawValue) string {
+	if raw.Class != asn1.ClassUniversal {
+		return hexPreview(raw.Bytes)
+	}
+	switch raw.Tag {
+	case asn1.TagBoolean:
+		var b bool
+		if _, err := asn1.Unmarshal(raw.FullBytes, &b); err == nil {
+			return fmt.Sprintf("%t", b)
+		}
+	case asn1.TagInteger, asn1.TagEnum:
+		if len(raw.Bytes) <= 8 {
+			var i int64
+			if _, err := asn1.Unmarshal(raw.FullBytes, &i); err == nil {
+				return fmt.Sprintf("%d (%#x)", i, i)
+			}
+		}
+	case asn1.TagOID:
+		var oid asn1.ObjectIdentifier
+		if _, err := asn1.Unmarshal(raw.FullBytes, &oid); err == nil {
+			return oid.String()
+		}
+	case asn1.TagNull:
+		return ""
+	case asn1.TagUTF8String, asn1.TagIA5String, asn1.TagPrintableString, asn1.TagNumericString, asn1.TagT61String, asn1.TagGeneralString:
+		if desc, ok := img4Names[string(raw.Bytes)]; ok {
+			return fmt.Sprintf("%s (%s)", raw.Bytes, desc)
+		}
+		return string(raw.Bytes)
+	case asn1.TagUTCTime, asn1.TagGeneralizedTime:
+		return string(raw.Bytes)
+	}
+	return hexPreview(raw.Bytes)
+}
+
+func hexPreview(data []byte) string {
+	if len(data) > maxDumpBytes {
+		return fmt.Sprintf("(%d bytes) %x...", len(data), data[:maxDumpBytes])
+	}
+	return fmt.Sprintf("(%d bytes) %x", len(data), data)
+}
@@ -37,6 +37,10 @@ type Device struct {
 	MemClass    uint64           `json:"mem_class,omitempty"`
 	SDKPlatform string           `json:"sdk,omitempty"`
 	Type        string           `json:"type,omitempty"`
+	// ANumbers are the regulatory model numbers (i.e. A2848)
+	ANumbers []string `json:"a_numbers,omitempty"`
+	// ModelNumbers are the order/part numbers without the region suffix (i.e. MU663)
+	ModelNumbers []string `json:"model_numbers,omitempty"`
 }
 
 type Devices map[string]Device
@@ -150,6 +154,12 @@ func (i *Info) GetDevices(devs *Devices) error {
 			case strings.HasPrefix(dt.ProductType, "Mac"):
 				devType = "macos"
 				devSDK = "macosx"
+			case strings.HasPrefix(dt.ProductType, "iBridge"):
+				devType = "bridgeos"
+				devSDK = "bridgeos"
+			case strings.HasPrefix(dt.ProductType, "RealityDevice"):
+				devType = "visionos"
+				devSDK = "xros"
 			case strings.HasPrefix(dt.ProductType, "AppleDisplay"):
 				devType = "accessory"
 				devSDK = "iphoneos"
@@ -270,6 +280,46 @@ func mLBTypeToBoardConfig(bc string, mlbType string) string {
 	return mlbType + strings.ToUpper(strings.TrimPrefix(bc, strings.ToLower(mlbType)))
 }
 
+// deviceType returns the type (OS) of a device from its product type or SDK platform
+func deviceType(prod, sdk string) string {
+	switch {
+	case strings.HasPrefix(prod, "iPod"):
+		fallthrough
+	case strings.HasPrefix(prod, "iPad"):
+		fallthrough
+	case strings.HasPrefix(prod, "iPhone"):
+		return "ios"
+	case strings.HasPrefix(prod, "Watch"):
+		return "watchos"
+	case strings.HasPrefix(prod, "AudioAccessory"):
+		return "audioos"
+	case strings.HasPrefix(prod, "AppleTV") || sdk == "appletvos":
+		return "tvos"
+	case strings.HasPrefix(prod, "iBridge") || sdk == "bridgeos": // before macOS as T2s ship in Macs
+		return "bridgeos"
+	case strings.HasPrefix(prod, "Mac") || sdk == "macosx":
+		return "macos"
+	case strings.HasPrefix(prod, "RealityDevice") || sdk == "xros":
+		return "visionos"
+	case strings.HasPrefix(prod, "AppleDisplay"):
+		return "accessory"
+	}
+	return "unknown"
+}
+
+// UpdateTypes re-derives the type of the devices that have one (i.e. after a new device type is added)
+func (ds Devices) UpdateTypes() {
+	for prod, d := range ds {
+		if len(d.Type) == 0 {
+			continue
+		}
+		if typ := deviceType(prod, d.SDKPlatform); typ != "unknown" {
+			d.Type = typ
+			ds[prod] = d
+		}
+	}
+}
+
 func (i *Info) GetDevicesFromMap(dmap *types.DeviceMap, devs *Devices) error {
 	for bc, d := range *dmap {
 		if len(d.ProductType) > 0 {
@@ -282,25 +332,7 @@ func (i *Info) GetDevicesFromMap(dmap *types.DeviceMap, devs *Devices) error {
 						return fmt.Errorf("failed to parse int: %v", err)
 					}
 				}
-				devType := "unknown"
-				switch {
-				case strings.HasPrefix(d.ProductType, "iPod"):
-					fallthrough
-				case strings.HasPrefix(d.ProductType, "iPad"):
-					fallthrough
-				case strings.HasPrefix(d.ProductType, "iPhone"):
-					devType = "ios"
-				case strings.HasPrefix(d.ProductType, "Watch"):
-					devType = "watchos"
-				case strings.HasPrefix(d.ProductType, "AudioAccessory"):
-					devType = "audioos"
-				case strings.HasPrefix(d.ProductType, "AppleTV") || d.SDKPlatform == "appletvos":
-					devType = "tvos"
-				case strings.HasPrefix(d.ProductType, "Mac") || d.SDKPlatform == "macosx":
-					devType = "macos"
-				case strings.HasPrefix(d.ProductType, "AppleDisplay"):
-					devType = "accessory"
-				}
+				devType := deviceType(d.ProductType, d.SDKPlatform)
 				if d.ProductName != d.ProductDescription {
 					(*devs)[d.ProductType] = Device{
 						Name:        d.ProductName,
@@ -346,3 +378,27 @@ func (i *Info) GetDevicesFromMap(dmap *types.DeviceMap, devs *Devices) error {
 
 	return nil
 }
+
+// ResolveIdentifier returns the identifier of a device from its identifier (in any case),
+// board config (i.e. D73AP), marketing name (i.e. iPhone 14 Pro) or A-number
+func (ds Devices) ResolveIdentifier(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if _, ok := ds[name]; ok {
+		return name, nil
+	}
+	for prod := range ds {
+		if strings.EqualFold(prod, name) {
+			return prod, nil
+		}
+	}
+	if prod, err := ds.GetProductForModel(name); err == nil {
+		return prod, nil
+	}
+	if prod, _, err := ds.GetDeviceForName(name); err == nil {
+		return prod, nil
+	}
+	if infos, err := ds.LookupModel(name); err == nil && len(infos) == 1 {
+		return infos[0].Product, nil
+	}
+	return "", fmt.Errorf("device %s not found", name)
+}
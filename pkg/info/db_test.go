@@ -0,0 +1,62 @@
+package info
+
+import (
+	"testing"
+)
+
+func TestVisionOSDevices(t *testing.T) {
+	db, err := GetIpswDB()
+	if err != nil {
+		t.Fatalf("GetIpswDB() error = %v", err)
+	}
+	tests := []struct {
+		name    string
+		prod    string
+		model   string
+		wantTyp string
+		wantSDK string
+	}{
+		{
+			name:    "Apple Vision Pro",
+			prod:    "RealityDevice14,1",
+			model:   "N301AP",
+			wantTyp: "visionos",
+			wantSDK: "xros",
+		},
+		{
+			name:    "visionOS VM",
+			prod:    "RealityDevice99,1",
+			model:   "Vprod201AP",
+			wantTyp: "visionos",
+			wantSDK: "xros",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dev, err := db.LookupDevice(tt.prod)
+			if err != nil {
+				t.Fatalf("LookupDevice() error = %v", err)
+			}
+			if dev.Type != tt.wantTyp {
+				t.Errorf("LookupDevice() Type = %v, want %v", dev.Type, tt.wantTyp)
+			}
+			if dev.SDKPlatform != tt.wantSDK {
+				t.Errorf("LookupDevice() SDKPlatform = %v, want %v", dev.SDKPlatform, tt.wantSDK)
+			}
+			prod, err := db.GetProductForModel(tt.model)
+			if err != nil {
+				t.Fatalf("GetProductForModel() error = %v", err)
+			}
+			if prod != tt.prod {
+				t.Errorf("GetProductForModel() = %v, want %v", prod, tt.prod)
+			}
+			devs, err := db.GetDevicesForType(tt.wantTyp)
+			if err != nil {
+				t.Fatalf("GetDevicesForType() error = %v", err)
+			}
+			if _, ok := (*devs)[tt.prod]; !ok {
+				t.Errorf("GetDevicesForType(%s) is missing %s", tt.wantTyp, tt.prod)
+			}
+		})
+	}
+}
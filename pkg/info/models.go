@@ -0,0 +1,220 @@
+package info
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Model is a model number to identifier mapping (i.e. A2848 => iPhone15,2)
+type Model struct {
+	Generation   string   `json:"generation,omitempty"`
+	Identifier   string   `json:"identifier,omitempty"`
+	InternalName string   `json:"internal_name,omitempty"`
+	ANumbers     []string `json:"a_numbers,omitempty"`
+	FCCIDs       []string `json:"fcc_ids,omitempty"`
+	ModelNumbers []string `json:"model_numbers,omitempty"`
+}
+
+// ModelInfo is the result of a model number/identifier lookup
+type ModelInfo struct {
+	Product     string `json:"product,omitempty"`
+	Device      Device `json:"device,omitempty"`
+	ModelNumber string `json:"model_number,omitempty"`
+	Region      string `json:"region,omitempty"`
+	RegionCode  string `json:"region_code,omitempty"`
+}
+
+var aNumberRegex = regexp.MustCompile(`^A\d{4}$`)
+var modelNumberRegex = regexp.MustCompile(`^([A-Z0-9]{4,5})([A-Z]{1,2}/[A-Z])?$`)
+
+// regions maps the region suffix of an Apple model/part number to the market it is sold in
+var regions = map[string]string{
+	"AB/A": "Saudi Arabia, UAE, Qatar, Jordan, Egypt",
+	"AE/A": "UAE",
+	"B/A":  "United Kingdom, Ireland",
+	"BG/A": "Bulgaria",
+	"BR/A": "Brazil",
+	"BZ/A": "Brazil",
+	"C/A":  "Canada",
+	"CH/A": "China",
+	"CI/A": "Paraguay",
+	"CL/A": "Canada",
+	"CM/A": "Hungary, Croatia",
+	"CN/A": "Slovakia",
+	"CR/A": "Croatia, Slovenia",
+	"CS/A": "Slovakia",
+	"CZ/A": "Czech Republic",
+	"D/A":  "Germany, Austria",
+	"DN/A": "Austria, Germany, Netherlands",
+	"E/A":  "Mexico",
+	"EE/A": "Estonia",
+	"EL/A": "Estonia, Latvia, Lithuania",
+	"ER/A": "Ireland",
+	"ET/A": "Estonia",
+	"F/A":  "France, Luxembourg",
+	"FB/A": "France, Luxembourg",
+	"FD/A": "Austria, Liechtenstein, Switzerland",
+	"FS/A": "Finland",
+	"GH/A": "Hungary",
+	"GP/A": "Portugal",
+	"GR/A": "Greece",
+	"HB/A": "Israel",
+	"HC/A": "Hungary, Croatia",
+	"HN/A": "India",
+	"IP/A": "Italy",
+	"J/A":  "Japan",
+	"KH/A": "South Korea",
+	"KN/A": "Denmark, Norway",
+	"KS/A": "Finland, Sweden",
+	"LA/A": "Colombia, Ecuador, El Salvador, Guatemala, Honduras, Peru",
+	"LE/A": "Argentina",
+	"LL/A": "United States",
+	"LT/A": "Lithuania",
+	"LV/A": "Latvia",
+	"LZ/A": "Chile, Paraguay, Uruguay",
+	"MG/A": "Hungary",
+	"MY/A": "Malaysia",
+	"NF/A": "Belgium, France, Luxembourg",
+	"PA/A": "Indonesia",
+	"PH/A": "Philippines",
+	"PL/A": "Poland",
+	"PM/A": "Poland",
+	"PO/A": "Portugal",
+	"PP/A": "Philippines",
+	"PY/A": "Spain",
+	"QL/A": "Italy, Portugal, Spain",
+	"QN/A": "Denmark, Iceland, Norway, Sweden",
+	"RK/A": "Kazakhstan",
+	"RM/A": "Russia, Kazakhstan",
+	"RO/A": "Romania",
+	"RP/A": "Russia",
+	"RR/A": "Russia",
+	"RS/A": "Russia",
+	"RU/A": "Russia",
+	"SL/A": "Slovakia",
+	"SO/A": "South Africa",
+	"T/A":  "Italy",
+	"TA/A": "Taiwan",
+	"TH/A": "Thailand",
+	"TU/A": "Turkey",
+	"TY/A": "Italy",
+	"UA/A": "Ukraine",
+	"VC/A": "Canada",
+	"VN/A": "Vietnam",
+	"X/A":  "Australia, New Zealand",
+	"Y/A":  "Spain",
+	"ZA/A": "Singapore",
+	"ZD/A": "Belgium, France, Germany, Luxembourg, Monaco, Netherlands, Austria, Switzerland",
+	"ZG/A": "Denmark",
+	"ZP/A": "Hong Kong, Macau",
+	"ZQ/A": "Jamaica",
+	"ZS/A": "Singapore",
+	"ZY/A": "Italy",
+}
+
+// GetRegion returns the market for a model number region suffix (i.e. LL/A => United States)
+func GetRegion(code string) (string, error) {
+	if r, ok := regions[strings.ToUpper(code)]; ok {
+		return r, nil
+	}
+	return "", fmt.Errorf("unknown region code %s", code)
+}
+
+// ParseModelNumber splits an order number into its part number and region suffix (i.e. MU663LL/A => MU663, LL/A)
+func ParseModelNumber(mn string) (part, region string, err error) {
+	m := modelNumberRegex.FindStringSubmatch(strings.ToUpper(strings.TrimSpace(mn)))
+	if m == nil {
+		return "", "", fmt.Errorf("invalid model number %s", mn)
+	}
+	return m[1], m[2], nil
+}
+
+// AddModels merges the model number mappings into the device records
+func (ds Devices) AddModels(models []Model) {
+	for _, m := range models {
+		dev, ok := ds[m.Identifier]
+		if !ok {
+			continue
+		}
+		dev.ANumbers = mergeStrings(dev.ANumbers, m.ANumbers)
+		dev.ModelNumbers = mergeStrings(dev.ModelNumbers, m.ModelNumbers)
+		ds[m.Identifier] = dev
+	}
+}
+
+func mergeStrings(a, b []string) []string {
+	seen := make(map[string]bool)
+	var out []string
+	for _, s := range append(a, b...) {
+		if len(s) > 0 && !seen[s] {
+			seen[s] = true
+			out = append(out, s)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// LookupModel returns the device for an A-number (A2848), model number (MU663LL/A),
+// internal name (D84AP) or identifier (iPhone15,2)
+func (ds Devices) LookupModel(query string) ([]ModelInfo, error) {
+	var infos []ModelInfo
+
+	query = strings.TrimSpace(query)
+
+	if dev, ok := ds[query]; ok {
+		return []ModelInfo{{Product: query, Device: dev}}, nil
+	}
+	for prod, dev := range ds {
+		if strings.EqualFold(prod, query) {
+			return []ModelInfo{{Product: prod, Device: dev}}, nil
+		}
+	}
+
+	upper := strings.ToUpper(query)
+
+	if aNumberRegex.MatchString(upper) {
+		for prod, dev := range ds {
+			for _, a := range dev.ANumbers {
+				if a == upper {
+					infos = append(infos, ModelInfo{Product: prod, Device: dev})
+					break
+				}
+			}
+		}
+	} else if part, code, err := ParseModelNumber(upper); err == nil {
+		for prod, dev := range ds {
+			for _, mn := range dev.ModelNumbers {
+				if mn == part {
+					mi := ModelInfo{Product: prod, Device: dev, ModelNumber: part, RegionCode: code}
+					if len(code) > 0 {
+						mi.Region, _ = GetRegion(code)
+					}
+					infos = append(infos, mi)
+					break
+				}
+			}
+		}
+	}
+
+	if len(infos) == 0 {
+		for _, board := range []string{upper, upper + "AP"} {
+			if prod, err := ds.GetProductForModel(board); err == nil {
+				infos = append(infos, ModelInfo{Product: prod, Device: ds[prod]})
+				break
+			}
+		}
+	}
+
+	if len(infos) == 0 {
+		return nil, fmt.Errorf("no device found for %s", query)
+	}
+
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].Product < infos[j].Product
+	})
+
+	return infos, nil
+}
@@ -38,6 +38,7 @@ type assetReceipt struct {
 type Asset struct {
 	ActualMinimumSystemPartition          int                `json:"ActualMinimumSystemPartition" plist:"ActualMinimumSystemPartition,omitempty"`
 	AutoUpdate                            bool               `json:"AutoUpdate" plist:"AutoUpdate,omitempty"`
+	ArchiveDecryptionKey                  string             `json:"ArchiveDecryptionKey,omitempty" plist:"ArchiveDecryptionKey,omitempty"`
 	AssetType                             string             `json:"AssetType" plist:"AssetType,omitempty"`
 	BridgeVersionInfo                     bridgeVersionInfo  `json:"BridgeVersionInfo,omitempty" plist:"BridgeVersionInfo,omitempty"`
 	Build                                 string             `json:"Build" plist:"Build,omitempty"`
@@ -0,0 +1,181 @@
+package plist
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/blacktop/go-plist"
+)
+
+// Property list serialization formats supported by Convert
+const (
+	FormatXML    = "xml"
+	FormatBinary = "binary"
+	FormatJSON   = "json"
+)
+
+// JSON object keys of the plist types that JSON has no type for (i.e. {"$data": "aXBzdw=="})
+const (
+	jsonDataKey = "$data" // base64 encoded data
+	jsonDateKey = "$date" // RFC3339 date
+)
+
+// Convert converts a binary/XML/OpenStep plist or JSON document into the given format (xml, binary or json)
+//
+// NOTE: in JSON, data is written as {"$data": "<base64>"}, dates as {"$date": "<RFC3339>"} and reals always
+// with a decimal point or exponent (integers never have one) so that converting back restores the types;
+// UIDs become integers
+func Convert(data []byte, format string) ([]byte, error) {
+	var v any
+	var err error
+
+	if isJSON(data) {
+		v, err = decodeJSON(data)
+	} else {
+		_, err = plist.Unmarshal(data, &v)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode input: %v", err)
+	}
+
+	switch strings.ToLower(format) {
+	case FormatXML:
+		return plist.MarshalIndent(v, plist.XMLFormat, "\t")
+	case FormatBinary:
+		return plist.Marshal(v, plist.BinaryFormat)
+	case FormatJSON:
+		return json.MarshalIndent(toJSON(v), "", "  ")
+	default:
+		return nil, fmt.Errorf("unsupported plist format %s (supported: xml, binary, json)", format)
+	}
+}
+
+// GetFormat returns the format of the plist/JSON data
+func GetFormat(data []byte) (string, error) {
+	if isJSON(data) {
+		return FormatJSON, nil
+	}
+	var v any
+	f, err := plist.Unmarshal(data, &v)
+	if err != nil {
+		return "", err
+	}
+	switch f {
+	case plist.BinaryFormat:
+		return FormatBinary, nil
+	case plist.XMLFormat:
+		return FormatXML, nil
+	case plist.OpenStepFormat:
+		return "openstep", nil
+	case plist.GNUStepFormat:
+		return "gnustep", nil
+	}
+	return "", fmt.Errorf("unknown plist format %d", f)
+}
+
+func isJSON(data []byte) bool {
+	data = bytes.TrimSpace(data)
+	return len(data) > 0 && (data[0] == '{' || data[0] == '[') && json.Valid(data)
+}
+
+func decodeJSON(data []byte) (any, error) {
+	var v any
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	return fromJSON(v), nil
+}
+
+// jsonReal is a plist real that is written with a decimal point or exponent (so that i.e. 1.0 isn't read back as an integer)
+type jsonReal struct {
+	value   float64
+	bitSize int
+}
+
+func (r jsonReal) MarshalJSON() ([]byte, error) {
+	if math.IsInf(r.value, 0) || math.IsNaN(r.value) {
+		return nil, fmt.Errorf("unsupported real value %v", r.value)
+	}
+	s := strconv.FormatFloat(r.value, 'g', -1, r.bitSize)
+	if !strings.ContainsAny(s, ".eE") {
+		s += ".0"
+	}
+	return []byte(s), nil
+}
+
+// toJSON tags the plist types that JSON has no type for (see fromJSON)
+func toJSON(v any) any {
+	switch t := v.(type) {
+	case map[string]any:
+		m := make(map[string]any, len(t))
+		for k, val := range t {
+			m[k] = toJSON(val)
+		}
+		return m
+	case []any:
+		a := make([]any, len(t))
+		for i, val := range t {
+			a[i] = toJSON(val)
+		}
+		return a
+	case []byte:
+		return map[string]string{jsonDataKey: base64.StdEncoding.EncodeToString(t)}
+	case time.Time:
+		return map[string]string{jsonDateKey: t.UTC().Format(time.RFC3339Nano)}
+	case float64:
+		return jsonReal{value: t, bitSize: 64}
+	case float32:
+		return jsonReal{value: float64(t), bitSize: 32}
+	}
+	return v
+}
+
+// fromJSON restores the plist types tagged by toJSON
+func fromJSON(v any) any {
+	switch t := v.(type) {
+	case map[string]any:
+		if len(t) == 1 {
+			if s, ok := t[jsonDataKey].(string); ok {
+				if d, err := base64.StdEncoding.DecodeString(s); err == nil {
+					return d
+				}
+			}
+			if s, ok := t[jsonDateKey].(string); ok {
+				if d, err := time.Parse(time.RFC3339Nano, s); err == nil {
+					return d
+				}
+			}
+		}
+		for k, val := range t {
+			t[k] = fromJSON(val)
+		}
+		return t
+	case []any:
+		for i, val := range t {
+			t[i] = fromJSON(val)
+		}
+		return t
+	case json.Number:
+		if !strings.ContainsAny(t.String(), ".eE") {
+			if i, err := t.Int64(); err == nil {
+				return i
+			}
+			if u, err := strconv.ParseUint(t.String(), 10, 64); err == nil {
+				return u
+			}
+		}
+		if f, err := t.Float64(); err == nil {
+			return f
+		}
+		return t.String()
+	}
+	return v
+}
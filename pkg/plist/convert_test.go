@@ -0,0 +1,73 @@
+package plist
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/blacktop/go-plist"
+)
+
+const testPlist = `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>Data</key>
+	<data>aXBzdw==</data>
+	<key>Date</key>
+	<date>2023-09-18T17:00:00Z</date>
+	<key>DateString</key>
+	<string>2023-09-18T17:00:00Z</string>
+	<key>Integer</key>
+	<integer>3</integer>
+	<key>Real</key>
+	<real>1</real>
+	<key>Reals</key>
+	<array>
+		<real>2.5</real>
+		<real>1e+30</real>
+	</array>
+	<key>Tagged</key>
+	<dict>
+		<key>$data</key>
+		<integer>1</integer>
+	</dict>
+</dict>
+</plist>
+`
+
+func TestConvertRoundTrip(t *testing.T) {
+	var want any
+	if _, err := plist.Unmarshal([]byte(testPlist), &want); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, format := range []string{FormatXML, FormatBinary} {
+		t.Run(format, func(t *testing.T) {
+			pl, err := Convert([]byte(testPlist), format)
+			if err != nil {
+				t.Fatal(err)
+			}
+			js, err := Convert(pl, FormatJSON)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, tag := range []string{`"$data": "aXBzdw=="`, `"$date": "2023-09-18T17:00:00Z"`, `"Real": 1.0`, `"Integer": 3`} {
+				if !bytes.Contains(js, []byte(tag)) {
+					t.Errorf("Convert(%s) to JSON = %s, want %s", format, js, tag)
+				}
+			}
+			back, err := Convert(js, format)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got any
+			if _, err := plist.Unmarshal(back, &got); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("round trip through JSON = %#v, want %#v", got, want)
+			}
+		})
+	}
+}
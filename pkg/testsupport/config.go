@@ -0,0 +1,24 @@
+package testsupport
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/blacktop/ipsw/internal/download"
+)
+
+// TempConfig persists the request budgets, the HTTP cache and the download history in a temporary folder for the
+// duration of the test (so that tests never use or pollute the user's ~/.ipsw) and returns it
+func TempConfig(t testing.TB) string {
+	t.Helper()
+	dir := t.TempDir()
+	download.SetBudgetFile(filepath.Join(dir, "budgets.json"))
+	download.SetHTTPCacheDir(filepath.Join(dir, "http-cache"))
+	download.SetHistoryFile(filepath.Join(dir, "history.json"))
+	t.Cleanup(func() {
+		download.SetBudgetFile("")
+		download.SetHTTPCacheDir("")
+		download.SetHistoryFile("")
+	})
+	return dir
+}
@@ -0,0 +1,165 @@
+package testsupport
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+const (
+	sessionID  = "fake-session-id"
+	scnt       = "fake-scnt"
+	widgetKey  = "fake-widget-key"
+	adcCookie  = "ADCDownloadAuth"
+	trustToken = "X-Apple-TwoSV-Trust-Token"
+)
+
+func (s *Server) authorized(r *http.Request) bool {
+	c, err := r.Cookie(sessionCookie)
+	return err == nil && c.Value == sessionID
+}
+
+func (s *Server) startSession(w http.ResponseWriter) {
+	http.SetCookie(w, &http.Cookie{Name: sessionCookie, Value: sessionID, Path: "/", Domain: "apple.com"})
+	s.mu.Lock()
+	s.signedIn = true
+	s.mu.Unlock()
+}
+
+func (s *Server) serviceKey(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, map[string]string{
+		"authServiceUrl": "https://idmsa.apple.com/appleauth",
+		"authServiceKey": widgetKey,
+	})
+}
+
+func (s *Server) olympusSession(w http.ResponseWriter, r *http.Request) {
+	if !s.authorized(r) {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	writeJSON(w, map[string]any{"user": map[string]string{"emailAddress": s.Username}})
+}
+
+func (s *Server) signIn(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodGet { // hashcash headers
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	var req struct {
+		AccountName string   `json:"accountName"`
+		Password    string   `json:"password"`
+		TrustTokens []string `json:"trust_tokens"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+	if req.AccountName != s.Username || req.Password != s.Password {
+		w.WriteHeader(http.StatusUnauthorized)
+		writeJSON(w, map[string]any{"serviceErrors": []map[string]string{{"code": "-20101", "message": "Your Apple ID or password was entered incorrectly."}}})
+		return
+	}
+	for _, token := range req.TrustTokens {
+		if len(s.TrustToken) > 0 && token == s.TrustToken {
+			s.startSession(w)
+			writeJSON(w, map[string]string{"authType": "hsa2"})
+			return
+		}
+	}
+	// two-factor authentication required
+	w.Header().Set("X-Apple-Id-Session-Id", sessionID)
+	w.Header().Set("Scnt", scnt)
+	w.WriteHeader(http.StatusConflict)
+	writeJSON(w, map[string]string{"authType": "hsa2"})
+}
+
+func (s *Server) authOptions(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, map[string]any{
+		"trustedDeviceCount": 1,
+		"trustedPhoneNumbers": []map[string]any{
+			{"id": 1, "obfuscatedNumber": "(•••) •••-••00", "numberWithDialCode": "+1 (•••) •••-••00", "pushMode": "sms"},
+		},
+		"securityCode": map[string]int{"length": len(s.Code)},
+	})
+}
+
+func (s *Server) verify(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodPut { // request an SMS code
+		writeJSON(w, map[string]any{"securityCode": map[string]int{"length": len(s.Code)}})
+		return
+	}
+	var req struct {
+		SecurityCode struct {
+			Code string `json:"code"`
+		} `json:"securityCode"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.SecurityCode.Code != s.Code {
+		w.WriteHeader(http.StatusBadRequest)
+		writeJSON(w, map[string]any{
+			"service_errors": []map[string]string{{"code": "-21669", "message": "Incorrect verification code."}},
+			"hasError":       true,
+		})
+		return
+	}
+	s.startSession(w)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) trust(w http.ResponseWriter, r *http.Request) {
+	if !s.authorized(r) {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	w.Header().Set(trustToken, s.TrustToken)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) listDownloads(w http.ResponseWriter, r *http.Request) {
+	if !s.authorized(r) {
+		w.WriteHeader(http.StatusUnauthorized)
+		writeJSON(w, map[string]any{"resultCode": 1100, "resultString": "Your session has expired. Please log in."})
+		return
+	}
+	writeJSON(w, map[string]any{"resultCode": 0, "downloads": s.MoreDownloads})
+}
+
+// devDownloads renders the developer.apple.com/download page in the layout the client scrapes
+func (s *Server) devDownloads(w http.ResponseWriter, r *http.Request) {
+	if !s.authorized(r) {
+		http.Redirect(w, r, "/account/", http.StatusFound)
+		return
+	}
+	var versions []string
+	for version := range s.DevDownloads {
+		versions = append(versions, version)
+	}
+	sort.Strings(versions)
+
+	var sb strings.Builder
+	sb.WriteString(`<html><body><div id="main"><section class="section section-downloads"><div>`)
+	for _, version := range versions {
+		sb.WriteString(`<div class="row"><div class="column"><div class="release">`)
+		fmt.Fprintf(&sb, `<h3>%s</h3><div class="downloads"><ul class="ios-list">`, html.EscapeString(version))
+		for _, dl := range s.DevDownloads[version] {
+			fmt.Fprintf(&sb, `<li><a href="%s">%s</a><p>%s</p></li>`, html.EscapeString(dl.URL), html.EscapeString(dl.Title), html.EscapeString(dl.Build))
+		}
+		sb.WriteString(`</ul></div></div></div></div>`)
+	}
+	sb.WriteString(`</div></section></div></body></html>`)
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	fmt.Fprint(w, sb.String())
+}
+
+func (s *Server) adcDownloadAuth(w http.ResponseWriter, r *http.Request) {
+	if !s.authorized(r) {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	http.SetCookie(w, &http.Cookie{Name: adcCookie, Value: "fake-adc-download-auth", Path: "/", Domain: "apple.com"})
+	w.WriteHeader(http.StatusOK)
+}
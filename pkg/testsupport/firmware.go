@@ -0,0 +1,44 @@
+package testsupport
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"fmt"
+	"testing"
+
+	"github.com/blacktop/ipsw/internal/download"
+)
+
+// FirmwareURL is the URL of the IPSW served by NewFirmwareServer
+const FirmwareURL = "https://updates.cdn-apple.com/2023/iPhone15,2_17.0_21A329_Restore.ipsw"
+
+// FirmwareData returns the contents of the IPSW served by NewFirmwareServer
+func FirmwareData() []byte {
+	return bytes.Repeat([]byte("ipsw"), 1024)
+}
+
+// NewFirmwareServer starts a Server whose ipsw.me fixtures are an iPhone 14 Pro (iPhone15,2) with the
+// signed iOS 17.0 (21A329) IPSW at FirmwareURL and an unsigned iOS 16.0 (20A362) build without a file
+//
+// The request budgets, HTTP cache and download history are kept in a temporary folder (see TempConfig)
+// and the Server is closed when the test ends.
+func NewFirmwareServer(t testing.TB) *Server {
+	t.Helper()
+	TempConfig(t)
+	s := NewServer()
+	t.Cleanup(s.Close)
+
+	data := FirmwareData()
+	if err := s.AddFile(FirmwareURL, data); err != nil {
+		t.Fatal(err)
+	}
+	s.Devices = []download.Device{{
+		Name:       "iPhone 14 Pro",
+		Identifier: "iPhone15,2",
+		Firmwares: []download.IPSW{
+			{Identifier: "iPhone15,2", Version: "17.0", BuildID: "21A329", SHA1: fmt.Sprintf("%x", sha1.Sum(data)), URL: FirmwareURL, Signed: true},
+			{Identifier: "iPhone15,2", Version: "16.0", BuildID: "20A362"},
+		},
+	}}
+	return s
+}
@@ -0,0 +1,402 @@
+// Package testsupport is an in-process stand-in for the remote providers (ipsw.me, AppleDB,
+// pallas and the Apple developer portal) so integration tests can run without network access
+package testsupport
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/blacktop/ipsw/internal/download"
+	"github.com/blacktop/ipsw/pkg/ota/types"
+)
+
+// Hosts are the provider hosts that are redirected to the Server while it is running
+var Hosts = []string{
+	"api.ipsw.me",                  // ipsw.me
+	"api.github.com",               // AppleDB (via the github contents API)
+	"gdmf.apple.com",               // pallas
+	"mesu.apple.com",               // public OTA/IPSW XMLs
+	"developer.apple.com",          // dev portal
+	"download.developer.apple.com", // dev portal downloads
+	"developerservices2.apple.com", // dev portal ADC download auth
+	"idmsa.apple.com",              // Apple ID sign in/2FA
+	"appstoreconnect.apple.com",    // olympus session
+}
+
+// Server is a fake of the provider APIs backed by the fixtures in its fields
+//
+// Set the fixtures before making requests; requests for the Hosts (and the hosts of any
+// files added with AddFile) are sent to the Server by its Transport until it is closed.
+//
+// NOTE: NewServer installs the Transport for all download sources (see download.SetTransport)
+// so tests that use a Server must not run in parallel
+type Server struct {
+	*httptest.Server
+
+	// ipsw.me
+	Devices []download.Device
+	// AppleDB
+	OSFiles []download.AppleDbOsFile
+	// pallas
+	AssetSets download.AssetSets
+	OTAs      []types.Asset
+	// dev portal
+	Username      string
+	Password      string
+	Code          string // 2FA verification code
+	TrustToken    string // skips 2FA when sent with the sign in
+	MoreDownloads []download.MoreDownload
+	DevDownloads  map[string][]download.DevDownload // by OS version heading (i.e. "iOS 17")
+
+	mu       sync.Mutex
+	files    map[string]file
+	failures map[string][]*failure // by file path
+	hosts    map[string]bool       // redirected to the Server
+	signedIn bool
+}
+
+type file struct {
+	data    []byte
+	modTime time.Time
+}
+
+// failure fails the next n GET requests of a file with status (only the ranged ones starting at offset if it is not negative)
+type failure struct {
+	offset int64
+	status int
+	n      int
+}
+
+const sessionCookie = "myacinfo"
+
+// NewServer starts a Server and sends the requests of all download sources through its Transport (call Close when done)
+func NewServer() *Server {
+	s := &Server{
+		Username:     "user@example.com",
+		Password:     "password",
+		Code:         "123456",
+		TrustToken:   "trust-token",
+		DevDownloads: make(map[string][]download.DevDownload),
+		files:        make(map[string]file),
+		failures:     make(map[string][]*failure),
+		hosts:        make(map[string]bool),
+	}
+	mux := http.NewServeMux()
+	// ipsw.me
+	mux.HandleFunc("/v4/", s.ipswMe)
+	// AppleDB
+	mux.HandleFunc("/repos/littlebyteorg/appledb/contents/", s.appleDBContents)
+	mux.HandleFunc("/appledb/", s.appleDBFile)
+	// pallas
+	mux.HandleFunc("/v2/pmv", s.assetSets)
+	mux.HandleFunc("/v2/assets", s.pallas)
+	mux.HandleFunc("/assets/", s.mesu)
+	// dev portal
+	mux.HandleFunc("/olympus/v1/app/config", s.serviceKey)
+	mux.HandleFunc("/olympus/v1/session", s.olympusSession)
+	mux.HandleFunc("/appleauth/auth/signin", s.signIn)
+	mux.HandleFunc("/appleauth/auth", s.authOptions)
+	mux.HandleFunc("/appleauth/auth/verify/", s.verify)
+	mux.HandleFunc("/appleauth/auth/2sv/trust", s.trust)
+	mux.HandleFunc("/services-account/QH65B2/downloadws/listDownloads.action", s.listDownloads)
+	mux.HandleFunc("/download/", s.devDownloads)
+	mux.HandleFunc("/services/download", s.adcDownloadAuth)
+	// files
+	mux.HandleFunc("/", s.file)
+	s.Server = httptest.NewServer(mux)
+
+	for _, host := range Hosts {
+		s.redirect(host)
+	}
+	download.SetTransport(s.Transport())
+	return s
+}
+
+// Close removes the Transport from the download sources and shuts down the Server
+func (s *Server) Close() {
+	download.SetTransport(nil)
+	s.Server.Close()
+}
+
+func (s *Server) redirect(host string) {
+	s.mu.Lock()
+	s.hosts[host] = true
+	s.mu.Unlock()
+}
+
+// Transport returns a transport that sends the requests for the Hosts (and the hosts of the files
+// added with AddFile) to the Server and all other requests to their hosts
+//
+// Pass it to the clients under test with download.WithTransport.
+func (s *Server) Transport() http.RoundTripper {
+	return redirectTransport{s}
+}
+
+type redirectTransport struct {
+	s *Server
+}
+
+func (rt redirectTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	rt.s.mu.Lock()
+	redirected := rt.s.hosts[req.URL.Hostname()]
+	rt.s.mu.Unlock()
+	if redirected {
+		u, err := url.Parse(rt.s.URL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse server URL '%s': %v", rt.s.URL, err)
+		}
+		r := req.Clone(req.Context())
+		r.URL.Scheme = u.Scheme
+		r.URL.Host = u.Host
+		r.Host = u.Host
+		r.Header.Set("X-Forwarded-Host", req.URL.Host)
+		req = r
+	}
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+// AddFile serves data at rawURL (i.e. an IPSW URL in the Devices fixtures) and redirects its host
+// to the Server; files support HEAD and range requests so downloads can be resumed
+func (s *Server) AddFile(rawURL string, data []byte) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("failed to parse file URL '%s': %v", rawURL, err)
+	}
+	s.mu.Lock()
+	s.files[u.Path] = file{data: data, modTime: time.Now().UTC().Truncate(time.Second)}
+	s.mu.Unlock()
+	if !slices.Contains(Hosts, u.Hostname()) {
+		s.redirect(u.Hostname())
+	}
+	return nil
+}
+
+// FailFile makes the next n GET requests for the file at rawURL respond with status (i.e. 503 to test retries)
+func (s *Server) FailFile(rawURL string, status, n int) error {
+	return s.FailRange(rawURL, -1, status, n)
+}
+
+// FailRange makes the next n ranged GET requests for the file at rawURL that start at offset respond with
+// status (i.e. to fail one chunk of a multi-connection download)
+func (s *Server) FailRange(rawURL string, offset int64, status, n int) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("failed to parse file URL '%s': %v", rawURL, err)
+	}
+	s.mu.Lock()
+	s.failures[u.Path] = append(s.failures[u.Path], &failure{offset: offset, status: status, n: n})
+	s.mu.Unlock()
+	return nil
+}
+
+// failure returns the status a file request should fail with (0 if it should be served)
+func (s *Server) failure(r *http.Request) int {
+	if r.Method != http.MethodGet {
+		return 0
+	}
+	offset := int64(-1)
+	if v, ok := strings.CutPrefix(r.Header.Get("Range"), "bytes="); ok {
+		start, _, _ := strings.Cut(v, "-")
+		if n, err := strconv.ParseInt(start, 10, 64); err == nil {
+			offset = n
+		}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, f := range s.failures[r.URL.Path] {
+		if f.n > 0 && (f.offset < 0 || f.offset == offset) {
+			f.n--
+			return f.status
+		}
+	}
+	return 0
+}
+
+// SignedIn returns true if a client has signed in to the dev portal
+func (s *Server) SignedIn() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.signedIn
+}
+
+func (s *Server) file(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	f, ok := s.files[r.URL.Path]
+	s.mu.Unlock()
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	if status := s.failure(r); status != 0 {
+		http.Error(w, http.StatusText(status), status)
+		return
+	}
+	w.Header().Set("ETag", fmt.Sprintf(`"%x"`, len(f.data)))
+	http.ServeContent(w, r, path.Base(r.URL.Path), f.modTime, bytes.NewReader(f.data))
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+/* ipsw.me */
+
+func (s *Server) ipswMe(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/v4/"), "/"), "/")
+	switch {
+	case len(parts) == 1 && parts[0] == "devices":
+		var devices []download.Device
+		for _, d := range s.Devices {
+			d.Firmwares = nil // the devices list does not include the firmwares
+			devices = append(devices, d)
+		}
+		writeJSON(w, devices)
+	case len(parts) == 2 && parts[0] == "device":
+		for _, d := range s.Devices {
+			if d.Identifier == parts[1] {
+				writeJSON(w, d)
+				return
+			}
+		}
+		http.NotFound(w, r)
+	case len(parts) == 2 && parts[0] == "ipsw": // by version
+		ipsws := []download.IPSW{}
+		for _, d := range s.Devices {
+			for _, fw := range d.Firmwares {
+				if fw.Version == parts[1] {
+					ipsws = append(ipsws, fw)
+				}
+			}
+		}
+		writeJSON(w, ipsws)
+	case len(parts) == 3 && parts[0] == "ipsw": // by device and build
+		for _, d := range s.Devices {
+			for _, fw := range d.Firmwares {
+				if fw.Identifier == parts[1] && fw.BuildID == parts[2] {
+					writeJSON(w, fw)
+					return
+				}
+			}
+		}
+		http.NotFound(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+/* AppleDB */
+
+// appleDBFolder returns the AppleDB osFiles folder of an OS file (i.e. "21x - 17.x")
+func appleDBFolder(of download.AppleDbOsFile) string {
+	build, major := of.Build, of.Version
+	if len(build) > 2 {
+		build = build[:2]
+	}
+	major, _, _ = strings.Cut(major, ".")
+	return fmt.Sprintf("%sx - %s.x", build, major)
+}
+
+func (s *Server) appleDBContents(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/repos/littlebyteorg/appledb/contents/"), "/"), "/")
+	if len(parts) < 2 || parts[0] != "osFiles" {
+		http.NotFound(w, r)
+		return
+	}
+	contents := []download.GithubContentsResponse{}
+	seen := make(map[string]bool)
+	for _, of := range s.OSFiles {
+		if of.OS != parts[1] {
+			continue
+		}
+		folder := appleDBFolder(of)
+		switch len(parts) {
+		case 2:
+			if !seen[folder] {
+				seen[folder] = true
+				contents = append(contents, download.GithubContentsResponse{Type: "dir", Name: folder, Path: path.Join(parts[0], parts[1], folder)})
+			}
+		case 3:
+			if folder == parts[2] {
+				name := of.Build + ".json"
+				contents = append(contents, download.GithubContentsResponse{
+					Type:        "file",
+					Name:        name,
+					Path:        path.Join(parts[0], parts[1], folder, name),
+					DownloadURL: s.URL + "/appledb/" + url.PathEscape(of.OS) + "/" + url.PathEscape(of.Build) + ".json",
+				})
+			}
+		}
+	}
+	writeJSON(w, contents)
+}
+
+func (s *Server) appleDBFile(w http.ResponseWriter, r *http.Request) {
+	osStr, build, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/appledb/"), "/")
+	for _, of := range s.OSFiles {
+		if of.OS == osStr && of.Build+".json" == build {
+			// AppleDB stores the release date as YYYY-MM-DD
+			type osFile download.AppleDbOsFile
+			writeJSON(w, struct {
+				osFile
+				Released string `json:"released"`
+			}{osFile(of), of.Released.Format("2006-01-02")})
+			return
+		}
+	}
+	http.NotFound(w, r)
+}
+
+/* pallas */
+
+func (s *Server) assetSets(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, s.AssetSets)
+}
+
+func (s *Server) pallas(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		AssetType   string `json:"AssetType"`
+		ProductType string `json:"ProductType"`
+	}
+	if r.Method != http.MethodPost || json.NewDecoder(r.Body).Decode(&req) != nil {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+	// pallas responds with the raw asset fields (not the types.Asset summary JSON)
+	type rawAsset types.Asset
+	var assets []rawAsset
+	for _, a := range s.OTAs {
+		if len(a.SupportedDevices) == 0 || slices.Contains(a.SupportedDevices, req.ProductType) {
+			assets = append(assets, rawAsset(a))
+		}
+	}
+	payload, err := json.Marshal(map[string]any{"Assets": assets})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	// pallas responds with a signed JWT (the signature is not verified by the client)
+	fmt.Fprintf(w, "%s.%s.%s",
+		base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none"}`)),
+		base64.RawURLEncoding.EncodeToString(payload),
+		base64.RawURLEncoding.EncodeToString([]byte("signature")),
+	)
+}
+
+func (s *Server) mesu(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/xml")
+	fmt.Fprint(w, `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0"><dict><key>Assets</key><array/></dict></plist>`)
+}
@@ -0,0 +1,185 @@
+package testsupport
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/blacktop/ipsw/internal/download"
+	"github.com/blacktop/ipsw/pkg/ota/types"
+	"github.com/hashicorp/go-version"
+)
+
+func TestIPSWMe(t *testing.T) {
+	NewFirmwareServer(t)
+	data := FirmwareData()
+
+	devices, err := download.GetAllDevices()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(devices) != 1 || devices[0].Identifier != "iPhone15,2" {
+		t.Fatalf("GetAllDevices() = %v", devices)
+	}
+	fw, err := download.GetIPSW("iPhone15,2", "21A329")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fw.URL != FirmwareURL {
+		t.Fatalf("GetIPSW() URL = %s, want %s", fw.URL, FirmwareURL)
+	}
+	if build, err := download.GetBuildID("17.0", "iPhone15,2"); err != nil || build != "21A329" {
+		t.Fatalf("GetBuildID() = %s, %v", build, err)
+	}
+
+	dest := filepath.Join(t.TempDir(), filepath.Base(fw.URL))
+	d := download.NewDownload("", false, false, false, false, false, false)
+	d.URL, d.Sha1, d.DestName = fw.URL, fw.SHA1, dest
+	if err := d.Do(); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := os.ReadFile(dest); !bytes.Equal(got, data) {
+		t.Fatal("downloaded IPSW does not match")
+	}
+}
+
+func TestAppleDB(t *testing.T) {
+	s := NewFirmwareServer(t)
+
+	var osfile download.AppleDbOsFile
+	if err := json.Unmarshal([]byte(`{
+		"osStr": "iOS", "version": "17.0", "build": "21A329", "released": "2023-09-18",
+		"sources": [{"type": "ipsw", "deviceMap": ["iPhone15,2"], "links": [{"url": "https://updates.cdn-apple.com/21A329.ipsw", "active": true}]}]
+	}`), &osfile); err != nil {
+		t.Fatal(err)
+	}
+	s.OSFiles = []download.AppleDbOsFile{osfile}
+
+	sources, err := download.AppleDBQuery(&download.ADBQuery{OSes: []string{"iOS"}, Version: "17.0", Device: "iPhone15,2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sources) != 1 || sources[0].Links[0].URL != "https://updates.cdn-apple.com/21A329.ipsw" {
+		t.Fatalf("AppleDBQuery() = %v", sources)
+	}
+}
+
+func TestPallas(t *testing.T) {
+	s := NewFirmwareServer(t)
+
+	s.AssetSets = download.AssetSets{
+		PublicAssetSets: map[string][]download.AssetSet{
+			"iOS": {{ProductVersion: "17.0", SupportedDevices: []string{"iPhone15,2"}}},
+		},
+	}
+	s.OTAs = []types.Asset{{
+		OSVersion:        "17.0",
+		Build:            "21A329",
+		SupportedDevices: []string{"iPhone15,2"},
+		BaseURL:          "https://updates.cdn-apple.com/",
+		RelativePath:     "ota.zip",
+	}}
+
+	as, err := download.GetAssetSets("", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := as.LatestVersion("ios"); got != "17.0" {
+		t.Fatalf("LatestVersion() = %s, want 17.0", got)
+	}
+	ota, err := download.NewOTA(as, download.OtaConf{
+		Platform: "ios",
+		Device:   "iPhone15,2",
+		Version:  version.Must(version.NewVersion("17.0")),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	otas, err := ota.GetPallasOTAs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(otas) == 0 || otas[0].Build != "21A329" {
+		t.Fatalf("GetPallasOTAs() = %v", otas)
+	}
+}
+
+func TestDevPortal(t *testing.T) {
+	s := NewFirmwareServer(t)
+
+	data := []byte("kernel debug kit")
+	if err := s.AddFile("https://download.developer.apple.com/macOS/Kernel_Debug_Kit_14.0_build_23A344.dmg", data); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal([]byte(`[{
+		"name": "Kernel Debug Kit 14.0 build 23A344",
+		"dateCreated": "09/26/23 17:00",
+		"files": [{"filename": "Kernel_Debug_Kit_14.0_build_23A344.dmg", "remotePath": "/macOS/Kernel_Debug_Kit_14.0_build_23A344.dmg"}]
+	}]`), &s.MoreDownloads); err != nil {
+		t.Fatal(err)
+	}
+	s.DevDownloads["iOS 17 beta"] = []download.DevDownload{{
+		Title: "iPhone 15 Pro",
+		Build: "21A5248v",
+		URL:   "https://developer.apple.com/services-account/download?path=/iOS/iPhone16,1_21A5248v.ipsw",
+	}}
+
+	dp := download.NewDevPortal(&download.DevConfig{
+		ConfigDir:      t.TempDir(),
+		KeyringBackend: "file",
+		VaultPassword:  "vault",
+		Headless:       true,
+		TrustToken:     s.TrustToken,
+	})
+	if err := dp.Init(); err != nil {
+		t.Fatal(err)
+	}
+	if err := dp.Login(s.Username, s.Password); err != nil {
+		t.Fatal(err)
+	}
+	if !s.SignedIn() {
+		t.Fatal("expected the dev portal sign in to succeed")
+	}
+
+	dat, err := dp.GetDownloadsAsJSON("more", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var dloads download.Downloads
+	if err := json.Unmarshal(dat, &dloads); err != nil {
+		t.Fatal(err)
+	}
+	if len(dloads.Downloads) != 1 {
+		t.Fatalf("expected 1 download, got %d", len(dloads.Downloads))
+	}
+
+	dat, err = dp.GetDownloadsAsJSON("os", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ipsws map[string][]download.DevDownload
+	if err := json.Unmarshal(dat, &ipsws); err != nil {
+		t.Fatal(err)
+	}
+	if dl := ipsws["iOS 17 beta"]; len(dl) != 1 || dl[0].Build != "21A5248v" {
+		t.Fatalf("expected the iOS 17 beta download, got %v", ipsws)
+	}
+
+	kdks, err := dp.ListKDKs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(kdks) != 1 || kdks[0].Build != "23A344" || kdks[0].Version != "14.0" || kdks[0].Date.Time().IsZero() {
+		t.Fatalf("ListKDKs() = %+v, want the 14.0 (23A344) KDK", kdks)
+	}
+
+	folder := t.TempDir()
+	if err := dp.Download("https://download.developer.apple.com/macOS/Kernel_Debug_Kit_14.0_build_23A344.dmg", folder); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := os.ReadFile(filepath.Join(folder, "Kernel_Debug_Kit_14.0_build_23A344.dmg")); !bytes.Equal(got, data) {
+		t.Fatal("downloaded KDK does not match")
+	}
+}
@@ -0,0 +1,175 @@
+package tss
+
+import (
+	"crypto/sha1"
+	"crypto/sha512"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/blacktop/go-plist"
+	info "github.com/blacktop/ipsw/pkg/plist"
+)
+
+// DefaultGenerator is the nonce generator most blob savers (and futurerestore) default to
+const DefaultGenerator = "0x1111111111111111"
+
+// BlobConfig is the config for saving SHSH blobs
+type BlobConfig struct {
+	Device    string
+	ECID      uint64
+	Generator string // boot-nonce generator (i.e. 0x1111111111111111)
+	ApNonce   []byte // takes precedence over Generator
+	Proxy     string
+	Insecure  bool
+}
+
+// SHSHBlob is a saved SHSH blob for a device's build
+type SHSHBlob struct {
+	Device    string
+	Board     string
+	Version   string
+	Build     string
+	ECID      uint64
+	ApNonce   []byte
+	Generator string
+	Data      []byte
+}
+
+// Name returns the tsschecker style filename for the blob
+func (b *SHSHBlob) Name() string {
+	return fmt.Sprintf("%d_%s_%s_%s-%s_%s.shsh2", b.ECID, b.Device, b.Board, b.Version, b.Build, hex.EncodeToString(b.ApNonce))
+}
+
+// ParseECID parses an ECID given in hex (with a 0x prefix) or decimal
+func ParseECID(ecid string) (uint64, error) {
+	var val uint64
+	var err error
+	if strings.HasPrefix(strings.ToLower(ecid), "0x") {
+		val, err = parseHexUint(ecid)
+	} else {
+		_, err = fmt.Sscanf(ecid, "%d", &val)
+	}
+	if err != nil || val == 0 {
+		return 0, fmt.Errorf("invalid ECID %#v", ecid)
+	}
+	return val, nil
+}
+
+// ApNonceFromGenerator returns the ApNonce iBoot derives from the boot-nonce generator
+func ApNonceFromGenerator(generator string, chipID uint64) ([]byte, error) {
+	gen, err := parseHexUint(generator)
+	if err != nil {
+		return nil, fmt.Errorf("invalid generator %#v: %v", generator, err)
+	}
+	buf := make([]byte, 8)
+	binary.LittleEndian.PutUint64(buf, gen)
+	// A12+ (and Apple Silicon) devices use a truncated SHA-384 of the generator
+	if chipID >= 0x8020 || (chipID >= 0x6000 && chipID < 0x7000) {
+		sum := sha512.Sum384(buf)
+		return sum[:32], nil
+	}
+	sum := sha1.Sum(buf)
+	return sum[:], nil
+}
+
+// GetSHSHBlob requests an SHSH blob from gs.apple.com for the device's build
+func GetSHSHBlob(bm *info.BuildManifest, conf *BlobConfig) (*SHSHBlob, error) {
+	if conf.ECID == 0 {
+		return nil, fmt.Errorf("an ECID is required to save SHSH blobs")
+	}
+
+	board, idx, err := getBuildIdentity(bm, conf.Device)
+	if err != nil {
+		return nil, err
+	}
+
+	chipID, err := parseHexUint(bm.BuildIdentities[idx].ApChipID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse chip id: %v", err)
+	}
+
+	blob := &SHSHBlob{
+		Device:  conf.Device,
+		Board:   board,
+		Version: bm.ProductVersion,
+		Build:   bm.ProductBuildVersion,
+		ECID:    conf.ECID,
+		ApNonce: conf.ApNonce,
+	}
+
+	if len(blob.ApNonce) == 0 {
+		blob.Generator = conf.Generator
+		if blob.Generator == "" {
+			blob.Generator = DefaultGenerator
+		}
+		blob.ApNonce, err = ApNonceFromGenerator(blob.Generator, chipID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	sepNonce, err := randomHex(20)
+	if err != nil {
+		return nil, err
+	}
+
+	tssReq, err := newTSSRequest(bm, idx, conf.ECID, blob.ApNonce, sepNonce)
+	if err != nil {
+		return nil, err
+	}
+
+	log.WithFields(log.Fields{
+		"device":    conf.Device,
+		"board":     board,
+		"build":     bm.ProductBuildVersion,
+		"generator": blob.Generator,
+		"apnonce":   hex.EncodeToString(blob.ApNonce),
+	}).Debug("Requesting SHSH blob")
+
+	tr, err := sendTSSRequest(tssReq, conf.Proxy, conf.Insecure)
+	if err != nil {
+		return nil, err
+	}
+	if tr.Status != StatusSuccess || tr.Message != "SUCCESS" {
+		return nil, fmt.Errorf("failed to get SHSH blob for %s (%s): status=%d message=%s", bm.ProductVersion, bm.ProductBuildVersion, tr.Status, tr.Message)
+	}
+
+	resp := make(map[string]any)
+	if err := plist.NewDecoder(strings.NewReader(tr.Plist)).Decode(&resp); err != nil {
+		return nil, fmt.Errorf("failed to decode TSS response REQUEST_STRING: %v", err)
+	}
+	if len(blob.Generator) > 0 {
+		resp["generator"] = blob.Generator // used by futurerestore to set the nonce
+	}
+
+	blob.Data, err = plist.MarshalIndent(resp, plist.XMLFormat, "\t")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal SHSH blob: %v", err)
+	}
+
+	return blob, nil
+}
+
+// SaveSHSHBlob requests an SHSH blob for the device's build and writes it to the output folder
+func SaveSHSHBlob(bm *info.BuildManifest, conf *BlobConfig, output string) (string, error) {
+	blob, err := GetSHSHBlob(bm, conf)
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(output, 0755); err != nil {
+		return "", fmt.Errorf("failed to create output directory %s: %v", output, err)
+	}
+
+	fname := filepath.Join(output, blob.Name())
+	if err := os.WriteFile(fname, blob.Data, 0644); err != nil {
+		return "", fmt.Errorf("failed to write SHSH blob %s: %v", fname, err)
+	}
+
+	return fname, nil
+}
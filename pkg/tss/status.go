@@ -0,0 +1,213 @@
+package tss
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/blacktop/ipsw/internal/download"
+	pinfo "github.com/blacktop/ipsw/pkg/info"
+	info "github.com/blacktop/ipsw/pkg/plist"
+	"github.com/google/uuid"
+)
+
+// TSS response statuses
+const (
+	StatusSuccess     = 0
+	StatusNotEligible = 94 // "This device isn't eligible for the requested build."
+)
+
+// SigningStatus is whether Apple's TSS server is still signing a build for a device
+type SigningStatus struct {
+	Device  string `json:"device,omitempty"`
+	Board   string `json:"board,omitempty"`
+	Version string `json:"version,omitempty"`
+	Build   string `json:"build,omitempty"`
+	Signed  bool   `json:"signed"`
+	Status  int    `json:"status"`
+	Message string `json:"message,omitempty"`
+}
+
+func parseHexUint(s string) (uint64, error) {
+	return strconv.ParseUint(strings.TrimPrefix(strings.ToLower(s), "0x"), 16, 64)
+}
+
+// getBuildIdentity returns the erase install build identity for the device
+func getBuildIdentity(bm *info.BuildManifest, identifier string) (string, int, error) {
+	var boards []string
+	if db, err := pinfo.GetIpswDB(); err == nil {
+		if dev, err := db.LookupDevice(identifier); err == nil {
+			for board := range dev.Boards {
+				boards = append(boards, strings.ToLower(board))
+			}
+		}
+	}
+	if len(boards) == 0 && len(bm.SupportedProductTypes) > 1 {
+		return "", 0, fmt.Errorf("unknown device %s: unable to pick a build identity", identifier)
+	}
+
+	for idx, bi := range bm.BuildIdentities {
+		if !strings.Contains(strings.ToLower(bi.Info.Variant), "erase") {
+			continue
+		}
+		if len(boards) == 0 {
+			return bi.Info.DeviceClass, idx, nil
+		}
+		for _, board := range boards {
+			if strings.EqualFold(bi.Info.DeviceClass, board) {
+				return bi.Info.DeviceClass, idx, nil
+			}
+		}
+	}
+
+	return "", 0, fmt.Errorf("no erase install build identity found for %s", identifier)
+}
+
+// GetBuildManifest returns the BuildManifest.plist from the remote IPSW for the device's build
+func GetBuildManifest(identifier, build, proxy string, insecure bool) (*info.BuildManifest, error) {
+	ipsw, err := download.GetIPSW(identifier, build)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get IPSW URL for %s %s: %v", identifier, build, err)
+	}
+
+	zr, err := download.NewRemoteZipReader(ipsw.URL, &download.RemoteConfig{
+		Proxy:    proxy,
+		Insecure: insecure,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse remote ipsw: %v", err)
+	}
+
+	inf, err := info.ParseZipFiles(zr.File)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse remote ipsw info: %v", err)
+	}
+	if inf.BuildManifest == nil {
+		return nil, fmt.Errorf("no BuildManifest.plist found in %s", ipsw.URL)
+	}
+
+	return inf.BuildManifest, nil
+}
+
+// newTSSRequest crafts an ApImg4Ticket TSS request for the build identity at index idx
+func newTSSRequest(bm *info.BuildManifest, idx int, ecid uint64, apNonce, sepNonce []byte) (map[string]any, error) {
+	bi := bm.BuildIdentities[idx]
+
+	boardID, err := parseHexUint(bi.ApBoardID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse board id: %v", err)
+	}
+	chipID, err := parseHexUint(bi.ApChipID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse chip id: %v", err)
+	}
+	secDomain, err := parseHexUint(bi.ApSecurityDomain)
+	if err != nil {
+		secDomain = 1
+	}
+
+	// NOTE: the request is a map so that the build identity's manifest entries can be added at the top level
+	tssReq := map[string]any{
+		"@ApImg4Ticket":     true,
+		"@HostPlatformInfo": "mac",
+		"@Locality":         "en_US",
+		"@VersionInfo":      tssClientVersion,
+		"@UUID":             uuid.New().String(),
+		"ApBoardID":         boardID,
+		"ApChipID":          chipID,
+		"ApECID":            ecid,
+		"ApNonce":           apNonce,
+		"ApProductionMode":  true,
+		"ApSecurityDomain":  secDomain,
+		"ApSecurityMode":    true,
+		"ApSupportsImg4":    true,
+		"SepNonce":          sepNonce,
+		"UniqueBuildID":     bi.UniqueBuildID,
+	}
+	if len(bi.PearlCertificationRootPub) > 0 {
+		tssReq["PearlCertificationRootPub"] = bi.PearlCertificationRootPub
+	}
+	for name, entry := range bi.Manifest {
+		if !entry.Trusted && len(entry.Digest) == 0 {
+			continue
+		}
+		if strings.HasPrefix(name, "Baseband") {
+			continue // requires a BbTicket request
+		}
+		e := map[string]any{
+			"EPRO":    true,
+			"ESEC":    true,
+			"Trusted": entry.Trusted,
+		}
+		if len(entry.Digest) > 0 {
+			e["Digest"] = entry.Digest
+		}
+		tssReq[name] = e
+	}
+
+	return tssReq, nil
+}
+
+// GetSigningStatus crafts a TSS request for the device's build and asks gs.apple.com if it is still being signed
+func GetSigningStatus(identifier, build, proxy string, insecure bool) (*SigningStatus, error) {
+	bm, err := GetBuildManifest(identifier, build, proxy, insecure)
+	if err != nil {
+		return nil, err
+	}
+
+	board, idx, err := getBuildIdentity(bm, identifier)
+	if err != nil {
+		return nil, err
+	}
+
+	apNonce, err := randomHex(32)
+	if err != nil {
+		return nil, err
+	}
+	sepNonce, err := randomHex(20)
+	if err != nil {
+		return nil, err
+	}
+	ecid, err := randomHex(8)
+	if err != nil {
+		return nil, err
+	}
+
+	tssReq, err := newTSSRequest(bm, idx, binary.LittleEndian.Uint64(ecid)&0xffffffffffff, apNonce, sepNonce)
+	if err != nil {
+		return nil, err
+	}
+
+	log.WithFields(log.Fields{
+		"device": identifier,
+		"board":  board,
+		"build":  bm.ProductBuildVersion,
+	}).Debug("Sending TSS request")
+
+	tr, err := sendTSSRequest(tssReq, proxy, insecure)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &SigningStatus{
+		Device:  identifier,
+		Board:   board,
+		Version: bm.ProductVersion,
+		Build:   bm.ProductBuildVersion,
+		Status:  tr.Status,
+		Message: tr.Message,
+	}
+
+	switch {
+	case tr.Status == StatusSuccess && tr.Message == "SUCCESS":
+		status.Signed = true
+	case tr.Status == StatusNotEligible:
+		status.Signed = false
+	default:
+		return status, fmt.Errorf("unexpected TSS response: status=%d message=%s", tr.Status, tr.Message)
+	}
+
+	return status, nil
+}
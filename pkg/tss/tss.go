@@ -92,7 +92,7 @@ func randomHexStr(n int) (string, error) {
 	return hex.EncodeToString(bytes), nil
 }
 
-func getApImg4Ticket(tssReq *Request, proxy string, insecure bool) (*Blob, error) {
+func sendTSSRequest(tssReq any, proxy string, insecure bool) (*Response, error) {
 	trdata, err := plist.Marshal(tssReq, plist.XMLFormat)
 	if err != nil {
 		return nil, err
@@ -156,6 +156,15 @@ func getApImg4Ticket(tssReq *Request, proxy string, insecure bool) (*Blob, error
 		"request_len": len(tr.Plist),
 	}).Debug("TSS Response")
 
+	return &tr, nil
+}
+
+func getApImg4Ticket(tssReq *Request, proxy string, insecure bool) (*Blob, error) {
+	tr, err := sendTSSRequest(tssReq, proxy, insecure)
+	if err != nil {
+		return nil, err
+	}
+
 	if tr.Status == 0 && tr.Message == "SUCCESS" {
 		var blob Blob
 		if err := plist.NewDecoder(strings.NewReader(tr.Plist)).Decode(&blob); err != nil {
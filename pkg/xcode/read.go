@@ -30,7 +30,7 @@ func uniqueDevices(d []Device) []Device {
 func ReadDeviceTraitsDB() ([]Device, error) {
 	var allDevices []Device
 
-	for _, osType := range []string{"iPhoneOS", "AppleTVOS", "WatchOS"} {
+	for _, osType := range []string{"iPhoneOS", "AppleTVOS", "WatchOS", "XROS"} {
 		for _, releaseType := range []string{"", "-beta"} {
 			dbFile := fmt.Sprintf("/Applications/Xcode%s.app/Contents/Developer/Platforms/%s.platform/usr/standalone/device_traits.db", releaseType, osType)
 			if _, err := os.Stat(dbFile); err == nil {
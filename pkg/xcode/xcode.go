@@ -1,22 +1,23 @@
 package xcode
 
-//#cgo LDFLAGS:
-//#include <stdio.h>
-//#include <stdlib.h>
-//#include <string.h>
-import "C"
 import (
 	"bytes"
 	"compress/gzip"
 	_ "embed"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
+	"github.com/blacktop/ipsw/internal/exitcode"
 	"github.com/blacktop/ipsw/internal/utils"
 )
 
+// ErrDeviceNotFound is returned when no device matches a query
+var ErrDeviceNotFound = exitcode.Wrap(exitcode.NotFound, errors.New("device not found"))
+
 //go:embed data/device_traits.gz
 var traitsData []byte
 
@@ -80,29 +81,6 @@ func WriteToJSON(devices []Device, dest string) error {
 	return os.WriteFile(filepath.Clean(dest), dJSON, 0660)
 }
 
-//export c_pkg_xcode_xcode_GetDevices
-func c_pkg_xcode_xcode_GetDevices(outJson **C.char, outJsonLen *C.uint, err **C.char, errLen *C.uint) C.char {
-	devices, devicesError := GetDevices()
-	if devicesError != nil {
-		outError := fmt.Sprintf("c_pkg_xcode_xcode_GetDevices: GetDeviceIPSWs failed with %w", devicesError)
-		*err = C.CString(outError)
-		*errLen = C.uint(len(outError))
-		return C.char(0)
-	}
-	fret, jsonErr := json.Marshal(devices)
-	if jsonErr != nil {
-		outError := fmt.Sprintf("c_pkg_xcode_xcode_GetDevices: Failed to serialize Device object: %w", jsonErr)
-		*err = C.CString(outError)
-		*errLen = C.uint(len(outError))
-		return C.char(0)
-	}
-	cs := C.CString(string(fret))
-	*outJson = cs
-	*outJsonLen = C.uint(C.strlen(cs))
-
-	return C.char(1)
-}
-
 // GetDevices reads the devices from embedded JSON
 func GetDevices() ([]Device, error) {
 	var devices []Device
@@ -134,7 +112,7 @@ func GetDeviceForProd(prod string) (*Device, error) {
 		}
 	}
 
-	return nil, fmt.Errorf("device not found")
+	return nil, ErrDeviceNotFound
 }
 
 // GetDeviceForModel returns the device matching a given model
@@ -151,5 +129,55 @@ func GetDeviceForModel(model string) (*Device, error) {
 		}
 	}
 
-	return nil, fmt.Errorf("device not found")
+	return nil, ErrDeviceNotFound
+}
+
+// GetDeviceForBoard returns the device matching a given board config (i.e. D73AP or d73)
+func GetDeviceForBoard(board string) (*Device, error) {
+
+	devices, err := GetDevices()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, device := range devices {
+		if strings.EqualFold(device.Target, board) || strings.EqualFold(device.TargetType, board) {
+			return &device, nil
+		}
+	}
+
+	return nil, ErrDeviceNotFound
+}
+
+// GetDevicesForIdiom returns the devices of a given idiom (phone, pad, watch or tv)
+func GetDevicesForIdiom(idiom string) ([]Device, error) {
+	return filterDevices(func(d Device) bool {
+		return strings.EqualFold(d.DeviceTrait.ArtworkDeviceIdiom, idiom)
+	})
+}
+
+// GetDevicesForChip returns the devices with a given chip (i.e. t8120)
+func GetDevicesForChip(chip string) ([]Device, error) {
+	return filterDevices(func(d Device) bool {
+		return strings.EqualFold(d.Platform, chip)
+	})
+}
+
+func filterDevices(match func(Device) bool) ([]Device, error) {
+	devices, err := GetDevices()
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []Device
+	for _, device := range devices {
+		if match(device) {
+			matches = append(matches, device)
+		}
+	}
+	if len(matches) == 0 {
+		return nil, ErrDeviceNotFound
+	}
+
+	return matches, nil
 }
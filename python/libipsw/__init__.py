@@ -0,0 +1,194 @@
+"""Python binding of libipsw, the C library of ipsw (build it with `make build-libipsw`).
+
+The functions and dataclasses of _api and _ffi are generated from the C exports by cmd/libipsw/gen;
+failing calls raise a LibipswError subclass for the error's code.
+"""
+
+import ctypes
+import json
+from typing import Callable, Dict, Optional
+
+from . import _runtime
+from ._api import *  # noqa: F401,F403
+from ._api import __all__ as _api_all
+from ._api import (
+    libipsw_call,
+    devportal_download,
+    devportal_list_downloads,
+    devportal_login,
+    devportal_new,
+    download_cancel,
+    download_start,
+    download_status,
+)
+from ._ffi import (  # noqa: F401
+    VERSION,
+    IPSW,
+    BuildInfo,
+    Capabilities,
+    Category,
+    DevDownload,
+    DevDownloads,
+    DevSessionConfig,
+    Device,
+    Dfile,
+    DownloadStatus,
+    ErrorCode,
+    Fformat,
+    LogLevel,
+    MoreDownload,
+    Stats,
+    Verification,
+    WikiConfig,
+    WikiFirmware,
+    XcodeDevice,
+    XcodeDeviceTrait,
+)
+from .errors import (  # noqa: F401
+    AuthError,
+    CanceledError,
+    ChecksumError,
+    DeadlineExceededError,
+    LibipswError,
+    NetworkError,
+    NotFoundError,
+    ParseError,
+    UnsupportedError,
+    InvalidArgumentError,
+)
+
+
+def version() -> str:
+    """returns the C API version of the loaded library (VERSION is the one this binding was generated from)"""
+    return _runtime.version()
+
+
+def new_handle() -> str:
+    """returns a handle for the *_with_handle functions (release it with free_handle)"""
+    return _runtime.new_handle()
+
+
+def free_handle(handle: str) -> None:
+    """cancels the operations of a handle and releases it"""
+    h = _runtime.to_bytes(handle)
+    _runtime.lib().c_libipsw_handle_free(h, len(h))
+
+
+def call(method: str, params: Optional[Dict[str, object]] = None, handle: str = "") -> object:
+    """calls a library method by name (i.e. call("ipsw_me.device", {"identifier": "iPhone16,1"})) and returns
+    its decoded JSON result; call("libipsw.methods") lists the methods and a handle makes the call cancelable"""
+    return libipsw_call(handle, method, json.dumps(params) if params else "")
+
+
+_LOG_CB = ctypes.CFUNCTYPE(None, ctypes.c_int, ctypes.c_char_p, ctypes.c_char_p, ctypes.c_void_p)
+_log_cb = None  # the ctypes callback must outlive its registration
+
+
+def set_log_callback(fn: Optional[Callable[[LogLevel, str, Dict[str, object]], None]], level: LogLevel = LogLevel.INFO) -> None:
+    """routes the library's log entries at or above level to fn(level, message, fields) (None restores stderr);
+    fn is called from the library's threads and must not raise"""
+    global _log_cb
+    if fn is None:
+        _runtime.lib().c_libipsw_set_log_callback(None, 0, None)
+        _log_cb = None
+        return
+
+    def _cb(lvl: int, message: bytes, fields_json: bytes, _user_data: Optional[int]) -> None:
+        try:
+            fields = json.loads(fields_json) if fields_json else {}
+            fn(LogLevel(lvl), message.decode("utf-8", "replace"), fields)
+        except Exception:  # an exception can't unwind through the library
+            pass
+
+    cb = _LOG_CB(_cb)
+    _runtime.lib().c_libipsw_set_log_callback(ctypes.cast(cb, ctypes.c_void_p), int(level), None)
+    _log_cb = cb
+
+
+class Job:
+    """a background download (c_download_start); the job is released on close"""
+
+    def __init__(self, url: str, dest: str = "", sha1: str = "", sha256: str = "", proxy: str = "", insecure: bool = False):
+        self.id: Optional[str] = download_start(url, dest, sha1, sha256, proxy, insecure)
+
+    def status(self) -> DownloadStatus:
+        return download_status(self._id())
+
+    def cancel(self) -> None:
+        download_cancel(self._id())
+
+    def close(self) -> None:
+        if self.id is not None:
+            job = _runtime.to_bytes(self.id)
+            _runtime.lib().c_download_free(job, len(job))
+            self.id = None
+
+    def _id(self) -> str:
+        if self.id is None:
+            raise InvalidArgumentError("job is closed")
+        return self.id
+
+    def __enter__(self) -> "Job":
+        return self
+
+    def __exit__(self, *exc) -> None:
+        self.close()
+
+
+class DevPortal:
+    """a dev portal session (c_devportal_new); the session is released on close"""
+
+    def __init__(self, config: Optional[DevSessionConfig] = None):
+        self.handle: Optional[str] = devportal_new(config)
+
+    def login(self, username: str = "", password: str = "") -> None:
+        devportal_login(self._handle(), username, password)
+
+    def list_downloads(self, download_type: str = "") -> DevDownloads:
+        return devportal_list_downloads(self._handle(), download_type)
+
+    def download(self, url: str, folder: str = "") -> None:
+        devportal_download(self._handle(), url, folder)
+
+    def close(self) -> None:
+        if self.handle is not None:
+            handle = _runtime.to_bytes(self.handle)
+            _runtime.lib().c_devportal_free(handle, len(handle))
+            self.handle = None
+
+    def _handle(self) -> str:
+        if self.handle is None:
+            raise InvalidArgumentError("dev portal session is closed")
+        return self.handle
+
+    def __enter__(self) -> "DevPortal":
+        return self
+
+    def __exit__(self, *exc) -> None:
+        self.close()
+
+
+__all__ = list(_api_all) + [
+    "VERSION",
+    "version",
+    "new_handle",
+    "free_handle",
+    "call",
+    "BuildInfo",
+    "Capabilities",
+    "set_log_callback",
+    "LogLevel",
+    "Job",
+    "DevPortal",
+    "ErrorCode",
+    "LibipswError",
+    "InvalidArgumentError",
+    "NotFoundError",
+    "NetworkError",
+    "ParseError",
+    "AuthError",
+    "CanceledError",
+    "DeadlineExceededError",
+    "ChecksumError",
+    "UnsupportedError",
+]
@@ -0,0 +1,623 @@
+# Code generated by cmd/libipsw/gen; DO NOT EDIT.
+"""Python wrappers of the libipsw C exports"""
+
+import ctypes
+from typing import Any, Dict, List, Optional, Tuple
+
+from . import _runtime as _rt
+from ._ffi import (
+    BuildInfo,
+    Capabilities,
+    Category,
+    DevDownload,
+    DevDownloads,
+    DevSessionConfig,
+    Device,
+    Dfile,
+    DownloadStatus,
+    Fformat,
+    IPSW,
+    MoreDownload,
+    Stats,
+    Verification,
+    WikiConfig,
+    WikiFirmware,
+    XcodeDevice,
+    XcodeDeviceTrait,
+)
+from ._ffi import _list, _map  # noqa: F401
+
+__all__ = [
+    "devportal_new",
+    "devportal_login",
+    "devportal_list_downloads",
+    "devportal_download",
+    "libipsw_call",
+    "download",
+    "download_with_handle",
+    "libipsw_cancel",
+    "libipsw_handle_trace",
+    "ipsw_me_get_device_with_handle",
+    "iphonewiki_get_wiki_ipsws",
+    "ipsw_me_get_all_devices",
+    "ipsw_me_get_device",
+    "ipsw_me_get_device_ipsws",
+    "ipsw_me_get_device_otas",
+    "ipsw_me_get_all_ipsw",
+    "ipsw_me_get_ipsw",
+    "ipsw_me_get_version",
+    "ipsw_me_get_build_id",
+    "download_start",
+    "download_status",
+    "download_cancel",
+    "manager_add",
+    "manager_pause",
+    "manager_resume",
+    "manager_cancel",
+    "manager_status",
+    "manager_list",
+    "libipsw_result_size",
+    "ipsw_me_get_all_devices_result",
+    "ipsw_me_get_device_ipsws_result",
+    "ipsw_me_get_all_ipsw_result",
+    "iphonewiki_get_wiki_ipsws_result",
+    "libipsw_version",
+    "libipsw_capabilities",
+    "xcode_get_devices",
+    "xcode_get_device_for_prod",
+    "xcode_get_device_for_model",
+    "xcode_get_device_for_board",
+    "xcode_get_devices_for_idiom",
+    "xcode_get_devices_for_chip",
+]
+
+
+def devportal_new(config: Optional['DevSessionConfig'] = None) -> str:
+    """c_devportal_new opens a dev portal session (an empty config uses the defaults); free it with c_devportal_free
+
+    Wraps c_devportal_new (raises LibipswError on failure).
+    """
+    config_b = _rt.encode_json(config)
+    out_handle = ctypes.c_void_p()
+    out_handle_len = ctypes.c_uint()
+    err, err_len = ctypes.c_void_p(), ctypes.c_uint()
+    if not _rt.lib().c_devportal_new(config_b, len(config_b), ctypes.byref(out_handle), ctypes.byref(out_handle_len), ctypes.byref(err), ctypes.byref(err_len)):
+        _rt.raise_error(err, err_len)
+    return _rt.take_string(out_handle, out_handle_len)
+
+
+def devportal_login(handle: str, username: str = "", password: str = "") -> None:
+    """c_devportal_login logs in to the dev portal (empty credentials are read from the session's vault)
+
+    Wraps c_devportal_login (raises LibipswError on failure).
+    """
+    handle_b = _rt.to_bytes(handle)
+    username_b = _rt.to_bytes(username)
+    password_b = _rt.to_bytes(password)
+    err, err_len = ctypes.c_void_p(), ctypes.c_uint()
+    if not _rt.lib().c_devportal_login(handle_b, len(handle_b), username_b, len(username_b), password_b, len(password_b), ctypes.byref(err), ctypes.byref(err_len)):
+        _rt.raise_error(err, err_len)
+
+
+def devportal_list_downloads(handle: str, download_type: str = "") -> 'DevDownloads':
+    """c_devportal_list_downloads lists the downloads of a type (os or more, empty for both) of a logged in session
+
+    Wraps c_devportal_list_downloads (raises LibipswError on failure).
+    """
+    handle_b = _rt.to_bytes(handle)
+    download_type_b = _rt.to_bytes(download_type)
+    out_json = ctypes.c_void_p()
+    out_json_len = ctypes.c_uint()
+    err, err_len = ctypes.c_void_p(), ctypes.c_uint()
+    if not _rt.lib().c_devportal_list_downloads(handle_b, len(handle_b), download_type_b, len(download_type_b), ctypes.byref(out_json), ctypes.byref(out_json_len), ctypes.byref(err), ctypes.byref(err_len)):
+        _rt.raise_error(err, err_len)
+    return DevDownloads._decode(_rt.take_json(out_json, out_json_len))
+
+
+def devportal_download(handle: str, url: str, folder: str = "") -> None:
+    """c_devportal_download downloads a dev portal URL (i.e. a url from c_devportal_list_downloads) into folder
+
+    Wraps c_devportal_download (raises LibipswError on failure).
+    """
+    handle_b = _rt.to_bytes(handle)
+    url_b = _rt.to_bytes(url)
+    folder_b = _rt.to_bytes(folder)
+    err, err_len = ctypes.c_void_p(), ctypes.c_uint()
+    if not _rt.lib().c_devportal_download(handle_b, len(handle_b), url_b, len(url_b), folder_b, len(folder_b), ctypes.byref(err), ctypes.byref(err_len)):
+        _rt.raise_error(err, err_len)
+
+
+def libipsw_call(handle: str, method: str, params_json: str) -> Any:
+    """c_libipsw_call calls a library method by name (i.e. ipsw_me.device, see libipsw.methods for the list)
+    with paramsJson, a JSON object of its parameters (empty for none), and returns its JSON result; pass a
+    handle of c_libipsw_handle_new to cancel the call with c_libipsw_cancel (empty for none). An unknown
+    method fails with LIBIPSW_ERR_UNSUPPORTED.
+
+    Wraps c_libipsw_call (raises LibipswError on failure).
+    """
+    handle_b = _rt.to_bytes(handle)
+    method_b = _rt.to_bytes(method)
+    params_json_b = _rt.to_bytes(params_json)
+    out_json = ctypes.c_void_p()
+    out_json_len = ctypes.c_uint()
+    err, err_len = ctypes.c_void_p(), ctypes.c_uint()
+    if not _rt.lib().c_libipsw_call(handle_b, len(handle_b), method_b, len(method_b), params_json_b, len(params_json_b), ctypes.byref(out_json), ctypes.byref(out_json_len), ctypes.byref(err), ctypes.byref(err_len)):
+        _rt.raise_error(err, err_len)
+    return _rt.take_json(out_json, out_json_len)
+
+
+def download(url: str, dest: str = "", sha1: str = "", sha256: str = "", proxy: str = "", insecure: bool = False) -> 'Stats':
+    """c_internal_download_Download downloads url to dest (resuming a partial download) and blocks until it is done;
+    the checksums are optional and the download's Stats are returned as JSON
+    (use c_internal_download_manager_Add to download in the background)
+
+    Wraps c_internal_download_Download (raises LibipswError on failure).
+    """
+    url_b = _rt.to_bytes(url)
+    dest_b = _rt.to_bytes(dest)
+    sha1_b = _rt.to_bytes(sha1)
+    sha256_b = _rt.to_bytes(sha256)
+    proxy_b = _rt.to_bytes(proxy)
+    out_json = ctypes.c_void_p()
+    out_json_len = ctypes.c_uint()
+    err, err_len = ctypes.c_void_p(), ctypes.c_uint()
+    if not _rt.lib().c_internal_download_Download(url_b, len(url_b), dest_b, len(dest_b), sha1_b, len(sha1_b), sha256_b, len(sha256_b), proxy_b, len(proxy_b), 1 if insecure else 0, ctypes.byref(out_json), ctypes.byref(out_json_len), ctypes.byref(err), ctypes.byref(err_len)):
+        _rt.raise_error(err, err_len)
+    return Stats._decode(_rt.take_json(out_json, out_json_len))
+
+
+def download_with_handle(handle: str, url: str, dest: str = "", sha1: str = "", sha256: str = "", proxy: str = "", insecure: bool = False) -> 'Stats':
+    """c_internal_download_DownloadWithHandle is c_internal_download_Download that can be canceled with c_libipsw_cancel
+
+    Wraps c_internal_download_DownloadWithHandle (raises LibipswError on failure).
+    """
+    handle_b = _rt.to_bytes(handle)
+    url_b = _rt.to_bytes(url)
+    dest_b = _rt.to_bytes(dest)
+    sha1_b = _rt.to_bytes(sha1)
+    sha256_b = _rt.to_bytes(sha256)
+    proxy_b = _rt.to_bytes(proxy)
+    out_json = ctypes.c_void_p()
+    out_json_len = ctypes.c_uint()
+    err, err_len = ctypes.c_void_p(), ctypes.c_uint()
+    if not _rt.lib().c_internal_download_DownloadWithHandle(handle_b, len(handle_b), url_b, len(url_b), dest_b, len(dest_b), sha1_b, len(sha1_b), sha256_b, len(sha256_b), proxy_b, len(proxy_b), 1 if insecure else 0, ctypes.byref(out_json), ctypes.byref(out_json_len), ctypes.byref(err), ctypes.byref(err_len)):
+        _rt.raise_error(err, err_len)
+    return Stats._decode(_rt.take_json(out_json, out_json_len))
+
+
+def libipsw_cancel(handle: str) -> None:
+    """c_libipsw_cancel cancels the in-flight operation of a handle (or the download of a download manager ID);
+    the canceled call returns an error once its requests are aborted
+
+    Wraps c_libipsw_cancel (raises LibipswError on failure).
+    """
+    handle_b = _rt.to_bytes(handle)
+    err, err_len = ctypes.c_void_p(), ctypes.c_uint()
+    if not _rt.lib().c_libipsw_cancel(handle_b, len(handle_b), ctypes.byref(err), ctypes.byref(err_len)):
+        _rt.raise_error(err, err_len)
+
+
+def libipsw_handle_trace(handle: str) -> str:
+    """c_libipsw_handle_trace returns the correlation ID of a handle's operation (it is included in the
+    operation's log lines and error messages)
+
+    Wraps c_libipsw_handle_trace (raises LibipswError on failure).
+    """
+    handle_b = _rt.to_bytes(handle)
+    out_trace = ctypes.c_void_p()
+    out_trace_len = ctypes.c_uint()
+    err, err_len = ctypes.c_void_p(), ctypes.c_uint()
+    if not _rt.lib().c_libipsw_handle_trace(handle_b, len(handle_b), ctypes.byref(out_trace), ctypes.byref(out_trace_len), ctypes.byref(err), ctypes.byref(err_len)):
+        _rt.raise_error(err, err_len)
+    return _rt.take_string(out_trace, out_trace_len)
+
+
+def ipsw_me_get_device_with_handle(handle: str, identifier: str) -> 'Device':
+    """Wraps c_internal_download_ipsw_me_GetDeviceWithHandle (raises LibipswError on failure).
+    """
+    handle_b = _rt.to_bytes(handle)
+    identifier_b = _rt.to_bytes(identifier)
+    out_json = ctypes.c_void_p()
+    out_json_len = ctypes.c_uint()
+    err, err_len = ctypes.c_void_p(), ctypes.c_uint()
+    if not _rt.lib().c_internal_download_ipsw_me_GetDeviceWithHandle(handle_b, len(handle_b), identifier_b, len(identifier_b), ctypes.byref(out_json), ctypes.byref(out_json_len), ctypes.byref(err), ctypes.byref(err_len)):
+        _rt.raise_error(err, err_len)
+    return Device._decode(_rt.take_json(out_json, out_json_len))
+
+
+def iphonewiki_get_wiki_ipsws(config: Optional['WikiConfig'] = None, proxy: str = "", insecure: bool = False) -> List['WikiFirmware']:
+    """Wraps c_internal_download_iphonewiki_GetWikiIPSWs (raises LibipswError on failure).
+    """
+    config_b = _rt.encode_json(config)
+    proxy_b = _rt.to_bytes(proxy)
+    output_json = ctypes.c_void_p()
+    output_json_len = ctypes.c_int()
+    err, err_len = ctypes.c_void_p(), ctypes.c_uint()
+    if not _rt.lib().c_internal_download_iphonewiki_GetWikiIPSWs(config_b, len(config_b), proxy_b, len(proxy_b), 1 if insecure else 0, ctypes.byref(output_json), ctypes.byref(output_json_len), ctypes.byref(err), ctypes.byref(err_len)):
+        _rt.raise_error(err, err_len)
+    return _list(WikiFirmware._decode)(_rt.take_json(output_json, output_json_len))
+
+
+def ipsw_me_get_all_devices() -> List['Device']:
+    """Wraps c_internal_download_ipsw_me_GetAllDevices (raises LibipswError on failure).
+    """
+    out_json = ctypes.c_void_p()
+    out_json_len = ctypes.c_uint()
+    err, err_len = ctypes.c_void_p(), ctypes.c_uint()
+    if not _rt.lib().c_internal_download_ipsw_me_GetAllDevices(ctypes.byref(out_json), ctypes.byref(out_json_len), ctypes.byref(err), ctypes.byref(err_len)):
+        _rt.raise_error(err, err_len)
+    return _list(Device._decode)(_rt.take_json(out_json, out_json_len))
+
+
+def ipsw_me_get_device(identifier: str) -> 'Device':
+    """Wraps c_internal_download_ipsw_me_GetDevice (raises LibipswError on failure).
+    """
+    identifier_b = _rt.to_bytes(identifier)
+    out_json = ctypes.c_void_p()
+    out_json_len = ctypes.c_uint()
+    err, err_len = ctypes.c_void_p(), ctypes.c_uint()
+    if not _rt.lib().c_internal_download_ipsw_me_GetDevice(identifier_b, len(identifier_b), ctypes.byref(out_json), ctypes.byref(out_json_len), ctypes.byref(err), ctypes.byref(err_len)):
+        _rt.raise_error(err, err_len)
+    return Device._decode(_rt.take_json(out_json, out_json_len))
+
+
+def ipsw_me_get_device_ipsws(identifier: str) -> List['IPSW']:
+    """Wraps c_internal_download_ipsw_me_GetDeviceIPSWs (raises LibipswError on failure).
+    """
+    identifier_b = _rt.to_bytes(identifier)
+    out_json = ctypes.c_void_p()
+    out_json_len = ctypes.c_uint()
+    err, err_len = ctypes.c_void_p(), ctypes.c_uint()
+    if not _rt.lib().c_internal_download_ipsw_me_GetDeviceIPSWs(identifier_b, len(identifier_b), ctypes.byref(out_json), ctypes.byref(out_json_len), ctypes.byref(err), ctypes.byref(err_len)):
+        _rt.raise_error(err, err_len)
+    return _list(IPSW._decode)(_rt.take_json(out_json, out_json_len))
+
+
+def ipsw_me_get_device_otas(identifier: str) -> List['IPSW']:
+    """c_internal_download_ipsw_me_GetDeviceOTAs returns the OTAs of a device (i.e. iPhone16,1) from ipsw.me
+
+    Wraps c_internal_download_ipsw_me_GetDeviceOTAs (raises LibipswError on failure).
+    """
+    identifier_b = _rt.to_bytes(identifier)
+    out_json = ctypes.c_void_p()
+    out_json_len = ctypes.c_uint()
+    err, err_len = ctypes.c_void_p(), ctypes.c_uint()
+    if not _rt.lib().c_internal_download_ipsw_me_GetDeviceOTAs(identifier_b, len(identifier_b), ctypes.byref(out_json), ctypes.byref(out_json_len), ctypes.byref(err), ctypes.byref(err_len)):
+        _rt.raise_error(err, err_len)
+    return _list(IPSW._decode)(_rt.take_json(out_json, out_json_len))
+
+
+def ipsw_me_get_all_ipsw(version: str) -> List['IPSW']:
+    """Wraps c_internal_download_ipsw_me_GetAllIPSW (raises LibipswError on failure).
+    """
+    version_b = _rt.to_bytes(version)
+    out_json = ctypes.c_void_p()
+    out_json_len = ctypes.c_uint()
+    err, err_len = ctypes.c_void_p(), ctypes.c_uint()
+    if not _rt.lib().c_internal_download_ipsw_me_GetAllIPSW(version_b, len(version_b), ctypes.byref(out_json), ctypes.byref(out_json_len), ctypes.byref(err), ctypes.byref(err_len)):
+        _rt.raise_error(err, err_len)
+    return _list(IPSW._decode)(_rt.take_json(out_json, out_json_len))
+
+
+def ipsw_me_get_ipsw(identifier: str, build_id: str) -> 'IPSW':
+    """Wraps c_internal_download_ipsw_me_GetIPSW (raises LibipswError on failure).
+    """
+    identifier_b = _rt.to_bytes(identifier)
+    build_id_b = _rt.to_bytes(build_id)
+    out_json = ctypes.c_void_p()
+    out_json_len = ctypes.c_uint()
+    err, err_len = ctypes.c_void_p(), ctypes.c_uint()
+    if not _rt.lib().c_internal_download_ipsw_me_GetIPSW(identifier_b, len(identifier_b), build_id_b, len(build_id_b), ctypes.byref(out_json), ctypes.byref(out_json_len), ctypes.byref(err), ctypes.byref(err_len)):
+        _rt.raise_error(err, err_len)
+    return IPSW._decode(_rt.take_json(out_json, out_json_len))
+
+
+def ipsw_me_get_version(build_id: str) -> str:
+    """c_internal_download_ipsw_me_GetVersion returns the version as a JSON string (i.e. "17.0")
+
+    Wraps c_internal_download_ipsw_me_GetVersion (raises LibipswError on failure).
+    """
+    build_id_b = _rt.to_bytes(build_id)
+    out_json = ctypes.c_void_p()
+    out_json_len = ctypes.c_uint()
+    err, err_len = ctypes.c_void_p(), ctypes.c_uint()
+    if not _rt.lib().c_internal_download_ipsw_me_GetVersion(build_id_b, len(build_id_b), ctypes.byref(out_json), ctypes.byref(out_json_len), ctypes.byref(err), ctypes.byref(err_len)):
+        _rt.raise_error(err, err_len)
+    return _rt.take_json(out_json, out_json_len)
+
+
+def ipsw_me_get_build_id(version: str, identifier: str) -> str:
+    """c_internal_download_ipsw_me_GetBuildID returns the build ID as a JSON string (i.e. "21A329")
+
+    Wraps c_internal_download_ipsw_me_GetBuildID (raises LibipswError on failure).
+    """
+    version_b = _rt.to_bytes(version)
+    identifier_b = _rt.to_bytes(identifier)
+    out_json = ctypes.c_void_p()
+    out_json_len = ctypes.c_uint()
+    err, err_len = ctypes.c_void_p(), ctypes.c_uint()
+    if not _rt.lib().c_internal_download_ipsw_me_GetBuildID(version_b, len(version_b), identifier_b, len(identifier_b), ctypes.byref(out_json), ctypes.byref(out_json_len), ctypes.byref(err), ctypes.byref(err_len)):
+        _rt.raise_error(err, err_len)
+    return _rt.take_json(out_json, out_json_len)
+
+
+def download_start(url: str, dest: str = "", sha1: str = "", sha256: str = "", proxy: str = "", insecure: bool = False) -> str:
+    """c_download_start downloads url to dest (the URL's file name in the current directory if empty) in the background;
+    the checksums are optional and the job handle must be released with c_download_free
+
+    Wraps c_download_start (raises LibipswError on failure).
+    """
+    url_b = _rt.to_bytes(url)
+    dest_b = _rt.to_bytes(dest)
+    sha1_b = _rt.to_bytes(sha1)
+    sha256_b = _rt.to_bytes(sha256)
+    proxy_b = _rt.to_bytes(proxy)
+    out_job = ctypes.c_void_p()
+    out_job_len = ctypes.c_uint()
+    err, err_len = ctypes.c_void_p(), ctypes.c_uint()
+    if not _rt.lib().c_download_start(url_b, len(url_b), dest_b, len(dest_b), sha1_b, len(sha1_b), sha256_b, len(sha256_b), proxy_b, len(proxy_b), 1 if insecure else 0, ctypes.byref(out_job), ctypes.byref(out_job_len), ctypes.byref(err), ctypes.byref(err_len)):
+        _rt.raise_error(err, err_len)
+    return _rt.take_string(out_job, out_job_len)
+
+
+def download_status(job: str) -> 'DownloadStatus':
+    """c_download_status returns the status of a download job
+
+    Wraps c_download_status (raises LibipswError on failure).
+    """
+    job_b = _rt.to_bytes(job)
+    out_json = ctypes.c_void_p()
+    out_json_len = ctypes.c_uint()
+    err, err_len = ctypes.c_void_p(), ctypes.c_uint()
+    if not _rt.lib().c_download_status(job_b, len(job_b), ctypes.byref(out_json), ctypes.byref(out_json_len), ctypes.byref(err), ctypes.byref(err_len)):
+        _rt.raise_error(err, err_len)
+    return DownloadStatus._decode(_rt.take_json(out_json, out_json_len))
+
+
+def download_cancel(job: str) -> None:
+    """c_download_cancel cancels a download job (canceling a finished job does nothing); it returns right away
+    and the job's state becomes canceled once its transfer is aborted
+
+    Wraps c_download_cancel (raises LibipswError on failure).
+    """
+    job_b = _rt.to_bytes(job)
+    err, err_len = ctypes.c_void_p(), ctypes.c_uint()
+    if not _rt.lib().c_download_cancel(job_b, len(job_b), ctypes.byref(err), ctypes.byref(err_len)):
+        _rt.raise_error(err, err_len)
+
+
+def manager_add(url: str, dest: str = "", sha1: str = "", proxy: str = "", insecure: bool = False) -> str:
+    """Wraps c_internal_download_manager_Add (raises LibipswError on failure).
+    """
+    url_b = _rt.to_bytes(url)
+    dest_b = _rt.to_bytes(dest)
+    sha1_b = _rt.to_bytes(sha1)
+    proxy_b = _rt.to_bytes(proxy)
+    out_id = ctypes.c_void_p()
+    out_id_len = ctypes.c_uint()
+    err, err_len = ctypes.c_void_p(), ctypes.c_uint()
+    if not _rt.lib().c_internal_download_manager_Add(url_b, len(url_b), dest_b, len(dest_b), sha1_b, len(sha1_b), proxy_b, len(proxy_b), 1 if insecure else 0, ctypes.byref(out_id), ctypes.byref(out_id_len), ctypes.byref(err), ctypes.byref(err_len)):
+        _rt.raise_error(err, err_len)
+    return _rt.take_string(out_id, out_id_len)
+
+
+def manager_pause(id: str) -> None:
+    """Wraps c_internal_download_manager_Pause (raises LibipswError on failure).
+    """
+    id_b = _rt.to_bytes(id)
+    err, err_len = ctypes.c_void_p(), ctypes.c_uint()
+    if not _rt.lib().c_internal_download_manager_Pause(id_b, len(id_b), ctypes.byref(err), ctypes.byref(err_len)):
+        _rt.raise_error(err, err_len)
+
+
+def manager_resume(id: str) -> None:
+    """Wraps c_internal_download_manager_Resume (raises LibipswError on failure).
+    """
+    id_b = _rt.to_bytes(id)
+    err, err_len = ctypes.c_void_p(), ctypes.c_uint()
+    if not _rt.lib().c_internal_download_manager_Resume(id_b, len(id_b), ctypes.byref(err), ctypes.byref(err_len)):
+        _rt.raise_error(err, err_len)
+
+
+def manager_cancel(id: str) -> None:
+    """Wraps c_internal_download_manager_Cancel (raises LibipswError on failure).
+    """
+    id_b = _rt.to_bytes(id)
+    err, err_len = ctypes.c_void_p(), ctypes.c_uint()
+    if not _rt.lib().c_internal_download_manager_Cancel(id_b, len(id_b), ctypes.byref(err), ctypes.byref(err_len)):
+        _rt.raise_error(err, err_len)
+
+
+def manager_status(id: str) -> 'DownloadStatus':
+    """Wraps c_internal_download_manager_Status (raises LibipswError on failure).
+    """
+    id_b = _rt.to_bytes(id)
+    out_json = ctypes.c_void_p()
+    out_json_len = ctypes.c_uint()
+    err, err_len = ctypes.c_void_p(), ctypes.c_uint()
+    if not _rt.lib().c_internal_download_manager_Status(id_b, len(id_b), ctypes.byref(out_json), ctypes.byref(out_json_len), ctypes.byref(err), ctypes.byref(err_len)):
+        _rt.raise_error(err, err_len)
+    return DownloadStatus._decode(_rt.take_json(out_json, out_json_len))
+
+
+def manager_list() -> List['DownloadStatus']:
+    """Wraps c_internal_download_manager_List (raises LibipswError on failure).
+    """
+    out_json = ctypes.c_void_p()
+    out_json_len = ctypes.c_uint()
+    err, err_len = ctypes.c_void_p(), ctypes.c_uint()
+    if not _rt.lib().c_internal_download_manager_List(ctypes.byref(out_json), ctypes.byref(out_json_len), ctypes.byref(err), ctypes.byref(err_len)):
+        _rt.raise_error(err, err_len)
+    return _list(DownloadStatus._decode)(_rt.take_json(out_json, out_json_len))
+
+
+def libipsw_result_size(result: str) -> int:
+    """Wraps c_libipsw_result_size (raises LibipswError on failure).
+    """
+    result_b = _rt.to_bytes(result)
+    out_size = ctypes.c_ulonglong()
+    err, err_len = ctypes.c_void_p(), ctypes.c_uint()
+    if not _rt.lib().c_libipsw_result_size(result_b, len(result_b), ctypes.byref(out_size), ctypes.byref(err), ctypes.byref(err_len)):
+        _rt.raise_error(err, err_len)
+    return out_size.value
+
+
+def ipsw_me_get_all_devices_result() -> List['Device']:
+    """Wraps c_internal_download_ipsw_me_GetAllDevicesResult (raises LibipswError on failure).
+    """
+    out_result = ctypes.c_void_p()
+    out_result_len = ctypes.c_uint()
+    out_size = ctypes.c_ulonglong()
+    err, err_len = ctypes.c_void_p(), ctypes.c_uint()
+    if not _rt.lib().c_internal_download_ipsw_me_GetAllDevicesResult(ctypes.byref(out_result), ctypes.byref(out_result_len), ctypes.byref(out_size), ctypes.byref(err), ctypes.byref(err_len)):
+        _rt.raise_error(err, err_len)
+    return _list(Device._decode)(_rt.take_result(out_result, out_result_len))
+
+
+def ipsw_me_get_device_ipsws_result(identifier: str) -> List['IPSW']:
+    """Wraps c_internal_download_ipsw_me_GetDeviceIPSWsResult (raises LibipswError on failure).
+    """
+    identifier_b = _rt.to_bytes(identifier)
+    out_result = ctypes.c_void_p()
+    out_result_len = ctypes.c_uint()
+    out_size = ctypes.c_ulonglong()
+    err, err_len = ctypes.c_void_p(), ctypes.c_uint()
+    if not _rt.lib().c_internal_download_ipsw_me_GetDeviceIPSWsResult(identifier_b, len(identifier_b), ctypes.byref(out_result), ctypes.byref(out_result_len), ctypes.byref(out_size), ctypes.byref(err), ctypes.byref(err_len)):
+        _rt.raise_error(err, err_len)
+    return _list(IPSW._decode)(_rt.take_result(out_result, out_result_len))
+
+
+def ipsw_me_get_all_ipsw_result(version: str) -> List['IPSW']:
+    """Wraps c_internal_download_ipsw_me_GetAllIPSWResult (raises LibipswError on failure).
+    """
+    version_b = _rt.to_bytes(version)
+    out_result = ctypes.c_void_p()
+    out_result_len = ctypes.c_uint()
+    out_size = ctypes.c_ulonglong()
+    err, err_len = ctypes.c_void_p(), ctypes.c_uint()
+    if not _rt.lib().c_internal_download_ipsw_me_GetAllIPSWResult(version_b, len(version_b), ctypes.byref(out_result), ctypes.byref(out_result_len), ctypes.byref(out_size), ctypes.byref(err), ctypes.byref(err_len)):
+        _rt.raise_error(err, err_len)
+    return _list(IPSW._decode)(_rt.take_result(out_result, out_result_len))
+
+
+def iphonewiki_get_wiki_ipsws_result(config: Optional['WikiConfig'] = None, proxy: str = "", insecure: bool = False) -> List['WikiFirmware']:
+    """Wraps c_internal_download_iphonewiki_GetWikiIPSWsResult (raises LibipswError on failure).
+    """
+    config_b = _rt.encode_json(config)
+    proxy_b = _rt.to_bytes(proxy)
+    out_result = ctypes.c_void_p()
+    out_result_len = ctypes.c_uint()
+    out_size = ctypes.c_ulonglong()
+    err, err_len = ctypes.c_void_p(), ctypes.c_uint()
+    if not _rt.lib().c_internal_download_iphonewiki_GetWikiIPSWsResult(config_b, len(config_b), proxy_b, len(proxy_b), 1 if insecure else 0, ctypes.byref(out_result), ctypes.byref(out_result_len), ctypes.byref(out_size), ctypes.byref(err), ctypes.byref(err_len)):
+        _rt.raise_error(err, err_len)
+    return _list(WikiFirmware._decode)(_rt.take_result(out_result, out_result_len))
+
+
+def libipsw_version() -> 'BuildInfo':
+    """c_libipsw_version returns the version of the library as JSON: the ipsw module version, the C API
+    version, the git commit, the Go version and the capabilities of c_libipsw_capabilities
+
+    Wraps c_libipsw_version (raises LibipswError on failure).
+    """
+    out_json = ctypes.c_void_p()
+    out_json_len = ctypes.c_uint()
+    err, err_len = ctypes.c_void_p(), ctypes.c_uint()
+    if not _rt.lib().c_libipsw_version(ctypes.byref(out_json), ctypes.byref(out_json_len), ctypes.byref(err), ctypes.byref(err_len)):
+        _rt.raise_error(err, err_len)
+    return BuildInfo._decode(_rt.take_json(out_json, out_json_len))
+
+
+def libipsw_capabilities() -> 'Capabilities':
+    """c_libipsw_capabilities returns what the library supports as JSON: its sources (i.e. ipsw.me,
+    developer.apple.com) and whether it was built with cgo or for wasm
+
+    Wraps c_libipsw_capabilities (raises LibipswError on failure).
+    """
+    out_json = ctypes.c_void_p()
+    out_json_len = ctypes.c_uint()
+    err, err_len = ctypes.c_void_p(), ctypes.c_uint()
+    if not _rt.lib().c_libipsw_capabilities(ctypes.byref(out_json), ctypes.byref(out_json_len), ctypes.byref(err), ctypes.byref(err_len)):
+        _rt.raise_error(err, err_len)
+    return Capabilities._decode(_rt.take_json(out_json, out_json_len))
+
+
+def xcode_get_devices() -> List['XcodeDevice']:
+    """Wraps c_pkg_xcode_xcode_GetDevices (raises LibipswError on failure).
+    """
+    out_json = ctypes.c_void_p()
+    out_json_len = ctypes.c_uint()
+    err, err_len = ctypes.c_void_p(), ctypes.c_uint()
+    if not _rt.lib().c_pkg_xcode_xcode_GetDevices(ctypes.byref(out_json), ctypes.byref(out_json_len), ctypes.byref(err), ctypes.byref(err_len)):
+        _rt.raise_error(err, err_len)
+    return _list(XcodeDevice._decode)(_rt.take_json(out_json, out_json_len))
+
+
+def xcode_get_device_for_prod(prod: str) -> 'XcodeDevice':
+    """c_pkg_xcode_xcode_GetDeviceForProd returns the device with a product type (i.e. iPhone16,1)
+
+    Wraps c_pkg_xcode_xcode_GetDeviceForProd (raises LibipswError on failure).
+    """
+    prod_b = _rt.to_bytes(prod)
+    out_json = ctypes.c_void_p()
+    out_json_len = ctypes.c_uint()
+    err, err_len = ctypes.c_void_p(), ctypes.c_uint()
+    if not _rt.lib().c_pkg_xcode_xcode_GetDeviceForProd(prod_b, len(prod_b), ctypes.byref(out_json), ctypes.byref(out_json_len), ctypes.byref(err), ctypes.byref(err_len)):
+        _rt.raise_error(err, err_len)
+    return XcodeDevice._decode(_rt.take_json(out_json, out_json_len))
+
+
+def xcode_get_device_for_model(model: str) -> 'XcodeDevice':
+    """c_pkg_xcode_xcode_GetDeviceForModel returns the device with a model (i.e. A2848)
+
+    Wraps c_pkg_xcode_xcode_GetDeviceForModel (raises LibipswError on failure).
+    """
+    model_b = _rt.to_bytes(model)
+    out_json = ctypes.c_void_p()
+    out_json_len = ctypes.c_uint()
+    err, err_len = ctypes.c_void_p(), ctypes.c_uint()
+    if not _rt.lib().c_pkg_xcode_xcode_GetDeviceForModel(model_b, len(model_b), ctypes.byref(out_json), ctypes.byref(out_json_len), ctypes.byref(err), ctypes.byref(err_len)):
+        _rt.raise_error(err, err_len)
+    return XcodeDevice._decode(_rt.take_json(out_json, out_json_len))
+
+
+def xcode_get_device_for_board(board: str) -> 'XcodeDevice':
+    """c_pkg_xcode_xcode_GetDeviceForBoard returns the device with a board config (i.e. D83AP or d83)
+
+    Wraps c_pkg_xcode_xcode_GetDeviceForBoard (raises LibipswError on failure).
+    """
+    board_b = _rt.to_bytes(board)
+    out_json = ctypes.c_void_p()
+    out_json_len = ctypes.c_uint()
+    err, err_len = ctypes.c_void_p(), ctypes.c_uint()
+    if not _rt.lib().c_pkg_xcode_xcode_GetDeviceForBoard(board_b, len(board_b), ctypes.byref(out_json), ctypes.byref(out_json_len), ctypes.byref(err), ctypes.byref(err_len)):
+        _rt.raise_error(err, err_len)
+    return XcodeDevice._decode(_rt.take_json(out_json, out_json_len))
+
+
+def xcode_get_devices_for_idiom(idiom: str) -> List['XcodeDevice']:
+    """c_pkg_xcode_xcode_GetDevicesForIdiom returns the devices of an idiom (phone, pad, watch or tv)
+
+    Wraps c_pkg_xcode_xcode_GetDevicesForIdiom (raises LibipswError on failure).
+    """
+    idiom_b = _rt.to_bytes(idiom)
+    out_json = ctypes.c_void_p()
+    out_json_len = ctypes.c_uint()
+    err, err_len = ctypes.c_void_p(), ctypes.c_uint()
+    if not _rt.lib().c_pkg_xcode_xcode_GetDevicesForIdiom(idiom_b, len(idiom_b), ctypes.byref(out_json), ctypes.byref(out_json_len), ctypes.byref(err), ctypes.byref(err_len)):
+        _rt.raise_error(err, err_len)
+    return _list(XcodeDevice._decode)(_rt.take_json(out_json, out_json_len))
+
+
+def xcode_get_devices_for_chip(chip: str) -> List['XcodeDevice']:
+    """c_pkg_xcode_xcode_GetDevicesForChip returns the devices with a chip (i.e. t8130)
+
+    Wraps c_pkg_xcode_xcode_GetDevicesForChip (raises LibipswError on failure).
+    """
+    chip_b = _rt.to_bytes(chip)
+    out_json = ctypes.c_void_p()
+    out_json_len = ctypes.c_uint()
+    err, err_len = ctypes.c_void_p(), ctypes.c_uint()
+    if not _rt.lib().c_pkg_xcode_xcode_GetDevicesForChip(chip_b, len(chip_b), ctypes.byref(out_json), ctypes.byref(out_json_len), ctypes.byref(err), ctypes.byref(err_len)):
+        _rt.raise_error(err, err_len)
+    return _list(XcodeDevice._decode)(_rt.take_json(out_json, out_json_len))
@@ -0,0 +1,580 @@
+# Code generated by cmd/libipsw/gen; DO NOT EDIT.
+"""ctypes prototypes, error codes and JSON types of the libipsw C API"""
+
+import ctypes
+import enum
+from dataclasses import dataclass
+from typing import Any, Callable, Dict, List, Optional, Tuple
+
+VERSION = "1.5.0"
+
+
+class ErrorCode(enum.IntEnum):
+    """the stable error codes of c_libipsw_error_code"""
+
+    OK = 0
+    UNKNOWN = 1
+    INVALID_ARG = 2
+    NOT_FOUND = 3
+    NETWORK = 4
+    PARSE = 5
+    AUTH = 6
+    CANCELED = 7
+    TIMEOUT = 8
+    CHECKSUM = 9
+    UNSUPPORTED = 10
+
+
+class LogLevel(enum.IntEnum):
+    """the levels of c_libipsw_set_log_callback"""
+
+    DEBUG = 0
+    INFO = 1
+    WARN = 2
+    ERROR = 3
+    FATAL = 4
+
+
+# argtypes and restype of the exports
+PROTOTYPES = {
+    "c_devportal_new": (ctypes.c_byte, [ctypes.c_void_p, ctypes.c_uint, ctypes.POINTER(ctypes.c_void_p), ctypes.POINTER(ctypes.c_uint), ctypes.POINTER(ctypes.c_void_p), ctypes.POINTER(ctypes.c_uint)]),
+    "c_devportal_login": (ctypes.c_byte, [ctypes.c_void_p, ctypes.c_uint, ctypes.c_void_p, ctypes.c_uint, ctypes.c_void_p, ctypes.c_uint, ctypes.POINTER(ctypes.c_void_p), ctypes.POINTER(ctypes.c_uint)]),
+    "c_devportal_list_downloads": (ctypes.c_byte, [ctypes.c_void_p, ctypes.c_uint, ctypes.c_void_p, ctypes.c_uint, ctypes.POINTER(ctypes.c_void_p), ctypes.POINTER(ctypes.c_uint), ctypes.POINTER(ctypes.c_void_p), ctypes.POINTER(ctypes.c_uint)]),
+    "c_devportal_download": (ctypes.c_byte, [ctypes.c_void_p, ctypes.c_uint, ctypes.c_void_p, ctypes.c_uint, ctypes.c_void_p, ctypes.c_uint, ctypes.POINTER(ctypes.c_void_p), ctypes.POINTER(ctypes.c_uint)]),
+    "c_devportal_free": (None, [ctypes.c_void_p, ctypes.c_uint]),
+    "c_libipsw_call": (ctypes.c_byte, [ctypes.c_void_p, ctypes.c_uint, ctypes.c_void_p, ctypes.c_uint, ctypes.c_void_p, ctypes.c_uint, ctypes.POINTER(ctypes.c_void_p), ctypes.POINTER(ctypes.c_uint), ctypes.POINTER(ctypes.c_void_p), ctypes.POINTER(ctypes.c_uint)]),
+    "c_internal_download_Download": (ctypes.c_byte, [ctypes.c_void_p, ctypes.c_uint, ctypes.c_void_p, ctypes.c_uint, ctypes.c_void_p, ctypes.c_uint, ctypes.c_void_p, ctypes.c_uint, ctypes.c_void_p, ctypes.c_uint, ctypes.c_byte, ctypes.POINTER(ctypes.c_void_p), ctypes.POINTER(ctypes.c_uint), ctypes.POINTER(ctypes.c_void_p), ctypes.POINTER(ctypes.c_uint)]),
+    "c_internal_download_DownloadWithHandle": (ctypes.c_byte, [ctypes.c_void_p, ctypes.c_uint, ctypes.c_void_p, ctypes.c_uint, ctypes.c_void_p, ctypes.c_uint, ctypes.c_void_p, ctypes.c_uint, ctypes.c_void_p, ctypes.c_uint, ctypes.c_void_p, ctypes.c_uint, ctypes.c_byte, ctypes.POINTER(ctypes.c_void_p), ctypes.POINTER(ctypes.c_uint), ctypes.POINTER(ctypes.c_void_p), ctypes.POINTER(ctypes.c_uint)]),
+    "c_libipsw_error_code": (ctypes.c_int, [ctypes.c_void_p]),
+    "c_libipsw_error_code_name": (ctypes.c_void_p, [ctypes.c_int]),
+    "c_libipsw_handle_new": (ctypes.c_byte, [ctypes.POINTER(ctypes.c_void_p), ctypes.POINTER(ctypes.c_uint)]),
+    "c_libipsw_cancel": (ctypes.c_byte, [ctypes.c_void_p, ctypes.c_uint, ctypes.POINTER(ctypes.c_void_p), ctypes.POINTER(ctypes.c_uint)]),
+    "c_libipsw_handle_trace": (ctypes.c_byte, [ctypes.c_void_p, ctypes.c_uint, ctypes.POINTER(ctypes.c_void_p), ctypes.POINTER(ctypes.c_uint), ctypes.POINTER(ctypes.c_void_p), ctypes.POINTER(ctypes.c_uint)]),
+    "c_libipsw_handle_free": (None, [ctypes.c_void_p, ctypes.c_uint]),
+    "c_internal_download_ipsw_me_GetDeviceWithHandle": (ctypes.c_byte, [ctypes.c_void_p, ctypes.c_uint, ctypes.c_void_p, ctypes.c_uint, ctypes.POINTER(ctypes.c_void_p), ctypes.POINTER(ctypes.c_uint), ctypes.POINTER(ctypes.c_void_p), ctypes.POINTER(ctypes.c_uint)]),
+    "c_internal_download_iphonewiki_GetWikiIPSWs": (ctypes.c_byte, [ctypes.c_void_p, ctypes.c_int, ctypes.c_void_p, ctypes.c_int, ctypes.c_byte, ctypes.POINTER(ctypes.c_void_p), ctypes.POINTER(ctypes.c_int), ctypes.POINTER(ctypes.c_void_p), ctypes.POINTER(ctypes.c_uint)]),
+    "c_internal_download_ipsw_me_GetAllDevices": (ctypes.c_byte, [ctypes.POINTER(ctypes.c_void_p), ctypes.POINTER(ctypes.c_uint), ctypes.POINTER(ctypes.c_void_p), ctypes.POINTER(ctypes.c_uint)]),
+    "c_internal_download_ipsw_me_GetDevice": (ctypes.c_byte, [ctypes.c_void_p, ctypes.c_uint, ctypes.POINTER(ctypes.c_void_p), ctypes.POINTER(ctypes.c_uint), ctypes.POINTER(ctypes.c_void_p), ctypes.POINTER(ctypes.c_uint)]),
+    "c_internal_download_ipsw_me_GetDeviceIPSWs": (ctypes.c_byte, [ctypes.c_void_p, ctypes.c_uint, ctypes.POINTER(ctypes.c_void_p), ctypes.POINTER(ctypes.c_uint), ctypes.POINTER(ctypes.c_void_p), ctypes.POINTER(ctypes.c_uint)]),
+    "c_internal_download_ipsw_me_GetDeviceOTAs": (ctypes.c_byte, [ctypes.c_void_p, ctypes.c_uint, ctypes.POINTER(ctypes.c_void_p), ctypes.POINTER(ctypes.c_uint), ctypes.POINTER(ctypes.c_void_p), ctypes.POINTER(ctypes.c_uint)]),
+    "c_internal_download_ipsw_me_GetAllIPSW": (ctypes.c_byte, [ctypes.c_void_p, ctypes.c_uint, ctypes.POINTER(ctypes.c_void_p), ctypes.POINTER(ctypes.c_uint), ctypes.POINTER(ctypes.c_void_p), ctypes.POINTER(ctypes.c_uint)]),
+    "c_internal_download_ipsw_me_GetIPSW": (ctypes.c_byte, [ctypes.c_void_p, ctypes.c_uint, ctypes.c_void_p, ctypes.c_uint, ctypes.POINTER(ctypes.c_void_p), ctypes.POINTER(ctypes.c_uint), ctypes.POINTER(ctypes.c_void_p), ctypes.POINTER(ctypes.c_uint)]),
+    "c_internal_download_ipsw_me_GetVersion": (ctypes.c_byte, [ctypes.c_void_p, ctypes.c_uint, ctypes.POINTER(ctypes.c_void_p), ctypes.POINTER(ctypes.c_uint), ctypes.POINTER(ctypes.c_void_p), ctypes.POINTER(ctypes.c_uint)]),
+    "c_internal_download_ipsw_me_GetBuildID": (ctypes.c_byte, [ctypes.c_void_p, ctypes.c_uint, ctypes.c_void_p, ctypes.c_uint, ctypes.POINTER(ctypes.c_void_p), ctypes.POINTER(ctypes.c_uint), ctypes.POINTER(ctypes.c_void_p), ctypes.POINTER(ctypes.c_uint)]),
+    "c_download_start": (ctypes.c_byte, [ctypes.c_void_p, ctypes.c_uint, ctypes.c_void_p, ctypes.c_uint, ctypes.c_void_p, ctypes.c_uint, ctypes.c_void_p, ctypes.c_uint, ctypes.c_void_p, ctypes.c_uint, ctypes.c_byte, ctypes.POINTER(ctypes.c_void_p), ctypes.POINTER(ctypes.c_uint), ctypes.POINTER(ctypes.c_void_p), ctypes.POINTER(ctypes.c_uint)]),
+    "c_download_status": (ctypes.c_byte, [ctypes.c_void_p, ctypes.c_uint, ctypes.POINTER(ctypes.c_void_p), ctypes.POINTER(ctypes.c_uint), ctypes.POINTER(ctypes.c_void_p), ctypes.POINTER(ctypes.c_uint)]),
+    "c_download_cancel": (ctypes.c_byte, [ctypes.c_void_p, ctypes.c_uint, ctypes.POINTER(ctypes.c_void_p), ctypes.POINTER(ctypes.c_uint)]),
+    "c_download_free": (None, [ctypes.c_void_p, ctypes.c_uint]),
+    "c_libipsw_set_log_callback": (None, [ctypes.c_void_p, ctypes.c_int, ctypes.c_void_p]),
+    "c_internal_download_manager_Add": (ctypes.c_byte, [ctypes.c_void_p, ctypes.c_uint, ctypes.c_void_p, ctypes.c_uint, ctypes.c_void_p, ctypes.c_uint, ctypes.c_void_p, ctypes.c_uint, ctypes.c_byte, ctypes.POINTER(ctypes.c_void_p), ctypes.POINTER(ctypes.c_uint), ctypes.POINTER(ctypes.c_void_p), ctypes.POINTER(ctypes.c_uint)]),
+    "c_internal_download_manager_Pause": (ctypes.c_byte, [ctypes.c_void_p, ctypes.c_uint, ctypes.POINTER(ctypes.c_void_p), ctypes.POINTER(ctypes.c_uint)]),
+    "c_internal_download_manager_Resume": (ctypes.c_byte, [ctypes.c_void_p, ctypes.c_uint, ctypes.POINTER(ctypes.c_void_p), ctypes.POINTER(ctypes.c_uint)]),
+    "c_internal_download_manager_SetProgressCallback": (ctypes.c_byte, [ctypes.c_void_p, ctypes.c_uint, ctypes.c_void_p, ctypes.c_void_p, ctypes.POINTER(ctypes.c_void_p), ctypes.POINTER(ctypes.c_uint)]),
+    "c_internal_download_manager_Cancel": (ctypes.c_byte, [ctypes.c_void_p, ctypes.c_uint, ctypes.POINTER(ctypes.c_void_p), ctypes.POINTER(ctypes.c_uint)]),
+    "c_internal_download_manager_Status": (ctypes.c_byte, [ctypes.c_void_p, ctypes.c_uint, ctypes.POINTER(ctypes.c_void_p), ctypes.POINTER(ctypes.c_uint), ctypes.POINTER(ctypes.c_void_p), ctypes.POINTER(ctypes.c_uint)]),
+    "c_internal_download_manager_List": (ctypes.c_byte, [ctypes.POINTER(ctypes.c_void_p), ctypes.POINTER(ctypes.c_uint), ctypes.POINTER(ctypes.c_void_p), ctypes.POINTER(ctypes.c_uint)]),
+    "c_libipsw_free_string": (None, [ctypes.c_void_p]),
+    "c_libipsw_free_buffer": (None, [ctypes.c_void_p]),
+    "c_libipsw_result_size": (ctypes.c_byte, [ctypes.c_void_p, ctypes.c_uint, ctypes.POINTER(ctypes.c_ulonglong), ctypes.POINTER(ctypes.c_void_p), ctypes.POINTER(ctypes.c_uint)]),
+    "c_libipsw_result_read": (ctypes.c_byte, [ctypes.c_void_p, ctypes.c_uint, ctypes.c_void_p, ctypes.c_uint, ctypes.POINTER(ctypes.c_uint), ctypes.POINTER(ctypes.c_void_p), ctypes.POINTER(ctypes.c_uint)]),
+    "c_libipsw_result_free": (None, [ctypes.c_void_p, ctypes.c_uint]),
+    "c_internal_download_ipsw_me_GetAllDevicesResult": (ctypes.c_byte, [ctypes.POINTER(ctypes.c_void_p), ctypes.POINTER(ctypes.c_uint), ctypes.POINTER(ctypes.c_ulonglong), ctypes.POINTER(ctypes.c_void_p), ctypes.POINTER(ctypes.c_uint)]),
+    "c_internal_download_ipsw_me_GetDeviceIPSWsResult": (ctypes.c_byte, [ctypes.c_void_p, ctypes.c_uint, ctypes.POINTER(ctypes.c_void_p), ctypes.POINTER(ctypes.c_uint), ctypes.POINTER(ctypes.c_ulonglong), ctypes.POINTER(ctypes.c_void_p), ctypes.POINTER(ctypes.c_uint)]),
+    "c_internal_download_ipsw_me_GetAllIPSWResult": (ctypes.c_byte, [ctypes.c_void_p, ctypes.c_uint, ctypes.POINTER(ctypes.c_void_p), ctypes.POINTER(ctypes.c_uint), ctypes.POINTER(ctypes.c_ulonglong), ctypes.POINTER(ctypes.c_void_p), ctypes.POINTER(ctypes.c_uint)]),
+    "c_internal_download_iphonewiki_GetWikiIPSWsResult": (ctypes.c_byte, [ctypes.c_void_p, ctypes.c_int, ctypes.c_void_p, ctypes.c_int, ctypes.c_byte, ctypes.POINTER(ctypes.c_void_p), ctypes.POINTER(ctypes.c_uint), ctypes.POINTER(ctypes.c_ulonglong), ctypes.POINTER(ctypes.c_void_p), ctypes.POINTER(ctypes.c_uint)]),
+    "c_libipsw_version": (ctypes.c_byte, [ctypes.POINTER(ctypes.c_void_p), ctypes.POINTER(ctypes.c_uint), ctypes.POINTER(ctypes.c_void_p), ctypes.POINTER(ctypes.c_uint)]),
+    "c_libipsw_capabilities": (ctypes.c_byte, [ctypes.POINTER(ctypes.c_void_p), ctypes.POINTER(ctypes.c_uint), ctypes.POINTER(ctypes.c_void_p), ctypes.POINTER(ctypes.c_uint)]),
+    "c_pkg_xcode_xcode_GetDevices": (ctypes.c_byte, [ctypes.POINTER(ctypes.c_void_p), ctypes.POINTER(ctypes.c_uint), ctypes.POINTER(ctypes.c_void_p), ctypes.POINTER(ctypes.c_uint)]),
+    "c_pkg_xcode_xcode_GetDeviceForProd": (ctypes.c_byte, [ctypes.c_void_p, ctypes.c_uint, ctypes.POINTER(ctypes.c_void_p), ctypes.POINTER(ctypes.c_uint), ctypes.POINTER(ctypes.c_void_p), ctypes.POINTER(ctypes.c_uint)]),
+    "c_pkg_xcode_xcode_GetDeviceForModel": (ctypes.c_byte, [ctypes.c_void_p, ctypes.c_uint, ctypes.POINTER(ctypes.c_void_p), ctypes.POINTER(ctypes.c_uint), ctypes.POINTER(ctypes.c_void_p), ctypes.POINTER(ctypes.c_uint)]),
+    "c_pkg_xcode_xcode_GetDeviceForBoard": (ctypes.c_byte, [ctypes.c_void_p, ctypes.c_uint, ctypes.POINTER(ctypes.c_void_p), ctypes.POINTER(ctypes.c_uint), ctypes.POINTER(ctypes.c_void_p), ctypes.POINTER(ctypes.c_uint)]),
+    "c_pkg_xcode_xcode_GetDevicesForIdiom": (ctypes.c_byte, [ctypes.c_void_p, ctypes.c_uint, ctypes.POINTER(ctypes.c_void_p), ctypes.POINTER(ctypes.c_uint), ctypes.POINTER(ctypes.c_void_p), ctypes.POINTER(ctypes.c_uint)]),
+    "c_pkg_xcode_xcode_GetDevicesForChip": (ctypes.c_byte, [ctypes.c_void_p, ctypes.c_uint, ctypes.POINTER(ctypes.c_void_p), ctypes.POINTER(ctypes.c_uint), ctypes.POINTER(ctypes.c_void_p), ctypes.POINTER(ctypes.c_uint)]),
+    "libipsw_init": (ctypes.c_byte, []),
+    "libipsw_shutdown": (None, []),
+    "libipsw_version": (ctypes.c_void_p, []),
+}
+
+
+def configure(lib: ctypes.CDLL) -> None:
+    """sets the prototypes of the exports on the loaded library"""
+    for name, (restype, argtypes) in PROTOTYPES.items():
+        fn = getattr(lib, name)
+        fn.restype = restype
+        fn.argtypes = argtypes
+
+
+def _list(decode: Callable[[Any], Any]) -> Callable[[Any], Any]:
+    return lambda v: None if v is None else [decode(x) for x in v]
+
+
+def _map(decode: Callable[[Any], Any]) -> Callable[[Any], Any]:
+    return lambda v: None if v is None else {k: decode(x) for k, x in v.items()}
+
+
+def _encode(v: Any) -> Any:
+    if isinstance(v, _Struct):
+        return v._encode()
+    if isinstance(v, (list, tuple)):
+        return [_encode(x) for x in v]
+    if isinstance(v, dict):
+        return {k: _encode(x) for k, x in v.items()}
+    return v
+
+
+class _Struct:
+    """base of the JSON types (_fields are the (attribute, JSON key, decoder, omitempty) of the fields)"""
+
+    _fields: Tuple[Tuple[str, str, Optional[Callable[[Any], Any]], bool], ...] = ()
+
+    @classmethod
+    def _decode(cls, d: Optional[Dict[str, Any]]) -> Any:
+        if d is None:
+            return None
+        kwargs = {}
+        for attr, key, decode, _ in cls._fields:
+            v = d.get(key)
+            kwargs[attr] = decode(v) if decode is not None and v is not None else v
+        return cls(**kwargs)
+
+    def _encode(self) -> Dict[str, Any]:
+        out = {}
+        for attr, key, _, omitempty in self._fields:
+            v = _encode(getattr(self, attr))
+            if v is None or (omitempty and v in ("", 0, False, [], {})):
+                continue
+            out[key] = v
+        return out
+
+
+@dataclass
+class DevSessionConfig(_Struct):
+    """download.DevSessionConfig"""
+
+    proxy: Optional[str] = None
+    insecure: Optional[bool] = None
+    config_dir: Optional[str] = None
+    vault_password: Optional[str] = None
+    keyring_backend: Optional[str] = None
+    account: Optional[str] = None
+    trust_token: Optional[str] = None
+    cache_ttl: Optional[str] = None
+    refresh_cache: Optional[bool] = None
+    skip_all: Optional[bool] = None
+    resume_all: Optional[bool] = None
+    restart_all: Optional[bool] = None
+    remove_commas: Optional[bool] = None
+    prefer_sms: Optional[bool] = None
+
+
+@dataclass
+class DevDownloads(_Struct):
+    """download.DevDownloads"""
+
+    os: Optional[Dict[str, List['DevDownload']]] = None
+    more: Optional[List['MoreDownload']] = None
+
+
+@dataclass
+class DevDownload(_Struct):
+    """download.DevDownload"""
+
+    title: Optional[str] = None
+    build: Optional[str] = None
+    url: Optional[str] = None
+    type: Optional[str] = None
+
+
+@dataclass
+class MoreDownload(_Struct):
+    """download.MoreDownload"""
+
+    name: Optional[str] = None
+    description: Optional[str] = None
+    is_released: Optional[int] = None
+    date_published: Optional[str] = None
+    date_created: Optional[str] = None
+    date_modified: Optional[str] = None
+    categories: Optional[List['Category']] = None
+    files: Optional[List['Dfile']] = None
+
+
+@dataclass
+class Category(_Struct):
+    """download.category"""
+
+    id: Optional[int] = None
+    name: Optional[str] = None
+    sort_order: Optional[int] = None
+
+
+@dataclass
+class Dfile(_Struct):
+    """download.dfile"""
+
+    filename: Optional[str] = None
+    display_name: Optional[str] = None
+    remote_path: Optional[str] = None
+    file_size: Optional[int] = None
+    sort_order: Optional[int] = None
+    date_created: Optional[str] = None
+    date_modified: Optional[str] = None
+    file_format: Optional['Fformat'] = None
+
+
+@dataclass
+class Fformat(_Struct):
+    """download.fformat"""
+
+    extension: Optional[str] = None
+    description: Optional[str] = None
+
+
+@dataclass
+class Stats(_Struct):
+    """download.Stats"""
+
+    url: Optional[str] = None
+    host: Optional[str] = None
+    dest: Optional[str] = None
+    state: Optional[str] = None
+    error: Optional[str] = None
+    started: Optional[str] = None
+    duration: Optional[int] = None
+    active: Optional[int] = None
+    bytes: Optional[int] = None
+    resumed_bytes: Optional[int] = None
+    retries: Optional[int] = None
+    avg_speed: Optional[float] = None
+    peak_speed: Optional[float] = None
+    verification: Optional['Verification'] = None
+    trace: Optional[str] = None
+
+
+@dataclass
+class Verification(_Struct):
+    """download.Verification"""
+
+    algorithm: Optional[str] = None
+    expected: Optional[str] = None
+    actual: Optional[str] = None
+    verified: Optional[bool] = None
+    attempts: Optional[int] = None
+
+
+@dataclass
+class Device(_Struct):
+    """download.Device"""
+
+    name: Optional[str] = None
+    identifier: Optional[str] = None
+    boardconfig: Optional[str] = None
+    platform: Optional[str] = None
+    cpid: Optional[int] = None
+    bdid: Optional[int] = None
+    firmwares: Optional[List['IPSW']] = None
+
+
+@dataclass
+class IPSW(_Struct):
+    """download.IPSW"""
+
+    identifier: Optional[str] = None
+    version: Optional[str] = None
+    buildid: Optional[str] = None
+    sha1sum: Optional[str] = None
+    md5sum: Optional[str] = None
+    filesize: Optional[int] = None
+    url: Optional[str] = None
+    releasedate: Optional[str] = None
+    uploaddate: Optional[str] = None
+    signed: Optional[bool] = None
+
+
+@dataclass
+class WikiConfig(_Struct):
+    """download.WikiConfig"""
+
+    device: Optional[str] = None
+    version: Optional[str] = None
+    build: Optional[str] = None
+    ipsw: Optional[bool] = None
+    ota: Optional[bool] = None
+    beta: Optional[bool] = None
+
+
+@dataclass
+class WikiFirmware(_Struct):
+    """download.WikiFirmware"""
+
+    version: Optional[str] = None
+    version_extra: Optional[str] = None
+    prerequisite_version: Optional[str] = None
+    build: Optional[str] = None
+    prerequisite_build: Optional[str] = None
+    product: Optional[str] = None
+    board_id: Optional[str] = None
+    keys: Optional[List[str]] = None
+    baseband: Optional[str] = None
+    release_date: Optional[str] = None
+    url: Optional[str] = None
+    sha1: Optional[str] = None
+    file_size: Optional[int] = None
+    doc: Optional[List[str]] = None
+
+
+@dataclass
+class DownloadStatus(_Struct):
+    """download.DownloadStatus"""
+
+    id: Optional[str] = None
+    url: Optional[str] = None
+    dest: Optional[str] = None
+    state: Optional[str] = None
+    size: Optional[int] = None
+    downloaded: Optional[int] = None
+    error: Optional[str] = None
+    stats: Optional['Stats'] = None
+
+
+@dataclass
+class BuildInfo(_Struct):
+    """download.BuildInfo"""
+
+    version: Optional[str] = None
+    c_api_version: Optional[str] = None
+    commit: Optional[str] = None
+    modified: Optional[bool] = None
+    go_version: Optional[str] = None
+    capabilities: Optional['Capabilities'] = None
+
+
+@dataclass
+class Capabilities(_Struct):
+    """download.Capabilities"""
+
+    sources: Optional[List[str]] = None
+    cgo: Optional[bool] = None
+    wasm: Optional[bool] = None
+    os: Optional[str] = None
+    arch: Optional[str] = None
+
+
+@dataclass
+class XcodeDevice(_Struct):
+    """xcode.Device"""
+
+    target: Optional[str] = None
+    target_type: Optional[str] = None
+    target_variant: Optional[str] = None
+    platform: Optional[str] = None
+    product_type: Optional[str] = None
+    product_description: Optional[str] = None
+    compatible_device_fallback: Optional[str] = None
+    traits: Optional['XcodeDeviceTrait'] = None
+
+
+@dataclass
+class XcodeDeviceTrait(_Struct):
+    """xcode.DeviceTrait"""
+
+    preferred_architecture: Optional[str] = None
+    artwork_device_idiom: Optional[str] = None
+    artwork_hosted_idioms: Optional[str] = None
+    artwork_scale_factor: Optional[int] = None
+    artwork_device_subtype: Optional[int] = None
+    artwork_display_gamut: Optional[str] = None
+    artwork_dynamic_display_mode: Optional[str] = None
+    device_performance_memory_class: Optional[int] = None
+    graphics_feature_set_class: Optional[str] = None
+    graphics_feature_set_fallbacks: Optional[str] = None
+
+
+DevSessionConfig._fields = (
+    ("proxy", "proxy", None, True),
+    ("insecure", "insecure", None, True),
+    ("config_dir", "config_dir", None, True),
+    ("vault_password", "vault_password", None, True),
+    ("keyring_backend", "keyring_backend", None, True),
+    ("account", "account", None, True),
+    ("trust_token", "trust_token", None, True),
+    ("cache_ttl", "cache_ttl", None, True),
+    ("refresh_cache", "refresh_cache", None, True),
+    ("skip_all", "skip_all", None, True),
+    ("resume_all", "resume_all", None, True),
+    ("restart_all", "restart_all", None, True),
+    ("remove_commas", "remove_commas", None, True),
+    ("prefer_sms", "prefer_sms", None, True),
+)
+
+DevDownloads._fields = (
+    ("os", "os", _map(_list(DevDownload._decode)), True),
+    ("more", "more", _list(MoreDownload._decode), True),
+)
+
+DevDownload._fields = (
+    ("title", "title", None, True),
+    ("build", "build", None, True),
+    ("url", "url", None, True),
+    ("type", "type", None, True),
+)
+
+MoreDownload._fields = (
+    ("name", "name", None, True),
+    ("description", "description", None, True),
+    ("is_released", "isReleased", None, True),
+    ("date_published", "datePublished", None, True),
+    ("date_created", "dateCreated", None, True),
+    ("date_modified", "dateModified", None, True),
+    ("categories", "categories", _list(Category._decode), True),
+    ("files", "files", _list(Dfile._decode), True),
+)
+
+Category._fields = (
+    ("id", "id", None, True),
+    ("name", "name", None, True),
+    ("sort_order", "sortOrder", None, True),
+)
+
+Dfile._fields = (
+    ("filename", "filename", None, True),
+    ("display_name", "displayName", None, True),
+    ("remote_path", "remotePath", None, True),
+    ("file_size", "fileSize", None, True),
+    ("sort_order", "sortOrder", None, True),
+    ("date_created", "dateCreated", None, True),
+    ("date_modified", "dateModified", None, True),
+    ("file_format", "fileFormat", Fformat._decode, True),
+)
+
+Fformat._fields = (
+    ("extension", "extension", None, True),
+    ("description", "description", None, True),
+)
+
+Stats._fields = (
+    ("url", "url", None, True),
+    ("host", "host", None, True),
+    ("dest", "dest", None, True),
+    ("state", "state", None, True),
+    ("error", "error", None, True),
+    ("started", "started", None, True),
+    ("duration", "duration", None, True),
+    ("active", "active", None, True),
+    ("bytes", "bytes", None, False),
+    ("resumed_bytes", "resumed_bytes", None, False),
+    ("retries", "retries", None, False),
+    ("avg_speed", "avg_speed", None, False),
+    ("peak_speed", "peak_speed", None, False),
+    ("verification", "verification", Verification._decode, True),
+    ("trace", "trace", None, True),
+)
+
+Verification._fields = (
+    ("algorithm", "algorithm", None, False),
+    ("expected", "expected", None, False),
+    ("actual", "actual", None, False),
+    ("verified", "verified", None, False),
+    ("attempts", "attempts", None, False),
+)
+
+Device._fields = (
+    ("name", "name", None, True),
+    ("identifier", "identifier", None, True),
+    ("boardconfig", "boardconfig", None, True),
+    ("platform", "platform", None, True),
+    ("cpid", "cpid", None, True),
+    ("bdid", "bdid", None, True),
+    ("firmwares", "firmwares", _list(IPSW._decode), True),
+)
+
+IPSW._fields = (
+    ("identifier", "identifier", None, True),
+    ("version", "version", None, True),
+    ("buildid", "buildid", None, True),
+    ("sha1sum", "sha1sum", None, True),
+    ("md5sum", "md5sum", None, True),
+    ("filesize", "filesize", None, True),
+    ("url", "url", None, True),
+    ("releasedate", "releasedate", None, True),
+    ("uploaddate", "uploaddate", None, True),
+    ("signed", "signed", None, True),
+)
+
+WikiConfig._fields = (
+    ("device", "Device", None, False),
+    ("version", "Version", None, False),
+    ("build", "Build", None, False),
+    ("ipsw", "IPSW", None, False),
+    ("ota", "OTA", None, False),
+    ("beta", "Beta", None, False),
+)
+
+WikiFirmware._fields = (
+    ("version", "version", None, True),
+    ("version_extra", "version_extra", None, True),
+    ("prerequisite_version", "prerequisite_version", None, True),
+    ("build", "build", None, True),
+    ("prerequisite_build", "prerequisite_build", None, True),
+    ("product", "product", None, True),
+    ("board_id", "board_id", None, True),
+    ("keys", "keys", None, True),
+    ("baseband", "baseband", None, True),
+    ("release_date", "release_date", None, True),
+    ("url", "url", None, True),
+    ("sha1", "sha1", None, True),
+    ("file_size", "file_size", None, True),
+    ("doc", "doc", None, True),
+)
+
+DownloadStatus._fields = (
+    ("id", "id", None, True),
+    ("url", "url", None, True),
+    ("dest", "dest", None, True),
+    ("state", "state", None, True),
+    ("size", "size", None, True),
+    ("downloaded", "downloaded", None, True),
+    ("error", "error", None, True),
+    ("stats", "stats", Stats._decode, True),
+)
+
+BuildInfo._fields = (
+    ("version", "version", None, False),
+    ("c_api_version", "c_api_version", None, False),
+    ("commit", "commit", None, True),
+    ("modified", "modified", None, True),
+    ("go_version", "go_version", None, False),
+    ("capabilities", "capabilities", Capabilities._decode, False),
+)
+
+Capabilities._fields = (
+    ("sources", "sources", None, False),
+    ("cgo", "cgo", None, False),
+    ("wasm", "wasm", None, False),
+    ("os", "os", None, False),
+    ("arch", "arch", None, False),
+)
+
+XcodeDevice._fields = (
+    ("target", "target", None, True),
+    ("target_type", "target_type", None, True),
+    ("target_variant", "target_variant", None, True),
+    ("platform", "platform", None, True),
+    ("product_type", "product_type", None, True),
+    ("product_description", "product_description", None, True),
+    ("compatible_device_fallback", "compatible_device_fallback", None, True),
+    ("traits", "traits", XcodeDeviceTrait._decode, True),
+)
+
+XcodeDeviceTrait._fields = (
+    ("preferred_architecture", "preferred_architecture", None, True),
+    ("artwork_device_idiom", "artwork_device_idiom", None, True),
+    ("artwork_hosted_idioms", "artwork_hosted_idioms", None, True),
+    ("artwork_scale_factor", "artwork_scale_factor", None, True),
+    ("artwork_device_subtype", "artwork_device_subtype", None, True),
+    ("artwork_display_gamut", "artwork_display_gamut", None, True),
+    ("artwork_dynamic_display_mode", "artwork_dynamic_display_mode", None, True),
+    ("device_performance_memory_class", "device_performance_memory_class", None, True),
+    ("graphics_feature_set_class", "graphics_feature_set_class", None, True),
+    ("graphics_feature_set_fallbacks", "graphics_feature_set_fallbacks", None, True),
+)
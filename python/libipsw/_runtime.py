@@ -0,0 +1,151 @@
+"""Loading of the libipsw shared library and the helpers of the generated wrappers"""
+
+import atexit
+import ctypes
+import ctypes.util
+import json
+import os
+import sys
+import threading
+from pathlib import Path
+from typing import Any, List, Optional
+
+from . import _ffi
+from .errors import error_for_code
+
+_lib: Optional[ctypes.CDLL] = None
+_lock = threading.Lock()
+
+
+def _library_name() -> str:
+    if sys.platform == "darwin":
+        return "libipsw.dylib"
+    if sys.platform == "win32":
+        return "libipsw.dll"
+    return "libipsw.so"
+
+
+def _candidates() -> List[str]:
+    """the paths the library is loaded from: $LIBIPSW_PATH, the dist/libipsw folder of `make build-libipsw`
+    (in a source checkout) and the system library paths"""
+    paths = []
+    if os.environ.get("LIBIPSW_PATH"):
+        paths.append(os.environ["LIBIPSW_PATH"])
+    paths.append(str(Path(__file__).resolve().parents[2] / "dist" / "libipsw" / _library_name()))
+    found = ctypes.util.find_library("ipsw")
+    if found:
+        paths.append(found)
+    return paths
+
+
+def _check_version(loaded: ctypes.CDLL, path: str) -> None:
+    """raises OSError unless the library implements the C API this binding was generated from
+    (the same major version and at least its minor version)"""
+    loaded.libipsw_version.restype = ctypes.c_void_p
+    loaded.libipsw_version.argtypes = []
+    ptr = loaded.libipsw_version()
+    try:
+        have = ctypes.string_at(ptr).decode()
+    finally:
+        loaded.c_libipsw_free_string.argtypes = [ctypes.c_void_p]
+        loaded.c_libipsw_free_string(ptr)
+    want = _ffi.VERSION.split(".")
+    got = have.split(".")
+    if got[0] != want[0] or int(got[1]) < int(want[1]):
+        raise OSError(f"{path} implements the C API {have} but this binding needs {_ffi.VERSION} (rebuild it with `make build-libipsw`)")
+
+
+def lib() -> ctypes.CDLL:
+    """returns the initialized library (it is loaded and libipsw_init is called on first use)"""
+    global _lib
+    if _lib is not None:
+        return _lib
+    with _lock:
+        if _lib is None:
+            errors = []
+            for path in _candidates():
+                try:
+                    loaded = ctypes.CDLL(path)
+                except OSError as e:
+                    errors.append(f"{path}: {e}")
+                    continue
+                _check_version(loaded, path)
+                _ffi.configure(loaded)
+                if not loaded.libipsw_init():
+                    raise OSError(f"failed to initialize {path}")
+                atexit.register(loaded.libipsw_shutdown)
+                _lib = loaded
+                break
+            else:
+                raise OSError("libipsw not found (build it with `make build-libipsw` or set LIBIPSW_PATH): " + "; ".join(errors))
+    return _lib
+
+
+def to_bytes(s: Optional[str]) -> bytes:
+    if s is None:
+        return b""
+    if isinstance(s, bytes):
+        return s
+    return str(s).encode()
+
+
+def encode_json(v: Any) -> bytes:
+    """serializes a JSON argument (a generated dataclass or a dict; None is an empty argument)"""
+    if v is None:
+        return b""
+    return json.dumps(_ffi._encode(v)).encode()
+
+
+def take_string(ptr: ctypes.c_void_p, length: Any) -> str:
+    """returns a string returned by the library and frees it"""
+    if not ptr.value:
+        return ""
+    try:
+        return ctypes.string_at(ptr.value, length.value).decode()
+    finally:
+        lib().c_libipsw_free_string(ptr)
+
+
+def take_json(ptr: ctypes.c_void_p, length: Any) -> Any:
+    """returns the decoded JSON string returned by the library and frees it"""
+    return json.loads(take_string(ptr, length) or "null")
+
+
+def take_result(ptr: ctypes.c_void_p, length: Any) -> Any:
+    """reads, decodes and releases the JSON document of a result ID"""
+    result = to_bytes(take_string(ptr, length))
+    l = lib()
+    try:
+        chunks = []
+        buf = ctypes.create_string_buffer(1 << 20)
+        n = ctypes.c_uint()
+        err, err_len = ctypes.c_void_p(), ctypes.c_uint()
+        while True:
+            if not l.c_libipsw_result_read(result, len(result), buf, len(buf), ctypes.byref(n), ctypes.byref(err), ctypes.byref(err_len)):
+                raise_error(err, err_len)
+            if n.value == 0:
+                break
+            chunks.append(buf.raw[: n.value])
+        return json.loads(b"".join(chunks) or b"null")
+    finally:
+        l.c_libipsw_result_free(result, len(result))
+
+
+def raise_error(err: ctypes.c_void_p, err_len: Any) -> None:
+    """raises the exception of an error returned by the library (and frees its message)"""
+    code = lib().c_libipsw_error_code(err)  # must be read before the message is freed
+    raise error_for_code(code, take_string(err, err_len))
+
+
+def new_handle() -> str:
+    handle, handle_len = ctypes.c_void_p(), ctypes.c_uint()
+    lib().c_libipsw_handle_new(ctypes.byref(handle), ctypes.byref(handle_len))
+    return take_string(handle, handle_len)
+
+
+def version() -> str:
+    ptr = ctypes.c_void_p(lib().libipsw_version())
+    try:
+        return ctypes.string_at(ptr.value).decode()
+    finally:
+        lib().c_libipsw_free_string(ptr)
@@ -0,0 +1,76 @@
+"""Exceptions raised for the errors of the libipsw C API (by their c_libipsw_error_code)"""
+
+from typing import Dict, Optional, Type
+
+from ._ffi import ErrorCode
+
+
+class LibipswError(Exception):
+    """an error returned by libipsw (code is its ErrorCode)"""
+
+    code = ErrorCode.UNKNOWN
+
+    def __init__(self, message: str, code: Optional[int] = None):
+        super().__init__(message)
+        if code is not None:
+            try:
+                self.code = ErrorCode(code)
+            except ValueError:  # a code added after this binding was generated
+                self.code = ErrorCode.UNKNOWN
+
+
+class InvalidArgumentError(LibipswError, ValueError):
+    code = ErrorCode.INVALID_ARG
+
+
+class NotFoundError(LibipswError, LookupError):
+    code = ErrorCode.NOT_FOUND
+
+
+class NetworkError(LibipswError, ConnectionError):
+    code = ErrorCode.NETWORK
+
+
+class ParseError(LibipswError):
+    code = ErrorCode.PARSE
+
+
+class AuthError(LibipswError, PermissionError):
+    code = ErrorCode.AUTH
+
+
+class CanceledError(LibipswError):
+    code = ErrorCode.CANCELED
+
+
+class DeadlineExceededError(LibipswError, TimeoutError):
+    code = ErrorCode.TIMEOUT
+
+
+class ChecksumError(LibipswError):
+    code = ErrorCode.CHECKSUM
+
+
+class UnsupportedError(LibipswError, NotImplementedError):
+    code = ErrorCode.UNSUPPORTED
+
+
+_ERRORS: Dict[int, Type[LibipswError]] = {
+    cls.code: cls
+    for cls in (
+        InvalidArgumentError,
+        NotFoundError,
+        NetworkError,
+        ParseError,
+        AuthError,
+        CanceledError,
+        DeadlineExceededError,
+        ChecksumError,
+        UnsupportedError,
+    )
+}
+
+
+def error_for_code(code: int, message: str) -> LibipswError:
+    """returns the exception of an error code"""
+    return _ERRORS.get(code, LibipswError)(message, code)
@@ -0,0 +1,91 @@
+import unittest
+
+import libipsw
+from libipsw import _ffi
+
+
+def _load():
+    try:
+        libipsw._runtime.lib()
+        return None
+    except OSError as e:
+        return str(e)
+
+
+_missing = _load()
+
+
+class TestTypes(unittest.TestCase):
+    def test_struct_roundtrip(self):
+        cfg = libipsw.WikiConfig(device="iPhone15,2", beta=True)
+        self.assertEqual(libipsw.WikiConfig._decode(cfg._encode()), cfg)
+
+    def test_nested_decode(self):
+        st = libipsw.DownloadStatus._decode({"id": "job-1", "state": "done", "stats": {"url": "https://example.com/a.ipsw"}})
+        self.assertEqual(st.id, "job-1")
+        self.assertIsInstance(st.stats, libipsw.Stats)
+
+    def test_error_for_code(self):
+        err = libipsw.errors.error_for_code(_ffi.ErrorCode.NOT_FOUND, "device not found")
+        self.assertIsInstance(err, libipsw.NotFoundError)
+        self.assertIsInstance(err, LookupError)
+        self.assertEqual(err.code, _ffi.ErrorCode.NOT_FOUND)
+        self.assertIsInstance(libipsw.errors.error_for_code(1000, "new"), libipsw.LibipswError)
+
+
+@unittest.skipIf(_missing, f"libipsw not available: {_missing}")
+class TestLibrary(unittest.TestCase):
+    def test_version(self):
+        self.assertEqual(libipsw.version(), libipsw.VERSION)
+        info = libipsw.libipsw_version()
+        self.assertIsInstance(info, libipsw.BuildInfo)
+        self.assertEqual(info.c_api_version, libipsw.VERSION)
+        caps = libipsw.libipsw_capabilities()
+        self.assertTrue(caps.cgo)
+        self.assertIn("ipsw.me", caps.sources)
+
+    def test_xcode_device(self):
+        dev = libipsw.xcode_get_device_for_prod("iPhone15,2")
+        self.assertIsInstance(dev, libipsw.XcodeDevice)
+        self.assertEqual(dev.product_type, "iPhone15,2")
+
+    def test_not_found(self):
+        with self.assertRaises(libipsw.NotFoundError):
+            libipsw.xcode_get_devices_for_chip("t0000")
+
+    def test_invalid_argument(self):
+        with self.assertRaises(libipsw.InvalidArgumentError):
+            libipsw.download_start("")
+        with self.assertRaises(libipsw.InvalidArgumentError):
+            libipsw.download_status("job-0")
+
+    def test_call(self):
+        self.assertIn("xcode.device", libipsw.call("libipsw.methods"))
+        dev = libipsw.call("xcode.device", {"product_type": "iPhone15,2"})
+        self.assertEqual(dev["product_type"], "iPhone15,2")
+        with self.assertRaises(libipsw.UnsupportedError):
+            libipsw.call("no.such_method")
+        with self.assertRaises(libipsw.InvalidArgumentError):
+            libipsw.call("ipsw_me.device")
+
+    def test_handle(self):
+        h = libipsw.new_handle()
+        try:
+            self.assertTrue(h.startswith("op-"))
+        finally:
+            libipsw.free_handle(h)
+
+    def test_log_callback(self):
+        entries = []
+        libipsw.set_log_callback(lambda level, msg, fields: entries.append((level, msg)), libipsw.LogLevel.DEBUG)
+        try:
+            libipsw.xcode_get_device_for_prod("iPhone15,2")
+        finally:
+            libipsw.set_log_callback(None)
+        for level, msg in entries:
+            self.assertIsInstance(level, libipsw.LogLevel)
+            self.assertIsInstance(msg, str)
+
+
+if __name__ == "__main__":
+    unittest.main()
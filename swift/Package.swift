@@ -0,0 +1,17 @@
+// swift-tools-version:5.7
+import PackageDescription
+
+// Swift wrapper of libipsw: build the library with `make build-libipsw` and pass its folder to the linker,
+// i.e. `swift build -Xlinker -L../dist/libipsw` (see README.md)
+let package = Package(
+    name: "Libipsw",
+    platforms: [.macOS(.v12), .iOS(.v15)],
+    products: [
+        .library(name: "Libipsw", targets: ["Libipsw"]),
+    ],
+    targets: [
+        .systemLibrary(name: "CLibipsw", path: "Sources/CLibipsw"),
+        .target(name: "Libipsw", dependencies: ["CLibipsw"]),
+        .testTarget(name: "LibipswTests", dependencies: ["Libipsw"]),
+    ]
+)
@@ -0,0 +1,7 @@
+#ifndef CLIBIPSW_SHIM_H
+#define CLIBIPSW_SHIM_H
+
+// the generated header of the library (cmd/libipsw/include/libipsw.h)
+#include "../../../cmd/libipsw/include/libipsw.h"
+
+#endif
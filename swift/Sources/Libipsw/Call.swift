@@ -0,0 +1,32 @@
+import CLibipsw
+import Foundation
+
+extension Libipsw {
+    /// Calls a library method by name (i.e. `ipsw_me.device`) with params encoded as a JSON object
+    /// and decodes its result (`methods()` lists the methods, an unknown one throws `.unsupported`)
+    public static func call<P: Encodable, T: Decodable>(_ method: String, params: P, as type: T.Type = T.self) throws -> T {
+        let data: Data
+        do {
+            data = try JSONEncoder().encode(params)
+        } catch {
+            throw LibipswError(code: .invalidArgument, message: "failed to encode \(method) params: \(error)")
+        }
+        return try callMethod(method, params: String(decoding: data, as: UTF8.self), as: type)
+    }
+
+    /// Calls a library method without parameters by name and decodes its result
+    public static func call<T: Decodable>(_ method: String, as type: T.Type = T.self) throws -> T {
+        try callMethod(method, params: "", as: type)
+    }
+
+    /// The names of the methods of `call`
+    public static func methods() throws -> [String] {
+        try call("libipsw.methods", as: [String].self)
+    }
+
+    private static func callMethod<T: Decodable>(_ method: String, params: String, as type: T.Type) throws -> T {
+        try withCArgs(["", method, params]) { a in
+            try callJSON(T.self) { c_libipsw_call(a[0].ptr, a[0].len, a[1].ptr, a[1].len, a[2].ptr, a[2].len, $0, $1, $2, $3) }
+        }
+    }
+}
@@ -0,0 +1,79 @@
+import CLibipsw
+import Foundation
+
+/// The options of a download
+public struct DownloadOptions: Sendable {
+    /// Expected checksums (optional; the download is verified against the strongest one)
+    public var sha1: String
+    public var sha256: String
+    /// HTTP/HTTPS proxy
+    public var proxy: String
+    /// Do not verify SSL certificates
+    public var insecure: Bool
+
+    public init(sha1: String = "", sha256: String = "", proxy: String = "", insecure: Bool = false) {
+        self.sha1 = sha1
+        self.sha256 = sha256
+        self.proxy = proxy
+        self.insecure = insecure
+    }
+}
+
+/// Downloads url to dest (the URL's file name in the current directory if empty), resuming a partial download;
+/// it blocks until the download is done (use `DownloadJob` to download in the background)
+public func download(_ url: String, to dest: String = "", options: DownloadOptions = DownloadOptions()) throws -> DownloadStats {
+    try withCArgs([url, dest, options.sha1, options.sha256, options.proxy]) { a in
+        try callJSON(DownloadStats.self) {
+            c_internal_download_Download(a[0].ptr, a[0].len, a[1].ptr, a[1].len, a[2].ptr, a[2].len, a[3].ptr, a[3].len, a[4].ptr, a[4].len, options.insecure.cChar, $0, $1, $2, $3)
+        }
+    }
+}
+
+/// A background download (the job is released when it is deinitialized; that does not cancel it)
+public final class DownloadJob: @unchecked Sendable {
+    /// The job handle
+    public let id: String
+
+    /// Starts downloading url to dest (the URL's file name in the current directory if empty)
+    public init(_ url: String, to dest: String = "", options: DownloadOptions = DownloadOptions()) throws {
+        id = try withCArgs([url, dest, options.sha1, options.sha256, options.proxy]) { a in
+            try callString {
+                c_download_start(a[0].ptr, a[0].len, a[1].ptr, a[1].len, a[2].ptr, a[2].len, a[3].ptr, a[3].len, a[4].ptr, a[4].len, options.insecure.cChar, $0, $1, $2, $3)
+            }
+        }
+    }
+
+    deinit {
+        withCArgs([id]) { a in c_download_free(a[0].ptr, a[0].len) }
+    }
+
+    /// Returns the status of the download
+    public func status() throws -> DownloadStatus {
+        try withCArgs([id]) { a in
+            try callJSON(DownloadStatus.self) { c_download_status(a[0].ptr, a[0].len, $0, $1, $2, $3) }
+        }
+    }
+
+    /// Cancels the download (canceling a finished download does nothing); it returns right away
+    public func cancel() throws {
+        try withCArgs([id]) { a in
+            try call { c_download_cancel(a[0].ptr, a[0].len, $0, $1) }
+        }
+    }
+
+    /// Waits for the download to finish (polling its status every interval nanoseconds) and returns its final status;
+    /// canceling the task cancels the download
+    public func wait(pollingEvery interval: UInt64 = 250_000_000) async throws -> DownloadStatus {
+        try await withTaskCancellationHandler {
+            while true {
+                let st = try status()
+                if let state = st.state, state.isFinished {
+                    return st
+                }
+                try await Task.sleep(nanoseconds: interval)
+            }
+        } onCancel: {
+            try? cancel()
+        }
+    }
+}
@@ -0,0 +1,58 @@
+import CLibipsw
+
+/// The ipsw.me API
+public enum IPSWMe {
+    /// Returns all the devices of ipsw.me (without their firmwares)
+    public static func devices() throws -> [Device] {
+        try callJSON([Device].self) { c_internal_download_ipsw_me_GetAllDevices($0, $1, $2, $3) }
+    }
+
+    /// Returns a device (i.e. iPhone16,1) with its IPSWs
+    public static func device(_ identifier: String) throws -> Device {
+        try withCArgs([identifier]) { a in
+            try callJSON(Device.self) { c_internal_download_ipsw_me_GetDevice(a[0].ptr, a[0].len, $0, $1, $2, $3) }
+        }
+    }
+
+    /// Returns the IPSWs of a device (i.e. iPhone16,1)
+    public static func ipsws(for identifier: String) throws -> [IPSW] {
+        try withCArgs([identifier]) { a in
+            try callJSON([IPSW].self) { c_internal_download_ipsw_me_GetDeviceIPSWs(a[0].ptr, a[0].len, $0, $1, $2, $3) }
+        }
+    }
+
+    /// Returns the OTAs of a device (i.e. iPhone16,1)
+    public static func otas(for identifier: String) throws -> [OTA] {
+        try withCArgs([identifier]) { a in
+            try callJSON([OTA].self) { c_internal_download_ipsw_me_GetDeviceOTAs(a[0].ptr, a[0].len, $0, $1, $2, $3) }
+        }
+    }
+
+    /// Returns the IPSWs of an iOS version (i.e. 17.0) for all devices
+    public static func ipsws(version: String) throws -> [IPSW] {
+        try withCArgs([version]) { a in
+            try callJSON([IPSW].self) { c_internal_download_ipsw_me_GetAllIPSW(a[0].ptr, a[0].len, $0, $1, $2, $3) }
+        }
+    }
+
+    /// Returns the IPSW of a device and build (i.e. iPhone16,1 and 21A329)
+    public static func ipsw(for identifier: String, build: String) throws -> IPSW {
+        try withCArgs([identifier, build]) { a in
+            try callJSON(IPSW.self) { c_internal_download_ipsw_me_GetIPSW(a[0].ptr, a[0].len, a[1].ptr, a[1].len, $0, $1, $2, $3) }
+        }
+    }
+
+    /// Returns the version of a build (i.e. 17.0 for 21A329)
+    public static func version(build: String) throws -> String {
+        try withCArgs([build]) { a in
+            try callJSON(String.self) { c_internal_download_ipsw_me_GetVersion(a[0].ptr, a[0].len, $0, $1, $2, $3) }
+        }
+    }
+
+    /// Returns the build of a version for a device (i.e. 21A329 for 17.0 and iPhone16,1)
+    public static func build(version: String, identifier: String) throws -> String {
+        try withCArgs([version, identifier]) { a in
+            try callJSON(String.self) { c_internal_download_ipsw_me_GetBuildID(a[0].ptr, a[0].len, a[1].ptr, a[1].len, $0, $1, $2, $3) }
+        }
+    }
+}
@@ -0,0 +1,151 @@
+import CLibipsw
+import Foundation
+
+/// The libipsw library (it is initialized on first use and all its functions are thread-safe)
+public enum Libipsw {
+    private static let initialized: Bool = libipsw_init() != 0
+
+    static func ensureInitialized() {
+        _ = initialized
+    }
+
+    /// The C API version of the loaded library
+    public static var version: String {
+        ensureInitialized()
+        guard let ptr = libipsw_version() else { return "" }
+        defer { c_libipsw_free_string(ptr) }
+        return String(cString: ptr)
+    }
+
+    /// The C API version this package was built against (`version` is the one of the library it is linked with)
+    public static let headerVersion: String = LIBIPSW_VERSION
+
+    /// The version, git commit and capabilities of the loaded library
+    public static func buildInfo() throws -> BuildInfo {
+        try callJSON(BuildInfo.self) { c_libipsw_version($0, $1, $2, $3) }
+    }
+
+    /// The sources and features of the loaded library
+    public static func capabilities() throws -> Capabilities {
+        try callJSON(Capabilities.self) { c_libipsw_capabilities($0, $1, $2, $3) }
+    }
+
+    /// Cancels the in-flight operations and downloads and releases the library's state; call it before the app exits
+    public static func shutdown() {
+        libipsw_shutdown()
+    }
+}
+
+/// The code of a `LibipswError` (the LIBIPSW_ERR_* constants of libipsw.h)
+public enum ErrorCode: Int32, Sendable {
+    case unknown = 1
+    case invalidArgument = 2
+    case notFound = 3
+    case network = 4
+    case parse = 5
+    case auth = 6
+    case canceled = 7
+    case timeout = 8
+    case checksum = 9
+    case unsupported = 10
+}
+
+/// An error returned by libipsw
+public struct LibipswError: Error, LocalizedError, CustomStringConvertible, Sendable {
+    public let code: ErrorCode
+    public let message: String
+
+    public init(code: ErrorCode, message: String) {
+        self.code = code
+        self.message = message
+    }
+
+    public var description: String { message }
+    public var errorDescription: String? { message }
+
+    /// takes the error message set by a failing call (its code must be read before it is freed)
+    static func take(_ err: UnsafeMutablePointer<CChar>?, _ errLen: CUnsignedInt) -> LibipswError {
+        let code = ErrorCode(rawValue: c_libipsw_error_code(err)) ?? .unknown
+        return LibipswError(code: code, message: takeString(err, errLen))
+    }
+}
+
+typealias COut = UnsafeMutablePointer<UnsafeMutablePointer<CChar>?>
+typealias COutLen = UnsafeMutablePointer<CUnsignedInt>
+typealias CArg = (ptr: UnsafeMutablePointer<CChar>, len: CUnsignedInt)
+
+/// passes strings to body as the (pointer, length) arguments of a C call
+func withCArgs<R>(_ strings: [String], _ body: ([CArg]) throws -> R) rethrows -> R {
+    let args: [CArg] = strings.map { s in
+        let ptr = strdup(s)!
+        return (ptr, CUnsignedInt(strlen(ptr)))
+    }
+    defer { args.forEach { free($0.ptr) } }
+    return try body(args)
+}
+
+/// returns a string returned by libipsw and frees it
+func takeString(_ ptr: UnsafeMutablePointer<CChar>?, _ len: CUnsignedInt) -> String {
+    guard let ptr else { return "" }
+    defer { c_libipsw_free_string(ptr) }
+    return String(decoding: UnsafeRawBufferPointer(start: ptr, count: Int(len)), as: UTF8.self)
+}
+
+/// calls a libipsw function without an output (fn is passed the err and errLen out-pointers)
+func call(_ fn: (COut, COutLen) -> CChar) throws {
+    Libipsw.ensureInitialized()
+    var err: UnsafeMutablePointer<CChar>?
+    var errLen: CUnsignedInt = 0
+    if fn(&err, &errLen) == 0 {
+        throw LibipswError.take(err, errLen)
+    }
+}
+
+/// calls a libipsw function returning a string (fn is passed the out, outLen, err and errLen out-pointers)
+func callString(_ fn: (COut, COutLen, COut, COutLen) -> CChar) throws -> String {
+    Libipsw.ensureInitialized()
+    var out: UnsafeMutablePointer<CChar>?
+    var outLen: CUnsignedInt = 0
+    var err: UnsafeMutablePointer<CChar>?
+    var errLen: CUnsignedInt = 0
+    if fn(&out, &outLen, &err, &errLen) == 0 {
+        throw LibipswError.take(err, errLen)
+    }
+    return takeString(out, outLen)
+}
+
+/// calls a libipsw function returning JSON and decodes it
+func callJSON<T: Decodable>(_ type: T.Type, _ fn: (COut, COutLen, COut, COutLen) -> CChar) throws -> T {
+    let json = try callString(fn)
+    do {
+        return try jsonDecoder.decode(T.self, from: Data(json.utf8))
+    } catch {
+        throw LibipswError(code: .parse, message: "failed to decode \(T.self): \(error)")
+    }
+}
+
+let jsonDecoder: JSONDecoder = {
+    let decoder = JSONDecoder()
+    decoder.dateDecodingStrategy = .custom { decoder in
+        let s = try decoder.singleValueContainer().decode(String.self)
+        if let date = parseDate(s) {
+            return date
+        }
+        throw DecodingError.dataCorrupted(.init(codingPath: decoder.codingPath, debugDescription: "invalid date \(s)"))
+    }
+    return decoder
+}()
+
+/// parses the RFC 3339 dates of Go's time.Time (with or without fractional seconds)
+func parseDate(_ s: String) -> Date? {
+    let formatter = ISO8601DateFormatter()
+    if let date = formatter.date(from: s) {
+        return date
+    }
+    formatter.formatOptions.insert(.withFractionalSeconds)
+    return formatter.date(from: s)
+}
+
+extension Bool {
+    var cChar: CChar { self ? 1 : 0 }
+}
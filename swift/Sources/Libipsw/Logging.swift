@@ -0,0 +1,51 @@
+import CLibipsw
+import Foundation
+
+/// The level of a log line of libipsw (the LIBIPSW_LOG_* constants of libipsw.h)
+public enum LogLevel: Int32, Comparable, Sendable {
+    case debug = 0
+    case info = 1
+    case warn = 2
+    case error = 3
+    case fatal = 4
+
+    public static func < (lhs: LogLevel, rhs: LogLevel) -> Bool {
+        lhs.rawValue < rhs.rawValue
+    }
+}
+
+/// A log handler is passed the level, message and fields (a JSON object, i.e. the trace ID of a lookup) of a log line
+public typealias LogHandler = @Sendable (LogLevel, String, String) -> Void
+
+private final class LogHandlerBox {
+    let handler: LogHandler
+
+    init(_ handler: @escaping LogHandler) {
+        self.handler = handler
+    }
+}
+
+private let logCallback: ipsw_log_cb = { level, message, fields, userData in
+    guard let userData else { return }
+    let box = Unmanaged<LogHandlerBox>.fromOpaque(userData).takeUnretainedValue()
+    box.handler(
+        LogLevel(rawValue: level) ?? .info,
+        message.map { String(cString: $0) } ?? "",
+        fields.map { String(cString: $0) } ?? "{}"
+    )
+}
+
+extension Libipsw {
+    /// Sends the log lines at or above level to handler instead of stderr (nil restores stderr);
+    /// handler is called from the library's threads
+    public static func setLogHandler(level: LogLevel = .info, _ handler: LogHandler?) {
+        ensureInitialized()
+        guard let handler else {
+            c_libipsw_set_log_callback(nil, 0, nil)
+            return
+        }
+        // the box is never released: a log line being handled may still use the previous handler
+        let box = Unmanaged.passRetained(LogHandlerBox(handler))
+        c_libipsw_set_log_callback(logCallback, level.rawValue, box.toOpaque())
+    }
+}
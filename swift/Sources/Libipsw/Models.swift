@@ -0,0 +1,244 @@
+import Foundation
+
+/// A device of ipsw.me
+public struct Device: Codable, Hashable, Sendable {
+    public var name: String
+    public var identifier: String
+    public var boardConfig: String?
+    public var platform: String?
+    public var cpid: Int?
+    public var bdid: Int?
+    public var firmwares: [IPSW]?
+
+    enum CodingKeys: String, CodingKey {
+        case name, identifier, platform, cpid, bdid, firmwares
+        case boardConfig = "boardconfig"
+    }
+}
+
+/// The fields shared by the IPSWs and OTAs of ipsw.me
+public protocol Firmware: Hashable, Sendable {
+    var identifier: String { get }
+    var version: String { get }
+    var buildID: String { get }
+    var url: String { get }
+    var fileSize: Int { get }
+    var releaseDate: Date? { get }
+    var signed: Bool { get }
+}
+
+enum FirmwareCodingKeys: String, CodingKey {
+    case identifier, version, url, signed
+    case buildID = "buildid"
+    case sha1 = "sha1sum"
+    case md5 = "md5sum"
+    case fileSize = "filesize"
+    case releaseDate = "releasedate"
+    case uploadDate = "uploaddate"
+}
+
+/// An IPSW of ipsw.me
+public struct IPSW: Firmware, Codable {
+    public var identifier: String
+    public var version: String
+    public var buildID: String
+    public var sha1: String?
+    public var md5: String?
+    public var fileSize: Int
+    public var url: String
+    public var releaseDate: Date?
+    public var uploadDate: Date?
+    public var signed: Bool
+
+    public init(from decoder: Decoder) throws {
+        let c = try decoder.container(keyedBy: FirmwareCodingKeys.self)
+        identifier = try c.decodeIfPresent(String.self, forKey: .identifier) ?? ""
+        version = try c.decodeIfPresent(String.self, forKey: .version) ?? ""
+        buildID = try c.decodeIfPresent(String.self, forKey: .buildID) ?? ""
+        sha1 = try c.decodeIfPresent(String.self, forKey: .sha1)
+        md5 = try c.decodeIfPresent(String.self, forKey: .md5)
+        fileSize = try c.decodeIfPresent(Int.self, forKey: .fileSize) ?? 0
+        url = try c.decodeIfPresent(String.self, forKey: .url) ?? ""
+        releaseDate = try c.decodeIfPresent(Date.self, forKey: .releaseDate)
+        uploadDate = try c.decodeIfPresent(Date.self, forKey: .uploadDate)
+        signed = try c.decodeIfPresent(Bool.self, forKey: .signed) ?? false
+    }
+
+    public func encode(to encoder: Encoder) throws {
+        var c = encoder.container(keyedBy: FirmwareCodingKeys.self)
+        try c.encode(identifier, forKey: .identifier)
+        try c.encode(version, forKey: .version)
+        try c.encode(buildID, forKey: .buildID)
+        try c.encodeIfPresent(sha1, forKey: .sha1)
+        try c.encodeIfPresent(md5, forKey: .md5)
+        try c.encode(fileSize, forKey: .fileSize)
+        try c.encode(url, forKey: .url)
+        try c.encodeIfPresent(releaseDate, forKey: .releaseDate)
+        try c.encodeIfPresent(uploadDate, forKey: .uploadDate)
+        try c.encode(signed, forKey: .signed)
+    }
+}
+
+/// An OTA of ipsw.me (the API describes them with the IPSW fields)
+public struct OTA: Firmware, Codable {
+    public var identifier: String
+    public var version: String
+    public var buildID: String
+    public var fileSize: Int
+    public var url: String
+    public var releaseDate: Date?
+    public var signed: Bool
+
+    public init(from decoder: Decoder) throws {
+        let c = try decoder.container(keyedBy: FirmwareCodingKeys.self)
+        identifier = try c.decodeIfPresent(String.self, forKey: .identifier) ?? ""
+        version = try c.decodeIfPresent(String.self, forKey: .version) ?? ""
+        buildID = try c.decodeIfPresent(String.self, forKey: .buildID) ?? ""
+        fileSize = try c.decodeIfPresent(Int.self, forKey: .fileSize) ?? 0
+        url = try c.decodeIfPresent(String.self, forKey: .url) ?? ""
+        releaseDate = try c.decodeIfPresent(Date.self, forKey: .releaseDate)
+        signed = try c.decodeIfPresent(Bool.self, forKey: .signed) ?? false
+    }
+
+    public func encode(to encoder: Encoder) throws {
+        var c = encoder.container(keyedBy: FirmwareCodingKeys.self)
+        try c.encode(identifier, forKey: .identifier)
+        try c.encode(version, forKey: .version)
+        try c.encode(buildID, forKey: .buildID)
+        try c.encode(fileSize, forKey: .fileSize)
+        try c.encode(url, forKey: .url)
+        try c.encodeIfPresent(releaseDate, forKey: .releaseDate)
+        try c.encode(signed, forKey: .signed)
+    }
+}
+
+/// A device of Xcode's device database (the lookups work offline)
+public struct XcodeDevice: Codable, Hashable, Sendable {
+    public var target: String
+    public var targetType: String?
+    public var targetVariant: String?
+    public var platform: String?
+    public var productType: String?
+    public var productDescription: String?
+    public var compatibleDeviceFallback: String?
+    public var traits: Traits?
+
+    /// The traits of a device (see the DeviceTraits table of Xcode's device database)
+    public struct Traits: Codable, Hashable, Sendable {
+        public var preferredArchitecture: String?
+        public var artworkDeviceIdiom: String?
+        public var artworkHostedIdioms: String?
+        public var artworkScaleFactor: Int?
+        public var artworkDeviceSubtype: Int?
+        public var artworkDisplayGamut: String?
+        public var artworkDynamicDisplayMode: String?
+        public var devicePerformanceMemoryClass: Int?
+        public var graphicsFeatureSetClass: String?
+        public var graphicsFeatureSetFallbacks: String?
+
+        enum CodingKeys: String, CodingKey {
+            case preferredArchitecture = "preferred_architecture"
+            case artworkDeviceIdiom = "artwork_device_idiom"
+            case artworkHostedIdioms = "artwork_hosted_idioms"
+            case artworkScaleFactor = "artwork_scale_factor"
+            case artworkDeviceSubtype = "artwork_device_subtype"
+            case artworkDisplayGamut = "artwork_display_gamut"
+            case artworkDynamicDisplayMode = "artwork_dynamic_display_mode"
+            case devicePerformanceMemoryClass = "device_performance_memory_class"
+            case graphicsFeatureSetClass = "graphics_feature_set_class"
+            case graphicsFeatureSetFallbacks = "graphics_feature_set_fallbacks"
+        }
+    }
+
+    enum CodingKeys: String, CodingKey {
+        case target, platform, traits
+        case targetType = "target_type"
+        case targetVariant = "target_variant"
+        case productType = "product_type"
+        case productDescription = "product_description"
+        case compatibleDeviceFallback = "compatible_device_fallback"
+    }
+}
+
+/// The state of a download
+public enum DownloadState: String, Codable, Sendable {
+    case queued, running, paused, canceled, done, failed
+
+    /// whether the download is over (done, failed or canceled)
+    public var isFinished: Bool {
+        self == .done || self == .failed || self == .canceled
+    }
+}
+
+/// The checksum verification of a download
+public struct Verification: Codable, Hashable, Sendable {
+    public var algorithm: String
+    public var expected: String
+    public var actual: String
+    public var verified: Bool
+    public var attempts: Int
+}
+
+/// The statistics of a download
+public struct DownloadStats: Codable, Hashable, Sendable {
+    public var url: String?
+    public var host: String?
+    public var dest: String?
+    public var state: DownloadState?
+    public var error: String?
+    public var started: Date?
+    public var duration: Int64? // nanoseconds
+    public var active: Int64? // nanoseconds spent transferring (excludes pauses)
+    public var bytes: Int64
+    public var resumedBytes: Int64
+    public var retries: Int
+    public var avgSpeed: Double // bytes per second
+    public var peakSpeed: Double // bytes per second
+    public var verification: Verification?
+    public var trace: String?
+
+    enum CodingKeys: String, CodingKey {
+        case url, host, dest, state, error, started, duration, active, bytes, retries, verification, trace
+        case resumedBytes = "resumed_bytes"
+        case avgSpeed = "avg_speed"
+        case peakSpeed = "peak_speed"
+    }
+}
+
+/// The status of a background download
+public struct DownloadStatus: Codable, Hashable, Sendable {
+    public var id: String?
+    public var url: String?
+    public var dest: String?
+    public var state: DownloadState?
+    public var size: Int64?
+    public var downloaded: Int64?
+    public var error: String?
+    public var stats: DownloadStats?
+}
+
+/// What a build of libipsw supports
+public struct Capabilities: Codable, Hashable, Sendable {
+    /// the metadata and download sources of the build (i.e. ipsw.me, developer.apple.com)
+    public var sources: [String]
+    public var cgo: Bool
+    public var wasm: Bool
+    public var os: String
+    public var arch: String
+}
+
+/// The version of libipsw (and of its C API) and what it was built with
+public struct BuildInfo: Codable, Hashable, Sendable {
+    public var version: String
+    public var cAPIVersion: String
+    public var commit: String?
+    public var modified: Bool?
+    public var goVersion: String
+    public var capabilities: Capabilities
+
+    enum CodingKeys: String, CodingKey {
+        case version, commit, modified, capabilities
+        case cAPIVersion = "c_api_version"
+        case goVersion = "go_version"
+    }
+}
@@ -0,0 +1,44 @@
+import CLibipsw
+
+/// The device lookups of Xcode's device database (embedded in the library, so they work offline)
+public enum Xcode {
+    /// Returns all the devices
+    public static func devices() throws -> [XcodeDevice] {
+        try callJSON([XcodeDevice].self) { c_pkg_xcode_xcode_GetDevices($0, $1, $2, $3) }
+    }
+
+    /// Returns the device with a product type (i.e. iPhone16,1)
+    public static func device(productType: String) throws -> XcodeDevice {
+        try withCArgs([productType]) { a in
+            try callJSON(XcodeDevice.self) { c_pkg_xcode_xcode_GetDeviceForProd(a[0].ptr, a[0].len, $0, $1, $2, $3) }
+        }
+    }
+
+    /// Returns the device with a model (i.e. A2848)
+    public static func device(model: String) throws -> XcodeDevice {
+        try withCArgs([model]) { a in
+            try callJSON(XcodeDevice.self) { c_pkg_xcode_xcode_GetDeviceForModel(a[0].ptr, a[0].len, $0, $1, $2, $3) }
+        }
+    }
+
+    /// Returns the device with a board config (i.e. D83AP or d83)
+    public static func device(board: String) throws -> XcodeDevice {
+        try withCArgs([board]) { a in
+            try callJSON(XcodeDevice.self) { c_pkg_xcode_xcode_GetDeviceForBoard(a[0].ptr, a[0].len, $0, $1, $2, $3) }
+        }
+    }
+
+    /// Returns the devices of an idiom (phone, pad, watch or tv)
+    public static func devices(idiom: String) throws -> [XcodeDevice] {
+        try withCArgs([idiom]) { a in
+            try callJSON([XcodeDevice].self) { c_pkg_xcode_xcode_GetDevicesForIdiom(a[0].ptr, a[0].len, $0, $1, $2, $3) }
+        }
+    }
+
+    /// Returns the devices with a chip (i.e. t8130)
+    public static func devices(chip: String) throws -> [XcodeDevice] {
+        try withCArgs([chip]) { a in
+            try callJSON([XcodeDevice].self) { c_pkg_xcode_xcode_GetDevicesForChip(a[0].ptr, a[0].len, $0, $1, $2, $3) }
+        }
+    }
+}
@@ -0,0 +1,51 @@
+import XCTest
+@testable import Libipsw
+
+final class LibipswTests: XCTestCase {
+    func testVersion() {
+        XCTAssertEqual(Libipsw.version, Libipsw.headerVersion)
+    }
+
+    func testBuildInfo() throws {
+        let info = try Libipsw.buildInfo()
+        XCTAssertEqual(info.cAPIVersion, Libipsw.headerVersion)
+        XCTAssertTrue(info.capabilities.cgo)
+        XCTAssertTrue(try Libipsw.capabilities().sources.contains("ipsw.me"))
+    }
+
+    func testCall() throws {
+        XCTAssertTrue(try Libipsw.methods().contains("xcode.device"))
+        let device = try Libipsw.call("xcode.device", params: ["product_type": "iPhone15,2"], as: XcodeDevice.self)
+        XCTAssertEqual(device.productType, "iPhone15,2")
+        XCTAssertThrowsError(try Libipsw.call("no.such_method", as: [String].self)) { error in
+            XCTAssertEqual((error as? LibipswError)?.code, .unsupported)
+        }
+    }
+
+    func testXcodeDevice() throws {
+        let device = try Xcode.device(productType: "iPhone15,2")
+        XCTAssertEqual(device.productType, "iPhone15,2")
+        XCTAssertNotNil(device.traits?.artworkDeviceIdiom)
+    }
+
+    func testNotFound() {
+        XCTAssertThrowsError(try Xcode.devices(chip: "t0000")) { error in
+            XCTAssertEqual((error as? LibipswError)?.code, .notFound)
+        }
+    }
+
+    func testInvalidArgument() {
+        XCTAssertThrowsError(try DownloadJob("")) { error in
+            XCTAssertEqual((error as? LibipswError)?.code, .invalidArgument)
+        }
+    }
+
+    func testDecodeIPSW() throws {
+        let json = #"{"identifier":"iPhone16,1","version":"17.0","buildid":"21A329","filesize":7000000000,"url":"https://updates.cdn-apple.com/a.ipsw","releasedate":"2023-09-18T17:03:35Z","uploaddate":"0001-01-01T00:00:00Z"}"#
+        let ipsw = try jsonDecoder.decode(IPSW.self, from: Data(json.utf8))
+        XCTAssertEqual(ipsw.buildID, "21A329")
+        XCTAssertEqual(ipsw.fileSize, 7_000_000_000)
+        XCTAssertFalse(ipsw.signed)
+        XCTAssertNotNil(ipsw.releaseDate)
+    }
+}